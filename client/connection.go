@@ -19,6 +19,8 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/oci"
+	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/simplestreams"
 )
 
@@ -316,6 +318,74 @@ func ConnectSimpleStreams(url string, args *ConnectionArgs) (ImageServer, error)
 	return &server, nil
 }
 
+// ConnectOCI lets you connect to a remote OCI distribution registry such as Docker Hub.
+//
+// Registry credentials can be included in the URL.
+// Images are resolved for the architecture of the local system.
+func ConnectOCI(url string, args *ConnectionArgs) (ImageServer, error) {
+	logger.Debug("Connecting to a remote OCI registry", logger.Ctx{"URL": url})
+
+	// Cleanup URL
+	url = strings.TrimSuffix(url, "/")
+
+	// Use empty args if not specified
+	if args == nil {
+		args = &ConnectionArgs{}
+	}
+
+	architecture, err := osarch.ArchitectureGetLocal()
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the client struct
+	server := ProtocolOCI{
+		httpHost:        url,
+		httpUserAgent:   args.UserAgent,
+		httpCertificate: args.TLSServerCert,
+		architecture:    architecture,
+		images:          map[string]*oci.Image{},
+	}
+
+	// Setup the HTTP client
+	httpClient, err := tlsHTTPClient(args.HTTPClient, args.TLSClientCert, args.TLSClientKey, args.TLSCA, args.TLSServerCert, args.InsecureSkipVerify, args.Proxy, args.TransportWrapper, "")
+	if err != nil {
+		return nil, err
+	}
+
+	server.http = httpClient
+
+	// Get the registry client
+	registry, err := oci.NewClient(url, *httpClient, args.UserAgent)
+	if err != nil {
+		return nil, err
+	}
+
+	server.registry = registry
+
+	// Setup the layer cache, layers being content addressed it is shared by all registries.
+	if args.CachePath != "" {
+		if !shared.PathExists(args.CachePath) {
+			return nil, fmt.Errorf("Cache directory %q does not exist", args.CachePath)
+		}
+
+		cachePath := filepath.Join(args.CachePath, "oci")
+		cacheExpiry := args.CacheExpiry
+		if cacheExpiry == 0 {
+			cacheExpiry = time.Hour
+		}
+
+		err := os.Mkdir(cachePath, 0700)
+		if err != nil && !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		registry.SetCache(cachePath, cacheExpiry)
+	}
+
+	return &server, nil
+}
+
 // ConnectDevLXD lets you connect to a LXD agent over a local unix socket.
 func ConnectDevLXD(socketPath string, args *ConnectionArgs) (DevLXDServer, error) {
 	return ConnectDevLXDWithContext(context.Background(), socketPath, args)
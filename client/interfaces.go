@@ -3,6 +3,7 @@ package lxd
 import (
 	"context"
 	"io"
+	"iter"
 	"net"
 	"net/http"
 	"net/url"
@@ -108,6 +109,8 @@ type InstanceServer interface {
 	GetInstanceNamesAllProjects(instanceType api.InstanceType) (names map[string][]string, err error)
 	GetInstances(args GetInstancesArgs) (instances []api.Instance, err error)
 	GetInstancesFull(args GetInstancesFullArgs) (instances []api.InstanceFull, err error)
+	GetInstancesIter(args GetInstancesArgs, page PageArgs) iter.Seq2[api.Instance, error]
+	GetInstancesFullIter(args GetInstancesFullArgs, page PageArgs) iter.Seq2[api.InstanceFull, error]
 	GetInstance(name string) (instance *api.Instance, ETag string, err error)
 	GetInstanceFull(name string) (instance *api.InstanceFull, ETag string, err error)
 	CreateInstance(instance api.InstancesPost) (op Operation, err error)
@@ -156,6 +159,16 @@ type InstanceServer interface {
 	GetInstanceBackupFile(instanceName string, name string, req *BackupFileRequest) (resp *BackupFileResponse, err error)
 	CreateInstanceFromBackup(args InstanceBackupArgs) (op Operation, err error)
 
+	// Backup target functions ("backup_targets" API extension)
+	GetBackupTargetNames() (names []string, err error)
+	GetBackupTargets() (targets []api.BackupTarget, err error)
+	GetBackupTarget(name string) (target *api.BackupTarget, ETag string, err error)
+	CreateBackupTarget(target api.BackupTargetsPost) (err error)
+	UpdateBackupTarget(name string, target api.BackupTargetPut, ETag string) (err error)
+	DeleteBackupTarget(name string) (err error)
+	GetBackupTargetBackups(name string, prefix string) (backups []api.BackupTargetBackup, err error)
+	DeleteBackupTargetBackup(name string, backupName string) (err error)
+
 	GetInstanceState(name string) (state *api.InstanceState, ETag string, err error)
 	UpdateInstanceState(name string, state api.InstanceStatePut, ETag string) (op Operation, err error)
 
@@ -183,12 +196,14 @@ type InstanceServer interface {
 	DeleteImage(fingerprint string) (op Operation, err error)
 	RefreshImage(fingerprint string) (op Operation, err error)
 	CreateImageSecret(fingerprint string) (op Operation, err error)
+	ExportImagesStreams(req api.ImagesStreamsPost) (op Operation, err error)
 	CreateImageAlias(alias api.ImageAliasesPost) (err error)
 	UpdateImageAlias(name string, alias api.ImageAliasesEntryPut, ETag string) (err error)
 	RenameImageAlias(name string, alias api.ImageAliasesEntryPost) (err error)
 	DeleteImageAlias(name string) (err error)
 	GetImagesAllProjects() (images []api.Image, err error)
 	GetImagesAllProjectsWithFilter(filters []string) (images []api.Image, err error)
+	GetImagesIter(filters []string, page PageArgs) iter.Seq2[api.Image, error]
 
 	// Network functions ("network" API extension)
 	GetNetworkNames() (names []string, err error)
@@ -238,11 +253,33 @@ type InstanceServer interface {
 	GetNetworkACLsAllProjects() (acls []api.NetworkACL, err error)
 	GetNetworkACL(name string) (acl *api.NetworkACL, ETag string, err error)
 	GetNetworkACLLogfile(name string) (log io.ReadCloser, err error)
+	GetNetworkACLState(name string) (state *api.NetworkACLState, err error)
 	CreateNetworkACL(acl api.NetworkACLsPost) (op Operation, err error)
 	UpdateNetworkACL(name string, acl api.NetworkACLPut, ETag string) (op Operation, err error)
 	RenameNetworkACL(name string, acl api.NetworkACLPost) (op Operation, err error)
 	DeleteNetworkACL(name string) (op Operation, err error)
 
+	// Network address set functions ("network_acl_sets" API extension)
+	GetNetworkAddressSetNames() (names []string, err error)
+	GetNetworkAddressSets() (sets []api.NetworkAddressSet, err error)
+	GetNetworkAddressSetsAllProjects() (sets []api.NetworkAddressSet, err error)
+	GetNetworkAddressSet(name string) (set *api.NetworkAddressSet, ETag string, err error)
+	GetNetworkAddressSetState(name string) (state *api.NetworkAddressSetState, err error)
+	CreateNetworkAddressSet(set api.NetworkAddressSetsPost) (op Operation, err error)
+	UpdateNetworkAddressSet(name string, set api.NetworkAddressSetPut, ETag string) (op Operation, err error)
+	RenameNetworkAddressSet(name string, set api.NetworkAddressSetPost) (op Operation, err error)
+	DeleteNetworkAddressSet(name string) (op Operation, err error)
+
+	// Network port set functions ("network_acl_sets" API extension)
+	GetNetworkPortSetNames() (names []string, err error)
+	GetNetworkPortSets() (sets []api.NetworkPortSet, err error)
+	GetNetworkPortSetsAllProjects() (sets []api.NetworkPortSet, err error)
+	GetNetworkPortSet(name string) (set *api.NetworkPortSet, ETag string, err error)
+	CreateNetworkPortSet(set api.NetworkPortSetsPost) (op Operation, err error)
+	UpdateNetworkPortSet(name string, set api.NetworkPortSetPut, ETag string) (op Operation, err error)
+	RenameNetworkPortSet(name string, set api.NetworkPortSetPost) (op Operation, err error)
+	DeleteNetworkPortSet(name string) (op Operation, err error)
+
 	// Network allocations functions ("network_allocations" API extension)
 	GetNetworkAllocations(allProjects bool) (allocations []api.NetworkAllocations, err error)
 
@@ -266,6 +303,7 @@ type InstanceServer interface {
 	GetOperationUUIDs() (uuids []string, err error)
 	GetOperations() (operations []api.Operation, err error)
 	GetOperationsAllProjects() (operations []api.Operation, err error)
+	GetOperationsIter(page PageArgs) iter.Seq2[api.Operation, error]
 	GetOperation(uuid string) (op *api.Operation, ETag string, err error)
 	GetOperationFull(uuid string) (op *api.OperationFull, ETag string, err error)
 	GetOperationWait(uuid string, timeout int) (op *api.Operation, ETag string, err error)
@@ -329,6 +367,7 @@ type InstanceServer interface {
 	GetStoragePoolVolumesAllProjects(pool string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolumesWithFilter(pool string, filters []string) (volumes []api.StorageVolume, err error)
 	GetStoragePoolVolumesWithFilterAllProjects(pool string, filters []string) (volumes []api.StorageVolume, err error)
+	GetStoragePoolVolumesIter(pool string, filters []string, page PageArgs) iter.Seq2[api.StorageVolume, error]
 	GetStoragePoolVolume(pool string, volType string, name string) (volume *api.StorageVolume, ETag string, err error)
 	GetStoragePoolVolumeState(pool string, volType string, name string) (state *api.StorageVolumeState, err error)
 	CreateStoragePoolVolume(pool string, volume api.StorageVolumesPost) (op Operation, err error)
@@ -376,6 +415,8 @@ type InstanceServer interface {
 	UpdateClusterCertificate(certs api.ClusterCertificatePut, ETag string) (err error)
 	GetClusterMemberState(name string) (*api.ClusterMemberState, string, error)
 	UpdateClusterMemberState(name string, state api.ClusterMemberStatePost) (op Operation, err error)
+	GetClusterRebalancePlan() (moves []api.ClusterRebalanceMove, err error)
+	RebalanceCluster() (op Operation, err error)
 	GetClusterGroups() ([]api.ClusterGroup, error)
 	GetClusterGroupNames() ([]string, error)
 	RenameClusterGroup(name string, group api.ClusterGroupPost) error
@@ -398,6 +439,7 @@ type InstanceServer interface {
 	GetReplicatorNames() (replicatorNames []string, err error)
 	GetReplicator(project string, name string) (replicator *api.Replicator, ETag string, err error)
 	GetReplicatorState(project string, name string) (replicatorState *api.ReplicatorState, err error)
+	GetReplicatorRuns(project string, name string) (runs []api.ReplicatorRun, err error)
 	CreateReplicator(project string, replicator api.ReplicatorsPost) (err error)
 	UpdateReplicator(project string, name string, replicator api.ReplicatorPut, ETag string) (err error)
 	DeleteReplicator(project string, name string) (err error)
@@ -407,6 +449,7 @@ type InstanceServer interface {
 	// Warning functions
 	GetWarningUUIDs() (uuids []string, err error)
 	GetWarnings() (warnings []api.Warning, err error)
+	GetWarningsIter(page PageArgs) iter.Seq2[api.Warning, error]
 	GetWarning(UUID string) (warning *api.Warning, ETag string, err error)
 	UpdateWarning(UUID string, warning api.WarningPut, ETag string) (err error)
 	DeleteWarning(UUID string) (err error)
@@ -555,6 +598,15 @@ type BackupFileResponse struct {
 	Size int64
 }
 
+// The PageArgs struct is used to iterate over a sorted and paginated collection.
+type PageArgs struct {
+	// Maximum number of entries to fetch per request (0 fetches the whole collection at once)
+	Limit int
+
+	// Fields to sort the collection by, prefixed with "-" for descending order
+	Sort []string
+}
+
 // The ImageCreateArgs struct is used for direct image upload.
 type ImageCreateArgs struct {
 	// Reader for the meta file
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
 	"net/http"
 	neturl "net/url"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -365,6 +367,69 @@ func (r *ProtocolLXD) queryStruct(method string, path string, data any, ETag str
 	return etag, nil
 }
 
+// queryPages returns an iterator over the entries of a collection, fetching pages of up to page.Limit entries on
+// demand by following the continue token returned by the server. The decode function converts the metadata of each
+// page into its entries.
+// If the server lacks the "collection_pagination" extension and no sort order is requested, the whole collection
+// is fetched in a single request.
+func queryPages[T any](r *ProtocolLXD, path string, values neturl.Values, page PageArgs, decode func(resp *api.Response) ([]T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		err := r.CheckExtension("collection_pagination")
+		if err == nil {
+			if page.Limit > 0 {
+				values.Set("limit", strconv.Itoa(page.Limit))
+			}
+
+			if len(page.Sort) > 0 {
+				values.Set("sort", strings.Join(page.Sort, ","))
+			}
+		} else if len(page.Sort) > 0 {
+			yield(zero, err)
+			return
+		}
+
+		for {
+			resp, _, err := r.query(http.MethodGet, path+"?"+values.Encode(), nil, "")
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			entries, err := decode(resp)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, entry := range entries {
+				if !yield(entry, nil) {
+					return
+				}
+			}
+
+			if resp.Continue == "" {
+				return
+			}
+
+			values.Set("continue", resp.Continue)
+		}
+	}
+}
+
+// decodePage unmarshals the response metadata into a list of entries.
+func decodePage[T any](resp *api.Response) ([]T, error) {
+	entries := []T{}
+
+	err := resp.MetadataAsStruct(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 // queryOperation sends a query to the LXD server and then converts the response metadata into an Operation object.
 // If useEventListener is true it will set up an early event listener and manage its lifecycle.
 // If useEventListener is false, it will not set up an event listener and calls to Operation.Wait will use the operations API instead.
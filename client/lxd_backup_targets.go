@@ -0,0 +1,155 @@
+package lxd
+
+import (
+	"net/http"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetBackupTargetNames returns the names of all backup targets.
+func (r *ProtocolLXD) GetBackupTargetNames() ([]string, error) {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/backup-targets"
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetBackupTargets returns a list of backup target structs.
+func (r *ProtocolLXD) GetBackupTargets() ([]api.BackupTarget, error) {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []api.BackupTarget{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("backup-targets").WithQuery("recursion", "1")
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &targets)
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// GetBackupTarget returns a backup target entry for the provided name.
+func (r *ProtocolLXD) GetBackupTarget(name string) (*api.BackupTarget, string, error) {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return nil, "", err
+	}
+
+	target := api.BackupTarget{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("backup-targets", name)
+	etag, err := r.queryStruct(http.MethodGet, u.String(), nil, "", &target)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &target, etag, nil
+}
+
+// CreateBackupTarget defines a new backup target using the provided struct.
+func (r *ProtocolLXD) CreateBackupTarget(target api.BackupTargetsPost) error {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	_, _, err = r.query(http.MethodPost, "/backup-targets", target, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UpdateBackupTarget updates the backup target to match the provided struct.
+func (r *ProtocolLXD) UpdateBackupTarget(name string, target api.BackupTargetPut, ETag string) error {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("backup-targets", name)
+	_, _, err = r.query(http.MethodPut, u.String(), target, ETag)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBackupTarget deletes an existing backup target.
+// The backups stored on the target are left in place.
+func (r *ProtocolLXD) DeleteBackupTarget(name string) error {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("backup-targets", name)
+	_, _, err = r.query(http.MethodDelete, u.String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetBackupTargetBackups returns the backups stored on the backup target whose name starts with the prefix.
+func (r *ProtocolLXD) GetBackupTargetBackups(name string, prefix string) ([]api.BackupTargetBackup, error) {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []api.BackupTargetBackup{}
+
+	// Fetch the raw value.
+	u := api.NewURL().Path("backup-targets", name, "backups")
+	if prefix != "" {
+		u = u.WithQuery("prefix", prefix)
+	}
+
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &backups)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
+// DeleteBackupTargetBackup deletes a backup stored on the backup target.
+func (r *ProtocolLXD) DeleteBackupTargetBackup(name string, backupName string) error {
+	err := r.CheckExtension("backup_targets")
+	if err != nil {
+		return err
+	}
+
+	// Send the request.
+	u := api.NewURL().Path("backup-targets", name, "backups", backupName)
+	_, _, err = r.query(http.MethodDelete, u.String(), nil, "")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
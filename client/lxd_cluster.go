@@ -246,6 +246,39 @@ func (r *ProtocolLXD) UpdateClusterMemberState(name string, state api.ClusterMem
 	return op, nil
 }
 
+// GetClusterRebalancePlan returns the instance moves a cluster rebalancing would perform.
+func (r *ProtocolLXD) GetClusterRebalancePlan() ([]api.ClusterRebalanceMove, error) {
+	err := r.CheckExtension("cluster_rebalance")
+	if err != nil {
+		return nil, err
+	}
+
+	moves := []api.ClusterRebalanceMove{}
+	u := api.NewURL().Path("cluster", "rebalance")
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &moves)
+	if err != nil {
+		return nil, err
+	}
+
+	return moves, nil
+}
+
+// RebalanceCluster moves instances from the most to the least loaded cluster members.
+func (r *ProtocolLXD) RebalanceCluster() (Operation, error) {
+	err := r.CheckExtension("cluster_rebalance")
+	if err != nil {
+		return nil, err
+	}
+
+	u := api.NewURL().Path("cluster", "rebalance")
+	op, _, err := r.queryOperation(http.MethodPost, u.String(), nil, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
+
 // GetClusterGroups returns the cluster groups.
 func (r *ProtocolLXD) GetClusterGroups() ([]api.ClusterGroup, error) {
 	err := r.CheckExtension("clustering_groups")
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -97,6 +98,25 @@ func (r *ProtocolLXD) GetImagesWithFilter(filters []string) ([]api.Image, error)
 	return images, nil
 }
 
+// GetImagesIter returns an iterator over the images, optionally filtered, fetched one page at a time.
+func (r *ProtocolLXD) GetImagesIter(filters []string, page PageArgs) iter.Seq2[api.Image, error] {
+	v := url.Values{}
+	v.Set("recursion", "1")
+
+	if len(filters) > 0 {
+		err := r.CheckExtension("api_filtering")
+		if err != nil {
+			return func(yield func(api.Image, error) bool) {
+				yield(api.Image{}, err)
+			}
+		}
+
+		v.Set("filter", parseFilters(filters))
+	}
+
+	return queryPages(r, "/images", v, page, decodePage[api.Image])
+}
+
 // GetImageFingerprints returns a list of available image fingerprints.
 func (r *ProtocolLXD) GetImageFingerprints() ([]string, error) {
 	// Fetch the raw URL values.
@@ -904,6 +924,12 @@ func (r *ProtocolLXD) CopyImage(source ImageServer, image api.Image, args *Image
 		req.Source.ImageType = args.Type
 	}
 
+	// OCI registries can't look images up by fingerprint so reference them by name instead.
+	if info.Protocol == "oci" && len(image.Aliases) > 0 {
+		req.Source.Fingerprint = ""
+		req.Source.Alias = image.Aliases[0].Name
+	}
+
 	// Generate secret token if needed
 	if !image.Public {
 		secret, err := source.GetImageSecret(image.Fingerprint)
@@ -1039,3 +1065,19 @@ func (r *ProtocolLXD) ExportImage(fingerprint string, image api.ImageExportPost)
 
 	return op, nil
 }
+
+// ExportImagesStreams adds images to a simplestreams tree stored in a directory on the server.
+func (r *ProtocolLXD) ExportImagesStreams(req api.ImagesStreamsPost) (Operation, error) {
+	err := r.CheckExtension("images_export_streams")
+	if err != nil {
+		return nil, err
+	}
+
+	// Send the request
+	op, _, err := r.queryOperation(http.MethodPost, "/images/streams", req, "", true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
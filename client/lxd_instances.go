@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net"
 	"net/http"
 	"net/url"
@@ -139,6 +140,30 @@ func (r *ProtocolLXD) GetInstances(args GetInstancesArgs) ([]api.Instance, error
 	return instances, nil
 }
 
+// GetInstancesIter returns an iterator over the instances, fetched one page at a time.
+func (r *ProtocolLXD) GetInstancesIter(args GetInstancesArgs, page PageArgs) iter.Seq2[api.Instance, error] {
+	path, v, err := r.instanceTypeToPath(args.InstanceType)
+	if err == nil && args.AllProjects {
+		err = r.CheckExtension("instance_all_projects")
+		v.Set("all-projects", "true")
+	}
+
+	if err == nil && len(args.Filters) > 0 {
+		err = r.CheckExtension("api_filtering")
+		v.Set("filter", parseFilters(args.Filters))
+	}
+
+	if err != nil {
+		return func(yield func(api.Instance, error) bool) {
+			yield(api.Instance{}, err)
+		}
+	}
+
+	v.Set("recursion", "1")
+
+	return queryPages(r, path, v, page, decodePage[api.Instance])
+}
+
 // UpdateInstances updates all instances to match the requested state.
 func (r *ProtocolLXD) UpdateInstances(state api.InstancesPut, ETag string) (Operation, error) {
 	path, v, err := r.instanceTypeToPath(api.InstanceTypeAny)
@@ -355,6 +380,41 @@ func (r *ProtocolLXD) GetInstancesFull(args GetInstancesFullArgs) ([]api.Instanc
 	return instances, nil
 }
 
+// GetInstancesFullIter returns an iterator over the instances including snapshots, backups and state, fetched one
+// page at a time.
+func (r *ProtocolLXD) GetInstancesFullIter(args GetInstancesFullArgs, page PageArgs) iter.Seq2[api.InstanceFull, error] {
+	path, v, err := r.instanceTypeToPath(args.InstanceType)
+	if err == nil {
+		err = r.CheckExtension("container_full")
+	}
+
+	if err == nil && args.AllProjects {
+		err = r.CheckExtension("instance_all_projects")
+		v.Set("all-projects", "true")
+	}
+
+	if err == nil && len(args.Filters) > 0 {
+		err = r.CheckExtension("api_filtering")
+		v.Set("filter", parseFilters(args.Filters))
+	}
+
+	if err != nil {
+		return func(yield func(api.InstanceFull, error) bool) {
+			yield(api.InstanceFull{}, err)
+		}
+	}
+
+	// Handle selective fields if specified, as in GetInstancesFull.
+	recursionValue := "2"
+	if args.Fields != nil && r.CheckExtension("instances_state_selective_recursion") == nil {
+		recursionValue = "2;fields=" + strings.Join(args.Fields, ",")
+	}
+
+	v.Set("recursion", recursionValue)
+
+	return queryPages(r, path, v, page, decodePage[api.InstanceFull])
+}
+
 // GetInstance returns the instance entry for the provided name.
 func (r *ProtocolLXD) GetInstance(name string) (*api.Instance, string, error) {
 	instance := api.Instance{}
@@ -592,6 +652,13 @@ func (r *ProtocolLXD) CreateInstance(instance api.InstancesPost) (Operation, err
 		}
 	}
 
+	if instance.Source.Type == api.SourceTypeBackup {
+		err := r.CheckExtension("backup_targets")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation(http.MethodPost, path, instance, "", true)
 	if err != nil {
@@ -2889,6 +2956,13 @@ func (r *ProtocolLXD) CreateInstanceBackup(instanceName string, backup api.Insta
 		return nil, err
 	}
 
+	if backup.BackupTarget != "" {
+		err = r.CheckExtension("backup_targets")
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Send the request
 	op, _, err := r.queryOperation(http.MethodPost, path+"/"+url.PathEscape(instanceName)+"/backups", backup, "", true)
 	if err != nil {
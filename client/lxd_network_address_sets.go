@@ -0,0 +1,157 @@
+package lxd
+
+import (
+	"net/http"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkAddressSetNames returns a list of network address set names.
+func (r *ProtocolLXD) GetNetworkAddressSetNames() ([]string, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/network-address-sets"
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkAddressSets returns a list of network address set structs.
+func (r *ProtocolLXD) GetNetworkAddressSets() ([]api.NetworkAddressSet, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []api.NetworkAddressSet{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct(http.MethodGet, "/network-address-sets?recursion=1", nil, "", &sets)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetNetworkAddressSetsAllProjects returns a list of network address sets across all projects.
+func (r *ProtocolLXD) GetNetworkAddressSetsAllProjects() ([]api.NetworkAddressSet, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []api.NetworkAddressSet{}
+	u := api.NewURL().Path("network-address-sets").WithQuery("recursion", "1").WithQuery("all-projects", "true")
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &sets)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetNetworkAddressSet returns a network address set entry for the provided name.
+func (r *ProtocolLXD) GetNetworkAddressSet(name string) (*api.NetworkAddressSet, string, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, "", err
+	}
+
+	set := api.NetworkAddressSet{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct(http.MethodGet, api.NewURL().Path("network-address-sets", name).String(), nil, "", &set)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &set, etag, nil
+}
+
+// GetNetworkAddressSetState returns the addresses a network address set currently resolves to.
+func (r *ProtocolLXD) GetNetworkAddressSetState(name string) (*api.NetworkAddressSetState, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	state := api.NetworkAddressSetState{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct(http.MethodGet, api.NewURL().Path("network-address-sets", name, "state").String(), nil, "", &state)
+	if err != nil {
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// CreateNetworkAddressSet defines a new network address set using the provided struct.
+func (r *ProtocolLXD) CreateNetworkAddressSet(set api.NetworkAddressSetsPost) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPost, api.NewURL().Path("network-address-sets"), set, "")
+}
+
+// UpdateNetworkAddressSet updates the network address set to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkAddressSet(name string, set api.NetworkAddressSetPut, ETag string) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPut, api.NewURL().Path("network-address-sets", name), set, ETag)
+}
+
+// RenameNetworkAddressSet renames an existing network address set entry.
+func (r *ProtocolLXD) RenameNetworkAddressSet(name string, set api.NetworkAddressSetPost) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPost, api.NewURL().Path("network-address-sets", name), set, "")
+}
+
+// DeleteNetworkAddressSet deletes an existing network address set.
+func (r *ProtocolLXD) DeleteNetworkAddressSet(name string) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodDelete, api.NewURL().Path("network-address-sets", name), nil, "")
+}
+
+// queryNetworkSetOperation sends a network set modification request, synchronously when handling a cluster
+// operation notification.
+func (r *ProtocolLXD) queryNetworkSetOperation(method string, path *api.URL, data any, ETag string) (Operation, error) {
+	if r.isClusterOperationNotification() {
+		_, _, err := r.query(method, path.String(), data, ETag)
+		if err != nil {
+			return nil, err
+		}
+
+		return noopOperation{}, nil
+	}
+
+	op, _, err := r.queryOperation(method, path.String(), data, ETag, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, nil
+}
@@ -0,0 +1,119 @@
+package lxd
+
+import (
+	"net/http"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetNetworkPortSetNames returns a list of network port set names.
+func (r *ProtocolLXD) GetNetworkPortSetNames() ([]string, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch the raw URL values.
+	urls := []string{}
+	baseURL := "/network-port-sets"
+	_, err = r.queryStruct(http.MethodGet, baseURL, nil, "", &urls)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse it.
+	return urlsToResourceNames(baseURL, urls...)
+}
+
+// GetNetworkPortSets returns a list of network port set structs.
+func (r *ProtocolLXD) GetNetworkPortSets() ([]api.NetworkPortSet, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []api.NetworkPortSet{}
+
+	// Fetch the raw value.
+	_, err = r.queryStruct(http.MethodGet, "/network-port-sets?recursion=1", nil, "", &sets)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetNetworkPortSetsAllProjects returns a list of network port sets across all projects.
+func (r *ProtocolLXD) GetNetworkPortSetsAllProjects() ([]api.NetworkPortSet, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := []api.NetworkPortSet{}
+	u := api.NewURL().Path("network-port-sets").WithQuery("recursion", "1").WithQuery("all-projects", "true")
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &sets)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetNetworkPortSet returns a network port set entry for the provided name.
+func (r *ProtocolLXD) GetNetworkPortSet(name string) (*api.NetworkPortSet, string, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, "", err
+	}
+
+	set := api.NetworkPortSet{}
+
+	// Fetch the raw value.
+	etag, err := r.queryStruct(http.MethodGet, api.NewURL().Path("network-port-sets", name).String(), nil, "", &set)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &set, etag, nil
+}
+
+// CreateNetworkPortSet defines a new network port set using the provided struct.
+func (r *ProtocolLXD) CreateNetworkPortSet(set api.NetworkPortSetsPost) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPost, api.NewURL().Path("network-port-sets"), set, "")
+}
+
+// UpdateNetworkPortSet updates the network port set to match the provided struct.
+func (r *ProtocolLXD) UpdateNetworkPortSet(name string, set api.NetworkPortSetPut, ETag string) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPut, api.NewURL().Path("network-port-sets", name), set, ETag)
+}
+
+// RenameNetworkPortSet renames an existing network port set entry.
+func (r *ProtocolLXD) RenameNetworkPortSet(name string, set api.NetworkPortSetPost) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodPost, api.NewURL().Path("network-port-sets", name), set, "")
+}
+
+// DeleteNetworkPortSet deletes an existing network port set.
+func (r *ProtocolLXD) DeleteNetworkPortSet(name string) (Operation, error) {
+	err := r.CheckExtension("network_acl_sets")
+	if err != nil {
+		return nil, err
+	}
+
+	return r.queryNetworkSetOperation(http.MethodDelete, api.NewURL().Path("network-port-sets", name), nil, "")
+}
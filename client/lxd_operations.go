@@ -1,13 +1,17 @@
 package lxd
 
 import (
+	"iter"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/websocket"
 
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/filter"
 )
 
 // GetOperationUUIDs returns a list of operation uuids.
@@ -43,6 +47,47 @@ func (r *ProtocolLXD) GetOperations() ([]api.Operation, error) {
 	return operations, nil
 }
 
+// GetOperationsIter returns an iterator over the operations, fetched one page at a time.
+func (r *ProtocolLXD) GetOperationsIter(page PageArgs) iter.Seq2[api.Operation, error] {
+	v := url.Values{}
+	v.Set("recursion", "1")
+
+	// Each page is grouped by status, so restore the requested order of its operations.
+	sortKeys, err := filter.ParseSort(strings.Join(page.Sort, ","))
+	if err != nil {
+		return func(yield func(api.Operation, error) bool) {
+			yield(api.Operation{}, err)
+		}
+	}
+
+	decode := func(resp *api.Response) ([]api.Operation, error) {
+		apiOperations := map[string][]api.Operation{}
+
+		err := resp.MetadataAsStruct(&apiOperations)
+		if err != nil {
+			return nil, err
+		}
+
+		operations := []api.Operation{}
+		for _, v := range apiOperations {
+			operations = append(operations, v...)
+		}
+
+		slices.SortFunc(operations, func(a api.Operation, b api.Operation) int {
+			return strings.Compare(a.ID, b.ID)
+		})
+
+		err = filter.Sort(operations, sortKeys)
+		if err != nil {
+			return nil, err
+		}
+
+		return operations, nil
+	}
+
+	return queryPages(r, "/operations", v, page, decode)
+}
+
 // GetOperationsAllProjects returns a list of operations from all projects.
 func (r *ProtocolLXD) GetOperationsAllProjects() ([]api.Operation, error) {
 	err := r.CheckExtension("operations_get_query_all_projects")
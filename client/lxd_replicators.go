@@ -122,6 +122,23 @@ func (r *ProtocolLXD) GetReplicatorState(project string, name string) (*api.Repl
 	return state, nil
 }
 
+// GetReplicatorRuns returns the recorded runs of a replicator, most recent first.
+func (r *ProtocolLXD) GetReplicatorRuns(project string, name string) ([]api.ReplicatorRun, error) {
+	err := r.CheckExtension("replicator_run_history")
+	if err != nil {
+		return nil, err
+	}
+
+	runs := []api.ReplicatorRun{}
+	u := api.NewURL().Path("replicators", name, "runs").Project(project)
+	_, err = r.queryStruct(http.MethodGet, u.String(), nil, "", &runs)
+	if err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
 // RenameReplicator renames a replicator.
 func (r *ProtocolLXD) RenameReplicator(project string, name string, req api.ReplicatorPost) error {
 	err := r.CheckExtension("replicators")
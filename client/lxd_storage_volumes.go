@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 	"net/url"
 
@@ -183,6 +184,30 @@ func (r *ProtocolLXD) GetStoragePoolVolumesWithFilter(pool string, filters []str
 	return volumes, nil
 }
 
+// GetStoragePoolVolumesIter returns an iterator over the StorageVolume entries for the provided pool, optionally
+// filtered, fetched one page at a time.
+func (r *ProtocolLXD) GetStoragePoolVolumesIter(pool string, filters []string, page PageArgs) iter.Seq2[api.StorageVolume, error] {
+	err := r.CheckExtension("storage")
+	if err == nil && len(filters) > 0 {
+		err = r.CheckExtension("api_filtering")
+	}
+
+	if err != nil {
+		return func(yield func(api.StorageVolume, error) bool) {
+			yield(api.StorageVolume{}, err)
+		}
+	}
+
+	v := url.Values{}
+	v.Set("recursion", "1")
+
+	if len(filters) > 0 {
+		v.Set("filter", parseFilters(filters))
+	}
+
+	return queryPages(r, "/storage-pools/"+url.PathEscape(pool)+"/volumes", v, page, decodePage[api.StorageVolume])
+}
+
 // GetStoragePoolVolumesWithFilterAllProjects returns a filtered list of StorageVolume entries for the provided pool for all projects.
 func (r *ProtocolLXD) GetStoragePoolVolumesWithFilterAllProjects(pool string, filters []string) ([]api.StorageVolume, error) {
 	err := r.CheckExtension("storage")
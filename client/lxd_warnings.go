@@ -1,6 +1,7 @@
 package lxd
 
 import (
+	"iter"
 	"net/http"
 	"net/url"
 
@@ -45,6 +46,21 @@ func (r *ProtocolLXD) GetWarnings() ([]api.Warning, error) {
 	return warnings, nil
 }
 
+// GetWarningsIter returns an iterator over the warnings, fetched one page at a time.
+func (r *ProtocolLXD) GetWarningsIter(page PageArgs) iter.Seq2[api.Warning, error] {
+	err := r.CheckExtension("warnings")
+	if err != nil {
+		return func(yield func(api.Warning, error) bool) {
+			yield(api.Warning{}, err)
+		}
+	}
+
+	v := url.Values{}
+	v.Set("recursion", "1")
+
+	return queryPages(r, "/warnings", v, page, decodePage[api.Warning])
+}
+
 // GetWarning returns the warning with the given UUID.
 func (r *ProtocolLXD) GetWarning(UUID string) (*api.Warning, string, error) {
 	err := r.CheckExtension("warnings")
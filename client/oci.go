@@ -0,0 +1,60 @@
+package lxd
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/canonical/lxd/shared/oci"
+)
+
+// ProtocolOCI implements an OCI distribution registry client.
+type ProtocolOCI struct {
+	registry *oci.Client
+
+	http            *http.Client
+	httpHost        string
+	httpUserAgent   string
+	httpCertificate string
+
+	// architecture is the architecture images are resolved for.
+	architecture string
+
+	// images holds the images resolved through this connection, indexed by fingerprint.
+	images   map[string]*oci.Image
+	imagesMu sync.Mutex
+}
+
+// Disconnect is a no-op for OCI registries.
+func (r *ProtocolOCI) Disconnect() {
+}
+
+// GetConnectionInfo returns the basic connection information used to interact with the server.
+func (r *ProtocolOCI) GetConnectionInfo() (*ConnectionInfo, error) {
+	info := ConnectionInfo{}
+	info.Addresses = []string{r.httpHost}
+	info.Certificate = r.httpCertificate
+	info.Protocol = "oci"
+	info.URL = r.httpHost
+
+	return &info, nil
+}
+
+// GetHTTPClient returns the http client used for the connection. This can be used to set custom http options.
+func (r *ProtocolOCI) GetHTTPClient() (*http.Client, error) {
+	if r.http == nil {
+		return nil, errors.New("HTTP client is not set, bad connection")
+	}
+
+	return r.http, nil
+}
+
+// DoHTTP performs a Request.
+func (r *ProtocolOCI) DoHTTP(req *http.Request) (*http.Response, error) {
+	// Set the user agent
+	if r.httpUserAgent != "" {
+		req.Header.Set("User-Agent", r.httpUserAgent)
+	}
+
+	return r.http.Do(req)
+}
@@ -0,0 +1,238 @@
+package lxd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/oci"
+)
+
+// countingWriter counts the bytes written to the underlying writer.
+type countingWriter struct {
+	io.Writer
+	size int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.size += int64(n)
+
+	return n, err
+}
+
+// resolve returns the image matching the given reference, or fingerprint of an image previously resolved through
+// this connection.
+func (r *ProtocolOCI) resolve(name string) (*oci.Image, error) {
+	r.imagesMu.Lock()
+	img, ok := r.images[name]
+	r.imagesMu.Unlock()
+
+	if ok {
+		return img, nil
+	}
+
+	ref, err := oci.ParseReference(name)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Image not found: %v", err)
+	}
+
+	img, err = r.registry.Resolve(context.TODO(), *ref, r.architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	r.imagesMu.Lock()
+	r.images[img.Fingerprint()] = img
+	r.imagesMu.Unlock()
+
+	return img, nil
+}
+
+// checkImageType checks that the requested image type can be served by an OCI registry.
+func (r *ProtocolOCI) checkImageType(imageType string) error {
+	if imageType != "" && imageType != string(api.InstanceTypeContainer) {
+		return api.StatusErrorf(http.StatusNotFound, "OCI images can only be used by containers")
+	}
+
+	return nil
+}
+
+// Image handling functions
+
+// GetImages isn't supported by OCI registries as they can't list their images.
+func (r *ProtocolOCI) GetImages() ([]api.Image, error) {
+	return nil, errors.New("Listing images is not supported by the OCI protocol")
+}
+
+// GetImageFingerprints isn't supported by OCI registries as they can't list their images.
+func (r *ProtocolOCI) GetImageFingerprints() ([]string, error) {
+	return nil, errors.New("Listing images is not supported by the OCI protocol")
+}
+
+// GetImagesWithFilter isn't supported by OCI registries as they can't list their images.
+func (r *ProtocolOCI) GetImagesWithFilter(filters []string) ([]api.Image, error) {
+	return nil, errors.New("Listing images is not supported by the OCI protocol")
+}
+
+// GetImage returns an Image struct for the provided image reference or fingerprint.
+//
+// The fingerprint of an image coming from an OCI registry is the digest of its manifest rather than the hash of
+// its image file, as the image file is only built when downloading the image.
+func (r *ProtocolOCI) GetImage(fingerprint string) (*api.Image, string, error) {
+	img, err := r.resolve(fingerprint)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed getting image: %w", err)
+	}
+
+	image := api.Image{
+		Properties:   img.Properties(),
+		Public:       true,
+		Architecture: img.Architecture,
+		Fingerprint:  img.Fingerprint(),
+		Filename:     img.Fingerprint() + ".tar.gz",
+		Size:         img.Size(),
+		Type:         string(api.InstanceTypeContainer),
+	}
+
+	if img.Reference.Tag != "" {
+		image.Aliases = []api.ImageAlias{{Name: img.Reference.Repository + ":" + img.Reference.Tag}}
+	}
+
+	if img.Config.Created != nil {
+		image.CreatedAt = *img.Config.Created
+		image.UploadedAt = *img.Config.Created
+	}
+
+	return &image, "", nil
+}
+
+// GetImageFile builds the image file of an image previously resolved through this connection by pulling its layers
+// from the registry, and returns an ImageFileResponse struct.
+//
+// The image is written as a unified image tarball into the MetaFile of the request.
+func (r *ProtocolOCI) GetImageFile(fingerprint string, req ImageFileRequest) (*ImageFileResponse, error) {
+	if req.MetaFile == nil {
+		return nil, errors.New("No file requested")
+	}
+
+	r.imagesMu.Lock()
+	img, ok := r.images[fingerprint]
+	r.imagesMu.Unlock()
+
+	if !ok {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Image %q wasn't resolved through this connection", fingerprint)
+	}
+
+	_, err := req.MetaFile.Seek(0, io.SeekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &countingWriter{Writer: req.MetaFile}
+
+	err = r.registry.Export(context.TODO(), img, w, req.ProgressHandler, req.Canceler)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImageFileResponse{
+		MetaName: img.Fingerprint() + ".tar.gz",
+		MetaSize: w.size,
+	}, nil
+}
+
+// GetImageSecret isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetImageSecret(fingerprint string) (string, error) {
+	return "", errors.New("Private images are not supported by the OCI protocol")
+}
+
+// GetPrivateImage isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImage(fingerprint string, secret string) (*api.Image, string, error) {
+	return nil, "", errors.New("Private images are not supported by the OCI protocol")
+}
+
+// GetPrivateImageFile isn't relevant for the OCI protocol.
+func (r *ProtocolOCI) GetPrivateImageFile(fingerprint string, secret string, req ImageFileRequest) (*ImageFileResponse, error) {
+	return nil, errors.New("Private images are not supported by the OCI protocol")
+}
+
+// GetImageAliases isn't supported by OCI registries as they can't list their images.
+func (r *ProtocolOCI) GetImageAliases() ([]api.ImageAliasesEntry, error) {
+	return nil, errors.New("Listing images is not supported by the OCI protocol")
+}
+
+// GetImageAliasNames isn't supported by OCI registries as they can't list their images.
+func (r *ProtocolOCI) GetImageAliasNames() ([]string, error) {
+	return nil, errors.New("Listing images is not supported by the OCI protocol")
+}
+
+// GetImageAlias returns the image reference as an ImageAliasesEntry struct targeting the resolved image.
+func (r *ProtocolOCI) GetImageAlias(name string) (*api.ImageAliasesEntry, string, error) {
+	return r.GetImageAliasType("", name)
+}
+
+// GetImageAliasType returns the image reference as an ImageAliasesEntry struct targeting the resolved image.
+func (r *ProtocolOCI) GetImageAliasType(imageType string, name string) (*api.ImageAliasesEntry, string, error) {
+	err := r.checkImageType(imageType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	img, err := r.resolve(name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	alias := api.ImageAliasesEntry{
+		Name:   name,
+		Type:   string(api.InstanceTypeContainer),
+		Target: img.Fingerprint(),
+	}
+
+	return &alias, "", nil
+}
+
+// GetImageAliasArchitectures returns a map of architectures / targets.
+func (r *ProtocolOCI) GetImageAliasArchitectures(imageType string, name string) (map[string]*api.ImageAliasesEntry, error) {
+	err := r.checkImageType(imageType)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := oci.ParseReference(name)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Image not found: %v", err)
+	}
+
+	digests, err := r.registry.Architectures(context.TODO(), *ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(digests) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Image %q has no Linux variant", name)
+	}
+
+	aliases := make(map[string]*api.ImageAliasesEntry, len(digests))
+	for architecture, digest := range digests {
+		target := *ref
+		target.Digest = digest
+
+		aliases[architecture] = &api.ImageAliasesEntry{
+			Name:   name,
+			Type:   string(api.InstanceTypeContainer),
+			Target: target.String(),
+		}
+	}
+
+	return aliases, nil
+}
+
+// ExportImage exports (copies) an image to a remote server.
+func (r *ProtocolOCI) ExportImage(fingerprint string, image api.ImageExportPost) (Operation, error) {
+	return nil, errors.New("Exporting images is not supported by the OCI protocol")
+}
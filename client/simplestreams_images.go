@@ -18,6 +18,8 @@ import (
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/vcdiff"
 )
 
 // combinedHash is the interface that the combined hash must implement.
@@ -55,6 +57,38 @@ func (w *combinedHashWriter) Write(p []byte) (int, error) {
 	return n, nil
 }
 
+// applyDelta applies the VCDIFF delta to the source file and writes the result to the target.
+// Deltas that can't be handled by the built-in decoder are applied with xdelta3 when it is available.
+func applyDelta(srcPath string, deltaPath string, target *os.File) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = src.Close() }()
+
+	delta, err := os.Open(deltaPath)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = delta.Close() }()
+
+	err = vcdiff.Decode(src, delta, target)
+	if !errors.Is(err, vcdiff.ErrUnsupported) {
+		return err
+	}
+
+	_, lookErr := exec.LookPath("xdelta3")
+	if lookErr != nil {
+		return err
+	}
+
+	_, err = shared.RunCommand(context.TODO(), "xdelta3", "-f", "-d", "-s", srcPath, deltaPath, target.Name())
+
+	return err
+}
+
 // Image handling functions
 
 // GetImages returns a list of available images as Image structs.
@@ -212,10 +246,9 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 	// Download the rootfs
 	rootfs, ok := files["root"]
 	if ok && req.RootfsFile != nil {
-		// Look for deltas (requires xdelta3)
+		// Look for deltas
 		downloaded := false
-		_, err := exec.LookPath("xdelta3")
-		if err == nil && req.DeltaSourceRetriever != nil {
+		if req.DeltaSourceRetriever != nil {
 			for filename, file := range files {
 				_, srcFingerprint, prefixFound := strings.Cut(filename, "root.delta-")
 				if !prefixFound {
@@ -258,7 +291,7 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 					return nil, err
 				}
 
-				// Create temporary file for the delta
+				// Create temporary file for the patched rootfs
 				patchedFile, err := os.CreateTemp("", "lxc_image_")
 				if err != nil {
 					return nil, err
@@ -268,7 +301,16 @@ func (r *ProtocolSimpleStreams) GetImageFile(fingerprint string, req ImageFileRe
 				defer func() { _ = os.Remove(patchedFile.Name()) }()
 
 				// Apply it
-				_, err = shared.RunCommand(context.TODO(), "xdelta3", "-f", "-d", "-s", srcPath, deltaFile.Name(), patchedFile.Name())
+				err = applyDelta(srcPath, deltaFile.Name(), patchedFile)
+				if errors.Is(err, vcdiff.ErrUnsupported) {
+					// Fallback to downloading the whole file.
+					logger.Warn("Unable to apply image delta", logger.Ctx{"fingerprint": fingerprint, "source": srcFingerprint, "err": err})
+					continue
+				} else if err != nil {
+					return nil, err
+				}
+
+				_, err = patchedFile.Seek(0, io.SeekStart)
 				if err != nil {
 					return nil, err
 				}
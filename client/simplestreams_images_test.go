@@ -12,6 +12,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -366,6 +367,108 @@ func TestGetImageFile_DeltaCombinedFingerprintValid(t *testing.T) {
 	assert.Equal(t, int64(len(newRootfs)), resp.RootfsSize)
 }
 
+// newTestDelta returns a VCDIFF delta turning the source into the target by replacing the first
+// prefixSize bytes of the source with prefix.
+func newTestDelta(source []byte, prefixSize int, prefix string, deltaIndicator byte) []byte {
+	size := len(source) - prefixSize + len(prefix)
+
+	// ADD of the prefix (code 1+size) followed by a COPY from the source (code 19 with the size in the instructions).
+	data := []byte(prefix)
+	instructions := []byte{byte(1 + len(prefix)), 19, byte(len(source) - prefixSize)}
+	addresses := []byte{byte(prefixSize)}
+
+	encoding := []byte{byte(size), deltaIndicator, byte(len(data)), byte(len(instructions)), byte(len(addresses))}
+	encoding = append(encoding, data...)
+	encoding = append(encoding, instructions...)
+	encoding = append(encoding, addresses...)
+
+	delta := []byte{0xd6, 0xc3, 0xc4, 0x00, 0x00, 0x01, byte(len(source)), 0x00, byte(len(encoding))}
+
+	return append(delta, encoding...)
+}
+
+// getImageFileWithDelta downloads the image from a server providing a delta, and returns the rootfs
+// content along with the number of full rootfs downloads.
+func getImageFileWithDelta(t *testing.T, srcRootfs []byte, newRootfs []byte, deltaContent []byte) ([]byte, int64) {
+	t.Helper()
+
+	newMeta := []byte("new-metadata-content")
+	combinedFP := computeCombinedFingerprint(newMeta, newRootfs)
+
+	server := newTestSimpleStreamsServerWithDelta(t, newMeta, srcRootfs, newRootfs, deltaContent, combinedFP)
+	defer server.Close()
+
+	var rootfsDownloads atomic.Int64
+	handler := server.Config.Handler
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/images/test/rootfs.squashfs" {
+			rootfsDownloads.Add(1)
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+
+	images := newTestSimpleStream(server)
+
+	srcCombinedFP := computeCombinedFingerprint([]byte("old-metadata"), srcRootfs)
+	srcRootfsPath := filepath.Join(t.TempDir(), "cached-rootfs.squashfs")
+	require.NoError(t, os.WriteFile(srcRootfsPath, srcRootfs, 0644))
+
+	metaFile, err := os.CreateTemp(t.TempDir(), "meta")
+	require.NoError(t, err)
+	defer metaFile.Close()
+
+	rootfsFile, err := os.CreateTemp(t.TempDir(), "rootfs")
+	require.NoError(t, err)
+	defer rootfsFile.Close()
+
+	resp, err := images.GetImageFile(combinedFP, ImageFileRequest{
+		MetaFile:   metaFile,
+		RootfsFile: rootfsFile,
+		DeltaSourceRetriever: func(fingerprint string, fname string) string {
+			if fingerprint == srcCombinedFP && fname == "rootfs" {
+				return srcRootfsPath
+			}
+
+			return ""
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(newRootfs)), resp.RootfsSize)
+
+	rootfs, err := os.ReadFile(rootfsFile.Name())
+	require.NoError(t, err)
+
+	return rootfs, rootfsDownloads.Load()
+}
+
+// TestGetImageFile_DeltaBuiltinDecoder verifies that deltas are applied without xdelta3.
+func TestGetImageFile_DeltaBuiltinDecoder(t *testing.T) {
+	srcRootfs := []byte("source-rootfs-content-for-delta-test")
+	newRootfs := []byte("new-rootfs-content-for-delta-test")
+
+	rootfs, rootfsDownloads := getImageFileWithDelta(t, srcRootfs, newRootfs, newTestDelta(srcRootfs, 6, "new", 0))
+	assert.Equal(t, newRootfs, rootfs)
+	assert.Equal(t, int64(0), rootfsDownloads)
+}
+
+// TestGetImageFile_DeltaUnsupportedFallback verifies that the whole rootfs is downloaded when the delta
+// can't be applied.
+func TestGetImageFile_DeltaUnsupportedFallback(t *testing.T) {
+	_, err := exec.LookPath("xdelta3")
+	if err == nil {
+		t.Skip("Unsupported deltas are applied with xdelta3")
+	}
+
+	srcRootfs := []byte("source-rootfs-content-for-delta-test")
+	newRootfs := []byte("new-rootfs-content-for-delta-test")
+
+	// Delta using secondary compression.
+	rootfs, rootfsDownloads := getImageFileWithDelta(t, srcRootfs, newRootfs, newTestDelta(srcRootfs, 6, "new", 1))
+	assert.Equal(t, newRootfs, rootfs)
+	assert.Equal(t, int64(1), rootfsDownloads)
+}
+
 func TestGetImageFile_DeltaPerFileHashMismatch(t *testing.T) {
 	_, err := exec.LookPath("xdelta3")
 	if err != nil && runtime.GOOS != "linux" {
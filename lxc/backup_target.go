@@ -0,0 +1,885 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v2"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
+)
+
+type cmdBackupTarget struct {
+	global *cmdGlobal
+}
+
+func (c *cmdBackupTarget) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("backup-target")
+	cmd.Short = "Manage backup targets"
+	cmd.Long = cli.FormatSection("Description", `Manage backup targets
+
+Backup targets store instance backups on S3-compatible object storage.
+Use "lxc export --backup-target" to back up an instance to a target and "lxc import --backup-target" to restore it.`)
+
+	// List.
+	backupTargetListCmd := cmdBackupTargetList{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetListCmd.command())
+
+	// Show.
+	backupTargetShowCmd := cmdBackupTargetShow{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetShowCmd.command())
+
+	// Get.
+	backupTargetGetCmd := cmdBackupTargetGet{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetGetCmd.command())
+
+	// Create.
+	backupTargetCreateCmd := cmdBackupTargetCreate{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetCreateCmd.command())
+
+	// Set.
+	backupTargetSetCmd := cmdBackupTargetSet{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetSetCmd.command())
+
+	// Unset.
+	backupTargetUnsetCmd := cmdBackupTargetUnset{global: c.global, backupTarget: c, backupTargetSet: &backupTargetSetCmd}
+	cmd.AddCommand(backupTargetUnsetCmd.command())
+
+	// Edit.
+	backupTargetEditCmd := cmdBackupTargetEdit{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetEditCmd.command())
+
+	// Delete.
+	backupTargetDeleteCmd := cmdBackupTargetDelete{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetDeleteCmd.command())
+
+	// List backups.
+	backupTargetListBackupsCmd := cmdBackupTargetListBackups{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetListBackupsCmd.command())
+
+	// Delete backup.
+	backupTargetDeleteBackupCmd := cmdBackupTargetDeleteBackup{global: c.global, backupTarget: c}
+	cmd.AddCommand(backupTargetDeleteBackupCmd.command())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+// cmdBackupTargetList handles listing backup targets.
+type cmdBackupTargetList struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+
+	flagFormat  string
+	flagColumns string
+}
+
+// columns returns the ordered column definitions for backup target list.
+func (c *cmdBackupTargetList) columns() []cli.ShorthandColumn[api.BackupTarget] {
+	return []cli.ShorthandColumn[api.BackupTarget]{
+		{Shorthand: 'n', Name: "NAME", Data: c.nameColumnData},
+		{Shorthand: 'd', Name: "DESCRIPTION", Data: c.descriptionColumnData},
+		{Shorthand: 'e', Name: "ENDPOINT", Data: c.endpointColumnData},
+		{Shorthand: 'b', Name: "BUCKET", Data: c.bucketColumnData},
+		{Shorthand: 'p', Name: "PREFIX", Data: c.prefixColumnData},
+	}
+}
+
+func (c *cmdBackupTargetList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", "[<remote>:]")
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = "List backup targets"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact)"))
+	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", cli.DefaultColumnString(c.columns()), cli.FormatStringFlagLabel("Columns"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, ":", true, instanceServerRemoteCompletionFilters(*c.global.conf)...)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return errors.New("Filtering is not supported yet")
+	}
+
+	targets, err := resource.server.GetBackupTargets()
+	if err != nil {
+		return err
+	}
+
+	columns, err := cli.ParseShorthandColumns(c.flagColumns, c.columns())
+	if err != nil {
+		return err
+	}
+
+	data := cli.ColumnData(columns, targets)
+	sort.Sort(cli.SortColumnsNaturally(data))
+	header := cli.ColumnHeaders(columns)
+
+	return cli.RenderTable(c.flagFormat, header, data, targets)
+}
+
+func (c *cmdBackupTargetList) nameColumnData(target api.BackupTarget) string {
+	return target.Name
+}
+
+func (c *cmdBackupTargetList) descriptionColumnData(target api.BackupTarget) string {
+	return target.Description
+}
+
+func (c *cmdBackupTargetList) endpointColumnData(target api.BackupTarget) string {
+	return target.Config["endpoint"]
+}
+
+func (c *cmdBackupTargetList) bucketColumnData(target api.BackupTarget) string {
+	return target.Config["bucket"]
+}
+
+func (c *cmdBackupTargetList) prefixColumnData(target api.BackupTarget) string {
+	return target.Config["prefix"]
+}
+
+// Show.
+type cmdBackupTargetShow struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+}
+
+func (c *cmdBackupTargetShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", "[<remote>:]<target>")
+	cmd.Short = "Show backup target configurations"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	// Show the backup target config.
+	target, _, err := resource.server.GetBackupTarget(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdBackupTargetGet struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+
+	flagIsProperty bool
+}
+
+func (c *cmdBackupTargetGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", "[<remote>:]<target> <key>")
+	cmd.Short = "Get value for backup target configuration key"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Get the key as a backup target property")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	resp, _, err := resource.server.GetBackupTarget(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJSONTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf("The property %q does not exist on the backup target %q: %v", args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		v, ok := resp.Config[args[1]]
+		if ok {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdBackupTargetCreate struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+
+	flagDescription string
+}
+
+func (c *cmdBackupTargetCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", "[<remote>:]<target> [key=value...]")
+	cmd.Short = "Create backup targets"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.Example = cli.FormatSection("", `lxc backup-target create s3 endpoint=https://s3.example.com bucket=backups access_key=ACCESSKEY secret_key=SECRETKEY
+    Create a backup target storing the backups in the "backups" bucket.
+
+lxc backup-target create s3 < config.yaml
+    Create a backup target with configuration from config.yaml`)
+
+	cmd.Flags().StringVar(&c.flagDescription, "description", "", cli.FormatStringFlagLabel("Backup target description"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, ":", true, instanceServerRemoteCompletionFilters(*c.global.conf)...)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var targetPut api.BackupTargetPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &targetPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	if targetPut.Config == nil {
+		targetPut.Config = map[string]string{}
+	}
+
+	// Get configuration keys from the command line.
+	for i := 1; i < len(args); i++ {
+		entry := strings.SplitN(args[i], "=", 2)
+		if len(entry) < 2 {
+			return fmt.Errorf("Bad key=value pair: %q", args[i])
+		}
+
+		targetPut.Config[entry[0]] = entry[1]
+	}
+
+	if c.flagDescription != "" {
+		targetPut.Description = c.flagDescription
+	}
+
+	// Create the backup target.
+	target := api.BackupTargetsPost{
+		Name:            resource.name,
+		BackupTargetPut: targetPut,
+	}
+
+	err = resource.server.CreateBackupTarget(target)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Backup target %s created\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdBackupTargetSet struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+
+	flagIsProperty bool
+}
+
+func (c *cmdBackupTargetSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", "[<remote>:]<target> <key>=<value>...")
+	cmd.Short = "Set backup target configuration keys"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Set the key as a backup target property")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	// Get the backup target.
+	target, etag, err := resource.server.GetBackupTarget(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := target.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJSONTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf("Error unsetting property: %v", err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf("Error setting properties: %v", err)
+			}
+		}
+	} else {
+		if writable.Config == nil {
+			writable.Config = map[string]string{}
+		}
+
+		maps.Copy(writable.Config, keys)
+	}
+
+	return resource.server.UpdateBackupTarget(resource.name, writable, etag)
+}
+
+// Unset.
+type cmdBackupTargetUnset struct {
+	global          *cmdGlobal
+	backupTarget    *cmdBackupTarget
+	backupTargetSet *cmdBackupTargetSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdBackupTargetUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", "[<remote>:]<target> <key>")
+	cmd.Short = "Unset backup target configuration keys"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Unset the key as a backup target property")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.backupTargetSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.backupTargetSet.run(cmd, args)
+}
+
+// Edit.
+type cmdBackupTargetEdit struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+}
+
+func (c *cmdBackupTargetEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", "[<remote>:]<target>")
+	cmd.Short = "Edit backup target configurations as YAML"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetEdit) helpTemplate() string {
+	return `### This is a YAML representation of the backup target.
+### Any line starting with a '#' will be ignored.
+###
+### A backup target consists of a set of configuration items.
+###
+### An example would look like:
+### name: s3
+### description: Offsite backups
+### config:
+###   endpoint: https://s3.example.com
+###   bucket: backups
+###   access_key: ACCESSKEY
+###   secret_key: SECRETKEY
+###   prefix: lxd
+###
+### Note that only the description and configuration keys can be changed.`
+}
+
+func (c *cmdBackupTargetEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc backup-target show` command to be passed in here, but only take the
+		// contents of the writable fields when updating the target. The other fields are silently discarded.
+		newdata := api.BackupTarget{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		return resource.server.UpdateBackupTarget(resource.name, newdata.Writable(), "")
+	}
+
+	// Get the current config.
+	target, etag, err := resource.server.GetBackupTarget(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&target)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.BackupTarget{} // We show the full target info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			err = resource.server.UpdateBackupTarget(resource.name, newdata.Writable(), etag)
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Config parsing error: %s\n", err)
+			fmt.Println("Press enter to open the editor again or ctrl+c to abort change")
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdBackupTargetDelete struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+}
+
+func (c *cmdBackupTargetDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", "[<remote>:]<target>")
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = "Delete backup targets"
+	cmd.Long = cli.FormatSection("Description", `Delete backup targets
+
+The backups stored on the target are left in place.`)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	// Delete the backup target.
+	err = resource.server.DeleteBackupTarget(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Backup target %s deleted\n", resource.name)
+	}
+
+	return nil
+}
+
+// List backups.
+// cmdBackupTargetListBackups handles listing the backups stored on a backup target.
+type cmdBackupTargetListBackups struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+
+	flagFormat  string
+	flagColumns string
+}
+
+// columns returns the ordered column definitions for backup target backup list.
+func (c *cmdBackupTargetListBackups) columns() []cli.ShorthandColumn[api.BackupTargetBackup] {
+	return []cli.ShorthandColumn[api.BackupTargetBackup]{
+		{Shorthand: 'n', Name: "NAME", Data: c.nameColumnData},
+		{Shorthand: 's', Name: "SIZE", Data: c.sizeColumnData},
+		{Shorthand: 'c', Name: "CREATED AT", Data: c.createdAtColumnData},
+	}
+}
+
+func (c *cmdBackupTargetListBackups) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list-backups", "[<remote>:]<target> [<prefix>]")
+	cmd.Short = "List the backups stored on backup targets"
+	cmd.Long = cli.FormatSection("Description", `List the backups stored on backup targets
+
+Backups are named after their project, instance and backup names, for example "default/c1/backup0".
+The optional prefix only lists the matching backups, for example "default/c1/".`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact)"))
+	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", cli.DefaultColumnString(c.columns()), cli.FormatStringFlagLabel("Columns"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetListBackups) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	prefix := ""
+	if len(args) > 1 {
+		prefix = args[1]
+	}
+
+	backups, err := resource.server.GetBackupTargetBackups(resource.name, prefix)
+	if err != nil {
+		return err
+	}
+
+	columns, err := cli.ParseShorthandColumns(c.flagColumns, c.columns())
+	if err != nil {
+		return err
+	}
+
+	data := cli.ColumnData(columns, backups)
+	sort.Sort(cli.SortColumnsNaturally(data))
+	header := cli.ColumnHeaders(columns)
+
+	return cli.RenderTable(c.flagFormat, header, data, backups)
+}
+
+func (c *cmdBackupTargetListBackups) nameColumnData(backup api.BackupTargetBackup) string {
+	return backup.Name
+}
+
+func (c *cmdBackupTargetListBackups) sizeColumnData(backup api.BackupTargetBackup) string {
+	return units.GetByteSizeStringIEC(backup.Size, 2)
+}
+
+func (c *cmdBackupTargetListBackups) createdAtColumnData(backup api.BackupTargetBackup) string {
+	const layout = "2006/01/02 15:04 MST"
+
+	return backup.CreatedAt.Local().Format(layout)
+}
+
+// Delete backup.
+type cmdBackupTargetDeleteBackup struct {
+	global       *cmdGlobal
+	backupTarget *cmdBackupTarget
+}
+
+func (c *cmdBackupTargetDeleteBackup) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete-backup", "[<remote>:]<target> <backup>")
+	cmd.Short = "Delete backups stored on backup targets"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.Example = cli.FormatSection("", `lxc backup-target delete-backup s3 default/c1/backup0
+    Delete the backup0 backup of the c1 instance of the default project from the s3 backup target.`)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("backup_target", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdBackupTargetDeleteBackup) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing backup target name")
+	}
+
+	err = resource.server.DeleteBackupTargetBackup(resource.name, args[1])
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Backup %s deleted from backup target %s\n", args[1], resource.name)
+	}
+
+	return nil
+}
@@ -94,6 +94,10 @@ func (c *cmdCluster) command() *cobra.Command {
 	cmdClusterRestore := cmdClusterRestore{global: c.global, cluster: c}
 	cmd.AddCommand(cmdClusterRestore.command())
 
+	// Rebalance instances across cluster members
+	cmdClusterRebalance := cmdClusterRebalance{global: c.global, cluster: c}
+	cmd.AddCommand(cmdClusterRebalance.command())
+
 	clusterGroupCmd := cmdClusterGroup{global: c.global, cluster: c}
 	cmd.AddCommand(clusterGroupCmd.command())
 
@@ -1344,6 +1348,116 @@ func (c *cmdClusterRestore) command() *cobra.Command {
 	return cmd
 }
 
+// Cluster instance rebalancing.
+type cmdClusterRebalance struct {
+	global  *cmdGlobal
+	cluster *cmdCluster
+
+	flagDryRun bool
+	flagFormat string
+}
+
+func (c *cmdClusterRebalance) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rebalance", "[<remote>:]")
+	cmd.Short = "Rebalance instances across cluster members"
+	cmd.Long = cli.FormatSection("Description", cmd.Short+`
+
+Instances are moved from the most to the least loaded cluster members until the
+difference is below cluster.rebalance.threshold or cluster.rebalance.batch
+instances have been moved.
+
+Use --dry-run to show the planned moves without moving any instance.`)
+
+	cmd.Flags().BoolVar(&c.flagDryRun, "dry-run", false, "Show the planned moves without moving any instance")
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact), only used with --dry-run"))
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, ":", true, instanceServerRemoteCompletionFilters(*c.global.conf)...)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdClusterRebalance) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) == 1 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if c.flagDryRun {
+		moves, err := resource.server.GetClusterRebalancePlan()
+		if err != nil {
+			return err
+		}
+
+		data := make([][]string, 0, len(moves))
+		for _, move := range moves {
+			live := "NO"
+			if move.Live {
+				live = "YES"
+			}
+
+			data = append(data, []string{move.Project, move.Instance, move.Source, move.Target, live})
+		}
+
+		header := []string{
+			"PROJECT",
+			"INSTANCE",
+			"SOURCE",
+			"TARGET",
+			"LIVE",
+		}
+
+		return cli.RenderTable(c.flagFormat, header, data, moves)
+	}
+
+	op, err := resource.server.RebalanceCluster()
+	if err != nil {
+		return err
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: "Rebalancing instances: %s",
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+	return nil
+}
+
 func (c *cmdClusterEvacuateAction) command() *cobra.Command {
 	cmd := &cobra.Command{}
 	cmd.RunE = c.run
@@ -43,6 +43,9 @@ func completionsFor(names []string, suffix string, toComplete string) []string {
 // topLevelInstanceServerResourceNameFuncs is a map of functions that can return LXD API resource names without any arguments.
 // This is used when returning completions for arguments like `<remote>:<name>` where the remote is an instance server.
 var topLevelInstanceServerResourceNameFuncs = map[string]func(server lxd.InstanceServer) ([]string, error){
+	"backup_target": func(server lxd.InstanceServer) ([]string, error) {
+		return server.GetBackupTargetNames()
+	},
 	"certificate": func(server lxd.InstanceServer) ([]string, error) {
 		return server.GetCertificateFingerprints()
 	},
@@ -76,6 +79,12 @@ var topLevelInstanceServerResourceNameFuncs = map[string]func(server lxd.Instanc
 	"network_acl": func(server lxd.InstanceServer) ([]string, error) {
 		return server.GetNetworkACLNames()
 	},
+	"network_address_set": func(server lxd.InstanceServer) ([]string, error) {
+		return server.GetNetworkAddressSetNames()
+	},
+	"network_port_set": func(server lxd.InstanceServer) ([]string, error) {
+		return server.GetNetworkPortSetNames()
+	},
 	"network_zone": func(server lxd.InstanceServer) ([]string, error) {
 		return server.GetNetworkZoneNames()
 	},
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -22,6 +23,7 @@ type cmdExport struct {
 	flagOptimizedStorage     bool
 	flagCompressionAlgorithm string
 	flagExportVersion        string
+	flagBackupTarget         string
 }
 
 func (c *cmdExport) command() *cobra.Command {
@@ -30,7 +32,10 @@ func (c *cmdExport) command() *cobra.Command {
 	cmd.Short = "Export instance backups"
 	cmd.Long = cli.FormatSection("Description", `Export instances as backup tarballs.`)
 	cmd.Example = cli.FormatSection("", `lxc export u1 backup0.tar.gz
-    Download a backup tarball of the u1 instance.`)
+    Download a backup tarball of the u1 instance.
+
+lxc export u1 --backup-target s3
+    Store a backup of the u1 instance on the s3 backup target.`)
 
 	cmd.RunE = c.run
 	cmd.Flags().BoolVar(&c.flagInstanceOnly, "instance-only", false,
@@ -39,6 +44,7 @@ func (c *cmdExport) command() *cobra.Command {
 	cmd.Flags().StringVar(&c.flagCompressionAlgorithm, "compression", "", cli.FormatStringFlagLabel(`Compression algorithm to use (none for uncompressed)`))
 	cmd.Flags().StringVar(&c.flagExportVersion, "export-version", "",
 		cli.FormatStringFlagLabel("Use a different metadata format version than the latest one supported by the server (to support imports on older LXD versions)"))
+	cmd.Flags().StringVar(&c.flagBackupTarget, "backup-target", "", cli.FormatStringFlagLabel("Store the backup on this backup target instead of downloading it"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		if len(args) > 0 {
@@ -73,6 +79,14 @@ func (c *cmdExport) run(cmd *cobra.Command, args []string) error {
 
 	instanceOnly := c.flagInstanceOnly
 
+	if c.flagBackupTarget != "" {
+		if len(args) > 1 {
+			return errors.New("A target file cannot be used with --backup-target")
+		}
+
+		return c.runBackupTarget(d, name)
+	}
+
 	req := api.InstanceBackupsPost{
 		Name:                 "",
 		ExpiresAt:            time.Now().Add(24 * time.Hour),
@@ -212,3 +226,53 @@ func (c *cmdExport) run(cmd *cobra.Command, args []string) error {
 	exportProgress.Done("Backup exported successfully!")
 	return nil
 }
+
+// runBackupTarget stores a backup of the instance on the backup target.
+func (c *cmdExport) runBackupTarget(d lxd.InstanceServer, name string) error {
+	req := api.InstanceBackupsPost{
+		ContainerOnly:        c.flagInstanceOnly,
+		InstanceOnly:         c.flagInstanceOnly,
+		OptimizedStorage:     c.flagOptimizedStorage,
+		CompressionAlgorithm: c.flagCompressionAlgorithm,
+		BackupTarget:         c.flagBackupTarget,
+	}
+
+	var err error
+	req.Version, err = getExportVersion(d, c.flagExportVersion)
+	if err != nil {
+		return err
+	}
+
+	op, err := d.CreateInstanceBackup(name, req)
+	if err != nil {
+		return fmt.Errorf("Create instance backup: %w", err)
+	}
+
+	// Watch the background operation
+	progress := cli.ProgressRenderer{
+		Format: "Backing up instance: %s",
+		Quiet:  c.global.flagQuiet,
+	}
+
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	// Wait until backup is done
+	err = cli.CancelableWait(op, &progress)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	backupName, err := getEntityFromOperationMetadata(op.Get().Metadata)
+	if err != nil {
+		progress.Done("")
+		return fmt.Errorf("Failed getting backup name from operation: %w", err)
+	}
+
+	progress.Done(fmt.Sprintf("Backup %s stored on backup target %s", backupName, c.flagBackupTarget))
+	return nil
+}
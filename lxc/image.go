@@ -19,6 +19,7 @@ import (
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/simplestreams"
 	"github.com/canonical/lxd/shared/termios"
 )
 
@@ -66,6 +67,10 @@ hash or alias name (if one is set).`)
 	imageExportCmd := cmdImageExport{global: c.global, image: c}
 	cmd.AddCommand(imageExportCmd.command())
 
+	// Export streams
+	imageExportStreamsCmd := cmdImageExportStreams{global: c.global, image: c}
+	cmd.AddCommand(imageExportStreamsCmd.command())
+
 	// Import
 	imageImportCmd := cmdImageImport{global: c.global, image: c}
 	cmd.AddCommand(imageImportCmd.command())
@@ -649,6 +654,248 @@ func (c *cmdImageExport) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// Export streams.
+type cmdImageExportStreams struct {
+	global *cmdGlobal
+	image  *cmdImage
+
+	flagAliases []string
+	flagVM      bool
+	flagServer  bool
+}
+
+func (c *cmdImageExportStreams) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("export-streams", "<directory> [<remote>:]<image>...")
+	cmd.Short = "Export images to a simplestreams tree"
+	cmd.Long = cli.FormatSection("Description", cmd.Short+`
+
+The images are added to the simplestreams tree stored in the directory, creating it if needed.
+Images already in the tree aren't downloaded again.
+The directory can then be served by any web server and added as a remote with --protocol=simplestreams.
+
+The images are published under their aliases, unless aliases are specified for a single image.
+
+With --server, the tree is written to the directory on the server holding the images instead.`)
+	cmd.Example = cli.FormatSection("", `lxc image export-streams /srv/images ubuntu:24.04 local:my-image
+    Add the Ubuntu 24.04 image and the local "my-image" image to the tree in /srv/images.
+
+lxc image export-streams /srv/images local:my-image --alias my-distro/2.0
+    Add the local "my-image" image to the tree in /srv/images, published under the "my-distro/2.0" alias.
+
+lxc image export-streams /srv/images local:my-image --server
+    Add the local "my-image" image to the tree in /srv/images on the local server.`)
+
+	cmd.Flags().StringArrayVar(&c.flagAliases, "alias", nil, cli.FormatStringFlagLabel("Aliases to publish the images under"))
+	cmd.Flags().BoolVar(&c.flagVM, "vm", false, "Query virtual machine images")
+	cmd.Flags().BoolVar(&c.flagServer, "server", false, "Write the tree to the directory on the server holding the images")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return nil, cobra.ShellCompDirectiveFilterDirs
+		}
+
+		return c.global.cmpImages(toComplete, false)
+	}
+
+	return cmd
+}
+
+func (c *cmdImageExportStreams) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Aliases given on the command line can only identify a single image.
+	if len(c.flagAliases) > 0 && len(args) > 2 {
+		return errors.New("--alias only works with a single image")
+	}
+
+	imageType := ""
+	if c.flagVM {
+		imageType = "virtual-machine"
+	}
+
+	if c.flagServer {
+		return c.runServer(args[0], args[1:], imageType)
+	}
+
+	target := shared.HostPathFollow(args[0])
+
+	tree, err := simplestreams.OpenTree(target)
+	if err != nil {
+		return err
+	}
+
+	for _, arg := range args[1:] {
+		remoteName, name, err := c.global.conf.ParseRemote(arg)
+		if err != nil {
+			return err
+		}
+
+		remoteServer, err := c.global.conf.GetImageServer(remoteName)
+		if err != nil {
+			return err
+		}
+
+		image, _, err := c.image.dereferenceAlias(remoteServer, imageType, name)
+		if err != nil {
+			return err
+		}
+
+		aliases := c.flagAliases
+		if len(aliases) == 0 {
+			for _, alias := range image.Aliases {
+				aliases = append(aliases, alias.Name)
+			}
+		}
+
+		// Only update the aliases of images already in the tree.
+		if tree.HasImage(image.Fingerprint) {
+			err = tree.AddAliases(image.Fingerprint, aliases)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = c.export(remoteServer, tree, target, name, image, aliases)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = tree.Save()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runServer has the server holding the images add them to the tree stored in the directory on the server.
+func (c *cmdImageExportStreams) runServer(target string, names []string, imageType string) error {
+	resources, err := c.global.ParseServers(names...)
+	if err != nil {
+		return err
+	}
+
+	req := api.ImagesStreamsPost{
+		Path: target,
+	}
+
+	for _, resource := range resources {
+		if resource.remote != resources[0].remote {
+			return errors.New("--server requires all images to be on the same server")
+		}
+
+		image, _, err := c.image.dereferenceAlias(resource.server, imageType, resource.name)
+		if err != nil {
+			return err
+		}
+
+		req.Images = append(req.Images, api.ImagesStreamsPostImage{
+			Fingerprint: image.Fingerprint,
+			Aliases:     c.flagAliases,
+		})
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: "Exporting the images: %s",
+		Quiet:  c.global.flagQuiet,
+	}
+
+	op, err := resources[0].server.ExportImagesStreams(req)
+	if err != nil {
+		return err
+	}
+
+	// Register progress handler
+	_, err = op.AddHandler(progress.UpdateOp)
+	if err != nil {
+		return err
+	}
+
+	// Wait for the export to happen
+	err = op.Wait()
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("Images exported successfully!")
+	return nil
+}
+
+// export downloads the image into the directory of the tree and adds it to the tree.
+func (c *cmdImageExportStreams) export(remoteServer lxd.ImageServer, tree *simplestreams.Tree, target string, name string, image *api.Image, aliases []string) error {
+	// Download next to the tree so the files can be moved into it.
+	metaFile, err := os.CreateTemp(target, ".lxc_image_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(metaFile.Name()) }()
+	defer func() { _ = metaFile.Close() }()
+
+	rootfsFile, err := os.CreateTemp(target, ".lxc_image_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(rootfsFile.Name()) }()
+	defer func() { _ = rootfsFile.Close() }()
+
+	progress := cli.ProgressRenderer{
+		Format: "Exporting " + name + ": %s",
+		Quiet:  c.global.flagQuiet,
+	}
+
+	req := lxd.ImageFileRequest{
+		MetaFile:        io.WriteSeeker(metaFile),
+		RootfsFile:      io.WriteSeeker(rootfsFile),
+		ProgressHandler: progress.UpdateProgress,
+	}
+
+	resp, err := remoteServer.GetImageFile(image.Fingerprint, req)
+	if err != nil {
+		progress.Done("")
+		return err
+	}
+
+	progress.Done("")
+
+	// Truncate down to size
+	err = metaFile.Truncate(resp.MetaSize)
+	if err != nil {
+		return err
+	}
+
+	err = rootfsFile.Truncate(resp.RootfsSize)
+	if err != nil {
+		return err
+	}
+
+	err = metaFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = rootfsFile.Close()
+	if err != nil {
+		return err
+	}
+
+	rootfsPath := ""
+	if resp.RootfsSize > 0 {
+		rootfsPath = rootfsFile.Name()
+	}
+
+	return tree.AddImage(*image, aliases, metaFile.Name(), rootfsPath)
+}
+
 // Import.
 type cmdImageImport struct {
 	global *cmdGlobal
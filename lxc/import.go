@@ -8,6 +8,7 @@ import (
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/ioprogress"
 )
@@ -15,21 +16,26 @@ import (
 type cmdImport struct {
 	global *cmdGlobal
 
-	flagStorage string
-	flagDevice  []string
+	flagStorage      string
+	flagDevice       []string
+	flagBackupTarget string
 }
 
 func (c *cmdImport) command() *cobra.Command {
 	cmd := &cobra.Command{}
-	cmd.Use = usage("import", "[<remote>:] <backup file> [<instance name>]")
+	cmd.Use = usage("import", "[<remote>:] <backup file>|<backup> [<instance name>]")
 	cmd.Short = "Import instance backups"
 	cmd.Long = cli.FormatSection("Description", `Import backups of instances including their snapshots.`)
 	cmd.Example = cli.FormatSection("", `lxc import backup0.tar.gz
-    Create a new instance using backup0.tar.gz as the source.`)
+    Create a new instance using backup0.tar.gz as the source.
+
+lxc import default/c1/backup0 c2 --backup-target s3
+    Create a new instance c2 from the backup0 backup of the c1 instance stored on the s3 backup target.`)
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagStorage, "storage", "s", "", cli.FormatStringFlagLabel("Storage pool name"))
 	cmd.Flags().StringArrayVarP(&c.flagDevice, "device", "d", nil, cli.FormatStringFlagLabel("New key/value to apply to a specific device"))
+	cmd.Flags().StringVar(&c.flagBackupTarget, "backup-target", "", cli.FormatStringFlagLabel("Import the backup from this backup target"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
 		if len(args) > 1 {
@@ -83,6 +89,10 @@ func (c *cmdImport) run(cmd *cobra.Command, args []string) error {
 
 	resource := resources[0]
 
+	if c.flagBackupTarget != "" {
+		return c.runBackupTarget(resource.server, srcFile, instanceName)
+	}
+
 	var file *os.File
 	if srcFile == "-" {
 		file = os.Stdin
@@ -133,3 +143,47 @@ func (c *cmdImport) run(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runBackupTarget creates an instance from a backup stored on the backup target.
+func (c *cmdImport) runBackupTarget(server lxd.InstanceServer, backupName string, instanceName string) error {
+	deviceMap, err := parseDeviceOverrides(c.flagDevice)
+	if err != nil {
+		return err
+	}
+
+	// The storage pool is selected through the root disk device.
+	if c.flagStorage != "" {
+		if deviceMap["root"] == nil {
+			deviceMap["root"] = map[string]string{}
+		}
+
+		deviceMap["root"]["pool"] = c.flagStorage
+	}
+
+	req := api.InstancesPost{
+		Name: instanceName,
+		InstancePut: api.InstancePut{
+			Devices: deviceMap,
+		},
+		Source: api.InstanceSource{
+			Type:         api.SourceTypeBackup,
+			BackupTarget: c.flagBackupTarget,
+			Backup:       backupName,
+		},
+	}
+
+	progress := cli.ProgressRenderer{
+		Format: "Importing instance: %s",
+		Quiet:  c.global.flagQuiet,
+	}
+
+	defer progress.Done("")
+
+	op, err := server.CreateInstance(req)
+	if err != nil {
+		return err
+	}
+
+	// Wait for operation to finish.
+	return cli.CancelableWait(op, &progress)
+}
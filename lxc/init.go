@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -33,6 +34,7 @@ type cmdInit struct {
 	flagNoProfiles    bool
 	flagEmpty         bool
 	flagVM            bool
+	flagKrun          bool
 }
 
 func (c *cmdInit) command() *cobra.Command {
@@ -71,6 +73,7 @@ with --project and the instance remote with --target-project (if different from
 	cmd.Flags().BoolVar(&c.flagNoProfiles, "no-profiles", false, "Create the instance with no profiles applied")
 	cmd.Flags().BoolVar(&c.flagEmpty, "empty", false, "Create an empty instance")
 	cmd.Flags().BoolVar(&c.flagVM, "vm", false, "Create a virtual machine")
+	cmd.Flags().BoolVar(&c.flagKrun, "krun", false, "Create a krun microVM")
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) > 1 {
@@ -269,8 +272,14 @@ func (c *cmdInit) create(conf *config.Config, args []string, launch bool) (lxd.I
 
 	// Decide whether we are creating a container or a virtual machine.
 	instanceDBType := api.InstanceTypeContainer
+	if c.flagVM && c.flagKrun {
+		return nil, "", errors.New("--vm and --krun can't be used together")
+	}
+
 	if c.flagVM {
 		instanceDBType = api.InstanceTypeVM
+	} else if c.flagKrun {
+		instanceDBType = api.InstanceTypeKrun
 	}
 
 	// Set the target if provided.
@@ -357,12 +366,19 @@ func (c *cmdInit) create(conf *config.Config, args []string, launch bool) (lxd.I
 			return nil, "", err
 		}
 
-		if conf.Remotes[iremote].Protocol != "simplestreams" {
+		if !slices.Contains([]string{"simplestreams", "oci"}, conf.Remotes[iremote].Protocol) {
 			if imgInfo.Type != "virtual-machine" && c.flagVM {
 				return nil, "", errors.New("Asked for a VM but image is of type container")
 			}
 
-			req.Type = api.InstanceType(imgInfo.Type)
+			if imgInfo.Type != "container" && c.flagKrun {
+				return nil, "", errors.New("Asked for a krun microVM but image is of type virtual-machine")
+			}
+
+			// Krun microVMs boot container images so keep the requested type.
+			if !c.flagKrun {
+				req.Type = api.InstanceType(imgInfo.Type)
+			}
 		}
 
 		// Create the instance.
@@ -39,6 +39,8 @@ type cmdList struct {
 	flagFast        bool
 	flagFormat      string
 	flagAllProjects bool
+	flagSort        string
+	flagPageSize    int
 
 	shorthandFilters map[string]func(*api.Instance, *api.InstanceState, string) bool
 }
@@ -126,13 +128,18 @@ Custom columns are defined with "[config:|devices:]key[:name][:maxWidth]":
   "ETHP" is a custom column generated from a device key.
 
 lxc list -c ns,user.comment:comment
-  List instances with their running state and user comment.`)
+  List instances with their running state and user comment.
+
+lxc list --sort=-created_at,name --page-size=100
+  List instances from newest to oldest, fetching them from the server 100 at a time.`)
 
 	cmd.RunE = c.run
 	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", defaultColumns, cli.FormatStringFlagLabel("Columns"))
 	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact)"))
 	cmd.Flags().BoolVar(&c.flagFast, "fast", false, "Fast mode (same as --columns=nsacPt)")
 	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, "Display instances from all projects")
+	cmd.Flags().StringVar(&c.flagSort, "sort", "", cli.FormatStringFlagLabel("Server side sort order (comma separated fields, prefixed with - for descending order)"))
+	cmd.Flags().IntVar(&c.flagPageSize, "page-size", 0, cli.FormatStringFlagLabel("Number of instances to fetch per request (0 fetches all at once)"))
 
 	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -430,7 +437,10 @@ func (c *cmdList) showInstances(instances []api.InstanceFull, filters []string,
 		data = append(data, col)
 	}
 
-	sort.Sort(cli.SortColumnsNaturally(data))
+	// Keep the server side order when one was requested.
+	if c.flagSort == "" {
+		sort.Sort(cli.SortColumnsNaturally(data))
+	}
 
 	headers := make([]string, 0, len(columns))
 	for _, column := range columns {
@@ -453,6 +463,10 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 		return errors.New("Cannot specify --project with --all-projects")
 	}
 
+	if c.flagPageSize < 0 {
+		return errors.New("Invalid page size")
+	}
+
 	// Parse the remote
 	var remote string
 	var name string
@@ -530,15 +544,19 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 		}
 
 		// Use the unified GetInstancesFull API.
-		instances, err = d.GetInstancesFull(lxd.GetInstancesFullArgs{
+		instancesArgs := lxd.GetInstancesFullArgs{
 			InstanceType: api.InstanceTypeAny,
 			Filters:      serverFilters,
 			AllProjects:  c.flagAllProjects,
 			Fields:       recursionFields,
-		})
+		}
 
-		if err != nil {
-			return err
+		for inst, err := range d.GetInstancesFullIter(instancesArgs, c.pageArgs()) {
+			if err != nil {
+				return err
+			}
+
+			instances = append(instances, inst)
 		}
 
 		return c.showInstances(instances, clientFilters, columns)
@@ -548,14 +566,18 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 	var instances []api.Instance
 	serverFilters, clientFilters := getServerSupportedFilters(filters, api.Instance{})
 
-	instances, err = d.GetInstances(lxd.GetInstancesArgs{
+	instancesArgs := lxd.GetInstancesArgs{
 		InstanceType: api.InstanceTypeAny,
 		Filters:      serverFilters,
 		AllProjects:  c.flagAllProjects,
-	})
+	}
 
-	if err != nil {
-		return err
+	for inst, err := range d.GetInstancesIter(instancesArgs, c.pageArgs()) {
+		if err != nil {
+			return err
+		}
+
+		instances = append(instances, inst)
 	}
 
 	// Apply filters
@@ -572,6 +594,16 @@ func (c *cmdList) run(cmd *cobra.Command, args []string) error {
 	return c.listInstances(d, instancesFiltered, clientFilters, columns)
 }
 
+// pageArgs returns the server side sorting and pagination arguments.
+func (c *cmdList) pageArgs() lxd.PageArgs {
+	page := lxd.PageArgs{Limit: c.flagPageSize}
+	if c.flagSort != "" {
+		page.Sort = strings.Split(c.flagSort, ",")
+	}
+
+	return page
+}
+
 func (c *cmdList) parseColumns(clustered bool) ([]column, bool, error) {
 	columnsShorthandMap := map[rune]column{
 		'4': {"IPV4", c.ipv4ColumnData, true, false, false, true},
@@ -886,6 +918,8 @@ func (c *cmdList) typeColumnData(cInfo api.InstanceFull) string {
 	instType := "CONTAINER"
 	if cInfo.Type == string(api.InstanceTypeVM) {
 		instType = "VIRTUAL-MACHINE"
+	} else if cInfo.Type == string(api.InstanceTypeKrun) {
+		instType = "KRUN"
 	}
 
 	if cInfo.Ephemeral {
@@ -148,6 +148,10 @@ For help with any of those, simply call them with --help.`)
 	aliasCmd := cmdAlias{global: &globalCmd}
 	app.AddCommand(aliasCmd.command())
 
+	// backup-target sub-command
+	backupTargetCmd := cmdBackupTarget{global: &globalCmd}
+	app.AddCommand(backupTargetCmd.command())
+
 	// cluster sub-command
 	clusterCmd := cmdCluster{global: &globalCmd}
 	app.AddCommand(clusterCmd.command())
@@ -284,6 +288,10 @@ For help with any of those, simply call them with --help.`)
 	stopCmd := cmdStop{global: &globalCmd}
 	app.AddCommand(stopCmd.command())
 
+	// top sub-command
+	topCmd := cmdTop{global: &globalCmd}
+	app.AddCommand(topCmd.command())
+
 	// version sub-command
 	versionCmd := cmdVersion{global: &globalCmd}
 	app.AddCommand(versionCmd.command())
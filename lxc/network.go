@@ -103,6 +103,14 @@ func (c *cmdNetwork) command() *cobra.Command {
 	networkACLCmd := cmdNetworkACL{global: c.global}
 	cmd.AddCommand(networkACLCmd.command())
 
+	// Address set
+	networkAddressSetCmd := cmdNetworkAddressSet{global: c.global}
+	cmd.AddCommand(networkAddressSetCmd.command())
+
+	// Port set
+	networkPortSetCmd := cmdNetworkPortSet{global: c.global}
+	cmd.AddCommand(networkPortSetCmd.command())
+
 	// Forward
 	networkForwardCmd := cmdNetworkForward{global: c.global}
 	cmd.AddCommand(networkForwardCmd.command())
@@ -1005,6 +1013,18 @@ func (c *cmdNetworkInfo) run(cmd *cobra.Command, args []string) error {
 		fmt.Printf("  Chassis: %s\n", state.OVN.Chassis)
 	}
 
+	// WireGuard tunnel information.
+	if len(state.WireGuard) > 0 {
+		fmt.Println("")
+		fmt.Println("WireGuard tunnels:")
+		for _, name := range slices.Sorted(maps.Keys(state.WireGuard)) {
+			tunnel := state.WireGuard[name]
+			fmt.Printf("  %s:\n", name)
+			fmt.Printf("    Public key: %s\n", tunnel.PublicKey)
+			fmt.Printf("    Listen port: %d\n", tunnel.ListenPort)
+		}
+	}
+
 	return nil
 }
 
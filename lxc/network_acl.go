@@ -43,6 +43,10 @@ func (c *cmdNetworkACL) command() *cobra.Command {
 	networkACLShowLogCmd := cmdNetworkACLShowLog{global: c.global, networkACL: c}
 	cmd.AddCommand(networkACLShowLogCmd.command())
 
+	// Show state.
+	networkACLShowStateCmd := cmdNetworkACLShowState{global: c.global, networkACL: c}
+	cmd.AddCommand(networkACLShowStateCmd.command())
+
 	// Get.
 	networkACLGetCmd := cmdNetworkACLGet{global: c.global, networkACL: c}
 	cmd.AddCommand(networkACLGetCmd.command())
@@ -318,6 +322,90 @@ func (c *cmdNetworkACLShowLog) run(cmd *cobra.Command, args []string) error {
 	return err
 }
 
+// Show state.
+type cmdNetworkACLShowState struct {
+	global     *cmdGlobal
+	networkACL *cmdNetworkACL
+
+	flagFormat string
+}
+
+func (c *cmdNetworkACLShowState) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show-state", "[<remote>:]<ACL>")
+	cmd.Short = "Show the traffic matched by the network ACL rules"
+	cmd.Long = cli.FormatSection("Description", `Show the traffic matched by the network ACL rules
+
+The packet and byte counters are aggregated across all cluster members.`)
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact)"))
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("network_acl", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkACLShowState) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+	if resource.name == "" {
+		return errors.New("Missing network ACL name")
+	}
+
+	netACL, _, err := resource.server.GetNetworkACL(resource.name)
+	if err != nil {
+		return err
+	}
+
+	aclState, err := resource.server.GetNetworkACLState(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data := [][]string{}
+	addRows := func(direction string, rules []api.NetworkACLRule, counters []api.NetworkACLRuleCounters) {
+		for i, rule := range rules {
+			var counter api.NetworkACLRuleCounters
+			if i < len(counters) {
+				counter = counters[i]
+			}
+
+			data = append(data, []string{direction, strconv.Itoa(i), rule.Action, rule.State, strconv.FormatUint(counter.Packets, 10), strconv.FormatUint(counter.Bytes, 10)})
+		}
+	}
+
+	addRows("ingress", netACL.Ingress, aclState.Ingress)
+	addRows("egress", netACL.Egress, aclState.Egress)
+
+	header := []string{
+		"DIRECTION",
+		"RULE",
+		"ACTION",
+		"STATE",
+		"PACKETS",
+		"BYTES",
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, aclState)
+}
+
 // Get.
 type cmdNetworkACLGet struct {
 	global     *cmdGlobal
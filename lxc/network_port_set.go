@@ -0,0 +1,947 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v2"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/termios"
+)
+
+type cmdNetworkPortSet struct {
+	global *cmdGlobal
+}
+
+func (c *cmdNetworkPortSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("port-set")
+	cmd.Short = "Manage network port sets"
+	cmd.Long = cli.FormatSection("Description", `Manage network port sets
+
+Network port sets are named lists of ports that network ACL rules can reference as "$<name>".`)
+
+	// List.
+	networkPortSetListCmd := cmdNetworkPortSetList{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetListCmd.command())
+
+	// Show.
+	networkPortSetShowCmd := cmdNetworkPortSetShow{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetShowCmd.command())
+	// Get.
+	networkPortSetGetCmd := cmdNetworkPortSetGet{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetGetCmd.command())
+
+	// Create.
+	networkPortSetCreateCmd := cmdNetworkPortSetCreate{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetCreateCmd.command())
+
+	// Set.
+	networkPortSetSetCmd := cmdNetworkPortSetSet{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetSetCmd.command())
+
+	// Unset.
+	networkPortSetUnsetCmd := cmdNetworkPortSetUnset{global: c.global, networkPortSet: c, networkPortSetSet: &networkPortSetSetCmd}
+	cmd.AddCommand(networkPortSetUnsetCmd.command())
+
+	// Edit.
+	networkPortSetEditCmd := cmdNetworkPortSetEdit{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetEditCmd.command())
+
+	// Rename.
+	networkPortSetRenameCmd := cmdNetworkPortSetRename{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetRenameCmd.command())
+
+	// Delete.
+	networkPortSetDeleteCmd := cmdNetworkPortSetDelete{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetDeleteCmd.command())
+
+	// Add/Remove entries.
+	networkPortSetEntryCmd := cmdNetworkPortSetEntry{global: c.global, networkPortSet: c}
+	cmd.AddCommand(networkPortSetEntryCmd.commandAdd())
+	cmd.AddCommand(networkPortSetEntryCmd.commandRemove())
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// List.
+// cmdNetworkPortSetList handles listing network port sets.
+type cmdNetworkPortSetList struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+
+	flagFormat      string
+	flagColumns     string
+	flagAllProjects bool
+}
+
+// columns returns the ordered column definitions for network port set list.
+func (c *cmdNetworkPortSetList) columns() []cli.ShorthandColumn[api.NetworkPortSet] {
+	return []cli.ShorthandColumn[api.NetworkPortSet]{
+		{Shorthand: 'n', Name: "NAME", Data: c.nameColumnData},
+		{Shorthand: 'd', Name: "DESCRIPTION", Data: c.descriptionColumnData},
+		{Shorthand: 'p', Name: "PORTS", Data: c.entriesColumnData},
+		{Shorthand: 'u', Name: "USED BY", Data: c.usedByColumnData},
+	}
+}
+
+func (c *cmdNetworkPortSetList) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("list", "[<remote>:]")
+	cmd.Aliases = []string{"ls"}
+	cmd.Short = "List network port sets"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (csv|json|table|yaml|compact)"))
+	cmd.Flags().StringVarP(&c.flagColumns, "columns", "c", cli.DefaultColumnString(c.columns()), cli.FormatStringFlagLabel("Columns"))
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, "Display network port sets from all projects")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, ":", true, instanceServerRemoteCompletionFilters(*c.global.conf)...)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetList) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return errors.New("Filtering is not supported yet")
+	}
+
+	var sets []api.NetworkPortSet
+	if c.flagAllProjects {
+		sets, err = resource.server.GetNetworkPortSetsAllProjects()
+		if err != nil {
+			return err
+		}
+	} else {
+		sets, err = resource.server.GetNetworkPortSets()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Parse column flags.
+	cols := c.columns()
+	defaultColumns := cli.DefaultColumnString(cols)
+
+	// Add project column so shorthand 'e' is always valid.
+	cols = append(cols, cli.ShorthandColumn[api.NetworkPortSet]{Shorthand: 'e', Name: "PROJECT", Data: c.projectColumnData})
+
+	if c.flagAllProjects {
+		if c.flagColumns == defaultColumns {
+			c.flagColumns = "e" + defaultColumns
+		}
+	}
+
+	columns, err := cli.ParseShorthandColumns(c.flagColumns, cols)
+	if err != nil {
+		return err
+	}
+
+	data := cli.ColumnData(columns, sets)
+	sort.Sort(cli.SortColumnsNaturally(data))
+	header := cli.ColumnHeaders(columns)
+
+	return cli.RenderTable(c.flagFormat, header, data, sets)
+}
+
+func (c *cmdNetworkPortSetList) projectColumnData(set api.NetworkPortSet) string {
+	return set.Project
+}
+
+func (c *cmdNetworkPortSetList) nameColumnData(set api.NetworkPortSet) string {
+	return set.Name
+}
+
+func (c *cmdNetworkPortSetList) descriptionColumnData(set api.NetworkPortSet) string {
+	return set.Description
+}
+
+func (c *cmdNetworkPortSetList) entriesColumnData(set api.NetworkPortSet) string {
+	return strings.Join(set.Ports, "\n")
+}
+
+func (c *cmdNetworkPortSetList) usedByColumnData(set api.NetworkPortSet) string {
+	return strconv.Itoa(len(set.UsedBy))
+}
+
+// Show.
+type cmdNetworkPortSetShow struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetShow) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("show", "[<remote>:]<set>")
+	cmd.Short = "Show network port set configurations"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetShow) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// Show the network port set config.
+	netSet, _, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	sort.Strings(netSet.UsedBy)
+
+	data, err := yaml.Marshal(&netSet)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+// Get.
+type cmdNetworkPortSetGet struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkPortSetGet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("get", "[<remote>:]<set> <key>")
+	cmd.Short = "Get value for network port set configuration key"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Get the key as a network port set property")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetGet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	resp, _, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	if c.flagIsProperty {
+		w := resp.Writable()
+		res, err := getFieldByJSONTag(&w, args[1])
+		if err != nil {
+			return fmt.Errorf("The property %q does not exist on the network port set %q: %v", args[1], resource.name, err)
+		}
+
+		fmt.Printf("%v\n", res)
+	} else {
+		v, ok := resp.Config[args[1]]
+		if ok {
+			fmt.Printf("%s\n", v)
+		}
+	}
+
+	return nil
+}
+
+// Create.
+type cmdNetworkPortSetCreate struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetCreate) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("create", "[<remote>:]<set> [<port>...]")
+	cmd.Short = "Create new network port set"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.Example = cli.FormatSection("", `lxc network port-set create s1 80 443 8000-8080
+    Create network port set with ports 80, 443 and the range 8000 to 8080
+
+lxc network port-set create s1 < config.yaml
+    Create network port set with configuration from config.yaml`)
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetCreate) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// If stdin isn't a terminal, read yaml from it.
+	var setPut api.NetworkPortSetPut
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		err = yaml.UnmarshalStrict(contents, &setPut)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the network port set.
+	set := api.NetworkPortSetsPost{
+		NetworkPortSetPost: api.NetworkPortSetPost{
+			Name: resource.name,
+		},
+		NetworkPortSetPut: setPut,
+	}
+
+	for _, entry := range args[1:] {
+		if !slices.Contains(set.Ports, entry) {
+			set.Ports = append(set.Ports, entry)
+		}
+	}
+
+	op, err := resource.server.CreateNetworkPortSet(set)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Network port set %s created\n", resource.name)
+	}
+
+	return nil
+}
+
+// Set.
+type cmdNetworkPortSetSet struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkPortSetSet) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("set", "[<remote>:]<set> <key>=<value>...")
+	cmd.Short = "Set network port set configuration keys"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Set the key as a network port set property")
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetSet) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// Get the network port set.
+	netSet, etag, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	// Set the keys.
+	keys, err := getConfig(args[1:]...)
+	if err != nil {
+		return err
+	}
+
+	writable := netSet.Writable()
+	if c.flagIsProperty {
+		if cmd.Name() == "unset" {
+			for k := range keys {
+				err := unsetFieldByJSONTag(&writable, k)
+				if err != nil {
+					return fmt.Errorf("Error unsetting property: %v", err)
+				}
+			}
+		} else {
+			err := unpackKVToWritable(&writable, keys)
+			if err != nil {
+				return fmt.Errorf("Error setting properties: %v", err)
+			}
+		}
+	} else {
+		if writable.Config == nil {
+			writable.Config = map[string]string{}
+		}
+
+		maps.Copy(writable.Config, keys)
+	}
+
+	op, err := resource.server.UpdateNetworkPortSet(resource.name, writable, etag)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	return err
+}
+
+// Unset.
+type cmdNetworkPortSetUnset struct {
+	global            *cmdGlobal
+	networkPortSet    *cmdNetworkPortSet
+	networkPortSetSet *cmdNetworkPortSetSet
+
+	flagIsProperty bool
+}
+
+func (c *cmdNetworkPortSetUnset) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("unset", "[<remote>:]<set> <key>")
+	cmd.Short = "Unset network port set configuration key"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.Flags().BoolVarP(&c.flagIsProperty, "property", "p", false, "Unset the key as a network port set property")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetUnset) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	c.networkPortSetSet.flagIsProperty = c.flagIsProperty
+
+	args = append(args, "")
+	return c.networkPortSetSet.run(cmd, args)
+}
+
+// Edit.
+type cmdNetworkPortSetEdit struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetEdit) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("edit", "[<remote>:]<set>")
+	cmd.Short = "Edit network port set configurations as YAML"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetEdit) helpTemplate() string {
+	return `### This is a YAML representation of the network port set.
+### Any line starting with a '#' will be ignored.
+###
+### A network port set consists of a list of ports and configuration items.
+###
+### An example would look like:
+### name: web
+### description: Web ports
+### ports:
+### - "80"
+### - "443"
+### config:
+###  user.foo: bah
+###
+### Note that only the ports, description and configuration keys can be changed.`
+}
+
+func (c *cmdNetworkPortSetEdit) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// If stdin isn't a terminal, read text from it
+	if !termios.IsTerminal(getStdinFd()) {
+		contents, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+
+		// Allow output of `lxc network port-set show` command to be passed in here, but only take the
+		// contents of the writable fields when updating the set. The other fields are silently discarded.
+		newdata := api.NetworkPortSet{}
+		err = yaml.UnmarshalStrict(contents, &newdata)
+		if err != nil {
+			return err
+		}
+
+		op, err := resource.server.UpdateNetworkPortSet(resource.name, newdata.Writable(), "")
+		if err == nil {
+			err = op.Wait()
+		}
+
+		return err
+	}
+
+	// Get the current config.
+	netSet, etag, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(&netSet)
+	if err != nil {
+		return err
+	}
+
+	// Spawn the editor.
+	content, err := shared.TextEditor("", []byte(c.helpTemplate()+"\n\n"+string(data)))
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Parse the text received from the editor.
+		newdata := api.NetworkPortSet{} // We show the full set info, but only send the writable fields.
+		err = yaml.UnmarshalStrict(content, &newdata)
+		if err == nil {
+			var op lxd.Operation
+			op, err = resource.server.UpdateNetworkPortSet(resource.name, newdata.Writable(), etag)
+			if err == nil {
+				err = op.Wait()
+			}
+		}
+
+		// Respawn the editor.
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Config parsing error: %s\n", err)
+			fmt.Println("Press enter to open the editor again or ctrl+c to abort change")
+
+			_, err := os.Stdin.Read(make([]byte, 1))
+			if err != nil {
+				return err
+			}
+
+			content, err = shared.TextEditor("", content)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// Rename.
+type cmdNetworkPortSetRename struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetRename) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("rename", "[<remote>:]<set> <new-name>")
+	cmd.Aliases = []string{"mv"}
+	cmd.Short = "Rename network port set"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetRename) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, 2)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// Rename the network port set.
+	op, err := resource.server.RenameNetworkPortSet(resource.name, api.NetworkPortSetPost{Name: args[1]})
+	if err == nil {
+		err = op.Wait()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Network port set %s renamed to %s\n", resource.name, args[1])
+	}
+
+	return nil
+}
+
+// Delete.
+type cmdNetworkPortSetDelete struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetDelete) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("delete", "[<remote>:]<set>")
+	cmd.Aliases = []string{"rm"}
+	cmd.Short = "Delete network port set"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.run
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetDelete) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	// Delete the network port set.
+	op, err := resource.server.DeleteNetworkPortSet(resource.name)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !c.global.flagQuiet {
+		fmt.Printf("Network port set %s deleted\n", resource.name)
+	}
+
+	return nil
+}
+
+// Add/Remove entries.
+type cmdNetworkPortSetEntry struct {
+	global         *cmdGlobal
+	networkPortSet *cmdNetworkPortSet
+}
+
+func (c *cmdNetworkPortSetEntry) commandAdd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("add", "[<remote>:]<set> <port>...")
+	cmd.Short = "Add ports to a network port set"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.runAdd
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetEntry) runAdd(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	netSet, etag, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	writable := netSet.Writable()
+	for _, entry := range args[1:] {
+		if slices.Contains(writable.Ports, entry) {
+			return fmt.Errorf("Entry %q already exists in network port set %q", entry, resource.name)
+		}
+
+		writable.Ports = append(writable.Ports, entry)
+	}
+
+	op, err := resource.server.UpdateNetworkPortSet(resource.name, writable, etag)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	return err
+}
+
+func (c *cmdNetworkPortSetEntry) commandRemove() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("remove", "[<remote>:]<set> <port>...")
+	cmd.Short = "Remove ports from a network port set"
+	cmd.Long = cli.FormatSection("Description", cmd.Short)
+	cmd.RunE = c.runRemove
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("networkportset", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdNetworkPortSetEntry) runRemove(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 2, -1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing network port set name")
+	}
+
+	netSet, etag, err := resource.server.GetNetworkPortSet(resource.name)
+	if err != nil {
+		return err
+	}
+
+	writable := netSet.Writable()
+	for _, entry := range args[1:] {
+		index := slices.Index(writable.Ports, entry)
+		if index < 0 {
+			return fmt.Errorf("Entry %q not found in network port set %q", entry, resource.name)
+		}
+
+		writable.Ports = slices.Delete(writable.Ports, index, index+1)
+	}
+
+	op, err := resource.server.UpdateNetworkPortSet(resource.name, writable, etag)
+	if err == nil {
+		err = op.Wait()
+	}
+
+	return err
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	cli "github.com/canonical/lxd/shared/cmd"
 	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
 )
 
 type cmdReplicator struct {
@@ -47,6 +48,10 @@ func (c *cmdReplicator) command() *cobra.Command {
 	replicatorGetCmd := cmdReplicatorGet{global: c.global}
 	cmd.AddCommand(replicatorGetCmd.command())
 
+	// History.
+	replicatorHistoryCmd := cmdReplicatorHistory{global: c.global}
+	cmd.AddCommand(replicatorHistoryCmd.command())
+
 	// Info.
 	replicatorInfoCmd := cmdReplicatorInfo{global: c.global}
 	cmd.AddCommand(replicatorInfoCmd.command())
@@ -779,6 +784,102 @@ func (c *cmdReplicatorEdit) run(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// History.
+type cmdReplicatorHistory struct {
+	global     *cmdGlobal
+	flagFormat string
+}
+
+func (c *cmdReplicatorHistory) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("history", "[<remote>:]<replicator>")
+	cmd.Short = "Show the run history of a replicator"
+	cmd.Long = cli.FormatSection("Description", `Show the run history of a replicator
+
+Displays the most recent runs of the replicator, most recent first, including
+the amount of data transferred and the outcome for each instance.`)
+	cmd.Example = cli.FormatSection("", `lxc replicator history my-replicator
+    Show the past runs of the replicator "my-replicator".
+
+lxc replicator history my-replicator --format yaml
+    Show the past runs of the replicator "my-replicator" including the failure reason of each instance.`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", "Format (csv|json|table|yaml|compact)")
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpTopLevelResource("replicator", toComplete)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+func (c *cmdReplicatorHistory) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 1, 1)
+	if exit {
+		return err
+	}
+
+	// Parse remote.
+	resources, err := c.global.ParseServers(args[0])
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name == "" {
+		return errors.New("Missing replicator name")
+	}
+
+	runs, err := resource.server.GetReplicatorRuns(c.global.flagProject, resource.name)
+	if err != nil {
+		return err
+	}
+
+	const layout = "2006/01/02 15:04 MST"
+
+	data := make([][]string, 0, len(runs))
+	for _, run := range runs {
+		counts := map[string]int{}
+		for _, inst := range run.Instances {
+			counts[inst.Status]++
+		}
+
+		outcomes := []string{}
+		for _, status := range []string{api.ReplicatorRunInstanceStatusSucceeded, api.ReplicatorRunInstanceStatusFailed, api.ReplicatorRunInstanceStatusSkipped} {
+			if counts[status] > 0 {
+				outcomes = append(outcomes, fmt.Sprintf("%d %s", counts[status], strings.ToLower(status)))
+			}
+		}
+
+		data = append(data, []string{
+			run.StartedAt.Local().Format(layout),
+			run.FinishedAt.Local().Format(layout),
+			run.Action,
+			run.Status,
+			units.GetByteSizeStringIEC(run.BytesTransferred, 2),
+			strings.Join(outcomes, ", "),
+		})
+	}
+
+	header := []string{
+		"STARTED",
+		"FINISHED",
+		"ACTION",
+		"STATUS",
+		"TRANSFERRED",
+		"INSTANCES",
+	}
+
+	return cli.RenderTable(c.flagFormat, header, data, runs)
+}
+
 // Info.
 type cmdReplicatorInfo struct {
 	global *cmdGlobal
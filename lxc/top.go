@@ -0,0 +1,540 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/client"
+	cli "github.com/canonical/lxd/shared/cmd"
+	"github.com/canonical/lxd/shared/termios"
+	"github.com/canonical/lxd/shared/units"
+)
+
+type cmdTop struct {
+	global *cmdGlobal
+
+	flagFormat      string
+	flagSort        string
+	flagInterval    time.Duration
+	flagCount       int
+	flagAllProjects bool
+	flagTarget      string
+
+	showLocation bool
+}
+
+// topSortKeys lists the supported sort keys. Names are sorted ascending, all other keys descending.
+var topSortKeys = []string{"name", "cpu", "memory", "disk", "network"}
+
+func (c *cmdTop) command() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = usage("top", "[<remote>:]")
+	cmd.Short = "Display live resource usage of instances"
+	cmd.Long = cli.FormatSection("Description", `Display live resource usage of instances
+
+The resource usage is computed from the metrics of the server (or of all cluster members) and shows
+the CPU usage, memory usage, disk operations and network throughput of each running instance.
+
+The server caches its metrics for a few seconds, so refresh intervals shorter than 10s may show
+the same values more than once.`)
+	cmd.Example = cli.FormatSection("", `lxc top
+    Show the resource usage of the instances in the current project, refreshed every 10 seconds.
+
+lxc top --all-projects --sort=memory
+    Show the resource usage of the instances in all projects, sorted by memory usage.
+
+lxc top --format=json --count=6 > usage.json
+    Write the resource usage of the instances as one JSON object per line for one minute.`)
+
+	cmd.RunE = c.run
+	cmd.Flags().StringVarP(&c.flagFormat, "format", "f", "table", cli.FormatStringFlagLabel("Format (table|json)"))
+	cmd.Flags().StringVarP(&c.flagSort, "sort", "s", "cpu", cli.FormatStringFlagLabel("Sort by (name|cpu|memory|disk|network)"))
+	cmd.Flags().DurationVarP(&c.flagInterval, "interval", "i", 10*time.Second, cli.FormatStringFlagLabel("Refresh interval"))
+	cmd.Flags().IntVarP(&c.flagCount, "count", "n", 0, cli.FormatStringFlagLabel("Number of refreshes before exiting (0 for no limit)"))
+	cmd.Flags().BoolVar(&c.flagAllProjects, "all-projects", false, "Display instances from all projects")
+	cmd.Flags().StringVar(&c.flagTarget, "target", "", cli.FormatStringFlagLabel("Cluster member name"))
+
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return c.global.cmpRemotes(toComplete, ":", true, instanceServerRemoteCompletionFilters(*c.global.conf)...)
+		}
+
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	return cmd
+}
+
+// topSample holds the metric values of an instance at a point in time.
+type topSample struct {
+	project  string
+	name     string
+	location string
+	instType string
+
+	cpuSeconds    float64
+	memoryTotal   float64
+	memoryFree    float64
+	memoryAvail   float64
+	hasMemAvail   bool
+	diskReads     float64
+	diskWrites    float64
+	diskReadBytes float64
+	diskWritBytes float64
+	netRxBytes    float64
+	netTxBytes    float64
+}
+
+// topInstance holds the resource usage of an instance computed from two samples.
+type topInstance struct {
+	Name     string `json:"name"`
+	Project  string `json:"project"`
+	Location string `json:"location,omitempty"`
+	Type     string `json:"type"`
+
+	// CPU usage in percent of a single CPU.
+	CPUUsage float64 `json:"cpu_usage"`
+
+	// Memory usage and total in bytes.
+	MemoryUsage int64 `json:"memory_usage"`
+	MemoryTotal int64 `json:"memory_total"`
+
+	// Disk operations per second and disk throughput in bytes per second.
+	DiskIOPS       float64 `json:"disk_iops"`
+	DiskReadBytes  float64 `json:"disk_read_bytes"`
+	DiskWriteBytes float64 `json:"disk_write_bytes"`
+
+	// Network throughput in bytes per second.
+	NetworkRXBytes float64 `json:"network_rx_bytes"`
+	NetworkTXBytes float64 `json:"network_tx_bytes"`
+}
+
+// topSnapshot is the JSON object written for every refresh when using the JSON format.
+type topSnapshot struct {
+	Time      time.Time     `json:"time"`
+	Instances []topInstance `json:"instances"`
+}
+
+// topSource is a server to collect the instance metrics from.
+type topSource struct {
+	server   lxd.InstanceServer
+	location string
+
+	lastMetrics string
+	lastTime    time.Time
+	elapsed     float64
+	previous    map[string]*topSample
+	current     map[string]*topSample
+}
+
+func (c *cmdTop) run(cmd *cobra.Command, args []string) error {
+	// Quick checks.
+	exit, err := c.global.CheckArgs(cmd, args, 0, 1)
+	if exit {
+		return err
+	}
+
+	if !slices.Contains([]string{cli.TableFormatTable, cli.TableFormatJSON}, c.flagFormat) {
+		return fmt.Errorf("Invalid format %q", c.flagFormat)
+	}
+
+	if !slices.Contains(topSortKeys, c.flagSort) {
+		return fmt.Errorf("Invalid sort key %q, must be one of: %s", c.flagSort, strings.Join(topSortKeys, ", "))
+	}
+
+	if c.flagInterval < time.Second {
+		return errors.New("The refresh interval must be at least 1s")
+	}
+
+	if c.flagCount < 0 {
+		return errors.New("The number of refreshes can't be negative")
+	}
+
+	if c.global.flagProject != "" && c.flagAllProjects {
+		return errors.New("Cannot specify --project with --all-projects")
+	}
+
+	// Parse remote.
+	remote := ""
+	if len(args) > 0 {
+		remote = args[0]
+	}
+
+	resources, err := c.global.ParseServers(remote)
+	if err != nil {
+		return err
+	}
+
+	resource := resources[0]
+
+	if resource.name != "" {
+		return errors.New("Filtering is not supported yet")
+	}
+
+	server := resource.server
+	if c.flagAllProjects {
+		server = server.UseProject("")
+	}
+
+	// Collect the metrics from each cluster member, as each one only reports its own instances.
+	var sources []*topSource
+	if c.flagTarget != "" {
+		sources = append(sources, &topSource{server: server.UseTarget(c.flagTarget), location: c.flagTarget})
+	} else if server.IsClustered() {
+		members, err := server.GetClusterMembers()
+		if err != nil {
+			return err
+		}
+
+		for _, member := range members {
+			// Offline members can't report metrics.
+			if member.Status == "Offline" {
+				continue
+			}
+
+			sources = append(sources, &topSource{server: server.UseTarget(member.ServerName), location: member.ServerName})
+		}
+	} else {
+		sources = append(sources, &topSource{server: server})
+	}
+
+	c.showLocation = c.flagTarget != "" || len(sources) > 1
+	isTerminal := c.flagFormat == cli.TableFormatTable && termios.IsTerminal(getStdoutFd())
+
+	var instances []topInstance
+	for i := 0; c.flagCount == 0 || i <= c.flagCount; i++ {
+		if i > 0 {
+			time.Sleep(c.flagInterval)
+		}
+
+		refreshed := false
+		for _, source := range sources {
+			changed, err := source.refresh()
+			if err != nil {
+				return err
+			}
+
+			refreshed = refreshed || changed
+		}
+
+		// Rates can only be computed from the second refresh onwards.
+		if i == 0 {
+			continue
+		}
+
+		// Keep the previous usage if the server returned the same cached metrics again.
+		if refreshed || instances == nil {
+			instances = instances[:0]
+			for _, source := range sources {
+				instances = append(instances, source.instances()...)
+			}
+
+			topSortInstances(instances, c.flagSort)
+		}
+
+		err := c.render(instances, isTerminal)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render writes the resource usage of the instances in the selected format.
+func (c *cmdTop) render(instances []topInstance, isTerminal bool) error {
+	if c.flagFormat == cli.TableFormatJSON {
+		data, err := json.Marshal(topSnapshot{Time: time.Now().UTC(), Instances: instances})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(data))
+		return nil
+	}
+
+	header := []string{"NAME"}
+	if c.flagAllProjects {
+		header = append(header, "PROJECT")
+	}
+
+	if c.showLocation {
+		header = append(header, "LOCATION")
+	}
+
+	header = append(header, "TYPE", "CPU%", "MEMORY", "DISK IOPS", "DISK READ/s", "DISK WRITE/s", "NET RX/s", "NET TX/s")
+
+	data := make([][]string, 0, len(instances))
+	for _, inst := range instances {
+		row := []string{inst.Name}
+		if c.flagAllProjects {
+			row = append(row, inst.Project)
+		}
+
+		if c.showLocation {
+			row = append(row, inst.Location)
+		}
+
+		memory := units.GetByteSizeStringIEC(inst.MemoryUsage, 2)
+		if inst.MemoryTotal > 0 {
+			memory += "/" + units.GetByteSizeStringIEC(inst.MemoryTotal, 2)
+		}
+
+		row = append(row,
+			inst.Type,
+			strconv.FormatFloat(inst.CPUUsage, 'f', 1, 64),
+			memory,
+			strconv.FormatFloat(inst.DiskIOPS, 'f', 1, 64),
+			units.GetByteSizeStringIEC(int64(inst.DiskReadBytes), 2),
+			units.GetByteSizeStringIEC(int64(inst.DiskWriteBytes), 2),
+			units.GetByteSizeStringIEC(int64(inst.NetworkRXBytes), 2),
+			units.GetByteSizeStringIEC(int64(inst.NetworkTXBytes), 2),
+		)
+
+		data = append(data, row)
+	}
+
+	if isTerminal {
+		// Clear the screen and move the cursor to the top left corner.
+		fmt.Print("\033[H\033[2J")
+		fmt.Printf("%s - %d instances, refreshing every %s\n\n", time.Now().Format(time.TimeOnly), len(instances), c.flagInterval)
+	}
+
+	return cli.RenderTable(cli.TableFormatTable, header, data, instances)
+}
+
+// refresh fetches the metrics of the source and returns whether they changed since the previous refresh.
+func (s *topSource) refresh() (bool, error) {
+	metrics, err := s.server.GetMetrics()
+	if err != nil {
+		if s.location != "" {
+			return false, fmt.Errorf("Failed getting metrics from %q: %w", s.location, err)
+		}
+
+		return false, fmt.Errorf("Failed getting metrics: %w", err)
+	}
+
+	if s.current != nil && metrics == s.lastMetrics {
+		return false, nil
+	}
+
+	now := time.Now()
+	if s.current != nil {
+		s.elapsed = now.Sub(s.lastTime).Seconds()
+	}
+
+	s.previous = s.current
+	s.current = topParseMetrics(metrics, s.location)
+	s.lastMetrics = metrics
+	s.lastTime = now
+
+	return true, nil
+}
+
+// instances returns the resource usage of the instances found in both the current and the previous samples.
+func (s *topSource) instances() []topInstance {
+	instances := []topInstance{}
+	for key, current := range s.current {
+		previous, ok := s.previous[key]
+		if !ok {
+			continue
+		}
+
+		instances = append(instances, topComputeUsage(previous, current, s.elapsed))
+	}
+
+	return instances
+}
+
+// topComputeUsage computes the resource usage of an instance from two samples taken elapsed seconds apart.
+func topComputeUsage(previous *topSample, current *topSample, elapsed float64) topInstance {
+	rate := func(prev float64, cur float64) float64 {
+		// Counters reset when instances restart.
+		if elapsed <= 0 || cur < prev {
+			return 0
+		}
+
+		return (cur - prev) / elapsed
+	}
+
+	memoryFree := current.memoryFree
+	if current.hasMemAvail {
+		memoryFree = current.memoryAvail
+	}
+
+	return topInstance{
+		Name:           current.name,
+		Project:        current.project,
+		Location:       current.location,
+		Type:           current.instType,
+		CPUUsage:       rate(previous.cpuSeconds, current.cpuSeconds) * 100,
+		MemoryUsage:    int64(max(current.memoryTotal-memoryFree, 0)),
+		MemoryTotal:    int64(current.memoryTotal),
+		DiskIOPS:       rate(previous.diskReads+previous.diskWrites, current.diskReads+current.diskWrites),
+		DiskReadBytes:  rate(previous.diskReadBytes, current.diskReadBytes),
+		DiskWriteBytes: rate(previous.diskWritBytes, current.diskWritBytes),
+		NetworkRXBytes: rate(previous.netRxBytes, current.netRxBytes),
+		NetworkTXBytes: rate(previous.netTxBytes, current.netTxBytes),
+	}
+}
+
+// topSortInstances sorts the instances by the given key, breaking ties by project and name.
+func topSortInstances(instances []topInstance, key string) {
+	value := func(inst topInstance) float64 {
+		switch key {
+		case "cpu":
+			return inst.CPUUsage
+		case "memory":
+			return float64(inst.MemoryUsage)
+		case "disk":
+			return inst.DiskIOPS
+		case "network":
+			return inst.NetworkRXBytes + inst.NetworkTXBytes
+		}
+
+		return 0
+	}
+
+	sort.SliceStable(instances, func(i, j int) bool {
+		vi, vj := value(instances[i]), value(instances[j])
+		if vi != vj {
+			return vi > vj
+		}
+
+		if instances[i].Project != instances[j].Project {
+			return instances[i].Project < instances[j].Project
+		}
+
+		return instances[i].Name < instances[j].Name
+	})
+}
+
+// topParseMetrics extracts the instance samples from metrics in the OpenMetrics text format, keyed by project
+// and instance name.
+func topParseMetrics(metrics string, location string) map[string]*topSample {
+	samples := map[string]*topSample{}
+
+	for line := range strings.SplitSeq(metrics, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, labels, value, ok := topParseMetricLine(line)
+		if !ok || labels["name"] == "" {
+			continue
+		}
+
+		key := labels["project"] + "/" + labels["name"]
+		sample, ok := samples[key]
+		if !ok {
+			sample = &topSample{
+				project:  labels["project"],
+				name:     labels["name"],
+				location: location,
+				instType: labels["type"],
+			}
+
+			samples[key] = sample
+		}
+
+		switch name {
+		case "lxd_cpu_seconds_total":
+			// Only count the time the CPUs were busy.
+			if labels["mode"] != "idle" && labels["mode"] != "iowait" {
+				sample.cpuSeconds += value
+			}
+
+		case "lxd_memory_MemTotal_bytes":
+			sample.memoryTotal = value
+		case "lxd_memory_MemFree_bytes":
+			sample.memoryFree = value
+		case "lxd_memory_MemAvailable_bytes":
+			sample.memoryAvail = value
+			sample.hasMemAvail = true
+		case "lxd_disk_reads_completed_total":
+			sample.diskReads += value
+		case "lxd_disk_writes_completed_total":
+			sample.diskWrites += value
+		case "lxd_disk_read_bytes_total":
+			sample.diskReadBytes += value
+		case "lxd_disk_written_bytes_total":
+			sample.diskWritBytes += value
+		case "lxd_network_receive_bytes_total":
+			if labels["device"] != "lo" {
+				sample.netRxBytes += value
+			}
+
+		case "lxd_network_transmit_bytes_total":
+			if labels["device"] != "lo" {
+				sample.netTxBytes += value
+			}
+		}
+	}
+
+	return samples
+}
+
+// topParseMetricLine parses a sample line of the OpenMetrics text format.
+func topParseMetricLine(line string) (string, map[string]string, float64, bool) {
+	labels := map[string]string{}
+
+	name, rest, hasLabels := strings.Cut(line, "{")
+	if hasLabels {
+		// Parse the comma separated key="value" pairs up to the closing brace.
+		for {
+			key, after, found := strings.Cut(rest, "=\"")
+			if !found {
+				return "", nil, 0, false
+			}
+
+			var value strings.Builder
+			i := 0
+			for ; i < len(after) && after[i] != '"'; i++ {
+				if after[i] == '\\' && i+1 < len(after) {
+					i++
+					if after[i] == 'n' {
+						value.WriteByte('\n')
+						continue
+					}
+				}
+
+				value.WriteByte(after[i])
+			}
+
+			if i >= len(after) {
+				return "", nil, 0, false
+			}
+
+			labels[strings.TrimPrefix(key, ",")] = value.String()
+			rest = after[i+1:]
+
+			if strings.HasPrefix(rest, "}") {
+				rest = rest[1:]
+				break
+			}
+		}
+	} else {
+		name, rest, _ = strings.Cut(line, " ")
+		rest = " " + rest
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) == 0 {
+		return "", nil, 0, false
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return "", nil, 0, false
+	}
+
+	return name, labels, value, true
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopParseMetricLine(t *testing.T) {
+	name, labels, value, ok := topParseMetricLine(`lxd_cpu_seconds_total{cpu="0",mode="user",name="c1",project="default",type="container"} 12.5`)
+	require.True(t, ok)
+	assert.Equal(t, "lxd_cpu_seconds_total", name)
+	assert.Equal(t, map[string]string{"cpu": "0", "mode": "user", "name": "c1", "project": "default", "type": "container"}, labels)
+	assert.Equal(t, 12.5, value)
+
+	name, labels, value, ok = topParseMetricLine(`lxd_go_goroutines 42`)
+	require.True(t, ok)
+	assert.Equal(t, "lxd_go_goroutines", name)
+	assert.Empty(t, labels)
+	assert.Equal(t, 42.0, value)
+
+	_, labels, _, ok = topParseMetricLine(`lxd_test{name="a \"quoted\" name"} 1`)
+	require.True(t, ok)
+	assert.Equal(t, `a "quoted" name`, labels["name"])
+
+	_, _, _, ok = topParseMetricLine(`lxd_test{name="c1" 1`)
+	assert.False(t, ok)
+
+	_, _, _, ok = topParseMetricLine(`lxd_test{name="c1"} abc`)
+	assert.False(t, ok)
+}
+
+func TestTopComputeUsage(t *testing.T) {
+	previous := `# HELP lxd_cpu_seconds_total The total number of CPU time used in seconds.
+# TYPE lxd_cpu_seconds_total counter
+lxd_cpu_seconds_total{cpu="0",mode="user",name="c1",project="default",type="container"} 10
+lxd_cpu_seconds_total{cpu="0",mode="idle",name="c1",project="default",type="container"} 100
+lxd_disk_reads_completed_total{device="root",name="c1",project="default",type="container"} 100
+lxd_disk_writes_completed_total{device="root",name="c1",project="default",type="container"} 50
+lxd_network_receive_bytes_total{device="eth0",name="c1",project="default",type="container"} 1000
+lxd_network_receive_bytes_total{device="lo",name="c1",project="default",type="container"} 5000
+lxd_memory_MemTotal_bytes{name="c1",project="default",type="container"} 1000
+lxd_memory_MemFree_bytes{name="c1",project="default",type="container"} 100
+lxd_memory_MemAvailable_bytes{name="c1",project="default",type="container"} 400
+lxd_api_requests_completed_total{entity_type="server",result="succeeded"} 3
+`
+
+	current := `lxd_cpu_seconds_total{cpu="0",mode="user",name="c1",project="default",type="container"} 15
+lxd_cpu_seconds_total{cpu="0",mode="idle",name="c1",project="default",type="container"} 200
+lxd_disk_reads_completed_total{device="root",name="c1",project="default",type="container"} 200
+lxd_disk_writes_completed_total{device="root",name="c1",project="default",type="container"} 150
+lxd_network_receive_bytes_total{device="eth0",name="c1",project="default",type="container"} 2000
+lxd_network_receive_bytes_total{device="lo",name="c1",project="default",type="container"} 9000
+lxd_memory_MemTotal_bytes{name="c1",project="default",type="container"} 1000
+lxd_memory_MemFree_bytes{name="c1",project="default",type="container"} 100
+lxd_memory_MemAvailable_bytes{name="c1",project="default",type="container"} 400
+`
+
+	prevSamples := topParseMetrics(previous, "member1")
+	curSamples := topParseMetrics(current, "member1")
+	require.Len(t, prevSamples, 1)
+	require.Contains(t, curSamples, "default/c1")
+
+	usage := topComputeUsage(prevSamples["default/c1"], curSamples["default/c1"], 10)
+	assert.Equal(t, topInstance{
+		Name:           "c1",
+		Project:        "default",
+		Location:       "member1",
+		Type:           "container",
+		CPUUsage:       50,
+		MemoryUsage:    600,
+		MemoryTotal:    1000,
+		DiskIOPS:       20,
+		NetworkRXBytes: 100,
+	}, usage)
+
+	// Counters going backwards after an instance restart don't produce negative rates.
+	usage = topComputeUsage(curSamples["default/c1"], prevSamples["default/c1"], 10)
+	assert.Zero(t, usage.CPUUsage)
+	assert.Zero(t, usage.DiskIOPS)
+}
+
+func TestTopSortInstances(t *testing.T) {
+	instances := []topInstance{
+		{Name: "c2", Project: "default", CPUUsage: 10, MemoryUsage: 300},
+		{Name: "c1", Project: "default", CPUUsage: 10, MemoryUsage: 100},
+		{Name: "c3", Project: "default", CPUUsage: 50, MemoryUsage: 200},
+	}
+
+	topSortInstances(instances, "cpu")
+	assert.Equal(t, []string{"c3", "c1", "c2"}, []string{instances[0].Name, instances[1].Name, instances[2].Name})
+
+	topSortInstances(instances, "memory")
+	assert.Equal(t, []string{"c2", "c3", "c1"}, []string{instances[0].Name, instances[1].Name, instances[2].Name})
+
+	topSortInstances(instances, "name")
+	assert.Equal(t, []string{"c1", "c2", "c3"}, []string{instances[0].Name, instances[1].Name, instances[2].Name})
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -392,8 +393,8 @@ func getImgInfo(conf *config.Config, imageRemote string, imageRef string, imageP
 		return nil, nil, err
 	}
 
-	// Optimisation for simplestreams
-	if conf.Remotes[imageRemote].Protocol == "simplestreams" {
+	// Optimisation for simplestreams and OCI registries
+	if slices.Contains([]string{"simplestreams", "oci"}, conf.Remotes[imageRemote].Protocol) {
 		imageInfo = &api.Image{}
 		imageInfo.Fingerprint = imageRef
 		imageInfo.Public = true
@@ -15,6 +15,7 @@ import (
 	"github.com/canonical/lxd/lxd/metrics"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
@@ -113,6 +114,20 @@ func metricsServer(d *Daemon) *http.Server {
 	}
 }
 
+func storageBucketsServer(d *Daemon) *http.Server {
+	handler := s3.NewHandler(storageBucketsS3Lookup(d.State()))
+
+	return &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-d.setupChan
+
+			handler.ServeHTTP(w, r)
+		}),
+		IdleTimeout:       30 * time.Second,
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+}
+
 type lxdHTTPServer struct {
 	r *http.ServeMux
 	d *Daemon
@@ -56,11 +56,16 @@ var api10 = []APIEndpoint{
 	certificateCmd,
 	certificatesCmd,
 	clusterCmd,
+	backupTargetCmd,
+	backupTargetsCmd,
+	backupTargetBackupCmd,
+	backupTargetBackupsCmd,
 	clusterGroupCmd,
 	clusterGroupsCmd,
 	clusterMemberCmd,
 	clusterMemberStateCmd,
 	clusterMembersCmd,
+	clusterRebalanceCmd,
 	clusterLinkCmd,
 	clusterLinksCmd,
 	clusterLinkStateCmd,
@@ -68,6 +73,7 @@ var api10 = []APIEndpoint{
 	replicatorCmd,
 	replicatorsCmd,
 	replicatorStateCmd,
+	replicatorRunsCmd,
 	instanceBackupCmd,
 	instanceBackupExportCmd,
 	instanceBackupsCmd,
@@ -91,6 +97,7 @@ var api10 = []APIEndpoint{
 	eventsCmd,
 	imageAliasesCmd,
 	imagesCmd,
+	imagesStreamsCmd,
 	imageSubCmd,
 	metadataConfigurationCmd,
 	networkCmd,
@@ -100,6 +107,12 @@ var api10 = []APIEndpoint{
 	networkACLCmd,
 	networkACLsCmd,
 	networkACLLogCmd,
+	networkACLStateCmd,
+	networkAddressSetCmd,
+	networkAddressSetsCmd,
+	networkAddressSetStateCmd,
+	networkPortSetCmd,
+	networkPortSetsCmd,
 	networkAllocationsCmd,
 	networkForwardCmd,
 	networkForwardsCmd,
@@ -1203,6 +1216,14 @@ func doAPI10UpdateTriggers(d *Daemon, nodeChanged, clusterChanged map[string]str
 		}
 	}
 
+	value, ok = nodeChanged["core.storage_buckets_address"]
+	if ok {
+		err := s.Endpoints.StorageBucketsUpdateAddress(value, s.Endpoints.NetworkCert())
+		if err != nil {
+			return err
+		}
+	}
+
 	value, ok = nodeChanged["storage.backups_volume"]
 	if ok {
 		oldValue := oldNodeConfig["storage.backups_volume"]
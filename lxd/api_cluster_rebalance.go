@@ -0,0 +1,646 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/placement"
+	"github.com/canonical/lxd/lxd/project/limits"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+var clusterRebalanceCmd = APIEndpoint{
+	Path:        "cluster/rebalance",
+	MetricsType: entity.TypeClusterMember,
+
+	Get:  APIEndpointAction{Handler: clusterRebalanceGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanView)},
+	Post: APIEndpointAction{Handler: clusterRebalancePost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// swagger:operation GET /1.0/cluster/rebalance cluster cluster_rebalance_get
+//
+//	Get the planned instance moves
+//
+//	Returns the instance moves that a rebalancing run would perform to even out the load of the cluster
+//	members, without moving any instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Planned instance moves
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of instance moves
+//	          items:
+//	            $ref: "#/definitions/ClusterRebalanceMove"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterRebalanceGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(errors.New("This server is not clustered"))
+	}
+
+	moves, err := clusterRebalancePlan(r.Context(), s)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, moves)
+}
+
+// swagger:operation POST /1.0/cluster/rebalance cluster cluster_rebalance_post
+//
+//	Rebalance the cluster instances
+//
+//	Moves instances from the most loaded to the least loaded cluster members until the imbalance
+//	is below `cluster.rebalance.threshold` or `cluster.rebalance.batch` instances have been moved.
+//	The load of the cluster members is scored on their live resource usage if any of the
+//	`cluster.scheduler.weight.*` options is set, and on their number of instances otherwise.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func clusterRebalancePost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	if !s.ServerClustered {
+		return response.BadRequest(errors.New("This server is not clustered"))
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		moves, err := clusterRebalancePlan(ctx, s)
+		if err != nil {
+			return err
+		}
+
+		return clusterRebalanceInstances(ctx, s, op, moves)
+	}
+
+	args := operations.OperationArgs{
+		Type:    operationtype.ClusterRebalance,
+		Class:   operationtype.OperationClassTask,
+		RunHook: run,
+		// Share the evacuation conflict reference so that instances aren't rebalanced while a member is evacuated.
+		ConflictReference: clusterMemberEvacuateConflictReference,
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// autoRebalanceClusterTask returns a task that rebalances the cluster instances every `cluster.rebalance.interval`
+// minutes. The task only runs on the cluster leader.
+func autoRebalanceClusterTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := stateFunc()
+
+		leaderInfo, err := s.LeaderInfo()
+		if err != nil || !leaderInfo.Clustered || !leaderInfo.Leader {
+			return
+		}
+
+		moves, err := clusterRebalancePlan(ctx, s)
+		if err != nil {
+			logger.Error("Failed planning cluster rebalancing", logger.Ctx{"err": err})
+			return
+		}
+
+		// Don't create an operation if the cluster is balanced.
+		if len(moves) == 0 {
+			return
+		}
+
+		opRun := func(ctx context.Context, op *operations.Operation) error {
+			return clusterRebalanceInstances(ctx, s, op, moves)
+		}
+
+		args := operations.OperationArgs{
+			Type:              operationtype.ClusterRebalance,
+			Class:             operationtype.OperationClassTask,
+			RunHook:           opRun,
+			ConflictReference: clusterMemberEvacuateConflictReference,
+		}
+
+		op, err := operations.ScheduleServerOperation(s, args)
+		if err != nil {
+			logger.Warn("Failed creating cluster rebalancing operation", logger.Ctx{"err": err})
+			return
+		}
+
+		err = op.Wait(ctx)
+		if err != nil {
+			logger.Error("Failed rebalancing cluster instances", logger.Ctx{"err": err})
+		}
+	}
+
+	// Re-read the interval after each run so that configuration changes are picked up.
+	// The first run is skipped so that rebalancing doesn't happen right after a restart or after enabling it.
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := stateFunc().GlobalConfig.ClusterRebalanceInterval()
+		if interval == 0 {
+			return time.Minute, task.ErrSkip
+		}
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+// clusterRebalanceInstances performs the given instance moves, reporting progress on the operation.
+func clusterRebalanceInstances(ctx context.Context, s *state.State, op *operations.Operation, moves []api.ClusterRebalanceMove) error {
+	err := op.ExtendMetadata(map[string]any{"moves": moves})
+	if err != nil {
+		return err
+	}
+
+	progress := op.ProgressHandler("rebalance")
+	for _, move := range moves {
+		progress(ioprogress.ProgressData{Text: fmt.Sprintf("Moving %q in project %q from %q to %q", move.Instance, move.Project, move.Source, move.Target)})
+
+		err := clusterRebalanceMoveInstance(ctx, s, move)
+		if err != nil {
+			return fmt.Errorf("Failed moving instance %q in project %q to %q: %w", move.Instance, move.Project, move.Target, err)
+		}
+	}
+
+	return nil
+}
+
+// clusterRebalanceMoveInstance moves an instance as planned.
+func clusterRebalanceMoveInstance(ctx context.Context, s *state.State, move api.ClusterRebalanceMove) error {
+	var sourceMember db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		sourceMember, err = tx.GetNodeByName(ctx, move.Source)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading cluster member %q: %w", move.Source, err)
+	}
+
+	client, err := cluster.Connect(ctx, sourceMember.Address, s.Endpoints.NetworkCert(), s.ServerCert(), true)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to cluster member %q: %w", move.Source, err)
+	}
+
+	return clusterRebalanceMigrate(ctx, client.UseProject(move.Project), move)
+}
+
+// clusterRebalanceMigrate moves an instance through the given client connected to its source cluster member.
+// Running instances which aren't live migrated are shut down cleanly first, falling back to a forced stop after
+// their `boot.host_shutdown_timeout`, and are started again on their new cluster member.
+func clusterRebalanceMigrate(ctx context.Context, client lxd.InstanceServer, move api.ClusterRebalanceMove) error {
+	// Skip instances which were moved since the rebalancing was planned.
+	inst, _, err := client.GetInstance(move.Instance)
+	if err != nil {
+		return err
+	}
+
+	if inst.Location != move.Source {
+		return nil
+	}
+
+	instState, _, err := client.GetInstanceState(move.Instance)
+	if err != nil {
+		return err
+	}
+
+	running := instState.StatusCode == api.Running
+	req := api.InstancePost{
+		Migration: true,
+		Live:      move.Live && running,
+	}
+
+	// Shut the instance down cleanly rather than letting the migration stop it forcefully.
+	stopped := running && !req.Live
+	if stopped {
+		timeout, err := strconv.Atoi(inst.ExpandedConfig["boot.host_shutdown_timeout"])
+		if err != nil {
+			timeout = evacuateHostShutdownDefaultTimeout
+		}
+
+		err = clusterRebalanceUpdateState(ctx, client, move.Instance, api.InstanceStatePut{Action: "stop", Timeout: timeout})
+		if err != nil {
+			logger.Warn("Failed shutting down instance, forcing stop", logger.Ctx{"project": move.Project, "instance": move.Instance, "err": err})
+
+			err = clusterRebalanceUpdateState(ctx, client, move.Instance, api.InstanceStatePut{Action: "stop", Force: true})
+			if err != nil {
+				return fmt.Errorf("Failed stopping instance: %w", err)
+			}
+		}
+	}
+
+	migrateOp, err := client.UseTarget(move.Target).MigrateInstance(move.Instance, req)
+	if err != nil {
+		return err
+	}
+
+	err = migrateOp.WaitContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !stopped {
+		return nil
+	}
+
+	// The migration only restarts instances it stopped itself, so start it back up on its new cluster member.
+	err = clusterRebalanceUpdateState(ctx, client, move.Instance, api.InstanceStatePut{Action: "start"})
+	if err != nil {
+		return fmt.Errorf("Failed starting instance: %w", err)
+	}
+
+	return nil
+}
+
+// clusterRebalanceUpdateState changes the state of an instance and waits for the change to complete.
+func clusterRebalanceUpdateState(ctx context.Context, client lxd.InstanceServer, name string, req api.InstanceStatePut) error {
+	op, err := client.UpdateInstanceState(name, req, "")
+	if err != nil {
+		return err
+	}
+
+	return op.WaitContext(ctx)
+}
+
+// clusterRebalancePlan returns the instance moves needed to even out the load of the cluster members that accept
+// automatically placed instances. The load is scored on the live resource usage of the members if weighted scheduling
+// is enabled, and on their number of instances otherwise. Instances are only moved if their `cluster.evacuate` mode
+// allows migration, and only to cluster members that comply with their cluster group and placement group.
+func clusterRebalancePlan(ctx context.Context, s *state.State) ([]api.ClusterRebalanceMove, error) {
+	var allMembers []db.NodeInfo
+	var dbInstances []dbCluster.Instance
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		allMembers, err = tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed getting cluster members: %w", err)
+		}
+
+		dbInstances, err = dbCluster.GetInstances(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed getting instances: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the instances of the cluster members that accept automatically placed instances.
+	counts := make(map[string]int, len(allMembers))
+	for _, member := range allMembers {
+		if member.State != db.ClusterMemberStateCreated || member.IsOffline(s.GlobalConfig.OfflineThreshold()) || member.Config["scheduler.instance"] == "manual" {
+			continue
+		}
+
+		counts[member.Name] = 0
+	}
+
+	memberInstances := make(map[string][]dbCluster.Instance, len(counts))
+	for _, dbInst := range dbInstances {
+		_, ok := counts[dbInst.Node]
+		if !ok {
+			continue
+		}
+
+		counts[dbInst.Node]++
+		memberInstances[dbInst.Node] = append(memberInstances[dbInst.Node], dbInst)
+	}
+
+	// Score the cluster members with the same weights and member states as automatic instance placement.
+	scorer := instancePlacementScorer(ctx, s)
+	scores := clusterRebalanceScores(scorer, counts)
+
+	pgCache := placement.NewCache()
+	movedPlacementGroups := make(map[string]bool)
+	moves := []api.ClusterRebalanceMove{}
+
+	for len(moves) < s.GlobalConfig.ClusterRebalanceBatch() {
+		var source string
+		if scores != nil {
+			source = clusterRebalanceScoredSource(scores, counts, s.GlobalConfig.ClusterRebalanceThreshold())
+		} else {
+			source = clusterRebalanceSource(counts, s.GlobalConfig.ClusterRebalanceThreshold())
+		}
+
+		if source == "" {
+			break
+		}
+
+		var move *api.ClusterRebalanceMove
+		for i, dbInst := range memberInstances[source] {
+			move, err = clusterRebalanceSelectMove(ctx, s, allMembers, dbInst, counts, scorer, scores, pgCache, movedPlacementGroups)
+			if err != nil {
+				return nil, err
+			}
+
+			if move != nil {
+				memberInstances[source] = slices.Delete(memberInstances[source], i, i+1)
+				break
+			}
+		}
+
+		// Stop if none of the instances of the most loaded member can be moved.
+		if move == nil {
+			break
+		}
+
+		counts[move.Source]--
+		counts[move.Target]++
+		moves = append(moves, *move)
+
+		// The resource usage of the source and target members is only measured again on the next run,
+		// so leave them out of the remaining moves of this run.
+		if scores != nil {
+			delete(scores, move.Source)
+			delete(scores, move.Target)
+		}
+	}
+
+	return moves, nil
+}
+
+// clusterRebalanceSource returns the cluster member with the most instances if the difference with the least loaded
+// member exceeds the threshold, expressed in percent of the average number of instances per member.
+// It returns an empty string if the cluster is balanced.
+func clusterRebalanceSource(counts map[string]int, threshold int64) string {
+	if len(counts) < 2 {
+		return ""
+	}
+
+	var source string
+	total := 0
+	minCount := -1
+	maxCount := -1
+	for name, count := range counts {
+		total += count
+
+		if minCount == -1 || count < minCount {
+			minCount = count
+		}
+
+		if count > maxCount || (count == maxCount && name < source) {
+			maxCount = count
+			source = name
+		}
+	}
+
+	// Moving an instance only helps if the difference is at least two instances.
+	spread := maxCount - minCount
+	if spread < 2 {
+		return ""
+	}
+
+	average := float64(total) / float64(len(counts))
+	if float64(spread)*100 < float64(threshold)*average {
+		return ""
+	}
+
+	return source
+}
+
+// clusterRebalanceScores returns the scores of the given cluster members. It returns nil if the scorer is nil or if
+// any of the members cannot be scored, in which case the members are balanced on their number of instances instead.
+func clusterRebalanceScores(scorer *placement.Scorer, counts map[string]int) map[string]float64 {
+	if scorer == nil {
+		return nil
+	}
+
+	scores := make(map[string]float64, len(counts))
+	for name := range counts {
+		memberState, ok := scorer.MemberStates[name]
+		if !ok {
+			return nil
+		}
+
+		score, ok := scorer.Score(memberState)
+		if !ok {
+			return nil
+		}
+
+		scores[name] = score
+	}
+
+	return scores
+}
+
+// clusterRebalanceScoredSource returns the cluster member with instances that has the lowest score if the difference
+// with the best scoring member exceeds the threshold, expressed in percent of free resources.
+// It returns an empty string if the cluster is balanced.
+func clusterRebalanceScoredSource(scores map[string]float64, counts map[string]int, threshold int64) string {
+	if len(scores) < 2 {
+		return ""
+	}
+
+	var source string
+	bestScore := -1.0
+	for name, score := range scores {
+		bestScore = max(bestScore, score)
+
+		if counts[name] == 0 {
+			continue
+		}
+
+		if source == "" || score < scores[source] || (score == scores[source] && name < source) {
+			source = name
+		}
+	}
+
+	if source == "" || (bestScore-scores[source])*100 < float64(threshold) {
+		return ""
+	}
+
+	return source
+}
+
+// clusterRebalanceScoredTarget returns the candidate with the fewest instances among the best scoring ones, provided
+// it scores better than the source. Ties are broken by name.
+func clusterRebalanceScoredTarget(scorer *placement.Scorer, candidates []db.NodeInfo, scores map[string]float64, counts map[string]int, source string) *db.NodeInfo {
+	eligible := make([]db.NodeInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		score, ok := scores[candidate.Name]
+		if ok && candidate.Name != source && score > scores[source] {
+			eligible = append(eligible, candidate)
+		}
+	}
+
+	if len(eligible) == 0 {
+		return nil
+	}
+
+	var target *db.NodeInfo
+	bestCandidates := scorer.Best(eligible)
+	for i, candidate := range bestCandidates {
+		count := counts[candidate.Name]
+		if target == nil || count < counts[target.Name] || (count == counts[target.Name] && candidate.Name < target.Name) {
+			target = &bestCandidates[i]
+		}
+	}
+
+	return target
+}
+
+// clusterRebalanceTarget returns the candidate with the fewest instances, provided moving an instance from the source
+// to it improves the balance. Ties are broken by name.
+func clusterRebalanceTarget(candidates []db.NodeInfo, counts map[string]int, source string) *db.NodeInfo {
+	var target *db.NodeInfo
+	for i, candidate := range candidates {
+		count, ok := counts[candidate.Name]
+		if !ok || candidate.Name == source || count > counts[source]-2 {
+			continue
+		}
+
+		if target == nil || count < counts[target.Name] || (count == counts[target.Name] && candidate.Name < target.Name) {
+			target = &candidates[i]
+		}
+	}
+
+	return target
+}
+
+// clusterRebalanceSelectMove returns the move of the given instance to the least loaded compliant cluster member,
+// or nil if the instance cannot be moved. The members are compared on their scores if scores are given, and on their
+// number of instances otherwise.
+func clusterRebalanceSelectMove(ctx context.Context, s *state.State, allMembers []db.NodeInfo, dbInst dbCluster.Instance, counts map[string]int, scorer *placement.Scorer, scores map[string]float64, pgCache *placement.Cache, movedPlacementGroups map[string]bool) (*api.ClusterRebalanceMove, error) {
+	inst, err := instance.LoadByProjectAndName(s, dbInst.Project, dbInst.Name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance %q in project %q: %w", dbInst.Name, dbInst.Project, err)
+	}
+
+	// Leave evacuated instances in place until their origin cluster member is restored.
+	if inst.LocalConfig()["volatile.evacuate.origin"] != "" {
+		return nil, nil
+	}
+
+	migrate, live := inst.CanMigrate()
+	if !migrate {
+		return nil, nil
+	}
+
+	// Only move a single instance per placement group in each run as the placement is computed from the database.
+	placementGroupName := inst.ExpandedConfig()["placement.group"]
+	placementGroupKey := dbInst.Project + "/" + placementGroupName
+	if placementGroupName != "" && movedPlacementGroups[placementGroupKey] {
+		return nil, nil
+	}
+
+	var target *db.NodeInfo
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, clusterGroupName := limits.TargetDetect(inst.LocalConfig()["volatile.cluster.group"])
+		instProject := inst.Project()
+
+		candidates, err := tx.GetCandidateMembers(ctx, allMembers, []int{inst.Architecture()}, clusterGroupName, limits.GetRestrictedClusterGroups(&instProject), s.GlobalConfig.OfflineThreshold())
+		if err != nil {
+			return err
+		}
+
+		if placementGroupName != "" {
+			apiPlacementGroup, err := pgCache.Get(ctx, tx, placementGroupName, dbInst.Project)
+			if err != nil {
+				return err
+			}
+
+			candidates, err = placement.Filter(ctx, tx, candidates, *apiPlacementGroup, false)
+			if err != nil {
+				// Leave the instance in place if moving it would violate its placement group.
+				if api.StatusErrorCheck(err, http.StatusConflict) {
+					return nil
+				}
+
+				return err
+			}
+		}
+
+		if scores != nil {
+			target = clusterRebalanceScoredTarget(scorer, candidates, scores, counts, dbInst.Node)
+		} else {
+			target = clusterRebalanceTarget(candidates, counts, dbInst.Node)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if target == nil {
+		return nil, nil
+	}
+
+	if placementGroupName != "" {
+		movedPlacementGroups[placementGroupKey] = true
+	}
+
+	return &api.ClusterRebalanceMove{
+		Instance: dbInst.Name,
+		Project:  dbInst.Project,
+		Source:   dbInst.Node,
+		Target:   target.Name,
+		Live:     live,
+	}, nil
+}
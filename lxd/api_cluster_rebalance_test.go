@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/placement"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestClusterRebalanceSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		counts    map[string]int
+		threshold int64
+		want      string
+	}{
+		{
+			name:      "single member",
+			counts:    map[string]int{"lxd01": 10},
+			threshold: 20,
+			want:      "",
+		},
+		{
+			name:      "difference of one instance",
+			counts:    map[string]int{"lxd01": 3, "lxd02": 2},
+			threshold: 1,
+			want:      "",
+		},
+		{
+			name:      "below threshold",
+			counts:    map[string]int{"lxd01": 11, "lxd02": 9, "lxd03": 10},
+			threshold: 30,
+			want:      "",
+		},
+		{
+			name:      "above threshold",
+			counts:    map[string]int{"lxd01": 4, "lxd02": 12, "lxd03": 8},
+			threshold: 20,
+			want:      "lxd02",
+		},
+		{
+			name:      "ties are broken by name",
+			counts:    map[string]int{"lxd01": 0, "lxd03": 6, "lxd02": 6},
+			threshold: 20,
+			want:      "lxd02",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clusterRebalanceSource(tt.counts, tt.threshold))
+		})
+	}
+}
+
+func TestClusterRebalanceTarget(t *testing.T) {
+	candidates := []db.NodeInfo{{Name: "lxd01"}, {Name: "lxd02"}, {Name: "lxd03"}, {Name: "lxd04"}}
+
+	tests := []struct {
+		name   string
+		counts map[string]int
+		want   string
+	}{
+		{
+			name:   "least loaded candidate",
+			counts: map[string]int{"lxd01": 8, "lxd02": 3, "lxd03": 2, "lxd04": 5},
+			want:   "lxd03",
+		},
+		{
+			name:   "ties are broken by name",
+			counts: map[string]int{"lxd01": 8, "lxd02": 2, "lxd03": 2, "lxd04": 5},
+			want:   "lxd02",
+		},
+		{
+			name:   "no candidate improves the balance",
+			counts: map[string]int{"lxd01": 8, "lxd02": 7, "lxd03": 8, "lxd04": 7},
+			want:   "",
+		},
+		{
+			name:   "candidates without count are ignored",
+			counts: map[string]int{"lxd01": 8, "lxd04": 7},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := clusterRebalanceTarget(candidates, tt.counts, "lxd01")
+			if tt.want == "" {
+				assert.Nil(t, target)
+				return
+			}
+
+			if assert.NotNil(t, target) {
+				assert.Equal(t, tt.want, target.Name)
+			}
+		})
+	}
+}
+
+// rebalanceTestMemberState returns a member state with the given share of free memory.
+func rebalanceTestMemberState(freeRAM uint64) api.ClusterMemberState {
+	return api.ClusterMemberState{SysInfo: api.ClusterMemberSysInfo{TotalRAM: 100, FreeRAM: freeRAM}}
+}
+
+func TestClusterRebalanceScores(t *testing.T) {
+	counts := map[string]int{"lxd01": 4, "lxd02": 2}
+
+	tests := []struct {
+		name   string
+		scorer *placement.Scorer
+		want   map[string]float64
+	}{
+		{
+			name:   "weighted scheduling disabled",
+			scorer: nil,
+			want:   nil,
+		},
+		{
+			name: "all members scored",
+			scorer: &placement.Scorer{
+				Weights:      placement.Weights{Memory: 1},
+				MemberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(25), "lxd02": rebalanceTestMemberState(75)},
+			},
+			want: map[string]float64{"lxd01": 0.25, "lxd02": 0.75},
+		},
+		{
+			name: "member without state",
+			scorer: &placement.Scorer{
+				Weights:      placement.Weights{Memory: 1},
+				MemberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(25)},
+			},
+			want: nil,
+		},
+		{
+			name: "member that cannot be scored",
+			scorer: &placement.Scorer{
+				Weights:      placement.Weights{Memory: 1},
+				MemberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(25), "lxd02": {}},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clusterRebalanceScores(tt.scorer, counts))
+		})
+	}
+}
+
+func TestClusterRebalanceScoredSource(t *testing.T) {
+	tests := []struct {
+		name      string
+		scores    map[string]float64
+		counts    map[string]int
+		threshold int64
+		want      string
+	}{
+		{
+			name:      "single member",
+			scores:    map[string]float64{"lxd01": 0.1},
+			counts:    map[string]int{"lxd01": 10},
+			threshold: 20,
+			want:      "",
+		},
+		{
+			name:      "below threshold",
+			scores:    map[string]float64{"lxd01": 0.5, "lxd02": 0.6, "lxd03": 0.55},
+			counts:    map[string]int{"lxd01": 2, "lxd02": 2, "lxd03": 2},
+			threshold: 20,
+			want:      "",
+		},
+		{
+			name:      "above threshold",
+			scores:    map[string]float64{"lxd01": 0.8, "lxd02": 0.2, "lxd03": 0.5},
+			counts:    map[string]int{"lxd01": 6, "lxd02": 2, "lxd03": 4},
+			threshold: 20,
+			want:      "lxd02",
+		},
+		{
+			name:      "members without instances are ignored",
+			scores:    map[string]float64{"lxd01": 0.8, "lxd02": 0.2, "lxd03": 0.5},
+			counts:    map[string]int{"lxd01": 6, "lxd02": 0, "lxd03": 4},
+			threshold: 20,
+			want:      "lxd03",
+		},
+		{
+			name:      "ties are broken by name",
+			scores:    map[string]float64{"lxd01": 0.9, "lxd03": 0.3, "lxd02": 0.3},
+			counts:    map[string]int{"lxd01": 1, "lxd02": 1, "lxd03": 1},
+			threshold: 20,
+			want:      "lxd02",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, clusterRebalanceScoredSource(tt.scores, tt.counts, tt.threshold))
+		})
+	}
+}
+
+func TestClusterRebalanceScoredTarget(t *testing.T) {
+	candidates := []db.NodeInfo{{ID: 1, Name: "lxd01"}, {ID: 2, Name: "lxd02"}, {ID: 3, Name: "lxd03"}, {ID: 4, Name: "lxd04"}}
+
+	tests := []struct {
+		name         string
+		memberStates map[string]api.ClusterMemberState
+		counts       map[string]int
+		want         string
+	}{
+		{
+			name:         "best scoring candidate",
+			memberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(10), "lxd02": rebalanceTestMemberState(40), "lxd03": rebalanceTestMemberState(80), "lxd04": rebalanceTestMemberState(60)},
+			counts:       map[string]int{"lxd01": 2, "lxd02": 1, "lxd03": 6, "lxd04": 1},
+			want:         "lxd03",
+		},
+		{
+			name:         "fewest instances among the best scoring candidates",
+			memberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(10), "lxd02": rebalanceTestMemberState(78), "lxd03": rebalanceTestMemberState(80), "lxd04": rebalanceTestMemberState(60)},
+			counts:       map[string]int{"lxd01": 2, "lxd02": 4, "lxd03": 6, "lxd04": 1},
+			want:         "lxd02",
+		},
+		{
+			name:         "no candidate scores better than the source",
+			memberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(90), "lxd02": rebalanceTestMemberState(40), "lxd03": rebalanceTestMemberState(80), "lxd04": rebalanceTestMemberState(60)},
+			counts:       map[string]int{"lxd01": 2, "lxd02": 1, "lxd03": 1, "lxd04": 1},
+			want:         "",
+		},
+		{
+			name:         "candidates without score are ignored",
+			memberStates: map[string]api.ClusterMemberState{"lxd01": rebalanceTestMemberState(10), "lxd02": rebalanceTestMemberState(40)},
+			counts:       map[string]int{"lxd01": 2, "lxd02": 1, "lxd03": 0, "lxd04": 0},
+			want:         "lxd02",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scorer := &placement.Scorer{Weights: placement.Weights{Memory: 1}, MemberStates: tt.memberStates}
+			scores := make(map[string]float64, len(tt.memberStates))
+			for name, memberState := range tt.memberStates {
+				scores[name], _ = scorer.Score(memberState)
+			}
+
+			target := clusterRebalanceScoredTarget(scorer, candidates, scores, tt.counts, "lxd01")
+			if tt.want == "" {
+				assert.Nil(t, target)
+				return
+			}
+
+			if assert.NotNil(t, target) {
+				assert.Equal(t, tt.want, target.Name)
+			}
+		})
+	}
+}
+
+// rebalanceTestOperation is an operation which completes with the given error.
+type rebalanceTestOperation struct {
+	lxd.Operation
+
+	err error
+}
+
+func (op *rebalanceTestOperation) WaitContext(ctx context.Context) error {
+	return op.err
+}
+
+// rebalanceTestServer records the requests made to move an instance.
+type rebalanceTestServer struct {
+	lxd.InstanceServer
+
+	instance    api.Instance
+	status      api.StatusCode
+	target      string
+	shutdownErr error
+	calls       *[]string
+}
+
+func (r *rebalanceTestServer) GetInstance(name string) (*api.Instance, string, error) {
+	return &r.instance, "", nil
+}
+
+func (r *rebalanceTestServer) GetInstanceState(name string) (*api.InstanceState, string, error) {
+	return &api.InstanceState{StatusCode: r.status}, "", nil
+}
+
+func (r *rebalanceTestServer) UseTarget(name string) lxd.InstanceServer {
+	server := *r
+	server.target = name
+	return &server
+}
+
+func (r *rebalanceTestServer) MigrateInstance(name string, req api.InstancePost) (lxd.Operation, error) {
+	*r.calls = append(*r.calls, fmt.Sprintf("migrate target=%s live=%v", r.target, req.Live))
+	return &rebalanceTestOperation{}, nil
+}
+
+func (r *rebalanceTestServer) UpdateInstanceState(name string, req api.InstanceStatePut, ETag string) (lxd.Operation, error) {
+	*r.calls = append(*r.calls, fmt.Sprintf("%s timeout=%d force=%v", req.Action, req.Timeout, req.Force))
+
+	if req.Action == "stop" && !req.Force {
+		return &rebalanceTestOperation{err: r.shutdownErr}, nil
+	}
+
+	return &rebalanceTestOperation{}, nil
+}
+
+func TestClusterRebalanceMigrate(t *testing.T) {
+	tests := []struct {
+		name        string
+		location    string
+		status      api.StatusCode
+		config      map[string]string
+		live        bool
+		shutdownErr error
+		want        []string
+	}{
+		{
+			name:     "already moved",
+			location: "lxd03",
+			status:   api.Running,
+			want:     nil,
+		},
+		{
+			name:     "stopped instance",
+			location: "lxd01",
+			status:   api.Stopped,
+			want:     []string{"migrate target=lxd02 live=false"},
+		},
+		{
+			name:     "live migration",
+			location: "lxd01",
+			status:   api.Running,
+			live:     true,
+			want:     []string{"migrate target=lxd02 live=true"},
+		},
+		{
+			name:     "clean shutdown with the default timeout",
+			location: "lxd01",
+			status:   api.Running,
+			want:     []string{"stop timeout=30 force=false", "migrate target=lxd02 live=false", "start timeout=0 force=false"},
+		},
+		{
+			name:     "clean shutdown with the instance timeout",
+			location: "lxd01",
+			status:   api.Running,
+			config:   map[string]string{"boot.host_shutdown_timeout": "5"},
+			want:     []string{"stop timeout=5 force=false", "migrate target=lxd02 live=false", "start timeout=0 force=false"},
+		},
+		{
+			name:        "forced stop after a failed shutdown",
+			location:    "lxd01",
+			status:      api.Running,
+			shutdownErr: errors.New("Instance didn't stop in time"),
+			want:        []string{"stop timeout=30 force=false", "stop timeout=0 force=true", "migrate target=lxd02 live=false", "start timeout=0 force=false"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			server := &rebalanceTestServer{
+				instance:    api.Instance{Name: "c1", Location: tt.location, ExpandedConfig: tt.config},
+				status:      tt.status,
+				shutdownErr: tt.shutdownErr,
+				calls:       &calls,
+			}
+
+			move := api.ClusterRebalanceMove{Instance: "c1", Project: "default", Source: "lxd01", Target: "lxd02", Live: tt.live}
+
+			err := clusterRebalanceMigrate(context.Background(), server, move)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, calls)
+		})
+	}
+}
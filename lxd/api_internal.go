@@ -68,6 +68,7 @@ var apiInternal = []APIEndpoint{
 	internalPruneTokenCmd,
 	internalOperationWaitCmd,
 	internalSnapshotScheduledTaskCmd,
+	internalBackupScheduledTaskCmd,
 }
 
 var internalShutdownCmd = APIEndpoint{
@@ -189,6 +190,12 @@ var internalSnapshotScheduledTaskCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: internalSnapshotScheduledTask, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
 }
 
+var internalBackupScheduledTaskCmd = APIEndpoint{
+	Path: "testing/backup-scheduled-task",
+
+	Post: APIEndpointAction{Handler: internalBackupScheduledTask, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 type internalImageOptimizePost struct {
 	Image   api.Image `json:"image"    yaml:"image"`
 	Pool    string    `json:"pool"     yaml:"pool"`
@@ -441,7 +448,8 @@ func internalContainerHookLoadFromReference(s *state.State, r *http.Request) (in
 		}
 	}
 
-	if inst.Type() != instancetype.Container {
+	// Krun instances also use the stop hook to notify LXD that their microVM has exited.
+	if inst.Type() != instancetype.Container && inst.Type() != instancetype.Krun {
 		return nil, errors.New("Instance is not container type")
 	}
 
@@ -1190,3 +1198,12 @@ func internalSnapshotScheduledTask(d *Daemon, r *http.Request) response.Response
 
 	return response.EmptySyncResponse
 }
+
+func internalBackupScheduledTask(d *Daemon, r *http.Request) response.Response {
+	err := autoCreateAndPruneScheduledBackups(r.Context(), d.State())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/locking"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
@@ -288,6 +290,20 @@ func metricsGet(d *Daemon, r *http.Request) response.Response {
 	wg.Wait()
 	close(instMetricsCh)
 
+	// Add the network ACL rule counters of the projects.
+	for _, project := range projectsToFetch {
+		aclMetrics := networkACLMetrics(r.Context(), s, *project.Project)
+		if aclMetrics == nil {
+			continue
+		}
+
+		if newMetrics[*project.Project] == nil {
+			newMetrics[*project.Project] = metrics.NewMetricSet(nil)
+		}
+
+		newMetrics[*project.Project].Merge(aclMetrics)
+	}
+
 	// Put the new data in the global cache and in response.
 	metricsCacheLock.Lock()
 
@@ -408,6 +424,55 @@ func clusterMemberWarnings(ctx context.Context, s *state.State, tx *db.ClusterTx
 	return dbCluster.GetWarnings(ctx, tx.Tx(), filters...)
 }
 
+// networkACLMetrics returns the counters of the rules of the network ACLs defined in the project on this member.
+// Returns nil if the project doesn't have any network ACL.
+func networkACLMetrics(ctx context.Context, s *state.State, projectName string) *metrics.MetricSet {
+	var aclNames []string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		aclNames, err = tx.GetNetworkACLs(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed getting network ACLs", logger.Ctx{"project": projectName, "err": err})
+		return nil
+	}
+
+	if len(aclNames) == 0 {
+		return nil
+	}
+
+	out := metrics.NewMetricSet(map[string]string{"project": projectName})
+	for _, aclName := range aclNames {
+		netACL, err := acl.LoadByName(ctx, s, projectName, aclName)
+		if err != nil {
+			logger.Warn("Failed loading network ACL", logger.Ctx{"project": projectName, "networkACL": aclName, "err": err})
+			continue
+		}
+
+		// Only retrieve the counters of this member, as each member exposes its own metrics.
+		aclState, err := netACL.GetState(ctx, request.ClientTypeNotifier)
+		if err != nil {
+			logger.Warn("Failed getting network ACL state", logger.Ctx{"project": projectName, "networkACL": aclName, "err": err})
+			continue
+		}
+
+		addSamples := func(direction string, counters []api.NetworkACLRuleCounters) {
+			for ruleIndex, counter := range counters {
+				labels := map[string]string{"name": aclName, "direction": direction, "rule": strconv.Itoa(ruleIndex)}
+				out.AddSamples(metrics.NetworkACLRulePacketsTotal, metrics.Sample{Labels: labels, Value: float64(counter.Packets)})
+				out.AddSamples(metrics.NetworkACLRuleBytesTotal, metrics.Sample{Labels: labels, Value: float64(counter.Bytes)})
+			}
+		}
+
+		addSamples("ingress", aclState.Ingress)
+		addSamples("egress", aclState.Egress)
+	}
+
+	return out
+}
+
 func internalMetrics(ctx context.Context, s *state.State, tx *db.ClusterTx) *metrics.MetricSet {
 	out := metrics.NewMetricSet(nil)
 
@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"math"
 	"net"
 	"net/http"
 	"net/url"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/auth"
@@ -336,6 +338,12 @@ func projectsPost(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(err)
 	}
 
+	for key := range project.Config {
+		if strings.HasPrefix(key, "volatile.") {
+			return response.BadRequest(fmt.Errorf("Volatile configuration key %q cannot be set", key))
+		}
+	}
+
 	// Validate the configuration.
 	err = projectValidateConfig(r.Context(), s, project.Config, project.Network, project.Name)
 	if err != nil {
@@ -755,6 +763,24 @@ func isProjectInUse(projectUsedBy []string, skipURLs ...string) bool {
 
 // Common logic between PUT and PATCH.
 func projectChange(ctx context.Context, s *state.State, project *api.Project, req api.ProjectPut) response.Response {
+	// Volatile keys are managed internally. Omitted keys are preserved, while added or changed ones are rejected.
+	err := checkVolatileConfig(project.Config, req.Config, false)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	for key, value := range project.Config {
+		if !strings.HasPrefix(key, "volatile.") {
+			continue
+		}
+
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		req.Config[key] = value
+	}
+
 	// Make a list of config keys that have changed.
 	configChanged := []string{}
 	for key := range project.Config {
@@ -811,7 +837,7 @@ func projectChange(ctx context.Context, s *state.State, project *api.Project, re
 	}
 
 	// Validate the configuration.
-	err := projectValidateConfig(ctx, s, req.Config, "", project.Name)
+	err = projectValidateConfig(ctx, s, req.Config, "", project.Name)
 	if err != nil {
 		return response.BadRequest(err)
 	}
@@ -1686,7 +1712,13 @@ func projectPromote(ctx context.Context, s *state.State, projectName string, for
 
 	// Update the replica mode to leader.
 	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		return dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), projectName, api.ReplicatorProjectModeLeader)
+		err := dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), projectName, api.ReplicatorProjectModeLeader)
+		if err != nil {
+			return err
+		}
+
+		// The leader health tracking only applies to standby projects.
+		return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), projectName, map[string]string{"volatile.replica.unreachable_since": ""})
 	})
 	if err != nil {
 		return fmt.Errorf("Failed updating project replica mode: %w", err)
@@ -1729,7 +1761,12 @@ func projectDemote(ctx context.Context, s *state.State, projectName string, forc
 
 	// Update the replica mode to standby.
 	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
-		return dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), projectName, api.ReplicatorProjectModeStandby)
+		err := dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), projectName, api.ReplicatorProjectModeStandby)
+		if err != nil {
+			return err
+		}
+
+		return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), projectName, replicaFailoverVolatileReset)
 	})
 	if err != nil {
 		return fmt.Errorf("Failed updating project replica mode: %w", err)
@@ -1758,6 +1795,11 @@ func projectClearReplicaMode(ctx context.Context, s *state.State, projectName st
 			return fmt.Errorf("Failed updating project replica mode: %w", err)
 		}
 
+		err = dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), projectName, replicaFailoverVolatileReset)
+		if err != nil {
+			return fmt.Errorf("Failed clearing project replica state: %w", err)
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -2199,6 +2241,42 @@ func projectValidateConfig(ctx context.Context, s *state.State, config map[strin
 
 			return s.Authorizer.CheckPermission(ctx, entity.ClusterLinkURL(value), auth.EntitlementCanView)
 		}),
+
+		// lxdmeta:generate(entities=project; group=replica; key=replica.failover)
+		// When enabled on a standby project, the project is automatically promoted to leader and its instances
+		// are started once the cluster link set in {config:option}`project-replica:replica.cluster` has been
+		// unreachable for longer than {config:option}`project-replica:replica.failover.grace_period`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to automatically promote this standby project when its leader is unreachable
+		"replica.failover": validate.Optional(validate.IsBool),
+
+		// lxdmeta:generate(entities=project; group=replica; key=replica.failover.grace_period)
+		// Number of seconds the leader cluster must be unreachable before the standby project is automatically promoted.
+		// ---
+		//  type: integer
+		//  defaultdesc: `300`
+		//  shortdesc: Seconds the leader must be unreachable before automatic promotion
+		"replica.failover.grace_period": validate.Optional(validate.IsInRange(60, math.MaxInt32)),
+
+		// lxdmeta:generate(entities=project; group=replica; key=volatile.replica.unreachable_since)
+		// Set by LXD on standby projects with {config:option}`project-replica:replica.failover` enabled while the leader cluster is unreachable.
+		// ---
+		//  type: string
+		//  shortdesc: Time since which the leader cluster has been unreachable
+		"volatile.replica.unreachable_since": validate.Optional(func(value string) error {
+			_, err := time.Parse(time.RFC3339, value)
+			return err
+		}),
+
+		// lxdmeta:generate(entities=project; group=replica; key=volatile.replica.fenced_leader)
+		// Set by LXD when a standby project is automatically promoted. The former leader cluster with this
+		// certificate fingerprint demotes its own project to standby once it becomes reachable again.
+		// ---
+		//  type: string
+		//  shortdesc: Certificate fingerprint of the fenced former leader cluster
+		"volatile.replica.fenced_leader": validate.Optional(validate.IsLowercaseHex),
 	}
 
 	// Add the storage pool keys.
@@ -7,8 +7,13 @@ import (
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -35,6 +40,25 @@ import (
 	"github.com/canonical/lxd/shared/version"
 )
 
+const (
+	// replicatorModeFull refreshes the instances and all their snapshots on each run.
+	replicatorModeFull = "full"
+
+	// replicatorModeIncremental only transfers the snapshots taken since the last common snapshot on each run.
+	replicatorModeIncremental = "incremental"
+)
+
+const (
+	// replicatorRunActionStart is recorded for runs replicating instances to the cluster link.
+	replicatorRunActionStart = "start"
+
+	// replicatorRunActionRestore is recorded for runs restoring instances from the cluster link.
+	replicatorRunActionRestore = "restore"
+
+	// replicatorRunHistoryLimit is the number of past runs kept for each replicator.
+	replicatorRunHistoryLimit = 50
+)
+
 var replicatorsCmd = APIEndpoint{
 	Path:            "replicators",
 	MetricsType:     entity.TypeReplicator,
@@ -65,6 +89,14 @@ var replicatorStateCmd = APIEndpoint{
 	Put: APIEndpointAction{Handler: replicatorStatePut, AccessHandler: allowPermission(entity.TypeReplicator, auth.EntitlementCanEdit, "name")},
 }
 
+var replicatorRunsCmd = APIEndpoint{
+	Path:            "replicators/{name}/runs",
+	MetricsType:     entity.TypeReplicator,
+	ProjectSpecific: true,
+
+	Get: APIEndpointAction{Handler: replicatorRunsGet, AccessHandler: allowPermission(entity.TypeReplicator, auth.EntitlementCanView, "name")},
+}
+
 // swagger:operation GET /1.0/replicators replicators replicators_get
 //
 //	Get the replicators
@@ -369,6 +401,30 @@ func replicatorValidateConfig(ctx context.Context, s *state.State, config map[st
 		//  shortdesc: Cron expression for the replication schedule.
 		//  scope: global
 		"schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+
+		// lxdmeta:generate(entities=replicator; group=conf; key=mode)
+		// Possible values are `full` and `incremental`.
+		// In `full` mode, each run refreshes the instances and all their snapshots.
+		// In `incremental` mode, each run creates a replication point snapshot on every instance and only
+		// transfers the snapshots taken since the last snapshot that exists on both clusters, sending the
+		// data incrementally from it. Older snapshots are left alone on both clusters.
+		// ---
+		//  type: string
+		//  defaultdesc: `full`
+		//  shortdesc: Replication mode.
+		//  scope: global
+		"mode": validate.Optional(validate.IsOneOf(replicatorModeFull, replicatorModeIncremental)),
+
+		// lxdmeta:generate(entities=replicator; group=conf; key=retention)
+		// Number of replication point snapshots to keep on each instance.
+		// Older replication points are deleted from both clusters after a successful run.
+		// This can only be set when {config:option}`replicator-conf:mode` is `incremental`.
+		// ---
+		//  type: integer
+		//  defaultdesc: `1`
+		//  shortdesc: Number of replication points to keep.
+		//  scope: global
+		"retention": validate.Optional(validate.IsInRange(1, math.MaxInt32)),
 	}
 
 	for k, v := range config {
@@ -392,6 +448,11 @@ func replicatorValidateConfig(ctx context.Context, s *state.State, config map[st
 		}
 	}
 
+	// Replication points are only created in incremental mode, so there is nothing to retain otherwise.
+	if config["retention"] != "" && config["mode"] != replicatorModeIncremental {
+		return fmt.Errorf("Replicator configuration key %q requires %q to be %q", "retention", "mode", replicatorModeIncremental)
+	}
+
 	// The validator loop only runs for keys present in config, so a missing "cluster" key
 	// must be caught separately.
 	if config["cluster"] == "" {
@@ -664,12 +725,11 @@ func replicatorStatePut(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
-	clusterLinkName := apiReplicator.Config["cluster"]
-	if clusterLinkName == "" {
+	if apiReplicator.Config["cluster"] == "" {
 		return response.BadRequest(fmt.Errorf("Replicator %q has no cluster link configured", name))
 	}
 
-	opArgs, err := prepareReplicatorRunOperation(r.Context(), s, projectName, name, clusterLinkName, restore, dbReplicator.Row.ID)
+	opArgs, err := prepareReplicatorRunOperation(r.Context(), s, projectName, name, apiReplicator.Config, restore, dbReplicator.Row.ID)
 	if err != nil {
 		return response.SmartError(err)
 	}
@@ -979,6 +1039,77 @@ func replicatorStateGet(d *Daemon, r *http.Request) response.Response {
 	return response.SyncResponse(true, api.ReplicatorState{Status: status})
 }
 
+// swagger:operation GET /1.0/replicators/{name}/runs replicators replicator_runs_get
+//
+//	Get the replicator run history
+//
+//	Gets the most recent runs of the replicator along with the outcome for each instance.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Replicator runs
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of replicator runs, most recent first
+//	          items:
+//	            $ref: "#/definitions/ReplicatorRun"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func replicatorRunsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := request.ProjectParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	name := r.PathValue("name")
+	var runs []api.ReplicatorRun
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbReplicator, err := dbCluster.GetReplicator(ctx, tx.Tx(), name, projectName)
+		if err != nil {
+			return err
+		}
+
+		runs, err = dbCluster.GetReplicatorRuns(ctx, tx.Tx(), dbReplicator.Row.ID)
+		return err
+	})
+	if err != nil {
+		return response.SmartError(fmt.Errorf("Failed loading replicator runs for %q: %w", name, err))
+	}
+
+	return response.SyncResponse(true, runs)
+}
+
 // runScheduledReplicatorsTask returns a background task that checks replicator schedules every minute
 // and triggers replication for any replicator whose cron expression matches the current time.
 func runScheduledReplicatorsTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
@@ -1005,14 +1136,20 @@ func runScheduledReplicatorsTask(stateFunc func() *state.State) (task.Func, task
 }
 
 // prepareReplicatorRunOperation builds the operation used to run a replicator.
-func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectName string, name string, clusterLinkName string, restore bool, replicatorID int64) (operations.OperationArgs, error) {
+func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectName string, name string, config map[string]string, restore bool, replicatorID int64) (operations.OperationArgs, error) {
+	clusterLinkName := config["cluster"]
+	points, err := replicatorPointsFromConfig(name, config)
+	if err != nil {
+		return operations.OperationArgs{}, err
+	}
+
 	// Load all DB state in a single transaction before any network I/O.
 	var clusterLink *api.ClusterLink
 	var targetCert *x509.Certificate
 	var sourceProject *api.Project
 	var allInsts []instance.Instance
 	var nodeAddressByName map[string]string
-	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 		_, clusterLink, targetCert, err = lxdCluster.LoadClusterLinkAndCert(ctx, tx.Tx(), clusterLinkName)
 		if err != nil {
@@ -1117,20 +1254,26 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 
 	// Forward replication: iterate over all loaded instances directly.
 	if !restore {
+		instNames := make([]string, 0, len(allInsts))
+		for _, inst := range allInsts {
+			instNames = append(instNames, inst.Name())
+		}
+
+		run := newReplicatorRun(replicatorRunActionStart, instNames)
 		childArgs := make([]*operations.OperationArgs, 0, len(allInsts))
 
 		for _, inst := range allInsts {
 			memberAddress := nodeAddressByName[inst.Location()]
 
-			copyFunc := func(ctx context.Context, op *operations.Operation) error {
+			copyFunc := func(ctx context.Context, op *operations.Operation) (int64, error) {
 				dstClient, err := lxdCluster.ConnectCluster(ctx, *clusterLink, lxdCluster.GetClusterLinkConnectionArgs(clusterCert, targetCert))
 				if err != nil {
-					return fmt.Errorf("Failed connecting to target cluster: %w", err)
+					return 0, fmt.Errorf("Failed connecting to target cluster: %w", err)
 				}
 
 				dstClient = dstClient.UseProject(projectName)
 
-				return replicateInstance(ctx, s, op, inst, memberAddress, dstClient, targetCertPEM)
+				return replicateInstance(ctx, s, op, inst, memberAddress, dstClient, targetCertPEM, points)
 			}
 
 			childArgs = append(childArgs, &operations.OperationArgs{
@@ -1141,7 +1284,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 				Metadata: map[string]any{
 					api.MetadataEntityURL: entity.InstanceURL(projectName, inst.Name()).String(),
 				},
-				RunHook: copyFunc,
+				RunHook: run.instanceHook(inst.Name(), copyFunc),
 			})
 		}
 
@@ -1152,25 +1295,12 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 			Class:             operationtype.OperationClassTask,
 			ConflictReference: replicatorURL.String(), // Prevents concurrent runs; paired with ConflictActionFail on the operation type to enforce cluster-wide exclusivity.
 			Children:          childArgs,
-			RunHook: func(_ context.Context, op *operations.Operation) error {
-				runStatus := api.ReplicatorStatusCompleted
-				for _, child := range op.Children() {
-					if child.Status() != api.Success {
-						runStatus = api.ReplicatorStatusFailed
-						break
-					}
-				}
-
-				// Use a fresh context so the status write always completes, even if the operation context was cancelled.
-				// Only the status is updated here; last_run_date was already set when the operation started.
-				return s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
-					return dbCluster.UpdateReplicatorLastRunStatus(ctx, tx.Tx(), replicatorID, runStatus)
-				})
-			},
+			RunHook:           run.finishHook(s, replicatorID),
 		}, nil
 	}
 
 	// Restore mode: iterate over the current leader cluster's instance list.
+	run := newReplicatorRun(replicatorRunActionRestore, iterNames)
 	childArgs := make([]*operations.OperationArgs, 0, len(iterNames))
 
 	// Use our cluster certificate so the leader can verify TLS when
@@ -1178,10 +1308,10 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 	localCertPEM := string(clusterCert.PublicKey())
 
 	for _, instName := range iterNames {
-		copyFunc := func(ctx context.Context, op *operations.Operation) error {
+		copyFunc := func(ctx context.Context, op *operations.Operation) (int64, error) {
 			dstClient, err := lxdCluster.ConnectCluster(ctx, *clusterLink, lxdCluster.GetClusterLinkConnectionArgs(clusterCert, targetCert))
 			if err != nil {
-				return fmt.Errorf("Failed connecting to target cluster: %w", err)
+				return 0, fmt.Errorf("Failed connecting to target cluster: %w", err)
 			}
 
 			dstClient = dstClient.UseProject(projectName)
@@ -1194,10 +1324,10 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 					// Instance was deleted on the current leader cluster after failover; skip it rather
 					// than failing the whole run, since the deletion is intentional.
 					logger.Warn("Skipping restore of instance deleted on current leader cluster", logger.Ctx{"instance": instName})
-					return nil
+					return 0, replicatorSkipError{reason: "Instance was deleted on the current leader cluster"}
 				}
 
-				return fmt.Errorf("Failed getting instance %q from current leader cluster: %w", instName, err)
+				return 0, fmt.Errorf("Failed getting instance %q from current leader cluster: %w", instName, err)
 			}
 
 			// If the instance lives on a remote cluster member, forward the restore
@@ -1218,7 +1348,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 			if memberAddress != "" {
 				memberClient, err := lxdCluster.Connect(ctx, memberAddress, s.Endpoints.NetworkCert(), s.ServerCert(), true)
 				if err != nil {
-					return fmt.Errorf("Failed connecting to hosting cluster member for instance %q: %w", instName, err)
+					return 0, fmt.Errorf("Failed connecting to hosting cluster member for instance %q: %w", instName, err)
 				}
 
 				memberClient = memberClient.UseProject(projectName)
@@ -1235,7 +1365,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 					},
 				})
 				if err != nil {
-					return fmt.Errorf("Failed requesting restore on hosting cluster member for instance %q: %w", instName, err)
+					return 0, fmt.Errorf("Failed requesting restore on hosting cluster member for instance %q: %w", instName, err)
 				}
 
 				restoreOpCancelled := false
@@ -1248,7 +1378,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 				restoreOpAPI := restoreOp.Get()
 				restoreSecrets, err := restoreOpAPI.WebsocketSecrets()
 				if err != nil {
-					return fmt.Errorf("Failed getting websocket secrets from hosting cluster member for instance %q: %w", instName, err)
+					return 0, fmt.Errorf("Failed getting websocket secrets from hosting cluster member for instance %q: %w", instName, err)
 				}
 
 				// Tell the current leader cluster to push-migrate the instance to the hosting cluster member's sink.
@@ -1261,17 +1391,22 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 					},
 				})
 				if err != nil {
-					return fmt.Errorf("Failed starting push migration on current leader cluster for instance %q: %w", instName, err)
+					return 0, fmt.Errorf("Failed starting push migration on current leader cluster for instance %q: %w", instName, err)
 				}
 
 				restoreOpCancelled = true
 
 				err = remoteMigrateOp.Wait()
 				if err != nil {
-					return fmt.Errorf("Restore of instance %q failed on current leader cluster: %w", instName, err)
+					return 0, fmt.Errorf("Restore of instance %q failed on current leader cluster: %w", instName, err)
 				}
 
-				return restoreOp.Wait()
+				err = restoreOp.Wait()
+				if err != nil {
+					return 0, err
+				}
+
+				return replicatorTransferredBytes(remoteMigrateOp.Get().Metadata), nil
 			}
 
 			// Load profiles for the instance to pass to the migration sink.
@@ -1281,7 +1416,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 				return err
 			})
 			if err != nil {
-				return fmt.Errorf("Failed loading profiles for instance %q: %w", instName, err)
+				return 0, fmt.Errorf("Failed loading profiles for instance %q: %w", instName, err)
 			}
 
 			// Set up a push-mode migration sink locally so the leader pushes data to us.
@@ -1306,7 +1441,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 
 			result, err := prepareInstanceMigrationSink(ctx, s, projectName, profiles, migrateReq, "")
 			if err != nil {
-				return fmt.Errorf("Failed preparing migration sink for instance %q: %w", instName, err)
+				return 0, fmt.Errorf("Failed preparing migration sink for instance %q: %w", instName, err)
 			}
 
 			defer result.revert.Fail()
@@ -1330,13 +1465,13 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 			}
 
 			if err != nil {
-				return fmt.Errorf("Failed scheduling migration sink operation for instance %q: %w", instName, err)
+				return 0, fmt.Errorf("Failed scheduling migration sink operation for instance %q: %w", instName, err)
 			}
 
 			_, sinkOpAPI := sinkOp.Render()
 			sinkSecrets, err := sinkOpAPI.WebsocketSecrets()
 			if err != nil {
-				return fmt.Errorf("Failed getting websocket secrets from local sink for instance %q: %w", instName, err)
+				return 0, fmt.Errorf("Failed getting websocket secrets from local sink for instance %q: %w", instName, err)
 			}
 
 			// Build the operation URL using a reachable address for this server.
@@ -1354,7 +1489,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 
 				if util.IsWildCardAddress(localAddress) || localAddress == "" {
 					sinkOp.Cancel()
-					return errors.New("Cannot restore to this server: configure a concrete address using cluster.https_address or core.https_address")
+					return 0, errors.New("Cannot restore to this server: configure a concrete address using cluster.https_address or core.https_address")
 				}
 			}
 
@@ -1371,22 +1506,22 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 			})
 			if err != nil {
 				sinkOp.Cancel()
-				return fmt.Errorf("Failed starting push migration on current leader cluster for instance %q: %w", instName, err)
+				return 0, fmt.Errorf("Failed starting push migration on current leader cluster for instance %q: %w", instName, err)
 			}
 
 			remoteErr := remoteMigrateOp.Wait()
 			if remoteErr != nil {
 				sinkOp.Cancel()
-				return fmt.Errorf("Restore of instance %q failed on current leader cluster: %w", instName, remoteErr)
+				return 0, fmt.Errorf("Restore of instance %q failed on current leader cluster: %w", instName, remoteErr)
 			}
 
 			sinkErr := sinkOp.Wait(context.Background())
 			if sinkErr != nil {
-				return fmt.Errorf("Restore of instance %q failed: %w", instName, sinkErr)
+				return 0, fmt.Errorf("Restore of instance %q failed: %w", instName, sinkErr)
 			}
 
 			result.revert.Success()
-			return nil
+			return replicatorTransferredBytes(remoteMigrateOp.Get().Metadata), nil
 		}
 
 		childArgs = append(childArgs, &operations.OperationArgs{
@@ -1397,7 +1532,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 			Metadata: map[string]any{
 				api.MetadataEntityURL: entity.InstanceURL(projectName, instName).String(),
 			},
-			RunHook: copyFunc,
+			RunHook: run.instanceHook(instName, copyFunc),
 		})
 	}
 
@@ -1408,21 +1543,7 @@ func prepareReplicatorRunOperation(ctx context.Context, s *state.State, projectN
 		Class:             operationtype.OperationClassTask,
 		ConflictReference: replicatorURL.String(), // Prevents concurrent runs; paired with ConflictActionFail on the operation type to enforce cluster-wide exclusivity.
 		Children:          childArgs,
-		RunHook: func(_ context.Context, op *operations.Operation) error {
-			runStatus := api.ReplicatorStatusCompleted
-			for _, child := range op.Children() {
-				if child.Status() != api.Success {
-					runStatus = api.ReplicatorStatusFailed
-					break
-				}
-			}
-
-			// Use a fresh context so the status write always completes, even if the operation context was cancelled.
-			// Only the status is updated here; last_run_date was already set when the operation started.
-			return s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
-				return dbCluster.UpdateReplicatorLastRunStatus(ctx, tx.Tx(), replicatorID, runStatus)
-			})
-		},
+		RunHook:           run.finishHook(s, replicatorID),
 	}, nil
 }
 
@@ -1439,10 +1560,281 @@ func replicatorCheckInstancesStopped(allInsts []instance.Instance) error {
 	return nil
 }
 
+// replicatorPoints holds the replication point settings of a replicator running in incremental mode.
+type replicatorPoints struct {
+	// Name prefix of the replication point snapshots.
+	prefix string
+
+	// Number of replication points to keep.
+	retention int
+}
+
+// replicatorPointsFromConfig returns the replication point settings of the replicator with the given name
+// and config. It returns nil if the replicator isn't running in incremental mode.
+func replicatorPointsFromConfig(name string, config map[string]string) (*replicatorPoints, error) {
+	if config["mode"] != replicatorModeIncremental {
+		return nil, nil
+	}
+
+	retention := 1
+	if config["retention"] != "" {
+		var err error
+		retention, err = strconv.Atoi(config["retention"])
+		if err != nil || retention < 1 {
+			return nil, fmt.Errorf("Invalid replicator retention %q", config["retention"])
+		}
+	}
+
+	return &replicatorPoints{
+		prefix:    "replicator-" + name + "-",
+		retention: retention,
+	}, nil
+}
+
+// instanceSource returns the source used to set up the migration sink of an instance on the target.
+// In incremental mode the target only requests the snapshots taken after the last snapshot that both
+// sides have in common and leaves the older ones alone.
+func (p *replicatorPoints) instanceSource() api.InstanceSource {
+	return api.InstanceSource{
+		Type:                api.SourceTypeMigration,
+		Mode:                "push",
+		Refresh:             true,
+		RefreshExcludeOlder: p != nil,
+	}
+}
+
+// nextName returns the snapshot name of the next replication point of the given instance.
+func (p *replicatorPoints) nextName(ctx context.Context, s *state.State, inst instance.Instance) (string, error) {
+	var index int
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		index = tx.GetNextInstanceSnapshotIndex(ctx, inst.Project().Name, inst.Name(), p.prefix+"%d")
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return p.prefix + strconv.Itoa(index), nil
+}
+
+// expired returns the replication points in snapshotNames which exceed the retention, oldest first.
+// Snapshots which aren't replication points of this replicator are ignored.
+func (p *replicatorPoints) expired(snapshotNames []string) []string {
+	type point struct {
+		name  string
+		index int
+	}
+
+	points := make([]point, 0, len(snapshotNames))
+	for _, name := range snapshotNames {
+		suffix, ok := strings.CutPrefix(name, p.prefix)
+		if !ok {
+			continue
+		}
+
+		index, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+
+		points = append(points, point{name: name, index: index})
+	}
+
+	if len(points) <= p.retention {
+		return nil
+	}
+
+	slices.SortFunc(points, func(a point, b point) int { return a.index - b.index })
+
+	expired := make([]string, 0, len(points)-p.retention)
+	for _, pt := range points[:len(points)-p.retention] {
+		expired = append(expired, pt.name)
+	}
+
+	return expired
+}
+
+// pruneRemote deletes the expired replication points of an instance through the given client.
+func (p *replicatorPoints) pruneRemote(client lxd.InstanceServer, instName string) error {
+	snapshotNames, err := client.GetInstanceSnapshotNames(instName)
+	if err != nil {
+		return fmt.Errorf("Failed listing snapshots of instance %q: %w", instName, err)
+	}
+
+	for _, snapName := range p.expired(snapshotNames) {
+		op, err := client.DeleteInstanceSnapshot(instName, snapName, "")
+		if err != nil {
+			return fmt.Errorf("Failed deleting replication point %q of instance %q: %w", snapName, instName, err)
+		}
+
+		err = op.Wait()
+		if err != nil {
+			return fmt.Errorf("Failed deleting replication point %q of instance %q: %w", snapName, instName, err)
+		}
+	}
+
+	return nil
+}
+
+// pruneLocal deletes the expired replication points of an instance located on the local cluster member.
+func (p *replicatorPoints) pruneLocal(ctx context.Context, inst instance.Instance) error {
+	snapshots, err := inst.Snapshots()
+	if err != nil {
+		return fmt.Errorf("Failed listing snapshots of instance %q: %w", inst.Name(), err)
+	}
+
+	snapshotsByName := make(map[string]instance.Instance, len(snapshots))
+	for _, snapshot := range snapshots {
+		_, snapName, _ := api.GetParentAndSnapshotName(snapshot.Name())
+		snapshotsByName[snapName] = snapshot
+	}
+
+	for _, snapName := range p.expired(slices.Collect(maps.Keys(snapshotsByName))) {
+		err = snapshotsByName[snapName].Delete(ctx, true, "", nil)
+		if err != nil {
+			return fmt.Errorf("Failed deleting replication point %q of instance %q: %w", snapName, inst.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// replicatorSkipError is returned by a replicator instance copy function to indicate that the instance was
+// intentionally not replicated. It is recorded in the run history and does not fail the run.
+type replicatorSkipError struct {
+	reason string
+}
+
+// Error returns the reason the instance was skipped.
+func (e replicatorSkipError) Error() string {
+	return e.reason
+}
+
+// replicatorRun records the outcome of a replicator run for its run history.
+type replicatorRun struct {
+	mu        sync.Mutex
+	action    string
+	startedAt time.Time
+	instances []string
+	results   map[string]api.ReplicatorRunInstance
+}
+
+// newReplicatorRun returns a new run recorder for the given action and instance names.
+func newReplicatorRun(action string, instances []string) *replicatorRun {
+	return &replicatorRun{
+		action:    action,
+		startedAt: time.Now(),
+		instances: instances,
+		results:   make(map[string]api.ReplicatorRunInstance, len(instances)),
+	}
+}
+
+// instanceHook wraps the copy function of an instance into an operation RunHook that records its outcome.
+func (r *replicatorRun) instanceHook(instName string, copyFunc func(ctx context.Context, op *operations.Operation) (int64, error)) func(ctx context.Context, op *operations.Operation) error {
+	return func(ctx context.Context, op *operations.Operation) error {
+		transferred, err := copyFunc(ctx, op)
+
+		result := api.ReplicatorRunInstance{
+			Name:             instName,
+			Status:           api.ReplicatorRunInstanceStatusSucceeded,
+			BytesTransferred: transferred,
+		}
+
+		var skipErr replicatorSkipError
+		if errors.As(err, &skipErr) {
+			result.Status = api.ReplicatorRunInstanceStatusSkipped
+			result.Reason = skipErr.reason
+			err = nil
+		} else if err != nil {
+			result.Status = api.ReplicatorRunInstanceStatusFailed
+			result.Reason = err.Error()
+		}
+
+		r.mu.Lock()
+		r.results[instName] = result
+		r.mu.Unlock()
+
+		return err
+	}
+}
+
+// finish returns the recorded run with the given status. Instances without a recorded outcome are
+// reported as failed.
+func (r *replicatorRun) finish(status string) api.ReplicatorRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	run := api.ReplicatorRun{
+		Action:     r.action,
+		StartedAt:  r.startedAt,
+		FinishedAt: time.Now(),
+		Status:     status,
+		Instances:  make([]api.ReplicatorRunInstance, 0, len(r.instances)),
+	}
+
+	for _, instName := range r.instances {
+		result, ok := r.results[instName]
+		if !ok {
+			result = api.ReplicatorRunInstance{
+				Name:   instName,
+				Status: api.ReplicatorRunInstanceStatusFailed,
+				Reason: "Instance was not replicated",
+			}
+		}
+
+		run.BytesTransferred += result.BytesTransferred
+		run.Instances = append(run.Instances, result)
+	}
+
+	return run
+}
+
+// finishHook returns the RunHook of the parent replicator run operation. It records the terminal status
+// of the run along with its history entry.
+func (r *replicatorRun) finishHook(s *state.State, replicatorID int64) func(ctx context.Context, op *operations.Operation) error {
+	return func(_ context.Context, op *operations.Operation) error {
+		runStatus := api.ReplicatorStatusCompleted
+		for _, child := range op.Children() {
+			if child.Status() != api.Success {
+				runStatus = api.ReplicatorStatusFailed
+				break
+			}
+		}
+
+		run := r.finish(runStatus)
+
+		// Use a fresh context so the status write always completes, even if the operation context was cancelled.
+		// Only the status is updated here; last_run_date was already set when the operation started.
+		return s.DB.Cluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			err := dbCluster.UpdateReplicatorLastRunStatus(ctx, tx.Tx(), replicatorID, runStatus)
+			if err != nil {
+				return err
+			}
+
+			return dbCluster.CreateReplicatorRun(ctx, tx.Tx(), run, replicatorID, replicatorRunHistoryLimit)
+		})
+	}
+}
+
+// replicatorTransferredBytes returns the number of bytes sent by a migration source operation as
+// recorded in its metadata.
+func replicatorTransferredBytes(metadata map[string]any) int64 {
+	switch value := metadata["transferred_bytes"].(type) {
+	case int64:
+		return value
+	case float64:
+		return int64(value)
+	}
+
+	return 0
+}
+
 // replicateInstance handles forward replication of a single instance to the
 // destination cluster. It handles both instances on the local cluster member
-// and instances on other cluster members.
-func replicateInstance(ctx context.Context, s *state.State, op *operations.Operation, inst instance.Instance, memberAddress string, dstClient lxd.InstanceServer, targetCertPEM string) error {
+// and instances on other cluster members. When points is set the replicator
+// runs in incremental mode and a replication point is created and pruned.
+// It returns the number of bytes sent to the destination.
+func replicateInstance(ctx context.Context, s *state.State, op *operations.Operation, inst instance.Instance, memberAddress string, dstClient lxd.InstanceServer, targetCertPEM string, points *replicatorPoints) (int64, error) {
 	instName := inst.Name()
 	projectName := inst.Project().Name
 	// Snapshotting is unconditional; the only exception is when the instance already has a
@@ -1450,39 +1842,56 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 	// an extra one here would be redundant.
 	createSnapshot := inst.ExpandedConfig()["snapshots.schedule"] == ""
 
+	// In incremental mode the replication point is always created as the changes of the
+	// next run are computed from it.
+	var snapName string
+	if points != nil {
+		if !dstClient.HasExtension("replicator_incremental") {
+			return 0, fmt.Errorf("Target cluster is missing the %q API extension required by incremental mode", "replicator_incremental")
+		}
+
+		createSnapshot = true
+
+		var err error
+		snapName, err = points.nextName(ctx, s, inst)
+		if err != nil {
+			return 0, fmt.Errorf("Failed generating replication point name for instance %q: %w", instName, err)
+		}
+	}
+
 	// Instance on another cluster member: connect to the hosting cluster member and
 	// drive the snapshot (if needed) and push migration through its API so the
 	// migration source has direct access to the instance's storage.
 	if inst.Location() != s.ServerName {
 		if memberAddress == "" {
-			return fmt.Errorf("Failed resolving cluster member address for instance %q", instName)
+			return 0, fmt.Errorf("Failed resolving cluster member address for instance %q", instName)
 		}
 
 		// Connect to the hosting cluster member.
 		memberClient, err := lxdCluster.Connect(ctx, memberAddress, s.Endpoints.NetworkCert(), s.ServerCert(), false)
 		if err != nil {
-			return fmt.Errorf("Failed connecting to hosting cluster member for instance %q: %w", instName, err)
+			return 0, fmt.Errorf("Failed connecting to hosting cluster member for instance %q: %w", instName, err)
 		}
 
 		memberClient = memberClient.UseProject(projectName)
 
 		// Create a snapshot on the hosting cluster member if needed.
 		if createSnapshot {
-			snapOp, err := memberClient.CreateInstanceSnapshot(instName, api.InstanceSnapshotsPost{})
+			snapOp, err := memberClient.CreateInstanceSnapshot(instName, api.InstanceSnapshotsPost{Name: snapName})
 			if err != nil {
-				return fmt.Errorf("Failed creating snapshot of instance %q on hosting cluster member: %w", instName, err)
+				return 0, fmt.Errorf("Failed creating snapshot of instance %q on hosting cluster member: %w", instName, err)
 			}
 
 			err = snapOp.Wait()
 			if err != nil {
-				return fmt.Errorf("Failed waiting for snapshot of instance %q on hosting cluster member: %w", instName, err)
+				return 0, fmt.Errorf("Failed waiting for snapshot of instance %q on hosting cluster member: %w", instName, err)
 			}
 		}
 
 		// Get instance metadata from the hosting cluster member.
 		srcInstInfo, _, err := memberClient.GetInstance(instName)
 		if err != nil {
-			return fmt.Errorf("Failed getting instance %q from hosting cluster member: %w", instName, err)
+			return 0, fmt.Errorf("Failed getting instance %q from hosting cluster member: %w", instName, err)
 		}
 
 		// Set up a push-mode migration sink on the destination.
@@ -1490,14 +1899,10 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 			Name:        instName,
 			InstancePut: srcInstInfo.Writable(),
 			Type:        api.InstanceType(srcInstInfo.Type),
-			Source: api.InstanceSource{
-				Type:    api.SourceTypeMigration,
-				Mode:    "push",
-				Refresh: true,
-			},
+			Source:      points.instanceSource(),
 		})
 		if err != nil {
-			return fmt.Errorf("Failed requesting instance create on destination for %q: %w", instName, err)
+			return 0, fmt.Errorf("Failed requesting instance create on destination for %q: %w", instName, err)
 		}
 
 		destOpCancelled := false
@@ -1510,7 +1915,7 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 		destOpAPI := destOp.Get()
 		destSecrets, err := destOpAPI.WebsocketSecrets()
 		if err != nil {
-			return fmt.Errorf("Failed getting websocket secrets from destination for instance %q: %w", instName, err)
+			return 0, fmt.Errorf("Failed getting websocket secrets from destination for instance %q: %w", instName, err)
 		}
 
 		// Tell the hosting cluster member to push-migrate the instance to the destination.
@@ -1523,40 +1928,59 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 			},
 		})
 		if err != nil {
-			return fmt.Errorf("Failed starting push migration for instance %q: %w", instName, err)
+			return 0, fmt.Errorf("Failed starting push migration for instance %q: %w", instName, err)
 		}
 
 		err = srcMigrateOp.Wait()
 		if err != nil {
-			return fmt.Errorf("Replication of instance %q failed on hosting cluster member: %w", instName, err)
+			return 0, fmt.Errorf("Replication of instance %q failed on hosting cluster member: %w", instName, err)
 		}
 
 		destOpCancelled = true
 
-		return destOp.Wait()
+		err = destOp.Wait()
+		if err != nil {
+			return 0, err
+		}
+
+		transferred := replicatorTransferredBytes(srcMigrateOp.Get().Metadata)
+
+		if points != nil {
+			err = points.pruneRemote(memberClient, instName)
+			if err != nil {
+				return transferred, err
+			}
+
+			return transferred, points.pruneRemote(dstClient, instName)
+		}
+
+		return transferred, nil
 	}
 
 	// Local instance: handle replication directly.
 	if createSnapshot {
-		snapName, err := instance.NextSnapshotName(s, inst, "snap%d")
-		if err != nil {
-			return fmt.Errorf("Failed generating snapshot name for instance %q: %w", instName, err)
+		if snapName == "" {
+			var err error
+			snapName, err = instance.NextSnapshotName(s, inst, "snap%d")
+			if err != nil {
+				return 0, fmt.Errorf("Failed generating snapshot name for instance %q: %w", instName, err)
+			}
 		}
 
-		err = inst.Snapshot(ctx, snapName, nil, false, api.DiskVolumesModeRoot, nil)
+		err := inst.Snapshot(ctx, snapName, nil, false, api.DiskVolumesModeRoot, nil)
 		if err != nil {
-			return fmt.Errorf("Failed creating snapshot of instance %q: %w", instName, err)
+			return 0, fmt.Errorf("Failed creating snapshot of instance %q: %w", instName, err)
 		}
 	}
 
 	srcRenderRes, _, err := inst.Render()
 	if err != nil {
-		return fmt.Errorf("Failed rendering source instance %q: %w", instName, err)
+		return 0, fmt.Errorf("Failed rendering source instance %q: %w", instName, err)
 	}
 
 	srcInstInfo, ok := srcRenderRes.(*api.Instance)
 	if !ok {
-		return fmt.Errorf("Unexpected result from source instance render for %q", instName)
+		return 0, fmt.Errorf("Unexpected result from source instance render for %q", instName)
 	}
 
 	// Set up a push-mode migration sink on the destination. In push mode the
@@ -1567,14 +1991,10 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 		Name:        instName,
 		InstancePut: srcInstInfo.Writable(),
 		Type:        api.InstanceType(srcInstInfo.Type),
-		Source: api.InstanceSource{
-			Type:    api.SourceTypeMigration,
-			Mode:    "push",
-			Refresh: true,
-		},
+		Source:      points.instanceSource(),
 	})
 	if err != nil {
-		return fmt.Errorf("Failed requesting instance create on destination: %w", err)
+		return 0, fmt.Errorf("Failed requesting instance create on destination: %w", err)
 	}
 
 	// Guard against leaving the destination sink operation running if we fail
@@ -1589,7 +2009,7 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 	destOpAPI := destOp.Get()
 	destSecrets, err := destOpAPI.WebsocketSecrets()
 	if err != nil {
-		return fmt.Errorf("Failed getting websocket secrets from destination for instance %q: %w", instName, err)
+		return 0, fmt.Errorf("Failed getting websocket secrets from destination for instance %q: %w", instName, err)
 	}
 
 	pushTarget := &api.InstancePostTarget{
@@ -1600,7 +2020,7 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 
 	srcMigration, err := newMigrationSource(inst, false, false, false, "", pushTarget)
 	if err != nil {
-		return fmt.Errorf("Failed setting up migration source for instance %q: %w", instName, err)
+		return 0, fmt.Errorf("Failed setting up migration source for instance %q: %w", instName, err)
 	}
 
 	migrArgs := operations.OperationArgs{
@@ -1631,17 +2051,33 @@ func replicateInstance(ctx context.Context, s *state.State, op *operations.Opera
 	}
 
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	destOpCancelled = true // source is now connected via websockets; cancel would interrupt an in-flight transfer
 
 	err = srcOp.Wait(context.Background())
 	if err != nil {
-		return fmt.Errorf("Replication of instance %q failed on source: %w", instName, err)
+		return 0, fmt.Errorf("Replication of instance %q failed on source: %w", instName, err)
+	}
+
+	err = destOp.Wait()
+	if err != nil {
+		return 0, err
+	}
+
+	transferred := srcMigration.transferredBytes.Load()
+
+	if points != nil {
+		err = points.pruneLocal(ctx, inst)
+		if err != nil {
+			return transferred, err
+		}
+
+		return transferred, points.pruneRemote(dstClient, instName)
 	}
 
-	return destOp.Wait()
+	return transferred, nil
 }
 
 // runScheduledReplicators loads all replicators, checks their schedule config key against the current
@@ -1754,12 +2190,11 @@ func replicatorIsScheduledNow(spec string, now time.Time) bool {
 // It blocks until the operation completes so that last_run_date is persisted before the next scheduler
 // tick and operation results are visible to callers.
 func triggerScheduledReplicator(ctx context.Context, s *state.State, replicator *api.Replicator, row *dbCluster.Replicator) error {
-	clusterLinkName := replicator.Config["cluster"]
-	if clusterLinkName == "" {
+	if replicator.Config["cluster"] == "" {
 		return fmt.Errorf("Replicator %q has no cluster link configured", replicator.Name)
 	}
 
-	opArgs, err := prepareReplicatorRunOperation(ctx, s, replicator.Project, replicator.Name, clusterLinkName, false, row.Row.ID)
+	opArgs, err := prepareReplicatorRunOperation(ctx, s, replicator.Project, replicator.Name, replicator.Config, false, row.Row.ID)
 	if err != nil {
 		return err
 	}
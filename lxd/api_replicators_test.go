@@ -1,11 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/shared/api"
 )
 
 func TestReplicatorIsScheduledNow(t *testing.T) {
@@ -66,3 +71,110 @@ func TestReplicatorIsScheduledNow(t *testing.T) {
 		})
 	}
 }
+
+func TestReplicatorPointsExpired(t *testing.T) {
+	points := &replicatorPoints{prefix: "replicator-backup-", retention: 2}
+
+	tests := []struct {
+		name          string
+		snapshotNames []string
+		want          []string
+	}{
+		{name: "no snapshots", snapshotNames: nil, want: nil},
+		{name: "within retention", snapshotNames: []string{"replicator-backup-0", "replicator-backup-1"}, want: nil},
+		{name: "oldest points expire first", snapshotNames: []string{"replicator-backup-3", "replicator-backup-1", "replicator-backup-10", "replicator-backup-2"}, want: []string{"replicator-backup-1", "replicator-backup-2"}},
+		{name: "other snapshots are ignored", snapshotNames: []string{"snap0", "replicator-other-0", "replicator-backup-0", "replicator-backup-1", "replicator-backup-2"}, want: []string{"replicator-backup-0"}},
+		{name: "replicator with a longer name is ignored", snapshotNames: []string{"replicator-backup-dr-0", "replicator-backup-dr-1", "replicator-backup-dr-2"}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, points.expired(tt.snapshotNames))
+		})
+	}
+}
+
+func TestReplicatorPointsFromConfig(t *testing.T) {
+	points, err := replicatorPointsFromConfig("backup", map[string]string{"cluster": "lxd02"})
+	assert.NoError(t, err)
+	assert.Nil(t, points)
+
+	points, err = replicatorPointsFromConfig("backup", map[string]string{"mode": replicatorModeFull, "retention": "3"})
+	assert.NoError(t, err)
+	assert.Nil(t, points)
+
+	points, err = replicatorPointsFromConfig("backup", map[string]string{"mode": replicatorModeIncremental})
+	assert.NoError(t, err)
+	assert.Equal(t, &replicatorPoints{prefix: "replicator-backup-", retention: 1}, points)
+
+	points, err = replicatorPointsFromConfig("backup", map[string]string{"mode": replicatorModeIncremental, "retention": "5"})
+	assert.NoError(t, err)
+	assert.Equal(t, &replicatorPoints{prefix: "replicator-backup-", retention: 5}, points)
+
+	_, err = replicatorPointsFromConfig("backup", map[string]string{"mode": replicatorModeIncremental, "retention": "0"})
+	assert.Error(t, err)
+}
+
+func TestReplicatorValidateConfigRetention(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr string
+	}{
+		{name: "retention in default mode", config: map[string]string{"retention": "3"}, wantErr: `Replicator configuration key "retention" requires "mode" to be "incremental"`},
+		{name: "retention in full mode", config: map[string]string{"mode": replicatorModeFull, "retention": "3"}, wantErr: `Replicator configuration key "retention" requires "mode" to be "incremental"`},
+		{name: "retention in incremental mode", config: map[string]string{"mode": replicatorModeIncremental, "retention": "3"}, wantErr: `Replicator configuration key "cluster" is required`},
+		{name: "invalid retention", config: map[string]string{"mode": replicatorModeIncremental, "retention": "0"}, wantErr: `Invalid value for replicator configuration key "retention"`},
+		{name: "invalid mode", config: map[string]string{"mode": "differential"}, wantErr: `Invalid value for replicator configuration key "mode"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := replicatorValidateConfig(context.Background(), nil, tt.config)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestReplicatorPointsInstanceSource(t *testing.T) {
+	// Full mode refreshes the instance and all its snapshots.
+	var full *replicatorPoints
+	source := full.instanceSource()
+	assert.Equal(t, api.SourceTypeMigration, source.Type)
+	assert.Equal(t, "push", source.Mode)
+	assert.True(t, source.Refresh)
+	assert.False(t, source.RefreshExcludeOlder)
+
+	// Incremental mode only refreshes from the last common replication point.
+	incremental := &replicatorPoints{prefix: "replicator-backup-", retention: 1}
+	source = incremental.instanceSource()
+	assert.True(t, source.Refresh)
+	assert.True(t, source.RefreshExcludeOlder)
+}
+
+func TestReplicatorRunInstanceHook(t *testing.T) {
+	run := newReplicatorRun(replicatorRunActionStart, []string{"c1", "c2", "c3", "c4"})
+
+	succeed := func(ctx context.Context, op *operations.Operation) (int64, error) { return 1024, nil }
+	fail := func(ctx context.Context, op *operations.Operation) (int64, error) {
+		return 0, errors.New("Connection refused")
+	}
+	skip := func(ctx context.Context, op *operations.Operation) (int64, error) {
+		return 0, fmt.Errorf("Wrapped: %w", replicatorSkipError{reason: "Instance was deleted"})
+	}
+
+	assert.NoError(t, run.instanceHook("c1", succeed)(context.Background(), nil))
+	assert.Error(t, run.instanceHook("c2", fail)(context.Background(), nil))
+	assert.NoError(t, run.instanceHook("c3", skip)(context.Background(), nil))
+
+	result := run.finish(api.ReplicatorStatusFailed)
+	assert.Equal(t, replicatorRunActionStart, result.Action)
+	assert.Equal(t, api.ReplicatorStatusFailed, result.Status)
+	assert.Equal(t, int64(1024), result.BytesTransferred)
+	assert.Equal(t, []api.ReplicatorRunInstance{
+		{Name: "c1", Status: api.ReplicatorRunInstanceStatusSucceeded, BytesTransferred: 1024},
+		{Name: "c2", Status: api.ReplicatorRunInstanceStatusFailed, Reason: "Connection refused"},
+		{Name: "c3", Status: api.ReplicatorRunInstanceStatusSkipped, Reason: "Instance was deleted"},
+		{Name: "c4", Status: api.ReplicatorRunInstanceStatusFailed, Reason: "Instance was not replicated"},
+	}, result.Instances)
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/operations"
 	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
 	"github.com/canonical/lxd/lxd/task"
@@ -77,31 +78,10 @@ func backupCreate(ctx context.Context, s *state.State, args db.InstanceBackup, s
 	}
 
 	// Detect compression method.
-	var compress string
 	b.SetCompressionAlgorithm(args.CompressionAlgorithm)
-	if b.CompressionAlgorithm() != "" {
-		compress = b.CompressionAlgorithm()
-	} else {
-		var p *api.Project
-		err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-			project, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
-			if err != nil {
-				return err
-			}
-
-			p, err = project.ToAPI(ctx, tx.Tx())
-
-			return err
-		})
-		if err != nil {
-			return err
-		}
-
-		if p.Config["backups.compression_algorithm"] != "" {
-			compress = p.Config["backups.compression_algorithm"]
-		} else {
-			compress = s.GlobalConfig.BackupsCompressionAlgorithm()
-		}
+	compress, err := backupCompressionAlgorithm(s, projectName, b.CompressionAlgorithm())
+	if err != nil {
+		return err
 	}
 
 	// Create the target path if needed.
@@ -132,6 +112,108 @@ func backupCreate(ctx context.Context, s *state.State, args db.InstanceBackup, s
 	defer func() { _ = tarFileWriter.Close() }()
 	revert.Add(func() { _ = os.Remove(target) })
 
+	err = backupWriteTarball(s, sourceInst, pool, b.OptimizedStorage(), b.InstanceOnly(), compress, version, tarFileWriter, op, l)
+	if err != nil {
+		return err
+	}
+
+	err = tarFileWriter.Close()
+	if err != nil {
+		return fmt.Errorf("Error closing tar file: %w", err)
+	}
+
+	revert.Success()
+
+	var eventCtx map[string]any
+	if args.Scheduled {
+		eventCtx = map[string]any{"scheduled": true}
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.InstanceBackupCreated.Event(ctx, args.Name, b.Instance(), eventCtx))
+
+	return nil
+}
+
+// backupCreateOnTarget creates a backup of the instance, streaming it to the backup target.
+func backupCreateOnTarget(ctx context.Context, s *state.State, target *backup.Target, targetName string, backupName string, sourceInst instance.Instance, args db.InstanceBackup, version uint32, op *operations.Operation) error {
+	projectName := sourceInst.Project().Name
+	l := logger.AddContext(logger.Ctx{"project": projectName, "instance": sourceInst.Name(), "backupTarget": targetName, "name": backupName})
+	l.Debug("Instance backup to backup target started")
+	defer l.Debug("Instance backup to backup target finished")
+
+	// Get storage pool.
+	pool, err := storagePools.LoadByInstance(s, sourceInst)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	// Ignore requests for optimized backups when pool driver doesn't support it.
+	optimized := args.OptimizedStorage && pool.Driver().Info().OptimizedBackups
+
+	compress, err := backupCompressionAlgorithm(s, projectName, args.CompressionAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	// Stream the tarball to the backup target as it is written.
+	uploadReader, uploadWriter := io.Pipe()
+	uploadRes := make(chan error)
+
+	go func() {
+		_, err := target.Upload(ctx, backupName, uploadReader)
+		_ = uploadReader.CloseWithError(err)
+		uploadRes <- err
+	}()
+
+	err = backupWriteTarball(s, sourceInst, pool, optimized, args.InstanceOnly, compress, version, uploadWriter, op, l)
+	_ = uploadWriter.CloseWithError(err)
+
+	uploadErr := <-uploadRes
+	if err != nil {
+		return err
+	}
+
+	if uploadErr != nil {
+		return uploadErr
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.BackupTargetBackupCreated.Event(targetName, backupName, request.CreateRequestor(ctx), map[string]any{"instance": sourceInst.Name()}))
+
+	return nil
+}
+
+// backupCompressionAlgorithm returns the compression algorithm of backups of the project, unless one is requested.
+func backupCompressionAlgorithm(s *state.State, projectName string, requested string) (string, error) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	var p *api.Project
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		project, err := dbCluster.GetProject(ctx, tx.Tx(), projectName)
+		if err != nil {
+			return err
+		}
+
+		p, err = project.ToAPI(ctx, tx.Tx())
+
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if p.Config["backups.compression_algorithm"] != "" {
+		return p.Config["backups.compression_algorithm"], nil
+	}
+
+	return s.GlobalConfig.BackupsCompressionAlgorithm(), nil
+}
+
+// backupWriteTarball writes the backup tarball of the instance to the writer, with optional compression.
+func backupWriteTarball(s *state.State, sourceInst instance.Instance, pool storagePools.Pool, optimized bool, instanceOnly bool, compress string, version uint32, tarFileWriter io.WriteCloser, op *operations.Operation, l logger.Logger) error {
+	var err error
+
 	// Get IDMap to unshift container as the tarball is created.
 	var idmap *idmap.IdmapSet
 	if sourceInst.Type() == instancetype.Container {
@@ -175,7 +257,7 @@ func backupCreate(ctx context.Context, s *state.State, args db.InstanceBackup, s
 
 	// Write index file.
 	l.Debug("Adding backup index file")
-	err = backupWriteIndex(sourceInst, pool, b.OptimizedStorage(), !b.InstanceOnly(), version, tarWriter)
+	err = backupWriteIndex(sourceInst, pool, optimized, !instanceOnly, version, tarWriter)
 
 	// Check compression errors.
 	if compressErr != nil {
@@ -187,7 +269,7 @@ func backupCreate(ctx context.Context, s *state.State, args db.InstanceBackup, s
 		return fmt.Errorf("Error writing backup index file: %w", err)
 	}
 
-	err = pool.BackupInstance(sourceInst, tarWriter, b.OptimizedStorage(), !b.InstanceOnly(), version, nil)
+	err = pool.BackupInstance(sourceInst, tarWriter, optimized, !instanceOnly, version, nil)
 	if err != nil {
 		return fmt.Errorf("Backup create: %w", err)
 	}
@@ -209,14 +291,6 @@ func backupCreate(ctx context.Context, s *state.State, args db.InstanceBackup, s
 		return fmt.Errorf("Error writing tarball: %w", err)
 	}
 
-	err = tarFileWriter.Close()
-	if err != nil {
-		return fmt.Errorf("Error closing tar file: %w", err)
-	}
-
-	revert.Success()
-	s.Events.SendLifecycle(projectName, lifecycle.InstanceBackupCreated.Event(ctx, args.Name, b.Instance(), nil))
-
 	return nil
 }
 
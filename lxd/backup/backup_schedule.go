@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flosch/pongo2"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// Retention is the retention policy applied to scheduled backups.
+type Retention struct {
+	// Last is the number of most recent backups to keep.
+	Last int
+
+	// Daily is the number of days for which the most recent backup of the day is kept.
+	Daily int
+
+	// Weekly is the number of weeks for which the most recent backup of the week is kept.
+	Weekly int
+}
+
+// RetentionFromConfig returns the retention policy defined by the `backups.retention.*` keys of the config.
+func RetentionFromConfig(config map[string]string) (Retention, error) {
+	var retention Retention
+
+	keys := map[string]*int{
+		"backups.retention.last":   &retention.Last,
+		"backups.retention.daily":  &retention.Daily,
+		"backups.retention.weekly": &retention.Weekly,
+	}
+
+	for key, value := range keys {
+		if config[key] == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(config[key], 10, 32)
+		if err != nil {
+			return Retention{}, fmt.Errorf("Invalid value for %q: %w", key, err)
+		}
+
+		*value = int(n)
+	}
+
+	return retention, nil
+}
+
+// IsEmpty returns true if the retention policy doesn't limit the number of backups.
+func (r Retention) IsEmpty() bool {
+	return r.Last == 0 && r.Daily == 0 && r.Weekly == 0
+}
+
+// Prune returns the indexes of the creation dates of the backups which aren't kept by the retention policy.
+// A backup is kept if it matches any of the rules of the policy. The returned indexes are sorted.
+func (r Retention) Prune(creationDates []time.Time) []int {
+	if r.IsEmpty() {
+		return nil
+	}
+
+	// Sort from newest to oldest.
+	order := make([]int, len(creationDates))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a int, b int) int {
+		return creationDates[b].Compare(creationDates[a])
+	})
+
+	keep := make(map[int]bool, len(creationDates))
+
+	for i := 0; i < r.Last && i < len(order); i++ {
+		keep[order[i]] = true
+	}
+
+	// keepPeriods keeps the newest backup of each of the most recent periods.
+	keepPeriods := func(count int, period func(t time.Time) string) {
+		seen := make(map[string]bool, count)
+		for _, i := range order {
+			if len(seen) >= count {
+				return
+			}
+
+			p := period(creationDates[i].Local())
+			if seen[p] {
+				continue
+			}
+
+			seen[p] = true
+			keep[i] = true
+		}
+	}
+
+	keepPeriods(r.Daily, func(t time.Time) string {
+		return t.Format(time.DateOnly)
+	})
+
+	keepPeriods(r.Weekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+
+	var prune []int
+	for i := range creationDates {
+		if !keep[i] {
+			prune = append(prune, i)
+		}
+	}
+
+	return prune
+}
+
+// NextScheduledBackupName returns the name of the next scheduled backup from the pattern and the names
+// of the existing backups.
+// The pattern is a Pongo2 template which can contain `%d` once, replaced by the next free index.
+func NextScheduledBackupName(pattern string, existingNames []string) (string, error) {
+	name, err := shared.RenderTemplate(pattern, pongo2.Context{
+		"creation_date": time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	count := strings.Count(name, "%d")
+	if count > 1 {
+		return "", errors.New("Backup pattern may contain '%d' only once")
+	}
+
+	if count == 0 {
+		if !slices.Contains(existingNames, name) {
+			return name, nil
+		}
+
+		// Append '-0', '-1', etc. if the backup name already exists.
+		name += "-%d"
+	}
+
+	prefix, suffix, _ := strings.Cut(name, "%d")
+
+	next := 0
+	for _, existingName := range existingNames {
+		if !strings.HasPrefix(existingName, prefix) || !strings.HasSuffix(existingName, suffix) || len(existingName) < len(prefix)+len(suffix) {
+			continue
+		}
+
+		num, err := strconv.Atoi(existingName[len(prefix) : len(existingName)-len(suffix)])
+		if err != nil || num < 0 {
+			continue
+		}
+
+		if num >= next {
+			next = num + 1
+		}
+	}
+
+	return strings.Replace(name, "%d", strconv.Itoa(next), 1), nil
+}
@@ -0,0 +1,165 @@
+package backup
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRetentionPrune(t *testing.T) {
+	day := func(d int, hour int) time.Time {
+		return time.Date(2024, time.January, d, hour, 0, 0, 0, time.Local)
+	}
+
+	// Monday the 1st to Sunday the 21st of January 2024, two backups a day.
+	var dates []time.Time
+	for d := 1; d <= 21; d++ {
+		dates = append(dates, day(d, 6), day(d, 18))
+	}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		kept      []time.Time
+	}{
+		{
+			name:      "Empty policy keeps everything",
+			retention: Retention{},
+			kept:      dates,
+		},
+		{
+			name:      "Keep last",
+			retention: Retention{Last: 3},
+			kept:      []time.Time{day(20, 18), day(21, 6), day(21, 18)},
+		},
+		{
+			name:      "Keep daily",
+			retention: Retention{Daily: 2},
+			kept:      []time.Time{day(20, 18), day(21, 18)},
+		},
+		{
+			name:      "Keep weekly",
+			retention: Retention{Weekly: 2},
+			kept:      []time.Time{day(14, 18), day(21, 18)},
+		},
+		{
+			name:      "Rules are combined",
+			retention: Retention{Last: 2, Daily: 2, Weekly: 3},
+			kept:      []time.Time{day(7, 18), day(14, 18), day(20, 18), day(21, 6), day(21, 18)},
+		},
+		{
+			name:      "Policy larger than the number of backups",
+			retention: Retention{Last: 100},
+			kept:      dates,
+		},
+	}
+
+	for _, test := range tests {
+		// Reverse the input to check the order of the dates doesn't matter.
+		input := slices.Clone(dates)
+		slices.Reverse(input)
+
+		prune := test.retention.Prune(input)
+
+		var kept []time.Time
+		for i, date := range input {
+			if !slices.Contains(prune, i) {
+				kept = append(kept, date)
+			}
+		}
+
+		slices.SortFunc(kept, time.Time.Compare)
+
+		if !slices.EqualFunc(kept, test.kept, time.Time.Equal) {
+			t.Errorf("%s: Kept backups do not match: %v != %v", test.name, kept, test.kept)
+		}
+	}
+}
+
+func TestRetentionFromConfig(t *testing.T) {
+	retention, err := RetentionFromConfig(map[string]string{
+		"backups.retention.last":   "5",
+		"backups.retention.weekly": "4",
+	})
+	if err != nil {
+		t.Fatalf("Failed parsing retention: %v", err)
+	}
+
+	if retention != (Retention{Last: 5, Weekly: 4}) {
+		t.Errorf("Retention does not match: %+v", retention)
+	}
+
+	_, err = RetentionFromConfig(map[string]string{"backups.retention.daily": "-1"})
+	if err == nil {
+		t.Error("Expected an error for a negative retention")
+	}
+}
+
+func TestNextScheduledBackupName(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		existing []string
+		expected string
+		err      bool
+	}{
+		{
+			name:     "First backup",
+			pattern:  "backup%d",
+			expected: "backup0",
+		},
+		{
+			name:     "Next index",
+			pattern:  "backup%d",
+			existing: []string{"backup0", "backup3", "backupfoo", "other7"},
+			expected: "backup4",
+		},
+		{
+			name:     "Index in the middle of the pattern",
+			pattern:  "daily-%d-backup",
+			existing: []string{"daily-1-backup"},
+			expected: "daily-2-backup",
+		},
+		{
+			name:     "Pattern without index",
+			pattern:  "nightly",
+			expected: "nightly",
+		},
+		{
+			name:     "Pattern without index that already exists",
+			pattern:  "nightly",
+			existing: []string{"nightly", "nightly-0"},
+			expected: "nightly-1",
+		},
+		{
+			name:     "Template",
+			pattern:  `{{ creation_date|date:"2006" }}-%d`,
+			expected: time.Now().Format("2006") + "-0",
+		},
+		{
+			name:    "Index used twice",
+			pattern: "backup%d-%d",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		name, err := NextScheduledBackupName(test.pattern, test.existing)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: Expected an error", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if name != test.expected {
+			t.Errorf("%s: Name does not match: %q != %q", test.name, name, test.expected)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/storage/s3"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// ValidateTargetConfig validates the configuration of a backup target.
+func ValidateTargetConfig(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=backup-target; group=conf; key=endpoint)
+		// URL of the S3-compatible object storage, for example `https://s3.example.com`.
+		// Requests use the path-style addressing of buckets.
+		// ---
+		//  type: string
+		//  shortdesc: S3 endpoint URL
+		//  scope: global
+		"endpoint": validate.Required(validate.IsRequestURL),
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=bucket)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Name of the bucket storing the backups
+		//  scope: global
+		"bucket": validate.Required(validate.IsURLSegmentSafe),
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=region)
+		//
+		// ---
+		//  type: string
+		//  defaultdesc: `us-east-1`
+		//  shortdesc: Region used to sign the requests
+		//  scope: global
+		"region": validate.IsAny,
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=access_key)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Access key of the bucket
+		//  scope: global
+		"access_key": validate.Required(validate.IsNotEmpty),
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=secret_key)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Secret key of the bucket
+		//  scope: global
+		"secret_key": validate.Required(validate.IsNotEmpty),
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=prefix)
+		// Backups are stored in the bucket under `<prefix>/<project>/<instance>/<backup>`.
+		// ---
+		//  type: string
+		//  shortdesc: Prefix of the backup objects in the bucket
+		//  scope: global
+		"prefix": func(value string) error {
+			if strings.HasPrefix(value, "/") || strings.HasSuffix(value, "/") {
+				return errors.New("Prefix cannot start or end with a slash")
+			}
+
+			return nil
+		},
+
+		// lxdmeta:generate(entities=backup-target; group=conf; key=certificate)
+		// When set, the certificate of the S3 endpoint is only trusted if it matches this certificate.
+		// This is needed for endpoints using a self-signed certificate, such as LXD storage buckets.
+		// ---
+		//  type: string
+		//  shortdesc: PEM encoded certificate of the S3 endpoint
+		//  scope: global
+		"certificate": func(value string) error {
+			if value == "" {
+				return nil
+			}
+
+			_, err := shared.ParseCert([]byte(value))
+
+			return err
+		},
+	}
+
+	for key, value := range config {
+		// lxdmeta:generate(entities=backup-target; group=conf; key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Free-form user key/value storage
+		//  scope: global
+		if strings.HasPrefix(key, "user.") {
+			continue
+		}
+
+		rule, ok := rules[key]
+		if !ok {
+			return fmt.Errorf("Invalid backup target configuration key %q", key)
+		}
+
+		err := rule(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for backup target configuration key %q: %w", key, err)
+		}
+	}
+
+	for key, rule := range rules {
+		_, ok := config[key]
+		if ok {
+			continue
+		}
+
+		err := rule("")
+		if err != nil {
+			return fmt.Errorf("Invalid value for backup target configuration key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateTargetName validates the name of a backup target.
+func ValidateTargetName(name string) error {
+	if name == "" {
+		return errors.New("Backup target name cannot be empty")
+	}
+
+	err := validate.IsURLSegmentSafe(name)
+	if err != nil {
+		return err
+	}
+
+	// Defend against path traversal attacks.
+	if !shared.IsFileName(name) {
+		return fmt.Errorf("Invalid name %q, may not contain slashes or consecutive dots", name)
+	}
+
+	return validate.IsEntityName(name)
+}
+
+// TargetBackupName returns the name of an instance backup stored on a backup target.
+func TargetBackupName(projectName string, instanceName string, backupName string) string {
+	return path.Join(projectName, instanceName, backupName)
+}
+
+// ValidateTargetBackupName validates the name of a backup stored on a backup target.
+func ValidateTargetBackupName(name string) error {
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 {
+		return fmt.Errorf("Invalid backup name %q, must be of the form <project>/<instance>/<backup>", name)
+	}
+
+	for _, part := range parts {
+		err := validate.IsURLSegmentSafe(part)
+		if err != nil || part == "" || part == "." || part == ".." {
+			return fmt.Errorf("Invalid backup name %q", name)
+		}
+	}
+
+	return nil
+}
+
+// Target is a backup target on S3-compatible object storage.
+type Target struct {
+	name   string
+	bucket string
+	prefix string
+	client *s3.Client
+}
+
+// NewTarget returns the backup target with the given name and configuration.
+func NewTarget(name string, config map[string]string, proxy func(req *http.Request) (*url.URL, error)) (*Target, error) {
+	httpClient, err := util.HTTPClient(config["certificate"], proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s3.NewClient(config["endpoint"], config["region"], config["access_key"], config["secret_key"], httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := config["prefix"]
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	return &Target{
+		name:   name,
+		bucket: config["bucket"],
+		prefix: prefix,
+		client: client,
+	}, nil
+}
+
+// Upload streams the content of the reader to the backup and returns its size.
+func (t *Target) Upload(ctx context.Context, name string, reader io.Reader) (int64, error) {
+	size, err := t.client.PutObject(ctx, t.bucket, t.prefix+name, reader)
+	if err != nil {
+		return -1, fmt.Errorf("Failed uploading backup %q to backup target %q: %w", name, t.name, err)
+	}
+
+	return size, nil
+}
+
+// Open returns a reader for the content of the backup.
+func (t *Target) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	reader, _, err := t.client.GetObject(ctx, t.bucket, t.prefix+name)
+	if err != nil {
+		return nil, t.mapError(name, err)
+	}
+
+	return reader, nil
+}
+
+// Stat returns the details of the backup.
+func (t *Target) Stat(ctx context.Context, name string) (*api.BackupTargetBackup, error) {
+	info, err := t.client.StatObject(ctx, t.bucket, t.prefix+name)
+	if err != nil {
+		return nil, t.mapError(name, err)
+	}
+
+	return &api.BackupTargetBackup{
+		Name:      name,
+		Size:      info.Size,
+		CreatedAt: info.LastModified,
+	}, nil
+}
+
+// List returns the backups stored on the backup target whose name starts with the prefix.
+func (t *Target) List(ctx context.Context, prefix string) ([]api.BackupTargetBackup, error) {
+	objects, err := t.client.ListObjects(ctx, t.bucket, t.prefix+prefix)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing backups of backup target %q: %w", t.name, err)
+	}
+
+	backups := make([]api.BackupTargetBackup, 0, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, t.prefix)
+		if ValidateTargetBackupName(name) != nil {
+			continue
+		}
+
+		backups = append(backups, api.BackupTargetBackup{
+			Name:      name,
+			Size:      object.Size,
+			CreatedAt: object.LastModified,
+		})
+	}
+
+	return backups, nil
+}
+
+// Delete deletes the backup.
+func (t *Target) Delete(ctx context.Context, name string) error {
+	err := t.client.DeleteObject(ctx, t.bucket, t.prefix+name)
+	if err != nil {
+		return t.mapError(name, err)
+	}
+
+	return nil
+}
+
+// mapError returns a not found error if the error is due to a missing backup.
+func (t *Target) mapError(name string, err error) error {
+	s3Err := &s3.Error{}
+	if errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound {
+		return api.StatusErrorf(http.StatusNotFound, "Backup %q not found on backup target %q", name, t.name)
+	}
+
+	return fmt.Errorf("Failed accessing backup %q on backup target %q: %w", name, t.name, err)
+}
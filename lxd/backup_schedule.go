@@ -0,0 +1,418 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/backup"
+	backupConfig "github.com/canonical/lxd/lxd/backup/config"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project/limits"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// defaultBackupPattern is the name pattern of scheduled backups when `backups.pattern` isn't set.
+const defaultBackupPattern = "backup%d"
+
+func autoCreateAndPruneScheduledBackupsTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
+	// `f` creates the scheduled backups and then applies the retention policies.
+	f := func(ctx context.Context) {
+		err := autoCreateAndPruneScheduledBackups(ctx, stateFunc())
+		if err != nil {
+			logger.Error("Failed running scheduled backup task", logger.Ctx{"err": err})
+		}
+	}
+
+	first := true
+	schedule := func() (time.Duration, error) {
+		interval := time.Minute
+
+		if first {
+			first = false
+			return interval, task.ErrSkip
+		}
+
+		return interval, nil
+	}
+
+	return f, schedule
+}
+
+func autoCreateAndPruneScheduledBackups(ctx context.Context, s *state.State) error {
+	var instances []instance.Instance
+	var volumes []db.StorageVolumeArgs
+	var remoteVolumes []db.StorageVolumeArgs
+	var onlineMemberIDs []int64
+	var memberCount int
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		// Cache the backup restriction of each project.
+		allowed := map[string]bool{}
+		allowBackupCreation := func(projectName string) bool {
+			isAllowed, ok := allowed[projectName]
+			if !ok {
+				isAllowed = limits.AllowBackupCreation(tx, projectName) == nil
+				allowed[projectName] = isAllowed
+			}
+
+			return isAllowed
+		}
+
+		// Get list of instances on the local member that are due to have backups created.
+		filter := dbCluster.InstanceFilter{Node: &s.ServerName}
+		err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			inst, err := instance.Load(s, dbInst, p)
+			if err != nil {
+				return fmt.Errorf("Failed loading instance %q (project %q) for backup task: %w", dbInst.Name, dbInst.Project, err)
+			}
+
+			// Check if instance has backup schedule enabled.
+			schedule := inst.ExpandedConfig()["backups.schedule"]
+			if schedule == "" || !snapshotIsScheduledNow(schedule, int64(inst.ID())) {
+				return nil
+			}
+
+			if !allowBackupCreation(p.Name) {
+				return nil
+			}
+
+			logger.Debug("Scheduling auto instance backup", logger.Ctx{"instance": inst.Name(), "project": inst.Project().Name})
+			instances = append(instances, inst)
+
+			return nil
+		}, filter)
+		if err != nil {
+			return fmt.Errorf("Failed getting instance backup schedule info: %w", err)
+		}
+
+		// Get list of custom volumes that are due to have backups created.
+		allVolumes, err := tx.GetStoragePoolVolumesWithType(ctx, dbCluster.StoragePoolVolumeTypeCustom, true)
+		if err != nil {
+			return fmt.Errorf("Failed getting volumes for auto custom volume backup task: %w", err)
+		}
+
+		for _, v := range allVolumes {
+			schedule := v.Config["backups.schedule"]
+			if schedule == "" || !snapshotIsScheduledNow(schedule, v.ID) {
+				continue
+			}
+
+			if !allowBackupCreation(v.ProjectName) {
+				continue
+			}
+
+			if v.NodeID < 0 {
+				// Keep a separate list of remote volumes in order to select a member to
+				// perform the backup later.
+				remoteVolumes = append(remoteVolumes, v)
+			} else {
+				logger.Debug("Scheduling local auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+				volumes = append(volumes, v)
+			}
+		}
+
+		if len(remoteVolumes) > 0 {
+			members, err := tx.GetNodes(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed getting cluster members: %w", err)
+			}
+
+			memberCount = len(members)
+
+			for _, member := range members {
+				if member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+					continue
+				}
+
+				onlineMemberIDs = append(onlineMemberIDs, member.ID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(remoteVolumes) > 0 {
+		// Skip remote custom volumes if there are no online members, as we can't be sure that the
+		// cluster isn't partitioned and we may end up attempting the backup on multiple members.
+		if memberCount > 1 && len(onlineMemberIDs) <= 0 {
+			logger.Error("Skipping remote volumes for auto custom volume backup task due to no online members")
+		} else {
+			localMemberID := s.DB.Cluster.GetNodeID()
+
+			for _, v := range remoteVolumes {
+				// If there are multiple cluster members, a stable random member is chosen to
+				// perform the backup from.
+				if memberCount > 1 {
+					selectedMemberID, err := util.GetStableRandomInt64FromList(v.ID, onlineMemberIDs)
+					if err != nil {
+						logger.Error("Failed scheduling remote auto custom volume backup task", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName, "err": err})
+						continue
+					}
+
+					if localMemberID != selectedMemberID {
+						continue
+					}
+				}
+
+				logger.Debug("Scheduling remote auto custom volume backup", logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+				volumes = append(volumes, v)
+			}
+		}
+	}
+
+	if len(instances) == 0 && len(volumes) == 0 {
+		return nil
+	}
+
+	opRun := func(ctx context.Context, op *operations.Operation) error {
+		var errs []error
+
+		for _, inst := range instances {
+			err := ctx.Err()
+			if err != nil {
+				return err
+			}
+
+			err = autoCreateAndPruneInstanceBackups(ctx, s, inst, op)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		for _, v := range volumes {
+			err := ctx.Err()
+			if err != nil {
+				return err
+			}
+
+			err = autoCreateAndPruneCustomVolumeBackups(ctx, s, v)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		return errors.Join(errs...)
+	}
+
+	args := operations.OperationArgs{
+		Type:    operationtype.BackupsCreateScheduled,
+		Class:   operationtype.OperationClassTask,
+		RunHook: opRun,
+	}
+
+	logger.Info("Creating scheduled backups")
+	op, err := operations.ScheduleServerOperation(s, args)
+	if err != nil {
+		return fmt.Errorf("Failed creating scheduled backup operation: %w", err)
+	}
+
+	err = op.Wait(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed creating scheduled backups: %w", err)
+	}
+
+	logger.Info("Done creating scheduled backups")
+
+	return nil
+}
+
+// autoCreateAndPruneInstanceBackups creates a scheduled backup of the instance and then deletes the
+// scheduled backups which aren't kept by the retention policy of the instance.
+func autoCreateAndPruneInstanceBackups(ctx context.Context, s *state.State, inst instance.Instance, op *operations.Operation) error {
+	config := inst.ExpandedConfig()
+
+	retention, err := backup.RetentionFromConfig(config)
+	if err != nil {
+		return fmt.Errorf("Failed loading backup retention of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	backups, err := inst.Backups()
+	if err != nil {
+		return fmt.Errorf("Failed loading backups of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	existingNames := make([]string, 0, len(backups))
+	for _, b := range backups {
+		_, backupName, _ := api.GetParentAndSnapshotName(b.Name())
+		existingNames = append(existingNames, backupName)
+	}
+
+	pattern := config["backups.pattern"]
+	if pattern == "" {
+		pattern = defaultBackupPattern
+	}
+
+	backupName, err := backup.NextScheduledBackupName(pattern, existingNames)
+	if err != nil {
+		return fmt.Errorf("Failed generating backup name for instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	backupName, err = backup.ValidateBackupName(backupName)
+	if err != nil {
+		return fmt.Errorf("Invalid backup name for instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	now := time.Now()
+	expiry, err := shared.GetExpiry(now, config["backups.expiry"])
+	if err != nil {
+		return fmt.Errorf("Invalid backup expiry for instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	args := db.InstanceBackup{
+		Name:         inst.Name() + shared.SnapshotDelimiter + backupName,
+		InstanceID:   inst.ID(),
+		CreationDate: now,
+		ExpiryDate:   expiry,
+		Scheduled:    true,
+	}
+
+	err = backupCreate(ctx, s, args, inst, backupConfig.DefaultMetadataVersion, op)
+	if err != nil {
+		return fmt.Errorf("Failed creating scheduled backup of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	if retention.IsEmpty() {
+		return nil
+	}
+
+	var scheduledBackups []db.InstanceBackup
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		scheduledBackups, err = tx.GetInstanceScheduledBackups(ctx, inst.ID())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading scheduled backups of instance %q in project %q: %w", inst.Name(), inst.Project().Name, err)
+	}
+
+	creationDates := make([]time.Time, 0, len(scheduledBackups))
+	for _, b := range scheduledBackups {
+		creationDates = append(creationDates, b.CreationDate)
+	}
+
+	for _, i := range retention.Prune(creationDates) {
+		b := scheduledBackups[i]
+
+		instBackup := backup.NewInstanceBackup(s, inst, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.InstanceOnly, b.OptimizedStorage)
+		err = instBackup.Delete(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed deleting instance backup %q in project %q: %w", b.Name, inst.Project().Name, err)
+		}
+
+		logger.Debug("Deleted instance backup due to retention policy", logger.Ctx{"project": inst.Project().Name, "backup": b.Name})
+	}
+
+	return nil
+}
+
+// autoCreateAndPruneCustomVolumeBackups creates a scheduled backup of the custom volume and then deletes
+// the scheduled backups which aren't kept by the retention policy of the volume.
+func autoCreateAndPruneCustomVolumeBackups(ctx context.Context, s *state.State, v db.StorageVolumeArgs) error {
+	l := logger.AddContext(logger.Ctx{"volName": v.Name, "project": v.ProjectName, "pool": v.PoolName})
+
+	retention, err := backup.RetentionFromConfig(v.Config)
+	if err != nil {
+		return fmt.Errorf("Failed loading backup retention of volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	var fullNames []string
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		fullNames, err = tx.GetStoragePoolVolumeBackupsNames(ctx, v.ProjectName, v.Name, v.PoolID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading backups of volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	existingNames := make([]string, 0, len(fullNames))
+	for _, fullName := range fullNames {
+		backupName, found := strings.CutPrefix(fullName, v.Name+shared.SnapshotDelimiter)
+		if found {
+			existingNames = append(existingNames, backupName)
+		}
+	}
+
+	pattern := v.Config["backups.pattern"]
+	if pattern == "" {
+		pattern = defaultBackupPattern
+	}
+
+	backupName, err := backup.NextScheduledBackupName(pattern, existingNames)
+	if err != nil {
+		return fmt.Errorf("Failed generating backup name for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	backupName, err = backup.ValidateBackupName(backupName)
+	if err != nil {
+		return fmt.Errorf("Invalid backup name for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	now := time.Now()
+	expiry, err := shared.GetExpiry(now, v.Config["backups.expiry"])
+	if err != nil {
+		return fmt.Errorf("Invalid backup expiry for volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	args := db.StoragePoolVolumeBackup{
+		Name:         v.Name + shared.SnapshotDelimiter + backupName,
+		VolumeID:     v.ID,
+		CreationDate: now,
+		ExpiryDate:   expiry,
+		Scheduled:    true,
+	}
+
+	err = volumeBackupCreate(s, args, v.ProjectName, v.PoolName, v.Name, backupConfig.DefaultMetadataVersion)
+	if err != nil {
+		return fmt.Errorf("Failed creating scheduled backup of volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	s.Events.SendLifecycle(v.ProjectName, lifecycle.StorageVolumeBackupCreated.Event(v.PoolName, dbCluster.StoragePoolVolumeTypeNameCustom, args.Name, v.ProjectName, nil, logger.Ctx{"type": dbCluster.StoragePoolVolumeTypeNameCustom, "scheduled": true}))
+
+	if retention.IsEmpty() {
+		return nil
+	}
+
+	var scheduledBackups []db.StoragePoolVolumeBackup
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		scheduledBackups, err = tx.GetStoragePoolVolumeScheduledBackups(ctx, v.ID)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading scheduled backups of volume %q (project %q, pool %q): %w", v.Name, v.ProjectName, v.PoolName, err)
+	}
+
+	creationDates := make([]time.Time, 0, len(scheduledBackups))
+	for _, b := range scheduledBackups {
+		creationDates = append(creationDates, b.CreationDate)
+	}
+
+	for _, i := range retention.Prune(creationDates) {
+		b := scheduledBackups[i]
+
+		volBackup := backup.NewVolumeBackup(s, v.ProjectName, v.PoolName, v.Name, b.ID, b.Name, b.CreationDate, b.ExpiryDate, b.VolumeOnly, b.OptimizedStorage)
+		err = volBackup.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed deleting storage volume backup %q (project %q, pool %q): %w", b.Name, v.ProjectName, v.PoolName, err)
+		}
+
+		s.Events.SendLifecycle(v.ProjectName, lifecycle.StorageVolumeBackupDeleted.Event(v.PoolName, dbCluster.StoragePoolVolumeTypeNameCustom, b.Name, v.ProjectName, nil, nil))
+		l.Debug("Deleted storage volume backup due to retention policy", logger.Ctx{"backup": b.Name})
+	}
+
+	return nil
+}
@@ -0,0 +1,689 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Backup targets hold the credentials of the object storage, so all their endpoints require server edit access.
+var backupTargetsCmd = APIEndpoint{
+	Path:        "backup-targets",
+	MetricsType: entity.TypeServer,
+
+	Get:  APIEndpointAction{Handler: backupTargetsGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Post: APIEndpointAction{Handler: backupTargetsPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var backupTargetCmd = APIEndpoint{
+	Path:        "backup-targets/{name}",
+	MetricsType: entity.TypeServer,
+
+	Delete: APIEndpointAction{Handler: backupTargetDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: backupTargetGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Patch:  APIEndpointAction{Handler: backupTargetPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Put:    APIEndpointAction{Handler: backupTargetPut, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var backupTargetBackupsCmd = APIEndpoint{
+	Path:        "backup-targets/{name}/backups",
+	MetricsType: entity.TypeServer,
+
+	Get: APIEndpointAction{Handler: backupTargetBackupsGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+var backupTargetBackupCmd = APIEndpoint{
+	Path:        "backup-targets/{name}/backups/{backup...}",
+	MetricsType: entity.TypeServer,
+
+	Delete: APIEndpointAction{Handler: backupTargetBackupDelete, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+	Get:    APIEndpointAction{Handler: backupTargetBackupGet, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
+// loadBackupTarget returns the backup target with the given name.
+func loadBackupTarget(ctx context.Context, s *state.State, name string) (*backup.Target, error) {
+	var dbTarget *db.BackupTarget
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbTarget, err = tx.GetBackupTarget(ctx, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return backup.NewTarget(dbTarget.Name, dbTarget.Config, s.Proxy)
+}
+
+// swagger:operation GET /1.0/backup-targets backup-targets backup_targets_get
+//
+//	Get the backup targets
+//
+//	Returns a list of backup targets (URLs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of endpoints
+//	          items:
+//	            type: string
+//	          example: |-
+//	            [
+//	              "/1.0/backup-targets/s3",
+//	              "/1.0/backup-targets/offsite"
+//	            ]
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/backup-targets?recursion=1 backup-targets backup_targets_get_recursion1
+//
+//	Get the backup targets
+//
+//	Returns a list of backup targets (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of backup targets
+//	          items:
+//	            $ref: "#/definitions/BackupTarget"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	recursion, _ := util.IsRecursionRequest(r)
+
+	targetURLs := []string{}
+	targets := []*api.BackupTarget{}
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		names, err := tx.GetBackupTargetNames(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range names {
+			if recursion == 0 {
+				targetURLs = append(targetURLs, api.NewURL().Path(version.APIVersion, "backup-targets", name).String())
+				continue
+			}
+
+			target, err := tx.GetBackupTarget(ctx, name)
+			if err != nil {
+				return err
+			}
+
+			targets = append(targets, target.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if recursion == 0 {
+		return response.SyncResponse(true, targetURLs)
+	}
+
+	return response.SyncResponse(true, targets)
+}
+
+// swagger:operation POST /1.0/backup-targets backup-targets backup_targets_post
+//
+//	Add a backup target
+//
+//	Creates a new backup target.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: target
+//	    description: Backup target
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/BackupTargetsPost"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	req := api.BackupTargetsPost{}
+
+	// Parse the request.
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Quick checks.
+	err = backup.ValidateTargetName(req.Name)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	err = backup.ValidateTargetConfig(req.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		_, err := tx.GetBackupTarget(ctx, req.Name)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "Backup target %q already exists", req.Name)
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = tx.CreateBackupTarget(ctx, req.Name, req.Description, req.Config)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r.Context())
+	lc := lifecycle.BackupTargetCreated.Event(req.Name, requestor, nil)
+	s.Events.SendLifecycle("", lc)
+
+	return response.SyncResponseLocation(true, nil, lc.Source)
+}
+
+// swagger:operation GET /1.0/backup-targets/{name} backup-targets backup_target_get
+//
+//	Get the backup target
+//
+//	Gets a specific backup target.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Backup target
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/BackupTarget"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name := r.PathValue("name")
+
+	var target *api.BackupTarget
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		dbTarget, err := tx.GetBackupTarget(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		target = dbTarget.ToAPI()
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, target, target.Writable())
+}
+
+// swagger:operation PATCH /1.0/backup-targets/{name} backup-targets backup_target_patch
+//
+//	Partially update the backup target
+//
+//	Updates a subset of the backup target configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: target
+//	    description: Backup target configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/BackupTargetPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/backup-targets/{name} backup-targets backup_target_put
+//
+//	Update the backup target
+//
+//	Updates the entire backup target configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: body
+//	    name: target
+//	    description: Backup target configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/BackupTargetPut"
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name := r.PathValue("name")
+
+	var dbTarget *db.BackupTarget
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		dbTarget, err = tx.GetBackupTarget(ctx, name)
+
+		return err
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, dbTarget.ToAPI().Writable())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.BackupTargetPut{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Config == nil {
+		req.Config = map[string]string{}
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		for k, v := range dbTarget.Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+	}
+
+	err = backup.ValidateTargetConfig(req.Config)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.UpdateBackupTarget(ctx, dbTarget.ID, req.Description, req.Config)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Don't send the credentials in the lifecycle event.
+	changedKeys := []string{}
+	for key := range req.Config {
+		if req.Config[key] != dbTarget.Config[key] {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+
+	for key := range dbTarget.Config {
+		_, ok := req.Config[key]
+		if !ok {
+			changedKeys = append(changedKeys, key)
+		}
+	}
+
+	slices.Sort(changedKeys)
+
+	requestor := request.CreateRequestor(r.Context())
+	s.Events.SendLifecycle("", lifecycle.BackupTargetUpdated.Event(name, requestor, logger.Ctx{"changed_keys": changedKeys}))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation DELETE /1.0/backup-targets/{name} backup-targets backup_target_delete
+//
+//	Delete the backup target
+//
+//	Removes the backup target.
+//	The backups stored on the target are left in place.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	name := r.PathValue("name")
+
+	err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		target, err := tx.GetBackupTarget(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		return tx.DeleteBackupTarget(ctx, target.ID)
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r.Context())
+	s.Events.SendLifecycle("", lifecycle.BackupTargetDeleted.Event(name, requestor, nil))
+
+	return response.EmptySyncResponse
+}
+
+// swagger:operation GET /1.0/backup-targets/{name}/backups backup-targets backup_target_backups_get
+//
+//	Get the backups stored on the backup target
+//
+//	Returns the list of instance backups stored on the backup target.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: prefix
+//	    description: Only list the backups whose name starts with this prefix
+//	    type: string
+//	    example: default/c1/
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of backups
+//	          items:
+//	            $ref: "#/definitions/BackupTargetBackup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetBackupsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	target, err := loadBackupTarget(r.Context(), s, r.PathValue("name"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	backups, err := target.List(r.Context(), r.FormValue("prefix"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, backups)
+}
+
+// swagger:operation GET /1.0/backup-targets/{name}/backups/{backup} backup-targets backup_target_backup_get
+//
+//	Get the backup stored on the backup target
+//
+//	Gets a specific backup stored on the backup target.
+//	The name of the backup is made of its project, instance and backup names.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    description: Backup
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/BackupTargetBackup"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetBackupGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	backupName := strings.TrimSuffix(r.PathValue("backup"), "/")
+	err := backup.ValidateTargetBackupName(backupName)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	target, err := loadBackupTarget(r.Context(), s, r.PathValue("name"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info, err := target.Stat(r.Context(), backupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, info)
+}
+
+// swagger:operation DELETE /1.0/backup-targets/{name}/backups/{backup} backup-targets backup_target_backup_delete
+//
+//	Delete the backup stored on the backup target
+//
+//	Deletes the backup from the backup target.
+//
+//	---
+//	produces:
+//	  - application/json
+//	responses:
+//	  "200":
+//	    $ref: "#/responses/EmptySyncResponse"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func backupTargetBackupDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	targetName := r.PathValue("name")
+	backupName := strings.TrimSuffix(r.PathValue("backup"), "/")
+	err := backup.ValidateTargetBackupName(backupName)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	target, err := loadBackupTarget(r.Context(), s, targetName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Object storage doesn't report deleting missing objects, so check the backup exists first.
+	_, err = target.Stat(r.Context(), backupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	err = target.Delete(r.Context(), backupName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	requestor := request.CreateRequestor(r.Context())
+	s.Events.SendLifecycle("", lifecycle.BackupTargetBackupDeleted.Event(targetName, backupName, requestor, nil))
+
+	return response.EmptySyncResponse
+}
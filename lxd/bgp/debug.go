@@ -2,9 +2,10 @@ package bgp
 
 // DebugInfo represents the internal debug state of the BGP server.
 type DebugInfo struct {
-	Server   DebugInfoServer   `json:"server" yaml:"server"`
-	Prefixes []DebugInfoPrefix `json:"prefixes" yaml:"prefixes"`
-	Peers    []DebugInfoPeer   `json:"peers" yaml:"peers"`
+	Server     DebugInfoServer      `json:"server" yaml:"server"`
+	Prefixes   []DebugInfoPrefix    `json:"prefixes" yaml:"prefixes"`
+	EVPNRoutes []DebugInfoEVPNRoute `json:"evpn_routes" yaml:"evpn_routes"`
+	Peers      []DebugInfoPeer      `json:"peers" yaml:"peers"`
 }
 
 // DebugInfoServer exposes the shared listener configuration.
@@ -22,6 +23,14 @@ type DebugInfoPrefix struct {
 	Nexthop string `json:"nexthop" yaml:"nexthop"`
 }
 
+// DebugInfoEVPNRoute exposes details on a single EVPN route.
+type DebugInfoEVPNRoute struct {
+	Owner   string `json:"owner" yaml:"owner"`
+	VNI     uint32 `json:"vni" yaml:"vni"`
+	Nexthop string `json:"nexthop" yaml:"nexthop"`
+	MAC     string `json:"mac" yaml:"mac"`
+}
+
 // DebugInfoPeer exposes details on a single BGP peer.
 type DebugInfoPeer struct {
 	Address  string `json:"address" yaml:"address"`
@@ -29,6 +38,7 @@ type DebugInfoPeer struct {
 	Password string `json:"password" yaml:"password"`
 	Count    int    `json:"count" yaml:"count"`
 	HoldTime uint64 `json:"holdtime" yaml:"holdtime"`
+	EVPN     bool   `json:"evpn" yaml:"evpn"`
 }
 
 // Debug returns a dump of the current configuration.
@@ -58,6 +68,7 @@ func (s *Server) debug() DebugInfo {
 		entry.Password = peer.password
 		entry.Count = peer.count
 		entry.HoldTime = peer.holdtime
+		entry.EVPN = peer.evpn
 
 		debug.Peers = append(debug.Peers, entry)
 	}
@@ -73,5 +84,19 @@ func (s *Server) debug() DebugInfo {
 		debug.Prefixes = append(debug.Prefixes, entry)
 	}
 
+	// Fill in the EVPN routes.
+	debug.EVPNRoutes = []DebugInfoEVPNRoute{}
+	for _, path := range s.evpnPaths {
+		entry := DebugInfoEVPNRoute{}
+		entry.Owner = path.owner
+		entry.VNI = path.route.VNI
+		entry.Nexthop = path.route.NextHop.String()
+		if path.route.MAC != nil {
+			entry.MAC = path.route.MAC.String()
+		}
+
+		debug.EVPNRoutes = append(debug.EVPNRoutes, entry)
+	}
+
 	return debug
 }
@@ -0,0 +1,443 @@
+package bgp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+
+	"github.com/google/uuid"
+	bgpAPI "github.com/osrg/gobgp/v3/api"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// evpnFamily is the L2VPN EVPN address family.
+var evpnFamily = &bgpAPI.Family{Afi: bgpAPI.Family_AFI_L2VPN, Safi: bgpAPI.Family_SAFI_EVPN}
+
+// evpnTunnelTypeVXLAN is the VXLAN tunnel type of the BGP encapsulation extended community (RFC 9012).
+const evpnTunnelTypeVXLAN = 8
+
+// evpnPMSITunnelTypeIngressReplication is the ingress replication tunnel type of the PMSI tunnel attribute (RFC 6514).
+const evpnPMSITunnelTypeIngressReplication = 6
+
+// evpnASTrans is the ASN used in route distinguishers and route targets when the local ASN doesn't fit in two octets (RFC 6793).
+const evpnASTrans = 23456
+
+// EVPNRoute represents an EVPN route of a VXLAN network.
+type EVPNRoute struct {
+	// VXLAN network identifier.
+	VNI uint32
+
+	// Address of the VXLAN tunnel endpoint the route points to.
+	NextHop net.IP
+
+	// MAC address of a MAC/IP advertisement route (type 2).
+	// Inclusive multicast Ethernet tag routes (type 3) have no MAC address.
+	MAC net.HardwareAddr
+
+	// Optional IP address of a MAC/IP advertisement route.
+	IP net.IP
+}
+
+// EVPNHandler is called when a remote EVPN route is learned or withdrawn.
+type EVPNHandler func(route EVPNRoute, withdrawn bool)
+
+type evpnPath struct {
+	owner string
+	route EVPNRoute
+}
+
+// AddEVPNPeer adds a new BGP peer which exchanges EVPN routes in addition to IPv4 and IPv6 prefixes.
+func (s *Server) AddEVPNPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addPeer(address, asn, password, holdTime, true)
+}
+
+// AddEVPNRoute advertises a local EVPN route.
+func (s *Server) AddEVPNRoute(route EVPNRoute, owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addEVPNRoute(route, owner)
+}
+
+func (s *Server) addEVPNRoute(route EVPNRoute, owner string) error {
+	var pathUUID string
+	if s.bgp != nil {
+		path, err := s.evpnAPIPath(route)
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.bgp.AddPath(context.Background(), &bgpAPI.AddPathRequest{Path: path})
+		if err != nil {
+			return err
+		}
+
+		pathUUID = string(resp.Uuid)
+	} else {
+		// Generate a dummy UUID.
+		pathUUID = uuid.New().String()
+	}
+
+	// Add path to the map.
+	s.evpnPaths[pathUUID] = evpnPath{
+		owner: owner,
+		route: route,
+	}
+
+	return nil
+}
+
+// RemoveEVPNRoute withdraws a local EVPN route.
+func (s *Server) RemoveEVPNRoute(route EVPNRoute, owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := false
+	for pathUUID, path := range s.evpnPaths {
+		if path.owner != owner || !evpnRouteEqual(path.route, route) {
+			continue
+		}
+
+		found = true
+
+		err := s.removeEVPNRouteByUUID(pathUUID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return ErrPrefixNotFound
+	}
+
+	return nil
+}
+
+// RemoveEVPNRoutesByOwner withdraws all local EVPN routes for the provided owner.
+func (s *Server) RemoveEVPNRoutesByOwner(owner string) error {
+	// Locking.
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Make a copy of the paths dict to safely iterate (path removal mutates it).
+	paths := map[string]evpnPath{}
+	maps.Copy(paths, s.evpnPaths)
+
+	for pathUUID, path := range paths {
+		if path.owner == owner {
+			err := s.removeEVPNRouteByUUID(pathUUID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) removeEVPNRouteByUUID(pathUUID string) error {
+	// Remove it from the BGP server.
+	if s.bgp != nil {
+		err := s.bgp.DeletePath(context.Background(), &bgpAPI.DeletePathRequest{Uuid: []byte(pathUUID)})
+		if err != nil && err.Error() != "cannot find a specified path" {
+			return err
+		}
+	}
+
+	// Remove the path from the map.
+	delete(s.evpnPaths, pathUUID)
+
+	return nil
+}
+
+// AddEVPNHandler registers the handler called for the remote EVPN routes of the given VXLAN network identifier.
+// The handler is immediately called for the remote routes already known.
+func (s *Server) AddEVPNHandler(vni uint32, handler EVPNHandler) error {
+	s.evpnHandlersMu.Lock()
+	_, ok := s.evpnHandlers[vni]
+	if ok {
+		s.evpnHandlersMu.Unlock()
+		return fmt.Errorf("EVPN handler for VNI %d already registered", vni)
+	}
+
+	s.evpnHandlers[vni] = handler
+	s.evpnHandlersMu.Unlock()
+
+	s.mu.Lock()
+	routes, err := s.evpnRoutes(vni)
+	s.mu.Unlock()
+	if err != nil {
+		s.RemoveEVPNHandler(vni)
+		return err
+	}
+
+	for _, route := range routes {
+		handler(route, false)
+	}
+
+	return nil
+}
+
+// RemoveEVPNHandler unregisters the handler of the given VXLAN network identifier.
+func (s *Server) RemoveEVPNHandler(vni uint32) {
+	s.evpnHandlersMu.Lock()
+	defer s.evpnHandlersMu.Unlock()
+
+	delete(s.evpnHandlers, vni)
+}
+
+// evpnRoutes returns the best EVPN routes known for the given VXLAN network identifier.
+func (s *Server) evpnRoutes(vni uint32) ([]EVPNRoute, error) {
+	routes := []EVPNRoute{}
+	if s.bgp == nil {
+		return routes, nil
+	}
+
+	err := s.bgp.ListPath(context.Background(), &bgpAPI.ListPathRequest{TableType: bgpAPI.TableType_GLOBAL, Family: evpnFamily}, func(d *bgpAPI.Destination) {
+		for _, path := range d.Paths {
+			if !path.Best {
+				continue
+			}
+
+			route, err := evpnRouteFromAPIPath(path)
+			if err != nil || route.VNI != vni {
+				continue
+			}
+
+			routes = append(routes, *route)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return routes, nil
+}
+
+// watchEVPN notifies the EVPN handlers of changes to the best EVPN routes.
+func (s *Server) watchEVPN() error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	req := &bgpAPI.WatchEventRequest{
+		Table: &bgpAPI.WatchEventRequest_Table{
+			Filters: []*bgpAPI.WatchEventRequest_Table_Filter{
+				{Type: bgpAPI.WatchEventRequest_Table_Filter_BEST, Init: true},
+			},
+		},
+	}
+
+	err := s.bgp.WatchEvent(ctx, req, func(resp *bgpAPI.WatchEventResponse) {
+		table := resp.GetTable()
+		if table == nil {
+			return
+		}
+
+		for _, path := range table.Paths {
+			if path.Family.GetAfi() != evpnFamily.Afi || path.Family.GetSafi() != evpnFamily.Safi {
+				continue
+			}
+
+			route, err := evpnRouteFromAPIPath(path)
+			if err != nil {
+				logger.Debug("Ignoring EVPN route", logger.Ctx{"err": err})
+				continue
+			}
+
+			// Use a separate lock as the watcher may run while the server is being reconfigured.
+			s.evpnHandlersMu.Lock()
+			handler := s.evpnHandlers[route.VNI]
+			s.evpnHandlersMu.Unlock()
+
+			if handler != nil {
+				handler(*route, path.IsWithdraw)
+			}
+		}
+	})
+	if err != nil {
+		cancel()
+		return fmt.Errorf("Failed watching EVPN routes: %w", err)
+	}
+
+	s.evpnWatchCancel = cancel
+
+	return nil
+}
+
+// evpnAPIPath returns the BGP path advertising the given route.
+func (s *Server) evpnAPIPath(route EVPNRoute) (*bgpAPI.Path, error) {
+	// Use ASN:VNI as both the route distinguisher and the route target.
+	// The VNI doesn't fit in the assigned number of an IP address based route distinguisher.
+	asn := s.asn
+	if asn > 65535 {
+		asn = evpnASTrans
+	}
+
+	rd, err := anypb.New(&bgpAPI.RouteDistinguisherTwoOctetASN{
+		Admin:    asn,
+		Assigned: route.VNI,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nlri *anypb.Any
+	if route.MAC != nil {
+		ipAddress := ""
+		if route.IP != nil {
+			ipAddress = route.IP.String()
+		}
+
+		nlri, err = anypb.New(&bgpAPI.EVPNMACIPAdvertisementRoute{
+			Rd:         rd,
+			Esi:        &bgpAPI.EthernetSegmentIdentifier{},
+			MacAddress: route.MAC.String(),
+			IpAddress:  ipAddress,
+			Labels:     []uint32{route.VNI},
+		})
+	} else {
+		nlri, err = anypb.New(&bgpAPI.EVPNInclusiveMulticastEthernetTagRoute{
+			Rd:        rd,
+			IpAddress: route.NextHop.String(),
+		})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	aOrigin, _ := anypb.New(&bgpAPI.OriginAttribute{
+		Origin: 0,
+	})
+
+	aNextHop, _ := anypb.New(&bgpAPI.MpReachNLRIAttribute{
+		Family:   evpnFamily,
+		NextHops: []string{route.NextHop.String()},
+		Nlris:    []*anypb.Any{nlri},
+	})
+
+	routeTarget, _ := anypb.New(&bgpAPI.TwoOctetAsSpecificExtended{
+		IsTransitive: true,
+		SubType:      0x02,
+		Asn:          asn,
+		LocalAdmin:   route.VNI,
+	})
+
+	encap, _ := anypb.New(&bgpAPI.EncapExtended{
+		TunnelType: evpnTunnelTypeVXLAN,
+	})
+
+	aCommunities, _ := anypb.New(&bgpAPI.ExtendedCommunitiesAttribute{
+		Communities: []*anypb.Any{routeTarget, encap},
+	})
+
+	pattrs := []*anypb.Any{aOrigin, aNextHop, aCommunities}
+
+	// Inclusive multicast Ethernet tag routes announce the VTEP for BUM traffic using ingress replication.
+	if route.MAC == nil {
+		tunnelID := route.NextHop.To4()
+		if tunnelID == nil {
+			tunnelID = route.NextHop.To16()
+		}
+
+		aPMSI, _ := anypb.New(&bgpAPI.PmsiTunnelAttribute{
+			Type:  evpnPMSITunnelTypeIngressReplication,
+			Label: route.VNI,
+			Id:    tunnelID,
+		})
+
+		pattrs = append(pattrs, aPMSI)
+	}
+
+	return &bgpAPI.Path{
+		Family: evpnFamily,
+		Nlri:   nlri,
+		Pattrs: pattrs,
+	}, nil
+}
+
+// evpnRouteFromAPIPath converts a BGP EVPN path into an EVPNRoute.
+// Only MAC/IP advertisement and inclusive multicast Ethernet tag routes are supported.
+func evpnRouteFromAPIPath(path *bgpAPI.Path) (*EVPNRoute, error) {
+	route := &EVPNRoute{}
+
+	// Get the next hop and the PMSI tunnel label from the attributes.
+	var pmsiLabel uint32
+	for _, attr := range path.Pattrs {
+		msg, err := attr.UnmarshalNew()
+		if err != nil {
+			continue
+		}
+
+		switch a := msg.(type) {
+		case *bgpAPI.MpReachNLRIAttribute:
+			if len(a.NextHops) > 0 {
+				route.NextHop = net.ParseIP(a.NextHops[0])
+			}
+
+		case *bgpAPI.PmsiTunnelAttribute:
+			pmsiLabel = a.Label
+		}
+	}
+
+	if route.NextHop == nil {
+		return nil, errors.New("EVPN route without next hop")
+	}
+
+	msg, err := path.Nlri.UnmarshalNew()
+	if err != nil {
+		return nil, err
+	}
+
+	switch nlri := msg.(type) {
+	case *bgpAPI.EVPNMACIPAdvertisementRoute:
+		if len(nlri.Labels) == 0 {
+			return nil, errors.New("EVPN MAC/IP advertisement route without label")
+		}
+
+		route.VNI = nlri.Labels[0]
+
+		route.MAC, err = net.ParseMAC(nlri.MacAddress)
+		if err != nil {
+			return nil, err
+		}
+
+		if nlri.IpAddress != "" {
+			route.IP = net.ParseIP(nlri.IpAddress)
+		}
+
+	case *bgpAPI.EVPNInclusiveMulticastEthernetTagRoute:
+		// The VNI is carried in the PMSI tunnel label, unless the Ethernet tag is used (VLAN-aware bundle service).
+		route.VNI = pmsiLabel
+		if nlri.EthernetTag != 0 {
+			route.VNI = nlri.EthernetTag
+		}
+
+	default:
+		return nil, fmt.Errorf("Unsupported EVPN route type %q", path.Nlri.GetTypeUrl())
+	}
+
+	return route, nil
+}
+
+// evpnRouteEqual returns true if both routes are the same.
+func evpnRouteEqual(a EVPNRoute, b EVPNRoute) bool {
+	if a.VNI != b.VNI || !a.NextHop.Equal(b.NextHop) || a.MAC.String() != b.MAC.String() {
+		return false
+	}
+
+	if a.IP == nil || b.IP == nil {
+		return a.IP == nil && b.IP == nil
+	}
+
+	return a.IP.Equal(b.IP)
+}
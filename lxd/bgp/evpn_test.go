@@ -0,0 +1,130 @@
+package bgp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mustParseMAC parses the given MAC address string.
+func mustParseMAC(s string) net.HardwareAddr {
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		panic("invalid MAC: " + s)
+	}
+
+	return mac
+}
+
+// TestEVPNRouteRoundTrip verifies that EVPN routes are preserved when converted
+// to BGP paths and back.
+func TestEVPNRouteRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		route EVPNRoute
+	}{
+		{
+			name:  "Inclusive multicast Ethernet tag route",
+			route: EVPNRoute{VNI: 100, NextHop: mustParseIP("192.0.2.10")},
+		},
+		{
+			name:  "MAC/IP advertisement route",
+			route: EVPNRoute{VNI: 16777215, NextHop: mustParseIP("192.0.2.10"), MAC: mustParseMAC("00:16:3e:01:02:03")},
+		},
+		{
+			name:  "MAC/IP advertisement route with IP",
+			route: EVPNRoute{VNI: 100, NextHop: mustParseIP("2001:db8::10"), MAC: mustParseMAC("00:16:3e:01:02:03"), IP: mustParseIP("2001:db8::20")},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := NewServer()
+			s.asn = 4200000000
+
+			path, err := s.evpnAPIPath(tc.route)
+			require.NoError(t, err)
+
+			route, err := evpnRouteFromAPIPath(path)
+			require.NoError(t, err)
+			require.True(t, evpnRouteEqual(tc.route, *route), "got %+v", *route)
+		})
+	}
+}
+
+// TestAddRemoveEVPNRoute verifies that EVPN routes can be added and then removed.
+func TestAddRemoveEVPNRoute(t *testing.T) {
+	s := NewServer()
+	route := EVPNRoute{VNI: 100, NextHop: mustParseIP("192.0.2.10"), MAC: mustParseMAC("00:16:3e:01:02:03")}
+
+	err := s.AddEVPNRoute(route, "owner")
+	require.NoError(t, err)
+	require.Len(t, s.evpnPaths, 1)
+
+	// Different next hop — should not match.
+	err = s.RemoveEVPNRoute(EVPNRoute{VNI: 100, NextHop: mustParseIP("192.0.2.11"), MAC: route.MAC}, "owner")
+	require.ErrorIs(t, err, ErrPrefixNotFound)
+
+	// Different owner — should not match.
+	err = s.RemoveEVPNRoute(route, "other")
+	require.ErrorIs(t, err, ErrPrefixNotFound)
+
+	err = s.RemoveEVPNRoute(route, "owner")
+	require.NoError(t, err)
+	require.Empty(t, s.evpnPaths)
+}
+
+// TestRemoveEVPNRoutesByOwner verifies that only the EVPN routes belonging to
+// the given owner are removed.
+func TestRemoveEVPNRoutesByOwner(t *testing.T) {
+	s := NewServer()
+
+	err := s.AddEVPNRoute(EVPNRoute{VNI: 100, NextHop: mustParseIP("192.0.2.10")}, "owner-a")
+	require.NoError(t, err)
+
+	err = s.AddEVPNRoute(EVPNRoute{VNI: 100, NextHop: mustParseIP("192.0.2.10"), MAC: mustParseMAC("00:16:3e:01:02:03")}, "owner-b")
+	require.NoError(t, err)
+
+	err = s.AddEVPNRoute(EVPNRoute{VNI: 200, NextHop: mustParseIP("192.0.2.10")}, "owner-a")
+	require.NoError(t, err)
+
+	err = s.RemoveEVPNRoutesByOwner("owner-a")
+	require.NoError(t, err)
+
+	// Only owner-b's route should remain.
+	require.Len(t, s.evpnPaths, 1)
+	for _, p := range s.evpnPaths {
+		require.Equal(t, "owner-b", p.owner)
+	}
+}
+
+// TestAddEVPNHandlerConflict verifies that a single handler can be registered per VNI.
+func TestAddEVPNHandlerConflict(t *testing.T) {
+	s := NewServer()
+	handler := func(route EVPNRoute, withdrawn bool) {}
+
+	err := s.AddEVPNHandler(100, handler)
+	require.NoError(t, err)
+
+	err = s.AddEVPNHandler(100, handler)
+	require.Error(t, err)
+
+	s.RemoveEVPNHandler(100)
+
+	err = s.AddEVPNHandler(100, handler)
+	require.NoError(t, err)
+}
+
+// TestAddPeerConflictEVPN verifies that adding the same peer address with and
+// without EVPN returns an error.
+func TestAddPeerConflictEVPN(t *testing.T) {
+	s := NewServer()
+	addr := mustParseIP("192.168.1.1")
+
+	err := s.AddPeer(addr, 65000, "", 0)
+	require.NoError(t, err)
+
+	err = s.AddEVPNPeer(addr, 65000, "", 0)
+	require.Error(t, err)
+}
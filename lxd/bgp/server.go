@@ -32,6 +32,12 @@ type Server struct {
 	paths    map[string]path
 	peers    map[string]peer
 
+	// EVPN state.
+	evpnPaths       map[string]evpnPath
+	evpnHandlers    map[uint32]EVPNHandler
+	evpnHandlersMu  sync.Mutex
+	evpnWatchCancel context.CancelFunc
+
 	mu sync.Mutex
 }
 
@@ -46,6 +52,7 @@ type peer struct {
 	asn      uint32
 	password string
 	holdtime uint64
+	evpn     bool
 	count    int
 }
 
@@ -53,8 +60,10 @@ type peer struct {
 func NewServer() *Server {
 	// Setup new struct.
 	s := &Server{
-		paths: map[string]path{},
-		peers: map[string]peer{},
+		paths:        map[string]path{},
+		peers:        map[string]peer{},
+		evpnPaths:    map[string]evpnPath{},
+		evpnHandlers: map[uint32]EVPNHandler{},
 	}
 
 	return s
@@ -97,8 +106,8 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 		RouterId: routerID.String(),
 		Asn:      asn,
 
-		// Always setup for IPv4 and IPv6.
-		Families: []uint32{0, 1},
+		// Always setup for IPv4, IPv6 and EVPN.
+		Families: []uint32{0, 1, 9},
 
 		// Listen address.
 		ListenAddresses: []string{addrHost},
@@ -131,7 +140,7 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 	// Add existing peers.
 	s.peers = map[string]peer{}
 	for _, peer := range oldPeers {
-		err := s.addPeer(peer.address, peer.asn, peer.password, peer.holdtime)
+		err := s.addPeer(peer.address, peer.asn, peer.password, peer.holdtime, peer.evpn)
 		if err != nil {
 			return err
 		}
@@ -142,6 +151,25 @@ func (s *Server) start(address string, asn uint32, routerID net.IP) error {
 	s.asn = asn
 	s.routerID = routerID
 
+	// Copy the EVPN path list.
+	oldEVPNPaths := map[string]evpnPath{}
+	maps.Copy(oldEVPNPaths, s.evpnPaths)
+
+	// Add existing EVPN paths.
+	s.evpnPaths = map[string]evpnPath{}
+	for _, path := range oldEVPNPaths {
+		err := s.addEVPNRoute(path.route, path.owner)
+		if err != nil {
+			logger.Warn("Cannot add EVPN route to BGP server", logger.Ctx{"vni": path.route.VNI, "err": err})
+		}
+	}
+
+	// Notify the EVPN handlers of remote routes.
+	err = s.watchEVPN()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -167,6 +195,12 @@ func (s *Server) stop() error {
 	// Restore peer list.
 	s.peers = oldPeers
 
+	// Stop notifying the EVPN handlers.
+	if s.evpnWatchCancel != nil {
+		s.evpnWatchCancel()
+		s.evpnWatchCancel = nil
+	}
+
 	// Stop the listener.
 	err := s.bgp.StopBgp(context.Background(), &bgpAPI.StopBgpRequest{})
 	if err != nil {
@@ -385,10 +419,10 @@ func (s *Server) AddPeer(address net.IP, asn uint32, password string, holdTime u
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.addPeer(address, asn, password, holdTime)
+	return s.addPeer(address, asn, password, holdTime, false)
 }
 
-func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime uint64) error {
+func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime uint64, evpn bool) error {
 	addrStr := address.String()
 
 	// Look for an existing peer.
@@ -402,6 +436,10 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 			return fmt.Errorf("Peer %q already used but with a different password", addrStr)
 		}
 
+		if bgpPeer.evpn != evpn {
+			return fmt.Errorf("Peer %q already used but with differing address families", addrStr)
+		}
+
 		// Re-use the existing entry.
 		bgpPeer.count++
 		s.peers[addrStr] = bgpPeer
@@ -439,9 +477,14 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		}
 	}
 
-	// Setup peer for dual-stack.
+	// Setup peer for dual-stack (and EVPN if requested).
+	families := []string{"ipv4-unicast", "ipv6-unicast"}
+	if evpn {
+		families = append(families, "l2vpn-evpn")
+	}
+
 	n.AfiSafis = make([]*bgpAPI.AfiSafi, 0)
-	for _, f := range []string{"ipv4-unicast", "ipv6-unicast"} {
+	for _, f := range families {
 		rf, err := bgpPacket.GetRouteFamily(f)
 		if err != nil {
 			return err
@@ -477,6 +520,7 @@ func (s *Server) addPeer(address net.IP, asn uint32, password string, holdTime u
 		asn:      asn,
 		password: password,
 		holdtime: holdTime,
+		evpn:     evpn,
 		count:    1,
 	}
 
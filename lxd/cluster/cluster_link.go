@@ -24,6 +24,9 @@ import (
 	"github.com/canonical/lxd/shared/version"
 )
 
+// ErrClusterLinkUnreachable is returned by [RefreshClusterLinkVolatileAddresses] when the linked cluster cannot be reached.
+var ErrClusterLinkUnreachable = errors.New("Cluster link is unreachable")
+
 // CheckClusterLinkCertificate checks the cluster certificate at each address and ensures every reachable address matches the provided fingerprint.
 // If a valid, consistent cluster certificate is found, it is returned with the first address at which it was found. Unreachable addresses are tolerated
 // so long as at least one address is reachable and no reachable address presents a different certificate.
@@ -183,6 +186,7 @@ func ConnectCluster(ctx context.Context, clusterLink api.ClusterLink, args *lxd.
 // RefreshClusterLinkVolatileAddresses refreshes the volatile addresses of a cluster link.
 // It connects to the linked cluster and retrieves its current cluster members. If the addresses
 // have changed, [CheckClusterLinkCertificate] is called to ensure the cluster certificate remains valid.
+// If the linked cluster cannot be reached, the returned error wraps [ErrClusterLinkUnreachable].
 func RefreshClusterLinkVolatileAddresses(ctx context.Context, s *state.State, name string) error {
 	// Fetch the cluster link and identity cert in a single transaction so we have everything needed
 	// for connecting and cert validation without any further DB queries.
@@ -209,7 +213,7 @@ func RefreshClusterLinkVolatileAddresses(ctx context.Context, s *state.State, na
 
 	targetClient, err := ConnectCluster(ctx, *clusterLink, GetClusterLinkConnectionArgs(clusterCert, targetCert))
 	if err != nil {
-		return fmt.Errorf("Failed connecting to target cluster link: %w", err)
+		return fmt.Errorf("Failed connecting to target cluster link: %w: %w", ErrClusterLinkUnreachable, err)
 	}
 
 	// Get cluster members from the target cluster.
@@ -195,6 +195,11 @@ func (c *Config) InstancesMigrationStateful() bool {
 	return c.m.GetBool("instances.migration.stateful")
 }
 
+// InstancesPlacementWebhook returns the URL and timeout of the instance placement webhook.
+func (c *Config) InstancesPlacementWebhook() (webhookURL string, timeout time.Duration) {
+	return c.m.GetString("instances.placement.webhook"), time.Duration(c.m.GetInt64("instances.placement.webhook.timeout")) * time.Second
+}
+
 // LokiServer returns all the Loki settings needed to connect to a server.
 func (c *Config) LokiServer() (apiURL string, authUsername string, authPassword string, apiCACert string, instance string, logLevel string, labels []string, types []string) {
 	if c.m.GetString("loki.types") != "" {
@@ -253,6 +258,28 @@ func (c *Config) OIDCSessionExpiry() (expiry string) {
 	return c.m.GetString("oidc.session.expiry")
 }
 
+// ClusterRebalanceInterval returns the interval between automatic rebalancing runs, or 0 if disabled.
+func (c *Config) ClusterRebalanceInterval() time.Duration {
+	return time.Duration(c.m.GetInt64("cluster.rebalance.interval")) * time.Minute
+}
+
+// ClusterRebalanceThreshold returns the imbalance, in percent of the average number of instances per cluster member,
+// above which instances are rebalanced.
+func (c *Config) ClusterRebalanceThreshold() int64 {
+	return c.m.GetInt64("cluster.rebalance.threshold")
+}
+
+// ClusterRebalanceBatch returns the maximum number of instances moved during a single rebalancing run.
+func (c *Config) ClusterRebalanceBatch() int {
+	return int(c.m.GetInt64("cluster.rebalance.batch"))
+}
+
+// ClusterSchedulerWeights returns the relative weights of the CPU load, free memory, free network bandwidth and
+// free storage pool space used to score cluster members for automatic instance placement.
+func (c *Config) ClusterSchedulerWeights() (cpu int64, memory int64, network int64, storage int64) {
+	return c.m.GetInt64("cluster.scheduler.weight.cpu"), c.m.GetInt64("cluster.scheduler.weight.memory"), c.m.GetInt64("cluster.scheduler.weight.network"), c.m.GetInt64("cluster.scheduler.weight.storage")
+}
+
 // ClusterHealingThreshold returns the configured healing threshold, i.e. the
 // number of seconds after which an offline node will be evacuated automatically. If the config key
 // is set but its value is lower than cluster.offline_threshold it returns
@@ -429,6 +456,79 @@ var ConfigSchema = config.Schema{
 		//  shortdesc: Number of database voter members
 		"cluster.max_voters": {Type: config.Int64, Default: "3", Validator: maxVotersValidator},
 
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.batch)
+		// Specify the maximum number of instances that are moved during a single rebalancing run.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `1`
+		//  shortdesc: Maximum number of instances moved per rebalancing run
+		"cluster.rebalance.batch": {Type: config.Int64, Default: "1", Validator: validate.IsInRange(1, 100)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.interval)
+		// Specify the number of minutes between automatic rebalancing runs.
+		// To disable automatic rebalancing, set this option to `0`.
+		// See {ref}`clustering-instance-rebalancing` for more information.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `0`
+		//  shortdesc: Interval between automatic rebalancing runs
+		"cluster.rebalance.interval": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 10080)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.rebalance.threshold)
+		// Specify the difference, in percent of the average number of instances per cluster member,
+		// between the most and the least loaded cluster members above which instances are rebalanced.
+		// If weighted scheduling is enabled, the difference is instead expressed in percent of the
+		// score of the cluster members, based on their free resources.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `20`
+		//  shortdesc: Imbalance that triggers rebalancing
+		"cluster.rebalance.threshold": {Type: config.Int64, Default: "20", Validator: validate.IsInRange(1, 1000)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.scheduler.weight.cpu)
+		// Relative weight of the CPU load when scoring cluster members for automatic instance placement.
+		// Set any of the `cluster.scheduler.weight.*` options to a value greater than `0` to enable weighted scheduling.
+		// See {ref}`clustering-instance-placement-weights` for more information.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `0`
+		//  shortdesc: Weight of the CPU load for instance placement
+		"cluster.scheduler.weight.cpu": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 100)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.scheduler.weight.memory)
+		// Relative weight of the free memory when scoring cluster members for automatic instance placement.
+		// See {ref}`clustering-instance-placement-weights` for more information.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `0`
+		//  shortdesc: Weight of the free memory for instance placement
+		"cluster.scheduler.weight.memory": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 100)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.scheduler.weight.network)
+		// Relative weight of the free network bandwidth when scoring cluster members for automatic instance placement.
+		// See {ref}`clustering-instance-placement-weights` for more information.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `0`
+		//  shortdesc: Weight of the free network bandwidth for instance placement
+		"cluster.scheduler.weight.network": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 100)},
+
+		// lxdmeta:generate(entities=server; group=cluster; key=cluster.scheduler.weight.storage)
+		// Relative weight of the free storage pool space when scoring cluster members for automatic instance placement.
+		// See {ref}`clustering-instance-placement-weights` for more information.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `0`
+		//  shortdesc: Weight of the free storage pool space for instance placement
+		"cluster.scheduler.weight.storage": {Type: config.Int64, Default: "0", Validator: validate.IsInRange(0, 100)},
+
 		// lxdmeta:generate(entities=server; group=cluster; key=cluster.max_standby)
 		// Specify the maximum number of cluster members that are assigned the database stand-by role.
 		// This must be a number between `0` and `5`.
@@ -645,6 +745,25 @@ var ConfigSchema = config.Schema{
 		//  shortdesc: Whether to set `migration.stateful` to `true` for the instances
 		"instances.migration.stateful": {Type: config.Bool, Default: "false"},
 
+		// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.placement.webhook)
+		// When set, LXD sends a `POST` request with the instance and the candidate cluster members to this URL whenever it automatically places an instance, and places the instance on the cluster member that the endpoint returns.
+		// If the endpoint fails, doesn't respond in time or doesn't select a member, LXD falls back to its built-in placement policy.
+		// See {ref}`clustering-instance-placement-webhook` for the request and response format.
+		// ---
+		//  type: string
+		//  scope: global
+		//  shortdesc: URL of the instance placement webhook
+		"instances.placement.webhook": {Validator: validate.Optional(validate.IsRequestURL)},
+
+		// lxdmeta:generate(entities=server; group=miscellaneous; key=instances.placement.webhook.timeout)
+		// Specify the number of seconds to wait for the placement webhook to respond before falling back to the built-in placement policy.
+		// ---
+		//  type: integer
+		//  scope: global
+		//  defaultdesc: `5`
+		//  shortdesc: Timeout of the instance placement webhook
+		"instances.placement.webhook.timeout": {Type: config.Int64, Default: "5", Validator: validate.Optional(validate.IsInRange(1, 60))},
+
 		// TODO: Remove after sunset period
 		// lxdmeta:generate(entities=server; group=miscellaneous; key=user.instances.placement.scriptlet)
 		// Stores the migrated value from the deprecated `instances.placement.scriptlet` configuration key. LXD ignores this key; changing it has no effect. It exists only to preserve previously stored data and may be removed in a future release.
@@ -713,14 +832,14 @@ var ConfigSchema = config.Schema{
 
 		// lxdmeta:generate(entities=server; group=loki; key=loki.types)
 		// Specify a comma-separated list of events to send to the Loki server.
-		// The events can be any combination of `lifecycle`, `logging`, `ovn`, and `security`.
+		// The events can be any combination of `lifecycle`, `logging`, `network`, `ovn`, and `security`.
 		// ---
 		//  type: string
 		//  scope: global
 		//  defaultdesc: `lifecycle,logging`
 		//  shortdesc: Events to send to the Loki server
 		"loki.types": {Validator: validate.Optional(validate.IsListOf(validate.IsOneOf(
-			api.EventTypeLifecycle, api.EventTypeLogging, api.EventTypeOVN, api.EventTypeSecurity,
+			api.EventTypeLifecycle, api.EventTypeLogging, api.EventTypeNetwork, api.EventTypeOVN, api.EventTypeSecurity,
 		))), Default: "lifecycle,logging"},
 
 		// lxdmeta:generate(entities=server; group=oidc; key=oidc.client.id)
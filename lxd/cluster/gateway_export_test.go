@@ -52,3 +52,6 @@ func (g *Gateway) TryRLock() bool {
 func (g *Gateway) RUnlock() {
 	g.lock.RUnlock()
 }
+
+// NetworkThroughput exposes networkThroughput for tests.
+var NetworkThroughput = networkThroughput
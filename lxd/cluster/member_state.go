@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/sys/unix"
 
@@ -15,6 +17,7 @@ import (
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/task"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
@@ -49,6 +52,149 @@ func getLoadAvgs() ([]float64, error) {
 	return loadAvgs, nil
 }
 
+// networkUsageInterval is the interval at which the throughput of the host's network interfaces is sampled.
+const networkUsageInterval = 5 * time.Second
+
+// networkUsageSample holds a reading of the network counters and the throughput measured since the previous one.
+type networkUsageSample struct {
+	time       time.Time
+	rxBytes    uint64
+	txBytes    uint64
+	bandwidth  uint64
+	throughput uint64
+}
+
+var networkUsageMu sync.Mutex
+var networkUsageLast networkUsageSample
+
+// readSysfsUint reads an unsigned integer from a sysfs file.
+func readSysfsUint(path string) (uint64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// getNetworkCounters returns the combined link speed (in bits per second) and the combined received and transmitted
+// bytes of the host's physical network interfaces that are up.
+func getNetworkCounters() (bandwidth uint64, rxBytes uint64, txBytes uint64, err error) {
+	const sysClassNet = "/sys/class/net"
+
+	entries, err := os.ReadDir(sysClassNet)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	for _, entry := range entries {
+		devPath := filepath.Join(sysClassNet, entry.Name())
+
+		// Skip virtual interfaces.
+		if !shared.PathExists(filepath.Join(devPath, "device")) {
+			continue
+		}
+
+		operState, err := os.ReadFile(filepath.Join(devPath, "operstate"))
+		if err != nil || strings.TrimSpace(string(operState)) != "up" {
+			continue
+		}
+
+		// The speed is reported in Mbit/s and is invalid for interfaces that don't know their link speed.
+		speed, err := readSysfsUint(filepath.Join(devPath, "speed"))
+		if err != nil || speed == 0 {
+			continue
+		}
+
+		devRxBytes, err := readSysfsUint(filepath.Join(devPath, "statistics", "rx_bytes"))
+		if err != nil {
+			continue
+		}
+
+		devTxBytes, err := readSysfsUint(filepath.Join(devPath, "statistics", "tx_bytes"))
+		if err != nil {
+			continue
+		}
+
+		bandwidth += speed * 1000 * 1000
+		rxBytes += devRxBytes
+		txBytes += devTxBytes
+	}
+
+	return bandwidth, rxBytes, txBytes, nil
+}
+
+// networkThroughput returns the throughput in bits per second between two counter readings taken elapsed apart.
+// The busiest direction is used as the links are full duplex.
+func networkThroughput(rxBefore uint64, txBefore uint64, rxAfter uint64, txAfter uint64, elapsed time.Duration) uint64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	var rxDelta, txDelta uint64
+
+	// Counters go backwards when interfaces disappear, ignore those directions.
+	if rxAfter >= rxBefore {
+		rxDelta = rxAfter - rxBefore
+	}
+
+	if txAfter >= txBefore {
+		txDelta = txAfter - txBefore
+	}
+
+	return uint64(float64(max(rxDelta, txDelta)*8) / elapsed.Seconds())
+}
+
+// sampleNetworkUsage reads the network counters and records the throughput since the previous sample.
+func sampleNetworkUsage() error {
+	bandwidth, rxBytes, txBytes, err := getNetworkCounters()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+
+	networkUsageMu.Lock()
+	defer networkUsageMu.Unlock()
+
+	sample := networkUsageSample{
+		time:      now,
+		rxBytes:   rxBytes,
+		txBytes:   txBytes,
+		bandwidth: bandwidth,
+	}
+
+	if !networkUsageLast.time.IsZero() {
+		sample.throughput = networkThroughput(networkUsageLast.rxBytes, networkUsageLast.txBytes, rxBytes, txBytes, now.Sub(networkUsageLast.time))
+	}
+
+	networkUsageLast = sample
+
+	return nil
+}
+
+// NetworkUsageTask returns a task sampling the throughput of the host's network interfaces every
+// [networkUsageInterval], so that member state requests don't have to measure it themselves.
+func NetworkUsageTask() (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		err := sampleNetworkUsage()
+		if err != nil {
+			logger.Warn("Failed sampling network usage", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(networkUsageInterval)
+}
+
+// getNetworkUsage returns the combined link speed and the throughput (in bits per second) of the host's physical
+// network interfaces that are up, as of the latest sample taken by [NetworkUsageTask].
+func getNetworkUsage() (bandwidth uint64, throughput uint64) {
+	networkUsageMu.Lock()
+	defer networkUsageMu.Unlock()
+
+	return networkUsageLast.bandwidth, networkUsageLast.throughput
+}
+
 // LocalSysInfo retrieves system information about a cluster member.
 func LocalSysInfo() (*api.ClusterMemberSysInfo, error) {
 	// Get system info.
@@ -81,6 +227,8 @@ func LocalSysInfo() (*api.ClusterMemberSysInfo, error) {
 	// not the currently available number of threads.
 	sysInfo.LogicalCPUs = uint64(runtime.NumCPU())
 
+	sysInfo.NetworkBandwidth, sysInfo.NetworkThroughput = getNetworkUsage()
+
 	return sysInfo, nil
 }
 
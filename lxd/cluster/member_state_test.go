@@ -3,6 +3,7 @@ package cluster_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -75,3 +76,14 @@ func TestClusterState(t *testing.T) {
 		assert.Equal(t, uint64(24), state.SysInfo.LogicalCPUs)
 	}
 }
+
+func TestNetworkThroughput(t *testing.T) {
+	// 1 MB received and 0.5 MB transmitted in 250ms is 32 Mbit/s in the busiest direction.
+	assert.Equal(t, uint64(32*1000*1000), cluster.NetworkThroughput(0, 0, 1000*1000, 500*1000, 250*time.Millisecond))
+
+	// Counters going backwards only drop that direction.
+	assert.Equal(t, uint64(16*1000*1000), cluster.NetworkThroughput(2000*1000, 0, 0, 500*1000, 250*time.Millisecond))
+
+	// No elapsed time means no throughput.
+	assert.Equal(t, uint64(0), cluster.NetworkThroughput(0, 0, 1000, 1000, 0))
+}
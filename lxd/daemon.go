@@ -58,6 +58,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/loki"
 	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	networkZone "github.com/canonical/lxd/lxd/network/zone"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/operations"
@@ -91,6 +92,7 @@ type Daemon struct {
 	firewall      firewall.Firewall
 	bgp           *bgp.Server
 	dns           *dns.Server
+	flowLog       *flowlog.Logger
 
 	// Event servers
 	devLXDEvents     *events.DevLXDServer
@@ -714,6 +716,7 @@ func (d *Daemon) State() *state.State {
 		DB:                  d.db,
 		BGP:                 d.bgp,
 		DNS:                 d.dns,
+		FlowLog:             d.flowLog,
 		OS:                  d.os,
 		Endpoints:           d.endpoints,
 		Events:              d.events,
@@ -1536,6 +1539,7 @@ func (d *Daemon) init() error {
 		ClusterAddress:       localClusterAddress,
 		DebugAddress:         debugAddress,
 		MetricsServer:        metricsServer(d),
+		StorageBucketsServer: storageBucketsServer(d),
 		VsockServer:          vSockServer(d),
 		VsockSupport:         false,
 	}
@@ -1807,6 +1811,23 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	// Setup network flow logger.
+	d.flowLog = flowlog.NewLogger(func(projectName string, flow api.EventNetworkFlow) error {
+		return d.events.Send(projectName, api.EventTypeNetwork, flow)
+	}, func(aclID int64) (string, string, error) {
+		var projectName, aclName string
+
+		err := d.db.Cluster.Transaction(d.shutdownCtx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			aclName, projectName, err = tx.GetNetworkACLNameAndProjectWithID(ctx, int(aclID))
+
+			return err
+		})
+
+		return projectName, aclName, err
+	})
+
 	if syslogSocketEnabled {
 		err = d.setupSyslogSocket(true)
 		if err != nil {
@@ -1897,6 +1918,14 @@ func (d *Daemon) init() error {
 		}
 	}
 
+	storageBucketsAddress := d.localConfig.StorageBucketsAddress()
+	if storageBucketsAddress != "" {
+		err = d.endpoints.UpStorageBuckets(storageBucketsAddress)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Apply all patches that need to be run after networks are initialised.
 	err = patchesApply(d, patchPostNetworks)
 	if err != nil {
@@ -2020,6 +2049,9 @@ func (d *Daemon) init() error {
 		// Prune expired custom volume snapshots and take snapshots of custom volumes (minutely check of configurable cron expression)
 		d.tasks.Add(pruneExpiredAndAutoCreateCustomVolumeSnapshotsTask(d.State))
 
+		// Take backups of instances and custom volumes and apply their retention policies (minutely check of configurable cron expression)
+		d.tasks.Add(autoCreateAndPruneScheduledBackupsTask(d.State))
+
 		// Remove resolved warnings (daily)
 		d.tasks.Add(pruneResolvedWarningsTask(d.State))
 
@@ -2031,6 +2063,9 @@ func (d *Daemon) init() error {
 
 		// Run scheduled replicators (minutely check of configurable cron expression)
 		d.tasks.Add(runScheduledReplicatorsTask(d.State))
+
+		// Refresh network address sets referencing instances (minutely)
+		d.tasks.Add(networkAddressSetsRefreshTask(d.State))
 	}
 
 	// Load Ubuntu Pro configuration before starting any instances.
@@ -2137,6 +2172,9 @@ func (d *Daemon) startClusterTasks() {
 	// Heartbeats
 	d.taskClusterHeartbeat = d.clusterTasks.Add(cluster.HeartbeatTask(d.gateway))
 
+	// Sample the network usage used to weigh instance placement (every 5 seconds)
+	d.clusterTasks.Add(cluster.NetworkUsageTask())
+
 	// Auto-sync images across the cluster (hourly)
 	d.clusterTasks.Add(autoSyncImagesTask(d.State))
 
@@ -2155,6 +2193,12 @@ func (d *Daemon) startClusterTasks() {
 	// Refresh cluster link volatile addresses (daily).
 	d.clusterTasks.Add(autoRefreshClusterLinkVolatileAddressesTask(d.State))
 
+	// Promote standby projects whose leader is unreachable and fence leaders that were taken over (minutely).
+	d.clusterTasks.Add(autoReplicaFailoverTask(d.State))
+
+	// Move instances from the most to the least loaded cluster members (every cluster.rebalance.interval).
+	d.clusterTasks.Add(autoRebalanceClusterTask(d.State))
+
 	// Start all background tasks
 	d.clusterTasks.Start(d.shutdownCtx)
 }
@@ -2403,7 +2447,7 @@ func (d *Daemon) setupSyslogSocket(enable bool) error {
 
 	logger.Debug("Starting syslog socket")
 
-	err := StartSyslogListener(ctx, d.events)
+	err := StartSyslogListener(ctx, d.events, d.flowLog)
 	if err != nil {
 		return err
 	}
@@ -2692,6 +2736,12 @@ func (d *Daemon) nodeRefreshTask(heartbeatData *cluster.APIHeartbeat, isLeader b
 		logger.Error("Error restarting OVN networks", logger.Ctx{"err": err})
 	}
 
+	// Refresh the peers of WireGuard mesh tunnels.
+	err = networkUpdateWireGuardMeshTask(s, heartbeatData)
+	if err != nil {
+		logger.Error("Error refreshing WireGuard mesh peers", logger.Ctx{"err": err, "local": localClusterAddress})
+	}
+
 	if d.hasMemberStateChanged(heartbeatData) {
 		logger.Info("Cluster member state has changed", logger.Ctx{"local": localClusterAddress})
 
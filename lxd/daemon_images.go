@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -61,6 +63,32 @@ func imageOperationLock(fingerprint string) (locking.UnlockFunc, error) {
 	return locking.Lock(context.TODO(), "ImageOperation_"+fingerprint)
 }
 
+// imageRenameToHash renames the image file to its SHA-256 hash within the images directory and returns the hash.
+func imageRenameToHash(root *os.Root, name string) (string, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return "", err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+
+	_, err = io.Copy(hash, f)
+	if err != nil {
+		return "", err
+	}
+
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+	err = root.Rename(name, fingerprint)
+	if err != nil {
+		return "", err
+	}
+
+	return fingerprint, nil
+}
+
 // ImageDownload resolves the image fingerprint and if not in the database, downloads it.
 func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation, args *ImageDownloadArgs) (*api.Image, error) {
 	l := logger.AddContext(logger.Ctx{"image": args.Alias, "member": s.ServerName, "project": args.ProjectName, "pool": args.StoragePool, "source": args.Server})
@@ -82,7 +110,7 @@ func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation
 	fp := alias
 
 	// Attempt to resolve the alias
-	if slices.Contains([]string{"lxd", "simplestreams"}, protocol) {
+	if slices.Contains([]string{"lxd", "simplestreams", "oci"}, protocol) {
 		// Use the proxy from args if provided (e.g., a no-op proxy for intra-cluster connections),
 		// otherwise fall back to the daemon-level proxy.
 		proxy := args.Proxy
@@ -109,6 +137,12 @@ func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation
 			if ok {
 				remote = server.UseProject(args.SourceProjectName)
 			}
+		} else if protocol == "oci" {
+			// Setup OCI registry client
+			remote, err = lxd.ConnectOCI(args.Server, clientArgs)
+			if err != nil {
+				return nil, fmt.Errorf("Failed connecting to OCI registry %q: %w", args.Server, err)
+			}
 		} else {
 			// Setup simplestreams client
 			remote, err = lxd.ConnectSimpleStreams(args.Server, clientArgs)
@@ -161,6 +195,27 @@ func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation
 
 	defer unlock()
 
+	// Images from OCI registries are built locally so their fingerprint isn't known to the registry.
+	// Look for an image previously built from the same manifest instead.
+	if protocol == "oci" {
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			fingerprints, err := tx.GetImageFingerprintsByProperty(ctx, "oci.digest", info.Properties["oci.digest"])
+			if err != nil {
+				return err
+			}
+
+			if len(fingerprints) > 0 {
+				fp = fingerprints[0]
+				info.Fingerprint = fp
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// If auto-update is on and we're being given the image by
 	// alias, try to use a locally cached image matching the given
 	// server/protocol/alias, regardless of whether it's stale or
@@ -358,7 +413,7 @@ func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation
 
 	// Validate the protocol. This is after handling cached images to allow previously downloaded images that were
 	// retrieved using the now deprecated "direct" protocol.
-	if !slices.Contains([]string{"lxd", "simplestreams"}, protocol) {
+	if !slices.Contains([]string{"lxd", "simplestreams", "oci"}, protocol) {
 		return nil, fmt.Errorf("Unsupported protocol: %v", protocol)
 	}
 
@@ -479,6 +534,18 @@ func ImageDownload(ctx context.Context, s *state.State, op *operations.Operation
 		return nil, err
 	}
 
+	// Images built from OCI images are identified by the hash of their image file rather than by their manifest digest.
+	if protocol == "oci" {
+		fp, err = imageRenameToHash(destRoot, fp)
+		if err != nil {
+			return nil, fmt.Errorf("Failed hashing image built from OCI image: %w", err)
+		}
+
+		info.Fingerprint = fp
+		info.Filename = resp.MetaName
+		info.Size = resp.MetaSize
+	}
+
 	// Override visiblity
 	info.Public = args.Public
 
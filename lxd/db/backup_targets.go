@@ -0,0 +1,131 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// BackupTarget represents a backup target.
+type BackupTarget struct {
+	ID          int64
+	Name        string
+	Description string
+	Config      map[string]string
+}
+
+// ToAPI returns the API representation of the backup target.
+func (t *BackupTarget) ToAPI() *api.BackupTarget {
+	return &api.BackupTarget{
+		Name: t.Name,
+		BackupTargetPut: api.BackupTargetPut{
+			Description: t.Description,
+			Config:      t.Config,
+		},
+	}
+}
+
+// GetBackupTargetNames returns the names of all backup targets.
+func (c *ClusterTx) GetBackupTargetNames(ctx context.Context) ([]string, error) {
+	return query.SelectStrings(ctx, c.tx, "SELECT name FROM backup_targets ORDER BY name")
+}
+
+// GetBackupTarget returns the backup target with the given name.
+func (c *ClusterTx) GetBackupTarget(ctx context.Context, name string) (*BackupTarget, error) {
+	target := BackupTarget{Name: name}
+
+	err := c.tx.QueryRowContext(ctx, "SELECT id, description FROM backup_targets WHERE name = ? LIMIT 1", name).Scan(&target.ID, &target.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Backup target not found")
+		}
+
+		return nil, err
+	}
+
+	target.Config = map[string]string{}
+
+	q := "SELECT key, value FROM backup_targets_config WHERE backup_target_id = ?"
+	err = query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		target.Config[key] = value
+
+		return nil
+	}, target.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading backup target config: %w", err)
+	}
+
+	return &target, nil
+}
+
+// CreateBackupTarget creates a new backup target.
+func (c *ClusterTx) CreateBackupTarget(ctx context.Context, name string, description string, config map[string]string) (int64, error) {
+	result, err := c.tx.ExecContext(ctx, "INSERT INTO backup_targets (name, description) VALUES (?, ?)", name, description)
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = backupTargetConfigAdd(ctx, c.tx, id, config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// UpdateBackupTarget updates the description and config of the backup target with the given ID.
+func (c *ClusterTx) UpdateBackupTarget(ctx context.Context, id int64, description string, config map[string]string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE backup_targets SET description = ? WHERE id = ?", description, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM backup_targets_config WHERE backup_target_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	return backupTargetConfigAdd(ctx, c.tx, id, config)
+}
+
+// DeleteBackupTarget deletes the backup target with the given ID.
+func (c *ClusterTx) DeleteBackupTarget(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM backup_targets WHERE id = ?", id)
+
+	return err
+}
+
+// backupTargetConfigAdd inserts backup target config keys.
+func backupTargetConfigAdd(ctx context.Context, tx *sql.Tx, id int64, config map[string]string) error {
+	for key, value := range config {
+		if value == "" {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO backup_targets_config (backup_target_id, key, value) VALUES (?, ?, ?)", id, key, value)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
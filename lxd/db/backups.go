@@ -24,6 +24,7 @@ type InstanceBackup struct {
 	InstanceOnly         bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	Scheduled            bool
 }
 
 // StoragePoolVolumeBackup is a value object holding all db-related details about a storage volume backup.
@@ -36,6 +37,7 @@ type StoragePoolVolumeBackup struct {
 	VolumeOnly           bool
 	OptimizedStorage     bool
 	CompressionAlgorithm string
+	Scheduled            bool
 }
 
 // Returns the ID of the instance backup with the given name.
@@ -161,6 +163,41 @@ WHERE projects.name=? AND instances.name=?`
 	return instanceBackupNames, nil
 }
 
+// GetInstanceScheduledBackups returns the backups of the instance with the given ID that were
+// created by the backup schedule, ordered from oldest to newest.
+func (c *ClusterTx) GetInstanceScheduledBackups(ctx context.Context, instanceID int) ([]InstanceBackup, error) {
+	q := `
+SELECT instances_backups.id, instances_backups.name, instances_backups.creation_date,
+       instances_backups.expiry_date, instances_backups.container_only, instances_backups.optimized_storage
+    FROM instances_backups
+    WHERE instances_backups.instance_id=? AND instances_backups.scheduled=1
+    ORDER BY instances_backups.creation_date, instances_backups.id
+`
+
+	var backups []InstanceBackup
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		b := InstanceBackup{InstanceID: instanceID, Scheduled: true}
+		var expiryTime sql.NullTime
+
+		err := scan(&b.ID, &b.Name, &b.CreationDate, &expiryTime, &b.InstanceOnly, &b.OptimizedStorage)
+		if err != nil {
+			return err
+		}
+
+		b.ExpiryDate = expiryTime.Time // Convert nulls to zero.
+
+		backups = append(backups, b)
+
+		return nil
+	}, instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
 // CreateInstanceBackup creates a new backup.
 func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBackup) error {
 	_, err := c.getInstanceBackupID(ctx, args.Name)
@@ -178,7 +215,12 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 		optimizedStorageInt = 1
 	}
 
-	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage) VALUES (?, ?, ?, ?, ?, ?)"
+	scheduledInt := 0
+	if args.Scheduled {
+		scheduledInt = 1
+	}
+
+	str := "INSERT INTO instances_backups (instance_id, name, creation_date, expiry_date, container_only, optimized_storage, scheduled) VALUES (?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := c.tx.Prepare(str)
 	if err != nil {
 		return err
@@ -187,7 +229,7 @@ func (c *ClusterTx) CreateInstanceBackup(ctx context.Context, args InstanceBacku
 	defer func() { _ = stmt.Close() }()
 	result, err := stmt.Exec(args.InstanceID, args.Name,
 		args.CreationDate.Unix(), args.ExpiryDate.Unix(), instanceOnlyInt,
-		optimizedStorageInt)
+		optimizedStorageInt, scheduledInt)
 	if err != nil {
 		return err
 	}
@@ -395,6 +437,46 @@ ORDER BY storage_volumes_backups.id`
 	return storagePoolVolumeBackupsNames, nil
 }
 
+// GetStoragePoolVolumeScheduledBackups returns the backups of the storage volume with the given ID
+// that were created by the backup schedule, ordered from oldest to newest.
+func (c *ClusterTx) GetStoragePoolVolumeScheduledBackups(ctx context.Context, volumeID int64) ([]StoragePoolVolumeBackup, error) {
+	q := `
+	SELECT
+		backups.id,
+		backups.name,
+		backups.creation_date,
+		backups.expiry_date,
+		backups.volume_only,
+		backups.optimized_storage
+	FROM storage_volumes_backups AS backups
+	WHERE backups.storage_volume_id=? AND backups.scheduled=1
+	ORDER BY backups.creation_date, backups.id
+	`
+
+	var backups []StoragePoolVolumeBackup
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		b := StoragePoolVolumeBackup{VolumeID: volumeID, Scheduled: true}
+		var expiryTime sql.NullTime
+
+		err := scan(&b.ID, &b.Name, &b.CreationDate, &expiryTime, &b.VolumeOnly, &b.OptimizedStorage)
+		if err != nil {
+			return err
+		}
+
+		b.ExpiryDate = expiryTime.Time // Convert nulls to zero.
+
+		backups = append(backups, b)
+
+		return nil
+	}, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return backups, nil
+}
+
 // CreateStoragePoolVolumeBackup creates a new storage volume backup.
 func (c *ClusterTx) CreateStoragePoolVolumeBackup(ctx context.Context, args StoragePoolVolumeBackup) error {
 	_, err := c.getStoragePoolVolumeBackupID(ctx, args.Name)
@@ -412,7 +494,12 @@ func (c *ClusterTx) CreateStoragePoolVolumeBackup(ctx context.Context, args Stor
 		optimizedStorageInt = 1
 	}
 
-	str := "INSERT INTO storage_volumes_backups (storage_volume_id, name, creation_date, expiry_date, volume_only, optimized_storage) VALUES (?, ?, ?, ?, ?, ?)"
+	scheduledInt := 0
+	if args.Scheduled {
+		scheduledInt = 1
+	}
+
+	str := "INSERT INTO storage_volumes_backups (storage_volume_id, name, creation_date, expiry_date, volume_only, optimized_storage, scheduled) VALUES (?, ?, ?, ?, ?, ?, ?)"
 	stmt, err := c.tx.Prepare(str)
 	if err != nil {
 		return err
@@ -421,7 +508,7 @@ func (c *ClusterTx) CreateStoragePoolVolumeBackup(ctx context.Context, args Stor
 	defer func() { _ = stmt.Close() }()
 	result, err := stmt.Exec(args.VolumeID, args.Name,
 		args.CreationDate.Unix(), args.ExpiryDate.Unix(), volumeOnlyInt,
-		optimizedStorageInt)
+		optimizedStorageInt, scheduledInt)
 	if err != nil {
 		return err
 	}
@@ -1032,3 +1032,124 @@ func (r ReplicatorRow) CreateStmt() string {
 func (r ReplicatorRow) UpdateStmt() string {
 	return "UPDATE replicators SET name = ?, project_id = ?, description = ?, last_run_date = ?, last_run_status = ? "
 }
+
+// TableName returns the table name for [ReplicatorRunInstanceRow] entities.
+func (r ReplicatorRunInstanceRow) TableName() string {
+	return "replicators_runs_instances"
+}
+
+// SelectColumns returns a slice of column names for [ReplicatorRunInstanceRow] entities.
+func (r ReplicatorRunInstanceRow) SelectColumns() []string {
+	return []string{
+		"replicators_runs_instances.id",
+		"replicators_runs_instances.replicator_run_id",
+		"replicators_runs_instances.instance_name",
+		"replicators_runs_instances.status",
+		"replicators_runs_instances.reason",
+		"replicators_runs_instances.bytes_transferred",
+	}
+}
+
+// Joins returns a slice of join expressions for [ReplicatorRunInstanceRow].
+func (r ReplicatorRunInstanceRow) Joins() []string {
+	return []string{}
+}
+
+// ScanArgs implements [query.ScanArger] for [ReplicatorRunInstanceRow].
+// This returns references to struct fields in definition order.
+func (r *ReplicatorRunInstanceRow) ScanArgs() []any {
+	return []any{&r.ID, &r.ReplicatorRunID, &r.InstanceName, &r.Status, &r.Reason, &r.BytesTransferred}
+}
+
+// CreateValues returns a list of values from [ReplicatorRunInstanceRow] entities matching the bind arguments in [CreateStmt].
+func (r ReplicatorRunInstanceRow) CreateValues() []any {
+	return []any{r.ReplicatorRunID, r.InstanceName, r.Status, r.Reason, r.BytesTransferred}
+}
+
+// UpdateValues returns a list of values from [ReplicatorRunInstanceRow] entities matching the columns in [UpdateStmt].
+func (r ReplicatorRunInstanceRow) UpdateValues() []any {
+	return []any{r.ReplicatorRunID, r.InstanceName, r.Status, r.Reason, r.BytesTransferred}
+}
+
+// PKColumns returns the column names for the primary key of a [ReplicatorRunInstanceRow] entity used during an update.
+// The returned slice must have the same number of elements as PKValues.
+func (r ReplicatorRunInstanceRow) PKColumns() []string {
+	return []string{"id"}
+}
+
+// PKValues returns the values for the primary key of a [ReplicatorRunInstanceRow] entity used during an update.
+// The returned slice must have the same number of elements as PKColumns.
+func (r ReplicatorRunInstanceRow) PKValues() []any {
+	return []any{r.ID}
+}
+
+// CreateStmt returns a query that creates a [ReplicatorRunInstanceRow] entity.
+func (r ReplicatorRunInstanceRow) CreateStmt() string {
+	return "INSERT INTO replicators_runs_instances (replicator_run_id, instance_name, status, reason, bytes_transferred) VALUES (?, ?, ?, ?, ?)"
+}
+
+// UpdateStmt returns a query that updates a [ReplicatorRunInstanceRow] by primary key.
+func (r ReplicatorRunInstanceRow) UpdateStmt() string {
+	return "UPDATE replicators_runs_instances SET replicator_run_id = ?, instance_name = ?, status = ?, reason = ?, bytes_transferred = ? "
+}
+
+// TableName returns the table name for [ReplicatorRunRow] entities.
+func (r ReplicatorRunRow) TableName() string {
+	return "replicators_runs"
+}
+
+// SelectColumns returns a slice of column names for [ReplicatorRunRow] entities.
+func (r ReplicatorRunRow) SelectColumns() []string {
+	return []string{
+		"replicators_runs.id",
+		"replicators_runs.replicator_id",
+		"replicators_runs.action",
+		"replicators_runs.start_date",
+		"replicators_runs.end_date",
+		"replicators_runs.status",
+		"replicators_runs.bytes_transferred",
+	}
+}
+
+// Joins returns a slice of join expressions for [ReplicatorRunRow].
+func (r ReplicatorRunRow) Joins() []string {
+	return []string{}
+}
+
+// ScanArgs implements [query.ScanArger] for [ReplicatorRunRow].
+// This returns references to struct fields in definition order.
+func (r *ReplicatorRunRow) ScanArgs() []any {
+	return []any{&r.ID, &r.ReplicatorID, &r.Action, &r.StartDate, &r.EndDate, &r.Status, &r.BytesTransferred}
+}
+
+// CreateValues returns a list of values from [ReplicatorRunRow] entities matching the bind arguments in [CreateStmt].
+func (r ReplicatorRunRow) CreateValues() []any {
+	return []any{r.ReplicatorID, r.Action, r.StartDate, r.EndDate, r.Status, r.BytesTransferred}
+}
+
+// UpdateValues returns a list of values from [ReplicatorRunRow] entities matching the columns in [UpdateStmt].
+func (r ReplicatorRunRow) UpdateValues() []any {
+	return []any{r.ReplicatorID, r.Action, r.StartDate, r.EndDate, r.Status, r.BytesTransferred}
+}
+
+// PKColumns returns the column names for the primary key of a [ReplicatorRunRow] entity used during an update.
+// The returned slice must have the same number of elements as PKValues.
+func (r ReplicatorRunRow) PKColumns() []string {
+	return []string{"id"}
+}
+
+// PKValues returns the values for the primary key of a [ReplicatorRunRow] entity used during an update.
+// The returned slice must have the same number of elements as PKColumns.
+func (r ReplicatorRunRow) PKValues() []any {
+	return []any{r.ID}
+}
+
+// CreateStmt returns a query that creates a [ReplicatorRunRow] entity.
+func (r ReplicatorRunRow) CreateStmt() string {
+	return "INSERT INTO replicators_runs (replicator_id, action, start_date, end_date, status, bytes_transferred) VALUES (?, ?, ?, ?, ?, ?)"
+}
+
+// UpdateStmt returns a query that updates a [ReplicatorRunRow] by primary key.
+func (r ReplicatorRunRow) UpdateStmt() string {
+	return "UPDATE replicators_runs SET replicator_id = ?, action = ?, start_date = ?, end_date = ?, status = ?, bytes_transferred = ? "
+}
@@ -167,6 +167,7 @@ var ImageSourceProtocol = map[int]string{
 	0: "lxd",
 	1: "direct", // Deprecated: kept for backward compatibility with existing data.
 	2: "simplestreams",
+	3: "oci",
 }
 
 // GetImageSource returns the image source with the given ID.
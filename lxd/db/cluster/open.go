@@ -141,9 +141,13 @@ func EnsureSchema(db *sql.DB, address string, dir string, serverUUID string) err
 			return fmt.Errorf("Failed updating cluster member version info for %q: %w", address, err)
 		}
 
-		err = checkNoLocalStorageBuckets(ctx, tx)
-		if err != nil {
-			return err
+		// Storage buckets on local storage pools created before the local storage bucket support was
+		// reintroduced can't be migrated and must be removed before upgrading.
+		if current < localStorageBucketsSchemaVersion {
+			err = checkNoLocalStorageBuckets(ctx, tx)
+			if err != nil {
+				return err
+			}
 		}
 
 		return checkClusterIsUpgradable(ctx, tx, [2]int{len(updates), apiExtensions})
@@ -268,9 +272,14 @@ func dqliteDriverName() string {
 // registered.
 var dqliteDriverSerial uint64
 
+// localStorageBucketsSchemaVersion is the first schema version applied by LXD versions which support storage
+// buckets on local storage pools again. Databases at this version or later can't contain legacy local buckets.
+const localStorageBucketsSchemaVersion = 90
+
 // checkNoLocalStorageBuckets returns an error if the database contains any storage buckets backed by
-// a local (non-object) storage pool driver. Such buckets are no longer supported and must be removed
-// before the schema can be upgraded.
+// a local (non-object) storage pool driver. Such legacy buckets use a storage layout incompatible with
+// the local storage buckets supported since localStorageBucketsSchemaVersion and must be removed before
+// the schema can be upgraded.
 func checkNoLocalStorageBuckets(ctx context.Context, tx *sql.Tx) error {
 	// Check whether the storage_buckets table already exists (it may not in very old schemas).
 	var tableName string
@@ -295,7 +304,7 @@ WHERE sp.driver != 'cephobject'
 	}
 
 	if count > 0 {
-		return errors.New("This LXD version no longer supports storage buckets backed by local storage drivers. " +
+		return errors.New("This LXD version doesn't support the storage buckets backed by local storage drivers of previous versions. " +
 			"This server has one or more such buckets and cannot be upgraded. " +
 			"To continue using those buckets, roll back to the previous LXD version. " +
 			"To proceed with the upgrade, back up and delete all locally-backed storage buckets first, then retry.")
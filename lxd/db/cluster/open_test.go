@@ -166,6 +166,38 @@ func TestEnsureSchema_UpdateNodeVersion(t *testing.T) {
 	}
 }
 
+// Legacy storage buckets on local storage pools block the schema upgrade, object storage buckets don't.
+func TestCheckNoLocalStorageBuckets(t *testing.T) {
+	cases := []struct {
+		driver string
+		error  string
+	}{
+		{driver: "cephobject"},
+		{driver: "zfs", error: "This LXD version doesn't support the storage buckets backed by local storage drivers of previous versions"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.driver, func(t *testing.T) {
+			db := newDB(t)
+
+			_, err := db.Exec(`INSERT INTO storage_pools(name, driver, description) VALUES ('pool', ?, '')`, c.driver)
+			require.NoError(t, err)
+
+			_, err = db.Exec(`INSERT INTO storage_buckets(name, storage_pool_id, description, project_id) VALUES ('bucket', 1, '', 1)`)
+			require.NoError(t, err)
+
+			err = query.Transaction(context.TODO(), db, func(ctx context.Context, tx *sql.Tx) error {
+				return checkNoLocalStorageBuckets(ctx, tx)
+			})
+			if c.error == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, c.error)
+			}
+		})
+	}
+}
+
 // Create a new in-memory SQLite database with a fresh cluster schema.
 func newDB(t *testing.T) *sql.DB {
 	db, err := sql.Open("sqlite3", ":memory:")
@@ -217,6 +217,33 @@ func GetProjectConfig(ctx context.Context, tx *sql.Tx, projectName string) (map[
 	return result, nil
 }
 
+// UpdateProjectConfigKeys sets the given config keys of the project with the given name, leaving any other
+// keys untouched. Keys with an empty value are removed.
+func UpdateProjectConfigKeys(ctx context.Context, tx *sql.Tx, projectName string, config map[string]string) error {
+	projectID, err := GetProjectID(ctx, tx, projectName)
+	if err != nil {
+		return fmt.Errorf("Failed loading project: %w", err)
+	}
+
+	for key, value := range config {
+		_, err = tx.ExecContext(ctx, "DELETE FROM projects_config WHERE project_id = ? AND key = ?", projectID, key)
+		if err != nil {
+			return fmt.Errorf("Failed deleting project config key %q: %w", key, err)
+		}
+
+		if value == "" {
+			continue
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO projects_config (project_id, key, value) VALUES (?, ?, ?)", projectID, key, value)
+		if err != nil {
+			return fmt.Errorf("Failed setting project config key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
 // GetProjectNames returns the names of all available projects.
 func GetProjectNames(ctx context.Context, tx *sql.Tx) ([]string, error) {
 	stmt := "SELECT name FROM projects"
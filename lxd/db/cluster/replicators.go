@@ -152,3 +152,117 @@ func UpdateReplicatorLastRunStatus(ctx context.Context, tx *sql.Tx, id int64, st
 	_, err := tx.ExecContext(ctx, `UPDATE replicators SET last_run_status=? WHERE id=?`, status, id)
 	return err
 }
+
+// ReplicatorRunRow represents a single row of the replicators_runs table.
+// db:model replicators_runs
+type ReplicatorRunRow struct {
+	ID               int64     `db:"id"`
+	ReplicatorID     int64     `db:"replicator_id"`
+	Action           string    `db:"action"`
+	StartDate        time.Time `db:"start_date"`
+	EndDate          time.Time `db:"end_date"`
+	Status           string    `db:"status"`
+	BytesTransferred int64     `db:"bytes_transferred"`
+}
+
+// APIName implements [query.APINamer] for API friendly error messages.
+func (ReplicatorRunRow) APIName() string {
+	return "Replicator run"
+}
+
+// ReplicatorRunInstanceRow represents a single row of the replicators_runs_instances table.
+// db:model replicators_runs_instances
+type ReplicatorRunInstanceRow struct {
+	ID               int64  `db:"id"`
+	ReplicatorRunID  int64  `db:"replicator_run_id"`
+	InstanceName     string `db:"instance_name"`
+	Status           string `db:"status"`
+	Reason           string `db:"reason"`
+	BytesTransferred int64  `db:"bytes_transferred"`
+}
+
+// APIName implements [query.APINamer] for API friendly error messages.
+func (ReplicatorRunInstanceRow) APIName() string {
+	return "Replicator run instance"
+}
+
+// CreateReplicatorRun records a finished run of the replicator with the given ID along with its per-instance
+// outcomes. Only the most recent keepRuns runs of the replicator are kept.
+func CreateReplicatorRun(ctx context.Context, tx *sql.Tx, run api.ReplicatorRun, replicatorID int64, keepRuns int) error {
+	runID, err := query.Create(ctx, tx, ReplicatorRunRow{
+		ReplicatorID:     replicatorID,
+		Action:           run.Action,
+		StartDate:        run.StartedAt,
+		EndDate:          run.FinishedAt,
+		Status:           run.Status,
+		BytesTransferred: run.BytesTransferred,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed creating replicator run: %w", err)
+	}
+
+	instances := make([]ReplicatorRunInstanceRow, 0, len(run.Instances))
+	for _, inst := range run.Instances {
+		instances = append(instances, ReplicatorRunInstanceRow{
+			ReplicatorRunID:  runID,
+			InstanceName:     inst.Name,
+			Status:           inst.Status,
+			Reason:           inst.Reason,
+			BytesTransferred: inst.BytesTransferred,
+		})
+	}
+
+	err = query.CreateMany(ctx, tx, instances)
+	if err != nil {
+		return fmt.Errorf("Failed creating replicator run instances: %w", err)
+	}
+
+	_, err = query.DeleteMany[ReplicatorRunRow](ctx, tx, "WHERE replicator_id = ? AND id NOT IN (SELECT id FROM replicators_runs WHERE replicator_id = ? ORDER BY start_date DESC, id DESC LIMIT ?)", replicatorID, replicatorID, keepRuns)
+	if err != nil {
+		return fmt.Errorf("Failed pruning replicator runs: %w", err)
+	}
+
+	return nil
+}
+
+// GetReplicatorRuns returns the recorded runs of the replicator with the given ID, most recent first.
+func GetReplicatorRuns(ctx context.Context, tx *sql.Tx, replicatorID int64) ([]api.ReplicatorRun, error) {
+	runRows, err := query.Select[ReplicatorRunRow](ctx, tx, "WHERE replicators_runs.replicator_id = ? ORDER BY replicators_runs.start_date DESC, replicators_runs.id DESC", replicatorID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading replicator runs: %w", err)
+	}
+
+	instancesByRunID := make(map[int64][]api.ReplicatorRunInstance, len(runRows))
+	err = query.SelectFunc[ReplicatorRunInstanceRow](ctx, tx, "JOIN replicators_runs ON replicators_runs.id = replicators_runs_instances.replicator_run_id WHERE replicators_runs.replicator_id = ? ORDER BY replicators_runs_instances.instance_name", func(row ReplicatorRunInstanceRow) error {
+		instancesByRunID[row.ReplicatorRunID] = append(instancesByRunID[row.ReplicatorRunID], api.ReplicatorRunInstance{
+			Name:             row.InstanceName,
+			Status:           row.Status,
+			Reason:           row.Reason,
+			BytesTransferred: row.BytesTransferred,
+		})
+
+		return nil
+	}, replicatorID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading replicator run instances: %w", err)
+	}
+
+	runs := make([]api.ReplicatorRun, 0, len(runRows))
+	for _, row := range runRows {
+		instances := instancesByRunID[row.ID]
+		if instances == nil {
+			instances = []api.ReplicatorRunInstance{}
+		}
+
+		runs = append(runs, api.ReplicatorRun{
+			Action:           row.Action,
+			StartedAt:        row.StartDate,
+			FinishedAt:       row.EndDate,
+			Status:           row.Status,
+			BytesTransferred: row.BytesTransferred,
+			Instances:        instances,
+		})
+	}
+
+	return runs, nil
+}
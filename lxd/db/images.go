@@ -131,6 +131,19 @@ func (c *ClusterTx) GetCachedImageSourceFingerprint(ctx context.Context, server
 	return fingerprints[0], nil
 }
 
+// GetImageFingerprintsByProperty returns the fingerprints of the images, from any project, having the given
+// property set to the given value.
+func (c *ClusterTx) GetImageFingerprintsByProperty(ctx context.Context, key string, value string) ([]string, error) {
+	q := `SELECT DISTINCT images.fingerprint
+			FROM images_properties
+			INNER JOIN images
+			ON images_properties.image_id=images.id
+			WHERE images_properties.key=? AND images_properties.value=?
+`
+
+	return query.SelectStrings(ctx, c.tx, q, key, value)
+}
+
 // ImageExists returns whether an image with the given fingerprint exists.
 func (c *ClusterTx) ImageExists(ctx context.Context, project string, fingerprint string) (bool, error) {
 	table := "images JOIN projects ON projects.id = images.project_id"
@@ -69,6 +69,31 @@ func TestImageExists(t *testing.T) {
 	})
 }
 
+func TestGetImageFingerprintsByProperty(t *testing.T) {
+	cluster, cleanup := db.NewTestCluster(t)
+	defer cleanup()
+
+	_ = cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.CreateImage(ctx,
+			"default", "abc", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"oci.digest": "sha256:123"}, "container", nil)
+		require.NoError(t, err)
+
+		err = tx.CreateImage(ctx,
+			"default", "def", "x.gz", 16, false, false, "amd64", time.Now(), time.Now(), map[string]string{"oci.digest": "sha256:456"}, "container", nil)
+		require.NoError(t, err)
+
+		fingerprints, err := tx.GetImageFingerprintsByProperty(ctx, "oci.digest", "sha256:123")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"abc"}, fingerprints)
+
+		fingerprints, err = tx.GetImageFingerprintsByProperty(ctx, "oci.digest", "sha256:789")
+		require.NoError(t, err)
+		assert.Empty(t, fingerprints)
+
+		return nil
+	})
+}
+
 func TestGetImage(t *testing.T) {
 	dbCluster, cleanup := db.NewTestCluster(t)
 	defer cleanup()
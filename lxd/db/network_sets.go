@@ -0,0 +1,329 @@
+//go:build linux && cgo && !agent
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkSetType indicates the type of a network set.
+type NetworkSetType int
+
+// Network set types.
+const (
+	NetworkSetTypeAddress NetworkSetType = iota // Set of addresses (address set).
+	NetworkSetTypePort                          // Set of ports (port set).
+)
+
+// String returns the name of the network set type.
+func (t NetworkSetType) String() string {
+	switch t {
+	case NetworkSetTypeAddress:
+		return "address set"
+	case NetworkSetTypePort:
+		return "port set"
+	}
+
+	return fmt.Sprintf("unknown network set type %d", t)
+}
+
+// NetworkSet represents a network address set or port set.
+type NetworkSet struct {
+	ID          int64
+	Project     string
+	Name        string
+	Type        NetworkSetType
+	Description string
+	Entries     []string
+	Config      map[string]string
+}
+
+// GetNetworkSets returns the names of existing network sets of the given type in the project.
+func (c *ClusterTx) GetNetworkSets(ctx context.Context, projectName string, setType NetworkSetType) ([]string, error) {
+	q := `SELECT name FROM networks_sets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND type = ?
+		ORDER BY id
+	`
+
+	names, err := query.SelectStrings(ctx, c.tx, q, projectName, setType)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// GetNetworkSetsAllProjects returns the names of existing network sets of the given type keyed by project.
+func (c *ClusterTx) GetNetworkSetsAllProjects(ctx context.Context, setType NetworkSetType) (map[string][]string, error) {
+	q := `SELECT projects.name, networks_sets.name FROM networks_sets
+		JOIN projects ON projects.id=networks_sets.project_id
+		WHERE networks_sets.type = ?
+		ORDER BY networks_sets.id
+	`
+
+	setNames := map[string][]string{}
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var projectName string
+		var setName string
+
+		err := scan(&projectName, &setName)
+		if err != nil {
+			return err
+		}
+
+		setNames[projectName] = append(setNames[projectName], setName)
+
+		return nil
+	}, setType)
+	if err != nil {
+		return nil, err
+	}
+
+	return setNames, nil
+}
+
+// GetNetworkSetIDsByNames returns a map of names to IDs of existing network sets of the given type in the project.
+func (c *ClusterTx) GetNetworkSetIDsByNames(ctx context.Context, projectName string, setType NetworkSetType) (map[string]int64, error) {
+	q := `SELECT id, name FROM networks_sets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND type = ?
+		ORDER BY id
+	`
+
+	sets := make(map[string]int64)
+
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var setID int64
+		var setName string
+
+		err := scan(&setID, &setName)
+		if err != nil {
+			return err
+		}
+
+		sets[setName] = setID
+
+		return nil
+	}, projectName, setType)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// GetNetworkSet returns the network set of the given type with the given name in the given project.
+func (c *ClusterTx) GetNetworkSet(ctx context.Context, projectName string, setType NetworkSetType, name string) (*NetworkSet, error) {
+	var entriesJSON string
+
+	set := NetworkSet{
+		Project: projectName,
+		Name:    name,
+		Type:    setType,
+	}
+
+	q := `
+		SELECT id, description, entries
+		FROM networks_sets
+		WHERE project_id = (SELECT id FROM projects WHERE name = ? LIMIT 1) AND type = ? AND name = ?
+		LIMIT 1
+	`
+
+	err := c.tx.QueryRowContext(ctx, q, projectName, setType, name).Scan(&set.ID, &set.Description, &entriesJSON)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Network %s not found", setType)
+		}
+
+		return nil, err
+	}
+
+	set.Entries = []string{}
+	if entriesJSON != "" {
+		err = json.Unmarshal([]byte(entriesJSON), &set.Entries)
+		if err != nil {
+			return nil, fmt.Errorf("Failed unmarshalling %s entries: %w", setType, err)
+		}
+	}
+
+	set.Config, err = networkSetConfig(ctx, c, set.ID)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading config: %w", err)
+	}
+
+	return &set, nil
+}
+
+// GetNetworkSetsWithType returns all network sets of the given type across all projects.
+func (c *ClusterTx) GetNetworkSetsWithType(ctx context.Context, setType NetworkSetType) ([]NetworkSet, error) {
+	q := `SELECT projects.name, networks_sets.name FROM networks_sets
+		JOIN projects ON projects.id=networks_sets.project_id
+		WHERE networks_sets.type = ?
+		ORDER BY networks_sets.id
+	`
+
+	type setKey struct {
+		projectName string
+		name        string
+	}
+
+	var keys []setKey
+	err := query.Scan(ctx, c.tx, q, func(scan func(dest ...any) error) error {
+		var key setKey
+
+		err := scan(&key.projectName, &key.name)
+		if err != nil {
+			return err
+		}
+
+		keys = append(keys, key)
+
+		return nil
+	}, setType)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]NetworkSet, 0, len(keys))
+	for _, key := range keys {
+		set, err := c.GetNetworkSet(ctx, key.projectName, setType, key.name)
+		if err != nil {
+			return nil, err
+		}
+
+		sets = append(sets, *set)
+	}
+
+	return sets, nil
+}
+
+// networkSetConfig returns the config map of the network set with the given ID.
+func networkSetConfig(ctx context.Context, tx *ClusterTx, id int64) (map[string]string, error) {
+	q := `
+		SELECT key, value
+		FROM networks_sets_config
+		WHERE network_set_id=?
+	`
+
+	config := make(map[string]string)
+	err := query.Scan(ctx, tx.Tx(), q, func(scan func(dest ...any) error) error {
+		var key, value string
+
+		err := scan(&key, &value)
+		if err != nil {
+			return err
+		}
+
+		_, found := config[key]
+		if found {
+			return fmt.Errorf("Duplicate config row found for key %q for network set ID %d", key, id)
+		}
+
+		config[key] = value
+
+		return nil
+	}, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// CreateNetworkSet creates a new network set.
+func (c *ClusterTx) CreateNetworkSet(ctx context.Context, set NetworkSet) (int64, error) {
+	entriesJSON, err := json.Marshal(set.Entries)
+	if err != nil {
+		return -1, fmt.Errorf("Failed marshalling %s entries: %w", set.Type, err)
+	}
+
+	// Insert a new network set record.
+	result, err := c.tx.ExecContext(ctx, `
+			INSERT INTO networks_sets (project_id, name, type, description, entries)
+			VALUES ((SELECT id FROM projects WHERE name = ? LIMIT 1), ?, ?, ?, ?)
+		`, set.Project, set.Name, set.Type, set.Description, string(entriesJSON))
+	if err != nil {
+		return -1, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return -1, err
+	}
+
+	err = networkSetConfigAdd(c.tx, id, set.Config)
+	if err != nil {
+		return -1, err
+	}
+
+	return id, nil
+}
+
+// networkSetConfigAdd inserts network set config keys.
+func networkSetConfigAdd(tx *sql.Tx, id int64, config map[string]string) error {
+	sql := "INSERT INTO networks_sets_config (network_set_id, key, value) VALUES(?, ?, ?)"
+	stmt, err := tx.Prepare(sql)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = stmt.Close() }()
+
+	for k, v := range config {
+		if v == "" {
+			continue
+		}
+
+		_, err = stmt.Exec(id, k, v)
+		if err != nil {
+			return fmt.Errorf("Failed inserting config: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// UpdateNetworkSet updates the description, entries and config of the network set with the given ID.
+func (c *ClusterTx) UpdateNetworkSet(ctx context.Context, id int64, description string, entries []string, config map[string]string) error {
+	entriesJSON, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("Failed marshalling entries: %w", err)
+	}
+
+	_, err = c.tx.ExecContext(ctx, "UPDATE networks_sets SET description=?, entries=? WHERE id=?", description, string(entriesJSON), id)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.tx.ExecContext(ctx, "DELETE FROM networks_sets_config WHERE network_set_id=?", id)
+	if err != nil {
+		return err
+	}
+
+	err = networkSetConfigAdd(c.tx, id, config)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RenameNetworkSet renames a network set.
+func (c *ClusterTx) RenameNetworkSet(ctx context.Context, id int64, newName string) error {
+	_, err := c.tx.ExecContext(ctx, "UPDATE networks_sets SET name=? WHERE id=?", newName, id)
+
+	return err
+}
+
+// DeleteNetworkSet deletes the network set.
+func (c *ClusterTx) DeleteNetworkSet(ctx context.Context, id int64) error {
+	_, err := c.tx.ExecContext(ctx, "DELETE FROM networks_sets WHERE id=?", id)
+
+	return err
+}
@@ -643,6 +643,7 @@ const (
 	NetworkTypeSriov                       // Network type sriov.
 	NetworkTypeOVN                         // Network type ovn.
 	NetworkTypePhysical                    // Network type physical.
+	NetworkTypeEVPN                        // Network type evpn.
 )
 
 // NetworkNode represents a network node.
@@ -770,6 +771,8 @@ func networkFillType(network *api.Network, netType NetworkType) {
 		network.Type = "ovn"
 	case NetworkTypePhysical:
 		network.Type = "physical"
+	case NetworkTypeEVPN:
+		network.Type = "evpn"
 	default:
 		network.Type = "" // Unknown
 	}
@@ -996,4 +999,5 @@ var NodeSpecificNetworkConfig = []string{
 	"bridge.external_interfaces",
 	"parent",
 	"acceleration.parent",
+	"evpn.local",
 }
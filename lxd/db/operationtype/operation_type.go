@@ -145,6 +145,17 @@ const (
 	ReplicatorRun
 	ReplicatorRunInstance
 	ProjectReplicaModeUpdate
+	ClusterRebalance
+	NetworkAddressSetCreate
+	NetworkAddressSetUpdate
+	NetworkAddressSetDelete
+	NetworkAddressSetRename
+	NetworkPortSetCreate
+	NetworkPortSetUpdate
+	NetworkPortSetDelete
+	NetworkPortSetRename
+	BackupsCreateScheduled
+	ImagesExportStreams
 
 	// upperBound is used only to enforce consistency in the package on init.
 	// Make sure it's always the last item in this list.
@@ -396,6 +407,28 @@ func (t Type) Description() string {
 		return "Replicating instance"
 	case ProjectReplicaModeUpdate:
 		return "Updating project replica mode"
+	case ClusterRebalance:
+		return "Rebalancing cluster instances"
+	case NetworkAddressSetCreate:
+		return "Creating network address set"
+	case NetworkAddressSetUpdate:
+		return "Updating network address set"
+	case NetworkAddressSetDelete:
+		return "Deleting network address set"
+	case NetworkAddressSetRename:
+		return "Renaming network address set"
+	case NetworkPortSetCreate:
+		return "Creating network port set"
+	case NetworkPortSetUpdate:
+		return "Updating network port set"
+	case NetworkPortSetDelete:
+		return "Deleting network port set"
+	case NetworkPortSetRename:
+		return "Renaming network port set"
+	case BackupsCreateScheduled:
+		return "Creating scheduled backups"
+	case ImagesExportStreams:
+		return "Exporting images to a simplestreams tree"
 
 	// It should never be possible to reach the default clause.
 	// See the init function.
@@ -414,7 +447,7 @@ func (t Type) EntityType() entity.Type {
 		BackupsExpire, SnapshotsExpire, ClusterJoinToken, CertificateAddToken, RenewServerCertificate,
 		ClusterHeal, ImagesUpdate, VolumeSnapshotsCreateScheduled, SnapshotsCreateScheduled,
 		PruneExpiredOperations, RefreshClusterLinkVolatileAddresses,
-		StoragePoolCreate, Wait:
+		StoragePoolCreate, Wait, ClusterRebalance, BackupsCreateScheduled, ImagesExportStreams:
 		return entity.TypeServer
 
 	// Project level operations.
@@ -425,6 +458,12 @@ func (t Type) EntityType() entity.Type {
 		NetworkZoneCreate, ReplicatorRunInstance, ProjectReplicaModeUpdate:
 		return entity.TypeProject
 
+	// Network address set and port set operations.
+	// Sets have no entity type of their own, so the project they belong to is the primary entity.
+	case NetworkAddressSetCreate, NetworkAddressSetUpdate, NetworkAddressSetDelete, NetworkAddressSetRename,
+		NetworkPortSetCreate, NetworkPortSetUpdate, NetworkPortSetDelete, NetworkPortSetRename:
+		return entity.TypeProject
+
 	// Storage bucket operations.
 	case StorageBucketUpdate, StorageBucketDelete, StorageBucketKeyCreate, StorageBucketKeyUpdate, StorageBucketKeyDelete:
 		return entity.TypeStorageBucket
@@ -528,6 +567,8 @@ func (t Type) ConflictAction() ConflictAction {
 		return ConflictActionFail
 	case ClusterMemberEvacuate:
 		return ConflictActionFail // Enforces cluster-wide evacuation exclusivity when used with a shared ConflictReference; this prevents evacuation race conditions.
+	case ClusterRebalance:
+		return ConflictActionFail // Shares the evacuation conflict reference so that instances aren't rebalanced during an evacuation.
 	case ReplicatorRun:
 		return ConflictActionFail // Prevents concurrent runs of the same replicator; the replicator URL is used as the per-replicator conflict reference.
 	}
@@ -280,16 +280,23 @@ func (c *ClusterTx) GetStoragePoolLocalBucketByAccessKey(ctx context.Context, ac
 }
 
 // CreateStoragePoolBucket creates a new Storage Bucket.
-func (c *ClusterTx) CreateStoragePoolBucket(ctx context.Context, poolID int64, projectName string, info api.StorageBucketsPost) (int64, error) {
+// If memberSpecific is true, then the bucket is associated to the current member, rather than being associated to
+// all members.
+func (c *ClusterTx) CreateStoragePoolBucket(ctx context.Context, poolID int64, projectName string, memberSpecific bool, info api.StorageBucketsPost) (int64, error) {
 	var err error
 	var bucketID int64
+	var nodeID any
+
+	if memberSpecific {
+		nodeID = c.nodeID
+	}
 
 	// Insert a new Storage Bucket record.
 	result, err := c.tx.ExecContext(ctx, `
 		INSERT INTO storage_buckets
 		(storage_pool_id, node_id, name, description, project_id)
 		VALUES (?, ?, ?, ?, (SELECT id FROM projects WHERE name = ?))
-		`, poolID, nil, info.Name, info.Description, projectName)
+		`, poolID, nodeID, info.Name, info.Description, projectName)
 	if err != nil {
 		if query.IsConflictErr(err) {
 			return -1, api.StatusErrorf(http.StatusConflict, "A bucket for that name already exists")
@@ -399,7 +406,8 @@ func (c *ClusterTx) DeleteStoragePoolBucket(ctx context.Context, poolID int64, b
 
 // StorageBucketKeyFilter used for filtering storage bucket keys with GetStoragePoolBucketKeys().
 type StorageBucketKeyFilter struct {
-	Name *string
+	Name      *string
+	AccessKey *string
 }
 
 // StorageBucketKey represents a database storage bucket key record.
@@ -439,6 +447,11 @@ func (c *ClusterTx) GetStoragePoolBucketKeys(ctx context.Context, bucketID int64
 				args = append(args, *filter.Name)
 			}
 
+			if filter.AccessKey != nil {
+				qFilters = append(qFilters, "storage_buckets_keys.access_key = ?")
+				args = append(args, *filter.AccessKey)
+			}
+
 			if qFilters == nil {
 				return nil, errors.New("Invalid storage bucket key filter")
 			}
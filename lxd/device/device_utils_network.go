@@ -22,8 +22,10 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/network/acl"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/units"
@@ -306,8 +308,9 @@ func networkCreateVethPair(hostName string, m deviceConfig.Device) (string, uint
 }
 
 // networkCreateTap creates and configures a TAP device.
+// If multiQueue is true the TAP device is created with multi-queue support.
 // Returns the MTU used.
-func networkCreateTap(hostName string, m deviceConfig.Device) (uint32, error) {
+func networkCreateTap(hostName string, m deviceConfig.Device, multiQueue bool) (uint32, error) {
 	hostMTU, instanceMTU, err := networkCalculatePairMTU(m)
 	if err != nil {
 		return 0, err
@@ -316,7 +319,7 @@ func networkCreateTap(hostName string, m deviceConfig.Device) (uint32, error) {
 	tuntap := &ip.Tuntap{
 		Name:       hostName,
 		Mode:       "tap",
-		MultiQueue: true,
+		MultiQueue: multiQueue,
 	}
 
 	err = tuntap.Add()
@@ -598,6 +601,79 @@ func networkValidGateway(value string) error {
 	return fmt.Errorf("Invalid gateway: %s", value)
 }
 
+// networkLeaseWaitTimeout is how long to wait for a NIC to get a DHCP lease before refreshing the address sets.
+const networkLeaseWaitTimeout = time.Minute
+
+// networkRefreshAddressSets triggers a refresh of the network address sets after the addresses of a NIC may have
+// changed. When waitLease is true and the NIC gets its IPv4 address from the DHCP server of a managed bridge,
+// another refresh is triggered once the lease shows up, so that address sets include the dynamic address.
+func networkRefreshAddressSets(d *deviceCommon, n network.Network, config map[string]string, waitLease bool) {
+	acl.TriggerAddressSetsRefresh(d.state)
+
+	if !waitLease || n == nil || n.Type() != "bridge" || config["ipv4.address"] != "" {
+		return
+	}
+
+	hwaddr := config["hwaddr"]
+	if hwaddr == "" {
+		hwaddr = d.volatileGet()["hwaddr"]
+	}
+
+	mac, err := net.ParseMAC(hwaddr)
+	if err != nil {
+		return
+	}
+
+	leaseFile := shared.VarPath("networks", n.Name(), "dnsmasq.leases")
+	hasLease := func() bool {
+		content, err := os.ReadFile(leaseFile)
+		return err == nil && strings.Contains(string(content), " "+mac.String()+" ")
+	}
+
+	// A lease that already exists is renewed with the same address.
+	if hasLease() {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(d.state.ShutdownCtx, networkLeaseWaitTimeout)
+		defer cancel()
+
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if hasLease() {
+					acl.TriggerAddressSetsRefresh(d.state)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// evpnNetwork is implemented by networks distributing the MAC addresses of the connected NICs using BGP EVPN.
+type evpnNetwork interface {
+	AdvertiseMAC(owner string, hwaddr net.HardwareAddr, ips []net.IP) error
+}
+
+// networkEVPNAddresses returns the static IP addresses of the NIC which are advertised along with its MAC address.
+func networkEVPNAddresses(config map[string]string) []net.IP {
+	ips := []net.IP{}
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		addr := net.ParseIP(config[key])
+		if addr != nil {
+			ips = append(ips, addr)
+		}
+	}
+
+	return ips
+}
+
 // bgpAddPrefix adds external routes to the BGP server.
 func bgpAddPrefix(d *deviceCommon, n network.Network, config map[string]string) error {
 	// BGP is only valid when tied to a managed network.
@@ -652,6 +728,25 @@ func bgpAddPrefix(d *deviceCommon, n network.Network, config map[string]string)
 		}
 	}
 
+	// Advertise the MAC address of the NIC on EVPN networks, along with its static IP addresses.
+	evpnNet, ok := n.(evpnNetwork)
+	if ok {
+		hwaddr := config["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = d.volatileGet()["hwaddr"]
+		}
+
+		mac, err := net.ParseMAC(hwaddr)
+		if err != nil {
+			return fmt.Errorf("Failed parsing MAC address %q: %w", hwaddr, err)
+		}
+
+		err = evpnNet.AdvertiseMAC(bgpOwner, mac, networkEVPNAddresses(config))
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -662,7 +757,13 @@ func bgpRemovePrefix(d *deviceCommon, config map[string]string) error {
 	}
 
 	// Load the network configuration.
-	err := d.state.BGP.RemovePrefixByOwner(fmt.Sprint("instance_", d.inst.ID(), "_", d.name))
+	bgpOwner := fmt.Sprint("instance_", d.inst.ID(), "_", d.name)
+	err := d.state.BGP.RemovePrefixByOwner(bgpOwner)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.BGP.RemoveEVPNRoutesByOwner(bgpOwner)
 	if err != nil {
 		return err
 	}
@@ -670,6 +771,64 @@ func bgpRemovePrefix(d *deviceCommon, config map[string]string) error {
 	return nil
 }
 
+// networkQoSAttach classifies the traffic of the NIC into its QoS class on the managed network.
+func networkQoSAttach(d *deviceCommon, n network.Network, config map[string]string) error {
+	// QoS classes are only valid when tied to a managed network.
+	if n == nil || config["network"] == "" || config["qos.class"] == "" {
+		return nil
+	}
+
+	hwaddr := config["hwaddr"]
+	if hwaddr == "" {
+		hwaddr = d.volatileGet()["hwaddr"]
+	}
+
+	mac, err := net.ParseMAC(hwaddr)
+	if err != nil {
+		return fmt.Errorf("Failed parsing MAC address %q: %w", hwaddr, err)
+	}
+
+	err = network.QoSAttachNIC(n, config["qos.class"], mac)
+	if err != nil {
+		return fmt.Errorf("Failed applying QoS class %q: %w", config["qos.class"], err)
+	}
+
+	return nil
+}
+
+// networkQoSDetach removes the NIC from its QoS class on the managed network.
+func networkQoSDetach(d *deviceCommon, n network.Network, config map[string]string) {
+	if n == nil || config["network"] == "" || config["qos.class"] == "" {
+		return
+	}
+
+	hwaddr := config["hwaddr"]
+	if hwaddr == "" {
+		hwaddr = d.volatileGet()["hwaddr"]
+	}
+
+	mac, err := net.ParseMAC(hwaddr)
+	if err != nil {
+		return
+	}
+
+	network.QoSDetachNIC(n, mac)
+}
+
+// networkQoSState returns the QoS class state of the NIC, or nil if not in a QoS class.
+func networkQoSState(n network.Network, config map[string]string) *api.InstanceStateNetworkQoS {
+	if n == nil || config["network"] == "" || config["qos.class"] == "" {
+		return nil
+	}
+
+	qos, err := network.QoSClassState(n, config["qos.class"])
+	if err != nil {
+		return nil
+	}
+
+	return qos
+}
+
 // networkSRIOVParentVFInfo returns info about an SR-IOV virtual function from the parent NIC using the ip tool.
 func networkSRIOVParentVFInfo(vfParent string, vfID int) (ip.VirtFuncInfo, error) {
 	link := &ip.Link{Name: vfParent}
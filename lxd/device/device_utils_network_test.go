@@ -1,6 +1,7 @@
 package device
 
 import (
+	"net"
 	"strconv"
 	"testing"
 
@@ -112,3 +113,33 @@ func TestNetworkCalculatePairMTUWithParent(t *testing.T) {
 		})
 	}
 }
+
+func TestNetworkEVPNAddresses(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]string
+		want   []net.IP
+	}{
+		{
+			name:   "No static addresses",
+			config: map[string]string{},
+			want:   []net.IP{},
+		},
+		{
+			name:   "IPv4 and IPv6 addresses",
+			config: map[string]string{"ipv4.address": "192.0.2.10", "ipv6.address": "2001:db8::10"},
+			want:   []net.IP{net.ParseIP("192.0.2.10"), net.ParseIP("2001:db8::10")},
+		},
+		{
+			name:   "Disabled IPv4 address",
+			config: map[string]string{"ipv4.address": "none", "ipv6.address": "2001:db8::10"},
+			want:   []net.IP{net.ParseIP("2001:db8::10")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, networkEVPNAddresses(tt.config))
+		})
+	}
+}
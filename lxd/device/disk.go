@@ -203,10 +203,15 @@ func (d *disk) checkBlockVolSharing(instanceType instancetype.Type, projectName
 
 // validateConfig checks the supplied config for correctness.
 func (d *disk) validateConfig(instConf instance.ConfigReader) error {
-	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM, instancetype.Krun) {
 		return ErrUnsupportedDevType
 	}
 
+	// Krun instances boot from their root disk and don't support additional disks.
+	if instConf.Type() == instancetype.Krun && !filters.IsRootDisk(d.config) {
+		return errors.New("Only the root disk device is supported for krun instances")
+	}
+
 	// Supported propagation types.
 	// If an empty value is supplied the default behavior is to assume "private" mode.
 	// These come from https://www.kernel.org/doc/Documentation/filesystems/sharedsubtree.txt
@@ -283,6 +283,15 @@ func nicValidationRules(requiredFields []string, optionalFields []string, instCo
 		//  type: integer
 		//  shortdesc: `skb->priority` value for outgoing traffic
 		"limits.priority": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=device-nic-{bridged+macvlan}; group=device-conf; key=qos.class)
+		// The class must be defined in the `qos.classes.NAME.*` options of the network.
+		// The traffic of the NIC is shaped in both directions according to the class.
+		// See {ref}`network-qos` for more information.
+		// ---
+		//  type: string
+		//  managed: no
+		//  shortdesc: QoS class of the network to use for the NIC
+		"qos.class": validate.IsAny,
 		// lxdmeta:generate(entities=device-nic-{bridged+sriov}; group=device-conf; key=security.mac_filtering)
 		// Set this option to `true` to prevent the instance from spoofing another instance’s MAC address.
 		// ---
@@ -61,7 +61,7 @@ func (d *nicBridged) CanMigrate() bool {
 
 // validateConfig checks the supplied config for correctness.
 func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
-	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM) {
+	if !instanceSupported(instConf.Type(), instancetype.Container, instancetype.VM, instancetype.Krun) {
 		return ErrUnsupportedDevType
 	}
 
@@ -90,6 +90,7 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		"security.port_isolation",
 		"boot.priority",
 		"vlan",
+		"qos.class",
 	}
 
 	// checkWithManagedNetwork validates the device's settings against the managed network.
@@ -98,8 +99,15 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 			return errors.New("Specified network is not fully created")
 		}
 
-		if n.Type() != "bridge" {
-			return errors.New("Specified network must be of type bridge")
+		if !slices.Contains([]string{"bridge", "evpn"}, n.Type()) {
+			return errors.New("Specified network must be of type bridge or evpn")
+		}
+
+		if d.config["qos.class"] != "" {
+			_, err := network.QoSClassState(n, d.config["qos.class"])
+			if err != nil {
+				return fmt.Errorf("Invalid QoS class: %w", err)
+			}
 		}
 
 		netConfig := n.Config()
@@ -243,6 +251,10 @@ func (d *nicBridged) validateConfig(instConf instance.ConfigReader) error {
 		// If no network property supplied, then parent property is required.
 		requiredFields = append(requiredFields, "parent")
 
+		if d.config["qos.class"] != "" {
+			return fmt.Errorf("Cannot use %q property without %q property", "qos.class", "network")
+		}
+
 		// Check if parent is a managed network.
 		// api.ProjectDefaultName is used here as bridge networks don't support projects.
 		d.network, _ = network.LoadByName(d.state, api.ProjectDefaultName, d.config["parent"])
@@ -458,7 +470,7 @@ func (d *nicBridged) UpdatableFields(oldDevice Type) []string {
 		return []string{}
 	}
 
-	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering"}
+	return []string{"limits.ingress", "limits.egress", "limits.max", "limits.priority", "ipv4.routes", "ipv6.routes", "ipv4.routes.external", "ipv6.routes.external", "ipv4.address", "ipv6.address", "security.mac_filtering", "security.ipv4_filtering", "security.ipv6_filtering", "qos.class"}
 }
 
 // Add is run when a device is added to a non-snapshot instance whether or not the instance is running.
@@ -516,7 +528,7 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 			}
 		}
 		peerName, mtu, err = networkCreateVethPair(saveData["host_name"], d.config)
-	case instancetype.VM:
+	case instancetype.VM, instancetype.Krun:
 		if saveData["host_name"] == "" {
 			saveData["host_name"], err = d.generateHostName("tap", d.config["hwaddr"])
 			if err != nil {
@@ -524,7 +536,9 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 			}
 		}
 		peerName = saveData["host_name"] // VMs use the host_name to link to the TAP FD.
-		mtu, err = networkCreateTap(saveData["host_name"], d.config)
+
+		// libkrun opens the TAP device by name itself and only supports a single queue.
+		mtu, err = networkCreateTap(saveData["host_name"], d.config, instType == instancetype.VM)
 	}
 
 	if err != nil {
@@ -673,7 +687,7 @@ func (d *nicBridged) Start() (*deviceConfig.RunConfig, error) {
 		{Key: "hwaddr", Value: d.config["hwaddr"]},
 	}
 
-	if d.inst.Type() == instancetype.VM {
+	if d.inst.Type() == instancetype.VM || d.inst.Type() == instancetype.Krun {
 		runConf.NetworkInterface = append(runConf.NetworkInterface,
 			[]deviceConfig.RunConfigItem{
 				{Key: "devName", Value: d.name},
@@ -692,6 +706,14 @@ func (d *nicBridged) postStart() error {
 		return err
 	}
 
+	// Refresh the address sets using the instance's addresses.
+	networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, true)
+
+	err = networkQoSAttach(&d.deviceCommon, d.network, d.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -800,6 +822,21 @@ func (d *nicBridged) Update(oldDevices deviceConfig.Devices, isRunning bool) err
 		return err
 	}
 
+	// If an address changed, refresh the address sets using the instance's addresses.
+	if d.config["ipv4.address"] != oldConfig["ipv4.address"] || d.config["ipv6.address"] != oldConfig["ipv6.address"] {
+		networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, false)
+	}
+
+	// If the QoS class changed, move the NIC to the new class.
+	if isRunning && d.config["qos.class"] != oldConfig["qos.class"] {
+		networkQoSDetach(&d.deviceCommon, d.network, oldConfig)
+
+		err = networkQoSAttach(&d.deviceCommon, d.network, d.config)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 	return nil
 }
@@ -812,6 +849,12 @@ func (d *nicBridged) Stop() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Refresh the address sets using the instance's addresses.
+	networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, false)
+
+	// Remove from QoS class.
+	networkQoSDetach(&d.deviceCommon, d.network, d.config)
+
 	// Populate device config with volatile fields (hwaddr and host_name) if needed.
 	networkVethFillFromVolatile(d.config, d.volatileGet())
 
@@ -1772,6 +1815,7 @@ func (d *nicBridged) State() (*api.InstanceStateNetwork, error) {
 		Mtu:      mtu,
 		State:    "up",
 		Type:     "broadcast",
+		QoS:      networkQoSState(d.network, d.config),
 	}
 
 	return &network, nil
@@ -1799,5 +1843,10 @@ func (d *nicBridged) Register() error {
 		return err
 	}
 
+	err = networkQoSAttach(&d.deviceCommon, d.network, d.config)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
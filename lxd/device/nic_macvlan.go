@@ -51,6 +51,7 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 		"vlan",
 		"boot.priority",
 		"gvrp",
+		"qos.class",
 	}
 
 	// Check that if network property is set that conflicting keys are not present.
@@ -81,6 +82,13 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 			return errors.New("Specified network must be of type macvlan")
 		}
 
+		if d.config["qos.class"] != "" {
+			_, err := network.QoSClassState(d.network, d.config["qos.class"])
+			if err != nil {
+				return fmt.Errorf("Invalid QoS class: %w", err)
+			}
+		}
+
 		netConfig := d.network.Config()
 
 		// Get actual parent device from network's parent setting.
@@ -96,6 +104,10 @@ func (d *nicMACVLAN) validateConfig(instConf instance.ConfigReader) error {
 	} else {
 		// If no network property supplied, then parent property is required.
 		requiredFields = append(requiredFields, "parent")
+
+		if d.config["qos.class"] != "" {
+			return fmt.Errorf("Cannot use %q property without %q property", "qos.class", "network")
+		}
 	}
 
 	err := d.config.Validate(nicValidationRules(requiredFields, optionalFields, instConf))
@@ -229,6 +241,14 @@ func (d *nicMACVLAN) Start() (*deviceConfig.RunConfig, error) {
 
 	revert.Add(func() { _ = network.InterfaceRemove(saveData["host_name"]) })
 
+	// Classify the traffic of the NIC into its QoS class.
+	err = networkQoSAttach(&d.deviceCommon, d.network, d.config)
+	if err != nil {
+		return nil, err
+	}
+
+	revert.Add(func() { networkQoSDetach(&d.deviceCommon, d.network, d.config) })
+
 	if d.inst.Type() == instancetype.VM {
 		// Disable IPv6 on host interface to avoid getting IPv6 link-local addresses unnecessarily.
 		err = util.SysctlSet(fmt.Sprintf("net/ipv6/conf/%s/disable_ipv6", link.Name), "1")
@@ -290,6 +310,9 @@ func (d *nicMACVLAN) postStop() error {
 	errs := []error{}
 	v := d.volatileGet()
 
+	// Remove from QoS class.
+	networkQoSDetach(&d.deviceCommon, d.network, d.config)
+
 	// Delete the detached device.
 	if v["host_name"] != "" && shared.PathExists("/sys/class/net/"+v["host_name"]) {
 		err := network.InterfaceRemove(v["host_name"])
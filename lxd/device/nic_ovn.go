@@ -590,7 +590,7 @@ func (d *nicOVN) Start() (*deviceConfig.RunConfig, error) {
 
 				integrationBridgeNICName = saveData["host_name"]
 				peerName = saveData["host_name"] // VMs use the host_name to link to the TAP FD.
-				mtu, err = networkCreateTap(saveData["host_name"], d.config)
+				mtu, err = networkCreateTap(saveData["host_name"], d.config, true)
 				if err != nil {
 					return nil, err
 				}
@@ -853,6 +853,9 @@ func (d *nicOVN) postStart() error {
 		return err
 	}
 
+	// Refresh the address sets using the instance's addresses.
+	networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, true)
+
 	return nil
 }
 
@@ -978,6 +981,11 @@ func (d *nicOVN) Update(oldDevices deviceConfig.Devices, isRunning bool) error {
 		return err
 	}
 
+	// If an address changed, refresh the address sets using the instance's addresses.
+	if d.config["ipv4.address"] != oldConfig["ipv4.address"] || d.config["ipv6.address"] != oldConfig["ipv6.address"] {
+		networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, false)
+	}
+
 	return nil
 }
 
@@ -1049,6 +1057,9 @@ func (d *nicOVN) Stop() (*deviceConfig.RunConfig, error) {
 		return nil, err
 	}
 
+	// Refresh the address sets using the instance's addresses.
+	networkRefreshAddressSets(&d.deviceCommon, d.network, d.config, false)
+
 	return &runConf, nil
 }
 
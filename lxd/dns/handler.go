@@ -9,13 +9,30 @@ import (
 
 	"github.com/miekg/dns"
 
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 )
 
+// maxCNAMEChain is the maximum number of CNAME records followed when answering a query.
+const maxCNAMEChain = 8
+
+// zoneCacheSerialWindow is the number of seconds for which the records of a zone are reused to answer queries.
+// Zone serials are generation timestamps, so a zone is regenerated once its current serial is past the window.
+const zoneCacheSerialWindow = 10
+
+// cachedZone holds the parsed records of a zone along with the serial they were generated with.
+type cachedZone struct {
+	serial  uint32
+	records []dns.RR
+}
+
 type dnsHandler struct {
 	server *Server
 	mu     sync.Mutex
+
+	// zones caches the records of the zones queried recently, indexed by zone name.
+	zones map[string]cachedZone
 }
 
 // writeRcode sends a DNS response with the given response code.
@@ -46,27 +63,33 @@ func (d *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
-	// Check that it's a supported request type.
-	if r.Question[0].Qtype != dns.TypeAXFR && r.Question[0].Qtype != dns.TypeIXFR && r.Question[0].Qtype != dns.TypeSOA {
-		writeRcode(w, r, dns.RcodeNotImplemented)
-		return
-	}
-
 	// Extract the request information.
-	name := strings.TrimSuffix(r.Question[0].Name, ".")
+	name := strings.ToLower(strings.TrimSuffix(r.Question[0].Name, "."))
 	ip, _, err := net.SplitHostPort(w.RemoteAddr().String())
 	if err != nil {
 		writeRcode(w, r, dns.RcodeServerFailure)
 		return
 	}
 
+	switch r.Question[0].Qtype {
+	case dns.TypeAXFR, dns.TypeIXFR:
+		d.serveTransfer(w, r, name, ip)
+	case dns.TypeANY:
+		writeRcode(w, r, dns.RcodeNotImplemented)
+	default:
+		d.serveQuery(w, r, name, ip)
+	}
+}
+
+// serveTransfer handles a zone transfer request from a zone peer.
+func (d *dnsHandler) serveTransfer(w dns.ResponseWriter, r *dns.Msg, name string, ip string) {
 	// Prepare the response.
 	m := new(dns.Msg)
 	m.SetReply(r)
 	m.Authoritative = true
 
 	// Load the zone.
-	zone, err := d.server.zoneRetriever(name, r.Question[0].Qtype != dns.TypeSOA)
+	zone, err := d.server.zoneRetriever(name, true)
 	if err != nil {
 		// On failure, return NXDOMAIN.
 		writeRcode(w, r, dns.RcodeNameError)
@@ -83,31 +106,249 @@ func (d *dnsHandler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		return
 	}
 
+	records, err := parseZone(zone)
+	if err != nil {
+		logger.Errorf("Bad DNS record in zone %q: %v", name, err)
+		writeRcode(w, r, dns.RcodeFormatError)
+		return
+	}
+
+	m.Answer = records
+
+	if tsig != nil && tsigOK {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	err = w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Cannot write message", logger.Ctx{"err": err})
+	}
+}
+
+// serveQuery answers a regular query from the records of the zone the name belongs to.
+func (d *dnsHandler) serveQuery(w dns.ResponseWriter, r *dns.Msg, name string, ip string) {
+	question := r.Question[0]
+
+	// Find the zone the name belongs to.
+	zone := d.findZone(name)
+	if zone == nil {
+		writeRcode(w, r, dns.RcodeNameError)
+		return
+	}
+
+	tsig := r.IsTsig()
+	tsigOK := w.TsigStatus() == nil
+
+	// Check access.
+	if !d.isAllowed(zone.Info, ip, tsig, tsigOK) && !d.isClientAllowed(zone.Info, ip) {
+		// On auth failure, return NXDOMAIN to avoid information leaks.
+		writeRcode(w, r, dns.RcodeNameError)
+		return
+	}
+
+	records, err := parseZone(zone)
+	if err != nil {
+		logger.Errorf("Bad DNS record in zone %q: %v", zone.Info.Name, err)
+		writeRcode(w, r, dns.RcodeServerFailure)
+		return
+	}
+
+	// Use the full zone content unless only the SOA record of the zone was requested.
+	if question.Qtype != dns.TypeSOA || name != zone.Info.Name {
+		records, err = d.zoneRecords(zone.Info.Name, zoneSerial(records))
+		if err != nil {
+			logger.Errorf("Failed loading DNS zone %q: %v", zone.Info.Name, err)
+			writeRcode(w, r, dns.RcodeServerFailure)
+			return
+		}
+	}
+
+	// Prepare the response.
+	m := new(dns.Msg)
+	m.SetReply(r)
+	m.Authoritative = true
+
+	answers, found := lookupRecords(records, question.Name, question.Qtype)
+	if len(answers) > 0 {
+		m.Answer = answers
+	} else {
+		if !found {
+			m.Rcode = dns.RcodeNameError
+		}
+
+		// Include the SOA record so that the negative answer can be cached.
+		for _, rr := range records {
+			if rr.Header().Rrtype == dns.TypeSOA {
+				m.Ns = append(m.Ns, rr)
+				break
+			}
+		}
+	}
+
+	// Truncate UDP responses that don't fit in the client buffer.
+	if w.LocalAddr().Network() == "udp" {
+		size := dns.MinMsgSize
+
+		opt := r.IsEdns0()
+		if opt != nil {
+			size = int(opt.UDPSize())
+		}
+
+		m.Truncate(size)
+	}
+
+	if tsig != nil && tsigOK {
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+
+	err = w.WriteMsg(m)
+	if err != nil {
+		logger.Error("Cannot write message", logger.Ctx{"err": err})
+	}
+}
+
+// zoneRecords returns the records of the full zone content.
+// The records are cached per serial so that the zone is only regenerated once the current serial of the zone is
+// past the cache window of the serial the cached records were generated with.
+func (d *dnsHandler) zoneRecords(name string, serial uint32) ([]dns.RR, error) {
+	cached, ok := d.zones[name]
+	if ok && serial >= cached.serial && serial-cached.serial < zoneCacheSerialWindow {
+		return cached.records, nil
+	}
+
+	zone, err := d.server.zoneRetriever(name, true)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := parseZone(zone)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.zones == nil {
+		d.zones = map[string]cachedZone{}
+	}
+
+	d.zones[name] = cachedZone{serial: zoneSerial(records), records: records}
+
+	return records, nil
+}
+
+// zoneSerial returns the serial of the SOA record among the records, or 0 if there is none.
+func zoneSerial(records []dns.RR) uint32 {
+	for _, rr := range records {
+		soa, ok := rr.(*dns.SOA)
+		if ok {
+			return soa.Serial
+		}
+	}
+
+	return 0
+}
+
+// findZone returns the zone that the name belongs to, with only its SOA record as content.
+// The name itself is looked up first, followed by each of its parent domains.
+func (d *dnsHandler) findZone(name string) *Zone {
+	labels := dns.SplitDomainName(name)
+	for i := range labels {
+		zone, err := d.server.zoneRetriever(strings.Join(labels[i:], "."), false)
+		if err == nil {
+			return zone
+		}
+	}
+
+	return nil
+}
+
+// parseZone parses the records of the zone content.
+func parseZone(zone *Zone) ([]dns.RR, error) {
+	records := []dns.RR{}
+
 	zoneRR := dns.NewZoneParser(strings.NewReader(zone.Content), "", "")
 	for {
 		rr, ok := zoneRR.Next()
 		if !ok {
 			err := zoneRR.Err()
 			if err != nil {
-				logger.Errorf("Bad DNS record in zone %q: %v", name, err)
-				writeRcode(w, r, dns.RcodeFormatError)
-				return
+				return nil, err
 			}
 
 			break
 		}
 
-		m.Answer = append(m.Answer, rr)
+		records = append(records, rr)
 	}
 
-	if tsig != nil && tsigOK {
-		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	return records, nil
+}
+
+// lookupRecords returns the records of the given type for the name, following CNAME records within the zone.
+// It also returns whether the name exists in the zone, either because it has records or because one of its
+// subdomains does.
+func lookupRecords(records []dns.RR, name string, qtype uint16) ([]dns.RR, bool) {
+	answers := []dns.RR{}
+	found := false
+
+	for range maxCNAMEChain {
+		var cname *dns.CNAME
+		matched := false
+
+		for _, rr := range records {
+			hdr := rr.Header()
+			if !dns.IsSubDomain(name, hdr.Name) {
+				continue
+			}
+
+			found = true
+
+			if !strings.EqualFold(hdr.Name, name) {
+				continue
+			}
+
+			if hdr.Rrtype == qtype {
+				answers = append(answers, rr)
+				matched = true
+
+				// The SOA record is repeated at the end of the zone content.
+				if qtype == dns.TypeSOA {
+					break
+				}
+			} else if hdr.Rrtype == dns.TypeCNAME {
+				cname, _ = rr.(*dns.CNAME)
+			}
+		}
+
+		if matched || cname == nil {
+			break
+		}
+
+		answers = append(answers, cname)
+		name = cname.Target
 	}
 
-	err = w.WriteMsg(m)
-	if err != nil {
-		logger.Error("Cannot write message", logger.Ctx{"err": err})
+	return answers, found
+}
+
+// isClientAllowed checks whether the client address is part of one of the subnets allowed to query the zone.
+func (d *dnsHandler) isClientAllowed(zone api.NetworkZone, ip string) bool {
+	clientIP := net.ParseIP(ip)
+	if clientIP == nil {
+		return false
 	}
+
+	for _, subnet := range shared.SplitNTrimSpace(zone.Config["dns.allowed_subnets"], ",", -1, true) {
+		_, allowedNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			continue
+		}
+
+		if allowedNet.Contains(clientIP) {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (d *dnsHandler) isAllowed(zone api.NetworkZone, ip string, tsig *dns.TSIG, tsigStatus bool) bool {
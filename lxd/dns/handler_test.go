@@ -1,6 +1,7 @@
 package dns
 
 import (
+	"fmt"
 	"net"
 	"testing"
 
@@ -68,7 +69,7 @@ func TestWriteRcode(t *testing.T) {
 func TestServeDNS_UnsupportedQueryType(t *testing.T) {
 	t.Parallel()
 
-	unsupported := []uint16{dns.TypeA, dns.TypeAAAA, dns.TypeMX, dns.TypeNS, dns.TypeTXT}
+	unsupported := []uint16{dns.TypeANY}
 
 	for _, qtype := range unsupported {
 		t.Run(dns.TypeToString[qtype], func(t *testing.T) {
@@ -203,6 +204,233 @@ func TestServeDNS_SOA_IPPeer(t *testing.T) {
 	assert.Equal(t, dns.TypeSOA, w.written.Answer[0].Header().Rrtype)
 }
 
+func TestServeDNS_Query(t *testing.T) {
+	t.Parallel()
+
+	soa := "example.net.\t300\tIN\tSOA\tns1.example.net. admin.example.net. 1 3600 900 604800 300\n"
+	content := soa +
+		"example.net.\t300\tIN\tNS\tns1.example.net.\n" +
+		"c1.example.net.\t300\tIN\tA\t192.0.2.10\n" +
+		"c1.example.net.\t300\tIN\tAAAA\t2001:db8::10\n" +
+		"www.example.net.\t300\tIN\tCNAME\tc1.example.net.\n" +
+		"_http._tcp.web.example.net.\t300\tIN\tSRV\t10 5 80 c1.example.net.\n" +
+		soa
+
+	config := map[string]string{
+		"dns.allowed_subnets": "192.0.2.0/24, 2001:db8::/32",
+	}
+
+	retriever := func(name string, full bool) (*Zone, error) {
+		if name != "example.net" {
+			return nil, assert.AnError
+		}
+
+		zone := &Zone{Info: api.NetworkZone{Name: "example.net", Config: config}, Content: soa}
+		if full {
+			zone.Content = content
+		}
+
+		return zone, nil
+	}
+
+	tests := []struct {
+		name        string
+		client      string
+		qname       string
+		qtype       uint16
+		wantRcode   int
+		wantAnswers []uint16
+		wantSOA     bool
+	}{
+		{
+			name:        "A record",
+			client:      "192.0.2.1:12345",
+			qname:       "c1.example.net.",
+			qtype:       dns.TypeA,
+			wantRcode:   dns.RcodeSuccess,
+			wantAnswers: []uint16{dns.TypeA},
+		},
+		{
+			name:        "AAAA record from IPv6 client",
+			client:      "[2001:db8::1]:12345",
+			qname:       "C1.Example.Net.",
+			qtype:       dns.TypeAAAA,
+			wantRcode:   dns.RcodeSuccess,
+			wantAnswers: []uint16{dns.TypeAAAA},
+		},
+		{
+			name:        "SRV record",
+			client:      "192.0.2.1:12345",
+			qname:       "_http._tcp.web.example.net.",
+			qtype:       dns.TypeSRV,
+			wantRcode:   dns.RcodeSuccess,
+			wantAnswers: []uint16{dns.TypeSRV},
+		},
+		{
+			name:        "SOA record",
+			client:      "192.0.2.1:12345",
+			qname:       "example.net.",
+			qtype:       dns.TypeSOA,
+			wantRcode:   dns.RcodeSuccess,
+			wantAnswers: []uint16{dns.TypeSOA},
+		},
+		{
+			name:        "CNAME record",
+			client:      "192.0.2.1:12345",
+			qname:       "www.example.net.",
+			qtype:       dns.TypeA,
+			wantRcode:   dns.RcodeSuccess,
+			wantAnswers: []uint16{dns.TypeCNAME, dns.TypeA},
+		},
+		{
+			name:      "No record of the requested type",
+			client:    "192.0.2.1:12345",
+			qname:     "c1.example.net.",
+			qtype:     dns.TypeTXT,
+			wantRcode: dns.RcodeSuccess,
+			wantSOA:   true,
+		},
+		{
+			name:      "Name with records in subdomains only",
+			client:    "192.0.2.1:12345",
+			qname:     "web.example.net.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeSuccess,
+			wantSOA:   true,
+		},
+		{
+			name:      "Unknown name",
+			client:    "192.0.2.1:12345",
+			qname:     "c2.example.net.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeNameError,
+			wantSOA:   true,
+		},
+		{
+			name:      "Unknown zone",
+			client:    "192.0.2.1:12345",
+			qname:     "c1.example.org.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeNameError,
+		},
+		{
+			name:      "Client outside allowed subnets",
+			client:    "198.51.100.1:12345",
+			qname:     "c1.example.net.",
+			qtype:     dns.TypeA,
+			wantRcode: dns.RcodeNameError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := &dnsHandler{server: &Server{zoneRetriever: retriever}}
+			w := newMockWriter(tt.client, nil)
+			r := new(dns.Msg)
+			r.SetQuestion(tt.qname, tt.qtype)
+
+			h.ServeDNS(w, r)
+
+			require.NotNil(t, w.written)
+			assert.Equal(t, tt.wantRcode, w.written.Rcode)
+
+			answers := []uint16{}
+			for _, rr := range w.written.Answer {
+				answers = append(answers, rr.Header().Rrtype)
+			}
+
+			if tt.wantAnswers == nil {
+				tt.wantAnswers = []uint16{}
+			}
+
+			assert.Equal(t, tt.wantAnswers, answers)
+
+			if tt.wantSOA {
+				require.Len(t, w.written.Ns, 1)
+				assert.Equal(t, dns.TypeSOA, w.written.Ns[0].Header().Rrtype)
+			}
+		})
+	}
+}
+
+func TestServeDNS_QueryCache(t *testing.T) {
+	t.Parallel()
+
+	serial := 1000
+	fullLoads := 0
+
+	retriever := func(name string, full bool) (*Zone, error) {
+		if name != "example.net" {
+			return nil, assert.AnError
+		}
+
+		soa := fmt.Sprintf("example.net.\t300\tIN\tSOA\tns1.example.net. admin.example.net. %d 3600 900 604800 300\n", serial)
+		zone := &Zone{Info: api.NetworkZone{Name: "example.net", Config: map[string]string{"dns.allowed_subnets": "192.0.2.0/24"}}, Content: soa}
+		if full {
+			fullLoads++
+			zone.Content += fmt.Sprintf("c1.example.net.\t300\tIN\tA\t192.0.2.%d\n", fullLoads)
+		}
+
+		return zone, nil
+	}
+
+	h := &dnsHandler{server: &Server{zoneRetriever: retriever}}
+
+	query := func() string {
+		w := newMockWriter("192.0.2.1:12345", nil)
+		r := new(dns.Msg)
+		r.SetQuestion("c1.example.net.", dns.TypeA)
+
+		h.ServeDNS(w, r)
+
+		require.NotNil(t, w.written)
+		require.Len(t, w.written.Answer, 1)
+
+		return w.written.Answer[0].(*dns.A).A.String()
+	}
+
+	// Queries within the cache window of the serial reuse the generated zone.
+	assert.Equal(t, "192.0.2.1", query())
+	serial += zoneCacheSerialWindow - 1
+	assert.Equal(t, "192.0.2.1", query())
+	assert.Equal(t, 1, fullLoads)
+
+	// The zone is regenerated once its serial is past the cache window.
+	serial++
+	assert.Equal(t, "192.0.2.2", query())
+	assert.Equal(t, 2, fullLoads)
+}
+
+func TestServeDNS_TransferFromAllowedSubnet(t *testing.T) {
+	t.Parallel()
+
+	zone := &Zone{
+		Info: api.NetworkZone{
+			Name: "example.net",
+			Config: map[string]string{
+				"dns.allowed_subnets": "127.0.0.0/8",
+			},
+		},
+		Content: "example.net.\t300\tIN\tSOA\tns1.example.net. admin.example.net. 1 3600 900 604800 300\n",
+	}
+
+	s := &Server{zoneRetriever: func(name string, full bool) (*Zone, error) {
+		return zone, nil
+	}}
+	h := &dnsHandler{server: s}
+	w := newMockWriter("127.0.0.1:12345", nil)
+	r := new(dns.Msg)
+	r.SetQuestion("example.net.", dns.TypeAXFR)
+
+	h.ServeDNS(w, r)
+
+	require.NotNil(t, w.written)
+	// Allowed subnets only grant regular queries, zone transfers are restricted to peers.
+	assert.Equal(t, dns.RcodeNameError, w.written.Rcode)
+}
+
 // TestIsAllowed exercises isAllowed for all combinations of address/key/TSIG.
 func TestIsAllowed(t *testing.T) {
 	t.Parallel()
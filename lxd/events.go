@@ -22,8 +22,8 @@ import (
 	"github.com/canonical/lxd/shared/ws"
 )
 
-var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeOVN, api.EventTypeSecurity}
-var privilegedEventTypes = []string{api.EventTypeLogging, api.EventTypeOVN, api.EventTypeSecurity}
+var eventTypes = []string{api.EventTypeLogging, api.EventTypeOperation, api.EventTypeLifecycle, api.EventTypeNetwork, api.EventTypeOVN, api.EventTypeSecurity}
+var privilegedEventTypes = []string{api.EventTypeLogging, api.EventTypeNetwork, api.EventTypeOVN, api.EventTypeSecurity}
 
 var eventsCmd = APIEndpoint{
 	Path:            "events",
@@ -282,7 +282,7 @@ func eventsSocket(s *state.State, r *http.Request, w http.ResponseWriter) error
 //	    example: default
 //	  - in: query
 //	    name: type
-//	    description: Event type(s), comma separated (valid types are logging, operation, lifecycle, ovn or security)
+//	    description: Event type(s), comma separated (valid types are logging, operation, lifecycle, network, ovn or security)
 //	    type: string
 //	    example: logging,lifecycle
 //	  - in: query
@@ -41,6 +41,7 @@ func (l *InternalListener) startListener() {
 	filteredEvents := []string{
 		api.EventTypeLifecycle,
 		api.EventTypeLogging,
+		api.EventTypeNetwork,
 		api.EventTypeOVN,
 		api.EventTypeSecurity,
 	}
@@ -32,6 +32,7 @@ type ACLRule struct {
 	Action          string
 	Log             bool   // Whether or not to log matched packets.
 	LogName         string // Log label name (requires Log be true).
+	LogGroup        uint16 // Netlink log group to send matched packets to instead of the kernel log (optional).
 	Source          string
 	Destination     string
 	Protocol        string
@@ -39,6 +40,21 @@ type ACLRule struct {
 	DestinationPort string
 	ICMPType        string
 	ICMPCode        string
+	CounterName     string // Name identifying the packet and byte counters of the rule (optional).
+}
+
+// ACLSet represents a named set of addresses or ports that ACL rules reference using "$<name>" in their
+// address or port fields.
+type ACLSet struct {
+	Name    string
+	Type    string   // Either "address" or "port".
+	Members []string // IP addresses and CIDR subnets for address sets, ports and port ranges for port sets.
+}
+
+// ACLRuleCounters represents the packet and byte counters of an ACL rule.
+type ACLRuleCounters struct {
+	Packets uint64
+	Bytes   uint64
 }
 
 // AddressForward represents a NAT address forward.
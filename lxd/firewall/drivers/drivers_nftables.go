@@ -92,11 +92,11 @@ func (d Nftables) Compat() (bool, error) {
 
 // nftGenericItem represents some common fields amongst the different nftables types.
 type nftGenericItem struct {
-	itemType string // Type of item (table, chain or rule). Populated by LXD.
+	itemType string // Type of item (table, chain, set or rule). Populated by LXD.
 	Family   string `json:"family"` // Family of item (ip, ip6, bridge etc).
-	Table    string `json:"table"`  // Table the item belongs to (for chains and rules).
+	Table    string `json:"table"`  // Table the item belongs to (for chains, sets and rules).
 	Chain    string `json:"chain"`  // Chain the item belongs to (for rules).
-	Name     string `json:"name"`   // Name of item (for tables and chains).
+	Name     string `json:"name"`   // Name of item (for tables, chains and sets).
 }
 
 // nftParseRuleset parses the ruleset and returns the generic parts as a slice of items.
@@ -130,6 +130,7 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		rule, foundRule := item["rule"]
 		chain, foundChain := item["chain"]
 		table, foundTable := item["table"]
+		set, foundSet := item["set"]
 		if foundRule {
 			rule.itemType = "rule"
 			items = append(items, rule)
@@ -139,6 +140,9 @@ func (d Nftables) nftParseRuleset() ([]nftGenericItem, error) {
 		} else if foundTable {
 			table.itemType = "table"
 			items = append(items, table)
+		} else if foundSet {
+			set.itemType = "set"
+			items = append(items, set)
 		}
 	}
 
@@ -328,6 +332,12 @@ func (d Nftables) NetworkClear(networkName string, _ bool, _ []uint) error {
 		return fmt.Errorf("Failed clearing nftables rules for network %q: %w", networkName, err)
 	}
 
+	// Remove the sets used by ACL rules now that no rules reference them anymore.
+	err = d.removeACLSets(networkName)
+	if err != nil {
+		return fmt.Errorf("Failed clearing nftables sets for network %q: %w", networkName, err)
+	}
+
 	return nil
 }
 
@@ -652,34 +662,80 @@ func (d Nftables) InstanceClearNetPrio(projectName string, instanceName string,
 }
 
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+// The sets referenced by the rules are created as named sets specific to the network.
+func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule, sets []ACLSet) error {
 	nftRules := make([]string, 0)
 	for _, rule := range rules {
-		// First try generating rules with IPv4 or IP agnostic criteria.
-		nftRule, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &rule)
-		if err != nil {
-			return err
-		}
-
-		if nftRule != "" {
-			nftRules = append(nftRules, nftRule)
-		}
-
-		if partial {
-			// If we couldn't fully generate the ruleset with only IPv4 or IP agnostic criteria, then
-			// fill in the remaining parts using IPv6 criteria.
-			nftRule, _, err = d.aclRuleCriteriaToRules(networkName, 6, &rule)
+		for _, splitRule := range aclRuleSplitSets(rule) {
+			// First try generating rules with IPv4 or IP agnostic criteria.
+			nftRule4, partial, err := d.aclRuleCriteriaToRules(networkName, 4, &splitRule)
 			if err != nil {
 				return err
 			}
 
-			if nftRule == "" {
+			if nftRule4 != "" {
+				nftRules = append(nftRules, nftRule4)
+			}
+
+			var nftRule6 string
+			if partial {
+				// If we couldn't fully generate the ruleset with only IPv4 or IP agnostic criteria, then
+				// fill in the remaining parts using IPv6 criteria.
+				nftRule6, _, err = d.aclRuleCriteriaToRules(networkName, 6, &splitRule)
+				if err != nil {
+					return err
+				}
+
+				if nftRule6 != "" {
+					nftRules = append(nftRules, nftRule6)
+				}
+			}
+
+			if nftRule4 == "" && nftRule6 == "" {
 				return errors.New("Invalid empty rule generated")
 			}
+		}
+	}
+
+	nftSets := make([]map[string]any, 0, len(sets)*2)
+	for _, set := range sets {
+		switch set.Type {
+		case "address":
+			members := map[uint][]string{4: {}, 6: {}}
+			for _, member := range set.Members {
+				ip := net.ParseIP(member)
+				if ip == nil {
+					ip, _, _ = net.ParseCIDR(member)
+				}
+
+				if ip == nil {
+					return fmt.Errorf("Invalid address %q in address set %q", member, set.Name)
+				}
+
+				if ip.To4() == nil {
+					members[6] = append(members[6], member)
+				} else {
+					members[4] = append(members[4], member)
+				}
+			}
 
-			nftRules = append(nftRules, nftRule)
-		} else if nftRule == "" {
-			return errors.New("Invalid empty rule generated")
+			nftSets = append(nftSets, map[string]any{
+				"name":     d.aclSetName(networkName, set.Name, "ip4"),
+				"type":     "ipv4_addr",
+				"elements": strings.Join(members[4], ", "),
+			}, map[string]any{
+				"name":     d.aclSetName(networkName, set.Name, "ip6"),
+				"type":     "ipv6_addr",
+				"elements": strings.Join(members[6], ", "),
+			})
+		case "port":
+			nftSets = append(nftSets, map[string]any{
+				"name":     d.aclSetName(networkName, set.Name, "port"),
+				"type":     "inet_service",
+				"elements": strings.Join(set.Members, ", "),
+			})
+		default:
+			return fmt.Errorf("Unknown set type %q for set %q", set.Type, set.Name)
 		}
 	}
 
@@ -689,6 +745,7 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		"networkName":    networkName,
 		"family":         "inet",
 		"rules":          nftRules,
+		"sets":           nftSets,
 	}
 
 	config := &strings.Builder{}
@@ -702,6 +759,52 @@ func (d Nftables) NetworkApplyACLRules(networkName string, rules []ACLRule) erro
 		return err
 	}
 
+	// Remove the sets that are no longer referenced by the rules.
+	keepSets := make([]string, 0, len(nftSets))
+	for _, nftSet := range nftSets {
+		keepSets = append(keepSets, nftSet["name"].(string))
+	}
+
+	err = d.removeACLSets(networkName, keepSets...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// aclSetName returns the name of the nftables set holding the members of an ACL set for the network.
+// The suffix is "ip4" or "ip6" for the addresses of each IP family of an address set and "port" for port sets.
+func (d Nftables) aclSetName(networkName string, setName string, suffix string) string {
+	return "aclset" + nftablesChainSeparator + networkName + nftablesChainSeparator + setName + "_" + suffix
+}
+
+// removeACLSets removes the nftables sets used by the network's ACL rules, except for the ones specified.
+func (d Nftables) removeACLSets(networkName string, keepSets ...string) error {
+	ruleset, err := d.nftParseRuleset()
+	if err != nil {
+		return err
+	}
+
+	prefix := "aclset" + nftablesChainSeparator + networkName + nftablesChainSeparator
+
+	for _, item := range ruleset {
+		if item.itemType != "set" || item.Family != "inet" || item.Table != nftablesNamespace || slices.Contains(keepSets, item.Name) {
+			continue
+		}
+
+		// ACL set names cannot contain the separator, so anything else belongs to another network.
+		setName, found := strings.CutPrefix(item.Name, prefix)
+		if !found || strings.Contains(setName, nftablesChainSeparator) {
+			continue
+		}
+
+		_, err = shared.RunCommand(context.TODO(), "nft", "delete", "set", item.Family, nftablesNamespace, item.Name)
+		if err != nil {
+			return fmt.Errorf("Failed deleting nftables set %q: %w", item.Name, err)
+		}
+	}
+
 	return nil
 }
 
@@ -719,7 +822,7 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	isPartialRule := false
 
 	if rule.Source != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("saddr", ipVersion, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch(networkName, "saddr", ipVersion, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -736,7 +839,7 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	}
 
 	if rule.Destination != "" {
-		matchArgs, partial, err := d.aclRuleSubjectToACLMatch("daddr", ipVersion, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		matchArgs, partial, err := d.aclRuleSubjectToACLMatch(networkName, "daddr", ipVersion, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return "", false, err
 		}
@@ -757,11 +860,11 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 		args = append(args, "meta", "l4proto", rule.Protocol)
 
 		if rule.SourcePort != "" {
-			args = append(args, d.aclRulePortToACLMatch("sport", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)...)
+			args = append(args, d.aclRulePortToACLMatch(networkName, "sport", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)...)
 		}
 
 		if rule.DestinationPort != "" {
-			args = append(args, d.aclRulePortToACLMatch("dport", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)...)
+			args = append(args, d.aclRulePortToACLMatch(networkName, "dport", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)...)
 		}
 	} else if slices.Contains([]string{"icmp4", "icmp6"}, rule.Protocol) {
 		var icmpIPVersion uint
@@ -783,8 +886,13 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 			// with at least some subjects in the same family as ipVersion. So if the icmpIPVersion
 			// doesn't match the ipVersion then it means the rule contains mixed-version subjects
 			// which is invalid when using an IP version specific ICMP protocol.
-			if rule.Source != "" || rule.Destination != "" {
-				return "", false, fmt.Errorf("Invalid use of %q protocol with non-IPv%d source/destination criteria", rule.Protocol, ipVersion)
+			// Set references are rendered for both IP versions, so only the addresses of the set of
+			// the ICMP protocol's IP version are used.
+			for _, subject := range []string{rule.Source, rule.Destination} {
+				_, isSet := aclSetReference(subject)
+				if subject != "" && !isSet {
+					return "", false, fmt.Errorf("Invalid use of %q protocol with non-IPv%d source/destination criteria", rule.Protocol, ipVersion)
+				}
 			}
 
 			// Otherwise it means this is just a blanket ICMP rule and is only appropriate for use
@@ -805,12 +913,21 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 	if rule.Log {
 		args = append(args, "log")
 
+		if rule.LogGroup > 0 {
+			args = append(args, "group", strconv.FormatUint(uint64(rule.LogGroup), 10))
+		}
+
 		if rule.LogName != "" {
 			// Add a trailing space to prefix for readability in logs.
 			args = append(args, "prefix", `"`+rule.LogName+` "`)
 		}
 	}
 
+	// Handle counters.
+	if rule.CounterName != "" {
+		args = append(args, "counter")
+	}
+
 	// Handle action.
 	action := rule.Action
 	if action == "allow" {
@@ -819,18 +936,99 @@ func (d Nftables) aclRuleCriteriaToRules(networkName string, ipVersion uint, rul
 
 	args = append(args, action)
 
+	if rule.CounterName != "" {
+		args = append(args, "comment", `"`+rule.CounterName+`"`)
+	}
+
 	return strings.Join(args, " "), isPartialRule, nil
 }
 
+// NetworkACLRuleCounters returns the packet and byte counters of the ACL rules applied to the network, keyed
+// by counter name. Counters of rules sharing the same name are summed.
+func (d Nftables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounters, error) {
+	chain := "acl" + nftablesChainSeparator + networkName
+
+	output, err := shared.RunCommandCLocale("nft", "--json", "-nn", "list", "chain", "inet", nftablesNamespace, chain)
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing nftables chain %q: %w", chain, err)
+	}
+
+	counters, err := nftablesACLRuleCounters([]byte(output))
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing nftables chain %q: %w", chain, err)
+	}
+
+	return counters, nil
+}
+
+// nftablesACLRuleCounters parses the JSON listing of an nftables chain and returns the counters of its rules
+// keyed by rule comment. Counters of rules sharing the same comment are summed.
+func nftablesACLRuleCounters(output []byte) (map[string]ACLRuleCounters, error) {
+	// This only extracts the comment and counter of the rules, see man libnftables-json for more info.
+	v := &struct {
+		Nftables []struct {
+			Rule *struct {
+				Comment string `json:"comment"`
+				Expr    []struct {
+					Counter *struct {
+						Packets uint64 `json:"packets"`
+						Bytes   uint64 `json:"bytes"`
+					} `json:"counter"`
+				} `json:"expr"`
+			} `json:"rule"`
+		} `json:"nftables"`
+	}{}
+
+	err := json.Unmarshal(output, v)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := make(map[string]ACLRuleCounters)
+	for _, item := range v.Nftables {
+		if item.Rule == nil || item.Rule.Comment == "" {
+			continue
+		}
+
+		for _, expr := range item.Rule.Expr {
+			if expr.Counter == nil {
+				continue
+			}
+
+			counter := counters[item.Rule.Comment]
+			counter.Packets += expr.Counter.Packets
+			counter.Bytes += expr.Counter.Bytes
+			counters[item.Rule.Comment] = counter
+		}
+	}
+
+	return counters, nil
+}
+
 // aclRuleSubjectToACLMatch converts direction (source/destination) and subject criteria list into xtables args.
 // Returns nil if none of the subjects are appropriate for the ipVersion.
-func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, subjectCriteria ...string) ([]string, bool, error) {
+// A set reference must be the only criterion and is always a partial match, as the set is used for both IP versions.
+func (d Nftables) aclRuleSubjectToACLMatch(networkName string, direction string, ipVersion uint, subjectCriteria ...string) ([]string, bool, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 
 	partial := false
 
+	ipFamily := "ip"
+	if ipVersion == 6 {
+		ipFamily = "ip6"
+	}
+
 	// For each criterion check if value looks like IP CIDR.
 	for _, subjectCriterion := range subjectCriteria {
+		setName, isSet := aclSetReference(subjectCriterion)
+		if isSet {
+			if len(subjectCriteria) > 1 {
+				return nil, false, fmt.Errorf("Set reference %q cannot be combined with other subjects", subjectCriterion)
+			}
+
+			return []string{ipFamily, direction, "@" + d.aclSetName(networkName, setName, fmt.Sprintf("ip%d", ipVersion))}, true, nil
+		}
+
 		if validate.IsNetworkRange(subjectCriterion) == nil {
 			criterionParts := strings.SplitN(subjectCriterion, "-", 2)
 
@@ -877,11 +1075,6 @@ func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, sub
 	}
 
 	if len(fieldParts) > 0 {
-		ipFamily := "ip"
-		if ipVersion == 6 {
-			ipFamily = "ip6"
-		}
-
 		return []string{ipFamily, direction, "{" + strings.Join(fieldParts, ",") + "}"}, partial, nil
 	}
 
@@ -889,11 +1082,16 @@ func (d Nftables) aclRuleSubjectToACLMatch(direction string, ipVersion uint, sub
 }
 
 // aclRulePortToACLMatch converts protocol (tcp/udp), direction (sports/dports) and port criteria list into
-// xtables args.
-func (d Nftables) aclRulePortToACLMatch(direction string, portCriteria ...string) []string {
+// xtables args. A port set reference is expected to be the only criterion.
+func (d Nftables) aclRulePortToACLMatch(networkName string, direction string, portCriteria ...string) []string {
 	fieldParts := make([]string, 0, len(portCriteria))
 
 	for _, portCriterion := range portCriteria {
+		setName, isSet := aclSetReference(portCriterion)
+		if isSet && len(portCriteria) == 1 {
+			return []string{"th", direction, "@" + d.aclSetName(networkName, setName, "port")}
+		}
+
 		criterionParts := strings.SplitN(portCriterion, "-", 2)
 		if len(criterionParts) > 1 {
 			fieldParts = append(fieldParts, criterionParts[0]+"-"+criterionParts[1])
@@ -173,6 +173,14 @@ table {{.family}} {{.namespace}} {
 `))
 
 var nftablesNetACLRules = template.Must(template.New("nftablesNetACLRules").Parse(`
+{{- range .sets}}
+add set {{$.family}} {{$.namespace}} {{.name}} { type {{.type}}; flags interval; auto-merge; }
+flush set {{$.family}} {{$.namespace}} {{.name}}
+{{- if .elements}}
+add element {{$.family}} {{$.namespace}} {{.name}} { {{.elements}} }
+{{- end}}
+{{- end}}
+
 flush chain {{.family}} {{.namespace}} acl{{.chainSeparator}}{{.networkName}}
 
 table {{.family}} {{.namespace}} {
@@ -0,0 +1,113 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_nftablesACLRuleCriteriaToRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     ACLRule
+		expected string
+		partial  bool
+	}{
+		{
+			name: "Without counter",
+			rule: ACLRule{
+				Direction:       "ingress",
+				Action:          "allow",
+				Protocol:        "tcp",
+				DestinationPort: "22",
+			},
+			expected: "oifname lxdbr0 meta l4proto tcp th dport {22} accept",
+		},
+		{
+			name: "With counter",
+			rule: ACLRule{
+				Direction:   "egress",
+				Action:      "drop",
+				Destination: "192.0.2.1",
+				CounterName: "lxd_acl1-egress-0",
+			},
+			expected: `iifname lxdbr0 ip daddr {192.0.2.1} counter drop comment "lxd_acl1-egress-0"`,
+		},
+		{
+			name: "With counter and logging",
+			rule: ACLRule{
+				Direction:   "ingress",
+				Action:      "reject",
+				Log:         true,
+				LogName:     "lxdbr0-ingress-2",
+				CounterName: "lxd_acl1-ingress-2",
+			},
+			expected: `oifname lxdbr0 log prefix "lxdbr0-ingress-2 " counter reject comment "lxd_acl1-ingress-2"`,
+		},
+		{
+			name: "With netlink log group",
+			rule: ACLRule{
+				Direction:   "egress",
+				Action:      "allow",
+				Log:         true,
+				LogName:     "lxd_acl1-egress-0 allow",
+				LogGroup:    8000,
+				CounterName: "lxd_acl1-egress-0",
+			},
+			expected: `iifname lxdbr0 log group 8000 prefix "lxd_acl1-egress-0 allow " counter accept comment "lxd_acl1-egress-0"`,
+		},
+		{
+			name: "With address and port sets",
+			rule: ACLRule{
+				Direction:       "ingress",
+				Action:          "allow",
+				Protocol:        "tcp",
+				Source:          "$web",
+				DestinationPort: "$http",
+			},
+			expected: "oifname lxdbr0 ip saddr @aclset.lxdbr0.web_ip4 meta l4proto tcp th dport @aclset.lxdbr0.http_port accept",
+			partial:  true,
+		},
+	}
+
+	d := Nftables{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, partial, err := d.aclRuleCriteriaToRules("lxdbr0", 4, &tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.partial, partial)
+			assert.Equal(t, tt.expected, rule)
+		})
+	}
+}
+
+func Test_nftablesACLRuleCounters(t *testing.T) {
+	output := `{"nftables": [
+  {"metainfo": {"version": "1.0.9", "release_name": "Old Doc Yak #3", "json_schema_version": 1}},
+  {"chain": {"family": "inet", "table": "lxd", "name": "acl.lxdbr0", "handle": 10}},
+  {"rule": {"family": "inet", "table": "lxd", "chain": "acl.lxdbr0", "handle": 11, "comment": "lxd_acl1-ingress-0", "expr": [
+    {"match": {"op": "==", "left": {"meta": {"key": "oifname"}}, "right": "lxdbr0"}},
+    {"counter": {"packets": 5, "bytes": 420}},
+    {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "lxd", "chain": "acl.lxdbr0", "handle": 12, "comment": "lxd_acl1-ingress-0", "expr": [
+    {"counter": {"packets": 1, "bytes": 80}},
+    {"accept": null}]}},
+  {"rule": {"family": "inet", "table": "lxd", "chain": "acl.lxdbr0", "handle": 13, "comment": "lxd_acl1-egress-0", "expr": [
+    {"counter": {"packets": 0, "bytes": 0}},
+    {"drop": null}]}},
+  {"rule": {"family": "inet", "table": "lxd", "chain": "acl.lxdbr0", "handle": 14, "expr": [
+    {"counter": {"packets": 7, "bytes": 700}},
+    {"reject": null}]}}
+]}`
+
+	counters, err := nftablesACLRuleCounters([]byte(output))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]ACLRuleCounters{
+		"lxd_acl1-ingress-0": {Packets: 6, Bytes: 500},
+		"lxd_acl1-egress-0":  {Packets: 0, Bytes: 0},
+	}, counters)
+
+	_, err = nftablesACLRuleCounters([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
 )
 
 // portRangesFromSlice checks if adjacent indices in the given slice contain consecutive
@@ -147,3 +150,133 @@ func subnetPrefixHex(ipNet *net.IPNet) (string, error) {
 
 	return hexStr[:ones/4], nil
 }
+
+// aclSetReference returns the name of the set referenced by an ACL rule criterion in the form "$<name>".
+func aclSetReference(criterion string) (string, bool) {
+	return strings.CutPrefix(criterion, "$")
+}
+
+// aclRuleSetFields returns pointers to the fields of the rule that can reference sets, keyed by the set type.
+func aclRuleSetFields(rule *ACLRule) map[*string]string {
+	return map[*string]string{
+		&rule.Source:          "address",
+		&rule.Destination:     "address",
+		&rule.SourcePort:      "port",
+		&rule.DestinationPort: "port",
+	}
+}
+
+// aclRuleSplitSets splits the rule into rules that each reference at most one set in each of their address and
+// port fields, with the literal values of a field kept together in a separate rule.
+// This is needed by firewalls that cannot combine a named set with other values in a single match.
+func aclRuleSplitSets(rule ACLRule) []ACLRule {
+	rules := []ACLRule{rule}
+
+	for _, getField := range []func(r *ACLRule) *string{
+		func(r *ACLRule) *string { return &r.Source },
+		func(r *ACLRule) *string { return &r.Destination },
+		func(r *ACLRule) *string { return &r.SourcePort },
+		func(r *ACLRule) *string { return &r.DestinationPort },
+	} {
+		if *getField(&rule) == "" {
+			continue
+		}
+
+		var literals []string
+		var values []string
+		for _, criterion := range shared.SplitNTrimSpace(*getField(&rule), ",", -1, false) {
+			_, isSet := aclSetReference(criterion)
+			if isSet {
+				values = append(values, criterion)
+			} else {
+				literals = append(literals, criterion)
+			}
+		}
+
+		if len(literals) > 0 {
+			values = append([]string{strings.Join(literals, ",")}, values...)
+		}
+
+		splitRules := make([]ACLRule, 0, len(rules)*len(values))
+		for _, r := range rules {
+			for _, value := range values {
+				*getField(&r) = value
+				splitRules = append(splitRules, r)
+			}
+		}
+
+		rules = splitRules
+	}
+
+	return rules
+}
+
+// aclRuleExpandSets returns a copy of the rule with its set references replaced by the members of the sets.
+// Address set members that aren't of the IP family of an ICMP rule are left out.
+// Returns false if one of the fields of the rule only references empty sets, as such a rule never matches.
+func aclRuleExpandSets(rule ACLRule, sets []ACLSet) (ACLRule, bool, error) {
+	for field, setType := range aclRuleSetFields(&rule) {
+		if *field == "" {
+			continue
+		}
+
+		var values []string
+		for _, criterion := range shared.SplitNTrimSpace(*field, ",", -1, false) {
+			name, isSet := aclSetReference(criterion)
+			if !isSet {
+				values = append(values, criterion)
+				continue
+			}
+
+			set := aclSetByName(sets, setType, name)
+			if set == nil {
+				return ACLRule{}, false, fmt.Errorf("Unknown %s set %q", setType, name)
+			}
+
+			for _, member := range set.Members {
+				if setType == "address" && !aclAddressMatchesProtocol(member, rule.Protocol) {
+					continue
+				}
+
+				values = append(values, member)
+			}
+		}
+
+		if len(values) == 0 {
+			return ACLRule{}, false, nil
+		}
+
+		*field = strings.Join(values, ",")
+	}
+
+	return rule, true, nil
+}
+
+// aclSetByName returns the set of the specified type and name, or nil if not found.
+func aclSetByName(sets []ACLSet, setType string, name string) *ACLSet {
+	for i := range sets {
+		if sets[i].Type == setType && sets[i].Name == name {
+			return &sets[i]
+		}
+	}
+
+	return nil
+}
+
+// aclAddressMatchesProtocol returns false if the address (IP or CIDR) cannot be used with the ICMP protocol
+// version of the rule.
+func aclAddressMatchesProtocol(address string, protocol string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		ip, _, _ = net.ParseCIDR(address)
+	}
+
+	switch protocol {
+	case "icmp4":
+		return ip == nil || ip.To4() != nil
+	case "icmp6":
+		return ip == nil || ip.To4() == nil
+	}
+
+	return true
+}
@@ -145,3 +145,92 @@ func Test_getOptimisedSNATRanges(t *testing.T) {
 		assert.Equal(t, tt.expected, actual)
 	}
 }
+
+func Test_aclRuleSplitSets(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     ACLRule
+		expected []ACLRule
+	}{
+		{
+			name:     "No sets",
+			rule:     ACLRule{Source: "192.0.2.1,192.0.2.2", DestinationPort: "80"},
+			expected: []ACLRule{{Source: "192.0.2.1,192.0.2.2", DestinationPort: "80"}},
+		},
+		{
+			name: "Set and literals",
+			rule: ACLRule{Source: "192.0.2.1,$web,192.0.2.2", DestinationPort: "80"},
+			expected: []ACLRule{
+				{Source: "192.0.2.1,192.0.2.2", DestinationPort: "80"},
+				{Source: "$web", DestinationPort: "80"},
+			},
+		},
+		{
+			name: "Sets in several fields",
+			rule: ACLRule{Source: "$web,$db", DestinationPort: "$http"},
+			expected: []ACLRule{
+				{Source: "$web", DestinationPort: "$http"},
+				{Source: "$db", DestinationPort: "$http"},
+			},
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		assert.Equal(t, tt.expected, aclRuleSplitSets(tt.rule))
+	}
+}
+
+func Test_aclRuleExpandSets(t *testing.T) {
+	sets := []ACLSet{
+		{Name: "web", Type: "address", Members: []string{"192.0.2.10", "2001:db8::10"}},
+		{Name: "empty", Type: "address"},
+		{Name: "http", Type: "port", Members: []string{"80", "443"}},
+	}
+
+	tests := []struct {
+		name      string
+		rule      ACLRule
+		expected  ACLRule
+		matchable bool
+		err       bool
+	}{
+		{
+			name:      "Address and port sets",
+			rule:      ACLRule{Protocol: "tcp", Source: "192.0.2.1,$web", DestinationPort: "$http,8080"},
+			expected:  ACLRule{Protocol: "tcp", Source: "192.0.2.1,192.0.2.10,2001:db8::10", DestinationPort: "80,443,8080"},
+			matchable: true,
+		},
+		{
+			name:      "ICMPv4 rule leaves out IPv6 members",
+			rule:      ACLRule{Protocol: "icmp4", Destination: "$web"},
+			expected:  ACLRule{Protocol: "icmp4", Destination: "192.0.2.10"},
+			matchable: true,
+		},
+		{
+			name:      "Only empty set",
+			rule:      ACLRule{Source: "$empty"},
+			matchable: false,
+		},
+		{
+			name: "Unknown set",
+			rule: ACLRule{Source: "$missing"},
+			err:  true,
+		},
+	}
+
+	for i, tt := range tests {
+		log.Printf("Running test #%d: %s", i, tt.name)
+		rule, matchable, err := aclRuleExpandSets(tt.rule, sets)
+		if tt.err {
+			assert.Error(t, err)
+			continue
+		}
+
+		assert.NoError(t, err)
+		assert.Equal(t, tt.matchable, matchable)
+		if tt.matchable {
+			assert.Equal(t, tt.expected, rule)
+		}
+	}
+}
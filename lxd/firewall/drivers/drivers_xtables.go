@@ -522,9 +522,24 @@ func (d Xtables) NetworkSetup(networkName string, ipv4Address net.IP, ipv6Addres
 }
 
 // NetworkApplyACLRules applies ACL rules to the existing firewall chains.
-func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error {
+// Xtables has no native sets, so the sets referenced by the rules are expanded inline.
+func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule, sets []ACLSet) error {
 	chain := iptablesChainACLFilterPrefix + "_" + networkName
 
+	expandedRules := make([]ACLRule, 0, len(rules))
+	for _, rule := range rules {
+		expandedRule, matchable, err := aclRuleExpandSets(rule, sets)
+		if err != nil {
+			return err
+		}
+
+		if !matchable {
+			continue // Rule only references empty sets.
+		}
+
+		expandedRules = append(expandedRules, expandedRule)
+	}
+
 	// Parse rules for both IP families before applying either family of rules.
 	iptCmdRules := make(map[string][][]string)
 	for _, ipVersion := range []uint{4, 6} {
@@ -534,7 +549,7 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 		}
 
 		iptRules := make([][]string, 0)
-		for _, rule := range rules {
+		for _, rule := range expandedRules {
 			actionArgs, logArgs, err := d.aclRuleCriteriaToArgs(networkName, ipVersion, &rule)
 			if err != nil {
 				return err
@@ -589,6 +604,53 @@ func (d Xtables) NetworkApplyACLRules(networkName string, rules []ACLRule) error
 	return nil
 }
 
+// NetworkACLRuleCounters returns the packet and byte counters of the ACL rules applied to the network, keyed
+// by counter name. Counters of rules sharing the same name (such as the IPv4 and IPv6 variants) are summed.
+func (d Xtables) NetworkACLRuleCounters(networkName string) (map[string]ACLRuleCounters, error) {
+	chain := iptablesChainACLFilterPrefix + "_" + networkName
+	counters := make(map[string]ACLRuleCounters)
+
+	for _, cmd := range []string{"iptables-save", "ip6tables-save"} {
+		output, err := shared.RunCommandCLocale(cmd, "-c", "-t", "filter")
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing %q rules: %w", cmd, err)
+		}
+
+		xtablesACLRuleCounters(output, chain, counters)
+	}
+
+	return counters, nil
+}
+
+// xtablesACLRuleCounters parses the output of iptables-save with counters and adds the counters of the rules of
+// the specified chain to counters, keyed by rule comment.
+func xtablesACLRuleCounters(output string, chain string, counters map[string]ACLRuleCounters) {
+	for line := range strings.SplitSeq(output, "\n") {
+		// Rules with counters are in the form "[<packets>:<bytes>] -A <chain> <args>".
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "-A" || fields[2] != chain {
+			continue
+		}
+
+		commentIndex := slices.Index(fields, "--comment")
+		if commentIndex < 0 || commentIndex+1 >= len(fields) {
+			continue
+		}
+
+		var packets, bytes uint64
+		_, err := fmt.Sscanf(fields[0], "[%d:%d]", &packets, &bytes)
+		if err != nil {
+			continue
+		}
+
+		name := strings.Trim(fields[commentIndex+1], `"`)
+		counter := counters[name]
+		counter.Packets += packets
+		counter.Bytes += bytes
+		counters[name] = counter
+	}
+}
+
 // aclRuleCriteriaToArgs converts an ACL rule into an set of arguments for an xtables rule.
 // Returns the arguments to use for the action command and separately the arguments for logging if enabled.
 // Returns nil arguments if the rule is not appropriate for the ipVersion.
@@ -692,10 +754,23 @@ func (d Xtables) aclRuleCriteriaToArgs(networkName string, ipVersion uint, rule
 		action = "accept"
 	}
 
-	actionArgs = append(args, "-j", strings.ToUpper(action))
+	actionArgs = append([]string{}, args...)
+
+	if rule.CounterName != "" {
+		actionArgs = append(actionArgs, "-m", "comment", "--comment", rule.CounterName)
+	}
+
+	actionArgs = append(actionArgs, "-j", strings.ToUpper(action))
 
 	// Handle logging.
-	if rule.Log {
+	if rule.Log && rule.LogGroup > 0 {
+		logArgs = append(args, "-j", "NFLOG", "--nflog-group", strconv.FormatUint(uint64(rule.LogGroup), 10))
+
+		if rule.LogName != "" {
+			// Add a trailing space to prefix for readability in logs.
+			logArgs = append(logArgs, "--nflog-prefix", rule.LogName+" ")
+		}
+	} else if rule.Log {
 		logArgs = append(args, "-j", "LOG")
 
 		if rule.LogName != "" {
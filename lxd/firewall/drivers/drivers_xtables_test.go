@@ -0,0 +1,34 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_xtablesACLRuleCounters(t *testing.T) {
+	ipv4Output := `# Generated by iptables-save v1.8.10 on Mon Jan  1 12:00:00 2024
+*filter
+:INPUT ACCEPT [100:10000]
+:lxd_acl_lxdbr0 - [0:0]
+[5:420] -A lxd_acl_lxdbr0 -o lxdbr0 -p tcp -m tcp --dport 22 -m comment --comment "lxd_acl1-ingress-0" -j ACCEPT
+[2:120] -A lxd_acl_lxdbr0 -i lxdbr0 -m comment --comment "lxd_acl1-egress-0" -j REJECT
+[9:900] -A lxd_acl_lxdbr1 -o lxdbr1 -m comment --comment "lxd_acl1-ingress-0" -j ACCEPT
+[3:300] -A lxd_acl_lxdbr0 -o lxdbr0 -j REJECT
+COMMIT
+`
+
+	ipv6Output := `*filter
+[1:80] -A lxd_acl_lxdbr0 -o lxdbr0 -p tcp -m tcp --dport 22 -m comment --comment "lxd_acl1-ingress-0" -j ACCEPT
+COMMIT
+`
+
+	counters := make(map[string]ACLRuleCounters)
+	xtablesACLRuleCounters(ipv4Output, "lxd_acl_lxdbr0", counters)
+	xtablesACLRuleCounters(ipv6Output, "lxd_acl_lxdbr0", counters)
+
+	assert.Equal(t, map[string]ACLRuleCounters{
+		"lxd_acl1-ingress-0": {Packets: 6, Bytes: 500},
+		"lxd_acl1-egress-0":  {Packets: 2, Bytes: 120},
+	}, counters)
+}
@@ -61,6 +61,7 @@ import (
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/simplestreams"
 	"github.com/canonical/lxd/shared/validate"
 	"github.com/canonical/lxd/shared/version"
 )
@@ -110,6 +111,14 @@ var imageRefreshCmd = APIEndpoint{
 	Post: APIEndpointAction{Handler: imageRefresh, AccessHandler: imageAccessHandler(auth.EntitlementCanEdit)},
 }
 
+var imagesStreamsCmd = APIEndpoint{
+	Path:            "images/streams",
+	MetricsType:     entity.TypeImage,
+	ProjectSpecific: true,
+
+	Post: APIEndpointAction{Handler: imagesStreamsPost, AccessHandler: allowPermission(entity.TypeServer, auth.EntitlementCanEdit)},
+}
+
 var imageAliasesCmd = APIEndpoint{
 	Path:            "images/aliases",
 	MetricsType:     entity.TypeImage,
@@ -1706,8 +1715,8 @@ func getImageMetadata(fname string) (*api.ImageMetadata, string, error) {
 	return &result, imageType, nil
 }
 
-func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectName string, public bool, clauses *filter.ClauseSet, hasPermission auth.PermissionChecker, allProjects bool) (any, error) {
-	mustLoadObjects := recursion || (clauses != nil && len(clauses.Clauses) > 0)
+func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectName string, public bool, clauses *filter.ClauseSet, pagination *request.Pagination, hasPermission auth.PermissionChecker, allProjects bool) (any, string, error) {
+	mustLoadObjects := recursion || (clauses != nil && len(clauses.Clauses) > 0) || pagination.IsSet()
 
 	imagesProjectsMap := map[string][]string{}
 	if allProjects {
@@ -1715,12 +1724,12 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 
 		imagesProjectsMap, err = tx.GetImages(ctx)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 	} else {
 		fingerprints, err := tx.GetImagesFingerprints(ctx, projectName, public)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		for _, fingerprint := range fingerprints {
@@ -1731,12 +1740,18 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 	var resultString []string
 	var resultMap []*api.Image
 
-	if recursion {
-		resultMap = make([]*api.Image, 0, len(imagesProjectsMap))
-	} else {
-		resultString = make([]string, 0, len(imagesProjectsMap))
+	imageURL := func(project string, fingerprint string) string {
+		url := api.NewURL().Path(version.APIVersion, "images", fingerprint)
+		if allProjects {
+			url = url.Project(project)
+		}
+
+		return url.String()
 	}
 
+	// Keep track of the project each loaded image was found in.
+	imageProjects := make(map[*api.Image]string, len(imagesProjectsMap))
+
 	for fingerprint, projects := range imagesProjectsMap {
 		for _, project := range projects {
 			image, err := doImageGet(ctx, tx, project, fingerprint, public)
@@ -1749,43 +1764,54 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 			}
 
 			if !mustLoadObjects {
-				url := api.NewURL().Path(version.APIVersion, "images", fingerprint)
-				if allProjects {
-					url = url.Project(project)
-				}
-
-				resultString = append(resultString, url.String())
-			} else {
-				if clauses != nil && len(clauses.Clauses) > 0 {
-					match, err := filter.Match(*image, *clauses)
-					if err != nil {
-						return nil, err
-					}
+				resultString = append(resultString, imageURL(project, fingerprint))
+				continue
+			}
 
-					if !match {
-						continue
-					}
+			if clauses != nil && len(clauses.Clauses) > 0 {
+				match, err := filter.Match(*image, *clauses)
+				if err != nil {
+					return nil, "", err
 				}
 
-				if recursion {
-					resultMap = append(resultMap, image)
-				} else {
-					url := api.NewURL().Path(version.APIVersion, "images", image.Fingerprint)
-					if allProjects {
-						url = url.Project(project)
-					}
-
-					resultString = append(resultString, url.String())
+				if !match {
+					continue
 				}
 			}
+
+			imageProjects[image] = project
+			resultMap = append(resultMap, image)
+		}
+	}
+
+	var next string
+	if pagination.IsSet() {
+		var err error
+
+		resultMap, next, err = request.Paginate(pagination, resultMap, "fingerprint", "project")
+		if err != nil {
+			return nil, "", err
 		}
 	}
 
 	if recursion {
-		return resultMap, nil
+		if resultMap == nil {
+			resultMap = []*api.Image{}
+		}
+
+		return resultMap, next, nil
 	}
 
-	return resultString, nil
+	if mustLoadObjects {
+		resultString = make([]string, 0, len(resultMap))
+		for _, image := range resultMap {
+			resultString = append(resultString, imageURL(imageProjects[image], image.Fingerprint))
+		}
+	} else if resultString == nil {
+		resultString = []string{}
+	}
+
+	return resultString, next, nil
 }
 
 // swagger:operation GET /1.0/images?public images images_get_untrusted
@@ -1809,6 +1835,20 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1867,6 +1907,20 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1920,6 +1974,20 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve images from all projects
 //      type: boolean
@@ -1978,6 +2046,20 @@ func doImagesGet(ctx context.Context, tx *db.ClusterTx, recursion bool, projectN
 //	    type: string
 //	    example: default
 //	  - in: query
+//	    name: sort
+//	    description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//	    type: string
+//	    example: -created_at
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: continue
+//	    description: Token returned by a previous request to fetch the next page
+//	    type: string
+//	  - in: query
 //	    name: all-projects
 //	    description: Retrieve images from all projects
 //	    type: boolean
@@ -2067,9 +2149,15 @@ func imagesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
 	}
 
+	pagination, err := request.PaginationParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var result any
+	var next string
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-		result, err = doImagesGet(ctx, tx, recursion > 0, projectName, publicOnly, clauses, canViewImage, allProjects)
+		result, next, err = doImagesGet(ctx, tx, recursion > 0, projectName, publicOnly, clauses, pagination, canViewImage, allProjects)
 		if err != nil {
 			return err
 		}
@@ -2101,7 +2189,7 @@ func imagesGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
-	return response.SyncResponse(true, result)
+	return response.SyncResponseContinue(true, result, next)
 }
 
 func autoUpdateImagesTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
@@ -4909,6 +4997,226 @@ func imageExportPost(d *Daemon, r *http.Request) response.Response {
 	return response.OperationResponse(op)
 }
 
+// swagger:operation POST /1.0/images/streams images images_streams_post
+//
+//	Export images to a simplestreams tree
+//
+//	Adds images to the simplestreams tree stored in a directory on the server, initializing it if needed.
+//	Images already in the tree aren't copied again, only their aliases are updated.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: streams
+//	    description: Images to export
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/ImagesStreamsPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func imagesStreamsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName := request.ProjectParam(r)
+
+	req := api.ImagesStreamsPost{}
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if !filepath.IsAbs(req.Path) {
+		return response.BadRequest(errors.New("The tree path must be absolute"))
+	}
+
+	if len(req.Images) == 0 {
+		return response.BadRequest(errors.New("No images specified"))
+	}
+
+	// Resolve the images before starting the operation so that unknown images are reported right away.
+	images := make([]*api.Image, 0, len(req.Images))
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, image := range req.Images {
+			_, info, err := tx.GetImage(ctx, image.Fingerprint, dbCluster.ImageFilter{Project: &projectName})
+			if err != nil {
+				return fmt.Errorf("Failed loading image %q: %w", image.Fingerprint, err)
+			}
+
+			images = append(images, info)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		tree, err := simplestreams.OpenTree(req.Path)
+		if err != nil {
+			return err
+		}
+
+		for i, image := range images {
+			aliases := req.Images[i].Aliases
+			if len(aliases) == 0 {
+				for _, alias := range image.Aliases {
+					aliases = append(aliases, alias.Name)
+				}
+			}
+
+			// Only update the aliases of images already in the tree.
+			if tree.HasImage(image.Fingerprint) {
+				err = tree.AddAliases(image.Fingerprint, aliases)
+			} else {
+				err = imageStreamsAdd(ctx, s, tree, req.Path, projectName, image, aliases)
+			}
+
+			if err != nil {
+				return fmt.Errorf("Failed adding image %q to the tree: %w", image.Fingerprint, err)
+			}
+
+			err = tree.Save()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: projectName,
+		Type:        operationtype.ImagesExportStreams,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// imageStreamsAdd copies the files of the image into the directory of the tree and adds the image to the tree.
+// The files are fetched from another cluster member if the image isn't stored locally.
+func imageStreamsAdd(ctx context.Context, s *state.State, tree *simplestreams.Tree, treePath string, projectName string, image *api.Image, aliases []string) error {
+	// Copy next to the tree so the files can be moved into it.
+	metaFile, err := os.CreateTemp(treePath, ".lxd_image_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(metaFile.Name()) }()
+	defer func() { _ = metaFile.Close() }()
+
+	rootfsFile, err := os.CreateTemp(treePath, ".lxd_image_")
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(rootfsFile.Name()) }()
+	defer func() { _ = rootfsFile.Close() }()
+
+	var address string
+	err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		address, err = tx.LocateImage(ctx, image.Fingerprint)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var rootfsSize int64
+	if address != "" {
+		client, err := cluster.Connect(ctx, address, s.Endpoints.NetworkCert(), s.ServerCert(), true)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.UseProject(projectName).GetImageFile(image.Fingerprint, lxd.ImageFileRequest{
+			MetaFile:   io.WriteSeeker(metaFile),
+			RootfsFile: io.WriteSeeker(rootfsFile),
+		})
+		if err != nil {
+			return err
+		}
+
+		err = metaFile.Truncate(resp.MetaSize)
+		if err != nil {
+			return err
+		}
+
+		rootfsSize = resp.RootfsSize
+	} else {
+		copyFile := func(target *os.File, source string) (int64, error) {
+			f, err := os.Open(source)
+			if err != nil {
+				return 0, err
+			}
+
+			defer func() { _ = f.Close() }()
+
+			return io.Copy(target, f)
+		}
+
+		imagePath := filepath.Join(s.ImagesStoragePath(projectName), image.Fingerprint)
+
+		_, err = copyFile(metaFile, imagePath)
+		if err != nil {
+			return err
+		}
+
+		if shared.PathExists(imagePath + ".rootfs") {
+			rootfsSize, err = copyFile(rootfsFile, imagePath+".rootfs")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = metaFile.Close()
+	if err != nil {
+		return err
+	}
+
+	err = rootfsFile.Truncate(rootfsSize)
+	if err != nil {
+		return err
+	}
+
+	err = rootfsFile.Close()
+	if err != nil {
+		return err
+	}
+
+	rootfsPath := ""
+	if rootfsSize > 0 {
+		rootfsPath = rootfsFile.Name()
+	}
+
+	return tree.AddImage(*image, aliases, metaFile.Name(), rootfsPath)
+}
+
 // swagger:operation POST /1.0/images/{fingerprint}/secret images images_secret_post
 //
 //	Generate secret for retrieval of the image by an untrusted client
@@ -30,6 +30,7 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/oci"
 	"github.com/canonical/lxd/shared/revert"
 )
 
@@ -142,7 +143,7 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 		return err
 	}
 
-	if imgType != args.Type {
+	if imgType != args.Type.ImageType() {
 		return fmt.Errorf("Requested image's type %q does not match instance type %q", imgType, args.Type)
 	}
 
@@ -153,6 +154,16 @@ func instanceCreateFromImage(ctx context.Context, s *state.State, img *api.Image
 		}
 	}
 
+	// Apply the execution parameters of images built from OCI images, unless overridden by the instance config.
+	if args.Type == instancetype.Container {
+		for k, v := range oci.InstanceConfig(img.Properties) {
+			_, found := args.Config[k]
+			if !found {
+				args.Config[k] = v
+			}
+		}
+	}
+
 	// Set the BaseImage field (regardless of previous value).
 	args.BaseImage = img.Fingerprint
 
@@ -214,7 +225,7 @@ func instanceRebuildFromImage(ctx context.Context, s *state.State, inst instance
 		return err
 	}
 
-	if imgType != inst.Type() {
+	if imgType != inst.Type().ImageType() {
 		return fmt.Errorf("Requested image's type %q does not match instance type %q", imgType, inst.Type())
 	}
 
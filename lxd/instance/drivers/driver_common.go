@@ -31,6 +31,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/operationlock"
 	"github.com/canonical/lxd/lxd/lifecycle"
 	"github.com/canonical/lxd/lxd/locking"
+	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
@@ -838,6 +839,12 @@ func (d *common) deleteCommon(ctx context.Context, inst instance.Instance, force
 			return err
 		}
 
+	case *krun:
+		err = s.delete(ctx, force)
+		if err != nil {
+			return err
+		}
+
 	default:
 		d.logger.Error("Failed deleting instance")
 	}
@@ -1085,6 +1092,8 @@ func (d *common) snapshotCommon(ctx context.Context, inst instance.Instance, nam
 			_ = s.delete(context.Background(), true)
 		case *qemu:
 			_ = s.delete(context.Background(), true)
+		case *krun:
+			_ = s.delete(context.Background(), true)
 		default:
 			d.logger.Error("Failed deleting snapshot during revert", logger.Ctx{"snapshot": snap.Name()})
 		}
@@ -2154,6 +2163,41 @@ func (d *common) devicesRemove(inst instance.Instance) {
 	}
 }
 
+// networkStateQoS populates the QoS class of the NICs in the network state of the instance.
+// The NICs are matched on hwaddr as the interface name can differ from the device name.
+func (d *common) networkStateQoS(networks map[string]api.InstanceStateNetwork) {
+	for k, m := range d.expandedDevices {
+		if m["type"] != "nic" || m["network"] == "" || m["qos.class"] == "" {
+			continue
+		}
+
+		// Get hwaddr from static or volatile config.
+		hwaddr := m["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = d.localConfig["volatile."+k+".hwaddr"]
+		}
+
+		for netName, netStatus := range networks {
+			if netStatus.Hwaddr != hwaddr || netStatus.QoS != nil {
+				continue
+			}
+
+			// api.ProjectDefaultName is used here as the networks supporting QoS classes don't support projects.
+			n, err := network.LoadByName(d.state, api.ProjectDefaultName, m["network"])
+			if err != nil {
+				continue
+			}
+
+			netStatus.QoS, err = network.QoSClassState(n, m["qos.class"])
+			if err != nil {
+				continue
+			}
+
+			networks[netName] = netStatus
+		}
+	}
+}
+
 // updateBackupFileLock acquires the update backup file lock that protects concurrent access to actions that will call UpdateBackupFile() as part of their operation.
 func (d *common) updateBackupFileLock(ctx context.Context) (locking.UnlockFunc, error) {
 	parentName, _, _ := api.GetParentAndSnapshotName(d.Name())
@@ -2376,7 +2420,7 @@ func (d *common) validateConfig(allUpdatedDeviceKeys []string, addDevices device
 		if shared.IsTrue(oldExpandedConfig["security.protection.start"]) && shared.IsFalseOrEmpty(d.expandedConfig["security.protection.start"]) {
 			var dbVolType dbCluster.StoragePoolVolumeType
 			switch d.dbType {
-			case instancetype.Container:
+			case instancetype.Container, instancetype.Krun:
 				dbVolType = dbCluster.StoragePoolVolumeTypeContainer
 			case instancetype.VM:
 				dbVolType = dbCluster.StoragePoolVolumeTypeVM
@@ -0,0 +1,2527 @@
+package drivers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/sftp"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/backup/config"
+	"github.com/canonical/lxd/lxd/cgroup"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/device"
+	deviceConfig "github.com/canonical/lxd/lxd/device/config"
+	"github.com/canonical/lxd/lxd/device/nictype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/instance/drivers/libkrun"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/instance/operationlock"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/metrics"
+	"github.com/canonical/lxd/lxd/network"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	storagePools "github.com/canonical/lxd/lxd/storage"
+	storageDrivers "github.com/canonical/lxd/lxd/storage/drivers"
+	"github.com/canonical/lxd/lxd/subprocess"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+	"github.com/canonical/lxd/shared/revert"
+)
+
+// krunDefaultCPUs defines the default number of vCPUs a krun microVM will get if no limit specified.
+const krunDefaultCPUs = 1
+
+// krunDefaultMemSize is the default memory size for krun microVMs if no limit specified.
+const krunDefaultMemSize = "1GiB"
+
+// krunStartTimeout is the amount of time to wait for the microVM process to appear after spawning forkkrun.
+const krunStartTimeout = 10 * time.Second
+
+var errKrunAgentOffline = errors.New("LXD krun agent is not currently running")
+
+// krunLoad creates a krun instance from the supplied InstanceArgs.
+func krunLoad(s *state.State, args db.InstanceArgs, p api.Project) (instance.Instance, error) {
+	// Create the instance struct.
+	d := krunInstantiate(s, args, nil, p)
+
+	// Expand config and devices.
+	err := d.expandConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// krunInstantiate creates a krun struct without expanding config. The expandedDevices argument is
+// used during device config validation when the devices have already been expanded and we do not
+// have access to the profiles used to do it. This can be safely passed as nil if not required.
+func krunInstantiate(s *state.State, args db.InstanceArgs, expandedDevices deviceConfig.Devices, p api.Project) *krun {
+	d := &krun{
+		common: common{
+			state: s,
+
+			architecture: args.Architecture,
+			creationDate: args.CreationDate,
+			dbType:       args.Type,
+			description:  args.Description,
+			ephemeral:    args.Ephemeral,
+			expiryDate:   args.ExpiryDate,
+			id:           args.ID,
+			lastUsedDate: args.LastUsedDate,
+			localConfig:  args.Config,
+			localDevices: args.Devices,
+			logger:       logger.AddContext(logger.Ctx{"instanceType": args.Type, "instance": args.Name, "project": args.Project}),
+			name:         args.Name,
+			node:         args.Node,
+			profiles:     args.Profiles,
+			project:      p,
+			isSnapshot:   args.Snapshot,
+			stateful:     args.Stateful,
+		},
+	}
+
+	// Get the architecture name.
+	archName, err := osarch.ArchitectureName(d.architecture)
+	if err == nil {
+		d.architectureName = archName
+	}
+
+	// Cleanup the zero values.
+	if d.expiryDate.IsZero() {
+		d.expiryDate = time.Time{}
+	}
+
+	if d.creationDate.IsZero() {
+		d.creationDate = time.Time{}
+	}
+
+	if d.lastUsedDate.IsZero() {
+		d.lastUsedDate = time.Time{}
+	}
+
+	// This is passed during expanded config validation.
+	if expandedDevices != nil {
+		d.expandedDevices = expandedDevices
+	}
+
+	return d
+}
+
+// krunCreate creates a new storage volume record and returns an initialised Instance.
+// Returns a revert fail function that can be used to undo this function if a subsequent step fails.
+func krunCreate(ctx context.Context, s *state.State, args db.InstanceArgs, p api.Project) (instance.Instance, revert.Hook, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Create the instance struct.
+	d := krunInstantiate(s, args, nil, p)
+
+	if args.Snapshot {
+		d.logger.Info("Creating instance snapshot", logger.Ctx{"ephemeral": d.ephemeral})
+	} else {
+		d.logger.Info("Creating instance", logger.Ctx{"ephemeral": d.ephemeral})
+	}
+
+	// Load the config.
+	err := d.init()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed expanding config: %w", err)
+	}
+
+	// When not a snapshot, perform full validation.
+	if !args.Snapshot {
+		// Validate expanded config (allows mixed instance types for profiles).
+		err = instance.ValidConfig(s.OS, d.expandedConfig, true, instancetype.Any)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid config: %w", err)
+		}
+
+		err = instance.ValidDevices(s, d.project, d.Type(), d.localDevices, d.expandedDevices)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Invalid devices: %w", err)
+		}
+
+		err = d.validatePrivileged()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Retrieve the instance's storage pool.
+	_, rootDiskDevice, err := d.getRootDiskDevice()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting root disk: %w", err)
+	}
+
+	if rootDiskDevice["pool"] == "" {
+		return nil, nil, errors.New("The instance's root device is missing the pool property")
+	}
+
+	// Initialize the storage pool.
+	d.storagePool, err = storagePools.LoadByName(d.state, rootDiskDevice["pool"])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed loading storage pool: %w", err)
+	}
+
+	volType, err := storagePools.InstanceTypeToVolumeType(d.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !slices.Contains(d.storagePool.Driver().Info().VolumeTypes, volType) {
+		return nil, nil, errors.New("Storage pool does not support instance type")
+	}
+
+	if !d.IsSnapshot() {
+		// Add devices to instance.
+		cleanup, err := d.devicesAdd(d, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		revert.Add(cleanup)
+	}
+
+	if d.isSnapshot {
+		d.logger.Info("Created instance snapshot", logger.Ctx{"ephemeral": d.ephemeral})
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotCreated.Event(ctx, d, nil))
+	} else {
+		d.logger.Info("Created instance", logger.Ctx{"ephemeral": d.ephemeral})
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceCreated.Event(ctx, d, map[string]any{
+			"type":         api.InstanceTypeKrun,
+			"storage-pool": d.storagePool.Name(),
+			"location":     d.Location(),
+		}))
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return d, cleanup, err
+}
+
+// krun is the libkrun microVM driver.
+// The microVM boots the instance's root filesystem directly and is run by a forkkrun process which notifies
+// LXD through the stop hook once the microVM has exited.
+type krun struct {
+	common
+
+	// Cached handles.
+	architectureName string
+}
+
+func (d *krun) init() error {
+	// Compute the expanded config and device list.
+	err := d.expandConfig()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// pidFilePath returns the path of the file holding the PID of the microVM process.
+func (d *krun) pidFilePath() string {
+	return filepath.Join(d.LogPath(), "krun.pid")
+}
+
+// configFilePath returns the path of the microVM configuration file used by forkkrun.
+func (d *krun) configFilePath() string {
+	return filepath.Join(d.LogPath(), "krun.conf")
+}
+
+// agentSocketPath returns the path of the unix socket forwarded to the lxd-agent vsock port.
+func (d *krun) agentSocketPath() string {
+	return filepath.Join(d.LogPath(), "agent.sock")
+}
+
+// LogFilePath returns the instance's log path.
+func (d *krun) LogFilePath() string {
+	return filepath.Join(d.LogPath(), "krun.log")
+}
+
+// pid gets the PID of the running microVM process. Returns 0 if PID file or process not found, and -1 if err non-nil.
+func (d *krun) pid() (int, error) {
+	pidStr, err := os.ReadFile(d.pidFilePath())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil // PID file has gone.
+		}
+
+		return -1, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(pidStr)))
+	if err != nil {
+		return -1, err
+	}
+
+	cmdLine, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return 0, nil // Process has gone.
+	}
+
+	if !strings.Contains(string(cmdLine), "forkkrun") || !strings.Contains(string(cmdLine), d.configFilePath()) {
+		return -1, errors.New("PID does not match the running process")
+	}
+
+	return pid, nil
+}
+
+// pidWait waits for the microVM process to exit. Returns true if process stopped, false if timeout was exceeded.
+func (d *krun) pidWait(timeout time.Duration) bool {
+	waitUntil := time.Now().Add(timeout)
+	for {
+		pid, _ := d.pid()
+		if pid <= 0 {
+			break
+		}
+
+		if time.Now().After(waitUntil) {
+			return false
+		}
+
+		time.Sleep(time.Millisecond * 250)
+	}
+
+	return true
+}
+
+// forceStop kills the microVM process if running.
+func (d *krun) forceStop() error {
+	pid, _ := d.pid()
+	if pid > 0 {
+		err := unix.Kill(pid, unix.SIGKILL)
+		if err != nil && !errors.Is(err, unix.ESRCH) {
+			return fmt.Errorf("Failed stopping microVM process %d: %w", pid, err)
+		}
+	}
+
+	return nil
+}
+
+// signal sends a signal to the microVM process.
+func (d *krun) signal(sig unix.Signal) error {
+	pid, err := d.pid()
+	if err != nil {
+		return err
+	}
+
+	if pid <= 0 {
+		return ErrInstanceIsStopped
+	}
+
+	return unix.Kill(pid, sig)
+}
+
+// mount the instance's root volume if needed.
+func (d *krun) mount() (*storagePools.MountInfo, error) {
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.IsSnapshot() {
+		return pool.MountInstanceSnapshot(d, nil)
+	}
+
+	return pool.MountInstance(d, nil)
+}
+
+// unmount the instance's root volume if needed.
+func (d *krun) unmount() error {
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return err
+	}
+
+	return pool.UnmountInstance(d, nil)
+}
+
+// generateAgentCert creates the necessary server key and certificate if needed.
+func (d *krun) generateAgentCert() (agentCert string, agentKey string, clientCert string, clientKey string, err error) {
+	instancePath := d.Path()
+	agentCertFile := filepath.Join(instancePath, "agent.crt")
+	agentKeyFile := filepath.Join(instancePath, "agent.key")
+	clientCertFile := filepath.Join(instancePath, "agent-client.crt")
+	clientKeyFile := filepath.Join(instancePath, "agent-client.key")
+
+	// Create server certificate.
+	err = shared.FindOrGenCert(agentCertFile, agentKeyFile, false, shared.CertOptions{})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	// Create client certificate.
+	err = shared.FindOrGenCert(clientCertFile, clientKeyFile, true, shared.CertOptions{})
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	// Read all the files.
+	agentCertBytes, err := os.ReadFile(agentCertFile)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	agentKeyBytes, err := os.ReadFile(agentKeyFile)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	clientCertBytes, err := os.ReadFile(clientCertFile)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	clientKeyBytes, err := os.ReadFile(clientKeyFile)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	return string(agentCertBytes), string(agentKeyBytes), string(clientCertBytes), string(clientKeyBytes), nil
+}
+
+// generateConfigShare populates the config share exported to the microVM with the lxd-agent and its certificates.
+func (d *krun) generateConfigShare() error {
+	configSharePath := filepath.Join(d.Path(), "config")
+
+	err := os.MkdirAll(configSharePath, 0500)
+	if err != nil {
+		return err
+	}
+
+	lxdAgentSrcPath, err := exec.LookPath("lxd-agent")
+	if err != nil {
+		d.logger.Warn("lxd-agent not found, skipping its inclusion in the microVM config share", logger.Ctx{"err": err})
+	} else {
+		lxdAgentSrcPath, err = filepath.EvalSymlinks(lxdAgentSrcPath)
+		if err != nil {
+			return err
+		}
+
+		lxdAgentInstallPath := filepath.Join(configSharePath, "lxd-agent")
+		err = shared.FileCopy(lxdAgentSrcPath, lxdAgentInstallPath)
+		if err != nil {
+			return err
+		}
+
+		err = os.Chmod(lxdAgentInstallPath, 0500)
+		if err != nil {
+			return err
+		}
+	}
+
+	agentCert, agentKey, clientCert, _, err := d.generateAgentCert()
+	if err != nil {
+		return err
+	}
+
+	files := map[string]string{
+		"server.crt": clientCert,
+		"agent.crt":  agentCert,
+		"agent.key":  agentKey,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(configSharePath, name)
+
+		// Remove any existing read-only file before writing the new content.
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		err = os.WriteFile(path, []byte(content), 0400)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resources returns the number of vCPUs and the amount of memory in MiB to give the microVM.
+func (d *krun) resources() (cpus uint8, memoryMiB uint32, err error) {
+	cpuCount := krunDefaultCPUs
+	if d.expandedConfig["limits.cpu"] != "" {
+		cpuCount, err = strconv.Atoi(d.expandedConfig["limits.cpu"])
+		if err != nil {
+			return 0, 0, errors.New("CPU pinning isn't supported for krun instances, limits.cpu must be a number of CPUs")
+		}
+	}
+
+	if cpuCount < 1 || cpuCount > math.MaxUint8 {
+		return 0, 0, fmt.Errorf("Invalid CPU count %d for krun instance", cpuCount)
+	}
+
+	memory := krunDefaultMemSize
+	if d.expandedConfig["limits.memory"] != "" {
+		memory = d.expandedConfig["limits.memory"]
+	}
+
+	memoryBytes, err := parseMemoryStr(memory)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed parsing limits.memory: %w", err)
+	}
+
+	memoryMiB64 := memoryBytes / 1024 / 1024
+	if memoryMiB64 < 1 || memoryMiB64 > math.MaxUint32 {
+		return 0, 0, fmt.Errorf("Invalid memory size %q for krun instance", memory)
+	}
+
+	return uint8(cpuCount), uint32(memoryMiB64), nil
+}
+
+// environment returns the environment passed to the first process of the microVM.
+func (d *krun) environment() []string {
+	env := []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=/root",
+		"TERM=linux",
+	}
+
+	userEnv := []string{}
+	for key, value := range d.expandedConfig {
+		name, ok := strings.CutPrefix(key, "environment.")
+		if ok {
+			userEnv = append(userEnv, name+"="+value)
+		}
+	}
+
+	sort.Strings(userEnv)
+
+	return append(env, userEnv...)
+}
+
+// validateStartup checks any constraints that would prevent start up from succeeding under normal circumstances.
+func (d *krun) validateStartup(stateful bool, statusCode api.StatusCode) error {
+	err := d.common.validateStartup(statusCode)
+	if err != nil {
+		return err
+	}
+
+	if stateful {
+		return errors.New("Stateful start isn't supported for krun instances")
+	}
+
+	// Check if instance is start protected.
+	if shared.IsTrue(d.expandedConfig["security.protection.start"]) {
+		return errors.New("Instance is protected from being started")
+	}
+
+	return d.validatePrivileged()
+}
+
+// validatePrivileged checks that the instance is configured as privileged.
+// The root filesystem is shared with the microVM over virtiofs without any ID mapping, so the root user of the
+// microVM owns the files of the root filesystem on the host like in a privileged container.
+func (d *krun) validatePrivileged() error {
+	if !shared.IsTrue(d.expandedConfig["security.privileged"]) {
+		return errors.New("Krun instances require security.privileged to be set to true as their root filesystem isn't ID mapped")
+	}
+
+	return nil
+}
+
+// Start starts the instance.
+func (d *krun) Start(ctx context.Context, stateful bool, progressReporter ioprogress.ProgressReporter) (err error) {
+	unlock, err := d.updateBackupFileLock(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	d.logger.Debug("Start started", logger.Ctx{"stateful": stateful})
+	defer d.logger.Debug("Start finished", logger.Ctx{"stateful": stateful})
+
+	// Check that we are startable before creating an operation lock.
+	// Must happen before creating operation Start lock to avoid the status check returning Stopped due to the
+	// existence of a Start operation lock.
+	err = d.validateStartup(stateful, d.statusCode())
+	if err != nil {
+		return err
+	}
+
+	cpus, memoryMiB, err := d.resources()
+	if err != nil {
+		return err
+	}
+
+	// Setup a new operation.
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), operationlock.ActionStart, []operationlock.Action{operationlock.ActionRestart, operationlock.ActionRestore}, false, false)
+	if err != nil {
+		if errors.Is(err, operationlock.ErrNonReusableSucceeded) {
+			// An existing matching operation has now succeeded, return.
+			return nil
+		}
+
+		return fmt.Errorf("Failed creating instance start operation: %w", err)
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Ensure the operation is always marked as done, with the error if start failed.
+	defer func() { op.Done(err) }()
+
+	// Create all needed paths.
+	err = os.MkdirAll(d.LogPath(), 0700)
+	if err != nil {
+		return err
+	}
+
+	// Rotate the log file.
+	logFile := d.LogFilePath()
+	err = os.Rename(logFile, logFile+".old")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// Remove stale runtime files.
+	for _, path := range []string{d.pidFilePath(), d.agentSocketPath(), d.configFilePath()} {
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Failed removing stale file %q: %w", path, err)
+		}
+	}
+
+	// Mount the instance's root volume.
+	_, err = d.mount()
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = d.unmount() })
+
+	// Generate UUID if not present (do this before UpdateBackupFile() call).
+	if d.localConfig["volatile.uuid"] == "" {
+		err = d.VolatileSet(map[string]string{"volatile.uuid": uuid.New().String()})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the config share.
+	err = d.generateConfigShare()
+	if err != nil {
+		return fmt.Errorf("Failed generating config share: %w", err)
+	}
+
+	err = os.MkdirAll(d.DevicesPath(), 0711)
+	if err != nil {
+		return err
+	}
+
+	// Snapshot if needed.
+	snapName, expiry, err := d.getStartupSnapNameAndExpiry(d)
+	if err != nil {
+		return fmt.Errorf("Failed getting startup snapshot info: %w", err)
+	}
+
+	if snapName != "" && expiry != nil {
+		err = d.snapshotCommon(ctx, d, snapName, expiry, false, api.DiskVolumesModeRoot, progressReporter)
+		if err != nil {
+			return fmt.Errorf("Failed taking startup snapshot: %w", err)
+		}
+	}
+
+	krunConfig := instancetype.KrunConfig{
+		Name:       d.Name(),
+		CPUs:       cpus,
+		MemoryMiB:  memoryMiB,
+		ConfigPath: filepath.Join(d.Path(), "config"),
+		AgentPath:  d.agentSocketPath(),
+		PidPath:    d.pidFilePath(),
+		NICs:       []instancetype.KrunNIC{},
+		Env:        d.environment(),
+	}
+
+	postStartHooks := []func() error{}
+	sortedDevices := d.expandedDevices.Sorted()
+	startDevices := make([]device.Device, 0, len(sortedDevices))
+
+	// Load devices in sorted order. Loading all devices first means that validation of all devices occurs
+	// before starting any of them.
+	for _, entry := range sortedDevices {
+		dev, err := d.deviceLoad(d, entry.Name, entry.Config)
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue // Skip unsupported device (allows for mixed instance type profiles).
+			}
+
+			return fmt.Errorf("Failed start validation for device %q: %w", entry.Name, err)
+		}
+
+		// Run pre-start of check all devices before starting any device to avoid expensive revert.
+		err = dev.PreStartCheck()
+		if err != nil {
+			return fmt.Errorf("Failed pre-start check for device %q: %w", dev.Name(), err)
+		}
+
+		startDevices = append(startDevices, dev)
+	}
+
+	// Start devices in order.
+	for i := range startDevices {
+		dev := startDevices[i] // Local var for revert.
+
+		runConf, err := d.deviceStart(dev, false)
+		if err != nil {
+			return fmt.Errorf("Failed starting device %q: %w", dev.Name(), err)
+		}
+
+		revert.Add(func() {
+			err := d.deviceStop(dev, false, "")
+			if err != nil {
+				d.logger.Error("Failed cleaning up device", logger.Ctx{"device": dev.Name(), "err": err})
+			}
+		})
+
+		if runConf == nil {
+			continue
+		}
+
+		if runConf.Revert != nil {
+			revert.Add(runConf.Revert)
+		}
+
+		if runConf.RootFS.Path != "" {
+			krunConfig.RootPath = runConf.RootFS.Path
+		}
+
+		if len(runConf.NetworkInterface) > 0 {
+			nic := instancetype.KrunNIC{}
+			for _, item := range runConf.NetworkInterface {
+				switch item.Key {
+				case "link":
+					nic.Name = item.Value
+				case "hwaddr":
+					nic.Hwaddr = item.Value
+				}
+			}
+
+			if nic.Name == "" || nic.Hwaddr == "" {
+				return fmt.Errorf("Network device %q isn't supported for krun instances", dev.Name())
+			}
+
+			krunConfig.NICs = append(krunConfig.NICs, nic)
+		}
+
+		postStartHooks = append(postStartHooks, runConf.PostHooks...)
+	}
+
+	if krunConfig.RootPath == "" {
+		return errors.New("No root filesystem available for the microVM")
+	}
+
+	// Write the microVM configuration for forkkrun.
+	data, err := json.Marshal(krunConfig)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(d.configFilePath(), data, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed writing microVM configuration: %w", err)
+	}
+
+	// Update the backup.yaml file.
+	err = d.UpdateBackupFile()
+	if err != nil {
+		return err
+	}
+
+	// Start the monitor process which in turn runs the microVM.
+	forkkrunArgs := []string{"forkkrun", "start", shared.VarPath(""), d.Project().Name, d.Name(), d.configFilePath()}
+	p, err := subprocess.NewProcess(d.state.OS.ExecPath, forkkrunArgs, logFile, logFile)
+	if err != nil {
+		return fmt.Errorf("Failed creating subprocess: %w", err)
+	}
+
+	err = p.Start(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed running: %s %s: %w", d.state.OS.ExecPath, strings.Join(forkkrunArgs, " "), err)
+	}
+
+	revert.Add(func() { _ = d.forceStop() })
+
+	// Wait for the microVM process to be running.
+	pid := 0
+	waitUntil := time.Now().Add(krunStartTimeout)
+	for time.Now().Before(waitUntil) {
+		pid, _ = d.pid()
+		if pid > 0 {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if pid <= 0 {
+		return fmt.Errorf("Failed starting microVM, check %q for details", logFile)
+	}
+
+	// Run any post start hooks.
+	err = d.runHooks(postStartHooks)
+	if err != nil {
+		return err
+	}
+
+	// Record last start state.
+	err = d.recordLastState()
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Don't fire the started lifecycle event if the start is part of a restart or restore.
+	if op.Action() == operationlock.ActionStart {
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStarted.Event(ctx, d, nil))
+	}
+
+	return nil
+}
+
+// onStop is run when the instance stops.
+func (d *krun) onStop(ctx context.Context, target string) error {
+	d.logger.Debug("onStop hook started", logger.Ctx{"target": target})
+	defer d.logger.Debug("onStop hook finished", logger.Ctx{"target": target})
+
+	// Validate target.
+	if !slices.Contains([]string{"stop", "reboot"}, target) {
+		d.logger.Error("Instance sent invalid target to OnStop", logger.Ctx{"target": target})
+		return fmt.Errorf("Invalid stop target: %s", target)
+	}
+
+	// Create/pick up operation.
+	op, err := d.onStopOperationSetup(target)
+	if err != nil {
+		return err
+	}
+
+	// Unlock on return.
+	defer op.Done(nil)
+
+	// Wait for the microVM process to end (to avoid racing start when restarting).
+	waitTimeout := time.Minute
+	if !d.pidWait(waitTimeout) {
+		// Log a warning, but continue clean up as best we can.
+		d.logger.Error("MicroVM process failed stopping", logger.Ctx{"timeout": waitTimeout})
+	}
+
+	// Record power state.
+	err = d.VolatileSet(map[string]string{
+		"volatile.last_state.power": instance.PowerStateStopped,
+		"volatile.last_state.ready": "false",
+	})
+	if err != nil {
+		// Don't return an error here as we still want to cleanup the instance even if DB not available.
+		d.logger.Error("Failed recording last power state", logger.Ctx{"err": err})
+	}
+
+	// Cleanup.
+	d.cleanupDevices() // Must be called before unmount.
+	_ = os.Remove(d.pidFilePath())
+	_ = os.Remove(d.agentSocketPath())
+	_ = os.Remove(d.configFilePath())
+
+	// Stop the storage for the instance.
+	err = d.unmount()
+	if err != nil && !errors.Is(err, storageDrivers.ErrInUse) {
+		err = fmt.Errorf("Failed unmounting instance: %w", err)
+		op.Done(err)
+		return err
+	}
+
+	// Log and emit lifecycle if not user triggered.
+	if op.GetInstanceInitiated() {
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceShutdown.Event(ctx, d, nil))
+	} else {
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceStopped.Event(ctx, d, nil))
+	}
+
+	// Reboot the instance.
+	if target == "reboot" {
+		err = d.Start(ctx, false, nil)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestarted.Event(ctx, d, nil))
+	} else if d.ephemeral {
+		// Destroy ephemeral instances.
+		err = d.delete(ctx, true)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// OnHook is the top-level hook handler.
+func (d *krun) OnHook(hookName string, args map[string]string) error {
+	switch hookName {
+	case instance.HookStop:
+		return d.onStop(context.Background(), args["target"])
+	default:
+		return instance.ErrNotImplemented
+	}
+}
+
+// Stop the instance.
+func (d *krun) Stop(ctx context.Context, stateful bool) error {
+	d.logger.Debug("Stop started", logger.Ctx{"stateful": stateful})
+	defer d.logger.Debug("Stop finished", logger.Ctx{"stateful": stateful})
+
+	// Must be run prior to creating the operation lock.
+	// Allow to proceed if statusCode is Error or Frozen as we may need to forcefully kill the microVM process.
+	statusCode := d.statusCode()
+	if !d.isRunningStatusCode(statusCode) && statusCode != api.Error && statusCode != api.Frozen {
+		return ErrInstanceIsStopped
+	}
+
+	if stateful {
+		return errors.New("Stateful stop isn't supported for krun instances")
+	}
+
+	// Setup a new operation.
+	// Allow inheriting of ongoing restart or restore operation (we are called from restartCommon and Restore).
+	// Allow reuse of a reusable ongoing stop operation as Shutdown() may be called first.
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), operationlock.ActionStop, []operationlock.Action{operationlock.ActionRestart, operationlock.ActionRestore}, false, true)
+	if err != nil {
+		if errors.Is(err, operationlock.ErrNonReusableSucceeded) {
+			// An existing matching operation has now succeeded, return.
+			return nil
+		}
+
+		return err
+	}
+
+	err = d.forceStop()
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
+	// Wait for operation lock to be Done. This is normally completed by the stop hook which is run by the
+	// forkkrun process once the microVM has exited. If the hook doesn't run in time (for example because the
+	// forkkrun process has gone), perform the cleanup directly.
+	waitCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = op.Wait(waitCtx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		d.logger.Warn("Timed out waiting for stop hook, cleaning up instance")
+		err = d.onStop(ctx, "stop")
+	}
+
+	status := d.statusCode()
+	if status != api.Stopped {
+		errPrefix := fmt.Errorf("Failed stopping instance, status is %q", status)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", errPrefix.Error(), err)
+		}
+
+		return errPrefix
+	}
+
+	// Now handle errors from stop sequence and return to caller if wasn't completed cleanly.
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Shutdown shuts the instance down by requesting a power off through the lxd-agent.
+func (d *krun) Shutdown(ctx context.Context, timeout time.Duration) error {
+	d.logger.Debug("Shutdown started", logger.Ctx{"timeout": timeout})
+	defer d.logger.Debug("Shutdown finished", logger.Ctx{"timeout": timeout})
+
+	// Must be run prior to creating the operation lock.
+	statusCode := d.statusCode()
+	if !d.isRunningStatusCode(statusCode) {
+		if statusCode == api.Error {
+			return fmt.Errorf("The instance cannot be cleanly shutdown as in %s status", statusCode)
+		}
+
+		return ErrInstanceIsStopped
+	}
+
+	// Setup a new operation.
+	// Allow inheriting of ongoing restart operation (we are called from restartCommon).
+	// Allow reuse when creating a new stop operation. This allows the Stop() function to inherit operation.
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), operationlock.ActionStop, []operationlock.Action{operationlock.ActionRestart}, true, true)
+	if err != nil {
+		if errors.Is(err, operationlock.ErrNonReusableSucceeded) {
+			// An existing matching operation has now succeeded, return.
+			return nil
+		}
+
+		return err
+	}
+
+	// If frozen, resume so the request can be handled.
+	if d.IsFrozen() {
+		err := d.Unfreeze(ctx)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
+	// Indicate to the onStop hook that if the instance stops it was due to a clean shutdown.
+	op.SetInstanceInitiated(true)
+
+	err = d.agentPoweroff()
+	if err != nil {
+		err = fmt.Errorf("Failed requesting shutdown: %w", err)
+		op.Done(err)
+		return err
+	}
+
+	d.logger.Debug("Shutdown request sent to instance")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Wait for operation lock to be Done or context to timeout. The operation lock is normally completed by
+	// onStop which picks up the same lock and then marks it as Done after the instance stops and the devices
+	// have been cleaned up. However if the operation has failed for another reason we collect the error here.
+	err = op.Wait(ctx)
+	status := d.statusCode()
+	if status != api.Stopped {
+		errPrefix := fmt.Errorf("Failed shutting down instance, status is %q", status)
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", errPrefix.Error(), err)
+		}
+
+		return errPrefix
+	}
+
+	// Now handle errors from shutdown sequence and return to caller if wasn't completed cleanly.
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// agentPoweroff asks the lxd-agent to power off the guest.
+func (d *krun) agentPoweroff() error {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentConnectTimeout)
+	defer cancel()
+
+	agent, err := lxd.ConnectLXDHTTPWithContext(ctx, nil, client)
+	if err != nil {
+		return fmt.Errorf("Failed connecting to lxd-agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	// Don't wait for the command to complete as the agent goes away with the guest.
+	_, err = agent.ExecInstance("", api.InstanceExecPost{Command: []string{"poweroff"}}, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Restart restart the instance.
+func (d *krun) Restart(ctx context.Context, timeout time.Duration, progressReporter ioprogress.ProgressReporter) error {
+	return d.restartCommon(ctx, d, timeout, progressReporter)
+}
+
+// Rebuild rebuilds the instance using the supplied image fingerprint as source.
+func (d *krun) Rebuild(ctx context.Context, img *api.Image, op *operations.Operation) error {
+	return d.rebuildCommon(ctx, d, img, op)
+}
+
+// Freeze pauses the microVM process.
+func (d *krun) Freeze(ctx context.Context) error {
+	err := d.signal(unix.SIGSTOP)
+	if err != nil {
+		return fmt.Errorf("Failed pausing microVM: %w", err)
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstancePaused.Event(ctx, d, nil))
+	return nil
+}
+
+// Unfreeze resumes the microVM process.
+func (d *krun) Unfreeze(ctx context.Context) error {
+	err := d.signal(unix.SIGCONT)
+	if err != nil {
+		return fmt.Errorf("Failed resuming microVM: %w", err)
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceResumed.Event(ctx, d, nil))
+	return nil
+}
+
+// RegisterDevices calls the Register() function on all of the instance's devices.
+func (d *krun) RegisterDevices() {
+	d.devicesRegister(d)
+}
+
+// deviceStart loads a new device and calls its Start() function.
+func (d *krun) deviceStart(dev device.Device, instanceRunning bool) (*deviceConfig.RunConfig, error) {
+	configCopy := dev.Config()
+	l := d.logger.AddContext(logger.Ctx{"device": dev.Name(), "type": configCopy["type"]})
+	l.Debug("Starting device")
+
+	// Devices are passed to libkrun when the microVM is started and can't be hotplugged.
+	if instanceRunning {
+		return nil, errors.New("Device cannot be started when krun instance is running")
+	}
+
+	return dev.Start()
+}
+
+// deviceStop loads a new device and calls its Stop() function.
+func (d *krun) deviceStop(dev device.Device, instanceRunning bool, _ string) error {
+	configCopy := dev.Config()
+	l := d.logger.AddContext(logger.Ctx{"device": dev.Name(), "type": configCopy["type"]})
+	l.Debug("Stopping device")
+
+	if instanceRunning {
+		return errors.New("Device cannot be stopped when krun instance is running")
+	}
+
+	runConf, err := dev.Stop()
+	if err != nil {
+		return err
+	}
+
+	if runConf != nil {
+		// Run post stop hooks irrespective of run state of instance.
+		err = d.runHooks(runConf.PostHooks)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanup removes leftover paths when the instance is renamed or deleted.
+func (d *krun) cleanup() {
+	// Unmount any leftovers.
+	_ = d.removeDiskDevices()
+
+	// Remove the devices path.
+	_ = os.Remove(d.DevicesPath())
+}
+
+// cleanupDevices performs any needed device cleanup steps when instance is stopped.
+// Must be called before root volume is unmounted.
+func (d *krun) cleanupDevices() {
+	for _, entry := range d.expandedDevices.Reversed() {
+		dev, err := d.deviceLoad(d, entry.Name, entry.Config)
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue // Skip unsupported device (allows for mixed instance type profiles).
+			}
+
+			// Just log an error, but still allow the device to be stopped if usable device returned.
+			d.logger.Error("Failed stop validation for device", logger.Ctx{"device": entry.Name, "err": err})
+		}
+
+		if dev != nil {
+			err = d.deviceStop(dev, false, "")
+			if err != nil {
+				d.logger.Error("Failed stopping device", logger.Ctx{"device": dev.Name(), "err": err})
+			}
+		}
+	}
+}
+
+// IsPrivileged returns whether the instance is privileged.
+// Krun instances are always privileged once started, see validatePrivileged.
+func (d *krun) IsPrivileged() bool {
+	return shared.IsTrue(d.expandedConfig["security.privileged"])
+}
+
+// Snapshot takes a new snapshot.
+func (d *krun) Snapshot(ctx context.Context, name string, expiry *time.Time, stateful bool, diskVolumesMode string, progressReporter ioprogress.ProgressReporter) error {
+	if stateful {
+		return errors.New("Stateful snapshots aren't supported for krun instances")
+	}
+
+	unlock, err := d.updateBackupFileLock(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	return d.snapshotCommon(ctx, d, name, expiry, false, diskVolumesMode, progressReporter)
+}
+
+// Restore restores an instance snapshot.
+func (d *krun) Restore(ctx context.Context, source instance.Instance, stateful bool, diskVolumesMode string, progressReporter ioprogress.ProgressReporter) error {
+	if stateful {
+		return errors.New("Stateful restore isn't supported for krun instances")
+	}
+
+	ctxMap := logger.Ctx{
+		"created":   d.creationDate,
+		"ephemeral": d.ephemeral,
+		"used":      d.lastUsedDate,
+		"source":    source.Name(),
+	}
+
+	d.logger.Info("Restoring instance", ctxMap)
+
+	wasRunning, op, err := d.restoreCommon(ctx, d, source, diskVolumesMode, progressReporter)
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
+	// Restart the instance.
+	if wasRunning {
+		d.logger.Debug("Starting instance after snapshot restore")
+		err := d.Start(ctx, false, progressReporter)
+		if err != nil {
+			op.Done(err)
+			return err
+		}
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRestored.Event(ctx, d, map[string]any{"snapshot": source.Name()}))
+	d.logger.Info("Restored instance", ctxMap)
+	return nil
+}
+
+// Rename the instance. Accepts an argument to enable applying deferred TemplateTriggerRename.
+func (d *krun) Rename(ctx context.Context, newName string, applyTemplateTrigger bool) error {
+	unlock, err := d.updateBackupFileLock(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	oldName := d.Name()
+	ctxMap := logger.Ctx{
+		"created":   d.creationDate,
+		"ephemeral": d.ephemeral,
+		"used":      d.lastUsedDate,
+		"newname":   newName}
+
+	d.logger.Info("Renaming instance", ctxMap)
+
+	// Quick checks.
+	err = instancetype.ValidName(newName, d.IsSnapshot())
+	if err != nil {
+		return err
+	}
+
+	if d.IsRunning() {
+		return errors.New("Renaming of running instance not allowed")
+	}
+
+	// Clean things up.
+	d.cleanup()
+
+	pool, err := storagePools.LoadByInstance(d.state, d)
+	if err != nil {
+		return fmt.Errorf("Failed loading instance storage pool: %w", err)
+	}
+
+	if d.IsSnapshot() {
+		_, newSnapName, _ := api.GetParentAndSnapshotName(newName)
+		err = pool.RenameInstanceSnapshot(d, newSnapName, nil)
+		if err != nil {
+			return fmt.Errorf("Rename instance snapshot: %w", err)
+		}
+	} else {
+		err = pool.RenameInstance(d, newName, nil)
+		if err != nil {
+			return fmt.Errorf("Rename instance: %w", err)
+		}
+
+		if applyTemplateTrigger {
+			err = d.DeferTemplateApply(instance.TemplateTriggerRename)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Rename the instance and snapshot database entries.
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		if d.IsSnapshot() {
+			oldParent, oldSnap, _ := strings.Cut(oldName, shared.SnapshotDelimiter)
+			_, newSnap, _ := strings.Cut(newName, shared.SnapshotDelimiter)
+			return dbCluster.RenameInstanceSnapshot(ctx, tx.Tx(), d.project.Name, oldParent, oldSnap, newSnap)
+		}
+
+		return dbCluster.RenameInstance(ctx, tx.Tx(), d.project.Name, oldName, newName)
+	})
+	if err != nil {
+		d.logger.Error("Failed renaming instance", ctxMap)
+		return err
+	}
+
+	// Rename the logging path.
+	newFullName := project.Instance(d.Project().Name, newName)
+	_ = os.RemoveAll(shared.LogPath(newFullName))
+	err = os.Rename(d.LogPath(), shared.LogPath(newFullName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		d.logger.Error("Failed renaming instance", ctxMap)
+		return err
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Set the new name in the struct.
+	d.name = newName
+	revert.Add(func() { d.name = oldName })
+
+	// Rename the backups.
+	backups, err := d.Backups()
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		b := backup
+		oldName := b.Name()
+		_, backupName, _ := strings.Cut(oldName, "/")
+		newName := newName + "/" + backupName
+
+		err = b.Rename(ctx, newName)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = b.Rename(context.Background(), oldName) })
+	}
+
+	// Update lease files.
+	err = network.UpdateDNSMasqStatic(d.state, "")
+	if err != nil {
+		return err
+	}
+
+	// Update the backup file.
+	err = d.UpdateBackupFile()
+	if err != nil {
+		return err
+	}
+
+	d.logger.Info("Renamed instance", ctxMap)
+
+	if d.isSnapshot {
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotRenamed.Event(ctx, d, map[string]any{"old_name": oldName}))
+	} else {
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceRenamed.Event(ctx, d, map[string]any{"old_name": oldName}))
+	}
+
+	revert.Success()
+	return nil
+}
+
+// Update the instance config.
+func (d *krun) Update(ctx context.Context, args db.InstanceArgs, actionType instance.UpdateAction) error {
+	userRequested := d.isUserRequested(actionType)
+
+	unlock, err := d.updateBackupFileLock(context.Background())
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	// Setup a new operation.
+	op, err := operationlock.CreateWaitGet(d.Project().Name, d.Name(), operationlock.ActionUpdate, []operationlock.Action{operationlock.ActionRestart, operationlock.ActionRestore}, false, false)
+	if err != nil {
+		return fmt.Errorf("Failed creating instance update operation: %w", err)
+	}
+
+	defer op.Done(nil)
+
+	// Setup the reverter.
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Set sane defaults for unset keys.
+	if args.Project == "" {
+		args.Project = api.ProjectDefaultName
+	}
+
+	if args.Architecture == 0 {
+		args.Architecture = d.architecture
+	}
+
+	if args.Config == nil {
+		args.Config = map[string]string{}
+	}
+
+	if args.Devices == nil {
+		args.Devices = deviceConfig.Devices{}
+	}
+
+	if args.Profiles == nil {
+		args.Profiles = []api.Profile{}
+	}
+
+	if userRequested {
+		// Validate the new config.
+		err := instance.ValidConfig(d.state.OS, args.Config, false, d.dbType)
+		if err != nil {
+			return fmt.Errorf("Invalid config: %w", err)
+		}
+
+		// Validate the new devices without using expanded devices validation (expensive checks disabled).
+		err = instance.ValidDevices(d.state, d.project, d.Type(), args.Devices, nil)
+		if err != nil {
+			return fmt.Errorf("Invalid devices: %w", err)
+		}
+	}
+
+	var profiles []string
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Validate the new profiles.
+		profiles, err = tx.GetProfileNames(ctx, args.Project)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting profiles: %w", err)
+	}
+
+	checkedProfiles := []string{}
+	for _, profile := range args.Profiles {
+		if !slices.Contains(profiles, profile.Name) {
+			return fmt.Errorf("Requested profile %q does not exist", profile.Name)
+		}
+
+		if slices.Contains(checkedProfiles, profile.Name) {
+			return errors.New("Duplicate profile found in request")
+		}
+
+		checkedProfiles = append(checkedProfiles, profile.Name)
+	}
+
+	// Validate the new architecture.
+	if args.Architecture != 0 {
+		_, err = osarch.ArchitectureName(args.Architecture)
+		if err != nil {
+			return fmt.Errorf("Invalid architecture ID: %w", err)
+		}
+	}
+
+	// Get a copy of the old configuration.
+	oldDescription := d.Description()
+	oldArchitecture := d.architecture
+	oldEphemeral := d.ephemeral
+	oldExpiryDate := d.expiryDate
+
+	oldExpandedDevices := deviceConfig.Devices{}
+	err = shared.DeepCopy(&d.expandedDevices, &oldExpandedDevices)
+	if err != nil {
+		return err
+	}
+
+	oldExpandedConfig := map[string]string{}
+	err = shared.DeepCopy(&d.expandedConfig, &oldExpandedConfig)
+	if err != nil {
+		return err
+	}
+
+	oldLocalDevices := deviceConfig.Devices{}
+	err = shared.DeepCopy(&d.localDevices, &oldLocalDevices)
+	if err != nil {
+		return err
+	}
+
+	oldLocalConfig := map[string]string{}
+	err = shared.DeepCopy(&d.localConfig, &oldLocalConfig)
+	if err != nil {
+		return err
+	}
+
+	oldProfiles := []api.Profile{}
+	err = shared.DeepCopy(&d.profiles, &oldProfiles)
+	if err != nil {
+		return err
+	}
+
+	// Revert local changes if update fails.
+	revert.Add(func() {
+		d.description = oldDescription
+		d.architecture = oldArchitecture
+		d.ephemeral = oldEphemeral
+		d.expandedConfig = oldExpandedConfig
+		d.expandedDevices = oldExpandedDevices
+		d.localConfig = oldLocalConfig
+		d.localDevices = oldLocalDevices
+		d.profiles = oldProfiles
+		d.expiryDate = oldExpiryDate
+	})
+
+	// Apply the various changes to local vars.
+	d.description = args.Description
+	d.architecture = args.Architecture
+	d.ephemeral = args.Ephemeral
+	d.localConfig = args.Config
+	d.localDevices = args.Devices
+	d.profiles = args.Profiles
+	d.expiryDate = args.ExpiryDate
+
+	// Expand the config.
+	err = d.expandConfig()
+	if err != nil {
+		return err
+	}
+
+	// Diff the configurations.
+	changedConfig := []string{}
+	for key := range oldExpandedConfig {
+		if oldExpandedConfig[key] != d.expandedConfig[key] && !slices.Contains(changedConfig, key) {
+			changedConfig = append(changedConfig, key)
+		}
+	}
+
+	for key := range d.expandedConfig {
+		if oldExpandedConfig[key] != d.expandedConfig[key] && !slices.Contains(changedConfig, key) {
+			changedConfig = append(changedConfig, key)
+		}
+	}
+
+	// Diff the devices.
+	removeDevices, addDevices, updateDevices, allUpdatedDeviceKeys := oldExpandedDevices.Update(d.expandedDevices, func(oldDevice deviceConfig.Device, newDevice deviceConfig.Device) []string {
+		// This function needs to return a list of fields that are excluded from differences
+		// between oldDevice and newDevice. The result of this is that as long as the
+		// devices are otherwise identical except for the fields returned here, then the
+		// device is considered to be being "updated" rather than "added & removed".
+		oldDevType, err := device.LoadByType(d.state, d.Project().Name, oldDevice)
+		if err != nil {
+			return []string{} // Could not create Device, so this cannot be an update.
+		}
+
+		newDevType, err := device.LoadByType(d.state, d.Project().Name, newDevice)
+		if err != nil {
+			return []string{} // Could not create Device, so this cannot be an update.
+		}
+
+		return newDevType.UpdatableFields(oldDevType)
+	})
+
+	err = d.validateConfig(allUpdatedDeviceKeys, addDevices, removeDevices, oldExpandedDevices, changedConfig, oldExpandedConfig, actionType)
+	if err != nil {
+		return err
+	}
+
+	isRunning := d.IsRunning()
+
+	// Use the device interface to apply update changes.
+	_, err = d.devicesUpdate(d, removeDevices, addDevices, updateDevices, oldExpandedDevices, isRunning, userRequested)
+	if err != nil {
+		return err
+	}
+
+	if isRunning {
+		// The microVM resources are fixed at start time, so only keys which don't affect it can change.
+		liveUpdateKeyPrefixes := []string{
+			"boot.",
+			"cloud-init.",
+			"image.",
+			"snapshots.",
+			"user.",
+			"volatile.",
+		}
+
+		for _, key := range changedConfig {
+			if !shared.StringHasPrefix(key, liveUpdateKeyPrefixes...) {
+				return fmt.Errorf("Key %q cannot be updated when krun instance is running", key)
+			}
+		}
+	}
+
+	// Re-generate the instance-id if needed.
+	if !d.IsSnapshot() && d.needsNewInstanceID(changedConfig, oldExpandedDevices) {
+		err = d.resetInstanceID()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Finally, apply the changes to the database.
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Snapshots should update only their descriptions and expiry date.
+		if d.IsSnapshot() {
+			return tx.UpdateInstanceSnapshot(d.id, d.description, d.expiryDate)
+		}
+
+		object, err := dbCluster.GetInstance(ctx, tx.Tx(), d.project.Name, d.name)
+		if err != nil {
+			return err
+		}
+
+		object.Description = d.description
+		object.Architecture = d.architecture
+		object.Ephemeral = d.ephemeral
+		object.ExpiryDate = sql.NullTime{Time: d.expiryDate, Valid: true}
+
+		err = dbCluster.UpdateInstance(ctx, tx.Tx(), d.project.Name, d.name, *object)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateInstanceConfig(ctx, tx.Tx(), int64(object.ID), d.localConfig)
+		if err != nil {
+			return err
+		}
+
+		// Do not store initial.* device config keys in database.
+		initialDevicesConfig := d.localDevices.CutInitialConfig()
+		defer func() { initialDevicesConfig.Copy(d.localDevices) }() // Restore after DB transaction.
+
+		devices, err := dbCluster.APIToDevices(d.localDevices.CloneNative())
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateInstanceDevices(ctx, tx.Tx(), int64(object.ID), devices)
+		if err != nil {
+			return err
+		}
+
+		profileNames := make([]string, 0, len(d.profiles))
+		for _, profile := range d.profiles {
+			profileNames = append(profileNames, profile.Name)
+		}
+
+		return dbCluster.UpdateInstanceProfiles(ctx, tx.Tx(), object.ID, object.Project, profileNames)
+	})
+	if err != nil {
+		return fmt.Errorf("Failed updating database: %w", err)
+	}
+
+	err = d.UpdateBackupFile()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("Failed writing backup file: %w", err)
+	}
+
+	// Changes have been applied and recorded, do not revert if an error occurs from here.
+	revert.Success()
+
+	if userRequested {
+		if d.isSnapshot {
+			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotUpdated.Event(ctx, d, nil))
+		} else {
+			d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceUpdated.Event(ctx, d, nil))
+		}
+	}
+
+	return nil
+}
+
+// Delete the instance.
+func (d *krun) Delete(ctx context.Context, force bool, diskVolumesMode string, progressReporter ioprogress.ProgressReporter) error {
+	return d.deleteCommon(ctx, d, force, diskVolumesMode, progressReporter)
+}
+
+// Delete the instance without creating an operation lock.
+func (d *krun) delete(ctx context.Context, force bool) error {
+	ctxMap := logger.Ctx{
+		"created":   d.creationDate,
+		"ephemeral": d.ephemeral,
+		"used":      d.lastUsedDate}
+
+	if d.isSnapshot {
+		d.logger.Info("Deleting instance snapshot", ctxMap)
+	} else {
+		d.logger.Info("Deleting instance", ctxMap)
+	}
+
+	// Check if instance is delete protected.
+	if !force && shared.IsTrue(d.expandedConfig["security.protection.delete"]) && !d.IsSnapshot() {
+		return errors.New("Instance is protected from being deleted")
+	}
+
+	// Delete any persistent warnings for instance.
+	err := d.warningsDelete()
+	if err != nil {
+		return err
+	}
+
+	// Attempt to initialize storage interface for the instance.
+	pool, err := d.getStoragePool()
+	if err != nil && !response.IsNotFoundError(err) {
+		return err
+	} else if pool != nil {
+		if d.IsSnapshot() {
+			// Remove snapshot volume and database record.
+			err = pool.DeleteInstanceSnapshot(d, nil)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Remove all snapshots.
+			err := d.deleteSnapshots(func(snapInst instance.Instance) error {
+				return snapInst.(*krun).delete(ctx, true) // Internal delete function that does not lock.
+			})
+			if err != nil {
+				return fmt.Errorf("Failed deleting instance snapshots: %w", err)
+			}
+
+			// Remove the storage volume and database records.
+			err = pool.DeleteInstance(d, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Perform other cleanup steps if not snapshot.
+	if !d.IsSnapshot() {
+		// Remove all backups.
+		backups, err := d.Backups()
+		if err != nil {
+			return err
+		}
+
+		for _, backup := range backups {
+			err = backup.Delete(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Run device removal function for each device.
+		d.devicesRemove(d)
+
+		// Clean things up.
+		d.cleanup()
+
+		// Remove the log directory. Not handled by cleanup() as that is
+		// also called during Rename() where logs should be preserved.
+		_ = os.RemoveAll(d.LogPath())
+	}
+
+	err = d.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		// Remove the database record of the instance or snapshot instance.
+		return tx.DeleteInstance(ctx, d.Project().Name, d.Name())
+	})
+	if err != nil {
+		d.logger.Error("Failed deleting instance entry", logger.Ctx{"project": d.Project().Name})
+		return err
+	}
+
+	if d.isSnapshot {
+		d.logger.Info("Deleted instance snapshot", ctxMap)
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceSnapshotDeleted.Event(ctx, d, nil))
+	} else {
+		d.logger.Info("Deleted instance", ctxMap)
+		d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceDeleted.Event(ctx, d, nil))
+	}
+
+	return nil
+}
+
+// Export is not supported for krun instances.
+func (d *krun) Export(w io.Writer, properties map[string]string, expiration time.Time, tracker *ioprogress.ProgressTracker) (api.ImageMetadata, error) {
+	return api.ImageMetadata{}, instance.ErrNotImplemented
+}
+
+// MigrateSend is not supported for krun instances.
+func (d *krun) MigrateSend(ctx context.Context, args instance.MigrateSendArgs, progressReporter ioprogress.ProgressReporter) error {
+	return instance.ErrNotImplemented
+}
+
+// MigrateReceive is not supported for krun instances.
+func (d *krun) MigrateReceive(ctx context.Context, args instance.MigrateReceiveArgs, progressReporter ioprogress.ProgressReporter) error {
+	return instance.ErrNotImplemented
+}
+
+// ConversionReceive is not supported for krun instances.
+func (d *krun) ConversionReceive(args instance.ConversionReceiveArgs, progressReporter ioprogress.ProgressReporter) error {
+	return instance.ErrNotImplemented
+}
+
+// CanMigrate returns whether the instance can be migrated. Migration isn't supported for krun instances.
+func (d *krun) CanMigrate() (canMigrate bool, live bool) {
+	return false, false
+}
+
+// CGroup is not implemented for krun instances.
+func (d *krun) CGroup() (*cgroup.CGroup, error) {
+	return nil, instance.ErrNotImplemented
+}
+
+// SetAffinity is not supported for krun instances as CPU pinning isn't available.
+func (d *krun) SetAffinity(set []string) error {
+	return nil
+}
+
+// getAgentClient returns an HTTP client connected to the lxd-agent through the forwarded vsock socket.
+func (d *krun) getAgentClient() (*http.Client, error) {
+	agentSocketPath := d.agentSocketPath()
+	if !shared.PathExists(agentSocketPath) {
+		return nil, errKrunAgentOffline
+	}
+
+	// The connection uses mutual authentication, so use the LXD server's key & cert for client.
+	agentCert, _, clientCert, clientKey, err := d.generateAgentCert()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := shared.GetTLSConfigMem(clientCert, clientKey, "", agentCert, false)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{}
+	client.Transport = &http.Transport{
+		TLSClientConfig: tlsConfig,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", agentSocketPath)
+		},
+		DisableKeepAlives:     true,
+		ExpectContinueTimeout: time.Second * 30,
+		ResponseHeaderTimeout: time.Second * 3600,
+		TLSHandshakeTimeout:   time.Second * 5,
+	}
+
+	return client, nil
+}
+
+// FileSFTPConn returns a connection to the agent SFTP endpoint.
+func (d *krun) FileSFTPConn() (net.Conn, error) {
+	// MicroVMs can only perform file operations while running and using the lxd-agent.
+	if !d.IsRunning() {
+		return nil, errors.New("Instance is not running")
+	}
+
+	// Connect to the agent.
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the HTTP transport.
+	httpTransport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		return nil, errors.New("FileSFTP transport is an invalid HTTP transport")
+	}
+
+	// Send the upgrade request.
+	u, err := url.Parse("https://custom.socket/1.0/sftp")
+	if err != nil {
+		return nil, err
+	}
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Host:       u.Host,
+	}
+
+	req.Header["Upgrade"] = []string{"sftp"}
+	req.Header["Connection"] = []string{"Upgrade"}
+
+	conn, err := httpTransport.DialContext(context.Background(), "unix", d.agentSocketPath())
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConn := tls.Client(conn, httpTransport.TLSClientConfig)
+	err = tlsConn.Handshake()
+	if err != nil {
+		return nil, err
+	}
+
+	err = req.Write(tlsConn)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(tlsConn), req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("Dialing failed: expected status code 101 got %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get("Upgrade") != "sftp" {
+		return nil, errors.New("Missing or unexpected Upgrade header in response")
+	}
+
+	return tlsConn, nil
+}
+
+// FileSFTP returns an SFTP connection to the agent endpoint.
+func (d *krun) FileSFTP() (*sftp.Client, error) {
+	conn, err := d.FileSFTPConn()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get a SFTP client.
+	client, err := sftp.NewClientPipe(conn, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	go func() {
+		// Wait for the client to be done before closing the connection.
+		_ = client.Wait()
+		_ = conn.Close()
+	}()
+
+	return client, nil
+}
+
+// Console is not supported for krun instances, the console output is written to the instance log.
+func (d *krun) Console(ctx context.Context, protocol string) (*os.File, chan error, error) {
+	return nil, nil, errors.New("Console isn't supported for krun instances")
+}
+
+// Exec a command inside the instance.
+func (d *krun) Exec(ctx context.Context, req api.InstanceExecPost, stdin *os.File, stdout *os.File, stderr *os.File) (instance.Cmd, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed connecting to lxd-agent", logger.Ctx{"err": err})
+		return nil, errors.New("Failed connecting to lxd-agent")
+	}
+
+	revert.Add(agent.Disconnect)
+
+	dataDone := make(chan bool)
+	controlSendCh := make(chan api.InstanceExecControl)
+	controlResCh := make(chan error)
+
+	// This is the signal control handler, it receives signals from lxc CLI and forwards them to the agent.
+	controlHandler := func(control *websocket.Conn) {
+		closeMsg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		defer func() { _ = control.WriteMessage(websocket.CloseMessage, closeMsg) }()
+
+		for {
+			select {
+			case cmd := <-controlSendCh:
+				controlResCh <- control.WriteJSON(cmd)
+			case <-dataDone:
+				return
+			}
+		}
+	}
+
+	args := lxd.InstanceExecArgs{
+		Stdin:    stdin,
+		Stdout:   stdout,
+		Stderr:   stderr,
+		DataDone: dataDone,
+		Control:  controlHandler,
+	}
+
+	// Always needed for agent exec, as even for non-websocket requests from the client we need to connect the
+	// websockets for control and for capturing output to a file on the LXD server.
+	req.WaitForWS = true
+
+	// Similarly, output recording is performed on the host rather than in the guest, so clear that bit from the request.
+	req.RecordOutput = false
+
+	op, err := agent.ExecInstance("", req, &args)
+	if err != nil {
+		return nil, err
+	}
+
+	instCmd := &qemuCmd{
+		cmd:              op,
+		attachedChildPid: 0, // Process is not running on LXD host.
+		dataDone:         args.DataDone,
+		cleanupFunc:      revert.Clone().Fail, // Pass revert function clone as clean up function.
+		controlSendCh:    controlSendCh,
+		controlResCh:     controlResCh,
+	}
+
+	d.state.Events.SendLifecycle(d.project.Name, lifecycle.InstanceExec.Event(ctx, d, logger.Ctx{"command": req.Command}))
+
+	revert.Success()
+	return instCmd, nil
+}
+
+// Render returns info about the instance.
+func (d *krun) Render(options ...func(response any) error) (state any, etag any, err error) {
+	profileNames := make([]string, 0, len(d.profiles))
+	for _, profile := range d.profiles {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	if d.IsSnapshot() {
+		// Prepare the ETag
+		etag := []any{d.expiryDate}
+
+		snapState := api.InstanceSnapshot{
+			Name:            strings.SplitN(d.name, "/", 2)[1],
+			Architecture:    d.architectureName,
+			Profiles:        profileNames,
+			Config:          d.localConfig,
+			ExpandedConfig:  d.expandedConfig,
+			Devices:         d.localDevices.CloneNative(),
+			ExpandedDevices: d.expandedDevices.CloneNative(),
+			CreatedAt:       d.creationDate,
+			LastUsedAt:      d.lastUsedDate,
+			ExpiresAt:       d.expiryDate,
+			Ephemeral:       d.ephemeral,
+			Stateful:        d.stateful,
+
+			// Default to uninitialised/error state (0 means no CoW usage).
+			// The size can then be populated optionally via the options argument.
+			Size: -1,
+		}
+
+		for _, option := range options {
+			err := option(&snapState)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return &snapState, etag, nil
+	}
+
+	// Prepare the ETag
+	etag = []any{d.architecture, d.localConfig, d.localDevices, d.ephemeral, d.profiles}
+
+	instState := api.Instance{
+		Name:            d.name,
+		Description:     d.description,
+		Architecture:    d.architectureName,
+		Profiles:        profileNames,
+		Config:          d.localConfig,
+		ExpandedConfig:  d.expandedConfig,
+		Devices:         d.localDevices.CloneNative(),
+		ExpandedDevices: d.expandedDevices.CloneNative(),
+		CreatedAt:       d.creationDate,
+		LastUsedAt:      d.lastUsedDate,
+		Ephemeral:       d.ephemeral,
+		Stateful:        d.stateful,
+		Project:         d.project.Name,
+		Location:        d.node,
+		Type:            d.Type().String(),
+		StatusCode:      api.Error, // Default to error status for remote instances that are unreachable.
+	}
+
+	// If instance is local then request status.
+	if d.state.ServerName == d.Location() {
+		instState.StatusCode = d.statusCode()
+	}
+
+	instState.Status = instState.StatusCode.String()
+
+	for _, option := range options {
+		err := option(&instState)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &instState, etag, nil
+}
+
+// RenderFull returns all info about the instance.
+func (d *krun) RenderFull(_ []net.Interface, opts ...instance.StateRenderOptions) (*api.InstanceFull, any, error) {
+	if d.IsSnapshot() {
+		return nil, nil, errors.New("RenderFull does not work with snapshots")
+	}
+
+	// Get the Instance struct.
+	base, etag, err := d.Render()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Convert to InstanceFull.
+	krunState := api.InstanceFull{Instance: *base.(*api.Instance)}
+
+	// Add the InstanceState (pass through opts).
+	krunState.State, err = d.renderState(krunState.StatusCode, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Add the InstanceSnapshots.
+	snaps, err := d.Snapshots()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, snap := range snaps {
+		render, _, err := snap.Render()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if krunState.Snapshots == nil {
+			krunState.Snapshots = []api.InstanceSnapshot{}
+		}
+
+		krunState.Snapshots = append(krunState.Snapshots, *render.(*api.InstanceSnapshot))
+	}
+
+	// Add the InstanceBackups.
+	backups, err := d.Backups()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, backup := range backups {
+		render := backup.Render()
+
+		if krunState.Backups == nil {
+			krunState.Backups = []api.InstanceBackup{}
+		}
+
+		krunState.Backups = append(krunState.Backups, *render)
+	}
+
+	return &krunState, etag, nil
+}
+
+// renderState returns just state info about the instance.
+func (d *krun) renderState(statusCode api.StatusCode, opts ...instance.StateRenderOptions) (*api.InstanceState, error) {
+	var err error
+
+	// Determine which fields to include.
+	options := instance.DefaultStateRenderOptions()
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	status := &api.InstanceState{}
+	pid, _ := d.pid()
+
+	if d.isRunningStatusCode(statusCode) {
+		// Try and get state info from agent.
+		status, err = d.agentGetState()
+		if err != nil {
+			if !errors.Is(err, errKrunAgentOffline) {
+				d.logger.Warn("Could not get krun instance state from agent", logger.Ctx{"err": err})
+			}
+
+			// Fallback data if agent is not reachable.
+			status = &api.InstanceState{}
+			status.Processes = -1
+
+			if options.IncludeNetwork {
+				status.Network, err = d.getNetworkState()
+				if err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			// Agent returned state - apply selective recursion filtering.
+			if !options.IncludeNetwork {
+				status.Network = nil
+			}
+
+			if !options.IncludeDisk {
+				status.Disk = nil
+			}
+		}
+	}
+
+	status.Pid = int64(pid)
+	status.Status = statusCode.String()
+	status.StatusCode = statusCode
+
+	// Disk - conditionally fetch (expensive operation).
+	if options.IncludeDisk {
+		status.Disk, err = d.diskState()
+		if err != nil && !errors.Is(err, storageDrivers.ErrNotSupported) {
+			d.logger.Info("Cannot get disk usage", logger.Ctx{"err": err})
+		}
+	} else {
+		status.Disk = nil
+	}
+
+	return status, nil
+}
+
+// RenderState returns just state info about the instance.
+func (d *krun) RenderState(_ []net.Interface, opts ...instance.StateRenderOptions) (*api.InstanceState, error) {
+	return d.renderState(d.statusCode(), opts...)
+}
+
+// diskState gets disk usage info.
+func (d *krun) diskState() (map[string]api.InstanceStateDisk, error) {
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return nil, err
+	}
+
+	// Get the root disk device config.
+	rootDiskName, _, err := d.getRootDiskDevice()
+	if err != nil {
+		return nil, err
+	}
+
+	usage, err := pool.GetInstanceUsage(d)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := map[string]api.InstanceStateDisk{}
+	disk[rootDiskName] = api.InstanceStateDisk{
+		Usage: usage.Used,
+		Total: usage.Total,
+	}
+
+	return disk, nil
+}
+
+// agentGetState connects to the agent inside of the microVM and does an API call to get the current state.
+func (d *krun) agentGetState() (*api.InstanceState, error) {
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), agentConnectTimeout)
+	defer cancel()
+
+	agent, err := lxd.ConnectLXDHTTPWithContext(ctx, nil, client)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to agent: %w", err)
+	}
+
+	defer agent.Disconnect()
+
+	status, _, err := agent.GetInstanceState("")
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// getNetworkState returns the state of the NICs which support fallback state mechanisms.
+func (d *krun) getNetworkState() (map[string]api.InstanceStateNetwork, error) {
+	networks := map[string]api.InstanceStateNetwork{}
+	for k, m := range d.ExpandedDevices() {
+		if m["type"] != "nic" {
+			continue
+		}
+
+		dev, err := d.deviceLoad(d, k, m)
+		if err != nil {
+			if errors.Is(err, device.ErrUnsupportedDevType) {
+				continue // Skip unsupported device (allows for mixed instance type profiles).
+			}
+
+			d.logger.Warn("Failed state validation for device", logger.Ctx{"device": k, "err": err})
+			continue
+		}
+
+		// Only some NIC types support fallback state mechanisms when there is no agent.
+		nic, ok := dev.(device.NICState)
+		if !ok {
+			continue
+		}
+
+		network, err := nic.State()
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting NIC state for %q: %w", k, err)
+		}
+
+		if network != nil {
+			networks[k] = *network
+		}
+	}
+
+	return networks, nil
+}
+
+// IsRunning returns whether or not the instance is running.
+func (d *krun) IsRunning() bool {
+	return d.isRunningStatusCode(d.statusCode())
+}
+
+// IsFrozen returns whether the instance frozen or not.
+func (d *krun) IsFrozen() bool {
+	return d.statusCode() == api.Frozen
+}
+
+// LockExclusive attempts to get exclusive access to the instance's root volume.
+func (d *krun) LockExclusive() (*operationlock.InstanceOperation, error) {
+	if d.IsRunning() {
+		return nil, errors.New("Instance is running")
+	}
+
+	// Prevent concurrent operations the instance.
+	op, err := operationlock.Create(d.Project().Name, d.Name(), operationlock.ActionCreate, false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return op, err
+}
+
+// DeviceEventHandler handles events occurring on the instance's devices.
+// Devices can't be reconfigured while the microVM is running so events are ignored.
+func (d *krun) DeviceEventHandler(runConf *deviceConfig.RunConfig) error {
+	return nil
+}
+
+// InitPID returns the PID of the microVM process.
+func (d *krun) InitPID() int {
+	pid, _ := d.pid()
+	return pid
+}
+
+func (d *krun) statusCode() api.StatusCode {
+	// Shortcut to avoid checking the process during ongoing operations.
+	operationStatus := d.operationStatusCode()
+	if operationStatus != nil {
+		return *operationStatus
+	}
+
+	pid, err := d.pid()
+	if err != nil {
+		return api.Error
+	}
+
+	if pid <= 0 {
+		return api.Stopped
+	}
+
+	procStatus, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return api.Stopped
+	}
+
+	for line := range strings.SplitSeq(string(procStatus), "\n") {
+		value, ok := strings.CutPrefix(line, "State:")
+		if ok && strings.Contains(value, "stopped") {
+			return api.Frozen
+		}
+	}
+
+	if shared.IsTrue(d.LocalConfig()["volatile.last_state.ready"]) {
+		return api.Ready
+	}
+
+	return api.Running
+}
+
+// State returns the instance's state code.
+func (d *krun) State() string {
+	return strings.ToUpper(d.statusCode().String())
+}
+
+// FillNetworkDevice takes a nic device type and enriches it with automatically generated name and hwaddr
+// properties if these are missing from the device.
+func (d *krun) FillNetworkDevice(name string, m deviceConfig.Device) (deviceConfig.Device, error) {
+	var err error
+
+	newDevice := m.Clone()
+
+	nicType, err := nictype.NICType(d.state, d.Project().Name, m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill in the MAC address.
+	if !slices.Contains([]string{"physical", "ipvlan", "sriov"}, nicType) && m["hwaddr"] == "" {
+		configKey := "volatile." + name + ".hwaddr"
+		volatileHwaddr := d.localConfig[configKey]
+		if volatileHwaddr == "" {
+			// Generate a new MAC address.
+			volatileHwaddr, err = instance.DeviceNextInterfaceHWAddr()
+			if err != nil || volatileHwaddr == "" {
+				return nil, fmt.Errorf("Failed generating %q: %w", configKey, err)
+			}
+
+			// Update the database and update volatileHwaddr with stored value.
+			volatileHwaddr, err = d.insertConfigkey(configKey, volatileHwaddr)
+			if err != nil {
+				return nil, fmt.Errorf("Failed storing generated config key %q: %w", configKey, err)
+			}
+
+			// Set stored value into current instance config.
+			d.localConfig[configKey] = volatileHwaddr
+			d.expandedConfig[configKey] = volatileHwaddr
+		}
+
+		if volatileHwaddr == "" {
+			return nil, fmt.Errorf("Failed getting %q", configKey)
+		}
+
+		newDevice["hwaddr"] = volatileHwaddr
+	}
+
+	return newDevice, nil
+}
+
+// UpdateBackupFile writes the instance's backup.yaml file to storage.
+func (d *krun) UpdateBackupFile() error {
+	pool, err := d.getStoragePool()
+	if err != nil {
+		return err
+	}
+
+	volBackupConf, err := pool.GenerateInstanceCustomVolumeBackupConfig(d, nil, true, nil)
+	if err != nil {
+		return fmt.Errorf("Failed generating instance custom volume config: %w", err)
+	}
+
+	// Use the global metadata version.
+	return pool.UpdateInstanceBackupFile(d, true, volBackupConf, config.DefaultMetadataVersion, nil)
+}
+
+// Info returns "krun" and the currently loaded libkrun version.
+func (d *krun) Info() instance.Info {
+	data := instance.Info{
+		Name:     "krun",
+		Features: make(map[string]any),
+		Type:     instancetype.Krun,
+		Error:    errors.New("Unknown error"),
+	}
+
+	if !shared.PathExists("/dev/kvm") {
+		data.Error = errors.New("KVM support is missing (no /dev/kvm)")
+		return data
+	}
+
+	err := libkrun.Load()
+	if err != nil {
+		data.Error = err
+		return data
+	}
+
+	data.Version = krunVersion()
+	data.Error = nil
+
+	return data
+}
+
+// krunVersion returns the version of the loaded libkrun library based on its file name.
+func krunVersion() string {
+	maps, err := os.ReadFile("/proc/self/maps")
+	if err != nil {
+		return "unknown"
+	}
+
+	for line := range strings.SplitSeq(string(maps), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 || !strings.Contains(filepath.Base(fields[5]), "libkrun.so") {
+			continue
+		}
+
+		libPath, err := filepath.EvalSymlinks(fields[5])
+		if err != nil {
+			libPath = fields[5]
+		}
+
+		_, libVersion, found := strings.Cut(filepath.Base(libPath), ".so.")
+		if found && libVersion != "" {
+			return libVersion
+		}
+	}
+
+	return "unknown"
+}
+
+// Metrics returns the metrics reported by the lxd-agent inside the microVM.
+func (d *krun) Metrics(_ []net.Interface) (*metrics.MetricSet, error) {
+	if !d.IsRunning() {
+		return nil, ErrInstanceIsStopped
+	}
+
+	client, err := d.getAgentClient()
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := lxd.ConnectLXDHTTP(nil, client)
+	if err != nil {
+		d.logger.Error("Failed connecting to lxd-agent", logger.Ctx{"err": err})
+		return nil, errors.New("Failed connecting to lxd-agent")
+	}
+
+	defer agent.Disconnect()
+
+	resp, _, err := agent.RawQuery(http.MethodGet, "/1.0/metrics", nil, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var m metrics.Metrics
+
+	err = json.Unmarshal(resp.Metadata, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	// The running state is hard-coded here as if we've made it to this point, the instance is running.
+	return metrics.MetricSetFromAPI(&m, map[string]string{"project": d.project.Name, "name": d.name, "type": instancetype.Krun.String(), "state": instance.PowerStateRunning})
+}
@@ -0,0 +1,99 @@
+package drivers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKrunResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    map[string]string
+		cpus      uint8
+		memoryMiB uint32
+		err       bool
+	}{
+		{
+			name:      "Defaults",
+			config:    map[string]string{},
+			cpus:      krunDefaultCPUs,
+			memoryMiB: 1024,
+		},
+		{
+			name:      "Limits",
+			config:    map[string]string{"limits.cpu": "4", "limits.memory": "512MiB"},
+			cpus:      4,
+			memoryMiB: 512,
+		},
+		{
+			name:   "CPU pinning",
+			config: map[string]string{"limits.cpu": "0-3"},
+			err:    true,
+		},
+		{
+			name:   "Too many CPUs",
+			config: map[string]string{"limits.cpu": "256"},
+			err:    true,
+		},
+		{
+			name:   "Memory below 1MiB",
+			config: map[string]string{"limits.memory": "1KiB"},
+			err:    true,
+		},
+	}
+
+	for _, test := range tests {
+		d := &krun{common: common{expandedConfig: test.config}}
+
+		cpus, memoryMiB, err := d.resources()
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: Expected an error", test.name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: Unexpected error: %v", test.name, err)
+			continue
+		}
+
+		if cpus != test.cpus || memoryMiB != test.memoryMiB {
+			t.Errorf("%s: Resources do not match: %d CPUs and %dMiB != %d CPUs and %dMiB", test.name, cpus, memoryMiB, test.cpus, test.memoryMiB)
+		}
+	}
+}
+
+func TestKrunEnvironment(t *testing.T) {
+	d := &krun{common: common{expandedConfig: map[string]string{
+		"environment.FOO": "bar",
+		"environment.ABC": "def",
+		"limits.cpu":      "2",
+	}}}
+
+	env := d.environment()
+	expected := []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=/root",
+		"TERM=linux",
+		"ABC=def",
+		"FOO=bar",
+	}
+
+	if !slices.Equal(env, expected) {
+		t.Errorf("Environment does not match: %v != %v", env, expected)
+	}
+}
+
+func TestKrunValidatePrivileged(t *testing.T) {
+	d := &krun{common: common{expandedConfig: map[string]string{}}}
+	if d.validatePrivileged() == nil || d.IsPrivileged() {
+		t.Error("Expected an unprivileged krun instance to be rejected")
+	}
+
+	d.expandedConfig["security.privileged"] = "true"
+	if d.validatePrivileged() != nil || !d.IsPrivileged() {
+		t.Error("Expected a privileged krun instance to be accepted")
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/flosch/pongo2"
 	"github.com/google/uuid"
+	"github.com/kballard/go-shellquote"
 	liblxc "github.com/lxc/go-lxc"
 	"github.com/pkg/sftp"
 	yaml "go.yaml.in/yaml/v2"
@@ -69,6 +70,7 @@ import (
 	"github.com/canonical/lxd/shared/ioprogress"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/netutils"
+	"github.com/canonical/lxd/shared/oci"
 	"github.com/canonical/lxd/shared/osarch"
 	"github.com/canonical/lxd/shared/revert"
 	"github.com/canonical/lxd/shared/termios"
@@ -2088,6 +2090,12 @@ func (d *lxc) startCommon(ctx context.Context, progressReporter ioprogress.Progr
 		}
 	}
 
+	// Setup the execution parameters of the entrypoint.
+	err = d.setupEntrypoint(cc)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
 	// Load the LXC raw config.
 	err = d.loadRawLXCConfig(cc)
 	if err != nil {
@@ -2251,6 +2259,12 @@ func (d *lxc) Start(ctx context.Context, stateful bool, progressReporter ioprogr
 		}
 	}
 
+	command, err := d.entrypointCommand()
+	if err != nil {
+		op.Done(err)
+		return err
+	}
+
 	// Run the shared start code.
 	cleanupInstanceDevices, configPath, postStartHooks, err := d.startCommon(ctx, progressReporter)
 	if err != nil {
@@ -2260,14 +2274,19 @@ func (d *lxc) Start(ctx context.Context, stateful bool, progressReporter ioprogr
 
 	name := project.Instance(d.Project().Name, d.name)
 
+	args := []string{"forkstart", name, d.state.OS.LxcPath, configPath}
+
+	// Run the entrypoint as the init process of the container.
+	if len(command) > 0 {
+		args = append(args, "--")
+		args = append(args, command...)
+	}
+
 	// Start the LXC container
 	_, err = shared.RunCommand(
 		context.TODO(),
 		d.state.OS.ExecPath,
-		"forkstart",
-		name,
-		d.state.OS.LxcPath,
-		configPath)
+		args...)
 	if err != nil && !d.IsRunning() {
 		// Attempt to extract the LXC errors
 		lxcLog := ""
@@ -5079,7 +5098,12 @@ func (d *lxc) MigrateReceive(ctx context.Context, args instance.MigrateReceiveAr
 		}
 
 		// Compare the two sets.
-		syncSourceSnapshotIndexes, deleteTargetSnapshotIndexes := storagePools.CompareSnapshots(sourceSnapshotComparable, targetSnapshotsComparable)
+		compareSnapshots := storagePools.CompareSnapshots
+		if args.RefreshExcludeOlder {
+			compareSnapshots = storagePools.CompareSnapshotsFromLastCommon
+		}
+
+		syncSourceSnapshotIndexes, deleteTargetSnapshotIndexes := compareSnapshots(sourceSnapshotComparable, targetSnapshotsComparable)
 
 		// Delete the extra local snapshots first.
 		for _, deleteTargetSnapshotIndex := range deleteTargetSnapshotIndexes {
@@ -5225,6 +5249,7 @@ func (d *lxc) MigrateReceive(ctx context.Context, args instance.MigrateReceiveAr
 			Name:                  d.Name(),
 			MigrationType:         respTypes[0],
 			Refresh:               args.Refresh,                // Indicate to receiver volume should exist.
+			RefreshExcludeOlder:   args.RefreshExcludeOlder,    // Leave the snapshots before the last common one alone.
 			TrackProgress:         true,                        // Use a progress tracker on receiver to get in-cluster progress information.
 			Live:                  sendFinalFsDelta,            // Indicates we will get a final rootfs sync.
 			VolumeSize:            offerHeader.GetVolumeSize(), // Block size setting override.
@@ -6367,6 +6392,9 @@ func (d *lxc) networkState(hostInterfaces []net.Interface) map[string]api.Instan
 		}
 	}
 
+	// Populate the QoS class of the NICs.
+	d.networkStateQoS(result)
+
 	return result
 }
 
@@ -7502,6 +7530,66 @@ func (d *lxc) getFSStats() (*metrics.MetricSet, error) {
 	return out, nil
 }
 
+// entrypointCommand returns the command run as the init process of the container, if "oci.entrypoint" is set.
+func (d *lxc) entrypointCommand() ([]string, error) {
+	entrypoint := d.expandedConfig["oci.entrypoint"]
+	if entrypoint == "" {
+		return nil, nil
+	}
+
+	command, err := shellquote.Split(entrypoint)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid entrypoint %q: %w", entrypoint, err)
+	}
+
+	return command, nil
+}
+
+// setupEntrypoint applies the working directory and user of the entrypoint to the LXC config.
+func (d *lxc) setupEntrypoint(cc *liblxc.Container) error {
+	if d.expandedConfig["oci.entrypoint"] == "" {
+		return nil
+	}
+
+	// Applications are expected to exit on SIGTERM, unlike the SIGPWR sent to init systems.
+	err := lxcSetConfigItem(cc, "lxc.signal.halt", "SIGTERM")
+	if err != nil {
+		return err
+	}
+
+	cwd := d.expandedConfig["oci.cwd"]
+	if cwd != "" {
+		err = lxcSetConfigItem(cc, "lxc.init.cwd", cwd)
+		if err != nil {
+			return err
+		}
+	}
+
+	user := d.expandedConfig["oci.user"]
+	if user == "" {
+		return nil
+	}
+
+	rootfsRoot, err := d.OpenRootfs()
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = rootfsRoot.Close() }()
+
+	uid, gid, err := oci.ResolveUser(rootfsRoot, user)
+	if err != nil {
+		return fmt.Errorf("Failed resolving entrypoint user: %w", err)
+	}
+
+	err = lxcSetConfigItem(cc, "lxc.init.uid", strconv.FormatInt(uid, 10))
+	if err != nil {
+		return err
+	}
+
+	return lxcSetConfigItem(cc, "lxc.init.gid", strconv.FormatInt(gid, 10))
+}
+
 func (d *lxc) loadRawLXCConfig(cc *liblxc.Container) error {
 	// Load the LXC raw config.
 	lxcConfig, ok := d.expandedConfig["raw.lxc"]
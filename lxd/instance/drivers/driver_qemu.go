@@ -7659,7 +7659,12 @@ func (d *qemu) MigrateReceive(ctx context.Context, args instance.MigrateReceiveA
 		}
 
 		// Compare the two sets.
-		syncSourceSnapshotIndexes, deleteTargetSnapshotIndexes := storagePools.CompareSnapshots(sourceSnapshotComparable, targetSnapshotsComparable)
+		compareSnapshots := storagePools.CompareSnapshots
+		if args.RefreshExcludeOlder {
+			compareSnapshots = storagePools.CompareSnapshotsFromLastCommon
+		}
+
+		syncSourceSnapshotIndexes, deleteTargetSnapshotIndexes := compareSnapshots(sourceSnapshotComparable, targetSnapshotsComparable)
 
 		// Delete the extra local snapshots first.
 		for _, deleteTargetSnapshotIndex := range deleteTargetSnapshotIndexes {
@@ -7805,6 +7810,7 @@ func (d *qemu) MigrateReceive(ctx context.Context, args instance.MigrateReceiveA
 			Name:                  d.Name(),
 			MigrationType:         respTypes[0],
 			Refresh:               args.Refresh,                // Indicate to receiver volume should exist.
+			RefreshExcludeOlder:   args.RefreshExcludeOlder,    // Leave the snapshots before the last common one alone.
 			TrackProgress:         true,                        // Use a progress tracker on receiver to get in-cluster progress information.
 			Live:                  false,                       // Indicates we will not get a final rootfs sync.
 			VolumeSize:            offerHeader.GetVolumeSize(), // Block size setting override.
@@ -8520,6 +8526,9 @@ func (d *qemu) renderState(statusCode api.StatusCode, opts ...instance.StateRend
 					}
 				}
 			}
+
+			// Populate the QoS class of the NICs.
+			d.networkStateQoS(status.Network)
 		}
 	}
 
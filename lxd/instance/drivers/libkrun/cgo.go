@@ -26,3 +26,29 @@ func freeCStr(p *C.char) {
 		C.free(unsafe.Pointer(p))
 	}
 }
+
+// cStrArray converts a slice of strings into a NULL terminated array of C strings.
+// The returned array must be released with freeCStrArray.
+func cStrArray(values []string) **C.char {
+	ptrSize := unsafe.Sizeof((*C.char)(nil))
+	arr := (**C.char)(C.calloc(C.size_t(len(values)+1), C.size_t(ptrSize)))
+	items := unsafe.Slice(arr, len(values)+1)
+
+	for i, value := range values {
+		items[i] = C.CString(value)
+	}
+
+	return arr
+}
+
+func freeCStrArray(arr **C.char) {
+	if arr == nil {
+		return
+	}
+
+	for p := arr; *p != nil; p = (**C.char)(unsafe.Add(unsafe.Pointer(p), unsafe.Sizeof(*p))) {
+		C.free(unsafe.Pointer(*p))
+	}
+
+	C.free(unsafe.Pointer(arr))
+}
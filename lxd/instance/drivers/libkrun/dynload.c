@@ -15,6 +15,8 @@ struct krun_api {
     __typeof__(krun_add_virtio_console_default) *add_virtio_console_default;
     __typeof__(krun_add_virtio_console_multiport) *add_virtio_console_multiport;
     __typeof__(krun_add_console_port_inout) *add_console_port_inout;
+    __typeof__(krun_set_root) *set_root;
+    __typeof__(krun_set_exec) *set_exec;
     __typeof__(krun_set_kernel) *set_kernel;
     __typeof__(krun_add_disk) *add_disk;
     __typeof__(krun_add_virtiofs3) *add_virtiofs3;
@@ -117,6 +119,8 @@ static void loader_init_once(void) {
     RESOLVE_REQUIRED(add_virtio_console_default, krun_add_virtio_console_default);
     RESOLVE_REQUIRED(add_virtio_console_multiport, krun_add_virtio_console_multiport);
     RESOLVE_REQUIRED(add_console_port_inout, krun_add_console_port_inout);
+    RESOLVE_REQUIRED(set_root, krun_set_root);
+    RESOLVE_REQUIRED(set_exec, krun_set_exec);
     RESOLVE_REQUIRED(set_kernel, krun_set_kernel);
     RESOLVE_REQUIRED(add_disk, krun_add_disk);
     RESOLVE_REQUIRED(add_virtiofs3, krun_add_virtiofs3);
@@ -134,6 +138,10 @@ static bool loader_ready(void) {
     return loader.init_ok == 1;
 }
 
+bool goKrunLoaderReady(void) {
+    return loader_ready();
+}
+
 const char *goKrunLoaderLastError(void) {
     pthread_once(&loader.once, loader_init_once);
     return loader.err;
@@ -187,6 +195,22 @@ int32_t krun_add_console_port_inout(uint32_t ctx_id, uint32_t console_id, const
     return loader.api.add_console_port_inout(ctx_id, console_id, name, input_fd, output_fd);
 }
 
+int32_t krun_set_root(uint32_t ctx_id, const char *root_path) {
+    if (!loader_ready()) {
+        return KRUN_LOADER_ERR;
+    }
+
+    return loader.api.set_root(ctx_id, root_path);
+}
+
+int32_t krun_set_exec(uint32_t ctx_id, const char *exec_path, const char *const argv[], const char *const envp[]) {
+    if (!loader_ready()) {
+        return KRUN_LOADER_ERR;
+    }
+
+    return loader.api.set_exec(ctx_id, exec_path, argv, envp);
+}
+
 int32_t krun_set_kernel(uint32_t ctx_id,
                         const char *kernel_path,
                         uint32_t kernel_format,
@@ -4,9 +4,22 @@ package libkrun
 #cgo linux LDFLAGS: -ldl -lpthread
 #include <stdlib.h>
 #include "libkrun_fwd.h"
+
+bool goKrunLoaderReady(void);
+const char *goKrunLoaderLastError(void);
 */
 import "C"
 
+// Load loads libkrun and resolves its symbols, returning an error if the library is unusable.
+// It is safe to call multiple times; the library is only loaded once.
+func Load() error {
+	if !bool(C.goKrunLoaderReady()) {
+		return LoaderError{message: C.GoString(C.goKrunLoaderLastError())}
+	}
+
+	return nil
+}
+
 // Context is a libkrun configuration context used to build and start a single microVM.
 type Context struct {
 	id C.uint32_t
@@ -45,6 +45,17 @@ func TestLoaderSubprocess(t *testing.T) {
 			t.Fatalf("LoaderError = %q, want missing required symbol detail", le.Error())
 		}
 
+	case "load-missing-lib":
+		err := Load()
+		if err == nil {
+			t.Fatalf("Load() = nil error, want LoaderError")
+		}
+
+		var le LoaderError
+		if !errors.As(err, &le) {
+			t.Fatalf("Load() error type = %T, want LoaderError", err)
+		}
+
 	default:
 		t.Fatalf("unknown GO_LIBKRUN_CASE=%q", os.Getenv("GO_LIBKRUN_CASE"))
 	}
@@ -54,6 +65,10 @@ func TestLoaderMissingLibraryPath(t *testing.T) {
 	runLoaderScenario(t, "missing-lib", filepath.Join(t.TempDir(), "does-not-exist-libkrun.so"))
 }
 
+func TestLoadMissingLibraryPath(t *testing.T) {
+	runLoaderScenario(t, "load-missing-lib", filepath.Join(t.TempDir(), "does-not-exist-libkrun.so"))
+}
+
 func TestLoaderMissingRequiredSymbol(t *testing.T) {
 	_, err := exec.LookPath("cc")
 	if err != nil {
@@ -0,0 +1,30 @@
+package libkrun
+
+/*
+#include <stdlib.h>
+#include "libkrun_fwd.h"
+*/
+import "C"
+
+// SetRoot sets the host directory exported to the guest as its root filesystem.
+func (c *Context) SetRoot(rootPath string) error {
+	cRoot := cStr(rootPath)
+	defer freeCStr(cRoot)
+
+	return check(C.krun_set_root(c.id, cRoot))
+}
+
+// SetExec sets the executable, arguments and environment of the guest workload.
+// The workload is started by the libkrun init once the guest kernel has booted.
+func (c *Context) SetExec(execPath string, argv []string, envp []string) error {
+	cExec := cStr(execPath)
+	defer freeCStr(cExec)
+
+	cArgv := cStrArray(argv)
+	defer freeCStrArray(cArgv)
+
+	cEnvp := cStrArray(envp)
+	defer freeCStrArray(cEnvp)
+
+	return check(C.krun_set_exec(c.id, cExec, cArgv, cEnvp))
+}
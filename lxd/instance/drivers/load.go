@@ -24,6 +24,7 @@ import (
 
 // Instance driver definitions.
 var instanceDrivers = map[string]func() instance.Instance{
+	"krun": func() instance.Instance { return &krun{} },
 	"lxc":  func() instance.Instance { return &lxc{} },
 	"qemu": func() instance.Instance { return &qemu{} },
 }
@@ -65,6 +66,8 @@ func load(s *state.State, args db.InstanceArgs, p api.Project) (instance.Instanc
 		inst, err = lxcLoad(s, args, p)
 	case instancetype.VM:
 		inst, err = qemuLoad(s, args, p)
+	case instancetype.Krun:
+		inst, err = krunLoad(s, args, p)
 	default:
 		return nil, fmt.Errorf("Invalid type for instance %q", args.Name)
 	}
@@ -143,6 +146,8 @@ func create(ctx context.Context, s *state.State, args db.InstanceArgs, p api.Pro
 		return lxcCreate(ctx, s, args, p)
 	case instancetype.VM:
 		return qemuCreate(ctx, s, args, p)
+	case instancetype.Krun:
+		return krunCreate(ctx, s, args, p)
 	}
 
 	return nil, nil, errors.New("Instance type invalid")
@@ -261,8 +261,9 @@ type MigrateSendArgs struct {
 type MigrateReceiveArgs struct {
 	MigrateArgs
 
-	InstanceOperation *operationlock.InstanceOperation
-	Refresh           bool
+	InstanceOperation   *operationlock.InstanceOperation
+	Refresh             bool
+	RefreshExcludeOlder bool // Only sync the snapshots taken after the last snapshot in common with the source.
 }
 
 // ConversionArgs represent arguments for instance conversion send and receive.
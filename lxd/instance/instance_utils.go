@@ -121,7 +121,7 @@ func ValidConfig(sysOS *sys.OS, config map[string]string, expanded bool, instanc
 
 func validConfigKey(os *sys.OS, key string, value string, instanceType instancetype.Type) error {
 	// Disallow keys with container-specific prefixes such as "linux.sysctl." and "limits.kernel." for VMs.
-	if instanceType == instancetype.VM && shared.StringHasPrefix(key, instancetype.ConfigKeyPrefixesContainer...) {
+	if (instanceType == instancetype.VM || instanceType == instancetype.Krun) && shared.StringHasPrefix(key, instancetype.ConfigKeyPrefixesContainer...) {
 		return fmt.Errorf("%q is not supported for %q", key, instanceType)
 	}
 
@@ -146,6 +146,8 @@ func validConfigKey(os *sys.OS, key string, value string, instanceType instancet
 			_, exists = instancetype.InstanceConfigKeysVM[key]
 		case instancetype.Container:
 			_, exists = instancetype.InstanceConfigKeysContainer[key]
+		case instancetype.Krun:
+			exists = slices.Contains(instancetype.KrunConfigKeysContainer, key)
 		}
 
 		_, existsAny := instancetype.InstanceConfigKeysAny[key]
@@ -619,6 +621,16 @@ func SuitableArchitectures(ctx context.Context, s *state.State, tx *db.ClusterTx
 				if err != nil {
 					return nil, err
 				}
+			} else if req.Source.Protocol == "oci" {
+				// Remote OCI registry.
+				remote, err = lxd.ConnectOCI(req.Source.Server, &lxd.ConnectionArgs{
+					TLSServerCert: req.Source.Certificate,
+					UserAgent:     version.UserAgent,
+					Proxy:         s.Proxy,
+				})
+				if err != nil {
+					return nil, err
+				}
 			} else {
 				return nil, api.StatusErrorf(http.StatusBadRequest, "Unsupported remote image server protocol %q", req.Source.Protocol)
 			}
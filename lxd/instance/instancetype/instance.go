@@ -3,10 +3,13 @@ package instancetype
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kballard/go-shellquote"
+
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/units"
@@ -93,6 +96,67 @@ var HugePageSizeSuffix = [...]string{"64KB", "1MB", "2MB", "1GB"}
 
 // InstanceConfigKeysAny is a map of config key to validator. (keys applying to containers AND virtual machines).
 var InstanceConfigKeysAny = map[string]func(value string) error{
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.schedule)
+	// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic backups.
+	//
+	// ---
+	//  type: string
+	//  defaultdesc: empty
+	//  liveupdate: no
+	//  shortdesc: Schedule for automatic instance backups
+	"backups.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly", "@never"})),
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.pattern)
+	// Specify a Pongo2 template string that represents the name of scheduled backups.
+	//
+	// See {ref}`instance-options-backups-names` for more information.
+	// ---
+	//  type: string
+	//  defaultdesc: `backup%d`
+	//  liveupdate: no
+	//  shortdesc: Template for the scheduled backup name
+	"backups.pattern": validate.IsAny,
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.expiry)
+	// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  shortdesc: Time until scheduled backups are deleted
+	"backups.expiry": func(value string) error {
+		// Validate expression
+		_, err := shared.GetExpiry(time.Time{}, value)
+		return err
+	},
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.retention.last)
+	// Scheduled backups that aren't kept by any of the `backups.retention.*` rules are deleted after each scheduled backup.
+	// Backups created manually are never deleted by the retention policy.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (no limit)
+	//  liveupdate: no
+	//  shortdesc: Number of most recent scheduled backups to keep
+	"backups.retention.last": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.retention.daily)
+	// The most recent scheduled backup of each of the last days that have a backup is kept.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (no limit)
+	//  liveupdate: no
+	//  shortdesc: Number of days for which to keep a scheduled backup
+	"backups.retention.daily": validate.Optional(validate.IsUint32),
+
+	// lxdmeta:generate(entities=instance; group=backups; key=backups.retention.weekly)
+	// The most recent scheduled backup of each of the last weeks that have a backup is kept.
+	// ---
+	//  type: integer
+	//  defaultdesc: `0` (no limit)
+	//  liveupdate: no
+	//  shortdesc: Number of weeks for which to keep a scheduled backup
+	"backups.retention.weekly": validate.Optional(validate.IsUint32),
+
 	// lxdmeta:generate(entities=instance; group=boot; key=boot.autostart)
 	// If set to `true`, the instance will always be auto-started, unless `security.protection.start` is also enabled.
 	// If set to `false`, the instance will not be started on LXD start up.
@@ -691,6 +755,53 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 	//  shortdesc: Percentage of memory to have in sync before stopping the instance
 	"migration.incremental.memory.goal": validate.Optional(validate.IsUint32),
 
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=oci.entrypoint)
+	// When set, the command runs as the first process of the container instead of the image's `init` system.
+	// The command is split into arguments using shell quoting rules.
+	//
+	// This option is set from the entrypoint and arguments of the image when creating a container from an OCI image.
+	// See {ref}`images-oci` for more information.
+	// ---
+	//  type: string
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Command to run as the first process of the container
+	"oci.entrypoint": validate.Optional(func(value string) error {
+		fields, err := shellquote.Split(value)
+		if err != nil {
+			return err
+		}
+
+		if len(fields) == 0 {
+			return errors.New("No command specified")
+		}
+
+		return nil
+	}),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=oci.cwd)
+	// This option only applies when {config:option}`instance-miscellaneous:oci.entrypoint` is set.
+	// ---
+	//  type: string
+	//  defaultdesc: `/`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: Working directory of the entrypoint
+	"oci.cwd": validate.Optional(validate.IsAbsFilePath),
+
+	// lxdmeta:generate(entities=instance; group=miscellaneous; key=oci.user)
+	// Specify a user and optionally a group in the form `<user>[:<group>]`, using either names or numeric IDs.
+	// Names are resolved using the `/etc/passwd` and `/etc/group` files of the container when it starts.
+	//
+	// This option only applies when {config:option}`instance-miscellaneous:oci.entrypoint` is set.
+	// ---
+	//  type: string
+	//  defaultdesc: `root`
+	//  liveupdate: no
+	//  condition: container
+	//  shortdesc: User and group running the entrypoint
+	"oci.user": validate.IsAny,
+
 	// Caller is responsible for full validation of any raw.* value.
 
 	// lxdmeta:generate(entities=instance; group=raw; key=raw.lxc)
@@ -753,11 +864,12 @@ var InstanceConfigKeysContainer = map[string]func(value string) error{
 
 	// lxdmeta:generate(entities=instance; group=security; key=security.privileged)
 	// See {ref}`container-security` for more information.
+	// Krun instances must set this option to `true`, because their root file system is shared with the microVM without any ID mapping.
 	// ---
 	//  type: bool
 	//  defaultdesc: `false`
 	//  liveupdate: no
-	//  condition: container
+	//  condition: container or krun
 	//  shortdesc: Whether to run the instance in privileged mode
 	"security.privileged": validate.Optional(validate.IsBool),
 
@@ -1215,6 +1327,10 @@ func ConfigKeyChecker(key string, instanceType Type) (func(value string) error,
 		}
 	}
 
+	if instanceType == Krun && slices.Contains(KrunConfigKeysContainer, key) {
+		return InstanceConfigKeysContainer[key], nil
+	}
+
 	// lxdmeta:generate(entities=instance; group=cloud-init; key=cloud-init.ssh-keys.KEYNAME)
 	// Represents an additional SSH public key to be merged into existing `cloud-init` seed data
 	// and injected into an instance. Has the format `{user}:{key}`, where {user} is a Linux username and
@@ -0,0 +1,23 @@
+package instancetype
+
+// KrunConfigKeysContainer lists the container configuration keys which also apply to krun instances.
+var KrunConfigKeysContainer = []string{"security.privileged"}
+
+// KrunNIC defines a network interface attached to a krun microVM.
+type KrunNIC struct {
+	Name   string `json:"name"`
+	Hwaddr string `json:"hwaddr"`
+}
+
+// KrunConfig defines the configuration used by forkkrun to start a krun microVM.
+type KrunConfig struct {
+	Name       string    `json:"name"`
+	CPUs       uint8     `json:"cpus"`
+	MemoryMiB  uint32    `json:"memory_mib"`
+	RootPath   string    `json:"root_path"`
+	ConfigPath string    `json:"config_path"`
+	AgentPath  string    `json:"agent_path"`
+	PidPath    string    `json:"pid_path"`
+	NICs       []KrunNIC `json:"nics"`
+	Env        []string  `json:"env"`
+}
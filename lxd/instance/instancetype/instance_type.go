@@ -18,6 +18,9 @@ const (
 
 	// VM represents a virtual-machine instance type.
 	VM = Type(1)
+
+	// Krun represents a libkrun microVM instance type.
+	Krun = Type(2)
 )
 
 // New validates the supplied string against the allowed types of instance and returns the internal
@@ -34,6 +37,11 @@ func New(name string) (Type, error) {
 		return VM, nil
 	}
 
+	// If "krun" is supplied, return type as Krun.
+	if api.InstanceType(name) == api.InstanceTypeKrun {
+		return Krun, nil
+	}
+
 	return -1, errors.New("Invalid instance type")
 }
 
@@ -48,9 +56,23 @@ func (instanceType Type) String() string {
 		return string(api.InstanceTypeVM)
 	}
 
+	if instanceType == Krun {
+		return string(api.InstanceTypeKrun)
+	}
+
 	return ""
 }
 
+// ImageType returns the type of image used to create instances of this type.
+// Krun microVMs boot from the root filesystem of container images.
+func (instanceType Type) ImageType() Type {
+	if instanceType == Krun {
+		return Container
+	}
+
+	return instanceType
+}
+
 // Filter returns a valid filter field compatible with cluster.InstanceFilter.
 // 'Any' represents any possible instance type, and so it is omitted.
 func (instanceType Type) Filter() *Type {
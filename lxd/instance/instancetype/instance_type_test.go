@@ -0,0 +1,52 @@
+package instancetype
+
+import (
+	"testing"
+)
+
+func TestTypeImageType(t *testing.T) {
+	tests := []struct {
+		name      string
+		imageType Type
+	}{
+		{
+			name:      "container",
+			imageType: Container,
+		},
+		{
+			name:      "virtual-machine",
+			imageType: VM,
+		},
+		{
+			name:      "krun",
+			imageType: Container,
+		},
+	}
+
+	for _, test := range tests {
+		instanceType, err := New(test.name)
+		if err != nil {
+			t.Fatalf("%s: Failed parsing instance type: %v", test.name, err)
+		}
+
+		if instanceType.String() != test.name {
+			t.Errorf("%s: Instance type name does not match: %q", test.name, instanceType.String())
+		}
+
+		if instanceType.ImageType() != test.imageType {
+			t.Errorf("%s: Image type does not match: %q != %q", test.name, instanceType.ImageType(), test.imageType)
+		}
+	}
+}
+
+func TestConfigKeyCheckerKrun(t *testing.T) {
+	_, err := ConfigKeyChecker("security.privileged", Krun)
+	if err != nil {
+		t.Errorf("Expected security.privileged to be valid for krun instances: %v", err)
+	}
+
+	_, err = ConfigKeyChecker("security.nesting", Krun)
+	if err == nil {
+		t.Error("Expected security.nesting to be invalid for krun instances")
+	}
+}
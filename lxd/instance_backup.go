@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -292,11 +293,45 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	var target *backup.Target
+	if req.BackupTarget != "" {
+		// Backup targets hold the object storage credentials of the server.
+		err = s.Authorizer.CheckPermission(r.Context(), entity.ServerURL(), auth.EntitlementCanEdit)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if !req.ExpiresAt.IsZero() {
+			return response.BadRequest(errors.New("Backups stored on a backup target cannot have an expiry date"))
+		}
+
+		target, err = loadBackupTarget(r.Context(), s, req.BackupTarget)
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if req.Name == "" {
 		// come up with a name.
-		backups, err := inst.Backups()
-		if err != nil {
-			return response.BadRequest(err)
+		var existingNames []string
+		if target != nil {
+			remoteBackups, err := target.List(r.Context(), backup.TargetBackupName(inst.Project().Name, name, "")+"/")
+			if err != nil {
+				return response.SmartError(err)
+			}
+
+			for _, remoteBackup := range remoteBackups {
+				existingNames = append(existingNames, name+shared.SnapshotDelimiter+path.Base(remoteBackup.Name))
+			}
+		} else {
+			backups, err := inst.Backups()
+			if err != nil {
+				return response.BadRequest(err)
+			}
+
+			for _, backup := range backups {
+				existingNames = append(existingNames, backup.Name())
+			}
 		}
 
 		base := name + shared.SnapshotDelimiter + "backup"
@@ -304,13 +339,13 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		backupNo := 0
 
 		// Iterate over previous backups to autoincrement the backup number.
-		for _, backup := range backups {
+		for _, existingName := range existingNames {
 			// Ignore backups not containing base.
-			if !strings.HasPrefix(backup.Name(), base) {
+			if !strings.HasPrefix(existingName, base) {
 				continue
 			}
 
-			substr := backup.Name()[length:]
+			substr := existingName[length:]
 			var num int
 			count, err := fmt.Sscanf(substr, "%d", &num)
 			if err != nil || count != 1 {
@@ -344,6 +379,23 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 	// We keep the req.ContainerOnly for backward compatibility.
 	instanceOnly := req.InstanceOnly || req.ContainerOnly //nolint:staticcheck,unused
 
+	metadata := map[string]any{
+		api.MetadataEntityURL: api.NewURL().Path(version.APIVersion, "instances", name, "backups", backupName).Project(inst.Project().Name).String(),
+	}
+
+	targetBackupName := backup.TargetBackupName(inst.Project().Name, name, backupName)
+	if target != nil {
+		// Backups on the target are never overwritten.
+		_, err = target.Stat(r.Context(), targetBackupName)
+		if err == nil {
+			return response.Conflict(fmt.Errorf("Backup %q already exists on backup target %q", targetBackupName, req.BackupTarget))
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return response.SmartError(err)
+		}
+
+		metadata[api.MetadataEntityURL] = api.NewURL().Path(version.APIVersion, "backup-targets", req.BackupTarget, "backups", targetBackupName).String()
+	}
+
 	backup := func(ctx context.Context, op *operations.Operation) error {
 		args := db.InstanceBackup{
 			Name:                 fullName,
@@ -355,6 +407,15 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 			CompressionAlgorithm: req.CompressionAlgorithm,
 		}
 
+		if target != nil {
+			err := backupCreateOnTarget(ctx, s, target, req.BackupTarget, targetBackupName, inst, args, req.Version, op)
+			if err != nil {
+				return fmt.Errorf("Create backup on backup target: %w", err)
+			}
+
+			return nil
+		}
+
 		err := backupCreate(ctx, s, args, inst, req.Version, op)
 		if err != nil {
 			return fmt.Errorf("Create backup: %w", err)
@@ -363,10 +424,6 @@ func instanceBackupsPost(d *Daemon, r *http.Request) response.Response {
 		return nil
 	}
 
-	metadata := map[string]any{
-		api.MetadataEntityURL: api.NewURL().Path(version.APIVersion, "instances", name, "backups", backupName).Project(inst.Project().Name).String(),
-	}
-
 	args := operations.OperationArgs{
 		ProjectName: projectName,
 		EntityURL:   api.NewURL().Path(version.APIVersion, "instances", name).Project(projectName),
@@ -271,7 +271,7 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 			return response.SmartError(err)
 		}
 
-		// Pick the member with the least number of instances.
+		// Let the placement webhook select the member if configured, otherwise pick the member with the least number of instances.
 		if targetMemberInfo == nil {
 			var filteredCandidateMembers []db.NodeInfo
 
@@ -283,13 +283,29 @@ func instancePost(d *Daemon, r *http.Request) response.Response {
 				}
 			}
 
-			err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
-				targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, filteredCandidateMembers)
-				return err
-			})
+			webhookReq := api.InstancePlacementWebhookRequest{
+				Reason:   api.InstancePlacementReasonRelocation,
+				Project:  projectName,
+				Instance: instancePlacementWebhookInstance(inst),
+			}
+
+			targetMemberInfo, err = instancePlacementWebhook(s).Select(r.Context(), webhookReq, filteredCandidateMembers)
 			if err != nil {
 				return response.SmartError(err)
 			}
+
+			if targetMemberInfo == nil {
+				// Only keep the members with the most free resources if weighted scheduling is enabled.
+				filteredCandidateMembers = instancePlacementScorer(r.Context(), s).Best(filteredCandidateMembers)
+
+				err := s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+					targetMemberInfo, err = tx.GetNodeWithLeastInstances(ctx, filteredCandidateMembers)
+					return err
+				})
+				if err != nil {
+					return response.SmartError(err)
+				}
+			}
 		}
 
 		if targetMemberInfo != nil && targetMemberInfo.IsOffline(s.GlobalConfig.OfflineThreshold()) {
@@ -230,6 +230,9 @@ func instancesStart(ctx context.Context, s *state.State, instances []instance.In
 	// Sort based on instance boot priority.
 	sort.Sort(instanceAutostartList(instances))
 
+	// Check whether leader replica projects were fenced by their standby before starting their instances.
+	fencedProjects := replicaFailoverFencedProjects(ctx, s, instances)
+
 	// Let's make up to 3 attempts to start instances.
 	maxAttempts := 3
 
@@ -239,6 +242,11 @@ func instancesStart(ctx context.Context, s *state.State, instances []instance.In
 			continue
 		}
 
+		// Instances of standby replica projects must not run, including those of fenced leader projects.
+		if inst.Project().ReplicaMode == api.ReplicatorProjectModeStandby || fencedProjects[inst.Project().Name] {
+			continue
+		}
+
 		// If already running, we're done.
 		if inst.IsRunning() {
 			continue
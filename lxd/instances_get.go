@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -65,6 +67,20 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -123,6 +139,20 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -180,6 +210,20 @@ func urlInstanceTypeDetect(r *http.Request) (instancetype.Type, error) {
 //      type: string
 //      example: default
 //    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
+//    - in: query
 //      name: all-projects
 //      description: Retrieve instances from all projects
 //      type: boolean
@@ -244,7 +288,13 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		return response.BadRequest(fmt.Errorf("Invalid filter: %w", err))
 	}
 
-	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0)
+	// Parse sorting and pagination values.
+	pagination, err := request.PaginationParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	mustLoadObjects := recursion > 0 || (recursion == 0 && clauses != nil && len(clauses.Clauses) > 0) || len(pagination.Sort) > 0
 
 	projectName, allProjects, err := request.ProjectParams(r)
 	if err != nil {
@@ -313,6 +363,19 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		memberAddressInstances[address] = filteredInstances
 	}
 
+	// Without a filter or a custom sort order, the requested page only depends on the instance names so it can be
+	// selected before loading the instances.
+	var nextToken string
+	pageSelected := false
+	if pagination.IsSet() && len(pagination.Sort) == 0 && (clauses == nil || len(clauses.Clauses) == 0) {
+		memberAddressInstances, nextToken, err = paginateMemberAddressInstances(memberAddressInstances, pagination)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		pageSelected = true
+	}
+
 	resultErrListAppend := func(inst db.Instance, err error) {
 		logger.Error("Failed getting instance info", logger.Ctx{"err": err, "project": inst.Project, "instance": inst.Name})
 
@@ -377,6 +440,24 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 				ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 				defer cancel()
 
+				// Only fetch the entries of the requested page from the member.
+				if pagination.IsSet() {
+					apiInsts, err := doInstancesPageFromNode(ctx, filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), instanceType, max(recursion, 1), pagination)
+					if err != nil {
+						for _, inst := range instances {
+							resultErrListAppend(inst, err)
+						}
+
+						return
+					}
+
+					for i := range apiInsts {
+						resultFullListAppend(&apiInsts[i])
+					}
+
+					return
+				}
+
 				if recursion == 1 {
 					apiInsts, err := doInstancesGetFromNode(ctx, filteredProjects, memberAddress, allProjects, networkCert, s.ServerCert(), instanceType)
 					if err != nil {
@@ -499,6 +580,31 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
+	// Sort and paginate result list if needed.
+	if pageSelected {
+		// Remote members return their instances following the continue token, so only keep the ones from the selected page.
+		type instanceKey struct {
+			project string
+			name    string
+		}
+
+		pageInstances := make(map[instanceKey]bool)
+		for _, instances := range memberAddressInstances {
+			for _, inst := range instances {
+				pageInstances[instanceKey{project: inst.Project, name: inst.Name}] = true
+			}
+		}
+
+		resultFullList = slices.DeleteFunc(resultFullList, func(inst *api.InstanceFull) bool {
+			return !pageInstances[instanceKey{project: inst.Project, name: inst.Name}]
+		})
+	} else if pagination.IsSet() {
+		resultFullList, nextToken, err = request.Paginate(pagination, resultFullList, "project", "name")
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if recursion == 0 {
 		resultList := make([]string, 0, len(resultFullList))
 		for i := range resultFullList {
@@ -506,7 +612,7 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 			resultList = append(resultList, url.String())
 		}
 
-		return response.SyncResponse(true, resultList)
+		return response.SyncResponseContinue(true, resultList, nextToken)
 	}
 
 	if len(withEntitlements) > 0 {
@@ -528,10 +634,85 @@ func instancesGet(d *Daemon, r *http.Request) response.Response {
 			resultList = append(resultList, &resultFullList[i].Instance)
 		}
 
-		return response.SyncResponse(true, resultList)
+		return response.SyncResponseContinue(true, resultList, nextToken)
+	}
+
+	return response.SyncResponseContinue(true, resultFullList, nextToken)
+}
+
+// paginateMemberAddressInstances returns the requested page of instances, ordered by project and instance name,
+// grouped by member address along with the continue token for the next page.
+func paginateMemberAddressInstances(memberAddressInstances map[string][]db.Instance, pagination *request.Pagination) (map[string][]db.Instance, string, error) {
+	// memberInstance exposes the project and name of an instance as sort fields.
+	type memberInstance struct {
+		Project string `yaml:"project"`
+		Name    string `yaml:"name"`
+
+		address string
+		inst    db.Instance
+	}
+
+	var instances []memberInstance
+	for address, insts := range memberAddressInstances {
+		for _, inst := range insts {
+			instances = append(instances, memberInstance{Project: inst.Project, Name: inst.Name, address: address, inst: inst})
+		}
+	}
+
+	instances, next, err := request.Paginate(pagination, instances, "project", "name")
+	if err != nil {
+		return nil, "", err
+	}
+
+	page := make(map[string][]db.Instance, len(memberAddressInstances))
+	for _, instance := range instances {
+		page[instance.address] = append(page[instance.address], instance.inst)
+	}
+
+	return page, next, nil
+}
+
+// doInstancesPageFromNode fetches the instances of the requested page from the given remote member.
+// The member returns up to the requested number of its own instances following the continue token, which includes
+// all of its instances that are part of the page.
+func doInstancesPageFromNode(ctx context.Context, projects []string, node string, allProjects bool, networkCert *shared.CertInfo, serverCert *shared.CertInfo, instanceType instancetype.Type, recursion int, pagination *request.Pagination) ([]api.InstanceFull, error) {
+	client, err := cluster.Connect(ctx, node, networkCert, serverCert, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed connecting to member %s: %w", node, err)
+	}
+
+	values := pagination.Values()
+	values.Set("recursion", strconv.Itoa(recursion))
+	if instanceType != instancetype.Any {
+		values.Set("instance-type", instanceType.String())
+	}
+
+	if allProjects {
+		values.Set("all-projects", "true")
+		projects = []string{""}
+	}
+
+	var instances []api.InstanceFull
+	for _, project := range projects {
+		if project != "" {
+			values.Set("project", project)
+		}
+
+		resp, _, err := client.RawQuery(http.MethodGet, "/1.0/instances?"+values.Encode(), nil, "")
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting instances from member %s: %w", node, err)
+		}
+
+		var page []api.InstanceFull
+		err = resp.MetadataAsStruct(&page)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing instances from member %s: %w", node, err)
+		}
+
+		instances = append(instances, page...)
 	}
 
-	return response.SyncResponse(true, resultFullList)
+	return instances, nil
 }
 
 // Fetch information about the instances on the given remote node.
@@ -118,7 +118,7 @@ func createFromImage(r *http.Request, s *state.State, p api.Project, profiles []
 		}
 
 		if req.Source.Server != "" {
-			img, err = ensureDownloadedImageFitWithinBudget(ctx, s, op, p, imgAlias, req.Source, string(req.Type))
+			img, err = ensureDownloadedImageFitWithinBudget(ctx, s, op, p, imgAlias, req.Source, dbType.ImageType().String())
 			if err != nil {
 				return err
 			}
@@ -359,6 +359,7 @@ func prepareInstanceMigrationSink(ctx context.Context, s *state.State, projectNa
 		instanceOnly:          instanceOnly,
 		clusterMoveSourceName: clusterMoveSourceName,
 		refresh:               req.Source.Refresh,
+		refreshExcludeOlder:   req.Source.Refresh && req.Source.RefreshExcludeOlder,
 	}
 
 	sink, err := newMigrationSink(&migrationArgs)
@@ -796,6 +797,39 @@ func createFromCopy(r *http.Request, s *state.State, projectName string, profile
 	return response.OperationResponse(op)
 }
 
+// createFromBackupTarget creates an instance from a backup stored on a backup target.
+// The root disk device of the request selects the storage pool and its devices override those of the backup.
+func createFromBackupTarget(s *state.State, r *http.Request, projectName string, req api.InstancesPost) response.Response {
+	// Backup targets hold the object storage credentials of the server.
+	err := s.Authorizer.CheckPermission(r.Context(), entity.ServerURL(), auth.EntitlementCanEdit)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	if req.Source.BackupTarget == "" {
+		return response.BadRequest(errors.New("Must specify a backup target"))
+	}
+
+	err = backup.ValidateTargetBackupName(req.Source.Backup)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	target, err := loadBackupTarget(r.Context(), s, req.Source.BackupTarget)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	reader, err := target.Open(r.Context(), req.Source.Backup)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	defer func() { _ = reader.Close() }()
+
+	return createFromBackup(s, r, projectName, reader, req.Devices["root"]["pool"], req.Name, req.Devices)
+}
+
 func createFromBackup(s *state.State, r *http.Request, projectName string, data io.Reader, pool string, instanceName string, devices map[string]map[string]string) response.Response {
 	revert := revert.New()
 	defer revert.Fail()
@@ -1246,7 +1280,7 @@ func setupInstanceArgs(s *state.State, instType instancetype.Type, projectName s
 //	Creates a new instance on LXD.
 //	Depending on the source, this can create an instance from an existing
 //	local image, remote image, existing local instance or snapshot, remote
-//	migration stream or backup file.
+//	migration stream, backup file or backup stored on a backup target.
 //
 //	---
 //	consumes:
@@ -1354,6 +1388,10 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		req.Config = map[string]string{}
 	}
 
+	if req.Source.Type == api.SourceTypeBackup {
+		return createFromBackupTarget(s, r, targetProjectName, req)
+	}
+
 	if req.Source.Type == api.SourceTypeCopy {
 		if req.Source.Source == "" {
 			return response.BadRequest(errors.New("Must specify a source instance"))
@@ -1401,12 +1439,21 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	var targetMemberInfo *db.NodeInfo
 	var targetGroupName string
 	var placementGroupName string
+	var placementCandidates []db.NodeInfo
 
 	// Set to true once we find that the request is currently handled on a member which isn't hosting the source instance.
 	sourceInstOnDifferentMember := false
 
 	target := request.QueryParam(r, "target")
 
+	// Retrieve the live resource usage of the cluster members ahead of the transaction when automatic placement
+	// may need it.
+	var placementScorer *placement.Scorer
+	targetMemberName, _ := limits.TargetDetect(target)
+	if s.ServerClustered && !clusterNotification && targetMemberName == "" {
+		placementScorer = instancePlacementScorer(r.Context(), s)
+	}
+
 	// Run a first transaction to figure out details about the source and target.
 	// To accommodate the different scenarios for copy (and refresh), the API handler can go into the following code paths:
 	// 1) Internal copy as the source is using a remote pool:
@@ -1544,7 +1591,12 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 			// Try to resolve the source image from cache and perform authorization checks.
 			// This is needed to verify the caller has access to the image if it's from a different project,
 			// and to retrieve the image's metadata (such as profiles) so they can be applied to the instance.
-			sourceImage, err = resolveSourceImageFromCache(r, s, tx, targetProject.Name, req.Source, &sourceImageRef, string(req.Type))
+			instType, err := instancetype.New(string(req.Type))
+			if err != nil {
+				return api.StatusErrorf(http.StatusBadRequest, "Invalid instance type %q: %w", req.Type, err)
+			}
+
+			sourceImage, err = resolveSourceImageFromCache(r, s, tx, targetProject.Name, req.Source, &sourceImageRef, instType.ImageType().String())
 			if err != nil {
 				return err
 			}
@@ -1665,7 +1717,19 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 
 			expandedConfig := instancetype.ExpandInstanceConfig(s.GlobalConfig.Dump(), req.Config, profiles)
 			placementGroupName = expandedConfig["placement.group"]
-			targetMemberInfo, err = instancesPostSelectClusterMember(ctx, tx, placementGroupName, candidateMembers, targetProject.Name)
+
+			if placementScorer != nil {
+				expandedDevices := instancetype.ExpandInstanceDevices(deviceConfig.NewDevices(req.Devices), profiles)
+				_, rootDiskDevice, _ := api.GetRootDiskDevice(expandedDevices.CloneNative())
+				placementScorer.PoolName = rootDiskDevice["pool"]
+			}
+
+			placementCandidates, err = instancesPostFilterClusterMembers(ctx, tx, placementGroupName, candidateMembers, targetProject.Name)
+			if err != nil {
+				return err
+			}
+
+			targetMemberInfo, err = instancesPostSelectClusterMember(ctx, tx, placementCandidates, placementScorer)
 			if err != nil {
 				return err
 			}
@@ -1694,6 +1758,24 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Let the placement webhook override the automatically selected cluster member if configured.
+	if len(placementCandidates) > 0 {
+		webhookReq := api.InstancePlacementWebhookRequest{
+			Reason:   api.InstancePlacementReasonNew,
+			Project:  targetProjectName,
+			Instance: req,
+		}
+
+		webhookMemberInfo, err := instancePlacementWebhook(s).Select(r.Context(), webhookReq, placementCandidates)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		if webhookMemberInfo != nil {
+			targetMemberInfo = webhookMemberInfo
+		}
+	}
+
 	poolSupportsInternalCopy := false
 
 	if s.ServerClustered && req.Source.Type == api.SourceTypeCopy && sourceInstPoolName != "" {
@@ -1782,14 +1864,13 @@ func instancesPost(d *Daemon, r *http.Request) response.Response {
 	}
 }
 
-// instancesPostSelectClusterMember determines which cluster member to use for placing an instance during creation or migration.
-// It first checks whether the instance belongs to a placement group and, if so, applies the placement group’s policy and rigor to filter the available members.
-// Among the remaining candidates, the member with the fewest existing instances is selected.
-// If the instance does not belong to a placement group, the member with the fewest instances is chosen from all candidates.
-func instancesPostSelectClusterMember(ctx context.Context, tx *db.ClusterTx, placementGroupName string, candidateMembers []db.NodeInfo, projectName string) (*db.NodeInfo, error) {
+// instancesPostFilterClusterMembers returns the candidate cluster members for placing an instance during creation or migration.
+// If the instance belongs to a placement group, the placement group’s policy and rigor are applied to filter the available members.
+// Otherwise all candidates are returned.
+func instancesPostFilterClusterMembers(ctx context.Context, tx *db.ClusterTx, placementGroupName string, candidateMembers []db.NodeInfo, projectName string) ([]db.NodeInfo, error) {
 	// Check if instance is using a placement group.
 	if placementGroupName == "" {
-		return tx.GetNodeWithLeastInstances(ctx, candidateMembers)
+		return candidateMembers, nil
 	}
 
 	placementGroup, err := dbCluster.GetPlacementGroup(ctx, tx.Tx(), placementGroupName, projectName)
@@ -1804,18 +1885,101 @@ func instancesPostSelectClusterMember(ctx context.Context, tx *db.ClusterTx, pla
 
 	apiPlacementGroup := placementGroup.ToAPI(configs)
 
-	filteredCandidates, err := placement.Filter(ctx, tx, candidateMembers, *apiPlacementGroup, false)
+	return placement.Filter(ctx, tx, candidateMembers, *apiPlacementGroup, false)
+}
+
+// instancesPostSelectClusterMember determines which of the candidate cluster members to use for placing an instance during creation or migration.
+// If a scorer is provided, only the candidates with the most free resources are kept.
+// Among the remaining candidates, the member with the fewest existing instances is selected.
+func instancesPostSelectClusterMember(ctx context.Context, tx *db.ClusterTx, candidateMembers []db.NodeInfo, scorer *placement.Scorer) (*db.NodeInfo, error) {
+	// Early return if only a single candidate.
+	if len(candidateMembers) == 1 {
+		return &candidateMembers[0], nil
+	}
+
+	return tx.GetNodeWithLeastInstances(ctx, scorer.Best(candidateMembers))
+}
+
+// instancePlacementWebhook returns the instance placement webhook if configured through the
+// `instances.placement.webhook` configuration key, otherwise it returns nil.
+func instancePlacementWebhook(s *state.State) *placement.Webhook {
+	webhookURL, timeout := s.GlobalConfig.InstancesPlacementWebhook()
+	if webhookURL == "" {
+		return nil
+	}
+
+	return &placement.Webhook{
+		URL:     webhookURL,
+		Timeout: timeout,
+		Proxy:   s.Proxy,
+	}
+}
+
+// instancePlacementWebhookInstance returns the representation of an existing instance sent to the instance placement webhook.
+func instancePlacementWebhookInstance(inst instance.Instance) api.InstancesPost {
+	architecture, _ := osarch.ArchitectureName(inst.Architecture())
+
+	profileNames := make([]string, 0, len(inst.Profiles()))
+	for _, profile := range inst.Profiles() {
+		profileNames = append(profileNames, profile.Name)
+	}
+
+	return api.InstancesPost{
+		Name: inst.Name(),
+		Type: api.InstanceType(inst.Type().String()),
+		InstancePut: api.InstancePut{
+			Architecture: architecture,
+			Config:       inst.ExpandedConfig(),
+			Devices:      inst.ExpandedDevices().CloneNative(),
+			Ephemeral:    inst.IsEphemeral(),
+			Profiles:     profileNames,
+			Stateful:     inst.IsStateful(),
+			Description:  inst.Description(),
+		},
+	}
+}
+
+// instancePlacementScorer returns a scorer based on the live resource usage of the online cluster members if weighted
+// scheduling is enabled through the `cluster.scheduler.weight.*` configuration keys, otherwise it returns nil.
+// The member states are queried for each placement rather than cached, so that every placement uses up-to-date usage.
+// Failing to retrieve the member states isn't fatal as placement then falls back to the instance count.
+func instancePlacementScorer(ctx context.Context, s *state.State) *placement.Scorer {
+	var weights placement.Weights
+	weights.CPU, weights.Memory, weights.Network, weights.Storage = s.GlobalConfig.ClusterSchedulerWeights()
+	if !weights.Enabled() {
+		return nil
+	}
+
+	var members []db.NodeInfo
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		allMembers, err := tx.GetNodes(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, member := range allMembers {
+			if member.State == db.ClusterMemberStateCreated && !member.IsOffline(s.GlobalConfig.OfflineThreshold()) {
+				members = append(members, member)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return nil, err
+		logger.Warn("Failed getting cluster members for weighted instance placement", logger.Ctx{"err": err})
+		return nil
 	}
 
-	// Early return if only a single candidate.
-	if len(filteredCandidates) == 1 {
-		return &filteredCandidates[0], nil
+	memberStates, err := cluster.ClusterState(s, s.Endpoints.NetworkCert(), members...)
+	if err != nil {
+		logger.Warn("Failed getting cluster member states for weighted instance placement", logger.Ctx{"err": err})
+		return nil
 	}
 
-	// Use filtered candidates to pick the node with least instances.
-	return tx.GetNodeWithLeastInstances(ctx, filteredCandidates)
+	return &placement.Scorer{
+		Weights:      weights,
+		MemberStates: memberStates,
+	}
 }
 
 func instanceFindStoragePool(s *state.State, projectName string, req *api.InstancesPost) (storagePool string, storagePoolProfile string, localRootDiskDeviceKey string, localRootDiskDevice map[string]string, err error) {
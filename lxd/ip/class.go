@@ -16,7 +16,9 @@ type Class struct {
 // ClassHTB represents htb qdisc class object.
 type ClassHTB struct {
 	Class
-	Rate string
+	Rate  string
+	Ceil  string
+	Burst string
 }
 
 // Add adds class to a node.
@@ -31,6 +33,14 @@ func (class *ClassHTB) Add() error {
 		cmd = append(cmd, "rate", class.Rate)
 	}
 
+	if class.Ceil != "" {
+		cmd = append(cmd, "ceil", class.Ceil)
+	}
+
+	if class.Burst != "" {
+		cmd = append(cmd, "burst", class.Burst, "cburst", class.Burst)
+	}
+
 	_, err := shared.RunCommand(context.TODO(), "tc", cmd...)
 	if err != nil {
 		return err
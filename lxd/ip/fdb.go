@@ -0,0 +1,88 @@
+package ip
+
+import (
+	"context"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// FDB represents arguments for forwarding database entry manipulation.
+type FDB struct {
+	DevName string
+	MAC     net.HardwareAddr
+	Dst     net.IP
+}
+
+// Show lists the forwarding database entries of the device that have a remote destination.
+func (f *FDB) Show() ([]FDB, error) {
+	out, err := shared.RunCommand(context.TODO(), "bridge", "fdb", "show", "dev", f.DevName)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := shared.SplitNTrimSpace(out, "\n", -1, true)
+	entries := make([]FDB, 0, len(lines))
+
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		mac, err := net.ParseMAC(fields[0])
+		if err != nil {
+			continue
+		}
+
+		dstIndex := slices.Index(fields, "dst")
+		if dstIndex < 0 || dstIndex+1 >= len(fields) {
+			continue
+		}
+
+		dst := net.ParseIP(fields[dstIndex+1])
+		if dst == nil {
+			continue
+		}
+
+		entries = append(entries, FDB{
+			DevName: f.DevName,
+			MAC:     mac,
+			Dst:     dst,
+		})
+	}
+
+	return entries, nil
+}
+
+// Append adds a forwarding database entry, keeping any existing entries for the same MAC address.
+func (f *FDB) Append() error {
+	_, err := shared.RunCommand(context.TODO(), "bridge", "fdb", "append", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Replace adds a static forwarding database entry, replacing any existing entry for the same MAC address.
+func (f *FDB) Replace() error {
+	_, err := shared.RunCommand(context.TODO(), "bridge", "fdb", "replace", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String(), "static")
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a forwarding database entry.
+func (f *FDB) Delete() error {
+	_, err := shared.RunCommand(context.TODO(), "bridge", "fdb", "delete", f.MAC.String(), "dev", f.DevName, "dst", f.Dst.String())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package ip
 
 import (
 	"context"
+	"net"
 
 	"github.com/canonical/lxd/shared"
 )
@@ -41,6 +42,16 @@ func (a *ActionPolice) AddAction() []string {
 	return result
 }
 
+// ActionMirred represents an action of 'mirred' type redirecting packets to the egress of another device.
+type ActionMirred struct {
+	Dev string
+}
+
+// AddAction generates a part of command specific for 'mirred' action.
+func (a *ActionMirred) AddAction() []string {
+	return []string{"action", "mirred", "egress", "redirect", "dev", a.Dev}
+}
+
 // Filter represents filter object.
 type Filter struct {
 	Dev      string
@@ -83,3 +94,98 @@ func (u32 *U32Filter) Add() error {
 
 	return nil
 }
+
+// MatchallFilter represents a traffic control filter matching all packets.
+type MatchallFilter struct {
+	Filter
+	Actions []Action
+}
+
+// Add adds the matchall traffic control filter to a node.
+func (m *MatchallFilter) Add() error {
+	cmd := []string{"filter", "add", "dev", m.Dev}
+	if m.Parent != "" {
+		cmd = append(cmd, "parent", m.Parent)
+	}
+
+	cmd = append(cmd, "protocol", m.Protocol, "matchall")
+
+	for _, action := range m.Actions {
+		actionCmd := action.AddAction()
+		cmd = append(cmd, actionCmd...)
+	}
+
+	if m.Flowid != "" {
+		cmd = append(cmd, "flowid", m.Flowid)
+	}
+
+	_, err := shared.RunCommand(context.TODO(), "tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FlowerFilter represents a flow based traffic control filter matching on MAC addresses.
+type FlowerFilter struct {
+	Filter
+	Prio   string
+	Handle string
+	SrcMAC net.HardwareAddr
+	DstMAC net.HardwareAddr
+}
+
+// mainCmd generates the part of the command identifying the filter.
+func (f *FlowerFilter) mainCmd(action string) []string {
+	cmd := []string{"filter", action, "dev", f.Dev}
+	if f.Parent != "" {
+		cmd = append(cmd, "parent", f.Parent)
+	}
+
+	cmd = append(cmd, "protocol", f.Protocol)
+
+	if f.Prio != "" {
+		cmd = append(cmd, "prio", f.Prio)
+	}
+
+	if f.Handle != "" {
+		cmd = append(cmd, "handle", f.Handle)
+	}
+
+	return append(cmd, "flower")
+}
+
+// Replace adds the flower traffic control filter to a node, replacing any existing filter with the same handle.
+func (f *FlowerFilter) Replace() error {
+	cmd := f.mainCmd("replace")
+
+	if f.SrcMAC != nil {
+		cmd = append(cmd, "src_mac", f.SrcMAC.String())
+	}
+
+	if f.DstMAC != nil {
+		cmd = append(cmd, "dst_mac", f.DstMAC.String())
+	}
+
+	if f.Flowid != "" {
+		cmd = append(cmd, "flowid", f.Flowid)
+	}
+
+	_, err := shared.RunCommand(context.TODO(), "tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the flower traffic control filter from a node.
+func (f *FlowerFilter) Delete() error {
+	_, err := shared.RunCommand(context.TODO(), "tc", f.mainCmd("del")...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
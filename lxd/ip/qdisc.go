@@ -10,6 +10,7 @@ import (
 type Qdisc struct {
 	Dev     string
 	Handle  string
+	Parent  string
 	Root    bool
 	Ingress bool
 }
@@ -20,6 +21,10 @@ func (qdisc *Qdisc) mainCmd() []string {
 		cmd = append(cmd, "handle", qdisc.Handle)
 	}
 
+	if qdisc.Parent != "" {
+		cmd = append(cmd, "parent", qdisc.Parent)
+	}
+
 	if qdisc.Root {
 		cmd = append(cmd, "root")
 	}
@@ -83,3 +88,21 @@ func (qdisc *QdiscHTB) Add() error {
 
 	return nil
 }
+
+// QdiscFqCodel represents the fair queuing controlled delay qdisc object.
+type QdiscFqCodel struct {
+	Qdisc
+}
+
+// Add adds qdisc to a node.
+func (qdisc *QdiscFqCodel) Add() error {
+	cmd := qdisc.mainCmd()
+	cmd = append(cmd, "fq_codel")
+
+	_, err := shared.RunCommand(context.TODO(), "tc", cmd...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// BackupTargetAction represents a lifecycle event action for backup targets.
+type BackupTargetAction string
+
+// All supported lifecycle events for backup targets.
+const (
+	BackupTargetCreated = BackupTargetAction(api.EventLifecycleBackupTargetCreated)
+	BackupTargetDeleted = BackupTargetAction(api.EventLifecycleBackupTargetDeleted)
+	BackupTargetUpdated = BackupTargetAction(api.EventLifecycleBackupTargetUpdated)
+)
+
+// Event creates the lifecycle event for an action on a backup target.
+func (a BackupTargetAction) Event(name string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "backup-targets", name)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
+
+// BackupTargetBackupAction represents a lifecycle event action for backups stored on backup targets.
+type BackupTargetBackupAction string
+
+// All supported lifecycle events for backups stored on backup targets.
+const (
+	BackupTargetBackupCreated = BackupTargetBackupAction(api.EventLifecycleBackupTargetBackupCreated)
+	BackupTargetBackupDeleted = BackupTargetBackupAction(api.EventLifecycleBackupTargetBackupDeleted)
+)
+
+// Event creates the lifecycle event for an action on a backup stored on a backup target.
+func (a BackupTargetBackupAction) Event(targetName string, backupName string, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "backup-targets", targetName, "backups", backupName)
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -0,0 +1,35 @@
+package lifecycle
+
+import (
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// Internal copy of the network port set interface.
+type networkPortSet interface {
+	Info() *api.NetworkPortSet
+	Project() string
+}
+
+// NetworkPortSetAction represents a lifecycle event action for network port sets.
+type NetworkPortSetAction string
+
+// All supported lifecycle events for network port sets.
+const (
+	NetworkPortSetCreated = NetworkPortSetAction(api.EventLifecycleNetworkPortSetCreated)
+	NetworkPortSetDeleted = NetworkPortSetAction(api.EventLifecycleNetworkPortSetDeleted)
+	NetworkPortSetUpdated = NetworkPortSetAction(api.EventLifecycleNetworkPortSetUpdated)
+	NetworkPortSetRenamed = NetworkPortSetAction(api.EventLifecycleNetworkPortSetRenamed)
+)
+
+// Event creates the lifecycle event for an action on a network port set.
+func (a NetworkPortSetAction) Event(n networkPortSet, requestor *api.EventLifecycleRequestor, ctx map[string]any) api.EventLifecycle {
+	u := api.NewURL().Path(version.APIVersion, "network-port-sets", n.Info().Name).Project(n.Project())
+
+	return api.EventLifecycle{
+		Action:    string(a),
+		Source:    u.String(),
+		Context:   ctx,
+		Requestor: requestor,
+	}
+}
@@ -430,6 +430,88 @@ func (c *Client) HandleEvent(event api.Event) {
 		message.WriteString(logEvent.Message)
 
 		entry.Line = message.String()
+	case api.EventTypeNetwork:
+		flowEvent := api.EventNetworkFlow{}
+
+		err := json.Unmarshal(event.Metadata, &flowEvent)
+		if err != nil {
+			return
+		}
+
+		if flowEvent.Project != "" {
+			entry.labels["project"] = flowEvent.Project
+		}
+
+		// Build map. These key-value pairs will either be added as labels, or be part of the
+		// log message itself.
+		context["source"] = flowEvent.Source
+		context["verdict"] = flowEvent.Verdict
+		context["protocol"] = flowEvent.Protocol
+		context["source_address"] = flowEvent.SourceAddress
+		context["destination_address"] = flowEvent.DestinationAddress
+		context["bytes_sent"] = strconv.FormatUint(flowEvent.BytesSent, 10)
+		context["bytes_received"] = strconv.FormatUint(flowEvent.BytesReceived, 10)
+		context["packets_sent"] = strconv.FormatUint(flowEvent.PacketsSent, 10)
+		context["packets_received"] = strconv.FormatUint(flowEvent.PacketsReceived, 10)
+
+		if flowEvent.SourcePort > 0 {
+			context["source_port"] = strconv.FormatUint(uint64(flowEvent.SourcePort), 10)
+		}
+
+		if flowEvent.DestinationPort > 0 {
+			context["destination_port"] = strconv.FormatUint(uint64(flowEvent.DestinationPort), 10)
+		}
+
+		optional := map[string]string{
+			"network":   flowEvent.Network,
+			"acl":       flowEvent.ACL,
+			"direction": flowEvent.Direction,
+			"icmp_type": flowEvent.ICMPType,
+			"icmp_code": flowEvent.ICMPCode,
+			// The "instance" label identifies the LXD server, use a different key for the instance name.
+			"instance_name": flowEvent.Instance,
+		}
+
+		for k, v := range optional {
+			if v != "" {
+				context[k] = v
+			}
+		}
+
+		// Add key-value pairs as labels but don't override any labels.
+		for k, v := range context {
+			if slices.Contains(c.cfg.labels, k) {
+				_, ok := entry.labels[k]
+				if !ok {
+					entry.labels[k] = v
+					delete(context, k)
+				}
+			}
+		}
+
+		keys := make([]string, 0, len(context))
+
+		for k := range context {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+
+		var line strings.Builder
+
+		// Add the remaining context as the message. The keys are sorted alphabetically.
+		for i, k := range keys {
+			if i > 0 {
+				line.WriteString(" ")
+			}
+
+			line.WriteString(k)
+			line.WriteString(`="`)
+			line.WriteString(context[k])
+			line.WriteString(`"`)
+		}
+
+		entry.Line = line.String()
 	case api.EventTypeSecurity:
 		secEvent := api.EventSecurity{}
 
@@ -106,3 +106,45 @@ func BenchmarkHandleEventLogging(b *testing.B) {
 		c.HandleEvent(event)
 	}
 }
+
+// TestHandleEventNetwork verifies that network flow events are turned into a sorted key-value log line,
+// with the configured labels extracted.
+func TestHandleEventNetwork(t *testing.T) {
+	flow := api.EventNetworkFlow{
+		Source:             "conntrack",
+		Network:            "lxdbr0",
+		Project:            "default",
+		Instance:           "c1",
+		Verdict:            "allow",
+		Protocol:           "tcp",
+		SourceAddress:      "10.0.0.2",
+		SourcePort:         45678,
+		DestinationAddress: "192.0.2.1",
+		DestinationPort:    443,
+		BytesSent:          100,
+		BytesReceived:      200,
+		PacketsSent:        1,
+		PacketsReceived:    2,
+	}
+
+	metadata, err := json.Marshal(flow)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newBenchClient([]string{api.EventTypeNetwork})
+	c.cfg.labels = []string{"network"}
+
+	c.HandleEvent(api.Event{Type: api.EventTypeNetwork, Timestamp: time.Now(), Metadata: metadata})
+
+	e := <-c.entries
+
+	expectedLine := `bytes_received="200" bytes_sent="100" destination_address="192.0.2.1" destination_port="443" instance_name="c1" packets_received="2" packets_sent="1" protocol="tcp" source="conntrack" source_address="10.0.0.2" source_port="45678" verdict="allow"`
+	if e.Line != expectedLine {
+		t.Fatalf("Unexpected line: %s", e.Line)
+	}
+
+	if e.labels["network"] != "lxdbr0" || e.labels["project"] != "default" || e.labels["type"] != api.EventTypeNetwork {
+		t.Fatalf("Unexpected labels: %v", e.labels)
+	}
+}
@@ -130,6 +130,10 @@ func main() {
 	forkfileCmd := cmdForkfile{global: &globalCmd}
 	app.AddCommand(forkfileCmd.command())
 
+	// forkkrun sub-command
+	forkkrunCmd := cmdForkkrun{global: &globalCmd}
+	app.AddCommand(forkkrunCmd.command())
+
 	// forklimits sub-command
 	forklimitsCmd := cmdForklimits{global: &globalCmd}
 	app.AddCommand(forklimitsCmd.command())
@@ -163,9 +163,15 @@ func (c *cmdActivateifneeded) run(cmd *cobra.Command, args []string) error {
 			logger.Debug("Daemon has scheduled instance snapshots, activating...")
 			return startLXD()
 		}
+
+		// Check for scheduled instance backups
+		if config["backups.schedule"] != "" {
+			logger.Debug("Daemon has scheduled instance backups, activating...")
+			return startLXD()
+		}
 	}
 
-	// Check for scheduled volume snapshots
+	// Check for scheduled volume snapshots and backups
 	var volumes []db.StorageVolumeArgs
 	err = s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
 		volumes, err = tx.GetStoragePoolVolumesWithType(ctx, cluster.StoragePoolVolumeTypeCustom, false)
@@ -184,6 +190,11 @@ func (c *cmdActivateifneeded) run(cmd *cobra.Command, args []string) error {
 			logger.Debug("Daemon has scheduled volume snapshots, activating...")
 			return startLXD()
 		}
+
+		if vol.Config["backups.schedule"] != "" {
+			logger.Debug("Daemon has scheduled volume backups, activating...")
+			return startLXD()
+		}
 	}
 
 	logger.Debug("No need to start the daemon now")
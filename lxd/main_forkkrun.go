@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/lxd/lxd-user/callhook"
+	"github.com/canonical/lxd/lxd/instance/drivers/libkrun"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/shared"
+)
+
+// forkkrunInitScript is run as the first process inside the microVM. It starts the lxd-agent from the config
+// share and then hands over to the guest's init system if present.
+const forkkrunInitScript = `mkdir -p /run/lxd_agent
+mount -t virtiofs config /run/lxd_agent
+(cd /run/lxd_agent && exec ./lxd-agent) &
+[ -x /sbin/init ] && exec /sbin/init
+wait
+`
+
+type cmdForkkrun struct {
+	global *cmdGlobal
+}
+
+func (c *cmdForkkrun) command() *cobra.Command {
+	// Main subcommand
+	cmd := &cobra.Command{}
+	cmd.Use = "forkkrun"
+	cmd.Short = "Run krun microVMs"
+	cmd.Long = `Description:
+  Run krun microVMs
+
+  This set of internal commands are used to start krun microVMs as
+  separate processes and to notify LXD once they have stopped.
+`
+	cmd.Hidden = true
+
+	// start
+	cmdStart := &cobra.Command{}
+	cmdStart.Use = "start <LXD path> <project> <instance> <config>"
+	cmdStart.Args = cobra.ExactArgs(4)
+	cmdStart.RunE = c.runStart
+	cmd.AddCommand(cmdStart)
+
+	// enter
+	cmdEnter := &cobra.Command{}
+	cmdEnter.Use = "enter <config>"
+	cmdEnter.Args = cobra.ExactArgs(1)
+	cmdEnter.RunE = c.runEnter
+	cmd.AddCommand(cmdEnter)
+
+	// Workaround for subcommand usage errors. See: https://github.com/spf13/cobra/issues/706
+	cmd.Args = cobra.NoArgs
+	cmd.Run = func(cmd *cobra.Command, args []string) { _ = cmd.Usage() }
+	return cmd
+}
+
+// loadConfig reads the microVM configuration file written by LXD.
+func (c *cmdForkkrun) loadConfig(path string) (*instancetype.KrunConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed reading config file %q: %w", path, err)
+	}
+
+	config := &instancetype.KrunConfig{}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing config file %q: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// runStart starts the microVM process, waits for it to exit and then runs the instance stop hook.
+func (c *cmdForkkrun) runStart(cmd *cobra.Command, args []string) error {
+	// Only root should run this
+	if os.Geteuid() != 0 {
+		return errors.New("This must be run as root")
+	}
+
+	lxdPath := args[0]
+	projectName := args[1]
+	instanceName := args[2]
+	configPath := args[3]
+
+	config, err := c.loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("Failed getting executable path: %w", err)
+	}
+
+	vmm := exec.Command(execPath, "forkkrun", "enter", configPath)
+	vmm.Stdin = os.Stdin
+	vmm.Stdout = os.Stdout
+	vmm.Stderr = os.Stderr
+
+	err = vmm.Start()
+	if err != nil {
+		return fmt.Errorf("Failed starting microVM: %w", err)
+	}
+
+	err = os.WriteFile(config.PidPath, []byte(strconv.Itoa(vmm.Process.Pid)+"\n"), 0600)
+	if err != nil {
+		_ = vmm.Process.Kill()
+		_ = vmm.Wait()
+		return fmt.Errorf("Failed writing microVM PID file: %w", err)
+	}
+
+	err = vmm.Wait()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "MicroVM exited: %v\n", err)
+	}
+
+	_ = os.Remove(config.PidPath)
+
+	// The microVM has no way of requesting a reboot, so any exit is treated as a stop.
+	err = os.Setenv("LXC_TARGET", "stop")
+	if err != nil {
+		return err
+	}
+
+	return callhook.HandleContainerHook(lxdPath, projectName, instanceName, "stop")
+}
+
+// runEnter configures libkrun and enters the microVM. It only returns on failure.
+func (c *cmdForkkrun) runEnter(cmd *cobra.Command, args []string) error {
+	config, err := c.loadConfig(args[0])
+	if err != nil {
+		return err
+	}
+
+	err = libkrun.Load()
+	if err != nil {
+		return err
+	}
+
+	ctx, err := libkrun.CreateContext()
+	if err != nil {
+		return fmt.Errorf("Failed creating libkrun context: %w", err)
+	}
+
+	defer func() { _ = ctx.Close() }()
+
+	err = ctx.SetVMConfig(config.CPUs, config.MemoryMiB)
+	if err != nil {
+		return fmt.Errorf("Failed setting CPU and memory limits: %w", err)
+	}
+
+	err = ctx.SetRoot(config.RootPath)
+	if err != nil {
+		return fmt.Errorf("Failed setting root filesystem: %w", err)
+	}
+
+	err = ctx.AddVirtioFS3("config", config.ConfigPath, 0, true)
+	if err != nil {
+		return fmt.Errorf("Failed adding config share: %w", err)
+	}
+
+	err = ctx.AddVsockPort2(shared.HTTPSDefaultPort, config.AgentPath, true)
+	if err != nil {
+		return fmt.Errorf("Failed adding agent socket: %w", err)
+	}
+
+	for _, nic := range config.NICs {
+		hwaddr, err := net.ParseMAC(nic.Hwaddr)
+		if err != nil || len(hwaddr) != 6 {
+			return fmt.Errorf("Invalid MAC address %q for interface %q", nic.Hwaddr, nic.Name)
+		}
+
+		err = ctx.AddNetTap(nic.Name, [6]byte(hwaddr), libkrun.CompatNetFeatures, 0)
+		if err != nil {
+			return fmt.Errorf("Failed adding interface %q: %w", nic.Name, err)
+		}
+	}
+
+	err = ctx.AddVirtioConsoleDefault(int(os.Stdin.Fd()), int(os.Stdout.Fd()), int(os.Stderr.Fd()))
+	if err != nil {
+		return fmt.Errorf("Failed adding console: %w", err)
+	}
+
+	err = ctx.SetExec("/bin/sh", []string{"-c", forkkrunInitScript}, config.Env)
+	if err != nil {
+		return fmt.Errorf("Failed setting init command: %w", err)
+	}
+
+	return ctx.StartEnter()
+}
@@ -22,13 +22,16 @@ type cmdForkstart struct {
 func (c *cmdForkstart) command() *cobra.Command {
 	// Main subcommand
 	cmd := &cobra.Command{}
-	cmd.Use = "forkstart <container name> <containers path> <config>"
+	cmd.Use = "forkstart <container name> <containers path> <config> [-- <command>...]"
 	cmd.Short = "Start the container"
 	cmd.Long = `Description:
   Start the container
 
   This internal command is used to start the container as a separate
   process.
+
+  When a command is provided, it is run as the init process of the
+  container instead of the one set in its configuration.
 `
 	cmd.RunE = c.run
 	cmd.Hidden = true
@@ -38,7 +41,7 @@ func (c *cmdForkstart) command() *cobra.Command {
 
 func (c *cmdForkstart) run(cmd *cobra.Command, args []string) error {
 	// Quick checks.
-	if len(args) != 3 {
+	if len(args) < 3 {
 		_ = cmd.Help()
 
 		if len(args) == 0 {
@@ -89,7 +92,12 @@ func (c *cmdForkstart) run(cmd *cobra.Command, args []string) error {
 		_ = unix.Dup3(int(logFile.Fd()), 2, 0)
 	}
 
-	err = d.Start()
+	if len(args) > 3 {
+		err = d.StartWithArgs(args[3:])
+	} else {
+		err = d.Start()
+	}
+
 	if err != nil {
 		// Wait for container to be stopped if the start failed.
 		// This is to ensure that container has transitioned from
@@ -748,6 +748,19 @@ func (c *cmdInit) askStoragePool(config *api.InitPreseed, d lxd.InstanceServer,
 					return err
 				}
 
+			case "nfs":
+				// Ask for the NFS server
+				pool.Config["nfs.host"], err = c.global.asker.AskString("Host name or IP address of the NFS server: ", "", nil)
+				if err != nil {
+					return err
+				}
+
+				// Ask for the NFS export
+				pool.Config["nfs.path"], err = c.global.asker.AskString("Path of the NFS export: ", "", validate.IsAbsFilePath)
+				if err != nil {
+					return err
+				}
+
 			default:
 				useEmptyBlockDev, err := c.global.asker.AskBool("Would you like to use an existing empty block device (e.g. a disk or partition)? (yes/no) [default=no]: ", "no")
 				if err != nil {
@@ -128,6 +128,10 @@ const (
 	MemoryUnevictableBytes
 	// MemoryWritebackBytes represents the amount of memory queued for syncing to disk.
 	MemoryWritebackBytes
+	// NetworkACLRuleBytesTotal represents the amount of bytes matched by a given network ACL rule.
+	NetworkACLRuleBytesTotal
+	// NetworkACLRulePacketsTotal represents the amount of packets matched by a given network ACL rule.
+	NetworkACLRulePacketsTotal
 	// NetworkReceiveBytesTotal represents the amount of received bytes on a given interface.
 	NetworkReceiveBytesTotal
 	// NetworkReceiveDropTotal represents the amount of received dropped bytes on a given interface.
@@ -211,6 +215,8 @@ var MetricNames = map[MetricType]string{
 	MemoryUnevictableBytes:      "lxd_memory_Unevictable_bytes",
 	MemoryWritebackBytes:        "lxd_memory_Writeback_bytes",
 	MemoryOOMKillsTotal:         "lxd_memory_OOM_kills_total",
+	NetworkACLRuleBytesTotal:    "lxd_network_acl_rule_bytes_total",
+	NetworkACLRulePacketsTotal:  "lxd_network_acl_rule_packets_total",
 	NetworkReceiveBytesTotal:    "lxd_network_receive_bytes_total",
 	NetworkReceiveDropTotal:     "lxd_network_receive_drop_total",
 	NetworkReceiveErrsTotal:     "lxd_network_receive_errs_total",
@@ -283,6 +289,8 @@ var MetricHeaders = map[MetricType]string{
 	MemoryUnevictableBytes:      "# HELP lxd_memory_Unevictable_bytes The amount of unevictable memory.",
 	MemoryWritebackBytes:        "# HELP lxd_memory_Writeback_bytes The amount of memory queued for syncing to disk.",
 	MemoryOOMKillsTotal:         "# HELP lxd_memory_OOM_kills_total The number of out of memory kills.",
+	NetworkACLRuleBytesTotal:    "# HELP lxd_network_acl_rule_bytes_total The amount of bytes matched by a given network ACL rule.",
+	NetworkACLRulePacketsTotal:  "# HELP lxd_network_acl_rule_packets_total The amount of packets matched by a given network ACL rule.",
 	NetworkReceiveBytesTotal:    "# HELP lxd_network_receive_bytes_total The amount of received bytes on a given interface.",
 	NetworkReceiveDropTotal:     "# HELP lxd_network_receive_drop_total The amount of received dropped bytes on a given interface.",
 	NetworkReceiveErrsTotal:     "# HELP lxd_network_receive_errs_total The amount of received errors on a given interface.",
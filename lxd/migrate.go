@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -141,6 +142,9 @@ type migrationSourceWs struct {
 	pushCertificate  string
 	pushOperationURL string
 	pushSecrets      map[string]string
+
+	// transferredBytes counts the bytes sent over the filesystem connection.
+	transferredBytes atomic.Int64
 }
 
 // Metadata returns a map where each key is a connection name and each value is
@@ -191,6 +195,7 @@ type migrationSink struct {
 	push                  bool
 	clusterMoveSourceName string
 	refresh               bool
+	refreshExcludeOlder   bool
 }
 
 // migrationSinkArgs arguments to configure migration sink.
@@ -206,6 +211,7 @@ type migrationSinkArgs struct {
 	instanceOnly          bool
 	live                  bool
 	refresh               bool
+	refreshExcludeOlder   bool
 	clusterMoveSourceName string
 	snapshots             []*migration.Snapshot
 
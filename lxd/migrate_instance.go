@@ -124,7 +124,7 @@ func (s *migrationSourceWs) Do(ctx context.Context, state *state.State, migrateO
 			return nil, fmt.Errorf("Failed getting migration source filesystem connection: %w", err)
 		}
 
-		return wsConn, nil
+		return &countingReadWriteCloser{ReadWriteCloser: wsConn, written: &s.transferredBytes}, nil
 	}
 
 	err = s.instance.MigrateSend(ctx, instance.MigrateSendArgs{
@@ -151,6 +151,11 @@ func (s *migrationSourceWs) Do(ctx context.Context, state *state.State, migrateO
 		return fmt.Errorf("Failed migration on source: %w", err)
 	}
 
+	err = migrateOp.ExtendMetadata(map[string]any{"transferred_bytes": s.transferredBytes.Load()})
+	if err != nil {
+		l.Warn("Failed recording transferred bytes on source", logger.Ctx{"err": err})
+	}
+
 	return nil
 }
 
@@ -165,6 +170,7 @@ func newMigrationSink(args *migrationSinkArgs) (*migrationSink, error) {
 		clusterMoveSourceName: args.clusterMoveSourceName,
 		push:                  args.push,
 		refresh:               args.refresh,
+		refreshExcludeOlder:   args.refreshExcludeOlder,
 	}
 
 	secretNames := []string{api.SecretNameControl, api.SecretNameFilesystem}
@@ -271,8 +277,9 @@ func (c *migrationSink) Do(ctx context.Context, instOpLock *operationlock.Instan
 			},
 			ClusterMoveSourceName: c.clusterMoveSourceName,
 		},
-		InstanceOperation: instOpLock,
-		Refresh:           c.refresh,
+		InstanceOperation:   instOpLock,
+		Refresh:             c.refresh,
+		RefreshExcludeOlder: c.refreshExcludeOlder,
 	}, migrateOp)
 	if err != nil {
 		l.Error("Failed migration on target", logger.Ctx{"err": err})
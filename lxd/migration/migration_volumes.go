@@ -69,6 +69,7 @@ type VolumeTargetArgs struct {
 	MigrationType         Type
 	TrackProgress         bool
 	Refresh               bool
+	RefreshExcludeOlder   bool // Only sync the snapshots taken after the last snapshot in common with the source.
 	ConversionOptions     []string
 	Live                  bool
 	VolumeSize            int64
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -182,3 +183,18 @@ func (c *migrationConn) Close() {
 		c.conn = nil
 	}
 }
+
+// countingReadWriteCloser wraps an io.ReadWriteCloser and adds the number of bytes written to a counter.
+type countingReadWriteCloser struct {
+	io.ReadWriteCloser
+
+	written *atomic.Int64
+}
+
+// Write writes to the wrapped connection and records the number of bytes written.
+func (c *countingReadWriteCloser) Write(p []byte) (int, error) {
+	n, err := c.ReadWriteCloser.Write(p)
+	c.written.Add(int64(n))
+
+	return n, err
+}
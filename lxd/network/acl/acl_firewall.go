@@ -3,9 +3,12 @@ package acl
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/canonical/lxd/lxd/db"
 	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -17,8 +20,10 @@ func FirewallApplyACLRules(ctx context.Context, s *state.State, aclProjectName s
 	var rejectRules []firewallDrivers.ACLRule
 	var allowRules []firewallDrivers.ACLRule
 
+	flowLogging := shared.IsTrue(aclNet.Config["security.flow_logging"])
+
 	// convertACLRules converts the ACL rules to Firewall ACL rules.
-	convertACLRules := func(direction string, logPrefix string, rules ...api.NetworkACLRule) error {
+	convertACLRules := func(direction string, logPrefix string, aclID int64, rules ...api.NetworkACLRule) error {
 		for ruleIndex, rule := range rules {
 			if rule.State == "disabled" {
 				continue
@@ -34,9 +39,15 @@ func FirewallApplyACLRules(ctx context.Context, s *state.State, aclProjectName s
 				DestinationPort: rule.DestinationPort,
 				ICMPType:        rule.ICMPType,
 				ICMPCode:        rule.ICMPCode,
+				CounterName:     ruleStatsName(aclID, direction, ruleIndex),
 			}
 
-			if rule.State == "logged" {
+			if flowLogging {
+				// Report every matching packet to the flow logger, labelled with the rule and its action.
+				firewallACLRule.Log = true
+				firewallACLRule.LogGroup = flowlog.NFLogGroup
+				firewallACLRule.LogName = firewallACLRule.CounterName + " " + rule.Action
+			} else if rule.State == "logged" {
 				firewallACLRule.Log = true
 				// Max 29 chars.
 				firewallACLRule.LogName = fmt.Sprintf("%s-%s-%d", logPrefix, direction, ruleIndex)
@@ -58,15 +69,17 @@ func FirewallApplyACLRules(ctx context.Context, s *state.State, aclProjectName s
 	}
 
 	logPrefix := aclNet.Name
+	aclInfos := []*api.NetworkACL{}
 
 	// Load ACLs specified by network.
 	for _, aclName := range shared.SplitNTrimSpace(aclNet.Config["security.acls"], ",", -1, true) {
+		var aclID int64
 		var aclInfo *api.NetworkACL
 
 		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 			var err error
 
-			_, aclInfo, err = tx.GetNetworkACL(ctx, aclProjectName, aclName)
+			aclID, aclInfo, err = tx.GetNetworkACL(ctx, aclProjectName, aclName)
 
 			return err
 		})
@@ -74,15 +87,23 @@ func FirewallApplyACLRules(ctx context.Context, s *state.State, aclProjectName s
 			return fmt.Errorf("Failed loading ACL %q for network %q: %w", aclName, aclNet.Name, err)
 		}
 
-		err = convertACLRules("ingress", logPrefix, aclInfo.Ingress...)
+		err = convertACLRules("ingress", logPrefix, aclID, aclInfo.Ingress...)
 		if err != nil {
 			return fmt.Errorf("Failed converting ACL %q ingress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
 		}
 
-		err = convertACLRules("egress", logPrefix, aclInfo.Egress...)
+		err = convertACLRules("egress", logPrefix, aclID, aclInfo.Egress...)
 		if err != nil {
 			return fmt.Errorf("Failed converting ACL %q egress rules for network %q: %w", aclInfo.Name, aclNet.Name, err)
 		}
+
+		aclInfos = append(aclInfos, aclInfo)
+	}
+
+	// Load the address and port sets referenced by the rules.
+	sets, err := firewallACLSets(ctx, s, aclProjectName, aclInfos...)
+	if err != nil {
+		return fmt.Errorf("Failed loading ACL sets for network %q: %w", aclNet.Name, err)
 	}
 
 	var rules = make([]firewallDrivers.ACLRule, 0, len(dropRules)+len(rejectRules)+len(allowRules)+2)
@@ -94,21 +115,62 @@ func FirewallApplyACLRules(ctx context.Context, s *state.State, aclProjectName s
 	egressAction, egressLogged := firewallACLDefaults(aclNet.Config, "egress")
 	ingressAction, ingressLogged := firewallACLDefaults(aclNet.Config, "ingress")
 
-	rules = append(rules, firewallDrivers.ACLRule{
+	egressRule := firewallDrivers.ACLRule{
 		Direction: "egress",
 		Action:    egressAction,
 		Log:       egressLogged,
 		LogName:   logPrefix + "-egress",
-	})
+	}
 
-	rules = append(rules, firewallDrivers.ACLRule{
+	ingressRule := firewallDrivers.ACLRule{
 		Direction: "ingress",
 		Action:    ingressAction,
 		Log:       ingressLogged,
 		LogName:   logPrefix + "-ingress",
+	}
+
+	if flowLogging {
+		for _, rule := range []*firewallDrivers.ACLRule{&egressRule, &ingressRule} {
+			rule.Log = true
+			rule.LogGroup = flowlog.NFLogGroup
+			rule.LogName += " " + rule.Action
+		}
+	}
+
+	rules = append(rules, egressRule, ingressRule)
+
+	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules, sets)
+}
+
+// firewallACLSets returns the address and port sets referenced by the rules of the ACLs, with the address sets
+// resolved to their current addresses.
+func firewallACLSets(ctx context.Context, s *state.State, aclProjectName string, aclInfos ...*api.NetworkACL) ([]firewallDrivers.ACLSet, error) {
+	ruleSets, err := loadRuleSets(ctx, s, aclProjectName, aclInfos...)
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make([]firewallDrivers.ACLSet, 0, len(ruleSets.addressSets)+len(ruleSets.portSets))
+
+	for name, set := range ruleSets.addressSets {
+		addresses, err := resolveAddressSet(ctx, s, aclProjectName, set.Entries)
+		if err != nil {
+			return nil, fmt.Errorf("Failed resolving address set %q: %w", name, err)
+		}
+
+		sets = append(sets, firewallDrivers.ACLSet{Name: name, Type: "address", Members: addresses})
+	}
+
+	for name, set := range ruleSets.portSets {
+		sets = append(sets, firewallDrivers.ACLSet{Name: name, Type: "port", Members: set.Entries})
+	}
+
+	// Keep a stable order so that the generated firewall config doesn't change needlessly.
+	slices.SortFunc(sets, func(a firewallDrivers.ACLSet, b firewallDrivers.ACLSet) int {
+		return strings.Compare(a.Type+"/"+a.Name, b.Type+"/"+b.Name)
 	})
 
-	return s.Firewall.NetworkApplyACLRules(aclNet.Name, rules)
+	return sets, nil
 }
 
 // firewallACLDefaults returns the action and logging mode to use for the specified direction's default rule.
@@ -211,6 +211,27 @@ func OVNEnsureACLs(ctx context.Context, s *state.State, l logger.Logger, client
 		delete(referencedACLs, aclStatus.name)
 	}
 
+	// Load the address and port sets referenced by the rules that are going to be applied, and make sure that the
+	// OVN address sets of the referenced address sets exist before the rules referencing them are added.
+	applyACLInfos := make([]*api.NetworkACL, 0, len(createACLPortGroups)+len(existingACLPortGroups))
+	for _, aclStatus := range createACLPortGroups {
+		applyACLInfos = append(applyACLInfos, aclStatus.aclInfo)
+	}
+
+	for _, aclStatus := range existingACLPortGroups {
+		applyACLInfos = append(applyACLInfos, aclStatus.aclInfo)
+	}
+
+	sets, err := loadRuleSets(ctx, s, aclProjectName, applyACLInfos...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sets.ovnSyncAddressSets(ctx, s, client)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create any missing port groups for the referenced ACLs before creating the requested ACL port groups.
 	// This way the referenced port groups will exist for any rules that referenced them in the creation ACLs.
 	// Note: We only create the empty port group, we do not add the ACL rules, so it is expected that any
@@ -263,7 +284,7 @@ func OVNEnsureACLs(ctx context.Context, s *state.State, l logger.Logger, client
 		}
 
 		// Now apply our ACL rules to port group (and any per-ACL-per-network port groups needed).
-		err = ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs)
+		err = ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs, sets)
 		if err != nil {
 			return nil, fmt.Errorf("Failed applying ACL rules to port group %q for security ACL %q setup: %w", portGroupName, aclStatus.name, err)
 		}
@@ -293,7 +314,7 @@ func OVNEnsureACLs(ctx context.Context, s *state.State, l logger.Logger, client
 		if aclStatus.aclInfo != nil {
 			l.Debug("Applying ACL rules to OVN port group", logger.Ctx{"networkACL": aclStatus.name, "portGroup": portGroupName})
 
-			err := ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs)
+			err := ovnApplyToPortGroup(l, client, aclStatus.aclInfo, portGroupName, aclNameIDs, aclNets, peerTargetNetIDs, sets)
 			if err != nil {
 				return nil, fmt.Errorf("Failed applying ACL rules to port group %q for security ACL %q setup: %w", portGroupName, aclStatus.name, err)
 			}
@@ -322,6 +343,10 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 				continue // Skip if the subject is an IP CIDR or IP range.
 			}
 
+			if strings.HasPrefix(subject, "$") {
+				continue // Skip address set references.
+			}
+
 			// Anything else must be a referenced ACL name.
 			// Record newly seen referenced ACL into authoritative list.
 			referencedACLNames[subject] = struct{}{}
@@ -338,7 +363,7 @@ func ovnAddReferencedACLs(info *api.NetworkACL, referencedACLNames map[string]st
 }
 
 // ovnApplyToPortGroup applies the rules in the specified ACL to the specified port group.
-func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, aclNets map[string]NetworkACLUsage, peerTargetNetIDs map[db.NetworkPeer]int64) error {
+func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.NetworkACL, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, aclNets map[string]NetworkACLUsage, peerTargetNetIDs map[db.NetworkPeer]int64, sets *ruleSets) error {
 	// Create slice for port group rules that has the capacity for ingress and egress rules, plus default rule.
 	portGroupRules := make([]openvswitch.OVNACLRule, 0, len(aclInfo.Ingress)+len(aclInfo.Egress)+1)
 	networkRules := make([]openvswitch.OVNACLRule, 0)
@@ -351,14 +376,16 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 				continue
 			}
 
-			ovnACLRule, networkSpecific, networkPeers, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs, peerTargetNetIDs)
+			ovnACLRule, networkSpecific, networkPeers, err := ovnRuleCriteriaToOVNACLRule(direction, &rule, portGroupName, aclNameIDs, peerTargetNetIDs, sets)
 			if err != nil {
 				return err
 			}
 
+			ovnACLRule.StatsName = fmt.Sprintf("%s-%s-%d", portGroupName, direction, ruleIndex)
+
 			if rule.State == "logged" {
 				ovnACLRule.Log = true
-				ovnACLRule.LogName = fmt.Sprintf("%s-%s-%d", portGroupName, direction, ruleIndex)
+				ovnACLRule.LogName = ovnACLRule.StatsName
 			}
 
 			if networkSpecific {
@@ -434,7 +461,7 @@ func ovnApplyToPortGroup(l logger.Logger, client *openvswitch.OVN, aclInfo *api.
 
 // ovnRuleCriteriaToOVNACLRule converts a LXD ACL rule into an OVNACLRule for an OVN port group or network.
 // Returns a bool indicating if any of the rule subjects are network specific.
-func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64) (openvswitch.OVNACLRule, bool, []db.NetworkPeer, error) {
+func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, portGroupName openvswitch.OVNPortGroup, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, sets *ruleSets) (openvswitch.OVNACLRule, bool, []db.NetworkPeer, error) {
 	networkSpecific := false
 	networkPeersNeeded := make([]db.NetworkPeer, 0)
 	portGroupRule := openvswitch.OVNACLRule{
@@ -468,7 +495,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 
 	// Add subject filters.
 	if rule.Source != "" {
-		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, peerTargetNetIDs, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
+		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("src", aclNameIDs, peerTargetNetIDs, sets, shared.SplitNTrimSpace(rule.Source, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, nil, err
 		}
@@ -482,7 +509,7 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 	}
 
 	if rule.Destination != "" {
-		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, peerTargetNetIDs, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
+		match, netSpecificMatch, networkPeers, err := ovnRuleSubjectToOVNACLMatch("dst", aclNameIDs, peerTargetNetIDs, sets, shared.SplitNTrimSpace(rule.Destination, ",", -1, false)...)
 		if err != nil {
 			return openvswitch.OVNACLRule{}, false, nil, err
 		}
@@ -500,11 +527,21 @@ func ovnRuleCriteriaToOVNACLRule(direction string, rule *api.NetworkACLRule, por
 		matchParts = append(matchParts, rule.Protocol)
 
 		if rule.SourcePort != "" {
-			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "src", shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...))
+			match, err := ovnRulePortSetsToOVNACLMatch(rule.Protocol, "src", sets, shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false)...)
+			if err != nil {
+				return openvswitch.OVNACLRule{}, false, nil, err
+			}
+
+			matchParts = append(matchParts, match)
 		}
 
 		if rule.DestinationPort != "" {
-			matchParts = append(matchParts, ovnRulePortToOVNACLMatch(rule.Protocol, "dst", shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...))
+			match, err := ovnRulePortSetsToOVNACLMatch(rule.Protocol, "dst", sets, shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false)...)
+			if err != nil {
+				return openvswitch.OVNACLRule{}, false, nil, err
+			}
+
+			matchParts = append(matchParts, match)
 		}
 	} else if slices.Contains([]string{"icmp4", "icmp6"}, rule.Protocol) {
 		matchParts = append(matchParts, rule.Protocol)
@@ -541,9 +578,37 @@ func ovnRulePortToOVNACLMatch(protocol string, direction string, portCriteria ..
 	return strings.Join(fieldParts, " || ")
 }
 
+// ovnRulePortSetsToOVNACLMatch converts protocol (tcp/udp), direction (src/dst) and port criteria list that can
+// reference port sets into an OVN match statement. Port set references are replaced by the ports of the set.
+func ovnRulePortSetsToOVNACLMatch(protocol string, direction string, sets *ruleSets, portCriteria ...string) (string, error) {
+	ports := make([]string, 0, len(portCriteria))
+
+	for _, portCriterion := range portCriteria {
+		setName, isSet := strings.CutPrefix(portCriterion, "$")
+		if !isSet {
+			ports = append(ports, portCriterion)
+			continue
+		}
+
+		set, found := sets.portSets[setName]
+		if !found {
+			return "", fmt.Errorf("Cannot find port set %q", setName)
+		}
+
+		ports = append(ports, set.Entries...)
+	}
+
+	// A rule only referencing empty port sets never matches.
+	if len(ports) == 0 {
+		return "0", nil
+	}
+
+	return ovnRulePortToOVNACLMatch(protocol, direction, ports...), nil
+}
+
 // ovnRuleSubjectToOVNACLMatch converts direction (src/dst) and subject criteria list into an OVN match statement.
 // Returns a bool indicating if any of the subjects are network specific.
-func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, subjectCriteria ...string) (string, bool, []db.NetworkPeer, error) {
+func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64, peerTargetNetIDs map[db.NetworkPeer]int64, sets *ruleSets, subjectCriteria ...string) (string, bool, []db.NetworkPeer, error) {
 	fieldParts := make([]string, 0, len(subjectCriteria))
 	networkSpecific := false
 	networkPeersNeeded := make([]db.NetworkPeer, 0)
@@ -583,7 +648,20 @@ func ovnRuleSubjectToOVNACLMatch(direction string, aclNameIDs map[string]int64,
 				// If not valid IP subnet, check if subject is ACL name or network peer name.
 				var subjectPortSelector openvswitch.OVNPortGroup
 				peerRef, hasPeerRef := strings.CutPrefix(subjectCriterion, "@")
-				if slices.Contains(ruleSubjectInternalAliases, subjectCriterion) {
+				setName, hasSetRef := strings.CutPrefix(subjectCriterion, "$")
+				if hasSetRef {
+					// Subject is an address set. Convert to address set criteria.
+					set, found := sets.addressSets[setName]
+					if !found {
+						return "", false, nil, fmt.Errorf("Cannot find address set %q", setName)
+					}
+
+					addrSetPrefix := OVNAddressSetPrefix(set.ID)
+
+					fieldParts = append(fieldParts, fmt.Sprintf("ip6.%s == $%s_ip6 || ip4.%s == $%s_ip4", direction, addrSetPrefix, direction, addrSetPrefix))
+
+					continue // Not a port based selector.
+				} else if slices.Contains(ruleSubjectInternalAliases, subjectCriterion) {
 					// Use pseudo port group name for special reserved port selector types.
 					// These will be expanded later for each network specific rule.
 					// Convert deprecated #internal to non-deprecated @internal if needed.
@@ -11,12 +11,14 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/canonical/lxd/client"
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/config"
 	"github.com/canonical/lxd/lxd/db"
 	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	firewallDrivers "github.com/canonical/lxd/lxd/firewall/drivers"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/state"
@@ -284,12 +286,25 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 	}
 
 	var acls map[string]int64
+	var addressSetNames []string
+	var portSetNames []string
 
 	err := d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		var err error
 
 		// Get map of ACL names to DB IDs (used for generating OVN port group names).
 		acls, err = tx.GetNetworkACLIDsByNames(ctx, d.Project())
+		if err != nil {
+			return err
+		}
+
+		// Get the names of the address and port sets that rules can reference.
+		addressSetNames, err = tx.GetNetworkSets(ctx, d.Project(), db.NetworkSetTypeAddress)
+		if err != nil {
+			return err
+		}
+
+		portSetNames, err = tx.GetNetworkSets(ctx, d.Project(), db.NetworkSetTypePort)
 
 		return err
 	})
@@ -310,7 +325,7 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 
 	// Validate Source field.
 	if rule.Source != "" {
-		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, shared.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames)
+		srcHasName, srcHasIPv4, srcHasIPv6, err = d.validateRuleSubjects("Source", direction, shared.SplitNTrimSpace(rule.Source, ",", -1, false), validSubjectNames, addressSetNames)
 		if err != nil {
 			return fmt.Errorf("Invalid Source: %w", err)
 		}
@@ -318,7 +333,7 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 
 	// Validate Destination field.
 	if rule.Destination != "" {
-		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, shared.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames)
+		dstHasName, dstHasIPv4, dstHasIPv6, err = d.validateRuleSubjects("Destination", direction, shared.SplitNTrimSpace(rule.Destination, ",", -1, false), validSubjectNames, addressSetNames)
 		if err != nil {
 			return fmt.Errorf("Invalid Destination: %w", err)
 		}
@@ -354,7 +369,7 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 
 		// Validate SourcePort field.
 		if rule.SourcePort != "" {
-			err := d.validatePorts(shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false))
+			err := d.validatePorts(shared.SplitNTrimSpace(rule.SourcePort, ",", -1, false), portSetNames)
 			if err != nil {
 				return fmt.Errorf("Invalid Source port: %w", err)
 			}
@@ -362,7 +377,7 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 
 		// Validate DestinationPort field.
 		if rule.DestinationPort != "" {
-			err := d.validatePorts(shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false))
+			err := d.validatePorts(shared.SplitNTrimSpace(rule.DestinationPort, ",", -1, false), portSetNames)
 			if err != nil {
 				return fmt.Errorf("Invalid Destination port: %w", err)
 			}
@@ -433,9 +448,10 @@ func (d *common) validateRule(ctx context.Context, direction ruleDirection, rule
 }
 
 // validateRuleSubjects checks that the source or destination subjects for a rule are valid.
-// Accepts a validSubjectNames list of valid ACL or special classifier names.
-// Returns whether the subjects include names, IPv4 and IPv6 addresses respectively.
-func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string) (hasName bool, hasIPv4 bool, hasIPv6 bool, err error) {
+// Accepts a validSubjectNames list of valid ACL or special classifier names and a validAddressSetNames list of
+// address sets that can be referenced using "$<name>".
+// Returns whether the subjects include names (including address sets), IPv4 and IPv6 addresses respectively.
+func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection, subjects []string, validSubjectNames []string, validAddressSetNames []string) (hasName bool, hasIPv4 bool, hasIPv6 bool, err error) {
 	// Check if named subjects are allowed in field/direction combination.
 	allowSubjectNames := (fieldName == "Source" && direction == ruleDirectionIngress) || (fieldName == "Destination" && direction == ruleDirectionEgress)
 
@@ -503,6 +519,16 @@ func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection,
 			}
 		}
 
+		// Check if it references an address set. These can contain addresses of both IP families.
+		setName, isSet := strings.CutPrefix(subject, "$")
+		if isSet {
+			if slices.Contains(validAddressSetNames, setName) {
+				return 0, nil // Found valid subject.
+			}
+
+			return 0, fmt.Errorf("Unknown address set %q", setName)
+		}
+
 		// Check if it is one of the valid subject names.
 		for _, n := range validSubjectNames {
 			if subject == n {
@@ -546,8 +572,18 @@ func (d *common) validateRuleSubjects(fieldName string, direction ruleDirection,
 }
 
 // validatePorts checks that the source or destination ports for a rule are valid.
-func (d *common) validatePorts(ports []string) error {
+// Accepts a validPortSetNames list of port sets that can be referenced using "$<name>".
+func (d *common) validatePorts(ports []string, validPortSetNames []string) error {
 	for _, port := range ports {
+		setName, isSet := strings.CutPrefix(port, "$")
+		if isSet {
+			if !slices.Contains(validPortSetNames, setName) {
+				return fmt.Errorf("Unknown port set %q", setName)
+			}
+
+			continue
+		}
+
 		err := validate.IsNetworkPortRange(port)
 		if err != nil {
 			return err
@@ -593,6 +629,8 @@ func (d *common) Update(ctx context.Context, config *api.NetworkACLPut, clientTy
 		})
 	}
 
+	d.forgetFlowLogACL()
+
 	// Get a list of networks that are using this ACL (either directly or indirectly via a NIC).
 	aclNets := map[string]NetworkACLUsage{}
 	err = NetworkUsage(context.TODO(), d.state, d.projectName, []string{d.info.Name}, aclNets)
@@ -716,6 +754,7 @@ func (d *common) Rename(ctx context.Context, newName string) error {
 
 	// Apply changes internally.
 	d.info.Name = newName
+	d.forgetFlowLogACL()
 
 	return nil
 }
@@ -731,9 +770,23 @@ func (d *common) Delete(ctx context.Context) error {
 		return errors.New("Cannot delete an ACL that is in use")
 	}
 
-	return d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+	err = d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		return tx.DeleteNetworkACL(ctx, d.id)
 	})
+	if err != nil {
+		return err
+	}
+
+	d.forgetFlowLogACL()
+
+	return nil
+}
+
+// forgetFlowLogACL removes the ACL from the flow logger's cache so that flow events use its current details.
+func (d *common) forgetFlowLogACL() {
+	if d.state.FlowLog != nil {
+		d.state.FlowLog.ForgetACL(d.id)
+	}
 }
 
 // GetLog gets the ACL log.
@@ -838,3 +891,184 @@ func (d *common) GetLog(ctx context.Context, clientType request.ClientType) (str
 
 	return strings.Join(logEntries, "\n") + "\n", nil
 }
+
+// ruleStatsName returns the name identifying the counters of the rule at the given index of an ACL.
+// This matches the name of the OVN ACL rules generated for the ACL port group.
+func ruleStatsName(aclID int64, direction string, ruleIndex int) string {
+	return fmt.Sprintf("%s-%s-%d", OVNACLPortGroupName(aclID), direction, ruleIndex)
+}
+
+// GetState gets the packet and byte counters of the ACL rules.
+// For normal requests the counters are aggregated across the cluster, otherwise only the counters of the local
+// member are returned.
+func (d *common) GetState(ctx context.Context, clientType request.ClientType) (*api.NetworkACLState, error) {
+	counters, err := d.localRuleCounters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleCounters := func(direction string, rules []api.NetworkACLRule) []api.NetworkACLRuleCounters {
+		result := make([]api.NetworkACLRuleCounters, len(rules))
+		for ruleIndex := range rules {
+			counter := counters[ruleStatsName(d.id, direction, ruleIndex)]
+			result[ruleIndex] = api.NetworkACLRuleCounters{Packets: counter.Packets, Bytes: counter.Bytes}
+		}
+
+		return result
+	}
+
+	aclState := &api.NetworkACLState{
+		Ingress: ruleCounters(string(ruleDirectionIngress), d.info.Ingress),
+		Egress:  ruleCounters(string(ruleDirectionEgress), d.info.Egress),
+	}
+
+	// Aggregates the counters from the rest of the cluster.
+	if clientType == request.ClientTypeNormal {
+		// Setup notifier to reach the rest of the cluster.
+		notifier, err := cluster.NewNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return nil, err
+		}
+
+		addCounters := func(total []api.NetworkACLRuleCounters, member []api.NetworkACLRuleCounters) {
+			for i := range min(len(total), len(member)) {
+				total[i].Packets += member[i].Packets
+				total[i].Bytes += member[i].Bytes
+			}
+		}
+
+		mu := sync.Mutex{}
+		err = notifier(func(member db.NodeInfo, client lxd.InstanceServer) error {
+			memberState, err := client.UseProject(d.projectName).GetNetworkACLState(d.info.Name)
+			if err != nil {
+				return err
+			}
+
+			// Prevent concurrent writes to the counters.
+			mu.Lock()
+			defer mu.Unlock()
+
+			addCounters(aclState.Ingress, memberState.Ingress)
+			addCounters(aclState.Egress, memberState.Egress)
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return aclState, nil
+}
+
+// localRuleCounters returns the counters of the ACL rules applied on this member, keyed by rule statistics name.
+// For bridge networks these come from the firewall counters of the rules, and for OVN networks from the
+// OpenFlow flows installed by the local OVN controller for the logical flows of the rules.
+func (d *common) localRuleCounters(ctx context.Context) (map[string]firewallDrivers.ACLRuleCounters, error) {
+	counters := make(map[string]firewallDrivers.ACLRuleCounters)
+
+	// Get a list of networks that are using this ACL (either directly or indirectly via a NIC).
+	aclNets := map[string]NetworkACLUsage{}
+	err := NetworkUsage(ctx, d.state, d.projectName, []string{d.info.Name}, aclNets)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting ACL network usage: %w", err)
+	}
+
+	hasOVNNets := false
+	for _, aclNet := range aclNets {
+		if aclNet.Type == "ovn" {
+			hasOVNNets = true
+			continue
+		}
+
+		// The ACL rules of bridge networks are only applied when the network is started on this member.
+		netCounters, err := d.state.Firewall.NetworkACLRuleCounters(aclNet.Name)
+		if err != nil {
+			d.logger.Debug("Failed getting network ACL rule counters", logger.Ctx{"network": aclNet.Name, "err": err})
+			continue
+		}
+
+		for name, counter := range netCounters {
+			total := counters[name]
+			total.Packets += counter.Packets
+			total.Bytes += counter.Bytes
+			counters[name] = total
+		}
+	}
+
+	if !hasOVNNets {
+		return counters, nil
+	}
+
+	logicalFlows, flowCounters, err := ovnRuleFlowCounters(d.state)
+	if err != nil {
+		return nil, err
+	}
+
+	namePrefix := string(OVNACLPortGroupName(d.id)) + "-"
+	for name, flowUUIDs := range logicalFlows {
+		if !strings.HasPrefix(name, namePrefix) {
+			continue
+		}
+
+		total := counters[name]
+		for _, flowUUID := range flowUUIDs {
+			cookie, err := openvswitch.OVNLogicalFlowCookie(flowUUID)
+			if err != nil {
+				continue
+			}
+
+			total.Packets += flowCounters[cookie].Packets
+			total.Bytes += flowCounters[cookie].Bytes
+		}
+
+		counters[name] = total
+	}
+
+	return counters, nil
+}
+
+// ovnRuleFlowCountersMaxAge is how long the OVN logical flows and flow counters of the ACL rules are reused, so that
+// getting the state of every ACL (such as when scraping metrics) only queries OVN once.
+const ovnRuleFlowCountersMaxAge = 5 * time.Second
+
+var ovnRuleFlowCountersMu sync.Mutex
+var ovnRuleFlowCountersTime time.Time
+var ovnRuleFlowCountersLogicalFlows map[string][]string
+var ovnRuleFlowCountersFlows map[uint64]openvswitch.OVSFlowCounters
+
+// ovnRuleFlowCounters returns the UUIDs of the southbound logical flows of all the OVN ACL rules keyed by rule name,
+// along with the counters of the flows installed on this member's OVN integration bridge keyed by flow cookie.
+func ovnRuleFlowCounters(s *state.State) (map[string][]string, map[uint64]openvswitch.OVSFlowCounters, error) {
+	ovnRuleFlowCountersMu.Lock()
+	defer ovnRuleFlowCountersMu.Unlock()
+
+	if time.Since(ovnRuleFlowCountersTime) < ovnRuleFlowCountersMaxAge {
+		return ovnRuleFlowCountersLogicalFlows, ovnRuleFlowCountersFlows, nil
+	}
+
+	client, err := openvswitch.NewOVN(s.GlobalConfig.NetworkOVNNorthboundConnection(), s.GlobalConfig.NetworkOVNSSL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting OVN client: %w", err)
+	}
+
+	logicalFlows, err := client.ACLLogicalFlows(ovnACLPortGroupPrefix)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed getting OVN logical flows of ACL rules: %w", err)
+	}
+
+	var flowCounters map[uint64]openvswitch.OVSFlowCounters
+	if len(logicalFlows) > 0 {
+		ovs := openvswitch.NewOVS()
+		flowCounters, err = ovs.BridgeFlowCounters(s.GlobalConfig.NetworkOVNIntegrationBridge())
+		if err != nil {
+			return nil, nil, fmt.Errorf("Failed getting OVN integration bridge flow counters: %w", err)
+		}
+	}
+
+	ovnRuleFlowCountersTime = time.Now()
+	ovnRuleFlowCountersLogicalFlows = logicalFlows
+	ovnRuleFlowCountersFlows = flowCounters
+
+	return logicalFlows, flowCounters, nil
+}
@@ -0,0 +1,616 @@
+package acl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/client"
+	"github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/config"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+	"github.com/canonical/lxd/shared/version"
+)
+
+// ovnAddressSetPrefix prefix used when naming the OVN address sets of network address sets.
+const ovnAddressSetPrefix = "lxd_set"
+
+// OVNAddressSetPrefix returns the OVN address set prefix for a network address set ID.
+func OVNAddressSetPrefix(addressSetID int64) openvswitch.OVNAddressSet {
+	return openvswitch.OVNAddressSet(fmt.Sprintf("%s%d", ovnAddressSetPrefix, addressSetID))
+}
+
+// setCommon represents the common functionality of network address sets and port sets.
+type setCommon struct {
+	logger logger.Logger
+	state  *state.State
+	info   *db.NetworkSet
+}
+
+// init initialise internal variables.
+func (d *setCommon) init(s *state.State, info *db.NetworkSet) {
+	d.info = info
+	d.state = s
+	d.logger = logger.AddContext(logger.Ctx{"project": info.Project, "networkSet": info.Name, "type": info.Type.String()})
+
+	if d.info.Entries == nil {
+		d.info.Entries = []string{}
+	}
+
+	if d.info.Config == nil {
+		d.info.Config = make(map[string]string)
+	}
+}
+
+// ID returns the network set ID.
+func (d *setCommon) ID() int64 {
+	return d.info.ID
+}
+
+// Project returns the project name.
+func (d *setCommon) Project() string {
+	return d.info.Project
+}
+
+// Name returns the network set name.
+func (d *setCommon) Name() string {
+	return d.info.Name
+}
+
+// Etag returns the values used for etag generation.
+func (d *setCommon) Etag() []any {
+	return []any{d.info.Name, d.info.Description, d.info.Entries, d.info.Config}
+}
+
+// usedByACLs returns the names of the ACLs in the project that have rules referencing the set.
+// If firstOnly is true then search stops at first result.
+func (d *setCommon) usedByACLs(ctx context.Context, firstOnly bool) ([]string, error) {
+	aclNames := []string{}
+
+	err := d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		projectACLNames, err := tx.GetNetworkACLs(ctx, d.info.Project)
+		if err != nil {
+			return err
+		}
+
+		for _, aclName := range projectACLNames {
+			_, aclInfo, err := tx.GetNetworkACL(ctx, d.info.Project, aclName)
+			if err != nil {
+				return err
+			}
+
+			if slices.Contains(aclSetReferences(aclInfo, d.info.Type), d.info.Name) {
+				aclNames = append(aclNames, aclName)
+
+				if firstOnly {
+					return nil
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting %s usage: %w", d.info.Type, err)
+	}
+
+	return aclNames, nil
+}
+
+// UsedBy returns a list of API endpoints referencing this set.
+func (d *setCommon) UsedBy() ([]string, error) {
+	aclNames, err := d.usedByACLs(context.TODO(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	usedBy := make([]string, 0, len(aclNames))
+	for _, aclName := range aclNames {
+		usedBy = append(usedBy, api.NewURL().Path(version.APIVersion, "network-acls", aclName).Project(d.info.Project).String())
+	}
+
+	return usedBy, nil
+}
+
+// isUsed returns whether or not the set is referenced by any ACL.
+func (d *setCommon) isUsed() (bool, error) {
+	aclNames, err := d.usedByACLs(context.TODO(), true)
+	if err != nil {
+		return false, err
+	}
+
+	return len(aclNames) > 0, nil
+}
+
+// validateConfig checks the set config. Only user keys are supported.
+func (d *setCommon) validateConfig(setConfig map[string]string) error {
+	for k := range setConfig {
+		if !config.IsUserConfig(k) {
+			return fmt.Errorf("Invalid config option %q", k)
+		}
+	}
+
+	return nil
+}
+
+// Rename renames the set if not in use.
+func (d *setCommon) Rename(ctx context.Context, newName string) error {
+	var existingNames []string
+
+	err := d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		existingNames, err = tx.GetNetworkSets(ctx, d.info.Project, d.info.Type)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if slices.Contains(existingNames, newName) {
+		return fmt.Errorf("A network %s by that name exists already", d.info.Type)
+	}
+
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot rename a network %s that is in use", d.info.Type)
+	}
+
+	err = ValidName(newName)
+	if err != nil {
+		return err
+	}
+
+	err = d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.RenameNetworkSet(ctx, d.info.ID, newName)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Apply changes internally.
+	d.info.Name = newName
+
+	return nil
+}
+
+// delete deletes the set if not in use.
+func (d *setCommon) delete(ctx context.Context) error {
+	isUsed, err := d.isUsed()
+	if err != nil {
+		return err
+	}
+
+	if isUsed {
+		return fmt.Errorf("Cannot delete a network %s that is in use", d.info.Type)
+	}
+
+	return d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.DeleteNetworkSet(ctx, d.info.ID)
+	})
+}
+
+// update stores the new set content in the database (when the request type is normal) and then applies the
+// changes to the networks using ACLs that reference the set.
+// The notify function is used to request the other cluster members to apply the changes too.
+func (d *setCommon) update(ctx context.Context, description string, entries []string, setConfig map[string]string, clientType request.ClientType, notify func(client lxd.InstanceServer) error) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	if clientType == request.ClientTypeNormal {
+		old := *d.info
+
+		err := d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			// Update database. Its important this occurs before we attempt to apply to networks using the
+			// set as ACL rule generation will inspect the database.
+			return tx.UpdateNetworkSet(ctx, d.info.ID, description, entries, setConfig)
+		})
+		if err != nil {
+			return err
+		}
+
+		// Apply changes internally.
+		d.info.Description = description
+		d.info.Entries = entries
+		d.info.Config = setConfig
+
+		revert.Add(func() {
+			_ = d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+				return tx.UpdateNetworkSet(ctx, old.ID, old.Description, old.Entries, old.Config)
+			})
+
+			*d.info = old
+		})
+	}
+
+	err := d.apply(ctx, clientType, notify)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// apply regenerates the firewall rules of the networks using ACLs that reference the set.
+func (d *setCommon) apply(ctx context.Context, clientType request.ClientType, notify func(client lxd.InstanceServer) error) error {
+	aclNames, err := d.usedByACLs(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	// Get a list of networks that are using the ACLs referencing this set.
+	aclNets := map[string]NetworkACLUsage{}
+	err = NetworkUsage(ctx, d.state, d.info.Project, aclNames, aclNets)
+	if err != nil {
+		return fmt.Errorf("Failed getting %s network usage: %w", d.info.Type, err)
+	}
+
+	hasOVNNets := false
+	hasBridgeNets := false
+
+	// Apply changes to non-OVN networks on this member.
+	for _, aclNet := range aclNets {
+		if aclNet.Type == "ovn" {
+			hasOVNNets = true
+			continue
+		}
+
+		hasBridgeNets = true
+
+		err = FirewallApplyACLRules(ctx, d.state, d.info.Project, aclNet)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If there are affected OVN networks, then apply the changes, but only if the request type is normal.
+	// This way we won't apply the same changes multiple times for each LXD cluster member.
+	if hasOVNNets && clientType == request.ClientTypeNormal {
+		client, err := openvswitch.NewOVN(d.state.GlobalConfig.NetworkOVNNorthboundConnection(), d.state.GlobalConfig.NetworkOVNSSL)
+		if err != nil {
+			return fmt.Errorf("Failed getting OVN client: %w", err)
+		}
+
+		if d.info.Type == db.NetworkSetTypeAddress {
+			// Rules reference the OVN address set, so only its addresses need updating.
+			err = ovnSyncAddressSet(ctx, d.state, client, d.info)
+			if err != nil {
+				return err
+			}
+		} else {
+			err = d.ovnApplyACLs(ctx, client, aclNames)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Apply changes to non-OVN networks on cluster members.
+	if clientType == request.ClientTypeNormal && hasBridgeNets {
+		// Notify all other nodes to update the set synchronously.
+		notifier, err := cluster.NewOperationNotifier(d.state, d.state.Endpoints.NetworkCert(), d.state.ServerCert(), cluster.NotifyAll)
+		if err != nil {
+			return err
+		}
+
+		err = notifier(func(member db.NodeInfo, client lxd.InstanceServer) error {
+			return notify(client.UseProject(d.info.Project))
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ovnApplyACLs reapplies the rules of each of the specified ACLs to the OVN networks using them.
+func (d *setCommon) ovnApplyACLs(ctx context.Context, client *openvswitch.OVN, aclNames []string) error {
+	var aclNameIDs map[string]int64
+
+	err := d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		// Get map of ACL names to DB IDs (used for generating OVN port group names).
+		aclNameIDs, err = tx.GetNetworkACLIDsByNames(ctx, d.info.Project)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed getting network ACL IDs for %s update: %w", d.info.Type, err)
+	}
+
+	for _, aclName := range aclNames {
+		aclNets := map[string]NetworkACLUsage{}
+		err = NetworkUsage(ctx, d.state, d.info.Project, []string{aclName}, aclNets)
+		if err != nil {
+			return fmt.Errorf("Failed getting ACL network usage: %w", err)
+		}
+
+		for k, v := range aclNets {
+			if v.Type != "ovn" {
+				delete(aclNets, k)
+			}
+		}
+
+		if len(aclNets) == 0 {
+			continue
+		}
+
+		_, err = OVNEnsureACLs(ctx, d.state, d.logger, client, d.info.Project, aclNameIDs, aclNets, []string{aclName}, true)
+		if err != nil {
+			return fmt.Errorf("Failed ensuring ACL %q is configured in OVN: %w", aclName, err)
+		}
+	}
+
+	return nil
+}
+
+// addressSet represents a network address set.
+type addressSet struct {
+	setCommon
+}
+
+// Info returns copy of internal info for the address set.
+func (d *addressSet) Info() *api.NetworkAddressSet {
+	return &api.NetworkAddressSet{
+		Name:        d.info.Name,
+		Description: d.info.Description,
+		Addresses:   append(make([]string, 0, len(d.info.Entries)), d.info.Entries...),
+		Config:      util.CopyConfig(d.info.Config),
+		UsedBy:      nil, // To indicate its not populated (use UsedBy() function to populate).
+		Project:     d.info.Project,
+	}
+}
+
+// validate checks the address set entries and config are valid.
+func (d *addressSet) validate(info *api.NetworkAddressSetPut) error {
+	err := d.validateConfig(info.Config)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range info.Addresses {
+		err := validateAddressSetEntry(entry)
+		if err != nil {
+			return fmt.Errorf("Invalid address %q: %w", entry, err)
+		}
+	}
+
+	return nil
+}
+
+// GetState returns the addresses the address set currently resolves to.
+func (d *addressSet) GetState(ctx context.Context) (*api.NetworkAddressSetState, error) {
+	addresses, err := resolveAddressSet(ctx, d.state, d.info.Project, d.info.Entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.NetworkAddressSetState{Addresses: addresses}, nil
+}
+
+// Update applies the supplied config to the address set.
+func (d *addressSet) Update(ctx context.Context, config *api.NetworkAddressSetPut, clientType request.ClientType) error {
+	err := d.validate(config)
+	if err != nil {
+		return err
+	}
+
+	return d.update(ctx, config.Description, config.Addresses, config.Config, clientType, d.notify(ctx))
+}
+
+// notify returns a function requesting a cluster member to apply the current address set.
+func (d *addressSet) notify(ctx context.Context) func(client lxd.InstanceServer) error {
+	return func(client lxd.InstanceServer) error {
+		op, err := client.UpdateNetworkAddressSet(d.info.Name, d.Info().Writable(), "")
+		if err == nil {
+			err = op.WaitContext(ctx)
+		}
+
+		return err
+	}
+}
+
+// Delete deletes the address set and its OVN address sets.
+func (d *addressSet) Delete(ctx context.Context) error {
+	err := d.delete(ctx)
+	if err != nil {
+		return err
+	}
+
+	var networks map[int64]api.Network
+
+	err = d.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		networks, err = tx.GetCreatedNetworksByProject(ctx, d.info.Project)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, network := range networks {
+		if network.Type != "ovn" {
+			continue
+		}
+
+		// The OVN address sets only exist if the address set was once used by an OVN network.
+		client, err := openvswitch.NewOVN(d.state.GlobalConfig.NetworkOVNNorthboundConnection(), d.state.GlobalConfig.NetworkOVNSSL)
+		if err != nil {
+			return fmt.Errorf("Failed getting OVN client: %w", err)
+		}
+
+		err = client.AddressSetDelete(OVNAddressSetPrefix(d.info.ID))
+		if err != nil {
+			return fmt.Errorf("Failed deleting OVN address set: %w", err)
+		}
+
+		break
+	}
+
+	return nil
+}
+
+// portSet represents a network port set.
+type portSet struct {
+	setCommon
+}
+
+// Info returns copy of internal info for the port set.
+func (d *portSet) Info() *api.NetworkPortSet {
+	return &api.NetworkPortSet{
+		Name:        d.info.Name,
+		Description: d.info.Description,
+		Ports:       append(make([]string, 0, len(d.info.Entries)), d.info.Entries...),
+		Config:      util.CopyConfig(d.info.Config),
+		UsedBy:      nil, // To indicate its not populated (use UsedBy() function to populate).
+		Project:     d.info.Project,
+	}
+}
+
+// validate checks the port set entries and config are valid.
+func (d *portSet) validate(info *api.NetworkPortSetPut) error {
+	err := d.validateConfig(info.Config)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range info.Ports {
+		err := validate.IsNetworkPortRange(port)
+		if err != nil {
+			return fmt.Errorf("Invalid port %q: %w", port, err)
+		}
+	}
+
+	return nil
+}
+
+// Update applies the supplied config to the port set.
+func (d *portSet) Update(ctx context.Context, config *api.NetworkPortSetPut, clientType request.ClientType) error {
+	err := d.validate(config)
+	if err != nil {
+		return err
+	}
+
+	return d.update(ctx, config.Description, config.Ports, config.Config, clientType, func(client lxd.InstanceServer) error {
+		op, err := client.UpdateNetworkPortSet(d.info.Name, d.Info().Writable(), "")
+		if err == nil {
+			err = op.WaitContext(ctx)
+		}
+
+		return err
+	})
+}
+
+// Delete deletes the port set.
+func (d *portSet) Delete(ctx context.Context) error {
+	return d.delete(ctx)
+}
+
+// validateAddressSetEntry checks an address set entry is an IP address, a CIDR subnet or a supported reference.
+func validateAddressSetEntry(entry string) error {
+	instanceName, isInstance := strings.CutPrefix(entry, addressSetEntryInstance)
+	if isInstance {
+		return validate.IsHostname(instanceName)
+	}
+
+	profileName, isProfile := strings.CutPrefix(entry, addressSetEntryProfile)
+	if isProfile {
+		if profileName == "" {
+			return errors.New("Profile name is required")
+		}
+
+		return nil
+	}
+
+	forward, isForward := strings.CutPrefix(entry, addressSetEntryForward)
+	if isForward {
+		networkName, listenAddress, found := strings.Cut(forward, "/")
+		if !found || networkName == "" {
+			return errors.New(`Network forward must be in the form "forward:<network>/<listen_address>"`)
+		}
+
+		return validate.IsNetworkAddress(listenAddress)
+	}
+
+	if net.ParseIP(entry) != nil {
+		return nil
+	}
+
+	return validate.IsNetworkAddressCIDR(entry)
+}
+
+// aclSetReferences returns the names of the sets of the specified type referenced by the rules of the ACL.
+func aclSetReferences(info *api.NetworkACL, setType db.NetworkSetType) []string {
+	var names []string
+
+	for _, rule := range append(slices.Clone(info.Ingress), info.Egress...) {
+		fields := []string{rule.Source, rule.Destination}
+		if setType == db.NetworkSetTypePort {
+			fields = []string{rule.SourcePort, rule.DestinationPort}
+		}
+
+		for _, field := range fields {
+			for _, criterion := range shared.SplitNTrimSpace(field, ",", -1, true) {
+				name, isSet := strings.CutPrefix(criterion, "$")
+				if isSet && !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+		}
+	}
+
+	return names
+}
+
+// ovnSyncAddressSet updates the OVN address sets of the address set with the addresses it currently resolves to.
+func ovnSyncAddressSet(ctx context.Context, s *state.State, client *openvswitch.OVN, set *db.NetworkSet) error {
+	addresses, err := resolveAddressSet(ctx, s, set.Project, set.Entries)
+	if err != nil {
+		return err
+	}
+
+	ipNets := make([]net.IPNet, 0, len(addresses))
+	for _, address := range addresses {
+		_, ipNet, err := net.ParseCIDR(address)
+		if err != nil {
+			ip := net.ParseIP(address)
+			if ip == nil {
+				continue
+			}
+
+			ipNet = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+			if ip.To4() != nil {
+				ipNet = &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}
+			}
+		}
+
+		ipNets = append(ipNets, *ipNet)
+	}
+
+	err = client.AddressSetSet(OVNAddressSetPrefix(set.ID), ipNets...)
+	if err != nil {
+		return fmt.Errorf("Failed updating OVN address set for address set %q: %w", set.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package acl
+
+import (
+	"context"
+
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// networkSet represents the functionality shared by network address sets and port sets.
+type networkSet interface {
+	// Info.
+	ID() int64
+	Project() string
+	Name() string
+	Etag() []any
+	UsedBy() ([]string, error)
+
+	// Modifications.
+	Rename(ctx context.Context, newName string) error
+	Delete(ctx context.Context) error
+}
+
+// NetworkAddressSet represents a network address set.
+type NetworkAddressSet interface {
+	networkSet
+
+	// Info.
+	Info() *api.NetworkAddressSet
+
+	// GetState.
+	GetState(ctx context.Context) (*api.NetworkAddressSetState, error)
+
+	// Modifications.
+	Update(ctx context.Context, config *api.NetworkAddressSetPut, clientType request.ClientType) error
+}
+
+// NetworkPortSet represents a network port set.
+type NetworkPortSet interface {
+	networkSet
+
+	// Info.
+	Info() *api.NetworkPortSet
+
+	// Modifications.
+	Update(ctx context.Context, config *api.NetworkPortSetPut, clientType request.ClientType) error
+}
@@ -0,0 +1,281 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/network/openvswitch"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// loadSet loads a network set of the specified type from the database by project and name.
+func loadSet(ctx context.Context, s *state.State, projectName string, setType db.NetworkSetType, name string) (*db.NetworkSet, error) {
+	var set *db.NetworkSet
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		set, err = tx.GetNetworkSet(ctx, projectName, setType, name)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+// createSet checks the name is valid and unused and creates a new network set record in the database.
+func createSet(ctx context.Context, s *state.State, set db.NetworkSet) error {
+	err := ValidName(set.Name)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		existingNames, err := tx.GetNetworkSets(ctx, set.Project, set.Type)
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(existingNames, set.Name) {
+			return api.StatusErrorf(http.StatusConflict, "A network %s by that name exists already", set.Type)
+		}
+
+		// Insert DB record.
+		_, err = tx.CreateNetworkSet(ctx, set)
+
+		return err
+	})
+}
+
+// LoadAddressSetByName loads and initialises a network address set from the database by project and name.
+func LoadAddressSetByName(ctx context.Context, s *state.State, projectName string, name string) (NetworkAddressSet, error) {
+	info, err := loadSet(ctx, s, projectName, db.NetworkSetTypeAddress, name)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &addressSet{}
+	set.init(s, info)
+
+	return set, nil
+}
+
+// CreateAddressSet validates supplied record and creates new network address set record in the database.
+func CreateAddressSet(ctx context.Context, s *state.State, projectName string, info *api.NetworkAddressSetsPost) error {
+	set := &addressSet{}
+	set.init(s, &db.NetworkSet{Project: projectName, Name: info.Name, Type: db.NetworkSetTypeAddress})
+
+	err := set.validate(&info.NetworkAddressSetPut)
+	if err != nil {
+		return err
+	}
+
+	return createSet(ctx, s, db.NetworkSet{
+		Project:     projectName,
+		Name:        info.Name,
+		Type:        db.NetworkSetTypeAddress,
+		Description: info.Description,
+		Entries:     info.Addresses,
+		Config:      info.Config,
+	})
+}
+
+// LoadPortSetByName loads and initialises a network port set from the database by project and name.
+func LoadPortSetByName(ctx context.Context, s *state.State, projectName string, name string) (NetworkPortSet, error) {
+	info, err := loadSet(ctx, s, projectName, db.NetworkSetTypePort, name)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &portSet{}
+	set.init(s, info)
+
+	return set, nil
+}
+
+// CreatePortSet validates supplied record and creates new network port set record in the database.
+func CreatePortSet(ctx context.Context, s *state.State, projectName string, info *api.NetworkPortSetsPost) error {
+	set := &portSet{}
+	set.init(s, &db.NetworkSet{Project: projectName, Name: info.Name, Type: db.NetworkSetTypePort})
+
+	err := set.validate(&info.NetworkPortSetPut)
+	if err != nil {
+		return err
+	}
+
+	return createSet(ctx, s, db.NetworkSet{
+		Project:     projectName,
+		Name:        info.Name,
+		Type:        db.NetworkSetTypePort,
+		Description: info.Description,
+		Entries:     info.Ports,
+		Config:      info.Config,
+	})
+}
+
+// addressSetCacheMu protects addressSetCache.
+var addressSetCacheMu sync.Mutex
+
+// addressSetCache holds the last known resolved addresses of the address sets with dynamic entries, keyed by ID.
+var addressSetCache map[int64][]string
+
+// addressSetsRefreshDelay is how long a triggered address sets refresh is delayed, so that the triggers of instances
+// starting or stopping together result in a single refresh.
+const addressSetsRefreshDelay = 2 * time.Second
+
+// addressSetsRefreshMu protects addressSetsRefreshPending.
+var addressSetsRefreshMu sync.Mutex
+
+// addressSetsRefreshPending indicates whether a triggered address sets refresh is scheduled.
+var addressSetsRefreshPending bool
+
+// TriggerAddressSetsRefresh schedules a refresh of the address sets after the addresses used by an instance may have
+// changed, such as when one of its NICs is started, stopped or gets a DHCP lease. Triggers are coalesced and the
+// refresh happens in the background.
+func TriggerAddressSetsRefresh(s *state.State) {
+	addressSetsRefreshMu.Lock()
+	defer addressSetsRefreshMu.Unlock()
+
+	if addressSetsRefreshPending {
+		return
+	}
+
+	addressSetsRefreshPending = true
+
+	time.AfterFunc(addressSetsRefreshDelay, func() {
+		addressSetsRefreshMu.Lock()
+		addressSetsRefreshPending = false
+		addressSetsRefreshMu.Unlock()
+
+		err := RefreshAddressSets(s.ShutdownCtx, s)
+		if err != nil {
+			logger.Warn("Failed refreshing network address sets", logger.Ctx{"err": err})
+		}
+	})
+}
+
+// RefreshAddressSets resolves the address sets that reference instances, profiles or network forwards and reapplies
+// the firewall rules using those whose resolved addresses have changed since the last refresh.
+func RefreshAddressSets(ctx context.Context, s *state.State) error {
+	var sets []db.NetworkSet
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		sets, err = tx.GetNetworkSetsWithType(ctx, db.NetworkSetTypeAddress)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed loading address sets: %w", err)
+	}
+
+	addressSetCacheMu.Lock()
+	defer addressSetCacheMu.Unlock()
+
+	oldCache := addressSetCache
+	addressSetCache = make(map[int64][]string, len(sets))
+
+	for i := range sets {
+		if !addressSetIsDynamic(sets[i].Entries) {
+			continue
+		}
+
+		addresses, err := resolveAddressSet(ctx, s, sets[i].Project, sets[i].Entries)
+		if err != nil {
+			logger.Warn("Failed resolving address set", logger.Ctx{"project": sets[i].Project, "addressSet": sets[i].Name, "err": err})
+			continue
+		}
+
+		addressSetCache[sets[i].ID] = addresses
+
+		oldAddresses, found := oldCache[sets[i].ID]
+		if found && slices.Equal(oldAddresses, addresses) {
+			continue
+		}
+
+		set := &addressSet{}
+		set.init(s, &sets[i])
+
+		// Reapply even if the set wasn't known before, as its addresses may have changed while not tracked.
+		err = set.apply(ctx, request.ClientTypeNormal, set.notify(ctx))
+		if err != nil {
+			// Forget the addresses so that applying is retried on the next refresh.
+			delete(addressSetCache, sets[i].ID)
+			logger.Warn("Failed applying address set", logger.Ctx{"project": sets[i].Project, "addressSet": sets[i].Name, "err": err})
+		}
+	}
+
+	return nil
+}
+
+// ruleSets contains the network sets of a project that are used when converting ACL rules referencing them.
+type ruleSets struct {
+	addressSets map[string]*db.NetworkSet
+	portSets    map[string]*db.NetworkSet
+}
+
+// loadRuleSets loads the network sets referenced by the rules of the supplied ACLs.
+func loadRuleSets(ctx context.Context, s *state.State, projectName string, aclInfos ...*api.NetworkACL) (*ruleSets, error) {
+	sets := &ruleSets{
+		addressSets: map[string]*db.NetworkSet{},
+		portSets:    map[string]*db.NetworkSet{},
+	}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		for _, aclInfo := range aclInfos {
+			if aclInfo == nil {
+				continue
+			}
+
+			for setType, setMap := range map[db.NetworkSetType]map[string]*db.NetworkSet{
+				db.NetworkSetTypeAddress: sets.addressSets,
+				db.NetworkSetTypePort:    sets.portSets,
+			} {
+				for _, name := range aclSetReferences(aclInfo, setType) {
+					_, found := setMap[name]
+					if found {
+						continue
+					}
+
+					set, err := tx.GetNetworkSet(ctx, projectName, setType, name)
+					if err != nil {
+						return fmt.Errorf("Failed loading network %s %q: %w", setType, name, err)
+					}
+
+					setMap[name] = set
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sets, nil
+}
+
+// ovnSyncAddressSets ensures the OVN address sets of the referenced address sets exist and are up to date.
+func (sets *ruleSets) ovnSyncAddressSets(ctx context.Context, s *state.State, client *openvswitch.OVN) error {
+	for _, set := range sets.addressSets {
+		err := ovnSyncAddressSet(ctx, s, client, set)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
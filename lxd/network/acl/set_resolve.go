@@ -0,0 +1,246 @@
+package acl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// NetworkLeases is linked from the network package to allow the DHCP leases of a managed network to be looked up
+// when resolving the instance addresses of an address set, without a circular import.
+var NetworkLeases func(s *state.State, projectName string, networkName string) ([]api.NetworkLease, error)
+
+// Address set entry prefixes used to reference addresses that LXD looks up dynamically.
+const (
+	addressSetEntryInstance = "instance:"
+	addressSetEntryProfile  = "profile:"
+	addressSetEntryForward  = "forward:"
+)
+
+// addressSetIsDynamic returns true if any of the address set entries reference addresses that can change without
+// the address set itself being modified.
+func addressSetIsDynamic(entries []string) bool {
+	for _, entry := range entries {
+		if shared.StringHasPrefix(entry, addressSetEntryInstance, addressSetEntryProfile, addressSetEntryForward) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveAddressSet returns the sorted list of unique IP addresses and CIDR subnets the address set entries
+// currently resolve to.
+func resolveAddressSet(ctx context.Context, s *state.State, projectName string, entries []string) ([]string, error) {
+	addresses := []string{}
+
+	addAddress := func(address string) {
+		ip := net.ParseIP(address)
+		if ip != nil {
+			address = ip.String() // Use the canonical form to detect duplicates.
+		}
+
+		if !slices.Contains(addresses, address) {
+			addresses = append(addresses, address)
+		}
+	}
+
+	var instanceNames []string
+
+	for _, entry := range entries {
+		instanceName, isInstance := strings.CutPrefix(entry, addressSetEntryInstance)
+		profileName, isProfile := strings.CutPrefix(entry, addressSetEntryProfile)
+		forward, isForward := strings.CutPrefix(entry, addressSetEntryForward)
+
+		switch {
+		case isInstance:
+			if !slices.Contains(instanceNames, instanceName) {
+				instanceNames = append(instanceNames, instanceName)
+			}
+
+		case isProfile:
+			profileInstanceNames, err := addressSetProfileInstances(ctx, s, projectName, profileName)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range profileInstanceNames {
+				if !slices.Contains(instanceNames, name) {
+					instanceNames = append(instanceNames, name)
+				}
+			}
+
+		case isForward:
+			forwardAddresses, err := addressSetForwardAddresses(ctx, s, projectName, forward)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, address := range forwardAddresses {
+				addAddress(address)
+			}
+
+		default:
+			addAddress(entry)
+		}
+	}
+
+	for _, instanceName := range instanceNames {
+		instanceAddresses, err := addressSetInstanceAddresses(ctx, s, projectName, instanceName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, address := range instanceAddresses {
+			addAddress(address)
+		}
+	}
+
+	slices.Sort(addresses)
+
+	return addresses, nil
+}
+
+// addressSetProfileInstances returns the names of the instances using the profile in the address set's project.
+func addressSetProfileInstances(ctx context.Context, s *state.State, projectName string, profileName string) ([]string, error) {
+	var instanceNames []string
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			if project.NetworkProjectFromRecord(&p) != projectName {
+				return nil
+			}
+
+			for _, profile := range inst.Profiles {
+				if profile.Name == profileName {
+					instanceNames = append(instanceNames, inst.Name)
+					break
+				}
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting instances using profile %q: %w", profileName, err)
+	}
+
+	return instanceNames, nil
+}
+
+// addressSetForwardAddresses returns the listen address and target addresses of the network forward referenced in
+// the "<network>/<listen_address>" format.
+func addressSetForwardAddresses(ctx context.Context, s *state.State, projectName string, forward string) ([]string, error) {
+	networkName, listenAddress, _ := strings.Cut(forward, "/")
+
+	var forwards map[int64]*api.NetworkForward
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		networkID, err := tx.GetNetworkID(ctx, projectName, networkName)
+		if err != nil {
+			return err
+		}
+
+		forwards, err = tx.GetNetworkForwards(ctx, networkID, false, listenAddress)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading network forward %q: %w", forward, err)
+	}
+
+	var addresses []string
+	for _, fwd := range forwards {
+		addresses = append(addresses, fwd.ListenAddress)
+
+		if fwd.Config["target_address"] != "" {
+			addresses = append(addresses, fwd.Config["target_address"])
+		}
+
+		for _, port := range fwd.Ports {
+			addresses = append(addresses, port.TargetAddress)
+		}
+	}
+
+	return addresses, nil
+}
+
+// addressSetInstanceAddresses returns the static and leased IP addresses of the NICs of the instance that are
+// connected to managed networks.
+func addressSetInstanceAddresses(ctx context.Context, s *state.State, projectName string, instanceName string) ([]string, error) {
+	var addresses []string
+	var instProject string
+	networkNames := []string{}
+
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		filter := cluster.InstanceFilter{Name: &instanceName}
+
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			if project.NetworkProjectFromRecord(&p) != projectName {
+				return nil
+			}
+
+			instProject = inst.Project
+			devices := instancetype.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+
+			for _, devConfig := range devices {
+				if devConfig["type"] != "nic" {
+					continue
+				}
+
+				for _, key := range []string{"ipv4.address", "ipv6.address"} {
+					for _, address := range shared.SplitNTrimSpace(devConfig[key], ",", -1, true) {
+						if net.ParseIP(address) != nil {
+							addresses = append(addresses, address)
+						}
+					}
+				}
+
+				if devConfig["network"] != "" && !slices.Contains(networkNames, devConfig["network"]) {
+					networkNames = append(networkNames, devConfig["network"])
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading instance %q: %w", instanceName, err)
+	}
+
+	if NetworkLeases == nil {
+		return addresses, nil
+	}
+
+	// Add the dynamically allocated addresses of the instance.
+	for _, networkName := range networkNames {
+		leases, err := NetworkLeases(s, projectName, networkName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed getting leases of network %q: %w", networkName, err)
+		}
+
+		for _, lease := range leases {
+			if lease.Hostname != instanceName || (lease.Project != "" && lease.Project != instProject) {
+				continue
+			}
+
+			if lease.Type != "static" && lease.Type != "dynamic" {
+				continue
+			}
+
+			addresses = append(addresses, lease.Address)
+		}
+	}
+
+	return addresses, nil
+}
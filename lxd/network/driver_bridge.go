@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
@@ -32,6 +33,7 @@ import (
 	"github.com/canonical/lxd/lxd/instance/instancetype"
 	"github.com/canonical/lxd/lxd/ip"
 	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	"github.com/canonical/lxd/lxd/network/openvswitch"
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
@@ -288,7 +290,7 @@ func (n *bridge) Validate(config map[string]string) error {
 		// The default value varies depending on whether the bridge uses a tunnel or a fan setup.
 		// ---
 		//  type: integer
-		//  defaultdesc: `1400` when tunnels are configured, otherwise `1500` if `bridge.mode=standard` or `1450` if `bridge.mode=fan`
+		//  defaultdesc: `1350` when WireGuard tunnels are configured, `1400` when other tunnels are configured, otherwise `1500` if `bridge.mode=standard` or `1450` if `bridge.mode=fan`
 		//  shortdesc: Bridge MTU
 		//  scope: global
 		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
@@ -595,6 +597,38 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: DNS zone name for IPv6 reverse DNS records
 		//  scope: global
 		"dns.zone.reverse.ipv6": validate.IsAny,
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=qos.rate)
+		// This is the bandwidth shared by the QoS classes of the network, in both directions.
+		// It is required when QoS classes are defined.
+		// ---
+		//  type: string
+		//  shortdesc: Total bandwidth available to the QoS classes (for example, `1Gbit`)
+		//  scope: global
+		"qos.rate": validate.Optional(validateBitRate),
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=qos.classes.NAME.rate)
+		// The rate is guaranteed to the NICs in the class, in both directions.
+		// The sum of the rates of all classes cannot exceed {config:option}`network-bridge-network-conf:qos.rate`.
+		// ---
+		//  type: string
+		//  shortdesc: Guaranteed bandwidth of the QoS class (for example, `100Mbit`)
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=qos.classes.NAME.ceil)
+		// The NICs in the class can borrow unused bandwidth from the other classes up to this rate.
+		// ---
+		//  type: string
+		//  defaultdesc: value of `qos.rate`
+		//  shortdesc: Maximum bandwidth of the QoS class (for example, `500Mbit`)
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=qos.classes.NAME.burst)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Amount of data that can be sent at the ceiling rate before shaping applies (for example, `64kB`)
+		//  scope: global
+
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=raw.dnsmasq)
 		// Additional `dnsmasq` configuration is appended to the generated configuration file.
 		// This is a low-level option and is not recommended for production use, as it allows for unsupported configurations that may cease to work in future versions.
@@ -648,6 +682,17 @@ func (n *bridge) Validate(config map[string]string) error {
 		//  shortdesc: Whether to log egress traffic that doesn’t match any ACL rule
 		//  scope: global
 		"security.acls.default.egress.logged": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=security.flow_logging)
+		// When enabled, connections of the instances on the network are reported as `network` events.
+		// If `security.acls` is set, the packets matching the ACL rules are reported too, instead of being written to the kernel log.
+		//
+		// See {ref}`events-network`.
+		// ---
+		//  type: bool
+		//  defaultdesc: `false`
+		//  shortdesc: Whether to report network flows as events
+		//  scope: global
+		"security.flow_logging": validate.Optional(validate.IsBool),
 
 		// lxdmeta:generate(entities=network-bridge; group=network-conf; key=user.*)
 		//
@@ -671,18 +716,24 @@ func (n *bridge) Validate(config map[string]string) error {
 				return fmt.Errorf("Network name too long for tunnel interface: %s-%s", n.name, fields[1])
 			}
 
+			// WireGuard tunnels need room for the "-wg" suffix of their WireGuard interface.
+			if config["tunnel."+fields[1]+".protocol"] == "wireguard" && len(n.name)+len(fields[1]) > 11 {
+				return fmt.Errorf("Network name too long for WireGuard tunnel interface: %s-%s-wg", n.name, fields[1])
+			}
+
 			tunnelKey := fields[2]
 
 			// Add the correct validation rule for the dynamic field based on last part of key.
 			switch tunnelKey {
 			case "protocol":
 				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.protocol)
-				// Possible values are `vxlan` and `gre`.
+				// Possible values are `vxlan`, `gre` and `wireguard`.
+				// The `wireguard` protocol encrypts the traffic, carrying a VXLAN tunnel over a WireGuard tunnel.
 				// ---
 				//  type: string
 				//  condition: standard mode
 				//  shortdesc: Tunneling protocol
-				rules[k] = validate.Optional(validate.IsOneOf("gre", "vxlan"))
+				rules[k] = validate.Optional(validate.IsOneOf("gre", "vxlan", "wireguard"))
 			case "local":
 				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.local)
 				//
@@ -694,21 +745,40 @@ func (n *bridge) Validate(config map[string]string) error {
 				rules[k] = validate.Optional(validate.IsNetworkAddress)
 			case "remote":
 				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.remote)
-				//
+				// For `wireguard`, this is the address of the remote WireGuard endpoint.
 				// ---
 				//  type: string
-				//  condition: `gre` or `vxlan`
-				//  required: not required for multicast `vxlan`
+				//  condition: `gre`, `vxlan` or `wireguard`
+				//  required: not required for multicast `vxlan` or `wireguard` in mesh mode
 				//  shortdesc: Remote address for the tunnel
 				rules[k] = validate.Optional(validate.IsNetworkAddress)
+			case "remote_key":
+				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.remote_key)
+				// The local public key is generated by LXD and shown by `lxc network info`.
+				// ---
+				//  type: string
+				//  condition: `wireguard`
+				//  required: not required in mesh mode
+				//  shortdesc: Public key of the remote WireGuard endpoint
+				rules[k] = validate.Optional(validateWireGuardKey)
+			case "mesh":
+				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.mesh)
+				// When enabled, LXD automatically connects the tunnel to the same tunnel on all other cluster members,
+				// and ignores {config:option}`network-bridge-network-conf:tunnel.NAME.remote` and {config:option}`network-bridge-network-conf:tunnel.NAME.remote_key`.
+				// ---
+				//  type: bool
+				//  condition: `wireguard`
+				//  defaultdesc: `false`
+				//  shortdesc: Whether to create a full mesh between cluster members
+				rules[k] = validate.Optional(validate.IsBool)
 			case "port":
 				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.port)
-				//
+				// For `wireguard`, this is the UDP port used both locally and on the remote endpoint.
 				// ---
 				//  type: integer
-				//  condition: `vxlan`
-				//  defaultdesc: `0`
-				//  shortdesc: Specific port to use for the `vxlan` tunnel
+				//  condition: `vxlan` or `wireguard`
+				//  defaultdesc: `0` for `vxlan`, `51820` for `wireguard`
+				//  shortdesc: Specific port to use for the tunnel
 				rules[k] = networkValidPort
 			case "group":
 				// lxdmeta:generate(entities=network-bridge; group=network-conf; key=tunnel.NAME.group)
@@ -724,7 +794,7 @@ func (n *bridge) Validate(config map[string]string) error {
 				//
 				// ---
 				//  type: integer
-				//  condition: `vxlan`
+				//  condition: `vxlan` or `wireguard`
 				//  shortdesc: `0`
 				//  shortdesc: Specific tunnel ID to use for the `vxlan` tunnel
 				rules[k] = validate.Optional(validate.IsInt64)
@@ -757,6 +827,14 @@ func (n *bridge) Validate(config map[string]string) error {
 
 	maps.Copy(rules, bgpRules)
 
+	// Add the QoS validation rules.
+	qosRules, err := qosValidationRules(config)
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(rules, qosRules)
+
 	// Validate the configuration.
 	err = n.validate(config, rules)
 	if err != nil {
@@ -765,6 +843,12 @@ func (n *bridge) Validate(config map[string]string) error {
 
 	// Perform composite key checks after per-key validation.
 
+	// Validate QoS classes.
+	_, err = qosClasses(config)
+	if err != nil {
+		return err
+	}
+
 	// Validate DNS zone names.
 	err = n.validateZoneNames(config)
 	if err != nil {
@@ -819,6 +903,8 @@ func (n *bridge) Validate(config map[string]string) error {
 
 			if config["bridge.mode"] == "fan" && mtu > 1450 {
 				return errors.New("Maximum MTU for a FAN bridge is 1450")
+			} else if n.hasWireGuardTunnels(config) && mtu > bridgeWireGuardMTU {
+				return fmt.Errorf("Maximum MTU for a bridge with WireGuard tunnels is %d", bridgeWireGuardMTU)
 			} else if n.hasTunnels(config) && mtu > 1400 {
 				return errors.New("Maximum MTU for a bridge with tunnels is 1400")
 			}
@@ -1324,6 +1410,8 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		}
 
 		bridge.MTU = uint32(mtuInt)
+	} else if n.hasWireGuardTunnels(n.config) {
+		bridge.MTU = bridgeWireGuardMTU
 	} else if len(tunnels) > 0 {
 		bridge.MTU = 1400
 	} else if n.config["bridge.mode"] == "fan" {
@@ -2077,6 +2165,11 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 			if err != nil {
 				return err
 			}
+		} else if tunProtocol == "wireguard" {
+			err := n.setupWireGuardTunnel(tunnel, bridge.MTU)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Bridge it and bring up.
@@ -2195,6 +2288,18 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 		return err
 	}
 
+	// Setup QoS classes.
+	err = n.qosApply(n.qosDevice())
+	if err != nil {
+		return err
+	}
+
+	// Setup flow logging.
+	err = n.flowLogSetup()
+	if err != nil {
+		return err
+	}
+
 	nodeEvacuated := n.state.DB.Cluster.LocalNodeIsEvacuated()
 
 	// Setup BGP.
@@ -2209,6 +2314,134 @@ func (n *bridge) setup(oldConfig map[string]string) error {
 	return nil
 }
 
+// qosDevice returns the interface carrying the traffic of the NICs connected to the network.
+// The traffic sent to the NICs leaves through the bridge.
+func (n *bridge) qosDevice() (string, bool) {
+	return n.name, true
+}
+
+// flowLogSetup registers the network with the flow logger if security.flow_logging is enabled.
+// Otherwise the network is unregistered.
+func (n *bridge) flowLogSetup() error {
+	if shared.IsFalseOrEmpty(n.config["security.flow_logging"]) {
+		n.state.FlowLog.Unregister(n.name)
+		return nil
+	}
+
+	var subnets []*net.IPNet
+	for _, key := range []string{"ipv4.address", "ipv6.address"} {
+		_, subnet, err := net.ParseCIDR(n.config[key])
+		if err == nil {
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	err := n.state.FlowLog.Register(flowlog.Network{
+		Name:      n.name,
+		Project:   n.project,
+		Interface: n.name,
+		Subnets:   subnets,
+		Resolve:   n.flowLogResolver(),
+	})
+	if err != nil {
+		return fmt.Errorf("Failed setting up flow logging: %w", err)
+	}
+
+	return nil
+}
+
+// flowLogResolver returns a function finding the instance using an address from the network's local leases.
+// Only the instances running on this member are resolved, as the flows are logged by the member running the instance.
+// The leases are cached for 30s to avoid loading them for every flow.
+func (n *bridge) flowLogResolver() func(ip net.IP) (string, string) {
+	var mu sync.Mutex
+	var lastRefresh time.Time
+	instances := map[string]api.NetworkLease{}
+
+	return func(ip net.IP) (string, string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if time.Since(lastRefresh) > 30*time.Second {
+			lastRefresh = time.Now()
+
+			leases, err := n.flowLogLeases()
+			if err != nil {
+				n.logger.Debug("Failed loading leases for flow logging", logger.Ctx{"err": err})
+			} else {
+				instances = leases
+			}
+		}
+
+		lease, found := instances[ip.String()]
+		if !found {
+			return "", ""
+		}
+
+		return lease.Project, lease.Hostname
+	}
+}
+
+// flowLogLeases returns the static and dynamic leases of the instances on this member, indexed by address.
+func (n *bridge) flowLogLeases() (map[string]api.NetworkLease, error) {
+	leases := map[string]api.NetworkLease{}
+	instanceProjects := map[string]string{}
+
+	_, netIP6, _ := net.ParseCIDR(n.config["ipv6.address"])
+	localMember := n.state.ServerName
+	err := UsedByInstanceDevices(n.state, n.Project(), n.Name(), n.Type(), func(inst db.InstanceArgs, nicName string, nicConfig map[string]string) error {
+		instanceProjects[inst.Name] = inst.Project
+
+		for _, key := range []string{"ipv4.address", "ipv6.address"} {
+			addr := net.ParseIP(nicConfig[key])
+			if addr != nil {
+				leases[addr.String()] = api.NetworkLease{Hostname: inst.Name, Address: addr.String(), Type: "static", Project: inst.Project}
+			}
+		}
+
+		// Add the SLAAC address.
+		hwaddr := nicConfig["hwaddr"]
+		if hwaddr == "" {
+			hwaddr = inst.Config[fmt.Sprintf("volatile.%s.hwaddr", nicName)]
+		}
+
+		hwAddr, _ := net.ParseMAC(hwaddr)
+		if netIP6 != nil && hwAddr != nil && shared.IsFalseOrEmpty(n.config["ipv6.dhcp.stateful"]) {
+			eui64IP6, err := eui64.ParseMAC(netIP6.IP, hwAddr)
+			if err == nil {
+				leases[eui64IP6.String()] = api.NetworkLease{Hostname: inst.Name, Address: eui64IP6.String(), Type: "dynamic", Project: inst.Project}
+			}
+		}
+
+		return nil
+	}, dbCluster.InstanceFilter{Node: &localMember})
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the local dynamic leases are needed, which don't include the instance project.
+	localLeases, err := n.Leases("", request.ClientTypeNotifier)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, lease := range localLeases {
+		if lease.Type != "dynamic" {
+			continue
+		}
+
+		_, found := leases[lease.Address]
+		if found {
+			continue
+		}
+
+		lease.Project = instanceProjects[lease.Hostname]
+		leases[lease.Address] = lease
+	}
+
+	return leases, nil
+}
+
 // Stop stops the network.
 func (n *bridge) Stop() error {
 	n.logger.Debug("Stop")
@@ -2223,6 +2456,15 @@ func (n *bridge) Stop() error {
 		return err
 	}
 
+	// Clear QoS classes.
+	err = qosClear(n.name, qosIFBName(n.id))
+	if err != nil {
+		return err
+	}
+
+	// Stop flow logging.
+	n.state.FlowLog.Unregister(n.name)
+
 	// Kill any existing dnsmasq and forkdns daemon for this network
 	err = dnsmasq.Kill(n.name, false)
 	if err != nil {
@@ -2452,9 +2694,16 @@ func (n *bridge) spawnForkDNS(listenAddress string) error {
 	return nil
 }
 
-// HandleHeartbeat refreshes forkdns servers. Retrieves the IPv4 address of each cluster node (excluding ourselves)
-// for this network. It then updates the forkdns server list file if there are changes.
+// HandleHeartbeat refreshes WireGuard mesh peers and forkdns servers. Retrieves the IPv4 address of each cluster node
+// (excluding ourselves) for this network. It then updates the forkdns server list file if there are changes.
 func (n *bridge) HandleHeartbeat(heartbeatData *cluster.APIHeartbeat) error {
+	// Refresh the peers of WireGuard tunnels in mesh mode.
+	// Failures are only logged so that the forkdns servers still get refreshed.
+	err := n.refreshWireGuardMeshPeers(heartbeatData)
+	if err != nil {
+		n.logger.Warn("Failed refreshing WireGuard mesh peers", logger.Ctx{"err": err})
+	}
+
 	// Make sure forkdns has been setup.
 	if !shared.PathExists(shared.VarPath("networks", n.name, "forkdns.pid")) {
 		return nil
@@ -2548,6 +2797,344 @@ func (n *bridge) hasTunnels(config map[string]string) bool {
 	return false
 }
 
+// hasWireGuardTunnels returns true if the given config contains any WireGuard tunnel.
+func (n *bridge) hasWireGuardTunnels(config map[string]string) bool {
+	for k, v := range config {
+		if strings.HasPrefix(k, "tunnel.") && strings.HasSuffix(k, ".protocol") && v == "wireguard" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wireGuardKeyPath returns the path of the private key file of the given WireGuard tunnel.
+func (n *bridge) wireGuardKeyPath(tunnel string) string {
+	return shared.VarPath("networks", n.name, "wireguard", tunnel+".key")
+}
+
+// wireGuardLocalPublicKey returns the public key of the local end of the given WireGuard tunnel.
+// If create is true, a new private key is generated if the tunnel doesn't have one yet.
+func (n *bridge) wireGuardLocalPublicKey(tunnel string, create bool) (string, error) {
+	keyPath := n.wireGuardKeyPath(tunnel)
+
+	content, err := os.ReadFile(keyPath)
+	if errors.Is(err, fs.ErrNotExist) && create {
+		privateKey, err := wireGuardGenerateKey()
+		if err != nil {
+			return "", err
+		}
+
+		err = os.MkdirAll(filepath.Dir(keyPath), 0700)
+		if err != nil {
+			return "", fmt.Errorf("Failed creating WireGuard key directory: %w", err)
+		}
+
+		err = os.WriteFile(keyPath, []byte(privateKey+"\n"), 0600)
+		if err != nil {
+			return "", fmt.Errorf("Failed writing WireGuard private key for tunnel %q: %w", tunnel, err)
+		}
+
+		content = []byte(privateKey)
+	} else if err != nil {
+		return "", err
+	}
+
+	return wireGuardPublicKey(strings.TrimSpace(string(content)))
+}
+
+// wireGuardPort returns the UDP port of the given WireGuard tunnel.
+func (n *bridge) wireGuardPort(tunnel string) string {
+	port := n.config["tunnel."+tunnel+".port"]
+	if port == "" {
+		return wireGuardDefaultPort
+	}
+
+	return port
+}
+
+// setupWireGuardTunnel sets up the WireGuard interface of the given tunnel and the VXLAN interface carried over it.
+// The VXLAN interface is named after the tunnel and gets attached to the bridge by the caller.
+func (n *bridge) setupWireGuardTunnel(tunnel string, mtu uint32) error {
+	getConfig := func(key string) string {
+		return n.config[fmt.Sprintf("tunnel.%s.%s", tunnel, key)]
+	}
+
+	tunName := fmt.Sprintf("%s-%s", n.name, tunnel)
+	wgName := tunName + "-wg"
+	tunPort := n.wireGuardPort(tunnel)
+
+	publicKey, err := n.wireGuardLocalPublicKey(tunnel, true)
+	if err != nil {
+		return err
+	}
+
+	localAddr, err := wireGuardTunnelAddress(publicKey)
+	if err != nil {
+		return err
+	}
+
+	wg := &ip.Wireguard{
+		Link: ip.Link{Name: wgName},
+	}
+
+	err = wg.Add()
+	if err != nil {
+		return err
+	}
+
+	// Leave room for the VXLAN encapsulation.
+	err = wg.SetMTU(mtu + wireGuardVXLANOverhead)
+	if err != nil {
+		return err
+	}
+
+	_, err = shared.RunCommand(context.TODO(), "wg", "set", wgName, "listen-port", tunPort, "private-key", n.wireGuardKeyPath(tunnel))
+	if err != nil {
+		return fmt.Errorf("Failed configuring WireGuard interface %q: %w", wgName, err)
+	}
+
+	addr := &ip.Addr{
+		DevName: wgName,
+		Address: localAddr.String() + "/64",
+		Family:  ip.FamilyV6,
+	}
+
+	err = addr.Add()
+	if err != nil {
+		return err
+	}
+
+	err = wg.SetUp()
+	if err != nil {
+		return err
+	}
+
+	tunID := getConfig("id")
+	if tunID == "" {
+		tunID = "1"
+	}
+
+	vxlan := &ip.Vxlan{
+		Link:    ip.Link{Name: tunName},
+		VxlanID: tunID,
+		DevName: wgName,
+		Local:   localAddr.String(),
+		DstPort: wireGuardVXLANPort,
+	}
+
+	err = vxlan.Add()
+	if err != nil {
+		return err
+	}
+
+	// The peers of mesh tunnels are configured on cluster heartbeats.
+	if shared.IsTrue(getConfig("mesh")) {
+		return nil
+	}
+
+	peers := []wireGuardPeer{}
+
+	// Skip partial configs.
+	tunRemote := getConfig("remote")
+	tunRemoteKey := getConfig("remote_key")
+	if tunRemote != "" && tunRemoteKey != "" {
+		peers = append(peers, wireGuardPeer{
+			PublicKey: tunRemoteKey,
+			Endpoint:  net.JoinHostPort(tunRemote, tunPort),
+		})
+	}
+
+	return wireGuardSetPeers(wgName, tunName, peers)
+}
+
+// wireGuardMeshMembersMaxAge is how long the WireGuard tunnels reported by a cluster member are reused
+// before the member is queried again.
+const wireGuardMeshMembersMaxAge = time.Minute
+
+// wireGuardMeshMember holds the WireGuard mesh peers derived from the network state of a cluster member.
+type wireGuardMeshMember struct {
+	time  time.Time
+	peers map[string]wireGuardPeer
+}
+
+// wireGuardMeshMembers caches the mesh peers of each cluster member, indexed by project and network name and
+// then by member address.
+var wireGuardMeshMembers = map[string]map[string]wireGuardMeshMember{}
+var wireGuardMeshMembersMu sync.Mutex
+
+// wireGuardMeshMemberPeers queries a cluster member for the WireGuard tunnels it has on the network and
+// returns the peers to configure for them. A member without the network has no peers.
+func (n *bridge) wireGuardMeshMemberPeers(address string, meshTunnels []string) (map[string]wireGuardPeer, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing cluster member address %q: %w", address, err)
+	}
+
+	client, err := cluster.Connect(context.Background(), address, n.state.Endpoints.NetworkCert(), n.state.ServerCert(), true)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]wireGuardPeer, len(meshTunnels))
+
+	state, err := client.UseProject(n.project).GetNetworkState(n.name)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return peers, nil
+		}
+
+		return nil, err
+	}
+
+	for _, tunnel := range meshTunnels {
+		tunState, ok := state.WireGuard[tunnel]
+		if !ok {
+			continue
+		}
+
+		peers[tunnel] = wireGuardPeer{
+			PublicKey: tunState.PublicKey,
+			Endpoint:  net.JoinHostPort(host, strconv.FormatInt(tunState.ListenPort, 10)),
+		}
+	}
+
+	return peers, nil
+}
+
+// refreshWireGuardMeshPeers connects the WireGuard tunnels in mesh mode to the same tunnels on all other
+// cluster members, using the public keys and ports the members report in their network state.
+// Members are only queried again once their cached state expired, and peers are only reconfigured when the
+// public key or endpoint of a peer differs from what is configured on the tunnel.
+func (n *bridge) refreshWireGuardMeshPeers(heartbeatData *cluster.APIHeartbeat) error {
+	meshTunnels := []string{}
+	for _, tunnel := range n.getTunnels() {
+		if n.config["tunnel."+tunnel+".protocol"] == "wireguard" && shared.IsTrue(n.config["tunnel."+tunnel+".mesh"]) {
+			meshTunnels = append(meshTunnels, tunnel)
+		}
+	}
+
+	cacheKey := n.project + "/" + n.name
+	if len(meshTunnels) == 0 || !n.isRunning() {
+		wireGuardMeshMembersMu.Lock()
+		delete(wireGuardMeshMembers, cacheKey)
+		wireGuardMeshMembersMu.Unlock()
+
+		return nil
+	}
+
+	wireGuardMeshMembersMu.Lock()
+	cached := wireGuardMeshMembers[cacheKey]
+	wireGuardMeshMembersMu.Unlock()
+
+	// Only keep the online members, so that members coming back online are queried again.
+	members := make(map[string]wireGuardMeshMember, len(heartbeatData.Members))
+	localClusterAddress := n.state.LocalConfig.ClusterAddress()
+	for _, node := range heartbeatData.Members {
+		if node.Address == localClusterAddress {
+			// No need to query ourselves.
+			continue
+		}
+
+		if !node.Online {
+			n.logger.Debug("Excluding offline member from WireGuard mesh peers refresh", logger.Ctx{"address": node.Address, "ID": node.ID, "raftID": node.RaftID, "lastHeartbeat": node.LastHeartbeat})
+			continue
+		}
+
+		member, ok := cached[node.Address]
+		if ok && time.Since(member.time) < wireGuardMeshMembersMaxAge {
+			members[node.Address] = member
+			continue
+		}
+
+		peers, err := n.wireGuardMeshMemberPeers(node.Address, meshTunnels)
+		if err != nil {
+			n.logger.Warn("Failed getting WireGuard mesh peers from cluster member", logger.Ctx{"address": node.Address, "err": err})
+			continue
+		}
+
+		members[node.Address] = wireGuardMeshMember{time: time.Now(), peers: peers}
+	}
+
+	wireGuardMeshMembersMu.Lock()
+	wireGuardMeshMembers[cacheKey] = members
+	wireGuardMeshMembersMu.Unlock()
+
+	for _, tunnel := range meshTunnels {
+		tunName := fmt.Sprintf("%s-%s", n.name, tunnel)
+
+		peers := []wireGuardPeer{}
+		wantEndpoints := map[string]string{}
+		for _, member := range members {
+			peer, ok := member.peers[tunnel]
+			if !ok {
+				continue
+			}
+
+			peers = append(peers, peer)
+			wantEndpoints[peer.PublicKey] = peer.Endpoint
+		}
+
+		endpoints, err := wireGuardPeerEndpoints(tunName + "-wg")
+		if err != nil {
+			return err
+		}
+
+		if maps.Equal(endpoints, wantEndpoints) {
+			continue
+		}
+
+		n.logger.Debug("Refreshing WireGuard mesh peers", logger.Ctx{"tunnel": tunnel})
+
+		err = wireGuardSetPeers(tunName+"-wg", tunName, peers)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// State returns the network state, including the WireGuard tunnel information.
+func (n *bridge) State() (*api.NetworkState, error) {
+	state, err := n.common.State()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tunnel := range n.getTunnels() {
+		if n.config["tunnel."+tunnel+".protocol"] != "wireguard" {
+			continue
+		}
+
+		publicKey, err := n.wireGuardLocalPublicKey(tunnel, false)
+		if err != nil {
+			// Skip tunnels that haven't been set up yet.
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		port, err := strconv.ParseInt(n.wireGuardPort(tunnel), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if state.WireGuard == nil {
+			state.WireGuard = map[string]api.NetworkStateWireGuard{}
+		}
+
+		state.WireGuard[tunnel] = api.NetworkStateWireGuard{
+			PublicKey:  publicKey,
+			ListenPort: port,
+		}
+	}
+
+	return state, nil
+}
+
 // bootRoutesV4 returns a list of IPv4 boot routes on the network's device.
 func (n *bridge) bootRoutesV4() ([]string, error) {
 	r := &ip.Route{
@@ -0,0 +1,676 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/bgp"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// evpnDefaultPort is the default destination port of the VXLAN tunnels.
+const evpnDefaultPort = "4789"
+
+// evpnMTUDefault is the default MTU of the bridge, accounting for the VXLAN overhead over an IPv4 underlay.
+const evpnMTUDefault = 1450
+
+// evpn represents a LXD EVPN network.
+// It builds a Linux bridge and a VXLAN interface on each cluster member and exchanges MAC reachability
+// with the other VXLAN tunnel endpoints (VTEPs) using BGP EVPN routes.
+type evpn struct {
+	common
+}
+
+// DBType returns the network type DB ID.
+func (n *evpn) DBType() db.NetworkType {
+	return db.NetworkTypeEVPN
+}
+
+// ValidateName validates network name.
+func (n *evpn) ValidateName(name string) error {
+	err := validate.IsInterfaceName(name)
+	if err != nil {
+		return err
+	}
+
+	// Leave room for the "-vx" suffix of the VXLAN interface.
+	if len(name) > 12 {
+		return fmt.Errorf("Network name too long for VXLAN interface: %s-vx", name)
+	}
+
+	// Apply common name validation that applies to all network types.
+	return n.common.ValidateName(name)
+}
+
+// Validate network config.
+func (n *evpn) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=evpn.vni)
+		// All members of the network use the same VXLAN network identifier.
+		// ---
+		//  type: integer
+		//  shortdesc: VXLAN network identifier
+		//  scope: global
+		"evpn.vni": validate.Required(validate.IsInRange(1, 16777215)),
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=evpn.local)
+		// This address is advertised to the other VXLAN tunnel endpoints as the next hop of the local routes.
+		// ---
+		//  type: string
+		//  defaultdesc: Address of `cluster.https_address`
+		//  shortdesc: Local VXLAN tunnel endpoint address
+		//  scope: local
+		"evpn.local": validate.Optional(validate.IsNetworkAddress),
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=evpn.port)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `4789`
+		//  shortdesc: Destination UDP port of the VXLAN tunnels
+		//  scope: global
+		"evpn.port": validate.Optional(validate.IsNetworkPort),
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=bridge.mtu)
+		//
+		// ---
+		//  type: integer
+		//  defaultdesc: `1450`
+		//  shortdesc: Bridge MTU
+		//  scope: global
+		"bridge.mtu": validate.Optional(validate.IsNetworkMTU),
+
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=bgp.peers.NAME.address)
+		// Peers using one of the local addresses are ignored, so that the same list of peers can be used on all cluster members.
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  shortdesc: Peer address (IPv4 or IPv6)
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=bgp.peers.NAME.asn)
+		//
+		// ---
+		//  type: integer
+		//  condition: BGP server
+		//  shortdesc: Peer AS number
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=bgp.peers.NAME.password)
+		//
+		// ---
+		//  type: string
+		//  condition: BGP server
+		//  defaultdesc: (no password)
+		//  required: no
+		//  shortdesc: Peer session password
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=bgp.peers.NAME.holdtime)
+		// Specify the hold time in seconds.
+		// ---
+		//  type: integer
+		//  condition: BGP server
+		//  defaultdesc: `180`
+		//  required: no
+		//  shortdesc: Peer session hold time
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-evpn; group=network-conf; key=user.*)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: User-provided free-form key/value pairs
+		//  scope: global
+	}
+
+	// Add the BGP validation rules.
+	bgpRules, err := n.bgpValidationRules(config)
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(rules, bgpRules)
+
+	err = n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Create checks whether the network interface name is used already.
+func (n *evpn) Create(clientType request.ClientType) error {
+	n.logger.Debug("Create", logger.Ctx{"clientType": clientType, "config": n.config})
+
+	if InterfaceExists(n.name) {
+		return fmt.Errorf("Network interface %q already exists", n.name)
+	}
+
+	return nil
+}
+
+// isRunning returns whether the network is up.
+func (n *evpn) isRunning() bool {
+	return InterfaceExists(n.name)
+}
+
+// Delete deletes a network.
+func (n *evpn) Delete(clientType request.ClientType) error {
+	n.logger.Debug("Delete", logger.Ctx{"clientType": clientType})
+
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.delete()
+}
+
+// Rename renames a network.
+func (n *evpn) Rename(newName string) error {
+	n.logger.Debug("Rename", logger.Ctx{"newName": newName})
+
+	// Reject known bad names that might cause problem when dealing with paths.
+	err := n.ValidateName(newName)
+	if err != nil {
+		return fmt.Errorf("Invalid network name: %q: %v", newName, err)
+	}
+
+	if InterfaceExists(newName) {
+		return fmt.Errorf("Network interface %q already exists", newName)
+	}
+
+	// Bring the network down.
+	if n.isRunning() {
+		err := n.Stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	// Rename common steps.
+	err = n.rename(newName)
+	if err != nil {
+		return err
+	}
+
+	// Bring the network up.
+	err = n.Start()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Start starts the network.
+func (n *evpn) Start() error {
+	n.logger.Debug("Start")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	revert.Add(func() { n.setUnavailable() })
+
+	err := n.setup(nil)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+
+	// Ensure network is marked as available now its started.
+	n.setAvailable()
+
+	return nil
+}
+
+// vxlanName returns the name of the VXLAN interface of the network.
+func (n *evpn) vxlanName() string {
+	return n.name + "-vx"
+}
+
+// bgpOwner returns the owner of the EVPN routes of the network.
+func (n *evpn) bgpOwner() string {
+	return fmt.Sprintf("network_%d", n.id)
+}
+
+// vni returns the VXLAN network identifier from the given config.
+func (n *evpn) vni(config map[string]string) (uint32, error) {
+	vni, err := strconv.ParseUint(config["evpn.vni"], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid VNI %q: %w", config["evpn.vni"], err)
+	}
+
+	return uint32(vni), nil
+}
+
+// localAddress returns the address of the local VXLAN tunnel endpoint from the given config.
+// Defaults to the address of the cluster listener.
+func (n *evpn) localAddress(config map[string]string) (net.IP, error) {
+	address := config["evpn.local"]
+	if address == "" {
+		clusterAddress := n.state.LocalConfig.ClusterAddress()
+		if clusterAddress != "" {
+			host, _, err := net.SplitHostPort(clusterAddress)
+			if err != nil {
+				return nil, err
+			}
+
+			address = host
+		}
+	}
+
+	localIP := net.ParseIP(address)
+	if localIP == nil || localIP.IsUnspecified() {
+		return nil, errors.New(`A local VXLAN tunnel endpoint address must be set using "evpn.local"`)
+	}
+
+	return localIP, nil
+}
+
+// setup brings up the bridge and VXLAN interfaces and applies the BGP configuration.
+func (n *evpn) setup(oldConfig map[string]string) error {
+	// If we are in mock mode, just no-op.
+	if n.state.OS.MockMode {
+		return nil
+	}
+
+	n.logger.Debug("Setting up network")
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	vni, err := n.vni(n.config)
+	if err != nil {
+		return err
+	}
+
+	localIP, err := n.localAddress(n.config)
+	if err != nil {
+		return err
+	}
+
+	port := n.config["evpn.port"]
+	if port == "" {
+		port = evpnDefaultPort
+	}
+
+	// Build up the bridge interface's settings.
+	bridge := ip.Bridge{
+		Link: ip.Link{
+			Name: n.name,
+			MTU:  evpnMTUDefault,
+		},
+	}
+
+	if n.config["bridge.mtu"] != "" {
+		mtuInt, err := strconv.ParseUint(n.config["bridge.mtu"], 10, 32)
+		if err != nil {
+			return fmt.Errorf("Invalid MTU %q: %w", n.config["bridge.mtu"], err)
+		}
+
+		bridge.MTU = uint32(mtuInt)
+	}
+
+	// Create the bridge interface if doesn't exist.
+	if !n.isRunning() {
+		err := bridge.Add()
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = bridge.Delete() })
+	} else {
+		err := bridge.SetMTU(bridge.MTU)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The bridge only forwards traffic, so prevent the host from being reachable over a link-local address.
+	err = util.SysctlSet("net/ipv6/conf/"+n.name+"/disable_ipv6", "1")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	// Recreate the VXLAN interface to apply any configuration change.
+	vxlan := &ip.Vxlan{
+		Link:       ip.Link{Name: n.vxlanName(), MTU: bridge.MTU},
+		VxlanID:    strconv.FormatUint(uint64(vni), 10),
+		Local:      localIP.String(),
+		DstPort:    port,
+		NoLearning: true,
+	}
+
+	if InterfaceExists(vxlan.Name) {
+		err = vxlan.Delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	err = vxlan.Add()
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { _ = vxlan.Delete() })
+
+	err = AttachInterface(n.name, vxlan.Name)
+	if err != nil {
+		return err
+	}
+
+	err = vxlan.SetUp()
+	if err != nil {
+		return err
+	}
+
+	err = bridge.SetUp()
+	if err != nil {
+		return err
+	}
+
+	// Setup the BGP peers.
+	err = n.bgpSetupEVPNPeers(oldConfig, localIP)
+	if err != nil {
+		return fmt.Errorf("Failed setting up BGP peers: %w", err)
+	}
+
+	// Replace the handler of the remote routes.
+	if oldConfig != nil {
+		oldVNI, err := n.vni(oldConfig)
+		if err == nil {
+			n.state.BGP.RemoveEVPNHandler(oldVNI)
+		}
+	}
+
+	n.state.BGP.RemoveEVPNHandler(vni)
+	err = n.state.BGP.AddEVPNHandler(vni, n.evpnHandler(vxlan.Name, localIP))
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() { n.state.BGP.RemoveEVPNHandler(vni) })
+
+	// Advertise the local VXLAN tunnel endpoint for broadcast, unknown unicast and multicast traffic.
+	err = n.state.BGP.RemoveEVPNRoutesByOwner(n.bgpOwner())
+	if err != nil {
+		return err
+	}
+
+	err = n.state.BGP.AddEVPNRoute(bgp.EVPNRoute{VNI: vni, NextHop: localIP}, n.bgpOwner())
+	if err != nil {
+		return fmt.Errorf("Failed advertising EVPN route: %w", err)
+	}
+
+	revert.Success()
+
+	return nil
+}
+
+// bgpSetupEVPNPeers updates the list of BGP peers exchanging EVPN routes.
+// Peers using the local VXLAN tunnel endpoint or BGP listener address are skipped.
+func (n *evpn) bgpSetupEVPNPeers(oldConfig map[string]string, localIP net.IP) error {
+	localAddresses := []net.IP{localIP}
+
+	bgpAddress := n.state.LocalConfig.BGPAddress()
+	if bgpAddress != "" {
+		host, _, err := net.SplitHostPort(bgpAddress)
+		if err == nil && net.ParseIP(host) != nil {
+			localAddresses = append(localAddresses, net.ParseIP(host))
+		}
+	}
+
+	newPeers := n.bgpGetPeers(n.config)
+	oldPeers := n.bgpGetPeers(oldConfig)
+
+	// Remove old peers.
+	for _, peer := range oldPeers {
+		if slices.Contains(newPeers, peer) {
+			continue
+		}
+
+		addr, _, _ := strings.Cut(peer, ",")
+		err := n.state.BGP.RemovePeer(net.ParseIP(addr))
+		if err != nil && !errors.Is(err, bgp.ErrPeerNotFound) {
+			return err
+		}
+	}
+
+	// Add new peers.
+	for _, peer := range newPeers {
+		if slices.Contains(oldPeers, peer) {
+			continue
+		}
+
+		fields := strings.Split(peer, ",")
+		peerIP := net.ParseIP(fields[0])
+		if slices.ContainsFunc(localAddresses, peerIP.Equal) {
+			continue
+		}
+
+		asn, err := strconv.ParseUint(fields[1], 10, 32)
+		if err != nil {
+			return err
+		}
+
+		var holdTime uint64
+		if fields[3] != "" {
+			holdTime, err = strconv.ParseUint(fields[3], 10, 32)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = n.state.BGP.AddEVPNPeer(peerIP, uint32(asn), fields[2], holdTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// evpnHandler returns the handler applying the remote EVPN routes to the forwarding database of the VXLAN interface.
+func (n *evpn) evpnHandler(vxlanName string, localIP net.IP) bgp.EVPNHandler {
+	zeroMAC := make(net.HardwareAddr, 6)
+
+	return func(route bgp.EVPNRoute, withdrawn bool) {
+		// Skip our own routes.
+		if route.NextHop.Equal(localIP) {
+			return
+		}
+
+		var err error
+
+		entry := &ip.FDB{DevName: vxlanName, MAC: route.MAC, Dst: route.NextHop}
+		if route.MAC == nil {
+			// Inclusive multicast Ethernet tag routes add a flooding entry for the remote endpoint.
+			entry.MAC = zeroMAC
+
+			if withdrawn {
+				err = entry.Delete()
+			} else {
+				var entries []ip.FDB
+				entries, err = entry.Show()
+				if err == nil && !slices.ContainsFunc(entries, func(e ip.FDB) bool {
+					return e.Dst.Equal(entry.Dst) && slices.Equal(e.MAC, zeroMAC)
+				}) {
+					err = entry.Append()
+				}
+			}
+		} else {
+			// MAC/IP advertisement routes point the MAC address to the remote endpoint.
+			// The forwarding entry is only removed with the route without IP address, as the MAC address
+			// is also advertised on its own.
+			if withdrawn {
+				if route.IP != nil {
+					return
+				}
+
+				err = entry.Delete()
+			} else {
+				err = entry.Replace()
+			}
+		}
+
+		if err != nil {
+			n.logger.Warn("Failed applying EVPN route", logger.Ctx{"mac": entry.MAC.String(), "nextHop": route.NextHop.String(), "withdrawn": withdrawn, "err": err})
+		}
+	}
+}
+
+// AdvertiseMAC advertises the MAC address of an instance NIC connected to the network to the other
+// VXLAN tunnel endpoints, with a MAC/IP route for each of the given IP addresses of the NIC.
+// The routes are withdrawn by removing the EVPN routes of the owner.
+func (n *evpn) AdvertiseMAC(owner string, hwaddr net.HardwareAddr, ips []net.IP) error {
+	vni, err := n.vni(n.config)
+	if err != nil {
+		return err
+	}
+
+	localIP, err := n.localAddress(n.config)
+	if err != nil {
+		return err
+	}
+
+	err = n.state.BGP.AddEVPNRoute(bgp.EVPNRoute{VNI: vni, NextHop: localIP, MAC: hwaddr}, owner)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range ips {
+		err = n.state.BGP.AddEVPNRoute(bgp.EVPNRoute{VNI: vni, NextHop: localIP, MAC: hwaddr, IP: addr}, owner)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop stops the network.
+func (n *evpn) Stop() error {
+	n.logger.Debug("Stop")
+
+	if !n.isRunning() {
+		return nil
+	}
+
+	vni, err := n.vni(n.config)
+	if err == nil {
+		n.state.BGP.RemoveEVPNHandler(vni)
+	}
+
+	// Clear BGP.
+	err = n.state.BGP.RemoveEVPNRoutesByOwner(n.bgpOwner())
+	if err != nil {
+		return err
+	}
+
+	err = n.bgpClearPeers(n.config)
+	if err != nil {
+		return err
+	}
+
+	// Destroy the VXLAN and bridge interfaces.
+	if InterfaceExists(n.vxlanName()) {
+		vxlanLink := &ip.Link{Name: n.vxlanName()}
+		err = vxlanLink.Delete()
+		if err != nil {
+			return err
+		}
+	}
+
+	bridgeLink := &ip.Link{Name: n.name}
+	err = bridgeLink.Delete()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update updates the network. Accepts notification boolean indicating if this update request is coming from a
+// cluster notification, in which case do not update the database, just apply local changes needed.
+func (n *evpn) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
+	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
+
+	dbUpdateNeeded, changedKeys, oldNetwork, err := n.configChanged(newNetwork)
+	if err != nil {
+		return err
+	}
+
+	if !dbUpdateNeeded {
+		return nil // Nothing changed.
+	}
+
+	// If the network as a whole has not had any previous creation attempts, or the node itself is still
+	// pending, then don't apply the new settings to the node, just to the database record (ready for the
+	// actual global create request to be initiated).
+	if n.Status() == api.NetworkStatusPending || n.LocalStatus() == api.NetworkStatusPending {
+		return n.update(newNetwork, targetNode, clientType)
+	}
+
+	// The routes of the connected instance NICs refer to the VNI and the local endpoint.
+	if slices.Contains(changedKeys, "evpn.vni") || slices.Contains(changedKeys, "evpn.local") {
+		isUsed, err := n.IsUsed()
+		if err != nil {
+			return err
+		}
+
+		if isUsed {
+			return errors.New(`Cannot change "evpn.vni" or "evpn.local" when network is in use`)
+		}
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Define a function which reverts everything.
+	revert.Add(func() {
+		// Reset changes to all nodes and database.
+		_ = n.update(oldNetwork, targetNode, clientType)
+
+		// Reset any change that was made to local bridge.
+		_ = n.setup(newNetwork.Config)
+	})
+
+	// Apply changes to all nodes and database.
+	err = n.update(newNetwork, targetNode, clientType)
+	if err != nil {
+		return err
+	}
+
+	// Restart the network if needed.
+	if len(changedKeys) > 0 {
+		err = n.setup(oldNetwork.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// UsesDNSMasq indicates if network's config indicates if it needs to use dnsmasq.
+func (n *evpn) UsesDNSMasq() bool {
+	return false
+}
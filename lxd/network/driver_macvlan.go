@@ -2,8 +2,10 @@ package network
 
 import (
 	"fmt"
+	"maps"
 	"math"
 	"net/http"
+	"slices"
 	"strconv"
 
 	"github.com/canonical/lxd/lxd/db"
@@ -104,6 +106,38 @@ func (n *macvlan) Validate(config map[string]string) error {
 		//  shortdesc: Whether to use GARP VLAN Registration Protocol
 		//  scope: global
 		"gvrp": validate.Optional(validate.IsBool),
+		// lxdmeta:generate(entities=network-macvlan; group=network-conf; key=qos.rate)
+		// This is the bandwidth shared by the QoS classes of the network, in both directions.
+		// It is required when QoS classes are defined.
+		// ---
+		//  type: string
+		//  shortdesc: Total bandwidth available to the QoS classes (for example, `1Gbit`)
+		//  scope: global
+		"qos.rate": validate.Optional(validateBitRate),
+
+		// lxdmeta:generate(entities=network-macvlan; group=network-conf; key=qos.classes.NAME.rate)
+		// The rate is guaranteed to the NICs in the class, in both directions.
+		// The sum of the rates of all classes cannot exceed {config:option}`network-macvlan-network-conf:qos.rate`.
+		// ---
+		//  type: string
+		//  shortdesc: Guaranteed bandwidth of the QoS class (for example, `100Mbit`)
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-macvlan; group=network-conf; key=qos.classes.NAME.ceil)
+		// The NICs in the class can borrow unused bandwidth from the other classes up to this rate.
+		// ---
+		//  type: string
+		//  defaultdesc: value of `qos.rate`
+		//  shortdesc: Maximum bandwidth of the QoS class (for example, `500Mbit`)
+		//  scope: global
+
+		// lxdmeta:generate(entities=network-macvlan; group=network-conf; key=qos.classes.NAME.burst)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Amount of data that can be sent at the ceiling rate before shaping applies (for example, `64kB`)
+		//  scope: global
+
 		// lxdmeta:generate(entities=network-macvlan; group=network-conf; key=user.*)
 		//
 		// ---
@@ -112,7 +146,21 @@ func (n *macvlan) Validate(config map[string]string) error {
 		//  scope: global
 	}
 
-	err := n.validate(config, rules)
+	// Add the QoS validation rules.
+	qosRules, err := qosValidationRules(config)
+	if err != nil {
+		return err
+	}
+
+	maps.Copy(rules, qosRules)
+
+	err = n.validate(config, rules)
+	if err != nil {
+		return err
+	}
+
+	// Validate QoS classes.
+	_, err = qosClasses(config)
 	if err != nil {
 		return err
 	}
@@ -140,7 +188,7 @@ func (n *macvlan) Rename(newName string) error {
 	return nil
 }
 
-// Start starts is a no-op.
+// Start applies the QoS classes of the network.
 func (n *macvlan) Start() error {
 	n.logger.Debug("Start")
 
@@ -153,6 +201,16 @@ func (n *macvlan) Start() error {
 		return fmt.Errorf("Parent interface %q not found", n.config["parent"])
 	}
 
+	// Setup QoS classes if the interface carrying the traffic already exists, otherwise they are applied when
+	// the first NIC using them creates it.
+	devName, towardsNICs := n.qosDevice()
+	if InterfaceExists(devName) {
+		err := n.qosApply(devName, towardsNICs)
+		if err != nil {
+			return err
+		}
+	}
+
 	revert.Success()
 
 	// Ensure network is marked as available now its started.
@@ -161,11 +219,19 @@ func (n *macvlan) Start() error {
 	return nil
 }
 
-// Stop stops is a no-op.
+// Stop clears the QoS classes of the network.
 func (n *macvlan) Stop() error {
 	n.logger.Debug("Stop")
 
-	return nil
+	devName, _ := n.qosDevice()
+
+	return qosClear(devName, qosIFBName(n.id))
+}
+
+// qosDevice returns the interface carrying the traffic of the NICs connected to the network.
+// The traffic sent by the NICs leaves through the parent interface.
+func (n *macvlan) qosDevice() (string, bool) {
+	return GetHostDevice(n.config["parent"], n.config["vlan"]), false
 }
 
 // Update updates the network. Accepts notification boolean indicating if this update request is coming from a
@@ -173,7 +239,7 @@ func (n *macvlan) Stop() error {
 func (n *macvlan) Update(newNetwork api.NetworkPut, targetNode string, clientType request.ClientType) error {
 	n.logger.Debug("Update", logger.Ctx{"clientType": clientType, "newNetwork": newNetwork})
 
-	dbUpdateNeeded, _, oldNetwork, err := n.configChanged(newNetwork)
+	dbUpdateNeeded, changedKeys, oldNetwork, err := n.configChanged(newNetwork)
 	if err != nil {
 		return err
 	}
@@ -204,6 +270,22 @@ func (n *macvlan) Update(newNetwork api.NetworkPut, targetNode string, clientTyp
 		return err
 	}
 
+	// Reapply the QoS classes if their configuration or the interface carrying the traffic changed.
+	if qosChanged(oldNetwork.Config, newNetwork.Config) || slices.Contains(changedKeys, "parent") || slices.Contains(changedKeys, "vlan") {
+		err = qosClear(GetHostDevice(oldNetwork.Config["parent"], oldNetwork.Config["vlan"]), qosIFBName(n.id))
+		if err != nil {
+			return err
+		}
+
+		devName, towardsNICs := n.qosDevice()
+		if InterfaceExists(devName) {
+			err = n.qosApply(devName, towardsNICs)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	revert.Success()
 	return nil
 }
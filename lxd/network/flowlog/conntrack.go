@@ -0,0 +1,219 @@
+package flowlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// conntrackAcctSysctl is the sysctl enabling the byte and packet counters of conntrack entries.
+const conntrackAcctSysctl = "net/netfilter/nf_conntrack_acct"
+
+// Conntrack ICMP attributes not defined by the netlink package.
+const (
+	ctaProtoICMPType   = 5
+	ctaProtoICMPCode   = 6
+	ctaProtoICMPv6Type = 8
+	ctaProtoICMPv6Code = 9
+)
+
+// conntrackSubscribe returns a netlink socket receiving conntrack destroy events, along with the previous value of
+// the conntrack accounting setting to restore once done.
+func conntrackSubscribe() (*nl.NetlinkSocket, string, error) {
+	// Byte and packet counters are only included in conntrack events when accounting is enabled.
+	acct, err := util.SysctlGet(conntrackAcctSysctl)
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed getting conntrack accounting: %w", err)
+	}
+
+	err = util.SysctlSet(conntrackAcctSysctl, "1")
+	if err != nil {
+		return nil, "", fmt.Errorf("Failed enabling conntrack accounting: %w", err)
+	}
+
+	sock, err := nl.Subscribe(unix.NETLINK_NETFILTER, unix.NFNLGRP_CONNTRACK_DESTROY)
+	if err != nil {
+		_ = util.SysctlSet(conntrackAcctSysctl, acct)
+		return nil, "", err
+	}
+
+	return sock, acct, nil
+}
+
+// conntrackUnsubscribe closes the conntrack netlink socket and restores the conntrack accounting setting.
+func conntrackUnsubscribe(sock *nl.NetlinkSocket, acct string) {
+	sock.Close()
+
+	err := util.SysctlSet(conntrackAcctSysctl, acct)
+	if err != nil {
+		logger.Warn("Failed restoring conntrack accounting", logger.Ctx{"value": acct, "err": err})
+	}
+}
+
+// handleConntrack handles a conntrack netlink message.
+// Each finished connection involving a registered network is reported as an allowed flow.
+func (l *Logger) handleConntrack(msgType uint16, data []byte) {
+	if msgType != (unix.NFNL_SUBSYS_CTNETLINK<<8)|nl.IPCTNL_MSG_CT_DELETE {
+		return
+	}
+
+	flow, err := parseConntrack(data)
+	if err != nil {
+		return
+	}
+
+	// Connections started by an instance are egress flows, those towards an instance are ingress flows.
+	n := l.networkByAddress(net.ParseIP(flow.SourceAddress))
+	if n != nil {
+		flow.Direction = "egress"
+	} else {
+		n = l.networkByAddress(net.ParseIP(flow.DestinationAddress))
+		if n == nil {
+			return
+		}
+
+		flow.Direction = "ingress"
+	}
+
+	l.forward(n, *flow)
+}
+
+// parseConntrack parses a conntrack netlink message (including its nfgenmsg header) into a flow record.
+func parseConntrack(data []byte) (*api.EventNetworkFlow, error) {
+	if len(data) < nl.SizeofNfgenmsg {
+		return nil, errors.New("Conntrack message too short")
+	}
+
+	attrs, err := nl.ParseRouteAttr(data[nl.SizeofNfgenmsg:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing conntrack attributes: %w", err)
+	}
+
+	flow := &api.EventNetworkFlow{
+		Source:  "conntrack",
+		Verdict: "allow",
+	}
+
+	foundTuple := false
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+		case nl.CTA_TUPLE_ORIG:
+			err = parseConntrackTuple(attr.Value, flow)
+			if err != nil {
+				return nil, err
+			}
+
+			foundTuple = true
+		case nl.CTA_COUNTERS_ORIG:
+			flow.PacketsSent, flow.BytesSent, err = parseConntrackCounters(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+
+		case nl.CTA_COUNTERS_REPLY:
+			flow.PacketsReceived, flow.BytesReceived, err = parseConntrackCounters(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !foundTuple {
+		return nil, errors.New("Conntrack message is missing the original tuple")
+	}
+
+	return flow, nil
+}
+
+// parseConntrackTuple parses a nested conntrack tuple attribute into the flow record.
+func parseConntrackTuple(data []byte, flow *api.EventNetworkFlow) error {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return fmt.Errorf("Failed parsing conntrack tuple: %w", err)
+	}
+
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+		case nl.CTA_TUPLE_IP:
+			ipAttrs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return fmt.Errorf("Failed parsing conntrack tuple addresses: %w", err)
+			}
+
+			for _, ipAttr := range ipAttrs {
+				switch ipAttr.Attr.Type & nl.NLA_TYPE_MASK {
+				case nl.CTA_IP_V4_SRC, nl.CTA_IP_V6_SRC:
+					flow.SourceAddress = net.IP(ipAttr.Value).String()
+				case nl.CTA_IP_V4_DST, nl.CTA_IP_V6_DST:
+					flow.DestinationAddress = net.IP(ipAttr.Value).String()
+				}
+			}
+
+		case nl.CTA_TUPLE_PROTO:
+			protoAttrs, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return fmt.Errorf("Failed parsing conntrack tuple protocol: %w", err)
+			}
+
+			for _, protoAttr := range protoAttrs {
+				if len(protoAttr.Value) < 1 {
+					continue
+				}
+
+				switch protoAttr.Attr.Type & nl.NLA_TYPE_MASK {
+				case nl.CTA_PROTO_NUM:
+					flow.Protocol = protocolName(protoAttr.Value[0])
+				case nl.CTA_PROTO_SRC_PORT:
+					if len(protoAttr.Value) >= 2 {
+						flow.SourcePort = binary.BigEndian.Uint16(protoAttr.Value)
+					}
+
+				case nl.CTA_PROTO_DST_PORT:
+					if len(protoAttr.Value) >= 2 {
+						flow.DestinationPort = binary.BigEndian.Uint16(protoAttr.Value)
+					}
+
+				case ctaProtoICMPType, ctaProtoICMPv6Type:
+					flow.ICMPType = strconv.FormatUint(uint64(protoAttr.Value[0]), 10)
+				case ctaProtoICMPCode, ctaProtoICMPv6Code:
+					flow.ICMPCode = strconv.FormatUint(uint64(protoAttr.Value[0]), 10)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseConntrackCounters parses a nested conntrack counters attribute and returns the packets and bytes.
+func parseConntrackCounters(data []byte) (uint64, uint64, error) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed parsing conntrack counters: %w", err)
+	}
+
+	var packets, bytes uint64
+	for _, attr := range attrs {
+		if len(attr.Value) < 8 {
+			continue
+		}
+
+		switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+		case nl.CTA_COUNTERS_PACKETS:
+			packets = binary.BigEndian.Uint64(attr.Value)
+		case nl.CTA_COUNTERS_BYTES:
+			bytes = binary.BigEndian.Uint64(attr.Value)
+		}
+	}
+
+	return packets, bytes, nil
+}
@@ -0,0 +1,304 @@
+package flowlog
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// NFLogGroup is the netlink log group the firewall sends packets matched by ACL rules to when flow logging is enabled.
+const NFLogGroup = 8000
+
+// aclRulePrefix is the prefix of the log and counter names used for ACL rules (matches the OVN port group prefix).
+const aclRulePrefix = "lxd_acl"
+
+// Network represents a local network whose flows are being logged.
+type Network struct {
+	// Name of the network.
+	Name string
+
+	// Project of the network.
+	Project string
+
+	// Host interface the network's instances are connected to.
+	Interface string
+
+	// Subnets used by the network's instances.
+	Subnets []*net.IPNet
+
+	// Resolve returns the project and name of the instance using the specified address (optional).
+	Resolve func(ip net.IP) (projectName string, instanceName string)
+}
+
+// contains returns true if the IP is part of one of the network's subnets.
+func (n *Network) contains(ip net.IP) bool {
+	for _, subnet := range n.Subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SendFunc is called for every flow record produced by the logger.
+type SendFunc func(projectName string, flow api.EventNetworkFlow) error
+
+// ACLResolveFunc returns the project and name of the network ACL with the specified ID.
+type ACLResolveFunc func(aclID int64) (projectName string, aclName string, err error)
+
+// Logger collects flow records from conntrack, nftables and OVN and forwards them as network events.
+type Logger struct {
+	send       SendFunc
+	resolveACL ACLResolveFunc
+
+	networks      map[string]*Network
+	acls          map[int64][2]string
+	conntrack     *nl.NetlinkSocket
+	conntrackAcct string
+	nflog         *nl.NetlinkSocket
+
+	mu sync.Mutex
+}
+
+// NewLogger returns a new flow logger.
+func NewLogger(send SendFunc, resolveACL ACLResolveFunc) *Logger {
+	return &Logger{
+		send:       send,
+		resolveACL: resolveACL,
+		networks:   map[string]*Network{},
+		acls:       map[int64][2]string{},
+	}
+}
+
+// Register enables flow logging for the specified network.
+// The netlink listeners are started when the first network is registered.
+func (l *Logger) Register(n Network) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conntrack == nil {
+		sock, acct, err := conntrackSubscribe()
+		if err != nil {
+			return fmt.Errorf("Failed subscribing to conntrack events: %w", err)
+		}
+
+		l.conntrack = sock
+		l.conntrackAcct = acct
+		go l.receive(sock, l.handleConntrack)
+	}
+
+	if l.nflog == nil {
+		sock, err := nflogSubscribe(NFLogGroup)
+		if err != nil {
+			if len(l.networks) == 0 {
+				conntrackUnsubscribe(l.conntrack, l.conntrackAcct)
+				l.conntrack = nil
+			}
+
+			return fmt.Errorf("Failed subscribing to netlink log group %d: %w", NFLogGroup, err)
+		}
+
+		l.nflog = sock
+		go l.receive(sock, l.handleNFLog)
+	}
+
+	l.networks[n.Name] = &n
+
+	return nil
+}
+
+// Unregister disables flow logging for the specified network.
+// The netlink listeners are stopped and conntrack accounting is restored when the last network is unregistered.
+func (l *Logger) Unregister(networkName string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.networks, networkName)
+
+	if len(l.networks) > 0 {
+		return
+	}
+
+	if l.conntrack != nil {
+		conntrackUnsubscribe(l.conntrack, l.conntrackAcct)
+		l.conntrack = nil
+	}
+
+	if l.nflog != nil {
+		l.nflog.Close()
+		l.nflog = nil
+	}
+}
+
+// receive reads messages from the netlink socket until it is closed.
+func (l *Logger) receive(sock *nl.NetlinkSocket, handler func(msgType uint16, data []byte)) {
+	for {
+		msgs, _, err := sock.Receive()
+		if err != nil {
+			// Messages were dropped because we couldn't keep up, carry on with the next ones.
+			if errors.Is(err, unix.ENOBUFS) {
+				continue
+			}
+
+			return
+		}
+
+		for _, msg := range msgs {
+			handler(msg.Header.Type, msg.Data)
+		}
+	}
+}
+
+// networkByInterface returns the registered network using the specified host interface.
+func (l *Logger) networkByInterface(ifName string) *Network {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, n := range l.networks {
+		if n.Interface == ifName {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// networkByAddress returns the registered network containing the specified address.
+func (l *Logger) networkByAddress(ip net.IP) *Network {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, n := range l.networks {
+		if n.contains(ip) {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// aclByRuleName returns the project and name of the ACL referenced by an ACL rule log name.
+// Returns empty strings if the name doesn't refer to an ACL rule or the ACL cannot be found.
+func (l *Logger) aclByRuleName(ruleName string) (string, string) {
+	aclID, ok := parseACLRuleName(ruleName)
+	if !ok || l.resolveACL == nil {
+		return "", ""
+	}
+
+	l.mu.Lock()
+	acl, found := l.acls[aclID]
+	l.mu.Unlock()
+
+	if found {
+		return acl[0], acl[1]
+	}
+
+	projectName, aclName, err := l.resolveACL(aclID)
+	if err != nil {
+		logger.Debug("Failed resolving network ACL for flow log", logger.Ctx{"aclID": aclID, "err": err})
+		return "", ""
+	}
+
+	l.mu.Lock()
+	l.acls[aclID] = [2]string{projectName, aclName}
+	l.mu.Unlock()
+
+	return projectName, aclName
+}
+
+// ForgetACL removes the cached project and name of the ACL with the specified ID.
+// It must be called when an ACL is updated, renamed or deleted.
+func (l *Logger) ForgetACL(aclID int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.acls, aclID)
+}
+
+// forward fills in the network, instance and project details of the flow and sends it.
+func (l *Logger) forward(n *Network, flow api.EventNetworkFlow) {
+	projectName := flow.Project
+
+	if n != nil {
+		flow.Network = n.Name
+
+		if projectName == "" {
+			projectName = n.Project
+		}
+
+		if n.Resolve != nil {
+			address := flow.SourceAddress
+			if flow.Direction == "ingress" {
+				address = flow.DestinationAddress
+			}
+
+			ip := net.ParseIP(address)
+			if ip != nil {
+				instProject, instName := n.Resolve(ip)
+				if instName != "" {
+					flow.Instance = instName
+					projectName = instProject
+				}
+			}
+		}
+	}
+
+	flow.Project = projectName
+
+	err := l.send(projectName, flow)
+	if err != nil {
+		logger.Debug("Failed sending network flow event", logger.Ctx{"network": flow.Network, "err": err})
+	}
+}
+
+// parseACLRuleName extracts the ACL ID from an ACL rule log name in the form "lxd_acl<id>-<direction>-<index>".
+func parseACLRuleName(ruleName string) (int64, bool) {
+	idStr, _, found := strings.Cut(strings.TrimPrefix(ruleName, aclRulePrefix), "-")
+	if !found || !strings.HasPrefix(ruleName, aclRulePrefix) {
+		return 0, false
+	}
+
+	aclID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return aclID, true
+}
+
+// ruleDirection returns the direction from a rule log name ending in "-ingress", "-egress" or "-<direction>-<index>".
+func ruleDirection(ruleName string) string {
+	for _, direction := range []string{"ingress", "egress"} {
+		if strings.HasSuffix(ruleName, "-"+direction) || strings.Contains(ruleName, "-"+direction+"-") {
+			return direction
+		}
+	}
+
+	return ""
+}
+
+// protocolName returns the name of the IP protocol number.
+func protocolName(proto uint8) string {
+	switch proto {
+	case 1:
+		return "icmp4"
+	case 6:
+		return "tcp"
+	case 17:
+		return "udp"
+	case 58:
+		return "icmp6"
+	}
+
+	return strconv.FormatUint(uint64(proto), 10)
+}
@@ -0,0 +1,212 @@
+package flowlog
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink/nl"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func Test_parseConntrack(t *testing.T) {
+	be16 := func(v uint16) []byte {
+		b := make([]byte, 2)
+		binary.BigEndian.PutUint16(b, v)
+		return b
+	}
+
+	be64 := func(v uint64) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, v)
+		return b
+	}
+
+	tuple := nl.NewRtAttr(nl.CTA_TUPLE_ORIG|int(nl.NLA_F_NESTED), nil)
+	tupleIP := tuple.AddRtAttr(nl.CTA_TUPLE_IP|int(nl.NLA_F_NESTED), nil)
+	tupleIP.AddRtAttr(nl.CTA_IP_V4_SRC, net.ParseIP("10.0.0.2").To4())
+	tupleIP.AddRtAttr(nl.CTA_IP_V4_DST, net.ParseIP("192.0.2.1").To4())
+	tupleProto := tuple.AddRtAttr(nl.CTA_TUPLE_PROTO|int(nl.NLA_F_NESTED), nil)
+	tupleProto.AddRtAttr(nl.CTA_PROTO_NUM, []byte{6})
+	tupleProto.AddRtAttr(nl.CTA_PROTO_SRC_PORT, be16(45678))
+	tupleProto.AddRtAttr(nl.CTA_PROTO_DST_PORT, be16(443))
+
+	countersOrig := nl.NewRtAttr(nl.CTA_COUNTERS_ORIG|int(nl.NLA_F_NESTED), nil)
+	countersOrig.AddRtAttr(nl.CTA_COUNTERS_PACKETS, be64(12))
+	countersOrig.AddRtAttr(nl.CTA_COUNTERS_BYTES, be64(1420))
+
+	countersReply := nl.NewRtAttr(nl.CTA_COUNTERS_REPLY|int(nl.NLA_F_NESTED), nil)
+	countersReply.AddRtAttr(nl.CTA_COUNTERS_PACKETS, be64(40))
+	countersReply.AddRtAttr(nl.CTA_COUNTERS_BYTES, be64(52800))
+
+	data := []byte{2, 0, 0, 0} // nfgenmsg header.
+	data = append(data, tuple.Serialize()...)
+	data = append(data, countersOrig.Serialize()...)
+	data = append(data, countersReply.Serialize()...)
+
+	flow, err := parseConntrack(data)
+	require.NoError(t, err)
+	assert.Equal(t, &api.EventNetworkFlow{
+		Source:             "conntrack",
+		Verdict:            "allow",
+		Protocol:           "tcp",
+		SourceAddress:      "10.0.0.2",
+		SourcePort:         45678,
+		DestinationAddress: "192.0.2.1",
+		DestinationPort:    443,
+		BytesSent:          1420,
+		BytesReceived:      52800,
+		PacketsSent:        12,
+		PacketsReceived:    40,
+	}, flow)
+
+	_, err = parseConntrack(data[:4])
+	assert.Error(t, err)
+}
+
+func Test_parsePacket(t *testing.T) {
+	// IPv4 UDP packet from 10.0.0.2:5353 to 10.0.0.1:53 with a total length of 60 bytes.
+	ipv4 := []byte{
+		0x45, 0x00, 0x00, 0x3c, 0x00, 0x00, 0x40, 0x00, 0x40, 0x11, 0x00, 0x00,
+		10, 0, 0, 2,
+		10, 0, 0, 1,
+		0x14, 0xe9, 0x00, 0x35, 0x00, 0x28, 0x00, 0x00,
+	}
+
+	flow, err := parsePacket(ipv4)
+	require.NoError(t, err)
+	assert.Equal(t, &api.EventNetworkFlow{
+		Protocol:           "udp",
+		SourceAddress:      "10.0.0.2",
+		SourcePort:         5353,
+		DestinationAddress: "10.0.0.1",
+		DestinationPort:    53,
+		BytesSent:          60,
+		PacketsSent:        1,
+	}, flow)
+
+	// IPv6 ICMP echo request from fd42::2 to fd42::1 with a 64 bytes payload.
+	ipv6 := make([]byte, 48)
+	ipv6[0] = 0x60
+	binary.BigEndian.PutUint16(ipv6[4:6], 64)
+	ipv6[6] = 58
+	copy(ipv6[8:24], net.ParseIP("fd42::2"))
+	copy(ipv6[24:40], net.ParseIP("fd42::1"))
+	ipv6[40] = 128
+
+	flow, err = parsePacket(ipv6)
+	require.NoError(t, err)
+	assert.Equal(t, &api.EventNetworkFlow{
+		Protocol:           "icmp6",
+		SourceAddress:      "fd42::2",
+		DestinationAddress: "fd42::1",
+		ICMPType:           "128",
+		ICMPCode:           "0",
+		BytesSent:          104,
+		PacketsSent:        1,
+	}, flow)
+
+	_, err = parsePacket(ipv4[:10])
+	assert.Error(t, err)
+
+	_, err = parsePacket([]byte{0x20})
+	assert.Error(t, err)
+}
+
+func Test_parseOVNLog(t *testing.T) {
+	l := NewLogger(nil, func(aclID int64) (string, string, error) {
+		return "default", "web", nil
+	})
+
+	tests := []struct {
+		name    string
+		message string
+		want    *api.EventNetworkFlow
+	}{
+		{
+			name:    "ACL rule",
+			message: `name="lxd_acl10-ingress-0", verdict=drop, severity=info, direction=to-lport: tcp,vlan_tci=0x0000,dl_src=00:16:3e:00:00:01,dl_dst=00:16:3e:00:00:02,nw_src=10.0.0.2,nw_dst=10.0.0.3,nw_tos=0,nw_ecn=0,nw_ttl=64,tp_src=45678,tp_dst=22,tcp_flags=syn`,
+			want: &api.EventNetworkFlow{
+				Source:             "ovn",
+				Project:            "default",
+				ACL:                "web",
+				Direction:          "ingress",
+				Verdict:            "drop",
+				Protocol:           "tcp",
+				SourceAddress:      "10.0.0.2",
+				SourcePort:         45678,
+				DestinationAddress: "10.0.0.3",
+				DestinationPort:    22,
+				PacketsSent:        1,
+			},
+		},
+		{
+			name:    "NIC default rule",
+			message: `name="3a6c1f4e-eth0-egress", verdict=reject, severity=info, direction=to-lport: icmp6,vlan_tci=0x0000,ipv6_src=fd42::2,ipv6_dst=fd42::1,icmp_type=128,icmp_code=0`,
+			want: &api.EventNetworkFlow{
+				Source:             "ovn",
+				Direction:          "egress",
+				Verdict:            "reject",
+				Protocol:           "icmp6",
+				SourceAddress:      "fd42::2",
+				DestinationAddress: "fd42::1",
+				ICMPType:           "128",
+				ICMPCode:           "0",
+				PacketsSent:        1,
+			},
+		},
+		{
+			name:    "Unknown rule",
+			message: `name="foo", verdict=drop, severity=info, direction=to-lport: tcp,nw_src=10.0.0.2,nw_dst=10.0.0.3`,
+		},
+		{
+			name:    "Missing addresses",
+			message: `name="lxd_acl10-ingress-0", verdict=drop, severity=info, direction=to-lport: tcp`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, l.parseOVNLog(tt.message))
+		})
+	}
+}
+
+func Test_parseACLRuleName(t *testing.T) {
+	aclID, ok := parseACLRuleName("lxd_acl12-egress-3")
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), aclID)
+
+	_, ok = parseACLRuleName("lxdbr0-egress")
+	assert.False(t, ok)
+
+	_, ok = parseACLRuleName("lxd_aclfoo-egress-3")
+	assert.False(t, ok)
+}
+
+func TestLogger_ForgetACL(t *testing.T) {
+	aclName := "web"
+	lookups := 0
+	l := NewLogger(nil, func(aclID int64) (string, string, error) {
+		lookups++
+		return "default", aclName, nil
+	})
+
+	projectName, name := l.aclByRuleName("lxd_acl10-ingress-0")
+	assert.Equal(t, "default", projectName)
+	assert.Equal(t, "web", name)
+
+	// The ACL is cached until it is forgotten.
+	aclName = "db"
+	_, name = l.aclByRuleName("lxd_acl10-egress-1")
+	assert.Equal(t, "web", name)
+	assert.Equal(t, 1, lookups)
+
+	l.ForgetACL(10)
+	_, name = l.aclByRuleName("lxd_acl10-egress-1")
+	assert.Equal(t, "db", name)
+	assert.Equal(t, 2, lookups)
+}
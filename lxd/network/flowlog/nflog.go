@@ -0,0 +1,245 @@
+package flowlog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Netfilter log (NFLOG) message types and attributes, see linux/netfilter/nfnetlink_log.h.
+const (
+	nfulnlMsgPacket = 0
+	nfulnlMsgConfig = 1
+
+	nfulaIfindexIndev  = 4
+	nfulaIfindexOutdev = 5
+	nfulaPayload       = 9
+	nfulaPrefix        = 10
+
+	nfulaCfgCmd  = 1
+	nfulaCfgMode = 2
+
+	nfulnlCfgCmdBind = 1
+	nfulnlCopyPacket = 2
+)
+
+// nflogCopyRange is the number of bytes of each packet copied to userspace.
+// This is enough for the IP and transport headers.
+const nflogCopyRange = 128
+
+// nflogPacket represents a packet received from the netfilter log.
+type nflogPacket struct {
+	prefix  string
+	inDev   uint32
+	outDev  uint32
+	payload []byte
+}
+
+// nflogSubscribe returns a netlink socket bound to the specified netfilter log group.
+func nflogSubscribe(group uint16) (*nl.NetlinkSocket, error) {
+	sock, err := nl.Subscribe(unix.NETLINK_NETFILTER)
+	if err != nil {
+		return nil, err
+	}
+
+	err = nflogConfig(sock, group, nl.NewRtAttr(nfulaCfgCmd, []byte{nfulnlCfgCmdBind}))
+	if err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("Failed binding to group: %w", err)
+	}
+
+	mode := make([]byte, 6)
+	binary.BigEndian.PutUint32(mode, nflogCopyRange)
+	mode[4] = nfulnlCopyPacket
+
+	err = nflogConfig(sock, group, nl.NewRtAttr(nfulaCfgMode, mode))
+	if err != nil {
+		sock.Close()
+		return nil, fmt.Errorf("Failed setting copy mode: %w", err)
+	}
+
+	return sock, nil
+}
+
+// nflogConfig sends a netfilter log configuration request for the group and waits for its acknowledgement.
+func nflogConfig(sock *nl.NetlinkSocket, group uint16, attr *nl.RtAttr) error {
+	req := nl.NewNetlinkRequest((unix.NFNL_SUBSYS_ULOG<<8)|nfulnlMsgConfig, unix.NLM_F_ACK)
+
+	// The nfgenmsg header carries the group number in network byte order.
+	req.AddRawData([]byte{unix.AF_UNSPEC, unix.NFNETLINK_V0, byte(group >> 8), byte(group)})
+	req.AddRawData(attr.Serialize())
+
+	err := sock.Send(req)
+	if err != nil {
+		return err
+	}
+
+	msgs, _, err := sock.Receive()
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		if msg.Header.Type != unix.NLMSG_ERROR || len(msg.Data) < 4 {
+			continue
+		}
+
+		errno := int32(nl.NativeEndian().Uint32(msg.Data[0:4]))
+		if errno != 0 {
+			return syscall.Errno(-errno)
+		}
+	}
+
+	return nil
+}
+
+// handleNFLog handles a netfilter log netlink message.
+// The log prefix set by the firewall is "<rule name> <action>".
+func (l *Logger) handleNFLog(msgType uint16, data []byte) {
+	if msgType != (unix.NFNL_SUBSYS_ULOG<<8)|nfulnlMsgPacket {
+		return
+	}
+
+	packet, err := parseNFLog(data)
+	if err != nil {
+		return
+	}
+
+	ruleName, verdict, _ := strings.Cut(strings.TrimSpace(packet.prefix), " ")
+
+	flow, err := parsePacket(packet.payload)
+	if err != nil {
+		return
+	}
+
+	flow.Source = "nftables"
+	flow.Direction = ruleDirection(ruleName)
+	flow.Verdict = verdict
+	flow.Project, flow.ACL = l.aclByRuleName(ruleName)
+
+	// Egress packets enter the host from the network's interface, ingress packets leave through it.
+	ifIndex := packet.inDev
+	if flow.Direction == "ingress" {
+		ifIndex = packet.outDev
+	}
+
+	var n *Network
+	iface, err := net.InterfaceByIndex(int(ifIndex))
+	if err == nil {
+		n = l.networkByInterface(iface.Name)
+	}
+
+	if n == nil {
+		return
+	}
+
+	l.forward(n, *flow)
+}
+
+// parseNFLog parses a netfilter log packet message (including its nfgenmsg header).
+func parseNFLog(data []byte) (*nflogPacket, error) {
+	if len(data) < nl.SizeofNfgenmsg {
+		return nil, errors.New("Netfilter log message too short")
+	}
+
+	attrs, err := nl.ParseRouteAttr(data[nl.SizeofNfgenmsg:])
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing netfilter log attributes: %w", err)
+	}
+
+	packet := &nflogPacket{}
+	for _, attr := range attrs {
+		switch attr.Attr.Type & nl.NLA_TYPE_MASK {
+		case nfulaPrefix:
+			packet.prefix = strings.TrimRight(string(attr.Value), "\x00")
+		case nfulaIfindexIndev:
+			if len(attr.Value) >= 4 {
+				packet.inDev = binary.BigEndian.Uint32(attr.Value)
+			}
+
+		case nfulaIfindexOutdev:
+			if len(attr.Value) >= 4 {
+				packet.outDev = binary.BigEndian.Uint32(attr.Value)
+			}
+
+		case nfulaPayload:
+			packet.payload = attr.Value
+		}
+	}
+
+	if packet.payload == nil {
+		return nil, errors.New("Netfilter log message is missing the packet payload")
+	}
+
+	return packet, nil
+}
+
+// parsePacket parses the IP and transport headers of a packet into a flow record.
+// IPv6 extension headers are not followed, the protocol is reported as the first next header value.
+func parsePacket(payload []byte) (*api.EventNetworkFlow, error) {
+	if len(payload) < 1 {
+		return nil, errors.New("Empty packet")
+	}
+
+	flow := &api.EventNetworkFlow{PacketsSent: 1}
+
+	var proto uint8
+	var l4 []byte
+
+	switch payload[0] >> 4 {
+	case 4:
+		if len(payload) < 20 {
+			return nil, errors.New("IPv4 header too short")
+		}
+
+		headerLen := int(payload[0]&0x0f) * 4
+		if headerLen < 20 || len(payload) < headerLen {
+			return nil, fmt.Errorf("Invalid IPv4 header length %d", headerLen)
+		}
+
+		flow.BytesSent = uint64(binary.BigEndian.Uint16(payload[2:4]))
+		proto = payload[9]
+		flow.SourceAddress = net.IP(payload[12:16]).String()
+		flow.DestinationAddress = net.IP(payload[16:20]).String()
+		l4 = payload[headerLen:]
+	case 6:
+		if len(payload) < 40 {
+			return nil, errors.New("IPv6 header too short")
+		}
+
+		flow.BytesSent = uint64(binary.BigEndian.Uint16(payload[4:6])) + 40
+		proto = payload[6]
+		flow.SourceAddress = net.IP(payload[8:24]).String()
+		flow.DestinationAddress = net.IP(payload[24:40]).String()
+		l4 = payload[40:]
+	default:
+		return nil, fmt.Errorf("Unknown IP version %d", payload[0]>>4)
+	}
+
+	flow.Protocol = protocolName(proto)
+
+	switch flow.Protocol {
+	case "tcp", "udp":
+		if len(l4) >= 4 {
+			flow.SourcePort = binary.BigEndian.Uint16(l4[0:2])
+			flow.DestinationPort = binary.BigEndian.Uint16(l4[2:4])
+		}
+
+	case "icmp4", "icmp6":
+		if len(l4) >= 2 {
+			flow.ICMPType = strconv.FormatUint(uint64(l4[0]), 10)
+			flow.ICMPCode = strconv.FormatUint(uint64(l4[1]), 10)
+		}
+	}
+
+	return flow, nil
+}
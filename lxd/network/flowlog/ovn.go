@@ -0,0 +1,95 @@
+package flowlog
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// HandleOVNLog handles a log message from the OVN controller.
+// Entries from the "acl_log" module generated by logged LXD ACL rules are forwarded as flow records.
+func (l *Logger) HandleOVNLog(module string, message string) {
+	if !strings.HasPrefix(module, "acl_log") {
+		return
+	}
+
+	flow := l.parseOVNLog(message)
+	if flow == nil {
+		return
+	}
+
+	l.forward(nil, *flow)
+}
+
+// parseOVNLog parses the message of an OVN ACL log entry into a flow record.
+// Returns nil if the entry wasn't generated by an LXD ACL rule.
+func (l *Logger) parseOVNLog(message string) *api.EventNetworkFlow {
+	entry := map[string]string{}
+	for _, field := range shared.SplitNTrimSpace(message, ",", -1, true) {
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			continue
+		}
+
+		entry[strings.Trim(key, "\"")] = strings.Trim(value, "\"")
+	}
+
+	ruleName := entry["name"]
+	_, isACLRule := parseACLRuleName(ruleName)
+	direction := ruleDirection(ruleName)
+	if !isACLRule && direction == "" {
+		return nil
+	}
+
+	// The direction field looks like "to-lport: tcp".
+	_, protocol, found := strings.Cut(entry["direction"], " ")
+	if !found {
+		return nil
+	}
+
+	if protocol == "icmp" {
+		protocol = "icmp4"
+	}
+
+	flow := &api.EventNetworkFlow{
+		Source:             "ovn",
+		Direction:          direction,
+		Verdict:            entry["verdict"],
+		Protocol:           protocol,
+		SourceAddress:      entry["nw_src"],
+		DestinationAddress: entry["nw_dst"],
+		ICMPType:           entry["icmp_type"],
+		ICMPCode:           entry["icmp_code"],
+		PacketsSent:        1,
+	}
+
+	if flow.SourceAddress == "" {
+		flow.SourceAddress = entry["ipv6_src"]
+	}
+
+	if flow.DestinationAddress == "" {
+		flow.DestinationAddress = entry["ipv6_dst"]
+	}
+
+	if flow.SourceAddress == "" || flow.DestinationAddress == "" {
+		return nil
+	}
+
+	srcPort, err := strconv.ParseUint(entry["tp_src"], 10, 16)
+	if err == nil {
+		flow.SourcePort = uint16(srcPort)
+	}
+
+	dstPort, err := strconv.ParseUint(entry["tp_dst"], 10, 16)
+	if err == nil {
+		flow.DestinationPort = uint16(dstPort)
+	}
+
+	if isACLRule {
+		flow.Project, flow.ACL = l.aclByRuleName(ruleName)
+	}
+
+	return flow
+}
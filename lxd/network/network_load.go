@@ -6,6 +6,8 @@ import (
 	"sync"
 
 	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/shared/api"
 )
@@ -16,6 +18,7 @@ var drivers = map[string]func() Network{
 	"sriov":    func() Network { return &sriov{} },
 	"ovn":      func() Network { return &ovn{} },
 	"physical": func() Network { return &physical{} },
+	"evpn":     func() Network { return &evpn{} },
 }
 
 // ProjectNetwork is a composite type of project name and network name.
@@ -27,6 +30,26 @@ type ProjectNetwork struct {
 var unavailableNetworks = make(map[ProjectNetwork]struct{})
 var unavailableNetworksMu = sync.Mutex{}
 
+func init() {
+	// Expose networkLeases to the acl package, to avoid circular imports.
+	acl.NetworkLeases = networkLeases
+}
+
+// networkLeases returns the leases of the bridge or OVN network across all cluster members.
+// Other network types don't allocate addresses and so have no leases.
+func networkLeases(s *state.State, projectName string, networkName string) ([]api.NetworkLease, error) {
+	n, err := LoadByName(s, projectName, networkName)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.Type() != "bridge" && n.Type() != "ovn" {
+		return nil, nil
+	}
+
+	return n.Leases("", request.ClientTypeNormal)
+}
+
 // LoadByType loads a network by driver type.
 func LoadByType(driverType string) (Type, error) {
 	driverFunc, ok := drivers[driverType]
@@ -0,0 +1,518 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// qosRootClassID is the HTB class holding the total bandwidth shared by the QoS classes.
+const qosRootClassID = "1:1"
+
+// qosFilterPrio is the priority of the filters classifying the traffic of the NICs.
+const qosFilterPrio = "10"
+
+// qosClass represents a traffic shaping class of a network.
+type qosClass struct {
+	Name  string
+	ID    string // HTB class ID.
+	Rate  int64  // Guaranteed rate in bit/s.
+	Ceil  int64  // Maximum rate in bit/s.
+	Burst int64  // Burst size in bytes.
+}
+
+// qosNetwork is implemented by the networks supporting QoS classes.
+type qosNetwork interface {
+	// qosDevice returns the interface carrying the traffic of the NICs connected to the network and whether
+	// the traffic sent to the NICs leaves through that interface (as opposed to arriving on it).
+	qosDevice() (devName string, towardsNICs bool)
+}
+
+// validateBitRate validates a rate in bit/s.
+func validateBitRate(value string) error {
+	rate, err := units.ParseBitSizeString(value)
+	if err != nil {
+		return err
+	}
+
+	if rate <= 0 {
+		return errors.New("Rate must be greater than zero")
+	}
+
+	return nil
+}
+
+// qosValidationRules returns the validation rules for the QoS class keys in the config.
+func qosValidationRules(config map[string]string) (map[string]func(value string) error, error) {
+	rules := map[string]func(value string) error{}
+	for k := range config {
+		// QoS class keys have the class name in their name, extract the suffix.
+		if !strings.HasPrefix(k, "qos.classes.") {
+			continue
+		}
+
+		// Validate class name in key.
+		fields := strings.Split(k, ".")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("Invalid network configuration key: %q", k)
+		}
+
+		if fields[2] == "" {
+			return nil, fmt.Errorf("Invalid network configuration key: %q", k)
+		}
+
+		// Add the correct validation rule for the dynamic field based on last part of key.
+		switch fields[3] {
+		case "rate":
+			rules[k] = validate.Required(validateBitRate)
+		case "ceil":
+			rules[k] = validate.Optional(validateBitRate)
+		case "burst":
+			rules[k] = validate.Optional(validate.IsSize)
+		}
+	}
+
+	return rules, nil
+}
+
+// qosClasses returns the QoS classes of the config sorted by name.
+func qosClasses(config map[string]string) ([]qosClass, error) {
+	names := []string{}
+	for k := range config {
+		if !strings.HasPrefix(k, "qos.classes.") {
+			continue
+		}
+
+		fields := strings.Split(k, ".")
+		if len(fields) == 4 && !slices.Contains(names, fields[2]) {
+			names = append(names, fields[2])
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	if config["qos.rate"] == "" {
+		return nil, errors.New(`QoS classes require "qos.rate" to be set`)
+	}
+
+	totalRate, err := units.ParseBitSizeString(config["qos.rate"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid QoS rate %q: %w", config["qos.rate"], err)
+	}
+
+	// HTB class minor IDs are hexadecimal, leave the lower IDs for the root class.
+	if len(names) > 0xffff-0x10 {
+		return nil, fmt.Errorf("Too many QoS classes (%d)", len(names))
+	}
+
+	slices.Sort(names)
+
+	classes := make([]qosClass, 0, len(names))
+	var guaranteed int64
+	for i, name := range names {
+		class := qosClass{
+			Name: name,
+			ID:   fmt.Sprintf("1:%x", 0x10+i),
+			Ceil: totalRate,
+		}
+
+		prefix := "qos.classes." + name + "."
+		if config[prefix+"rate"] == "" {
+			return nil, fmt.Errorf("QoS class %q requires %q to be set", name, prefix+"rate")
+		}
+
+		class.Rate, err = units.ParseBitSizeString(config[prefix+"rate"])
+		if err != nil {
+			return nil, fmt.Errorf("Invalid rate for QoS class %q: %w", name, err)
+		}
+
+		if config[prefix+"ceil"] != "" {
+			class.Ceil, err = units.ParseBitSizeString(config[prefix+"ceil"])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid ceiling for QoS class %q: %w", name, err)
+			}
+		}
+
+		if config[prefix+"burst"] != "" {
+			class.Burst, err = units.ParseByteSizeString(config[prefix+"burst"])
+			if err != nil {
+				return nil, fmt.Errorf("Invalid burst for QoS class %q: %w", name, err)
+			}
+		}
+
+		if class.Ceil < class.Rate {
+			return nil, fmt.Errorf("Ceiling of QoS class %q cannot be lower than its rate", name)
+		}
+
+		if class.Ceil > totalRate {
+			return nil, fmt.Errorf("Ceiling of QoS class %q cannot exceed %q", name, "qos.rate")
+		}
+
+		guaranteed += class.Rate
+		classes = append(classes, class)
+	}
+
+	if guaranteed > totalRate {
+		return nil, fmt.Errorf("Sum of the QoS class rates cannot exceed %q", "qos.rate")
+	}
+
+	return classes, nil
+}
+
+// qosClassByName returns the QoS class of the config with the given name.
+func qosClassByName(config map[string]string, name string) (*qosClass, error) {
+	classes, err := qosClasses(config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, class := range classes {
+		if class.Name == name {
+			return &class, nil
+		}
+	}
+
+	return nil, api.StatusErrorf(404, "QoS class %q not found", name)
+}
+
+// qosIFBName returns the name of the intermediate functional block device shaping the traffic arriving on
+// the QoS device of the network.
+func qosIFBName(networkID int64) string {
+	return fmt.Sprintf("lxdqos%d", networkID)
+}
+
+// qosClear removes the traffic shaping of the network.
+// The IFB device only exists while the traffic shaping is in place, so the qdiscs of the interface are left
+// untouched otherwise (they may have been set up outside of LXD on a parent interface).
+func qosClear(devName string, ifbName string) error {
+	if !InterfaceExists(ifbName) {
+		return nil
+	}
+
+	// Deleting the root and ingress qdiscs removes all classes and filters.
+	if InterfaceExists(devName) {
+		rootQdisc := &ip.Qdisc{Dev: devName, Root: true}
+		_ = rootQdisc.Delete()
+
+		ingressQdisc := &ip.Qdisc{Dev: devName, Ingress: true}
+		_ = ingressQdisc.Delete()
+	}
+
+	ifb := &ip.Link{Name: ifbName}
+	err := ifb.Delete()
+	if err != nil {
+		return fmt.Errorf("Failed deleting QoS interface %q: %w", ifbName, err)
+	}
+
+	return nil
+}
+
+// qosActive returns true if the traffic shaping of the network is in place on the given interface.
+func qosActive(devName string, ifbName string) bool {
+	if !InterfaceExists(devName) || !InterfaceExists(ifbName) {
+		return false
+	}
+
+	out, err := shared.RunCommand(context.TODO(), "tc", "qdisc", "show", "dev", devName, "root")
+	if err != nil {
+		return false
+	}
+
+	return strings.Contains(out, "htb 1:")
+}
+
+// qosSetupClasses creates the HTB hierarchy for the classes on the given interface.
+func qosSetupClasses(devName string, totalRate string, classes []qosClass) error {
+	qdisc := &ip.QdiscHTB{Qdisc: ip.Qdisc{Dev: devName, Handle: "1:0", Root: true}}
+	err := qdisc.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating root tc qdisc: %w", err)
+	}
+
+	rootClass := &ip.ClassHTB{Class: ip.Class{Dev: devName, Parent: "1:0", Classid: qosRootClassID}, Rate: totalRate, Ceil: totalRate}
+	err = rootClass.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating root tc class: %w", err)
+	}
+
+	for _, class := range classes {
+		classHTB := &ip.ClassHTB{
+			Class: ip.Class{Dev: devName, Parent: qosRootClassID, Classid: class.ID},
+			Rate:  fmt.Sprint(class.Rate, "bit"),
+			Ceil:  fmt.Sprint(class.Ceil, "bit"),
+		}
+
+		if class.Burst > 0 {
+			classHTB.Burst = fmt.Sprint(class.Burst, "b")
+		}
+
+		err = classHTB.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating tc class for QoS class %q: %w", class.Name, err)
+		}
+
+		// Share the bandwidth of the class fairly between its flows.
+		leaf := &ip.QdiscFqCodel{Qdisc: ip.Qdisc{Dev: devName, Parent: class.ID}}
+		err = leaf.Add()
+		if err != nil {
+			return fmt.Errorf("Failed creating tc qdisc for QoS class %q: %w", class.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// qosSetup applies the QoS classes of the network config.
+// The traffic leaving through the interface is shaped on the interface itself, while the traffic arriving on it is
+// redirected to an intermediate functional block (IFB) device and shaped there.
+func qosSetup(devName string, ifbName string, config map[string]string) error {
+	err := qosClear(devName, ifbName)
+	if err != nil {
+		return err
+	}
+
+	classes, err := qosClasses(config)
+	if err != nil {
+		return err
+	}
+
+	if len(classes) == 0 {
+		return nil
+	}
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	// Shape the traffic leaving through the interface.
+	revert.Add(func() {
+		rootQdisc := &ip.Qdisc{Dev: devName, Root: true}
+		_ = rootQdisc.Delete()
+	})
+
+	err = qosSetupClasses(devName, config["qos.rate"], classes)
+	if err != nil {
+		return err
+	}
+
+	// Shape the traffic arriving on the interface.
+	ifb := &ip.Ifb{Link: ip.Link{Name: ifbName, Up: true}}
+	err = ifb.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating QoS interface %q: %w", ifbName, err)
+	}
+
+	revert.Add(func() { _ = qosClear(devName, ifbName) })
+
+	err = qosSetupClasses(ifbName, config["qos.rate"], classes)
+	if err != nil {
+		return err
+	}
+
+	ingress := &ip.Qdisc{Dev: devName, Handle: "ffff:0", Ingress: true}
+	err = ingress.Add()
+	if err != nil {
+		return fmt.Errorf("Failed creating ingress tc qdisc: %w", err)
+	}
+
+	redirect := &ip.MatchallFilter{Filter: ip.Filter{Dev: devName, Parent: "ffff:0", Protocol: "all"}, Actions: []ip.Action{&ip.ActionMirred{Dev: ifbName}}}
+	err = redirect.Add()
+	if err != nil {
+		return fmt.Errorf("Failed redirecting incoming traffic to QoS interface %q: %w", ifbName, err)
+	}
+
+	revert.Success()
+	return nil
+}
+
+// qosFilters returns the filters classifying the traffic of the NIC with the given MAC address.
+func qosFilters(devName string, ifbName string, towardsNICs bool, hwaddr net.HardwareAddr) []*ip.FlowerFilter {
+	// Derive the filter handle from the lower bytes of the MAC address, which are unique within a network.
+	handle := fmt.Sprintf("0x%02x%02x%02x%02x", hwaddr[len(hwaddr)-4], hwaddr[len(hwaddr)-3], hwaddr[len(hwaddr)-2], hwaddr[len(hwaddr)-1])
+
+	devFilter := &ip.FlowerFilter{Filter: ip.Filter{Dev: devName, Parent: "1:0", Protocol: "all"}, Prio: qosFilterPrio, Handle: handle}
+	ifbFilter := &ip.FlowerFilter{Filter: ip.Filter{Dev: ifbName, Parent: "1:0", Protocol: "all"}, Prio: qosFilterPrio, Handle: handle}
+
+	if towardsNICs {
+		devFilter.DstMAC = hwaddr
+		ifbFilter.SrcMAC = hwaddr
+	} else {
+		devFilter.SrcMAC = hwaddr
+		ifbFilter.DstMAC = hwaddr
+	}
+
+	return []*ip.FlowerFilter{devFilter, ifbFilter}
+}
+
+// qosAttachNIC classifies the traffic of the NIC with the given MAC address into the QoS class.
+func qosAttachNIC(devName string, ifbName string, towardsNICs bool, class *qosClass, hwaddr net.HardwareAddr) error {
+	for _, filter := range qosFilters(devName, ifbName, towardsNICs, hwaddr) {
+		filter.Flowid = class.ID
+
+		err := filter.Replace()
+		if err != nil {
+			return fmt.Errorf("Failed adding tc filter for %q to QoS class %q: %w", hwaddr.String(), class.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// qosSetupNICs classifies the traffic of the NICs of the local instances connected to the network.
+func (n *common) qosSetupNICs(devName string, ifbName string, towardsNICs bool) error {
+	filter := dbCluster.InstanceFilter{Node: &n.state.ServerName}
+
+	return n.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		return tx.InstanceList(ctx, func(inst db.InstanceArgs, p api.Project) error {
+			// Get the instance's effective network project name.
+			instNetworkProject := project.NetworkProjectFromRecord(&p)
+
+			if instNetworkProject != n.project {
+				return nil
+			}
+
+			devices := instancetype.ExpandInstanceDevices(inst.Devices.Clone(), inst.Profiles)
+
+			for devName, devConfig := range devices {
+				if devConfig["type"] != "nic" || devConfig["qos.class"] == "" {
+					continue
+				}
+
+				if !NICUsesNetwork(devConfig, &api.Network{Name: n.name}) {
+					continue
+				}
+
+				hwaddr := devConfig["hwaddr"]
+				if hwaddr == "" {
+					hwaddr = inst.Config["volatile."+devName+".hwaddr"]
+				}
+
+				mac, err := net.ParseMAC(hwaddr)
+				if err != nil {
+					continue // Skip NICs that haven't been started yet.
+				}
+
+				class, err := qosClassByName(n.config, devConfig["qos.class"])
+				if err != nil {
+					n.logger.Warn("Skipping NIC with unknown QoS class", logger.Ctx{"instance": inst.Name, "project": inst.Project, "device": devName, "err": err})
+					continue
+				}
+
+				err = qosAttachNIC(devName, ifbName, towardsNICs, class, mac)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}, filter)
+	})
+}
+
+// qosApply applies the QoS classes of the network to the given interface and classifies the traffic of the
+// local instance NICs connected to the network.
+func (n *common) qosApply(devName string, towardsNICs bool) error {
+	ifbName := qosIFBName(n.id)
+
+	err := qosSetup(devName, ifbName, n.config)
+	if err != nil {
+		return err
+	}
+
+	if n.config["qos.rate"] == "" {
+		return nil
+	}
+
+	return n.qosSetupNICs(devName, ifbName, towardsNICs)
+}
+
+// qosChanged returns true if the QoS configuration differs between the configs.
+func qosChanged(oldConfig map[string]string, newConfig map[string]string) bool {
+	for _, config := range []map[string]string{oldConfig, newConfig} {
+		for k := range config {
+			if strings.HasPrefix(k, "qos.") && oldConfig[k] != newConfig[k] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// QoSAttachNIC classifies the traffic of the NIC with the given MAC address into a QoS class of the network.
+func QoSAttachNIC(n Network, className string, hwaddr net.HardwareAddr) error {
+	qn, ok := n.(qosNetwork)
+	if !ok {
+		return fmt.Errorf("Network type %q doesn't support QoS classes", n.Type())
+	}
+
+	class, err := qosClassByName(n.Config(), className)
+	if err != nil {
+		return err
+	}
+
+	devName, towardsNICs := qn.qosDevice()
+	ifbName := qosIFBName(n.ID())
+
+	// The interface may have been created after the network was started (for example a VLAN interface created
+	// by a macvlan NIC), in which case the QoS classes need to be applied first.
+	if !qosActive(devName, ifbName) {
+		err = qosSetup(devName, ifbName, n.Config())
+		if err != nil {
+			return err
+		}
+	}
+
+	return qosAttachNIC(devName, ifbName, towardsNICs, class, hwaddr)
+}
+
+// QoSDetachNIC stops classifying the traffic of the NIC with the given MAC address.
+func QoSDetachNIC(n Network, hwaddr net.HardwareAddr) {
+	qn, ok := n.(qosNetwork)
+	if !ok {
+		return
+	}
+
+	devName, towardsNICs := qn.qosDevice()
+	ifbName := qosIFBName(n.ID())
+
+	for _, filter := range qosFilters(devName, ifbName, towardsNICs, hwaddr) {
+		if !InterfaceExists(filter.Dev) {
+			continue
+		}
+
+		// The filter doesn't exist if the NIC wasn't in a QoS class or the classes were reapplied since.
+		_ = filter.Delete()
+	}
+}
+
+// QoSClassState returns the state of the QoS class of the network with the given name.
+func QoSClassState(n Network, className string) (*api.InstanceStateNetworkQoS, error) {
+	class, err := qosClassByName(n.Config(), className)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.InstanceStateNetworkQoS{
+		Class: class.Name,
+		Rate:  class.Rate,
+		Ceil:  class.Ceil,
+		Burst: class.Burst,
+	}, nil
+}
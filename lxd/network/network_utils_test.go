@@ -253,3 +253,82 @@ func Example_complementRangesIP4() {
 	// Range2: 10.1.1.1-10.1.1.9, 10.1.1.101-10.1.1.199, 10.1.1.231-10.1.1.255
 	// Range3: 10.1.1.1-10.1.1.9, 10.1.1.26-10.1.1.255
 }
+
+func Test_wireGuardPublicKey(t *testing.T) {
+	// Test vector from RFC 7748 section 6.1.
+	publicKey, err := wireGuardPublicKey("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=")
+	require.NoError(t, err)
+	assert.Equal(t, "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=", publicKey)
+
+	_, err = wireGuardPublicKey("invalid")
+	assert.Error(t, err)
+
+	privateKey, err := wireGuardGenerateKey()
+	require.NoError(t, err)
+
+	_, err = wireGuardPublicKey(privateKey)
+	assert.NoError(t, err)
+}
+
+func Test_wireGuardTunnelAddress(t *testing.T) {
+	addr, err := wireGuardTunnelAddress("hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=")
+	require.NoError(t, err)
+	assert.True(t, addr.IsLinkLocalUnicast())
+	assert.Nil(t, addr.To4())
+
+	otherAddr, err := wireGuardTunnelAddress("dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo=")
+	require.NoError(t, err)
+	assert.False(t, addr.Equal(otherAddr))
+}
+
+func Test_parseWireGuardEndpoints(t *testing.T) {
+	out := "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=\t192.0.2.1:51820\n" +
+		"3p7bfXt9wbTTW2HC7OQ1Nz+DQ8hbeGdNrfx+FG+IK08=\t[2001:db8::1]:51821\n" +
+		"de9edb/8Yd5KxMyFV/jnAQ/tjYDtnH/VPAw9ozkdrxw=\t(none)\n"
+
+	assert.Equal(t, map[string]string{
+		"hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=": "192.0.2.1:51820",
+		"3p7bfXt9wbTTW2HC7OQ1Nz+DQ8hbeGdNrfx+FG+IK08=": "[2001:db8::1]:51821",
+		"de9edb/8Yd5KxMyFV/jnAQ/tjYDtnH/VPAw9ozkdrxw=": "",
+	}, parseWireGuardEndpoints(out))
+
+	assert.Empty(t, parseWireGuardEndpoints(""))
+}
+
+func Test_qosClasses(t *testing.T) {
+	classes, err := qosClasses(map[string]string{
+		"qos.rate":                 "1Gbit",
+		"qos.classes.gold.rate":    "600Mbit",
+		"qos.classes.bronze.rate":  "100Mbit",
+		"qos.classes.bronze.ceil":  "300Mbit",
+		"qos.classes.bronze.burst": "64kB",
+	})
+	require.NoError(t, err)
+	require.Len(t, classes, 2)
+
+	// Classes are sorted by name.
+	assert.Equal(t, qosClass{Name: "bronze", ID: "1:10", Rate: 100000000, Ceil: 300000000, Burst: 64000}, classes[0])
+	assert.Equal(t, qosClass{Name: "gold", ID: "1:11", Rate: 600000000, Ceil: 1000000000}, classes[1])
+
+	classes, err = qosClasses(map[string]string{})
+	require.NoError(t, err)
+	assert.Empty(t, classes)
+
+	invalidConfigs := []map[string]string{
+		// Missing total rate.
+		{"qos.classes.gold.rate": "100Mbit"},
+		// Missing class rate.
+		{"qos.rate": "1Gbit", "qos.classes.gold.ceil": "100Mbit"},
+		// Ceiling lower than rate.
+		{"qos.rate": "1Gbit", "qos.classes.gold.rate": "200Mbit", "qos.classes.gold.ceil": "100Mbit"},
+		// Ceiling higher than total rate.
+		{"qos.rate": "1Gbit", "qos.classes.gold.rate": "200Mbit", "qos.classes.gold.ceil": "2Gbit"},
+		// Sum of rates higher than total rate.
+		{"qos.rate": "1Gbit", "qos.classes.gold.rate": "600Mbit", "qos.classes.silver.rate": "600Mbit"},
+	}
+
+	for _, config := range invalidConfigs {
+		_, err = qosClasses(config)
+		assert.Error(t, err, "config: %v", config)
+	}
+}
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/canonical/lxd/lxd/ip"
+	"github.com/canonical/lxd/shared"
+)
+
+// wireGuardDefaultPort is the default UDP port used by WireGuard tunnels.
+const wireGuardDefaultPort = "51820"
+
+// wireGuardVXLANPort is the destination port of the VXLAN tunnel carried inside a WireGuard tunnel.
+const wireGuardVXLANPort = "4789"
+
+// wireGuardVXLANOverhead is the overhead of the VXLAN encapsulation (IPv6, UDP, VXLAN and Ethernet headers)
+// carried inside a WireGuard tunnel.
+const wireGuardVXLANOverhead = 70
+
+// wireGuardKeepalive is the persistent keepalive interval (in seconds) used for WireGuard peers.
+const wireGuardKeepalive = "25"
+
+// bridgeWireGuardMTU is the default and maximum MTU for a bridge with WireGuard tunnels.
+// This accounts for the WireGuard overhead over an IPv6 underlay (80 bytes) and the VXLAN overhead.
+const bridgeWireGuardMTU = 1500 - 80 - wireGuardVXLANOverhead
+
+// wireGuardPeer represents a WireGuard peer of a tunnel.
+type wireGuardPeer struct {
+	PublicKey string
+	Endpoint  string
+}
+
+// wireGuardParseKey decodes a base64 encoded WireGuard key.
+func wireGuardParseKey(key string) ([]byte, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid WireGuard key: %w", err)
+	}
+
+	if len(keyBytes) != curve25519.ScalarSize {
+		return nil, fmt.Errorf("Invalid WireGuard key length %d, expected %d bytes", len(keyBytes), curve25519.ScalarSize)
+	}
+
+	return keyBytes, nil
+}
+
+// validateWireGuardKey validates a base64 encoded WireGuard key.
+func validateWireGuardKey(value string) error {
+	_, err := wireGuardParseKey(value)
+	return err
+}
+
+// wireGuardGenerateKey generates a new base64 encoded WireGuard private key.
+func wireGuardGenerateKey() (string, error) {
+	key := make([]byte, curve25519.ScalarSize)
+	_, err := rand.Read(key)
+	if err != nil {
+		return "", fmt.Errorf("Failed generating WireGuard private key: %w", err)
+	}
+
+	// Clamp the key as per the Curve25519 specification.
+	key[0] &= 248
+	key[31] = (key[31] & 127) | 64
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// wireGuardPublicKey returns the base64 encoded public key for the given base64 encoded private key.
+func wireGuardPublicKey(privateKey string) (string, error) {
+	key, err := wireGuardParseKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	publicKey, err := curve25519.X25519(key, curve25519.Basepoint)
+	if err != nil {
+		return "", fmt.Errorf("Failed deriving WireGuard public key: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(publicKey), nil
+}
+
+// wireGuardTunnelAddress returns the IPv6 link-local address used inside a WireGuard tunnel by the peer
+// with the given public key. The interface identifier is derived from the public key so that both ends
+// of a tunnel can compute each other's address without further coordination.
+func wireGuardTunnelAddress(publicKey string) (net.IP, error) {
+	key, err := wireGuardParseKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(key)
+
+	addr := make(net.IP, net.IPv6len)
+	addr[0] = 0xfe
+	addr[1] = 0x80
+	copy(addr[8:], hash[:8])
+
+	return addr, nil
+}
+
+// wireGuardPeerKeys returns the public keys of the peers configured on the WireGuard interface.
+func wireGuardPeerKeys(wgName string) ([]string, error) {
+	out, err := shared.RunCommand(context.TODO(), "wg", "show", wgName, "peers")
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing WireGuard peers of %q: %w", wgName, err)
+	}
+
+	return shared.SplitNTrimSpace(out, "\n", -1, true), nil
+}
+
+// wireGuardPeerEndpoints returns the endpoints of the peers configured on the WireGuard interface, indexed by
+// their public key.
+func wireGuardPeerEndpoints(wgName string) (map[string]string, error) {
+	out, err := shared.RunCommand(context.TODO(), "wg", "show", wgName, "endpoints")
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing WireGuard peer endpoints of %q: %w", wgName, err)
+	}
+
+	return parseWireGuardEndpoints(out), nil
+}
+
+// parseWireGuardEndpoints parses the output of "wg show <interface> endpoints". Peers without an endpoint
+// have an empty one.
+func parseWireGuardEndpoints(output string) map[string]string {
+	endpoints := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		publicKey, endpoint, _ := strings.Cut(strings.TrimSpace(line), "\t")
+		if publicKey == "" {
+			continue
+		}
+
+		if endpoint == "(none)" {
+			endpoint = ""
+		}
+
+		endpoints[publicKey] = endpoint
+	}
+
+	return endpoints
+}
+
+// wireGuardSetPeers configures the peers of the WireGuard interface and the flooding entries of the VXLAN
+// interface carried over it. Peers and entries that are not in the given list are removed.
+func wireGuardSetPeers(wgName string, vxlanName string, peers []wireGuardPeer) error {
+	zeroMAC := make(net.HardwareAddr, 6)
+
+	fdb := &ip.FDB{DevName: vxlanName}
+	entries, err := fdb.Show()
+	if err != nil {
+		return fmt.Errorf("Failed listing forwarding entries of %q: %w", vxlanName, err)
+	}
+
+	publicKeys := make([]string, 0, len(peers))
+	addresses := make([]net.IP, 0, len(peers))
+	for _, peer := range peers {
+		addr, err := wireGuardTunnelAddress(peer.PublicKey)
+		if err != nil {
+			return err
+		}
+
+		args := []string{"set", wgName, "peer", peer.PublicKey, "allowed-ips", addr.String() + "/128", "persistent-keepalive", wireGuardKeepalive}
+		if peer.Endpoint != "" {
+			args = append(args, "endpoint", peer.Endpoint)
+		}
+
+		_, err = shared.RunCommand(context.TODO(), "wg", args...)
+		if err != nil {
+			return fmt.Errorf("Failed configuring WireGuard peer %q on %q: %w", peer.PublicKey, wgName, err)
+		}
+
+		// Flood broadcast, unknown unicast and multicast traffic to the peer.
+		exists := slices.ContainsFunc(entries, func(entry ip.FDB) bool {
+			return entry.Dst.Equal(addr) && slices.Equal(entry.MAC, zeroMAC)
+		})
+
+		if !exists {
+			entry := &ip.FDB{DevName: vxlanName, MAC: zeroMAC, Dst: addr}
+			err = entry.Append()
+			if err != nil {
+				return fmt.Errorf("Failed adding forwarding entry for WireGuard peer %q on %q: %w", peer.PublicKey, vxlanName, err)
+			}
+		}
+
+		publicKeys = append(publicKeys, peer.PublicKey)
+		addresses = append(addresses, addr)
+	}
+
+	// Remove stale forwarding entries.
+	for _, entry := range entries {
+		if !slices.Equal(entry.MAC, zeroMAC) || slices.ContainsFunc(addresses, entry.Dst.Equal) {
+			continue
+		}
+
+		err = entry.Delete()
+		if err != nil {
+			return fmt.Errorf("Failed removing stale forwarding entry %q from %q: %w", entry.Dst.String(), vxlanName, err)
+		}
+	}
+
+	// Remove stale peers.
+	currentKeys, err := wireGuardPeerKeys(wgName)
+	if err != nil {
+		return err
+	}
+
+	for _, publicKey := range currentKeys {
+		if slices.Contains(publicKeys, publicKey) {
+			continue
+		}
+
+		_, err = shared.RunCommand(context.TODO(), "wg", "set", wgName, "peer", publicKey, "remove")
+		if err != nil {
+			return fmt.Errorf("Failed removing stale WireGuard peer %q from %q: %w", publicKey, wgName, err)
+		}
+	}
+
+	return nil
+}
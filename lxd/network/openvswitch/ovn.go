@@ -142,6 +142,7 @@ type OVNACLRule struct {
 	Priority  int    // Priority (between 0 and 32767, inclusive). Higher values take precedence.
 	Log       bool   // Whether or not to log matched packets.
 	LogName   string // Log label name (requires Log be true).
+	StatsName string // Name used to retrieve the statistics of the rule (optional, overridden by LogName if logged).
 }
 
 // OVNLoadBalancerTarget represents an OVN load balancer Virtual IP target.
@@ -1816,6 +1817,90 @@ func (o *OVN) PortGroupSetACLRules(portGroupName OVNPortGroup, matchReplace map[
 	return nil
 }
 
+// ACLLogicalFlows returns the UUIDs of the southbound logical flows generated for the ACL rules whose name starts
+// with the specified prefix, keyed by ACL rule name.
+// This uses a single query to each of the northbound and southbound databases regardless of the number of rules.
+func (o *OVN) ACLLogicalFlows(namePrefix string) (map[string][]string, error) {
+	output, err := o.xbctl(false, "--format=csv", "--no-headings", "--data=bare", "--columns=_uuid,name", "list", "acl")
+	if err != nil {
+		return nil, err
+	}
+
+	aclRecords, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing ACL rules: %w", err)
+	}
+
+	output, err = o.sbctl("--format=csv", "--no-headings", "--data=bare", "--columns=_uuid,external_ids", "list", "logical_flow")
+	if err != nil {
+		return nil, err
+	}
+
+	flowRecords, err := csv.NewReader(strings.NewReader(output)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing logical flows: %w", err)
+	}
+
+	return aclLogicalFlows(aclRecords, flowRecords, namePrefix), nil
+}
+
+// aclLogicalFlows matches the ACL rule records (UUID and name) whose name starts with the specified prefix against
+// the logical flow records (UUID and external IDs), and returns the logical flow UUIDs keyed by ACL rule name.
+func aclLogicalFlows(aclRecords [][]string, flowRecords [][]string, namePrefix string) map[string][]string {
+	// The logical flows generated for an ACL rule reference the first 8 characters of its UUID as stage hint.
+	aclNames := make(map[string]string)
+	for _, record := range aclRecords {
+		if len(record) != 2 || !strings.HasPrefix(record[1], namePrefix) {
+			continue
+		}
+
+		aclUUID := strings.TrimSpace(record[0])
+		if len(aclUUID) < 8 {
+			continue
+		}
+
+		aclNames[aclUUID[:8]] = record[1]
+	}
+
+	logicalFlows := make(map[string][]string)
+	if len(aclNames) == 0 {
+		return logicalFlows
+	}
+
+	for _, record := range flowRecords {
+		if len(record) != 2 {
+			continue
+		}
+
+		// Bare external IDs are in the form "key1=value1 key2=value2".
+		for externalID := range strings.FieldsSeq(record[1]) {
+			key, value, _ := strings.Cut(externalID, "=")
+			if key != "stage-hint" {
+				continue
+			}
+
+			aclName, ok := aclNames[value]
+			if ok {
+				logicalFlows[aclName] = append(logicalFlows[aclName], strings.TrimSpace(record[0]))
+			}
+
+			break
+		}
+	}
+
+	return logicalFlows
+}
+
+// OVNLogicalFlowCookie returns the OpenFlow cookie used by ovn-controller for the flows generated from the
+// specified logical flow, which is made of the first 32 bits of the logical flow UUID.
+func OVNLogicalFlowCookie(logicalFlowUUID string) (uint64, error) {
+	if len(logicalFlowUUID) < 8 {
+		return 0, fmt.Errorf("Invalid logical flow UUID %q", logicalFlowUUID)
+	}
+
+	return strconv.ParseUint(logicalFlowUUID[:8], 16, 32)
+}
+
 // aclRuleAddAppendArgs adds the commands to args that add the provided ACL rules to the specified OVN entity.
 // Returns args with the ACL rule add commands added to it.
 func (o *OVN) aclRuleAddAppendArgs(args []string, entityTable string, entityName string, externalIDs map[string]string, matchReplace map[string]string, aclRules ...OVNACLRule) []string {
@@ -1837,14 +1922,19 @@ func (o *OVN) aclRuleAddAppendArgs(args []string, entityTable string, entityName
 			"match="+strconv.Quote(rule.Match),
 		)
 
+		name := rule.StatsName
 		if rule.Log {
 			args = append(args, "log=true")
 
 			if rule.LogName != "" {
-				args = append(args, "name="+rule.LogName)
+				name = rule.LogName
 			}
 		}
 
+		if name != "" {
+			args = append(args, "name="+name)
+		}
+
 		for k, v := range externalIDs {
 			args = append(args, "external_ids:"+k+"="+v)
 		}
@@ -2562,6 +2652,33 @@ func (o *OVN) AddressSetRemove(addressSetPrefix OVNAddressSet, addresses ...net.
 	return nil
 }
 
+// AddressSetSet replaces the addresses of the address sets with the supplied addresses, or creates new address
+// sets if needed. The address set names used are "<addressSetPrefix>_ip<IP version>", e.g. "foo_ip4".
+func (o *OVN) AddressSetSet(addressSetPrefix OVNAddressSet, addresses ...net.IPNet) error {
+	args := []string{
+		"clear", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, 4), "addresses",
+		"--", "clear", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, 6), "addresses",
+	}
+
+	for _, address := range addresses {
+		var ipVersion uint = 4
+		if address.IP.To4() == nil {
+			ipVersion = 6
+		}
+
+		args = append(args, "--", "add", "address_set", fmt.Sprintf("%s_ip%d", addressSetPrefix, ipVersion), "addresses", fmt.Sprintf(`"%s"`, address.String()))
+	}
+
+	// Optimistically assume the address sets exist (they normally will).
+	_, err := o.nbctl(args...)
+	if err != nil {
+		// The address sets are always created together, so create both if they can't be updated.
+		return o.AddressSetCreate(addressSetPrefix, addresses...)
+	}
+
+	return nil
+}
+
 // AddressSetDelete deletes address sets for IP versions 4 and 6 in the format "<addressSetPrefix>_ip<IP version>".
 func (o *OVN) AddressSetDelete(addressSetPrefix OVNAddressSet) error {
 	_, err := o.nbctl(
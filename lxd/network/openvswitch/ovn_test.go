@@ -0,0 +1,45 @@
+package openvswitch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOVNLogicalFlowCookie(t *testing.T) {
+	cookie, err := OVNLogicalFlowCookie("1a2b3c4d-0000-4000-8000-000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x1a2b3c4d), cookie)
+
+	_, err = OVNLogicalFlowCookie("1a2b")
+	assert.Error(t, err)
+
+	_, err = OVNLogicalFlowCookie("not-a-uuid")
+	assert.Error(t, err)
+}
+
+func Test_aclLogicalFlows(t *testing.T) {
+	aclRecords := [][]string{
+		{"4d3c2b1a-1111-4111-8111-111111111111", "lxd_acl1-ingress-0"},
+		{"5e4d3c2b-2222-4222-8222-222222222222", "lxd_acl1-egress-0"},
+		{"6f5e4d3c-3333-4333-8333-333333333333", "lxd_acl2-ingress-0"},
+		{"7a6f5e4d-4444-4444-8444-444444444444", "other"},
+	}
+
+	flowRecords := [][]string{
+		{"1a2b3c4d-aaaa-4aaa-8aaa-aaaaaaaaaaaa", "source=northd.c:6789 stage-hint=4d3c2b1a stage-name=ls_out_acl_eval"},
+		{"2b3c4d5e-bbbb-4bbb-8bbb-bbbbbbbbbbbb", "source=northd.c:6789 stage-hint=4d3c2b1a stage-name=ls_in_acl_eval"},
+		{"3c4d5e6f-cccc-4ccc-8ccc-cccccccccccc", "stage-hint=6f5e4d3c stage-name=ls_out_acl_eval"},
+		{"4d5e6f7a-dddd-4ddd-8ddd-dddddddddddd", "stage-hint=7a6f5e4d stage-name=ls_out_acl_eval"},
+		{"5e6f7a8b-eeee-4eee-8eee-eeeeeeeeeeee", "stage-name=ls_in_port_sec_l2"},
+	}
+
+	assert.Equal(t, map[string][]string{
+		"lxd_acl1-ingress-0": {"1a2b3c4d-aaaa-4aaa-8aaa-aaaaaaaaaaaa", "2b3c4d5e-bbbb-4bbb-8bbb-bbbbbbbbbbbb"},
+		"lxd_acl2-ingress-0": {"3c4d5e6f-cccc-4ccc-8ccc-cccccccccccc"},
+	}, aclLogicalFlows(aclRecords, flowRecords, "lxd_acl"))
+
+	assert.Equal(t, map[string][]string{
+		"lxd_acl1-ingress-0": {"1a2b3c4d-aaaa-4aaa-8aaa-aaaaaaaaaaaa", "2b3c4d5e-bbbb-4bbb-8bbb-bbbbbbbbbbbb"},
+	}, aclLogicalFlows(aclRecords, flowRecords, "lxd_acl1-"))
+}
@@ -371,6 +371,63 @@ func (o *OVS) BridgePortList(bridgeName string) ([]string, error) {
 	return ports, nil
 }
 
+// OVSFlowCounters represents the packet and byte counters of OpenFlow flows.
+type OVSFlowCounters struct {
+	Packets uint64
+	Bytes   uint64
+}
+
+// BridgeFlowCounters returns the packet and byte counters of the flows on the bridge, summed by flow cookie.
+func (o *OVS) BridgeFlowCounters(bridgeName string) (map[uint64]OVSFlowCounters, error) {
+	output, err := shared.RunCommand(context.TODO(), "ovs-ofctl", "dump-flows", bridgeName)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseBridgeFlowCounters(output), nil
+}
+
+// parseBridgeFlowCounters parses the output of ovs-ofctl dump-flows and returns the packet and byte counters of
+// the flows summed by flow cookie.
+func parseBridgeFlowCounters(output string) map[uint64]OVSFlowCounters {
+	counters := make(map[uint64]OVSFlowCounters)
+	for line := range strings.SplitSeq(output, "\n") {
+		// Flows are in the form " cookie=0x1a2b3c4d, duration=1.2s, table=0, n_packets=0, n_bytes=0, ...".
+		var cookie uint64
+		var flowCounters OVSFlowCounters
+		var hasCookie bool
+
+		for field := range strings.SplitSeq(strings.TrimSpace(line), ", ") {
+			key, value, found := strings.Cut(field, "=")
+			if !found {
+				continue
+			}
+
+			switch key {
+			case "cookie":
+				var err error
+				cookie, err = strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+				hasCookie = err == nil
+			case "n_packets":
+				flowCounters.Packets, _ = strconv.ParseUint(value, 10, 64)
+			case "n_bytes":
+				flowCounters.Bytes, _ = strconv.ParseUint(value, 10, 64)
+			}
+		}
+
+		if !hasCookie {
+			continue
+		}
+
+		counter := counters[cookie]
+		counter.Packets += flowCounters.Packets
+		counter.Bytes += flowCounters.Bytes
+		counters[cookie] = counter
+	}
+
+	return counters
+}
+
 // HardwareOffloadingEnabled returns true if hardware offloading is enabled.
 func (o *OVS) HardwareOffloadingEnabled() bool {
 	// ovs-vsctl's get command doesn't support its --format flag, so we always get the output quoted.
@@ -0,0 +1,21 @@
+package openvswitch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseBridgeFlowCounters(t *testing.T) {
+	output := `NXST_FLOW reply (xid=0x4):
+ cookie=0x1a2b3c4d, duration=12.345s, table=44, n_packets=5, n_bytes=420, idle_age=3, priority=2002,ct_state=+new+trk,tcp,metadata=0x1,tp_dst=22 actions=resubmit(,45)
+ cookie=0x1a2b3c4d, duration=12.345s, table=44, n_packets=1, n_bytes=80, idle_age=3, priority=2002,ct_state=+new+trk,tcp6,metadata=0x1,tp_dst=22 actions=resubmit(,45)
+ cookie=0xdeadbeef, duration=1.000s, table=12, n_packets=0, n_bytes=0, priority=100 actions=drop
+ duration=1.000s, table=0, n_packets=9, n_bytes=900, priority=0 actions=NORMAL
+`
+
+	assert.Equal(t, map[uint64]OVSFlowCounters{
+		0x1a2b3c4d: {Packets: 6, Bytes: 500},
+		0xdeadbeef: {Packets: 0, Bytes: 0},
+	}, parseBridgeFlowCounters(output))
+}
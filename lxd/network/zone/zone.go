@@ -169,6 +169,15 @@ func (d *zone) validateConfig(info *api.NetworkZonePut) error {
 
 	// Regular config keys.
 
+	// lxdmeta:generate(entities=network-zone; group=config-options; key=dns.allowed_subnets)
+	// The built-in DNS server answers regular queries (for example, `A`, `AAAA` or `PTR`) for the zone from clients in these subnets.
+	// Zone transfers remain restricted to the peers of the zone.
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Comma-separated list of client subnets allowed to query the zone
+	rules["dns.allowed_subnets"] = validate.Optional(validate.IsListOf(validate.IsNetwork))
+
 	// lxdmeta:generate(entities=network-zone; group=config-options; key=dns.nameservers)
 	//
 	// ---
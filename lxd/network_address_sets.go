@@ -0,0 +1,830 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var networkAddressSetsCmd = APIEndpoint{
+	Path:            "network-address-sets",
+	MetricsType:     entity.TypeNetwork,
+	ProjectSpecific: true,
+
+	Get:  APIEndpointAction{Handler: networkAddressSetsGet, AccessHandler: allowAuthenticated, AllProjectsMode: allProjectsModeDisallowRestrictedTLSClients},
+	Post: APIEndpointAction{Handler: networkAddressSetsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateNetworkACLs)},
+}
+
+var networkAddressSetCmd = APIEndpoint{
+	Path:            "network-address-sets/{name}",
+	MetricsType:     entity.TypeNetwork,
+	ProjectSpecific: true,
+
+	Delete: APIEndpointAction{Handler: networkAddressSetDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteNetworkACLs)},
+	Get:    APIEndpointAction{Handler: networkAddressSetGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewNetworkACLs)},
+	Put:    APIEndpointAction{Handler: networkAddressSetPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Patch:  APIEndpointAction{Handler: networkAddressSetPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Post:   APIEndpointAction{Handler: networkAddressSetPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+}
+
+var networkAddressSetStateCmd = APIEndpoint{
+	Path:            "network-address-sets/{name}/state",
+	MetricsType:     entity.TypeNetwork,
+	ProjectSpecific: true,
+
+	Get: APIEndpointAction{Handler: networkAddressSetStateGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewNetworkACLs)},
+}
+
+// networkAddressSetURL returns the API URL of a network address set.
+func networkAddressSetURL(projectName string, name string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "network-address-sets", name).Project(projectName)
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-address-sets network-address-sets network_address_sets_get
+//
+//  Get the network address sets
+//
+//  Returns a list of network address sets (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: query
+//      name: all-projects
+//      description: Retrieve network address sets from all projects
+//      type: boolean
+//      example: true
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/network-address-sets/foo",
+//                "/1.0/network-address-sets/bar"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-address-sets?recursion=1 network-address-sets network_address_sets_get_recursion1
+//
+//	Get the network address sets
+//
+//	Returns a list of network address sets (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: all-projects
+//	    description: Retrieve network address sets from all projects
+//	    type: boolean
+//	    example: true
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network address sets
+//	          items:
+//	            $ref: "#/definitions/NetworkAddressSet"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	requestProjectName, allProjects, err := request.ProjectParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var effectiveProjectName string
+	if !allProjects {
+		// Project specific requests require an effective project, when "features.networks" is enabled this is the requested project, otherwise it is the default project.
+		effectiveProjectName, _, err = project.NetworkProject(s.DB.Cluster, requestProjectName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// If the request is project specific, then set effective project name in the request context so that the authorizer can generate the correct URL.
+		request.SetContextValue(r, request.CtxEffectiveProjectName, effectiveProjectName)
+	}
+
+	recursion, _ := util.IsRecursionRequest(r)
+
+	var setNames map[string][]string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		if allProjects {
+			// Get list of network address sets across all projects.
+			setNames, err = tx.GetNetworkSetsAllProjects(ctx, db.NetworkSetTypeAddress)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Get list of network address sets.
+			sets, err := tx.GetNetworkSets(ctx, effectiveProjectName, db.NetworkSetTypeAddress)
+			if err != nil {
+				return err
+			}
+
+			// Set names should be mapped to the requested project for project specific requests.
+			setNames = map[string][]string{}
+			setNames[requestProjectName] = sets
+		}
+
+		return err
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Network Address sets are covered by the network ACL entitlements of their project.
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanViewNetworkACLs, entity.TypeProject)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.NetworkAddressSet{}
+	for projectName, sets := range setNames {
+		if !userHasPermission(entity.ProjectURL(projectName)) {
+			continue
+		}
+
+		for _, setName := range sets {
+			if recursion == 0 {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-address-sets", setName).String())
+			} else {
+				setProjectName := projectName
+				if !allProjects {
+					setProjectName = effectiveProjectName
+				}
+
+				netSet, err := acl.LoadAddressSetByName(r.Context(), s, setProjectName, setName)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				netSetInfo := netSet.Info()
+				netSetInfo.UsedBy, _ = netSet.UsedBy() // Ignore errors in UsedBy, will return nil.
+				netSetInfo.UsedBy = project.FilterUsedBy(r.Context(), s.Authorizer, netSetInfo.UsedBy)
+				netSetInfo.Project = projectName
+
+				resultMap = append(resultMap, netSetInfo)
+			}
+		}
+	}
+
+	if recursion == 0 {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-address-sets network-address-sets network_address_sets_post
+//
+//	Add a network address set
+//
+//	Creates a new network address set.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Address set
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressSetsPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	requestProject := request.ProjectParam(r)
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressSetsPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = acl.LoadAddressSetByName(r.Context(), s, effectiveProjectName, req.Name)
+	if err == nil {
+		return response.BadRequest(errors.New("The network address set already exists"))
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = acl.CreateAddressSet(ctx, s, effectiveProjectName, &req)
+		if err != nil {
+			return err
+		}
+
+		netSet, err := acl.LoadAddressSetByName(ctx, s, effectiveProjectName, req.Name)
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkAddressSetCreated.Event(netSet, request.CreateRequestor(ctx), nil))
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: requestProject,
+		Type:        operationtype.NetworkAddressSetCreate,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkAddressSetURL(requestProject, req.Name).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation DELETE /1.0/network-address-sets/{name} network-address-sets network_address_set_delete
+//
+//	Delete the network address set
+//
+//	Removes the network address set.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	setName := r.PathValue("name")
+
+	// Load the set before creating the operation so we can return a synchronous 404 if not found.
+	netSet, err := acl.LoadAddressSetByName(r.Context(), s, effectiveProjectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err := netSet.Delete(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed deleting network address set %q: %w", setName, err)
+		}
+
+		s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkAddressSetDeleted.Event(netSet, request.CreateRequestor(ctx), nil))
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: request.ProjectParam(r),
+		Type:        operationtype.NetworkAddressSetDelete,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkAddressSetURL(request.ProjectParam(r), setName).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/network-address-sets/{name} network-address-sets network_address_set_get
+//
+//	Get the network address set
+//
+//	Gets a specific network address set.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Address set
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkAddressSet"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netSet, err := acl.LoadAddressSetByName(r.Context(), s, projectName, r.PathValue("name"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := netSet.Info()
+	info.UsedBy, err = netSet.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info.UsedBy = project.FilterUsedBy(r.Context(), s.Authorizer, info.UsedBy)
+
+	return response.SyncResponseETag(true, info, netSet.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-address-sets/{name} network-address-sets network_address_set_patch
+//
+//  Partially update the network address set
+//
+//  Updates a subset of the network address set configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: set
+//      description: Address set configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkAddressSetPut"
+//  responses:
+//    "202":
+//      $ref: "#/responses/Operation"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-address-sets/{name} network-address-sets network_address_set_put
+//
+//	Update the network address set
+//
+//	Updates the entire network address set configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Address set configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressSetPut"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	setName := r.PathValue("name")
+
+	// Get the existing network address set.
+	netSet, err := acl.LoadAddressSetByName(r.Context(), s, projectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, netSet.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkAddressSetPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		for k, v := range netSet.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		if req.Addresses == nil {
+			req.Addresses = netSet.Info().Addresses
+		}
+	}
+
+	requestor, err := request.GetRequestor(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := requestor.ClientType()
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = netSet.Update(ctx, &req, clientType)
+		if err != nil {
+			return err
+		}
+
+		if !clientType.IsClusterOperationNotification() {
+			s.Events.SendLifecycle(projectName, lifecycle.NetworkAddressSetUpdated.Event(netSet, request.CreateRequestor(ctx), nil))
+		}
+
+		return nil
+	}
+
+	if clientType.IsClusterOperationNotification() {
+		// Operation notification from the leader node: handle synchronously.
+		err := run(r.Context(), nil)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: request.ProjectParam(r),
+		Type:        operationtype.NetworkAddressSetUpdate,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(projectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkAddressSetURL(request.ProjectParam(r), setName).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation POST /1.0/network-address-sets/{name} network-address-sets network_address_set_post
+//
+//	Rename the network address set
+//
+//	Renames an existing network address set.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Address set rename request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkAddressSetPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	setName := r.PathValue("name")
+	requestProject := request.ProjectParam(r)
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkAddressSetPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing network address set.
+	netSet, err := acl.LoadAddressSetByName(r.Context(), s, effectiveProjectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = netSet.Rename(ctx, req.Name)
+		if err != nil {
+			return err
+		}
+
+		lc := lifecycle.NetworkAddressSetRenamed.Event(netSet, request.CreateRequestor(ctx), logger.Ctx{"old_name": setName})
+		s.Events.SendLifecycle(effectiveProjectName, lc)
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: requestProject,
+		Type:        operationtype.NetworkAddressSetRename,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataOriginalEntityURL: networkAddressSetURL(requestProject, setName).String(),
+			api.MetadataEntityURL:         networkAddressSetURL(requestProject, req.Name).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/network-address-sets/{name}/state network-address-sets network_address_set_state_get
+//
+//	Get the network address set state
+//
+//	Gets the addresses a specific network address set currently resolves to.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Address set state
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkAddressSetState"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkAddressSetStateGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netSet, err := acl.LoadAddressSetByName(r.Context(), s, projectName, r.PathValue("name"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	setState, err := netSet.GetState(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	return response.SyncResponse(true, setState)
+}
+
+// networkAddressSetsRefreshTask returns a task that keeps the firewall rules using network address sets that
+// reference instances, profiles or network forwards up to date with the addresses those currently use.
+// Refreshes are triggered when instance NICs start, stop, get a DHCP lease or change address, so this task only
+// acts as a backstop for changes that aren't covered by those triggers.
+func networkAddressSetsRefreshTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := stateFunc()
+
+		leaderInfo, err := s.LeaderInfo()
+		if err != nil {
+			logger.Error("Failed getting leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		// The leader resolves the address sets and notifies the other members when their addresses change.
+		if leaderInfo.Clustered && !leaderInfo.Leader {
+			return
+		}
+
+		err = acl.RefreshAddressSets(ctx, s)
+		if err != nil {
+			logger.Error("Failed refreshing network address sets", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(10 * time.Minute)
+}
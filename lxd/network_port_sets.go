@@ -0,0 +1,730 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/auth"
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/network/acl"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/project"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/version"
+)
+
+var networkPortSetsCmd = APIEndpoint{
+	Path:            "network-port-sets",
+	MetricsType:     entity.TypeNetwork,
+	ProjectSpecific: true,
+
+	Get:  APIEndpointAction{Handler: networkPortSetsGet, AccessHandler: allowAuthenticated, AllProjectsMode: allProjectsModeDisallowRestrictedTLSClients},
+	Post: APIEndpointAction{Handler: networkPortSetsPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanCreateNetworkACLs)},
+}
+
+var networkPortSetCmd = APIEndpoint{
+	Path:            "network-port-sets/{name}",
+	MetricsType:     entity.TypeNetwork,
+	ProjectSpecific: true,
+
+	Delete: APIEndpointAction{Handler: networkPortSetDelete, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanDeleteNetworkACLs)},
+	Get:    APIEndpointAction{Handler: networkPortSetGet, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanViewNetworkACLs)},
+	Put:    APIEndpointAction{Handler: networkPortSetPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Patch:  APIEndpointAction{Handler: networkPortSetPut, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+	Post:   APIEndpointAction{Handler: networkPortSetPost, AccessHandler: allowPermission(entity.TypeProject, auth.EntitlementCanEditNetworkACLs)},
+}
+
+// networkPortSetURL returns the API URL of a network port set.
+func networkPortSetURL(projectName string, name string) *api.URL {
+	return api.NewURL().Path(version.APIVersion, "network-port-sets", name).Project(projectName)
+}
+
+// API endpoints.
+
+// swagger:operation GET /1.0/network-port-sets network-port-sets network_port_sets_get
+//
+//  Get the network port sets
+//
+//  Returns a list of network port sets (URLs).
+//
+//  ---
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: query
+//      name: all-projects
+//      description: Retrieve network port sets from all projects
+//      type: boolean
+//      example: true
+//  responses:
+//    "200":
+//      description: API endpoints
+//      schema:
+//        type: object
+//        description: Sync response
+//        properties:
+//          type:
+//            type: string
+//            description: Response type
+//            example: sync
+//          status:
+//            type: string
+//            description: Status description
+//            example: Success
+//          status_code:
+//            type: integer
+//            description: Status code
+//            example: 200
+//          metadata:
+//            type: array
+//            description: List of endpoints
+//            items:
+//              type: string
+//            example: |-
+//              [
+//                "/1.0/network-port-sets/foo",
+//                "/1.0/network-port-sets/bar"
+//              ]
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation GET /1.0/network-port-sets?recursion=1 network-port-sets network_port_sets_get_recursion1
+//
+//	Get the network port sets
+//
+//	Returns a list of network port sets (structs).
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: query
+//	    name: all-projects
+//	    description: Retrieve network port sets from all projects
+//	    type: boolean
+//	    example: true
+//	responses:
+//	  "200":
+//	    description: API endpoints
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          type: array
+//	          description: List of network port sets
+//	          items:
+//	            $ref: "#/definitions/NetworkPortSet"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetsGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	requestProjectName, allProjects, err := request.ProjectParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	var effectiveProjectName string
+	if !allProjects {
+		// Project specific requests require an effective project, when "features.networks" is enabled this is the requested project, otherwise it is the default project.
+		effectiveProjectName, _, err = project.NetworkProject(s.DB.Cluster, requestProjectName)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		// If the request is project specific, then set effective project name in the request context so that the authorizer can generate the correct URL.
+		request.SetContextValue(r, request.CtxEffectiveProjectName, effectiveProjectName)
+	}
+
+	recursion, _ := util.IsRecursionRequest(r)
+
+	var setNames map[string][]string
+	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+
+		if allProjects {
+			// Get list of network port sets across all projects.
+			setNames, err = tx.GetNetworkSetsAllProjects(ctx, db.NetworkSetTypePort)
+			if err != nil {
+				return err
+			}
+		} else {
+			// Get list of network port sets.
+			sets, err := tx.GetNetworkSets(ctx, effectiveProjectName, db.NetworkSetTypePort)
+			if err != nil {
+				return err
+			}
+
+			// Set names should be mapped to the requested project for project specific requests.
+			setNames = map[string][]string{}
+			setNames[requestProjectName] = sets
+		}
+
+		return err
+	})
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	// Network Port sets are covered by the network ACL entitlements of their project.
+	userHasPermission, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanViewNetworkACLs, entity.TypeProject)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	resultString := []string{}
+	resultMap := []*api.NetworkPortSet{}
+	for projectName, sets := range setNames {
+		if !userHasPermission(entity.ProjectURL(projectName)) {
+			continue
+		}
+
+		for _, setName := range sets {
+			if recursion == 0 {
+				resultString = append(resultString, api.NewURL().Path(version.APIVersion, "network-port-sets", setName).String())
+			} else {
+				setProjectName := projectName
+				if !allProjects {
+					setProjectName = effectiveProjectName
+				}
+
+				netSet, err := acl.LoadPortSetByName(r.Context(), s, setProjectName, setName)
+				if err != nil {
+					return response.SmartError(err)
+				}
+
+				netSetInfo := netSet.Info()
+				netSetInfo.UsedBy, _ = netSet.UsedBy() // Ignore errors in UsedBy, will return nil.
+				netSetInfo.UsedBy = project.FilterUsedBy(r.Context(), s.Authorizer, netSetInfo.UsedBy)
+				netSetInfo.Project = projectName
+
+				resultMap = append(resultMap, netSetInfo)
+			}
+		}
+	}
+
+	if recursion == 0 {
+		return response.SyncResponse(true, resultString)
+	}
+
+	return response.SyncResponse(true, resultMap)
+}
+
+// swagger:operation POST /1.0/network-port-sets network-port-sets network_port_sets_post
+//
+//	Add a network port set
+//
+//	Creates a new network port set.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Port set
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkPortSetsPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetsPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	requestProject := request.ProjectParam(r)
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkPortSetsPost{}
+
+	// Parse the request into a record.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	_, err = acl.LoadPortSetByName(r.Context(), s, effectiveProjectName, req.Name)
+	if err == nil {
+		return response.BadRequest(errors.New("The network port set already exists"))
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = acl.CreatePortSet(ctx, s, effectiveProjectName, &req)
+		if err != nil {
+			return err
+		}
+
+		netSet, err := acl.LoadPortSetByName(ctx, s, effectiveProjectName, req.Name)
+		if err != nil {
+			return err
+		}
+
+		s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkPortSetCreated.Event(netSet, request.CreateRequestor(ctx), nil))
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: requestProject,
+		Type:        operationtype.NetworkPortSetCreate,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkPortSetURL(requestProject, req.Name).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation DELETE /1.0/network-port-sets/{name} network-port-sets network_port_set_delete
+//
+//	Delete the network port set
+//
+//	Removes the network port set.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetDelete(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	setName := r.PathValue("name")
+
+	// Load the set before creating the operation so we can return a synchronous 404 if not found.
+	netSet, err := acl.LoadPortSetByName(r.Context(), s, effectiveProjectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err := netSet.Delete(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed deleting network port set %q: %w", setName, err)
+		}
+
+		s.Events.SendLifecycle(effectiveProjectName, lifecycle.NetworkPortSetDeleted.Event(netSet, request.CreateRequestor(ctx), nil))
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: request.ProjectParam(r),
+		Type:        operationtype.NetworkPortSetDelete,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkPortSetURL(request.ProjectParam(r), setName).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation GET /1.0/network-port-sets/{name} network-port-sets network_port_set_get
+//
+//	Get the network port set
+//
+//	Gets a specific network port set.
+//
+//	---
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	responses:
+//	  "200":
+//	    description: Port set
+//	    schema:
+//	      type: object
+//	      description: Sync response
+//	      properties:
+//	        type:
+//	          type: string
+//	          description: Response type
+//	          example: sync
+//	        status:
+//	          type: string
+//	          description: Status description
+//	          example: Success
+//	        status_code:
+//	          type: integer
+//	          description: Status code
+//	          example: 200
+//	        metadata:
+//	          $ref: "#/definitions/NetworkPortSet"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetGet(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	netSet, err := acl.LoadPortSetByName(r.Context(), s, projectName, r.PathValue("name"))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info := netSet.Info()
+	info.UsedBy, err = netSet.UsedBy()
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	info.UsedBy = project.FilterUsedBy(r.Context(), s.Authorizer, info.UsedBy)
+
+	return response.SyncResponseETag(true, info, netSet.Etag())
+}
+
+// swagger:operation PATCH /1.0/network-port-sets/{name} network-port-sets network_port_set_patch
+//
+//  Partially update the network port set
+//
+//  Updates a subset of the network port set configuration.
+//
+//  ---
+//  consumes:
+//    - application/json
+//  produces:
+//    - application/json
+//  parameters:
+//    - in: query
+//      name: project
+//      description: Project name
+//      type: string
+//      example: default
+//    - in: body
+//      name: set
+//      description: Port set configuration
+//      required: true
+//      schema:
+//        $ref: "#/definitions/NetworkPortSetPut"
+//  responses:
+//    "202":
+//      $ref: "#/responses/Operation"
+//    "400":
+//      $ref: "#/responses/BadRequest"
+//    "403":
+//      $ref: "#/responses/Forbidden"
+//    "412":
+//      $ref: "#/responses/PreconditionFailed"
+//    "500":
+//      $ref: "#/responses/InternalServerError"
+
+// swagger:operation PUT /1.0/network-port-sets/{name} network-port-sets network_port_set_put
+//
+//	Update the network port set
+//
+//	Updates the entire network port set configuration.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Port set configuration
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkPortSetPut"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "404":
+//	    $ref: "#/responses/NotFound"
+//	  "412":
+//	    $ref: "#/responses/PreconditionFailed"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetPut(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	projectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	setName := r.PathValue("name")
+
+	// Get the existing network port set.
+	netSet, err := acl.LoadPortSetByName(r.Context(), s, projectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	// Validate the ETag.
+	err = util.EtagCheck(r, netSet.Etag())
+	if err != nil {
+		return response.PreconditionFailed(err)
+	}
+
+	req := api.NetworkPortSetPut{}
+
+	// Decode the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if r.Method == http.MethodPatch {
+		// If config being updated via "patch" method, then merge all existing config with the keys that
+		// are present in the request config.
+		if req.Config == nil {
+			req.Config = map[string]string{}
+		}
+
+		for k, v := range netSet.Info().Config {
+			_, ok := req.Config[k]
+			if !ok {
+				req.Config[k] = v
+			}
+		}
+
+		if req.Ports == nil {
+			req.Ports = netSet.Info().Ports
+		}
+	}
+
+	requestor, err := request.GetRequestor(r.Context())
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	clientType := requestor.ClientType()
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = netSet.Update(ctx, &req, clientType)
+		if err != nil {
+			return err
+		}
+
+		if !clientType.IsClusterOperationNotification() {
+			s.Events.SendLifecycle(projectName, lifecycle.NetworkPortSetUpdated.Event(netSet, request.CreateRequestor(ctx), nil))
+		}
+
+		return nil
+	}
+
+	if clientType.IsClusterOperationNotification() {
+		// Operation notification from the leader node: handle synchronously.
+		err := run(r.Context(), nil)
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: request.ProjectParam(r),
+		Type:        operationtype.NetworkPortSetUpdate,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(projectName),
+		Metadata: map[string]any{
+			api.MetadataEntityURL: networkPortSetURL(request.ProjectParam(r), setName).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
+
+// swagger:operation POST /1.0/network-port-sets/{name} network-port-sets network_port_set_post
+//
+//	Rename the network port set
+//
+//	Renames an existing network port set.
+//
+//	---
+//	consumes:
+//	  - application/json
+//	produces:
+//	  - application/json
+//	parameters:
+//	  - in: query
+//	    name: project
+//	    description: Project name
+//	    type: string
+//	    example: default
+//	  - in: body
+//	    name: set
+//	    description: Port set rename request
+//	    required: true
+//	    schema:
+//	      $ref: "#/definitions/NetworkPortSetPost"
+//	responses:
+//	  "202":
+//	    $ref: "#/responses/Operation"
+//	  "400":
+//	    $ref: "#/responses/BadRequest"
+//	  "403":
+//	    $ref: "#/responses/Forbidden"
+//	  "500":
+//	    $ref: "#/responses/InternalServerError"
+func networkPortSetPost(d *Daemon, r *http.Request) response.Response {
+	s := d.State()
+
+	setName := r.PathValue("name")
+	requestProject := request.ProjectParam(r)
+	effectiveProjectName, _, err := project.NetworkProject(s.DB.Cluster, request.ProjectParam(r))
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	req := api.NetworkPortSetPost{}
+
+	// Parse the request.
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// Get the existing network port set.
+	netSet, err := acl.LoadPortSetByName(r.Context(), s, effectiveProjectName, setName)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
+	run := func(ctx context.Context, op *operations.Operation) error {
+		err = netSet.Rename(ctx, req.Name)
+		if err != nil {
+			return err
+		}
+
+		lc := lifecycle.NetworkPortSetRenamed.Event(netSet, request.CreateRequestor(ctx), logger.Ctx{"old_name": setName})
+		s.Events.SendLifecycle(effectiveProjectName, lc)
+
+		return nil
+	}
+
+	args := operations.OperationArgs{
+		ProjectName: requestProject,
+		Type:        operationtype.NetworkPortSetRename,
+		Class:       operationtype.OperationClassTask,
+		RunHook:     run,
+		EntityURL:   entity.ProjectURL(effectiveProjectName),
+		Metadata: map[string]any{
+			api.MetadataOriginalEntityURL: networkPortSetURL(requestProject, setName).String(),
+			api.MetadataEntityURL:         networkPortSetURL(requestProject, req.Name).String(),
+		},
+	}
+
+	op, err := operations.ScheduleUserOperationFromRequest(s, r, args)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	return response.OperationResponse(op)
+}
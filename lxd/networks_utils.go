@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 
 	"github.com/canonical/lxd/lxd/cluster"
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/network"
 	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 )
@@ -76,6 +78,54 @@ func networkUpdateForkdnsServersTask(s *state.State, heartbeatData *cluster.APIH
 	return nil
 }
 
+// networkUpdateWireGuardMeshTask gets called on heartbeats and refreshes the peers of bridge networks with
+// WireGuard tunnels in mesh mode.
+func networkUpdateWireGuardMeshTask(s *state.State, heartbeatData *cluster.APIHeartbeat) error {
+	// Use api.ProjectDefaultName here as bridge networks don't support projects.
+	projectName := api.ProjectDefaultName
+
+	// Get a list of managed networks
+	var networks []string
+	err := s.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, c *db.ClusterTx) error {
+		var err error
+		networks, err = c.GetCreatedNetworkNamesByProject(ctx, projectName)
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range networks {
+		n, err := network.LoadByName(s, projectName, name)
+		if err != nil {
+			logger.Errorf("Failed loading network %q from project %q for heartbeat", name, projectName)
+			continue
+		}
+
+		if n.Type() != "bridge" {
+			continue
+		}
+
+		hasMesh := false
+		for k, v := range n.Config() {
+			if strings.HasPrefix(k, "tunnel.") && strings.HasSuffix(k, ".mesh") && shared.IsTrue(v) {
+				hasMesh = true
+				break
+			}
+		}
+
+		if hasMesh {
+			err := n.HandleHeartbeat(heartbeatData)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
 // networkUpdateOVNChassis gets called on heartbeats to check if OVN needs reconfiguring.
 func networkUpdateOVNChassis(s *state.State, heartbeatData *cluster.APIHeartbeat, localAddress string) error {
 	// Check if we have at least one active OVN chassis.
@@ -384,6 +384,20 @@ func operationCancelToken(ctx context.Context, s *state.State, projectName strin
 //      name: all-projects
 //      description: Retrieve operations from all projects
 //      type: boolean
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -440,6 +454,20 @@ func operationCancelToken(ctx context.Context, s *state.State, projectName strin
 //	    name: all-projects
 //	    description: Retrieve operations from all projects
 //	    type: boolean
+//	  - in: query
+//	    name: sort
+//	    description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//	    type: string
+//	    example: -created_at
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: continue
+//	    description: Token returned by a previous request to fetch the next page
+//	    type: string
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -486,6 +514,11 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		projectFilter = &projectName
 	}
 
+	pagination, err := request.PaginationParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	canViewProjectOperations, err := s.Authorizer.GetPermissionChecker(r.Context(), auth.EntitlementCanViewOperations, entity.TypeProject)
 	if err != nil {
 		return response.InternalError(fmt.Errorf("Failed getting operation permission checker: %w", err))
@@ -586,6 +619,15 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		return strings.Compare(a.ID, b.ID)
 	})
 
+	// Sort and paginate operations if needed, the page is then grouped per status.
+	var next string
+	if pagination.IsSet() {
+		apiOps, next, err = request.Paginate(pagination, apiOps, "id")
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	// Sort all operations per status.
 	md := map[string]any{}
 	for _, apiOp := range apiOps {
@@ -607,7 +649,7 @@ func operationsGet(d *Daemon, r *http.Request) response.Response {
 		}
 	}
 
-	return response.SyncResponse(true, md)
+	return response.SyncResponseContinue(true, md, next)
 }
 
 // operationsGetByType gets all operations for a project and type.
@@ -3,11 +3,13 @@ package placement
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 
 	"github.com/canonical/lxd/lxd/db"
 	"github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 )
 
@@ -25,30 +27,126 @@ func Filter(ctx context.Context, tx *db.ClusterTx, candidates []db.NodeInfo, api
 		memberID = &sourceMemberID
 	}
 
+	unitOf, err := getPlacementUnits(ctx, tx, apiPlacementGroup.Config["scope"])
+	if err != nil {
+		return nil, err
+	}
+
 	memberToInst, err := cluster.GetInstancesInPlacementGroup(ctx, tx.Tx(), apiPlacementGroup.Name, apiPlacementGroup.Project, memberID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get compliant cluster members using the placement group.
-	filteredCandidates, err := getCompliantMembers(policy, rigor, candidates, memberToInst)
+	filteredCandidates, err := getCompliantMembers(policy, rigor, candidates, unitOf, groupByUnit(memberToInst, unitOf))
 	if err != nil {
 		return nil, api.StatusErrorf(http.StatusConflict, "Failed filtering candidate cluster members using placement group %q with %q policy and %q rigor: %w", apiPlacementGroup.Name, policy, rigor, err)
 	}
 
+	// Apply the rules referencing other placement groups.
+	for _, rule := range []struct {
+		key      string
+		colocate bool
+	}{
+		{key: "affinity", colocate: true},
+		{key: "anti_affinity", colocate: false},
+	} {
+		for _, groupName := range shared.SplitNTrimSpace(apiPlacementGroup.Config[rule.key], ",", -1, true) {
+			otherMemberToInst, err := cluster.GetInstancesInPlacementGroup(ctx, tx.Tx(), groupName, apiPlacementGroup.Project, memberID)
+			if err != nil {
+				return nil, err
+			}
+
+			filteredCandidates, err = getRuleCompliantMembers(rule.colocate, rigor, filteredCandidates, unitOf, groupByUnit(otherMemberToInst, unitOf))
+			if err != nil {
+				return nil, api.StatusErrorf(http.StatusConflict, "Failed filtering candidate cluster members using %s with placement group %q: %w", rule.key, groupName, err)
+			}
+		}
+	}
+
 	return filteredCandidates, nil
 }
 
+// getPlacementUnits returns a function mapping a cluster member ID to the unit placement decisions are made on for
+// the given scope. With the member scope each cluster member is its own unit, with the failure domain scope all
+// cluster members of the same failure domain share a unit.
+func getPlacementUnits(ctx context.Context, tx *db.ClusterTx, scope string) (func(memberID int64) int64, error) {
+	if scope == "" || scope == api.PlacementScopeMember {
+		return func(memberID int64) int64 { return memberID }, nil
+	}
+
+	if scope != api.PlacementScopeFailureDomain {
+		return nil, fmt.Errorf("Invalid placement scope %q", scope)
+	}
+
+	members, err := tx.GetNodes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading cluster members: %w", err)
+	}
+
+	domains, err := tx.GetNodesFailureDomains(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading failure domains: %w", err)
+	}
+
+	memberToDomain := make(map[int64]int64, len(members))
+	for _, member := range members {
+		memberToDomain[member.ID] = int64(domains[member.Address])
+	}
+
+	return func(memberID int64) int64 { return memberToDomain[memberID] }, nil
+}
+
+// groupByUnit aggregates the instances of a placement group per placement unit.
+func groupByUnit(memberToInst map[int64][]int64, unitOf func(memberID int64) int64) map[int64][]int64 {
+	unitToInst := make(map[int64][]int64, len(memberToInst))
+	for memberID, instances := range memberToInst {
+		unit := unitOf(memberID)
+		unitToInst[unit] = append(unitToInst[unit], instances...)
+	}
+
+	return unitToInst
+}
+
+// getRuleCompliantMembers filters the provided candidates based on the placement units hosting instances of another
+// placement group. When colocate is true only candidates in those units are kept (affinity), otherwise candidates in
+// those units are removed (anti-affinity). With the permissive rigor, the candidates are left untouched if none of them
+// comply. An affinity rule referencing a placement group without instances does not constrain placement.
+func getRuleCompliantMembers(colocate bool, rigor string, candidates []db.NodeInfo, unitOf func(memberID int64) int64, unitToInst map[int64][]int64) ([]db.NodeInfo, error) {
+	if colocate && len(unitToInst) == 0 {
+		return candidates, nil
+	}
+
+	compliantCandidates := make([]db.NodeInfo, 0, len(candidates))
+	for _, c := range candidates {
+		_, hasInst := unitToInst[unitOf(c.ID)]
+		if hasInst == colocate {
+			compliantCandidates = append(compliantCandidates, c)
+		}
+	}
+
+	if len(compliantCandidates) > 0 {
+		return compliantCandidates, nil
+	}
+
+	if rigor == api.PlacementRigorPermissive {
+		return candidates, nil
+	}
+
+	return nil, errors.New("No eligible cluster members available")
+}
+
 // getCompliantMembers gets compliant cluster members from the provided candidates based on the given placement policy and rigor.
-func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo, memberToInst map[int64][]int64) ([]db.NodeInfo, error) {
+// The unitToInst map holds the instances of the placement group per placement unit, as returned by unitOf for each candidate.
+func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo, unitOf func(memberID int64) int64, unitToInst map[int64][]int64) ([]db.NodeInfo, error) {
 	var compliantCandidates []db.NodeInfo
 
 	switch {
 	case policy == api.PlacementPolicySpread && rigor == api.PlacementRigorStrict:
-		// Spread + Strict: Place at most one instance per cluster member.
-		// Filter out candidates that already have instances.
+		// Spread + Strict: Place at most one instance per placement unit.
+		// Filter out candidates whose unit already has instances.
 		for _, c := range candidates {
-			_, hasInst := memberToInst[c.ID]
+			_, hasInst := unitToInst[unitOf(c.ID)]
 			if !hasInst {
 				compliantCandidates = append(compliantCandidates, c)
 			}
@@ -61,13 +159,13 @@ func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo,
 		return compliantCandidates, nil
 
 	case policy == api.PlacementPolicySpread && rigor == api.PlacementRigorPermissive:
-		// Spread + Permissive: Prefer spreading instances evenly across cluster members.
-		// The number of instances per cluster member differs by at most one.
+		// Spread + Permissive: Prefer spreading instances evenly across placement units.
+		// The number of instances per placement unit differs by at most one.
 
 		// Find the minimum instance count among candidates.
 		counts := make([]int, 0, len(candidates))
 		for _, c := range candidates {
-			counts = append(counts, len(memberToInst[c.ID]))
+			counts = append(counts, len(unitToInst[unitOf(c.ID)]))
 		}
 
 		minInstances := 0
@@ -76,9 +174,9 @@ func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo,
 		}
 
 		// Filter candidates to only those with at most minInstances instances.
-		// This ensures the number of instances per cluster member differs by at most one.
+		// This ensures the number of instances per placement unit differs by at most one.
 		for _, c := range candidates {
-			instanceCount := len(memberToInst[c.ID])
+			instanceCount := len(unitToInst[unitOf(c.ID)])
 			if instanceCount <= minInstances {
 				compliantCandidates = append(compliantCandidates, c)
 			}
@@ -91,29 +189,21 @@ func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo,
 		return compliantCandidates, nil
 
 	case policy == api.PlacementPolicyCompact && rigor == api.PlacementRigorStrict:
-		// Compact + Strict: Place all instances on the same cluster member.
-		// The member with the most instances determines the cluster member.
-		if len(memberToInst) == 0 {
+		// Compact + Strict: Place all instances in the same placement unit.
+		// The unit with the most instances determines the placement unit.
+		if len(unitToInst) == 0 {
 			// No instances yet.
-			// All candidates are valid (first instance determines the member).
+			// All candidates are valid (first instance determines the unit).
 			return candidates, nil
 		}
 
-		// Find which member has the most instances from this placement group.
-		var targetMemberID int64
-		maxInstances := -1
-		for memberID, instances := range memberToInst {
-			if len(instances) > maxInstances {
-				maxInstances = len(instances)
-				targetMemberID = memberID
-			}
-		}
+		// Find which unit has the most instances from this placement group.
+		targetUnit := getFullestUnit(unitToInst)
 
-		// Filter candidates to only include the node with the most instances.
+		// Filter candidates to only include those in the unit with the most instances.
 		for _, c := range candidates {
-			if c.ID == targetMemberID {
+			if unitOf(c.ID) == targetUnit {
 				compliantCandidates = append(compliantCandidates, c)
-				break
 			}
 		}
 
@@ -124,35 +214,47 @@ func getCompliantMembers(policy string, rigor string, candidates []db.NodeInfo,
 		return compliantCandidates, nil
 
 	case policy == api.PlacementPolicyCompact && rigor == api.PlacementRigorPermissive:
-		// Compact + Permissive: Prefer to place all instances on the same cluster member.
-		if len(memberToInst) == 0 {
+		// Compact + Permissive: Prefer to place all instances in the same placement unit.
+		if len(unitToInst) == 0 {
 			// No instances yet.
-			// All candidates are valid (first instance determines preferred member).
+			// All candidates are valid (first instance determines preferred unit).
 			return candidates, nil
 		}
 
-		// Find which member has the most instances from this placement group.
-		var preferredMemberID int64
-		maxInstances := -1
-		for memberID, instances := range memberToInst {
-			if len(instances) > maxInstances {
-				maxInstances = len(instances)
-				preferredMemberID = memberID
-			}
-		}
+		// Find which unit has the most instances from this placement group.
+		preferredUnit := getFullestUnit(unitToInst)
 
-		// Check if preferred member is in candidates.
+		// Check if preferred unit is in candidates.
 		for _, c := range candidates {
-			if c.ID == preferredMemberID {
-				// Preferred member is available.
-				return []db.NodeInfo{c}, nil
+			if unitOf(c.ID) == preferredUnit {
+				compliantCandidates = append(compliantCandidates, c)
 			}
 		}
 
-		// Preferred node is not available - fall back to all candidates.
+		if len(compliantCandidates) > 0 {
+			// Preferred unit is available.
+			return compliantCandidates, nil
+		}
+
+		// Preferred unit is not available - fall back to all candidates.
 		return candidates, nil
 
 	default:
 		return nil, errors.New("Invalid placement group")
 	}
 }
+
+// getFullestUnit returns the placement unit with the most instances. Ties are broken by the lowest unit ID so that
+// the result is stable.
+func getFullestUnit(unitToInst map[int64][]int64) int64 {
+	var fullestUnit int64
+	maxInstances := -1
+	for unit, instances := range unitToInst {
+		if len(instances) > maxInstances || (len(instances) == maxInstances && unit < fullestUnit) {
+			maxInstances = len(instances)
+			fullestUnit = unit
+		}
+	}
+
+	return fullestUnit
+}
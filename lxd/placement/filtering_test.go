@@ -68,6 +68,57 @@ func (s *filteringSuite) TestFilter() {
 	})
 	s.Require().NoError(err)
 
+	createPlacementGroup := func(name string, config map[string]string) {
+		_ = testCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			pgID, err := query.Create(ctx, tx.Tx(), cluster.PlacementGroupsRow{
+				ProjectID: 1,
+				Name:      name,
+			})
+			s.Require().NoError(err)
+
+			err = cluster.PlacementGroupsConfigStore().Set(ctx, tx.Tx(), pgID, config)
+			s.Require().NoError(err)
+
+			return nil
+		})
+	}
+
+	createInstance := func(name string, member string, placementGroup string) {
+		_ = testCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			instanceID, err := cluster.CreateInstance(ctx, tx.Tx(), cluster.Instance{
+				Name:    name,
+				Node:    member,
+				Project: "default",
+				Type:    instancetype.Container,
+			})
+			s.Require().NoError(err)
+
+			err = cluster.CreateInstanceConfig(ctx, tx.Tx(), instanceID, map[string]string{
+				"placement.group": placementGroup,
+			})
+			s.Require().NoError(err)
+
+			return nil
+		})
+	}
+
+	deleteInstances := func(names ...string) {
+		_ = testCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+			for _, name := range names {
+				_ = cluster.DeleteInstance(ctx, tx.Tx(), "default", name)
+			}
+
+			return nil
+		})
+	}
+
+	placementGroup := func(name string) cluster.PlacementGroup {
+		return cluster.PlacementGroup{
+			ProjectName: "default",
+			Row:         cluster.PlacementGroupsRow{Name: name},
+		}
+	}
+
 	type args struct {
 		candidates     []db.NodeInfo
 		project        string
@@ -597,6 +648,154 @@ func (s *filteringSuite) TestFilter() {
 				})
 			},
 		},
+		// Scope: failure-domain
+		{
+			name: "spread/strict/failure-domain: occupied failure domain is excluded",
+			caseSetup: func() {
+				_ = testCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+					// member01 and member02 share rack1, member03 and member04 share rack2.
+					for i, domain := range []string{"rack1", "rack1", "rack2", "rack2"} {
+						err := tx.UpdateNodeFailureDomain(ctx, candidates[i].ID, domain)
+						s.Require().NoError(err)
+					}
+
+					return nil
+				})
+
+				createPlacementGroup("pg-spread-strict-fd", map[string]string{
+					"policy": api.PlacementPolicySpread,
+					"rigor":  api.PlacementRigorStrict,
+					"scope":  api.PlacementScopeFailureDomain,
+				})
+
+				createInstance("c1", "member01", "pg-spread-strict-fd")
+			},
+			args: args{
+				candidates:     candidates,
+				project:        "default",
+				placementGroup: placementGroup("pg-spread-strict-fd"),
+			},
+			want:    candidatesWithout("member01", "member02"), // rack1 is occupied.
+			wantErr: false,
+			caseTearDown: func() {
+				deleteInstances("c1")
+			},
+		},
+		{
+			name: "compact/strict/failure-domain: any member of the target failure domain",
+			caseSetup: func() {
+				createPlacementGroup("pg-compact-strict-fd", map[string]string{
+					"policy": api.PlacementPolicyCompact,
+					"rigor":  api.PlacementRigorStrict,
+					"scope":  api.PlacementScopeFailureDomain,
+				})
+
+				createInstance("c1", "member03", "pg-compact-strict-fd")
+			},
+			args: args{
+				candidates:     candidates,
+				project:        "default",
+				placementGroup: placementGroup("pg-compact-strict-fd"),
+			},
+			want:    candidatesOnly("member03", "member04"), // Both members are in rack2.
+			wantErr: false,
+			caseTearDown: func() {
+				deleteInstances("c1")
+
+				_ = testCluster.Transaction(context.Background(), func(ctx context.Context, tx *db.ClusterTx) error {
+					for _, candidate := range candidates {
+						err := tx.UpdateNodeFailureDomain(ctx, candidate.ID, "default")
+						s.Require().NoError(err)
+					}
+
+					return nil
+				})
+			},
+		},
+
+		// Affinity and anti-affinity rules
+		{
+			name: "anti-affinity/strict: members hosting the other group are excluded",
+			caseSetup: func() {
+				createPlacementGroup("pg-db-primary", map[string]string{
+					"policy": api.PlacementPolicySpread,
+					"rigor":  api.PlacementRigorPermissive,
+				})
+
+				createPlacementGroup("pg-db-replica", map[string]string{
+					"policy":        api.PlacementPolicySpread,
+					"rigor":         api.PlacementRigorStrict,
+					"anti_affinity": "pg-db-primary",
+				})
+
+				createInstance("c1", "member01", "pg-db-primary")
+				createInstance("c2", "member02", "pg-db-replica")
+			},
+			args: args{
+				candidates:     candidates,
+				project:        "default",
+				placementGroup: placementGroup("pg-db-replica"),
+			},
+			want:    candidatesWithout("member01", "member02"), // member01 hosts the primary, member02 a replica.
+			wantErr: false,
+		},
+		{
+			name: "anti-affinity/strict: only excluded members available should return error",
+			args: args{
+				candidates:     candidatesOnly("member01"),
+				project:        "default",
+				placementGroup: placementGroup("pg-db-replica"),
+			},
+			want:    nil,
+			wantErr: true,
+		},
+		{
+			name: "anti-affinity/permissive: rule is ignored when it cannot be satisfied",
+			caseSetup: func() {
+				createPlacementGroup("pg-db-backup", map[string]string{
+					"policy":        api.PlacementPolicySpread,
+					"rigor":         api.PlacementRigorPermissive,
+					"anti_affinity": "pg-db-primary",
+				})
+			},
+			args: args{
+				candidates:     candidatesOnly("member01"),
+				project:        "default",
+				placementGroup: placementGroup("pg-db-backup"),
+			},
+			want:    candidatesOnly("member01"),
+			wantErr: false,
+		},
+		{
+			name: "affinity: only members hosting the other group are kept",
+			caseSetup: func() {
+				createPlacementGroup("pg-app", map[string]string{
+					"policy":   api.PlacementPolicySpread,
+					"rigor":    api.PlacementRigorStrict,
+					"affinity": "pg-db-primary",
+				})
+			},
+			args: args{
+				candidates:     candidates,
+				project:        "default",
+				placementGroup: placementGroup("pg-app"),
+			},
+			want:    candidatesOnly("member01"),
+			wantErr: false,
+			caseTearDown: func() {
+				deleteInstances("c1", "c2")
+			},
+		},
+		{
+			name: "affinity: other group without instances does not constrain placement",
+			args: args{
+				candidates:     candidates,
+				project:        "default",
+				placementGroup: placementGroup("pg-app"),
+			},
+			want:    candidates,
+			wantErr: false,
+		},
 	}
 
 	// Prepare a placement group cache to avoid reloading the same group repeatedly.
@@ -0,0 +1,136 @@
+package placement
+
+import (
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// scoreTolerance is the score difference under which cluster members are considered equally suitable.
+// This avoids small fluctuations in resource usage from overriding the instance count tie breaker.
+const scoreTolerance = 0.05
+
+// Weights holds the relative weights of the resources used to score cluster members.
+type Weights struct {
+	CPU     int64
+	Memory  int64
+	Network int64
+	Storage int64
+}
+
+// Enabled returns whether any of the resources is weighted.
+func (w Weights) Enabled() bool {
+	return w.CPU > 0 || w.Memory > 0 || w.Network > 0 || w.Storage > 0
+}
+
+// Scorer scores cluster members based on their live resource usage.
+type Scorer struct {
+	// Weights of the resources.
+	Weights Weights
+
+	// MemberStates holds the state of each cluster member keyed by member name.
+	MemberStates map[string]api.ClusterMemberState
+
+	// PoolName is the storage pool used by the instance being placed.
+	// If empty, the free space of all storage pools of a member is averaged.
+	PoolName string
+}
+
+// freeShare returns the share of total that is free, between 0 and 1.
+func freeShare(free float64, total float64) float64 {
+	return min(max(free/total, 0), 1)
+}
+
+// Score returns the weighted share of free resources of the given member state, between 0 (fully loaded) and 1 (idle).
+// Resources that cannot be measured on the member are left out of its score. The boolean is false if none of the
+// weighted resources could be measured.
+func (s *Scorer) Score(memberState api.ClusterMemberState) (float64, bool) {
+	sysInfo := memberState.SysInfo
+
+	var score float64
+	var totalWeight int64
+
+	addScore := func(weight int64, share float64) {
+		score += float64(weight) * share
+		totalWeight += weight
+	}
+
+	if s.Weights.CPU > 0 && len(sysInfo.LoadAverages) > 0 && sysInfo.LogicalCPUs > 0 {
+		addScore(s.Weights.CPU, 1-freeShare(sysInfo.LoadAverages[0], float64(sysInfo.LogicalCPUs)))
+	}
+
+	if s.Weights.Memory > 0 && sysInfo.TotalRAM > 0 {
+		addScore(s.Weights.Memory, freeShare(float64(sysInfo.FreeRAM+sysInfo.BufferRAM), float64(sysInfo.TotalRAM)))
+	}
+
+	if s.Weights.Network > 0 && sysInfo.NetworkBandwidth > 0 {
+		addScore(s.Weights.Network, 1-freeShare(float64(sysInfo.NetworkThroughput), float64(sysInfo.NetworkBandwidth)))
+	}
+
+	if s.Weights.Storage > 0 {
+		var poolsShare float64
+		var poolsCount int
+
+		for poolName, pool := range memberState.StoragePools {
+			if s.PoolName != "" && poolName != s.PoolName {
+				continue
+			}
+
+			if pool.Space.Total == 0 {
+				continue
+			}
+
+			poolsShare += freeShare(float64(pool.Space.Total)-float64(pool.Space.Used), float64(pool.Space.Total))
+			poolsCount++
+		}
+
+		if poolsCount > 0 {
+			addScore(s.Weights.Storage, poolsShare/float64(poolsCount))
+		}
+	}
+
+	if totalWeight == 0 {
+		return 0, false
+	}
+
+	return score / float64(totalWeight), true
+}
+
+// Best returns the candidates whose score is within [scoreTolerance] of the best scoring candidate.
+// Candidates that cannot be scored are only returned if none of the candidates can be scored.
+// If the scorer is nil or no resource is weighted, all candidates are returned.
+func (s *Scorer) Best(candidates []db.NodeInfo) []db.NodeInfo {
+	if s == nil || !s.Weights.Enabled() {
+		return candidates
+	}
+
+	scores := make(map[int64]float64, len(candidates))
+	bestScore := -1.0
+	for _, candidate := range candidates {
+		memberState, ok := s.MemberStates[candidate.Name]
+		if !ok {
+			continue
+		}
+
+		score, ok := s.Score(memberState)
+		if !ok {
+			continue
+		}
+
+		scores[candidate.ID] = score
+		bestScore = max(bestScore, score)
+	}
+
+	if len(scores) == 0 {
+		return candidates
+	}
+
+	bestCandidates := make([]db.NodeInfo, 0, len(scores))
+	for _, candidate := range candidates {
+		score, ok := scores[candidate.ID]
+		if ok && score >= bestScore-scoreTolerance {
+			bestCandidates = append(bestCandidates, candidate)
+		}
+	}
+
+	return bestCandidates
+}
@@ -0,0 +1,81 @@
+package placement
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestScorerBest(t *testing.T) {
+	candidates := []db.NodeInfo{
+		{ID: 1, Name: "member01"},
+		{ID: 2, Name: "member02"},
+		{ID: 3, Name: "member03"},
+	}
+
+	memberState := func(load float64, freeRAM uint64, poolUsed uint64) api.ClusterMemberState {
+		return api.ClusterMemberState{
+			SysInfo: api.ClusterMemberSysInfo{
+				LoadAverages: []float64{load, load, load},
+				LogicalCPUs:  4,
+				TotalRAM:     100,
+				FreeRAM:      freeRAM,
+			},
+			StoragePools: map[string]api.StoragePoolState{
+				"default": {ResourcesStoragePool: api.ResourcesStoragePool{Space: api.ResourcesStoragePoolSpace{Used: poolUsed, Total: 100}}},
+			},
+		}
+	}
+
+	memberStates := map[string]api.ClusterMemberState{
+		"member01": memberState(4, 10, 90), // Fully loaded.
+		"member02": memberState(1, 80, 10),
+		"member03": memberState(1, 78, 50),
+	}
+
+	tests := []struct {
+		name   string
+		scorer *Scorer
+		want   []db.NodeInfo
+	}{
+		{
+			name:   "nil scorer keeps all candidates",
+			scorer: nil,
+			want:   candidates,
+		},
+		{
+			name:   "no weights keeps all candidates",
+			scorer: &Scorer{MemberStates: memberStates},
+			want:   candidates,
+		},
+		{
+			name:   "cpu and memory within tolerance",
+			scorer: &Scorer{Weights: Weights{CPU: 1, Memory: 1}, MemberStates: memberStates},
+			want:   candidates[1:],
+		},
+		{
+			name:   "storage favours the emptiest pool",
+			scorer: &Scorer{Weights: Weights{CPU: 1, Memory: 1, Storage: 2}, MemberStates: memberStates, PoolName: "default"},
+			want:   candidates[1:2],
+		},
+		{
+			name:   "unmeasured resources are left out",
+			scorer: &Scorer{Weights: Weights{Network: 1}, MemberStates: memberStates},
+			want:   candidates,
+		},
+		{
+			name:   "members without state are excluded",
+			scorer: &Scorer{Weights: Weights{CPU: 1}, MemberStates: map[string]api.ClusterMemberState{"member03": memberStates["member03"]}},
+			want:   candidates[2:],
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.scorer.Best(candidates))
+		})
+	}
+}
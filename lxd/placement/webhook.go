@@ -0,0 +1,124 @@
+package placement
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+// webhookMaxResponseSize is the maximum size of the response body read from the placement webhook.
+const webhookMaxResponseSize = 1024 * 1024
+
+// Webhook delegates the selection of the cluster member to place an instance on to an external HTTP endpoint.
+type Webhook struct {
+	// URL of the endpoint.
+	URL string
+
+	// Timeout of the request, after which the built-in placement policy applies.
+	Timeout time.Duration
+
+	// Proxy used to reach the endpoint.
+	Proxy func(req *http.Request) (*url.URL, error)
+}
+
+// Select sends the placement request along with the candidate cluster members to the webhook and returns the
+// candidate it selected. If the webhook fails, times out, defers the decision or selects a member that isn't a
+// candidate, nil is returned so that the built-in placement policy applies.
+// An error is only returned if the webhook rejected the placement request.
+// If the webhook is nil, nil is returned.
+func (w *Webhook) Select(ctx context.Context, req api.InstancePlacementWebhookRequest, candidates []db.NodeInfo) (*db.NodeInfo, error) {
+	if w == nil || len(candidates) == 0 {
+		return nil, nil
+	}
+
+	req.Candidates = make([]api.InstancePlacementWebhookCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		architecture, _ := osarch.ArchitectureName(candidate.Architecture)
+
+		req.Candidates = append(req.Candidates, api.InstancePlacementWebhookCandidate{
+			Name:         candidate.Name,
+			Description:  candidate.Description,
+			Architecture: architecture,
+			Groups:       candidate.Groups,
+			Config:       candidate.Config,
+		})
+	}
+
+	l := logger.AddContext(logger.Ctx{"url": w.URL, "project": req.Project, "instance": req.Instance.Name, "reason": req.Reason})
+
+	resp, err := w.send(ctx, req)
+	if err != nil {
+		l.Warn("Failed calling instance placement webhook, using built-in placement", logger.Ctx{"err": err})
+		return nil, nil
+	}
+
+	if resp.Error != "" {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Instance placement rejected: %s", resp.Error)
+	}
+
+	if resp.Target == "" {
+		return nil, nil
+	}
+
+	for i := range candidates {
+		if candidates[i].Name == resp.Target {
+			return &candidates[i], nil
+		}
+	}
+
+	l.Warn("Instance placement webhook selected a cluster member that isn't a candidate, using built-in placement", logger.Ctx{"target": resp.Target})
+	return nil, nil
+}
+
+// send posts the request to the webhook and decodes its response.
+func (w *Webhook) send(ctx context.Context, req api.InstancePlacementWebhookRequest) (*api.InstancePlacementWebhookResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = w.Proxy
+	transport.DisableKeepAlives = true
+
+	client := &http.Client{Transport: transport}
+
+	httpResp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Unexpected response status %q", httpResp.Status)
+	}
+
+	resp := &api.InstancePlacementWebhookResponse{}
+	err = json.NewDecoder(io.LimitReader(httpResp.Body, webhookMaxResponseSize)).Decode(resp)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding response: %w", err)
+	}
+
+	return resp, nil
+}
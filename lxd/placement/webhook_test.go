@@ -0,0 +1,124 @@
+package placement
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/db"
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestWebhookSelect(t *testing.T) {
+	candidates := []db.NodeInfo{
+		{ID: 1, Name: "member01"},
+		{ID: 2, Name: "member02"},
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		response   any
+		delay      time.Duration
+		wantTarget string
+		wantErr    bool
+	}{
+		{
+			name:       "selected candidate",
+			status:     http.StatusOK,
+			response:   api.InstancePlacementWebhookResponse{Target: "member02"},
+			wantTarget: "member02",
+		},
+		{
+			name:     "rejection",
+			status:   http.StatusOK,
+			response: api.InstancePlacementWebhookResponse{Error: "Not enough GPUs"},
+			wantErr:  true,
+		},
+		{
+			name:     "deferred decision",
+			status:   http.StatusOK,
+			response: api.InstancePlacementWebhookResponse{},
+		},
+		{
+			name:     "unknown member",
+			status:   http.StatusOK,
+			response: api.InstancePlacementWebhookResponse{Target: "member03"},
+		},
+		{
+			name:     "server error",
+			status:   http.StatusInternalServerError,
+			response: api.InstancePlacementWebhookResponse{Target: "member02"},
+		},
+		{
+			name:     "invalid response",
+			status:   http.StatusOK,
+			response: "member02",
+		},
+		{
+			name:     "timeout",
+			status:   http.StatusOK,
+			response: api.InstancePlacementWebhookResponse{Target: "member02"},
+			delay:    time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				req := api.InstancePlacementWebhookRequest{}
+				err := json.NewDecoder(r.Body).Decode(&req)
+				assert.NoError(t, err)
+				assert.Equal(t, api.InstancePlacementReasonNew, req.Reason)
+				assert.Equal(t, "c1", req.Instance.Name)
+				assert.Len(t, req.Candidates, len(candidates))
+
+				select {
+				case <-time.After(tt.delay):
+				case <-r.Context().Done():
+					return
+				}
+
+				w.WriteHeader(tt.status)
+				_ = json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer server.Close()
+
+			webhook := &Webhook{URL: server.URL, Timeout: 100 * time.Millisecond}
+			req := api.InstancePlacementWebhookRequest{
+				Reason:   api.InstancePlacementReasonNew,
+				Project:  "default",
+				Instance: api.InstancesPost{Name: "c1"},
+			}
+
+			target, err := webhook.Select(context.Background(), req, candidates)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.True(t, api.StatusErrorCheck(err, http.StatusBadRequest))
+				return
+			}
+
+			require.NoError(t, err)
+			if tt.wantTarget == "" {
+				assert.Nil(t, target)
+				return
+			}
+
+			require.NotNil(t, target)
+			assert.Equal(t, tt.wantTarget, target.Name)
+		})
+	}
+
+	t.Run("nil webhook", func(t *testing.T) {
+		var webhook *Webhook
+		target, err := webhook.Select(context.Background(), api.InstancePlacementWebhookRequest{}, candidates)
+		require.NoError(t, err)
+		assert.Nil(t, target)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/canonical/lxd/lxd/auth"
@@ -18,6 +19,7 @@ import (
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/state"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
 	"github.com/canonical/lxd/shared/validate"
@@ -305,6 +307,11 @@ func placementGroupsPost(d *Daemon, r *http.Request) response.Response {
 			return err
 		}
 
+		err = placementGroupValidateRules(ctx, tx, projectName, req.Config)
+		if err != nil {
+			return err
+		}
+
 		// The project ID should already be in scope or context, because we have already checked if the caller has access to it.
 		// Since it currently isn't available, get it to perform the creation.
 		projectID, err := cluster.GetProjectID(ctx, tx.Tx(), projectName)
@@ -389,6 +396,15 @@ func doPlacementGroupDelete(ctx context.Context, s *state.State, name string, pr
 			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q is currently in use", name)
 		}
 
+		referencedBy, err := placementGroupReferencedBy(ctx, tx, dbGroup.ProjectName, dbGroup.Row.Name)
+		if err != nil {
+			return err
+		}
+
+		if len(referencedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q is referenced by placement group %q", name, referencedBy[0])
+		}
+
 		return query.DeleteByPrimaryKey(ctx, tx.Tx(), dbGroup.Row)
 	})
 	if err != nil {
@@ -634,6 +650,11 @@ func placementGroupPut(d *Daemon, r *http.Request) response.Response {
 	}
 
 	err = s.DB.Cluster.Transaction(r.Context(), func(ctx context.Context, tx *db.ClusterTx) error {
+		err = placementGroupValidateRules(ctx, tx, projectName, updatedConfig)
+		if err != nil {
+			return err
+		}
+
 		if descriptionChanged {
 			err = query.UpdateByPrimaryKey(ctx, tx.Tx(), updatedPlacementGroup.Row)
 			if err != nil {
@@ -713,6 +734,15 @@ func placementGroupPost(d *Daemon, r *http.Request) response.Response {
 			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q is currently in use", placementGroupName)
 		}
 
+		referencedBy, err := placementGroupReferencedBy(ctx, tx, dbGroup.ProjectName, dbGroup.Row.Name)
+		if err != nil {
+			return err
+		}
+
+		if len(referencedBy) > 0 {
+			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q is referenced by placement group %q", placementGroupName, referencedBy[0])
+		}
+
 		dbGroup.Row.Name = req.Name
 		return query.UpdateByPrimaryKey(ctx, tx.Tx(), dbGroup.Row)
 	})
@@ -750,6 +780,38 @@ func placementGroupValidateConfig(config map[string]string) error {
 		//  required: "yes"
 		//  shortdesc: Enforcement level of the placement policy
 		"rigor": validate.IsOneOf(api.PlacementRigorStrict, api.PlacementRigorPermissive),
+
+		// lxdmeta:generate(entities=placement-group; group=placement-group; key=scope)
+		// Determines the unit the policy and rules apply to.
+		//
+		// Possible values are `member` and `failure-domain`.
+		// With `failure-domain`, all cluster members sharing a failure domain are treated as one unit.
+		// See {ref}`clustering-instance-placement` for more information.
+		// ---
+		//  type: string
+		//  defaultdesc: `member`
+		//  shortdesc: Scope of the placement policy
+		"scope": validate.Optional(validate.IsOneOf(api.PlacementScopeMember, api.PlacementScopeFailureDomain)),
+
+		// lxdmeta:generate(entities=placement-group; group=placement-group; key=affinity)
+		// Comma-separated list of placement groups in the same project whose instances
+		// the instances of this group must be co-located with.
+		//
+		// See {ref}`cluster-placement-groups-rules` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Placement groups to co-locate with
+		"affinity": validate.Optional(validate.IsListOf(validate.IsDeviceName)),
+
+		// lxdmeta:generate(entities=placement-group; group=placement-group; key=anti_affinity)
+		// Comma-separated list of placement groups in the same project whose instances
+		// the instances of this group must never share a cluster member (or failure domain) with.
+		//
+		// See {ref}`cluster-placement-groups-rules` for more information.
+		// ---
+		//  type: string
+		//  shortdesc: Placement groups to keep apart from
+		"anti_affinity": validate.Optional(validate.IsListOf(validate.IsDeviceName)),
 	}
 
 	for k, v := range config {
@@ -773,6 +835,14 @@ func placementGroupValidateConfig(config map[string]string) error {
 		}
 	}
 
+	// A placement group cannot be both co-located with and kept apart from another placement group.
+	antiAffinity := shared.SplitNTrimSpace(config["anti_affinity"], ",", -1, true)
+	for _, groupName := range shared.SplitNTrimSpace(config["affinity"], ",", -1, true) {
+		if slices.Contains(antiAffinity, groupName) {
+			return api.StatusErrorf(http.StatusBadRequest, "Placement group %q cannot be in both %q and %q", groupName, "affinity", "anti_affinity")
+		}
+	}
+
 	// Policy and rigor are both required.
 	for _, k := range []string{"policy", "rigor"} {
 		_, found := config[k]
@@ -783,3 +853,52 @@ func placementGroupValidateConfig(config map[string]string) error {
 
 	return nil
 }
+
+// placementGroupValidateRules checks that the placement groups referenced by the affinity and anti-affinity rules of
+// the given config exist in the project. Rules referencing a missing placement group would never constrain placement.
+func placementGroupValidateRules(ctx context.Context, tx *db.ClusterTx, projectName string, config map[string]string) error {
+	for _, key := range []string{"affinity", "anti_affinity"} {
+		for _, groupName := range shared.SplitNTrimSpace(config[key], ",", -1, true) {
+			_, err := cluster.GetPlacementGroup(ctx, tx.Tx(), groupName, projectName)
+			if err != nil {
+				if api.StatusErrorCheck(err, http.StatusNotFound) {
+					return api.StatusErrorf(http.StatusBadRequest, "Placement group %q in %q not found", groupName, key)
+				}
+
+				return fmt.Errorf("Failed loading placement group %q: %w", groupName, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// placementGroupReferencedBy returns the names of the other placement groups in the project whose affinity or
+// anti-affinity rules reference the placement group with the given name.
+func placementGroupReferencedBy(ctx context.Context, tx *db.ClusterTx, projectName string, name string) ([]string, error) {
+	configs, err := cluster.PlacementGroupsConfigStore().Select(ctx, tx.Tx(), "JOIN projects ON placement_groups.project_id = projects.id WHERE projects.name = ?", projectName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting placement group configs: %w", err)
+	}
+
+	groups, _, err := cluster.GetPlacementGroupsAndURLs(ctx, tx.Tx(), &projectName, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed getting placement groups: %w", err)
+	}
+
+	var referencedBy []string
+	for _, group := range groups {
+		if group.Row.Name == name {
+			continue
+		}
+
+		for _, key := range []string{"affinity", "anti_affinity"} {
+			if slices.Contains(shared.SplitNTrimSpace(configs[group.Row.ID][key], ",", -1, true), name) {
+				referencedBy = append(referencedBy, group.Row.Name)
+				break
+			}
+		}
+	}
+
+	return referencedBy, nil
+}
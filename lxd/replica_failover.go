@@ -0,0 +1,473 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	lxdCluster "github.com/canonical/lxd/lxd/cluster"
+	"github.com/canonical/lxd/lxd/db"
+	dbCluster "github.com/canonical/lxd/lxd/db/cluster"
+	"github.com/canonical/lxd/lxd/db/operationtype"
+	"github.com/canonical/lxd/lxd/instance"
+	"github.com/canonical/lxd/lxd/lifecycle"
+	"github.com/canonical/lxd/lxd/operations"
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/state"
+	"github.com/canonical/lxd/lxd/task"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/entity"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// replicaFailoverDefaultGracePeriod is the default number of seconds the leader cluster of a standby project
+// must be unreachable before the project is automatically promoted.
+const replicaFailoverDefaultGracePeriod = 300
+
+// replicaFailoverVolatileReset clears the automatic failover state of a project.
+var replicaFailoverVolatileReset = map[string]string{
+	"volatile.replica.unreachable_since": "",
+	"volatile.replica.fenced_leader":     "",
+}
+
+// autoReplicaFailoverTask returns a task that checks every minute whether the leader cluster of standby projects
+// with automatic failover enabled is still reachable, and whether leader projects have been fenced by a standby
+// that took over. The task only runs on the cluster leader.
+func autoReplicaFailoverTask(stateFunc func() *state.State) (task.Func, task.Schedule) {
+	f := func(ctx context.Context) {
+		s := stateFunc()
+
+		leaderInfo, err := s.LeaderInfo()
+		if err != nil {
+			logger.Error("Failed getting leader cluster member address", logger.Ctx{"err": err})
+			return
+		}
+
+		if !leaderInfo.Leader {
+			logger.Debug("Skipping replica failover task since we're not leader")
+			return
+		}
+
+		err = autoReplicaFailover(ctx, s)
+		if err != nil {
+			logger.Error("Failed checking replica failover", logger.Ctx{"err": err})
+		}
+	}
+
+	return f, task.Every(time.Minute)
+}
+
+// autoReplicaFailover checks the health of the leader of every standby project with automatic failover enabled,
+// and fences every leader project whose standby was automatically promoted.
+func autoReplicaFailover(ctx context.Context, s *state.State) error {
+	var projects []api.Project
+	var replicatorLinks map[string][]string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		dbProjects, err := dbCluster.GetProjects(ctx, tx.Tx())
+		if err != nil {
+			return fmt.Errorf("Failed loading projects: %w", err)
+		}
+
+		for _, dbProject := range dbProjects {
+			if dbProject.ReplicaMode == dbCluster.ProjectReplicaMode(api.ReplicatorProjectModeNone) {
+				continue
+			}
+
+			project, err := dbProject.ToAPI(ctx, tx.Tx())
+			if err != nil {
+				return err
+			}
+
+			projects = append(projects, *project)
+		}
+
+		replicatorLinks, err = replicaFailoverReplicatorLinks(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		switch project.ReplicaMode {
+		case api.ReplicatorProjectModeStandby:
+			if shared.IsFalseOrEmpty(project.Config["replica.failover"]) {
+				continue
+			}
+
+			err = replicaFailoverCheckLeader(ctx, s, project)
+			if err != nil {
+				logger.Warn("Failed checking leader of standby project", logger.Ctx{"project": project.Name, "err": err})
+			}
+
+		case api.ReplicatorProjectModeLeader:
+			_, err = replicaFailoverCheckFenced(ctx, s, project, replicatorLinks[project.Name])
+			if err != nil {
+				logger.Warn("Failed checking whether leader project was fenced", logger.Ctx{"project": project.Name, "err": err})
+			}
+		}
+	}
+
+	return nil
+}
+
+// replicaFailoverReplicatorLinks returns the cluster links used by the replicators of each project, indexed by
+// project name.
+func replicaFailoverReplicatorLinks(ctx context.Context, tx *db.ClusterTx) (map[string][]string, error) {
+	replicators, _, err := dbCluster.GetReplicatorsAndURLs(ctx, tx.Tx(), nil, func(_ dbCluster.Replicator) bool { return true })
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading replicators: %w", err)
+	}
+
+	allConfigs, err := dbCluster.ReplicatorsConfigStore().GetAll(ctx, tx.Tx())
+	if err != nil {
+		return nil, fmt.Errorf("Failed loading replicator configs: %w", err)
+	}
+
+	replicatorLinks := map[string][]string{}
+	for _, replicator := range replicators {
+		apiReplicator := replicator.ToAPI(allConfigs)
+		clusterLinkName := apiReplicator.Config["cluster"]
+		if clusterLinkName != "" {
+			replicatorLinks[apiReplicator.Project] = append(replicatorLinks[apiReplicator.Project], clusterLinkName)
+		}
+	}
+
+	return replicatorLinks, nil
+}
+
+// replicaFailoverCheckLeader checks whether the leader cluster of the given standby project is reachable through
+// the cluster link set in replica.cluster. Once it has been unreachable for longer than the grace period, the
+// project is promoted to leader.
+func replicaFailoverCheckLeader(ctx context.Context, s *state.State, project api.Project) error {
+	clusterLinkName := project.Config["replica.cluster"]
+	if clusterLinkName == "" {
+		return errors.New("Automatic failover requires replica.cluster to be set")
+	}
+
+	err := lxdCluster.RefreshClusterLinkVolatileAddresses(ctx, s, clusterLinkName)
+	if err != nil && !errors.Is(err, lxdCluster.ErrClusterLinkUnreachable) {
+		return err
+	}
+
+	unreachableSince := project.Config["volatile.replica.unreachable_since"]
+
+	// The leader is reachable, reset any previously recorded outage.
+	if err == nil {
+		if unreachableSince == "" {
+			return nil
+		}
+
+		logger.Info("Leader cluster of standby project is reachable again", logger.Ctx{"project": project.Name, "clusterLink": clusterLinkName})
+		return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), project.Name, map[string]string{"volatile.replica.unreachable_since": ""})
+		})
+	}
+
+	// First failed check, record the start of the outage.
+	if unreachableSince == "" {
+		logger.Warn("Leader cluster of standby project is unreachable", logger.Ctx{"project": project.Name, "clusterLink": clusterLinkName, "err": err})
+		return s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), project.Name, map[string]string{"volatile.replica.unreachable_since": time.Now().UTC().Format(time.RFC3339)})
+		})
+	}
+
+	promote, err := replicaFailoverGracePeriodExpired(project.Config, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !promote {
+		return nil
+	}
+
+	logger.Warn("Leader cluster of standby project unreachable for longer than the grace period, promoting project", logger.Ctx{"project": project.Name, "clusterLink": clusterLinkName, "since": unreachableSince})
+
+	op, err := operations.ScheduleServerOperation(s, operations.OperationArgs{
+		Type:      operationtype.ProjectReplicaModeUpdate,
+		Class:     operationtype.OperationClassTask,
+		EntityURL: entity.ProjectURL(project.Name),
+		RunHook: func(ctx context.Context, op *operations.Operation) error {
+			return replicaFailoverPromote(ctx, s, project.Name, clusterLinkName)
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed creating project promotion operation: %w", err)
+	}
+
+	return op.Wait(ctx)
+}
+
+// replicaFailoverGracePeriodExpired returns whether the leader cluster of a standby project with the given config
+// has been unreachable for longer than the failover grace period at the given time.
+func replicaFailoverGracePeriodExpired(config map[string]string, now time.Time) (bool, error) {
+	if config["volatile.replica.unreachable_since"] == "" {
+		return false, nil
+	}
+
+	since, err := time.Parse(time.RFC3339, config["volatile.replica.unreachable_since"])
+	if err != nil {
+		return false, fmt.Errorf("Failed parsing volatile.replica.unreachable_since: %w", err)
+	}
+
+	gracePeriod := int64(replicaFailoverDefaultGracePeriod)
+	if config["replica.failover.grace_period"] != "" {
+		gracePeriod, err = strconv.ParseInt(config["replica.failover.grace_period"], 10, 64)
+		if err != nil {
+			return false, fmt.Errorf("Failed parsing replica.failover.grace_period: %w", err)
+		}
+	}
+
+	return now.Sub(since) >= time.Duration(gracePeriod)*time.Second, nil
+}
+
+// replicaFailoverPromote promotes the given standby project to leader and starts its instances. The certificate
+// fingerprint of the former leader cluster is recorded so that it demotes itself once it becomes reachable again.
+func replicaFailoverPromote(ctx context.Context, s *state.State, projectName string, clusterLinkName string) error {
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		_, _, leaderCert, err := lxdCluster.LoadClusterLinkAndCert(ctx, tx.Tx(), clusterLinkName)
+		if err != nil {
+			return err
+		}
+
+		err = dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), projectName, api.ReplicatorProjectModeLeader)
+		if err != nil {
+			return fmt.Errorf("Failed updating project replica mode: %w", err)
+		}
+
+		return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), projectName, map[string]string{
+			"volatile.replica.unreachable_since": "",
+			"volatile.replica.fenced_leader":     shared.CertFingerprint(leaderCert),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Events.SendLifecycle(projectName, lifecycle.ProjectUpdated.Event(projectName, request.CreateRequestor(ctx), nil))
+
+	return replicaFailoverUpdateInstances(ctx, s, projectName, true)
+}
+
+// replicaFailoverFenceLinks returns the cluster links through which the standby of the given leader project may be
+// reached. These are the cluster links of the project replicators, along with replica.cluster which is kept when a
+// standby project is promoted and points at the cluster it took over from.
+func replicaFailoverFenceLinks(project api.Project, replicatorLinks []string) []string {
+	clusterLinkNames := slices.Clone(replicatorLinks)
+	if project.Config["replica.cluster"] != "" && !slices.Contains(clusterLinkNames, project.Config["replica.cluster"]) {
+		clusterLinkNames = append(clusterLinkNames, project.Config["replica.cluster"])
+	}
+
+	return clusterLinkNames
+}
+
+// replicaFailoverIsFenced returns whether the given project of a standby cluster was promoted to leader after
+// losing contact with the cluster with the given certificate fingerprint.
+func replicaFailoverIsFenced(standbyProject *api.Project, fingerprint string) bool {
+	return standbyProject.ReplicaMode == api.ReplicatorProjectModeLeader && standbyProject.Config["volatile.replica.fenced_leader"] == fingerprint
+}
+
+// replicaFailoverCheckFenced checks whether the standby of the given leader project, reached through the given
+// replicator cluster links or replica.cluster, was automatically promoted after losing contact with this cluster.
+// If so, the project is demoted to standby and its instances are stopped so that both clusters never act as leader
+// at the same time. It returns whether the project was fenced.
+func replicaFailoverCheckFenced(ctx context.Context, s *state.State, project api.Project, replicatorLinks []string) (bool, error) {
+	clusterCert := s.Endpoints.NetworkCert()
+
+	for _, clusterLinkName := range replicaFailoverFenceLinks(project, replicatorLinks) {
+		var clusterLink *api.ClusterLink
+		var targetCert *x509.Certificate
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+			_, clusterLink, targetCert, err = lxdCluster.LoadClusterLinkAndCert(ctx, tx.Tx(), clusterLinkName)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("Failed loading cluster link %q: %w", clusterLinkName, err)
+		}
+
+		targetClient, err := lxdCluster.ConnectCluster(ctx, *clusterLink, lxdCluster.GetClusterLinkConnectionArgs(clusterCert, targetCert))
+		if err != nil {
+			// The standby is unreachable, nothing to check against.
+			continue
+		}
+
+		targetProject, _, err := targetClient.GetProject(project.Name)
+		targetClient.Disconnect()
+		if err != nil {
+			logger.Warn("Failed getting project from standby cluster", logger.Ctx{"project": project.Name, "clusterLink": clusterLinkName, "err": err})
+			continue
+		}
+
+		if !replicaFailoverIsFenced(targetProject, clusterCert.Fingerprint()) {
+			continue
+		}
+
+		logger.Warn("Standby cluster took over as leader, demoting project", logger.Ctx{"project": project.Name, "clusterLink": clusterLinkName})
+
+		err = s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			err := dbCluster.UpdateProjectReplicaMode(ctx, tx.Tx(), project.Name, api.ReplicatorProjectModeStandby)
+			if err != nil {
+				return fmt.Errorf("Failed updating project replica mode: %w", err)
+			}
+
+			// Point the demoted project at the new leader so it can be restored from it.
+			if project.Config["replica.cluster"] != "" {
+				return nil
+			}
+
+			return dbCluster.UpdateProjectConfigKeys(ctx, tx.Tx(), project.Name, map[string]string{"replica.cluster": clusterLinkName})
+		})
+		if err != nil {
+			return false, err
+		}
+
+		s.Events.SendLifecycle(project.Name, lifecycle.ProjectUpdated.Event(project.Name, request.CreateRequestor(ctx), nil))
+
+		return true, replicaFailoverUpdateInstances(ctx, s, project.Name, false)
+	}
+
+	return false, nil
+}
+
+// replicaFailoverFencedProjects checks whether the leader projects of the given instances were fenced by their
+// standby, for instance while this cluster member was offline, and returns the names of the projects which were.
+// It is used before auto-starting instances so that the instances of a fenced project are never started.
+func replicaFailoverFencedProjects(ctx context.Context, s *state.State, instances []instance.Instance) map[string]bool {
+	projects := map[string]api.Project{}
+	for _, inst := range instances {
+		if inst.Project().ReplicaMode == api.ReplicatorProjectModeLeader && instanceShouldAutoStart(inst) && !inst.IsRunning() {
+			projects[inst.Project().Name] = inst.Project()
+		}
+	}
+
+	if len(projects) == 0 {
+		return nil
+	}
+
+	var replicatorLinks map[string][]string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		var err error
+		replicatorLinks, err = replicaFailoverReplicatorLinks(ctx, tx)
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed loading replicators to check whether leader projects were fenced", logger.Ctx{"err": err})
+		return nil
+	}
+
+	fenced := map[string]bool{}
+	for _, project := range projects {
+		isFenced, err := replicaFailoverCheckFenced(ctx, s, project, replicatorLinks[project.Name])
+		if err != nil {
+			logger.Warn("Failed checking whether leader project was fenced", logger.Ctx{"project": project.Name, "err": err})
+		}
+
+		if isFenced {
+			fenced[project.Name] = true
+		}
+	}
+
+	return fenced
+}
+
+// replicaFailoverUpdateInstances starts or force stops all instances of the given project across all cluster
+// members. When starting, instances with boot.autostart set to false are left stopped.
+func replicaFailoverUpdateInstances(ctx context.Context, s *state.State, projectName string, start bool) error {
+	var insts []instance.Instance
+	var nodeAddressByName map[string]string
+	err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+		err := tx.InstanceList(ctx, func(dbInst db.InstanceArgs, p api.Project) error {
+			inst, err := instance.Load(s, dbInst, p)
+			if err != nil {
+				return fmt.Errorf("Failed loading instance %q: %w", dbInst.Name, err)
+			}
+
+			insts = append(insts, inst)
+			return nil
+		}, dbCluster.InstanceFilter{Project: &projectName})
+		if err != nil {
+			return fmt.Errorf("Failed listing project instances: %w", err)
+		}
+
+		nodes, err := tx.GetNodes(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed listing cluster members: %w", err)
+		}
+
+		nodeAddressByName = make(map[string]string, len(nodes))
+		for _, node := range nodes {
+			nodeAddressByName[node.Name] = node.Address
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, inst := range insts {
+		if start && shared.IsFalse(inst.ExpandedConfig()["boot.autostart"]) {
+			continue
+		}
+
+		err := replicaFailoverUpdateInstance(ctx, s, inst, nodeAddressByName[inst.Location()], start)
+		if err != nil {
+			logger.Warn("Failed updating instance state after replica failover", logger.Ctx{"project": projectName, "instance": inst.Name(), "start": start, "err": err})
+			errs = append(errs, fmt.Errorf("Instance %q: %w", inst.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// replicaFailoverUpdateInstance starts or force stops a single instance, forwarding the request to the hosting
+// cluster member if needed.
+func replicaFailoverUpdateInstance(ctx context.Context, s *state.State, inst instance.Instance, memberAddress string, start bool) error {
+	if s.ServerClustered && inst.Location() != s.ServerName {
+		client, err := lxdCluster.Connect(ctx, memberAddress, s.Endpoints.NetworkCert(), s.ServerCert(), true)
+		if err != nil {
+			return fmt.Errorf("Failed connecting to hosting cluster member: %w", err)
+		}
+
+		client = client.UseProject(inst.Project().Name)
+
+		state, _, err := client.GetInstanceState(inst.Name())
+		if err != nil {
+			return err
+		}
+
+		running := state.StatusCode == api.Running
+		if running == start {
+			return nil
+		}
+
+		req := api.InstanceStatePut{Action: "start"}
+		if !start {
+			req = api.InstanceStatePut{Action: "stop", Force: true}
+		}
+
+		op, err := client.UpdateInstanceState(inst.Name(), req, "")
+		if err != nil {
+			return err
+		}
+
+		return op.Wait()
+	}
+
+	if inst.IsRunning() == start {
+		return nil
+	}
+
+	if start {
+		return inst.Start(ctx, false, nil)
+	}
+
+	return inst.Stop(ctx, false)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func TestReplicaFailoverGracePeriodExpired(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		config  map[string]string
+		promote bool
+		wantErr bool
+	}{
+		{
+			name:    "Leader reachable",
+			config:  map[string]string{},
+			promote: false,
+		},
+		{
+			name:    "Within the default grace period",
+			config:  map[string]string{"volatile.replica.unreachable_since": now.Add(-299 * time.Second).Format(time.RFC3339)},
+			promote: false,
+		},
+		{
+			name:    "Past the default grace period",
+			config:  map[string]string{"volatile.replica.unreachable_since": now.Add(-300 * time.Second).Format(time.RFC3339)},
+			promote: true,
+		},
+		{
+			name: "Within a custom grace period",
+			config: map[string]string{
+				"volatile.replica.unreachable_since": now.Add(-10 * time.Minute).Format(time.RFC3339),
+				"replica.failover.grace_period":      "3600",
+			},
+			promote: false,
+		},
+		{
+			name: "Past a custom grace period",
+			config: map[string]string{
+				"volatile.replica.unreachable_since": now.Add(-10 * time.Minute).Format(time.RFC3339),
+				"replica.failover.grace_period":      "60",
+			},
+			promote: true,
+		},
+		{
+			name:    "Invalid outage start",
+			config:  map[string]string{"volatile.replica.unreachable_since": "yesterday"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promote, err := replicaFailoverGracePeriodExpired(tt.config, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.promote, promote)
+		})
+	}
+}
+
+func TestReplicaFailoverIsFenced(t *testing.T) {
+	fingerprint := "abcdef"
+
+	tests := []struct {
+		name           string
+		standbyProject api.Project
+		fenced         bool
+	}{
+		{
+			name:           "Standby not promoted",
+			standbyProject: api.Project{ReplicaMode: api.ReplicatorProjectModeStandby},
+			fenced:         false,
+		},
+		{
+			name: "Standby promoted manually",
+			standbyProject: api.Project{
+				ReplicaMode: api.ReplicatorProjectModeLeader,
+				Config:      map[string]string{},
+			},
+			fenced: false,
+		},
+		{
+			name: "Standby promoted after losing another leader",
+			standbyProject: api.Project{
+				ReplicaMode: api.ReplicatorProjectModeLeader,
+				Config:      map[string]string{"volatile.replica.fenced_leader": "012345"},
+			},
+			fenced: false,
+		},
+		{
+			name: "Standby promoted after losing this leader",
+			standbyProject: api.Project{
+				ReplicaMode: api.ReplicatorProjectModeLeader,
+				Config:      map[string]string{"volatile.replica.fenced_leader": fingerprint},
+			},
+			fenced: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.fenced, replicaFailoverIsFenced(&tt.standbyProject, fingerprint))
+		})
+	}
+}
+
+func TestReplicaFailoverFenceLinks(t *testing.T) {
+	project := api.Project{Config: map[string]string{}}
+	assert.Empty(t, replicaFailoverFenceLinks(project, nil))
+	assert.Equal(t, []string{"lxd02"}, replicaFailoverFenceLinks(project, []string{"lxd02"}))
+
+	// A promoted project without replicators is checked through replica.cluster.
+	project.Config["replica.cluster"] = "lxd02"
+	assert.Equal(t, []string{"lxd02"}, replicaFailoverFenceLinks(project, nil))
+	assert.Equal(t, []string{"lxd02", "lxd03"}, replicaFailoverFenceLinks(project, []string{"lxd02", "lxd03"}))
+	assert.Equal(t, []string{"lxd03", "lxd02"}, replicaFailoverFenceLinks(project, []string{"lxd03"}))
+}
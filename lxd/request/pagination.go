@@ -0,0 +1,234 @@
+package request
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/filter"
+)
+
+// Pagination holds the sorting and pagination parameters of a collection request.
+type Pagination struct {
+	// Limit is the maximum number of entries to return (0 means no limit).
+	Limit int
+
+	// Sort is the list of fields to sort the collection by.
+	Sort []filter.SortKey
+
+	// after holds the sort and key values of the last entry of the previous page, as recorded in the continue token.
+	after []json.RawMessage
+
+	filter string
+}
+
+// continueToken is the decoded form of the opaque "continue" query parameter.
+// Pages are selected by key rather than by offset, so that entries being added or removed between requests don't
+// cause entries to be skipped or returned twice.
+type continueToken struct {
+	After  []json.RawMessage `json:"after"`
+	Sort   string            `json:"sort,omitempty"`
+	Filter string            `json:"filter,omitempty"`
+}
+
+// PaginationParams parses the "limit", "sort" and "continue" query parameters of the given request. It returns
+// an [api.StatusError] with [http.StatusBadRequest] if any of them is invalid, or if the continue token was issued
+// for a request with a different sort order or filter.
+func PaginationParams(r *http.Request) (*Pagination, error) {
+	p := &Pagination{
+		filter: QueryParam(r, "filter"),
+	}
+
+	limitStr := QueryParam(r, "limit")
+	if limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid limit %q", limitStr)
+		}
+
+		p.Limit = limit
+	}
+
+	sortStr := QueryParam(r, "sort")
+	sortKeys, err := filter.ParseSort(sortStr)
+	if err != nil {
+		return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid sort: %v", err)
+	}
+
+	p.Sort = sortKeys
+
+	continueStr := QueryParam(r, "continue")
+	if continueStr != "" {
+		tokenJSON, err := base64.RawURLEncoding.DecodeString(continueStr)
+		if err != nil {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid continue token")
+		}
+
+		var token continueToken
+
+		err = json.Unmarshal(tokenJSON, &token)
+		if err != nil || len(token.After) == 0 {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Invalid continue token")
+		}
+
+		if token.Sort != filter.FormatSort(p.Sort) || token.Filter != p.filter {
+			return nil, api.StatusErrorf(http.StatusBadRequest, "Continue token doesn't match the requested sort order or filter")
+		}
+
+		p.after = token.After
+	}
+
+	return p, nil
+}
+
+// IsSet returns whether the request asked for a sorted or paginated collection.
+func (p *Pagination) IsSet() bool {
+	return p.Limit > 0 || p.after != nil || len(p.Sort) > 0
+}
+
+// Values returns the query parameters requesting the same page, for forwarding the request to other servers.
+func (p *Pagination) Values() url.Values {
+	values := url.Values{}
+
+	if p.Limit > 0 {
+		values.Set("limit", strconv.Itoa(p.Limit))
+	}
+
+	if len(p.Sort) > 0 {
+		values.Set("sort", filter.FormatSort(p.Sort))
+	}
+
+	if p.after != nil {
+		values.Set("continue", p.token(p.after))
+	}
+
+	if p.filter != "" {
+		values.Set("filter", p.filter)
+	}
+
+	return values
+}
+
+// token returns the continue token resuming after the entry with the given sort and key values.
+func (p *Pagination) token(after []json.RawMessage) string {
+	tokenJSON, _ := json.Marshal(continueToken{
+		After:  after,
+		Sort:   filter.FormatSort(p.Sort),
+		Filter: p.filter,
+	})
+
+	return base64.RawURLEncoding.EncodeToString(tokenJSON)
+}
+
+// sortKeys returns the requested sort keys followed by the key fields that aren't already sorted on, so that the
+// order of the entries is total.
+func (p *Pagination) sortKeys(keyFields []string) []filter.SortKey {
+	keys := slices.Clone(p.Sort)
+	for _, field := range keyFields {
+		if !slices.ContainsFunc(keys, func(key filter.SortKey) bool { return key.Field == field }) {
+			keys = append(keys, filter.SortKey{Field: field})
+		}
+	}
+
+	return keys
+}
+
+// compareAfter compares the sort key values of the object with the ones recorded in the continue token.
+// It returns a positive number if the object sorts after the last entry of the previous page.
+func compareAfter(obj any, keys []filter.SortKey, after []json.RawMessage) (int, error) {
+	for i, key := range keys {
+		value := filter.ValueOf(obj, key.Field)
+
+		var afterValue any
+		if !bytes.Equal(after[i], []byte("null")) {
+			if value == nil {
+				// Missing values sort before the others, whatever the recorded value is.
+				afterValue = struct{}{}
+			} else {
+				// Decode the recorded value into the type of the object's value so that both can be compared.
+				ptr := reflect.New(reflect.TypeOf(value))
+
+				err := json.Unmarshal(after[i], ptr.Interface())
+				if err != nil {
+					return 0, err
+				}
+
+				afterValue = ptr.Elem().Interface()
+			}
+		}
+
+		res, err := filter.CompareValues(value, afterValue, key.Field)
+		if err != nil {
+			return 0, err
+		}
+
+		if res == 0 {
+			continue
+		}
+
+		if key.Descending {
+			return -res, nil
+		}
+
+		return res, nil
+	}
+
+	return 0, nil
+}
+
+// Paginate sorts the given objects according to the pagination parameters and returns the requested page along
+// with the continue token for the next page. The key fields identify an object within the collection and are used
+// to order objects with equal sort values. The continue token is empty when the page is the last one.
+func Paginate[T any](p *Pagination, objs []T, keyFields ...string) ([]T, string, error) {
+	keys := p.sortKeys(keyFields)
+
+	err := filter.Sort(objs, keys)
+	if err != nil {
+		return nil, "", api.StatusErrorf(http.StatusBadRequest, "Failed sorting: %v", err)
+	}
+
+	start := 0
+	if p.after != nil {
+		if len(p.after) != len(keys) {
+			return nil, "", api.StatusErrorf(http.StatusBadRequest, "Invalid continue token")
+		}
+
+		start = len(objs)
+		for i, obj := range objs {
+			res, err := compareAfter(obj, keys, p.after)
+			if err != nil {
+				return nil, "", api.StatusErrorf(http.StatusBadRequest, "Invalid continue token")
+			}
+
+			if res > 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := len(objs)
+	if p.Limit <= 0 || start+p.Limit >= len(objs) {
+		return objs[start:end], "", nil
+	}
+
+	end = start + p.Limit
+
+	after := make([]json.RawMessage, 0, len(keys))
+	for _, key := range keys {
+		valueJSON, err := json.Marshal(filter.ValueOf(objs[end-1], key.Field))
+		if err != nil {
+			return nil, "", err
+		}
+
+		after = append(after, valueJSON)
+	}
+
+	return objs[start:end], p.token(after), nil
+}
@@ -0,0 +1,170 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+func newPaginationRequest(values url.Values) *http.Request {
+	return httptest.NewRequest(http.MethodGet, "/1.0/instances?"+values.Encode(), nil)
+}
+
+func Test_Paginate(t *testing.T) {
+	instances := []api.Instance{
+		{Name: "c3"},
+		{Name: "c1"},
+		{Name: "c5"},
+		{Name: "c2"},
+		{Name: "c4"},
+	}
+
+	values := url.Values{
+		"limit":  []string{"2"},
+		"sort":   []string{"-name"},
+		"filter": []string{"status eq Running"},
+	}
+
+	var names []string
+	pages := 0
+
+	for {
+		p, err := PaginationParams(newPaginationRequest(values))
+		require.NoError(t, err)
+
+		page, next, err := Paginate(p, instances)
+		require.NoError(t, err)
+
+		pages++
+		for _, inst := range page {
+			names = append(names, inst.Name)
+		}
+
+		if next == "" {
+			break
+		}
+
+		values.Set("continue", next)
+	}
+
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"c5", "c4", "c3", "c2", "c1"}, names)
+}
+
+func Test_Paginate_Keyset(t *testing.T) {
+	instances := []api.Instance{
+		{Name: "c1", Project: "p2", Status: "Running"},
+		{Name: "c2", Project: "p1", Status: "Stopped"},
+		{Name: "c3", Project: "p1", Status: "Running"},
+		{Name: "c1", Project: "p1", Status: "Running"},
+		{Name: "c4", Project: "p1", Status: "Stopped"},
+	}
+
+	values := url.Values{"limit": []string{"2"}, "sort": []string{"status"}}
+
+	p, err := PaginationParams(newPaginationRequest(values))
+	require.NoError(t, err)
+
+	page, next, err := Paginate(p, instances, "project", "name")
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+	assert.Equal(t, []string{"p1/c1", "p1/c3"}, []string{page[0].Project + "/" + page[0].Name, page[1].Project + "/" + page[1].Name})
+
+	// Entries added or removed before the end of the previous page don't shift the next page.
+	instances = append(instances, api.Instance{Name: "c0", Project: "p1", Status: "Running"})
+	instances = slices.DeleteFunc(instances, func(inst api.Instance) bool { return inst.Project == "p1" && inst.Name == "c1" })
+
+	values.Set("continue", next)
+	p, err = PaginationParams(newPaginationRequest(values))
+	require.NoError(t, err)
+
+	page, next, err = Paginate(p, instances, "project", "name")
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+	assert.Equal(t, []string{"p2/c1", "p1/c2"}, []string{page[0].Project + "/" + page[0].Name, page[1].Project + "/" + page[1].Name})
+
+	values.Set("continue", next)
+	p, err = PaginationParams(newPaginationRequest(values))
+	require.NoError(t, err)
+
+	page, next, err = Paginate(p, instances, "project", "name")
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	require.Len(t, page, 1)
+	assert.Equal(t, "c4", page[0].Name)
+
+	// The forwarded parameters select the same page.
+	forwarded, err := PaginationParams(newPaginationRequest(p.Values()))
+	require.NoError(t, err)
+	assert.Equal(t, p, forwarded)
+}
+
+func Test_Paginate_NoParams(t *testing.T) {
+	instances := []string{"b", "a", "c"}
+
+	p, err := PaginationParams(newPaginationRequest(url.Values{}))
+	require.NoError(t, err)
+	assert.False(t, p.IsSet())
+
+	page, next, err := Paginate(p, instances)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	assert.Equal(t, []string{"b", "a", "c"}, page)
+}
+
+func Test_PaginationParams_Error(t *testing.T) {
+	p, err := PaginationParams(newPaginationRequest(url.Values{"limit": []string{"1"}, "sort": []string{"name"}}))
+	require.NoError(t, err)
+
+	_, next, err := Paginate(p, []api.Instance{{Name: "c1"}, {Name: "c2"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+
+	tests := []struct {
+		name   string
+		values url.Values
+		err    string
+	}{
+		{
+			name:   "invalid limit",
+			values: url.Values{"limit": []string{"-1"}},
+			err:    `Invalid limit "-1"`,
+		},
+		{
+			name:   "invalid sort",
+			values: url.Values{"sort": []string{"name,"}},
+			err:    "Invalid sort: Empty sort field",
+		},
+		{
+			name:   "invalid continue token",
+			values: url.Values{"continue": []string{"foo"}},
+			err:    "Invalid continue token",
+		},
+		{
+			name:   "mismatched sort",
+			values: url.Values{"limit": []string{"1"}, "sort": []string{"-name"}, "continue": []string{next}},
+			err:    "Continue token doesn't match the requested sort order or filter",
+		},
+		{
+			name:   "mismatched filter",
+			values: url.Values{"limit": []string{"1"}, "sort": []string{"name"}, "filter": []string{"name eq c1"}, "continue": []string{next}},
+			err:    "Continue token doesn't match the requested sort order or filter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PaginationParams(newPaginationRequest(tt.values))
+			require.Error(t, err)
+			assert.True(t, api.StatusErrorCheck(err, http.StatusBadRequest))
+			assert.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -194,6 +194,9 @@ type syncResponse struct {
 	headers   map[string]string
 	plaintext bool
 	compress  bool
+
+	// continueToken is the token to fetch the next page of a paginated collection.
+	continueToken string
 }
 
 // EmptySyncResponse represents an empty syncResponse.
@@ -220,6 +223,11 @@ func SyncResponseLocation(success bool, metadata any, location string) Response
 	return &syncResponse{success: success, metadata: metadata, location: location}
 }
 
+// SyncResponseContinue returns a new syncResponse for a page of a collection along with the token for the next page.
+func SyncResponseContinue(success bool, metadata any, continueToken string) Response {
+	return &syncResponse{success: success, metadata: metadata, continueToken: continueToken}
+}
+
 // SyncResponseRedirect returns a new syncResponse with a location, indicating
 // a permanent redirect.
 func SyncResponseRedirect(address string) Response {
@@ -333,6 +341,7 @@ func (r *syncResponse) Render(w http.ResponseWriter, req *http.Request) error {
 		Type:       api.SyncResponse,
 		Status:     status.String(),
 		StatusCode: int(status),
+		Continue:   r.continueToken,
 		Metadata:   r.metadata,
 	}
 
@@ -20,6 +20,7 @@ import (
 	"github.com/canonical/lxd/lxd/fsmonitor"
 	"github.com/canonical/lxd/lxd/identity"
 	"github.com/canonical/lxd/lxd/instance/instancetype"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	"github.com/canonical/lxd/lxd/node"
 	"github.com/canonical/lxd/lxd/sys"
 	"github.com/canonical/lxd/lxd/ubuntupro"
@@ -43,6 +44,9 @@ type State struct {
 	// DNS server
 	DNS *dns.Server
 
+	// Network flow logger
+	FlowLog *flowlog.Logger
+
 	// OS access
 	OS    *sys.OS
 	Proxy func(req *http.Request) (*url.URL, error)
@@ -4506,13 +4506,29 @@ func (b *lxdBackend) CreateBucket(projectName string, bucket api.StorageBucketsP
 	// Set the new bucket volume's UUID.
 	bucket.Config["volatile.uuid"] = bucketVol.Config()["volatile.uuid"]
 
-	bucketID, err := BucketDBCreate(context.TODO(), b, projectName, &bucket)
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
+	bucketID, err := BucketDBCreate(context.TODO(), b, projectName, memberSpecific, &bucket)
 	if err != nil {
 		return err
 	}
 
 	revert.Add(func() { _ = BucketDBDelete(context.TODO(), b, bucketID) })
 
+	// Handle common implementation for local storage drivers, where the bucket is stored in a volume
+	// served by the storage buckets listener.
+	if memberSpecific {
+		err = b.driver.CreateVolume(bucketVol, nil, nil)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() { _ = b.driver.DeleteVolume(bucketVol, nil) })
+
+		revert.Success()
+		return nil
+	}
+
 	// Handle per-driver implementation for remote storage drivers.
 	err = b.driver.CreateBucket(bucketVol)
 	if err != nil {
@@ -4538,10 +4554,12 @@ func (b *lxdBackend) UpdateBucket(projectName string, bucketName string, bucket
 		return errors.New("Storage pool does not support buckets")
 	}
 
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
 	// Get current config to compare what has changed.
 	var curBucket *db.StorageBucket
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		curBucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, false, bucketName)
+		curBucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
 		return err
 	})
 	if err != nil {
@@ -4587,10 +4605,22 @@ func (b *lxdBackend) UpdateBucket(projectName string, bucketName string, bucket
 
 	changedConfig, userOnly := b.detectChangedConfig(curBucket.Config, bucket.Config)
 	if len(changedConfig) > 0 && !userOnly {
-		// Handle per-driver implementation for remote storage drivers.
-		err = b.driver.UpdateBucket(curBucketVol, changedConfig)
-		if err != nil {
-			return err
+		if memberSpecific {
+			// Handle common implementation for local storage drivers.
+			// The size is the only setting applied to the bucket volume.
+			newSize, found := changedConfig["size"]
+			if found {
+				err = b.driver.SetVolumeQuota(curBucketVol, newSize, false, nil)
+				if err != nil {
+					return err
+				}
+			}
+		} else {
+			// Handle per-driver implementation for remote storage drivers.
+			err = b.driver.UpdateBucket(curBucketVol, changedConfig)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
@@ -4620,9 +4650,11 @@ func (b *lxdBackend) DeleteBucket(projectName string, bucketName string) error {
 		return errors.New("Storage pool does not support buckets")
 	}
 
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
 	var bucket *db.StorageBucket
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, false, bucketName)
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
 		return err
 	})
 	if err != nil {
@@ -4632,10 +4664,18 @@ func (b *lxdBackend) DeleteBucket(projectName string, bucketName string) error {
 	bucketVolName := project.StorageVolume(projectName, bucket.Name)
 	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
 
-	// Handle per-driver implementation for remote storage drivers.
-	err = b.driver.DeleteBucket(bucketVol)
-	if err != nil {
-		return err
+	if memberSpecific {
+		// Handle common implementation for local storage drivers.
+		err = b.driver.DeleteVolume(bucketVol, nil)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Handle per-driver implementation for remote storage drivers.
+		err = b.driver.DeleteBucket(bucketVol)
+		if err != nil {
+			return err
+		}
 	}
 
 	return BucketDBDelete(context.TODO(), b, bucket.ID)
@@ -4659,9 +4699,11 @@ func (b *lxdBackend) CreateBucketKey(projectName string, bucketName string, key
 	revert := revert.New()
 	defer revert.Fail()
 
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
 	var bucket *db.StorageBucket
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, false, bucketName)
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
 		return err
 	})
 	if err != nil {
@@ -4682,13 +4724,22 @@ func (b *lxdBackend) CreateBucketKey(projectName string, bucketName string, key
 		return nil, err
 	}
 
-	// Handle per-driver implementation for remote storage drivers.
-	newCreds, err := b.driver.CreateBucketKey(bucketVol, key.Name, creds, key.Role)
-	if err != nil {
-		return nil, err
-	}
+	var newCreds *drivers.S3Credentials
+	if memberSpecific {
+		// Handle common implementation for local storage drivers, where the keys are only stored in the database.
+		newCreds, err = b.localBucketKeyCredentials(creds, "")
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Handle per-driver implementation for remote storage drivers.
+		newCreds, err = b.driver.CreateBucketKey(bucketVol, key.Name, creds, key.Role)
+		if err != nil {
+			return nil, err
+		}
 
-	revert.Add(func() { _ = b.driver.DeleteBucketKey(bucketVol, key.Name) })
+		revert.Add(func() { _ = b.driver.DeleteBucketKey(bucketVol, key.Name) })
+	}
 
 	key.AccessKey = newCreds.AccessKey
 	key.SecretKey = newCreds.SecretKey
@@ -4729,11 +4780,13 @@ func (b *lxdBackend) UpdateBucketKey(projectName string, bucketName string, keyN
 		return errors.New("Storage pool does not support buckets")
 	}
 
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
 	// Get current config to compare what has changed.
 	var bucket *db.StorageBucket
 	var curBucketKey *db.StorageBucketKey
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, false, bucketName)
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
 		if err != nil {
 			return err
 		}
@@ -4784,10 +4837,19 @@ func (b *lxdBackend) UpdateBucketKey(projectName string, bucketName string, keyN
 		return err
 	}
 
-	// Handle per-driver implementation for remote storage drivers.
-	newCreds, err := b.driver.UpdateBucketKey(bucketVol, keyName, creds, key.Role)
-	if err != nil {
-		return err
+	var newCreds *drivers.S3Credentials
+	if memberSpecific {
+		// Handle common implementation for local storage drivers, where the keys are only stored in the database.
+		newCreds, err = b.localBucketKeyCredentials(creds, curBucketKey.AccessKey)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Handle per-driver implementation for remote storage drivers.
+		newCreds, err = b.driver.UpdateBucketKey(bucketVol, keyName, creds, key.Role)
+		if err != nil {
+			return err
+		}
 	}
 
 	key.AccessKey = newCreds.AccessKey
@@ -4819,10 +4881,12 @@ func (b *lxdBackend) DeleteBucketKey(projectName string, bucketName string, keyN
 		return errors.New("Storage pool does not support buckets")
 	}
 
+	memberSpecific := !b.Driver().Info().Remote // Member specific if storage pool isn't remote.
+
 	var bucket *db.StorageBucket
 	var bucketKey *db.StorageBucketKey
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
-		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, false, bucketName)
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, memberSpecific, bucketName)
 		if err != nil {
 			return err
 		}
@@ -4839,13 +4903,16 @@ func (b *lxdBackend) DeleteBucketKey(projectName string, bucketName string, keyN
 	}
 
 	// Handle per-driver implementation for remote storage drivers.
-	bucketVolName := project.StorageVolume(projectName, bucket.Name)
-	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
+	// Keys of local storage drivers are only stored in the database.
+	if !memberSpecific {
+		bucketVolName := project.StorageVolume(projectName, bucket.Name)
+		bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
 
-	// Delete the bucket key from the storage device.
-	err = b.driver.DeleteBucketKey(bucketVol, keyName)
-	if err != nil {
-		return err
+		// Delete the bucket key from the storage device.
+		err = b.driver.DeleteBucketKey(bucketVol, keyName)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
@@ -4869,10 +4936,124 @@ func (b *lxdBackend) GetBucketURL(bucketName string) *url.URL {
 		return nil
 	}
 
+	// Handle common implementation for local storage drivers.
+	if !b.Driver().Info().Remote {
+		if b.state.Endpoints == nil {
+			return nil
+		}
+
+		address := b.state.Endpoints.StorageBucketsAddress()
+		if address == "" {
+			return nil
+		}
+
+		return &url.URL{
+			Scheme: "https",
+			Host:   address,
+			Path:   "/" + bucketName,
+		}
+	}
+
 	// Handle per-driver implementation for remote storage drivers.
 	return b.driver.GetBucketURL(bucketName)
 }
 
+// MountBucket mounts the volume of a bucket on a local storage pool and returns its mount path.
+func (b *lxdBackend) MountBucket(projectName string, bucketName string) (string, error) {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "bucketName": bucketName})
+	l.Debug("MountBucket started")
+	defer l.Debug("MountBucket finished")
+
+	bucketVol, err := b.localBucketVolume(projectName, bucketName)
+	if err != nil {
+		return "", err
+	}
+
+	err = b.driver.MountVolume(*bucketVol, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return bucketVol.MountPath(), nil
+}
+
+// UnmountBucket unmounts the volume of a bucket on a local storage pool.
+func (b *lxdBackend) UnmountBucket(projectName string, bucketName string) error {
+	l := b.logger.AddContext(logger.Ctx{"project": projectName, "bucketName": bucketName})
+	l.Debug("UnmountBucket started")
+	defer l.Debug("UnmountBucket finished")
+
+	bucketVol, err := b.localBucketVolume(projectName, bucketName)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.driver.UnmountVolume(*bucketVol, false, nil)
+
+	return err
+}
+
+// localBucketVolume returns the volume of a bucket on a local storage pool.
+func (b *lxdBackend) localBucketVolume(projectName string, bucketName string) (*drivers.Volume, error) {
+	err := b.isStatusReady()
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.Driver().Info().Buckets || b.Driver().Info().Remote {
+		return nil, errors.New("Storage pool does not support local buckets")
+	}
+
+	var bucket *db.StorageBucket
+	err = b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+		bucket, err = tx.GetStoragePoolBucket(ctx, b.id, projectName, true, bucketName)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	bucketVolName := project.StorageVolume(projectName, bucket.Name)
+	bucketVol := b.GetVolume(drivers.VolumeTypeBucket, drivers.ContentTypeFS, bucketVolName, bucket.Config)
+
+	return &bucketVol, nil
+}
+
+// localBucketKeyCredentials returns the credentials of a local bucket key, curAccessKey being the current access
+// key of the key if it already exists. Missing access and secret keys are generated, and new access keys must be
+// unique on the member as they identify the bucket served by the storage buckets listener.
+func (b *lxdBackend) localBucketKeyCredentials(creds drivers.S3Credentials, curAccessKey string) (*drivers.S3Credentials, error) {
+	if creds.AccessKey == "" {
+		accessKey, err := shared.RandomCryptoString()
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating access key: %w", err)
+		}
+
+		creds.AccessKey = strings.ToUpper(accessKey[:20])
+	} else if creds.AccessKey != curAccessKey {
+		err := b.state.DB.Cluster.Transaction(context.TODO(), func(ctx context.Context, tx *db.ClusterTx) error {
+			_, err := tx.GetStoragePoolLocalBucketByAccessKey(ctx, creds.AccessKey)
+			return err
+		})
+		if err == nil {
+			return nil, api.StatusErrorf(http.StatusConflict, "A bucket key with that access key already exists")
+		} else if !response.IsNotFoundError(err) {
+			return nil, err
+		}
+	}
+
+	if creds.SecretKey == "" {
+		secretKey, err := shared.RandomCryptoString()
+		if err != nil {
+			return nil, fmt.Errorf("Failed generating secret key: %w", err)
+		}
+
+		creds.SecretKey = secretKey[:40]
+	}
+
+	return &creds, nil
+}
+
 // CreateCustomVolume creates an empty custom volume.
 func (b *lxdBackend) CreateCustomVolume(ctx context.Context, projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, progressReporter ioprogress.ProgressReporter) error {
 	l := b.logger.AddContext(logger.Ctx{"project": projectName, "volName": volName, "desc": desc, "config": config, "contentType": contentType})
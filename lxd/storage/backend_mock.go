@@ -345,6 +345,16 @@ func (b *mockBackend) GetBucketURL(bucketName string) *url.URL {
 	return nil
 }
 
+// MountBucket ...
+func (b *mockBackend) MountBucket(projectName string, bucketName string) (string, error) {
+	return "", nil
+}
+
+// UnmountBucket ...
+func (b *mockBackend) UnmountBucket(projectName string, bucketName string) error {
+	return nil
+}
+
 // CreateCustomVolume ...
 func (b *mockBackend) CreateCustomVolume(ctx context.Context, projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, progressReporter ioprogress.ProgressReporter) error {
 	return nil
@@ -0,0 +1,751 @@
+package clients
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+
+	"github.com/canonical/lxd/lxd/storage/connectors"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+const (
+	// SANBackendLVM uses thin logical volumes of an LVM thin pool as backing devices.
+	SANBackendLVM = "lvm"
+
+	// SANBackendZFS uses sparse ZFS volumes (zvols) of a dataset as backing devices.
+	SANBackendZFS = "zfs"
+)
+
+// sanConfigfsLIO is the configfs root of the kernel's LIO SCSI target.
+const sanConfigfsLIO = "/sys/kernel/config/target"
+
+// sanConfigfsNVMe is the configfs root of the kernel's NVMe target.
+const sanConfigfsNVMe = "/sys/kernel/config/nvmet"
+
+// sanSSHTimeout is the maximum time given to establish an SSH connection with the storage host.
+const sanSSHTimeout = 30 * time.Second
+
+// sanSizeAlignment is the alignment of the backing device sizes.
+// Both ZFS volumes and LVM logical volumes require their size to be a multiple of their block size.
+const sanSizeAlignment = 1024 * 1024
+
+// SANClient manages the backing devices of a storage pool on a Linux storage host and exports
+// them through the kernel's LIO (iSCSI) or nvmet (NVMe/TCP) targets.
+// Commands are executed locally if no host is set, otherwise over SSH.
+type SANClient struct {
+	logger     logger.Logger
+	host       string
+	user       string
+	privateKey string
+	hostKey    string
+	backend    string
+	source     string
+	mode       string
+}
+
+// NewSANClient creates a new instance of the SAN client.
+func NewSANClient(logger logger.Logger, host string, user string, privateKey string, hostKey string, backend string, source string, mode string) *SANClient {
+	return &SANClient{
+		logger:     logger,
+		host:       host,
+		user:       user,
+		privateKey: privateKey,
+		hostKey:    hostKey,
+		backend:    backend,
+		source:     source,
+		mode:       mode,
+	}
+}
+
+// shellQuote quotes the given string for use as a single shell word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// run executes the given shell script on the storage host and returns its standard output.
+func (c *SANClient) run(ctx context.Context, script string) (string, error) {
+	if c.host == "" {
+		return shared.RunCommand(ctx, "sh", "-c", script)
+	}
+
+	return c.runRemote(ctx, script)
+}
+
+// runRemote executes the given shell script on the storage host over SSH.
+func (c *SANClient) runRemote(ctx context.Context, script string) (string, error) {
+	signer, err := ssh.ParsePrivateKey([]byte(c.privateKey))
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing SSH private key: %w", err)
+	}
+
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(c.hostKey))
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing SSH host key: %w", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User:            c.user,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         sanSSHTimeout,
+	}
+
+	addr := shared.EnsurePort(c.host, "22")
+	dialer := net.Dialer{Timeout: sanSSHTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("Failed connecting to storage host %q: %w", addr, err)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("Failed establishing SSH connection with storage host %q: %w", addr, err)
+	}
+
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer func() { _ = client.Close() }()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return "", fmt.Errorf("Failed creating SSH session with storage host %q: %w", addr, err)
+	}
+
+	defer func() { _ = session.Close() }()
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	cmd := "sh -c " + shellQuote(script)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(cmd)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Closing the client also terminates the running session.
+		_ = client.Close()
+		return "", ctx.Err()
+	case err = <-done:
+	}
+
+	if err != nil {
+		return stdout.String(), shared.NewRunError("ssh", []string{addr, cmd}, err, &stdout, &stderr)
+	}
+
+	return stdout.String(), nil
+}
+
+// splitSource returns the volume group and thin pool names of an LVM source.
+func (c *SANClient) splitSource() (vgName string, poolName string) {
+	vgName, poolName, _ = strings.Cut(c.source, "/")
+	return vgName, poolName
+}
+
+// volumePath returns the backend path of the given volume or snapshot (if set).
+func (c *SANClient) volumePath(name string, snapshot string) string {
+	if c.backend == SANBackendZFS {
+		if snapshot != "" {
+			return c.source + "/" + name + "@" + snapshot
+		}
+
+		return c.source + "/" + name
+	}
+
+	vgName, _ := c.splitSource()
+
+	// LVM thin snapshots are regular logical volumes within the same volume group.
+	if snapshot != "" {
+		return vgName + "/" + snapshot
+	}
+
+	return vgName + "/" + name
+}
+
+// DevicePath returns the path of the block device backing the given volume or snapshot (if set)
+// on the storage host.
+func (c *SANClient) DevicePath(name string, snapshot string) string {
+	if c.backend == SANBackendZFS {
+		return "/dev/zvol/" + c.volumePath(name, snapshot)
+	}
+
+	return "/dev/" + c.volumePath(name, snapshot)
+}
+
+// alignSize rounds up the given size to the backing device size alignment.
+func alignSize(sizeBytes int64) int64 {
+	return ((sizeBytes + sanSizeAlignment - 1) / sanSizeAlignment) * sanSizeAlignment
+}
+
+// modules returns the kernel modules required on the storage host for the configured mode.
+func (c *SANClient) modules() []string {
+	if c.mode == connectors.TypeNVMeTCP {
+		return []string{"nvmet", "nvmet_tcp"}
+	}
+
+	return []string{"target_core_mod", "target_core_iblock", "iscsi_target_mod"}
+}
+
+// CheckHost ensures the storage host can be used with the configured backend and mode.
+// It loads the required kernel target modules and checks that the backing source exists.
+func (c *SANClient) CheckHost(ctx context.Context) error {
+	configfs := sanConfigfsLIO
+	if c.mode == connectors.TypeNVMeTCP {
+		configfs = sanConfigfsNVMe
+	}
+
+	script := "set -e\n"
+	for _, module := range c.modules() {
+		script += "modprobe " + module + "\n"
+	}
+
+	script += "mountpoint -q /sys/kernel/config || mount -t configfs configfs /sys/kernel/config\n"
+	script += "[ -d " + shellQuote(configfs) + " ]\n"
+
+	_, err := c.run(ctx, script)
+	if err != nil {
+		return fmt.Errorf("Failed preparing kernel target on storage host: %w", err)
+	}
+
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs list -H -o name "+shellQuote(c.source))
+	} else {
+		_, err = c.run(ctx, "lvs --noheadings -o lv_name "+shellQuote(c.source))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed checking backing source %q: %w", c.source, err)
+	}
+
+	return nil
+}
+
+// GetSpace returns the used and total space of the backing source.
+func (c *SANClient) GetSpace(ctx context.Context) (used uint64, total uint64, err error) {
+	if c.backend == SANBackendZFS {
+		out, err := c.run(ctx, "zfs get -Hp -o value used,available "+shellQuote(c.source))
+		if err != nil {
+			return 0, 0, err
+		}
+
+		fields := strings.Fields(out)
+		if len(fields) != 2 {
+			return 0, 0, fmt.Errorf("Unexpected output from zfs get: %q", out)
+		}
+
+		used, err = strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		available, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		return used, used + available, nil
+	}
+
+	out, err := c.run(ctx, "lvs --noheadings --units b --nosuffix --separator , -o lv_size,data_percent "+shellQuote(c.source))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("Unexpected output from lvs: %q", out)
+	}
+
+	total, err = strconv.ParseUint(strings.TrimSpace(fields[0]), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return uint64(float64(total) * percent / 100), total, nil
+}
+
+// CreateVolume creates a new thinly provisioned backing device.
+func (c *SANClient) CreateVolume(ctx context.Context, name string, sizeBytes int64) error {
+	size := strconv.FormatInt(alignSize(sizeBytes), 10)
+	path := c.volumePath(name, "")
+
+	var err error
+	if c.backend == SANBackendZFS {
+		// Expose the snapshots as devices so that they can be exported too.
+		_, err = c.run(ctx, "zfs create -s -V "+size+" -o volmode=dev -o snapdev=visible "+shellQuote(path))
+	} else {
+		vgName, poolName := c.splitSource()
+		_, err = c.run(ctx, "lvcreate -y -V "+size+"b -T "+shellQuote(vgName+"/"+poolName)+" -n "+shellQuote(name))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed creating volume %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// DeleteVolume deletes a backing device together with all its snapshots.
+func (c *SANClient) DeleteVolume(ctx context.Context, name string) error {
+	if c.backend == SANBackendLVM {
+		// Thin snapshots don't depend on their origin, so remove them explicitly.
+		snapshots, err := c.GetVolumeSnapshots(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		for _, snapshot := range snapshots {
+			err = c.DeleteVolumeSnapshot(ctx, name, snapshot)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var err error
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs destroy -r "+shellQuote(c.volumePath(name, "")))
+	} else {
+		_, err = c.run(ctx, "lvremove -y "+shellQuote(c.volumePath(name, "")))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed deleting volume %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// VolumeExists returns whether the given volume or snapshot (if set) exists.
+func (c *SANClient) VolumeExists(ctx context.Context, name string, snapshot string) (bool, error) {
+	var cmd string
+	if c.backend == SANBackendZFS {
+		cmd = "zfs list -H -o name " + shellQuote(c.volumePath(name, snapshot))
+	} else {
+		cmd = "lvs --noheadings -o lv_name " + shellQuote(c.volumePath(name, snapshot))
+	}
+
+	out, err := c.run(ctx, "if "+cmd+" >/dev/null 2>&1; then echo yes; else echo no; fi")
+	if err != nil {
+		return false, err
+	}
+
+	return strings.TrimSpace(out) == "yes", nil
+}
+
+// GetVolumeSize returns the size of the given backing device in bytes.
+func (c *SANClient) GetVolumeSize(ctx context.Context, name string) (int64, error) {
+	var out string
+	var err error
+	if c.backend == SANBackendZFS {
+		out, err = c.run(ctx, "zfs get -Hp -o value volsize "+shellQuote(c.volumePath(name, "")))
+	} else {
+		out, err = c.run(ctx, "lvs --noheadings --units b --nosuffix -o lv_size "+shellQuote(c.volumePath(name, "")))
+	}
+
+	if err != nil {
+		return -1, fmt.Errorf("Failed getting size of volume %q: %w", name, err)
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+}
+
+// GetVolumeUsage returns the space allocated by the given backing device in bytes.
+func (c *SANClient) GetVolumeUsage(ctx context.Context, name string) (int64, error) {
+	if c.backend == SANBackendZFS {
+		out, err := c.run(ctx, "zfs get -Hp -o value referenced "+shellQuote(c.volumePath(name, "")))
+		if err != nil {
+			return -1, fmt.Errorf("Failed getting usage of volume %q: %w", name, err)
+		}
+
+		return strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	}
+
+	out, err := c.run(ctx, "lvs --noheadings --units b --nosuffix --separator , -o lv_size,data_percent "+shellQuote(c.volumePath(name, "")))
+	if err != nil {
+		return -1, fmt.Errorf("Failed getting usage of volume %q: %w", name, err)
+	}
+
+	fields := strings.Split(strings.TrimSpace(out), ",")
+	if len(fields) != 2 {
+		return -1, fmt.Errorf("Unexpected output from lvs: %q", out)
+	}
+
+	size, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+	if err != nil {
+		return -1, err
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+	if err != nil {
+		return -1, err
+	}
+
+	return int64(float64(size) * percent / 100), nil
+}
+
+// ResizeVolume changes the size of the given backing device.
+func (c *SANClient) ResizeVolume(ctx context.Context, name string, sizeBytes int64) error {
+	size := strconv.FormatInt(alignSize(sizeBytes), 10)
+
+	var err error
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs set volsize="+size+" "+shellQuote(c.volumePath(name, "")))
+	} else {
+		_, err = c.run(ctx, "lvresize -y -f -L "+size+"b "+shellQuote(c.volumePath(name, "")))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed resizing volume %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// CreateVolumeSnapshot creates a read-only snapshot of the given backing device.
+func (c *SANClient) CreateVolumeSnapshot(ctx context.Context, name string, snapshot string) error {
+	var err error
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs snapshot "+shellQuote(c.volumePath(name, snapshot)))
+	} else {
+		// Thin snapshots skip activation by default, which would leave them without a device node.
+		_, err = c.run(ctx, "lvcreate -y -s -kn -pr -n "+shellQuote(snapshot)+" "+shellQuote(c.volumePath(name, "")))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed creating snapshot %q of volume %q: %w", snapshot, name, err)
+	}
+
+	return nil
+}
+
+// DeleteVolumeSnapshot deletes a snapshot of the given backing device.
+func (c *SANClient) DeleteVolumeSnapshot(ctx context.Context, name string, snapshot string) error {
+	var err error
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs destroy "+shellQuote(c.volumePath(name, snapshot)))
+	} else {
+		_, err = c.run(ctx, "lvremove -y "+shellQuote(c.volumePath(name, snapshot)))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed deleting snapshot %q of volume %q: %w", snapshot, name, err)
+	}
+
+	return nil
+}
+
+// GetVolumeSnapshots returns the names of the snapshots of the given backing device.
+func (c *SANClient) GetVolumeSnapshots(ctx context.Context, name string) ([]string, error) {
+	snapshots := []string{}
+
+	if c.backend == SANBackendZFS {
+		path := c.volumePath(name, "")
+		out, err := c.run(ctx, "zfs list -H -o name -t snapshot "+shellQuote(path))
+		if err != nil {
+			return nil, fmt.Errorf("Failed listing snapshots of volume %q: %w", name, err)
+		}
+
+		for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+			snapshot, ok := strings.CutPrefix(line, path+"@")
+			if ok {
+				snapshots = append(snapshots, snapshot)
+			}
+		}
+
+		return snapshots, nil
+	}
+
+	vgName, _ := c.splitSource()
+	out, err := c.run(ctx, "lvs --noheadings --separator , -o lv_name,origin "+shellQuote(vgName))
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing snapshots of volume %q: %w", name, err)
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(out), "\n") {
+		lvName, origin, ok := strings.Cut(strings.TrimSpace(line), ",")
+		if ok && origin == name {
+			snapshots = append(snapshots, lvName)
+		}
+	}
+
+	return snapshots, nil
+}
+
+// CloneVolumeSnapshot creates a new writable backing device from a snapshot of the given backing device.
+func (c *SANClient) CloneVolumeSnapshot(ctx context.Context, name string, snapshot string, cloneName string) error {
+	var err error
+	if c.backend == SANBackendZFS {
+		_, err = c.run(ctx, "zfs clone -o volmode=dev "+shellQuote(c.volumePath(name, snapshot))+" "+shellQuote(c.volumePath(cloneName, "")))
+	} else {
+		_, err = c.run(ctx, "lvcreate -y -s -kn -n "+shellQuote(cloneName)+" "+shellQuote(c.volumePath(name, snapshot)))
+	}
+
+	if err != nil {
+		return fmt.Errorf("Failed cloning snapshot %q of volume %q: %w", snapshot, name, err)
+	}
+
+	return nil
+}
+
+// CopyVolume overwrites the content of the destination backing device with the content of the
+// source volume or snapshot (if set). The destination must not be smaller than the source.
+// The destination is discarded first so that zero blocks of the source remain unallocated.
+func (c *SANClient) CopyVolume(ctx context.Context, srcName string, srcSnapshot string, dstName string) error {
+	srcPath := shellQuote(c.DevicePath(srcName, srcSnapshot))
+	dstPath := shellQuote(c.DevicePath(dstName, ""))
+
+	script := "set -e\nblkdiscard " + dstPath + "\ndd if=" + srcPath + " of=" + dstPath + " bs=4M conv=sparse,fsync status=none\n"
+
+	_, err := c.run(ctx, script)
+	if err != nil {
+		return fmt.Errorf("Failed copying volume %q into %q: %w", srcName, dstName, err)
+	}
+
+	return nil
+}
+
+// splitAddress splits the given target address into its host and port part.
+func (c *SANClient) splitAddress(addr string) (host string, port string, err error) {
+	defaultPort := connectors.ISCSIDefaultPort
+	if c.mode == connectors.TypeNVMeTCP {
+		defaultPort = connectors.NVMeDefaultTransportPort
+	}
+
+	host, port, err = net.SplitHostPort(shared.EnsurePort(addr, defaultPort))
+	if err != nil {
+		return "", "", fmt.Errorf("Invalid target address %q: %w", addr, err)
+	}
+
+	return host, port, nil
+}
+
+// ExportVolume exports the given volume under its own target and grants the initiator access to it.
+// The serial is a hexadecimal string that uniquely identifies the device.
+// Exporting an already exported volume only grants access to the given initiator.
+// The returned bool indicates whether the initiator was newly granted access.
+func (c *SANClient) ExportVolume(ctx context.Context, targetQN string, name string, serial string, initiatorQN string, addresses []string) (bool, error) {
+	if len(addresses) == 0 {
+		return false, errors.New("No target addresses configured")
+	}
+
+	var script string
+	var err error
+	if c.mode == connectors.TypeNVMeTCP {
+		script, err = c.nvmeExportScript(targetQN, c.DevicePath(name, ""), serial, initiatorQN, addresses)
+	} else {
+		script, err = c.iscsiExportScript(targetQN, name, c.DevicePath(name, ""), serial, initiatorQN, addresses)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	out, err := c.run(ctx, script)
+	if err != nil {
+		return false, fmt.Errorf("Failed exporting volume %q: %w", name, err)
+	}
+
+	return strings.TrimSpace(out) == "granted", nil
+}
+
+// iscsiExportScript returns the script creating the LIO backstore, target and ACL of a volume.
+func (c *SANClient) iscsiExportScript(targetQN string, name string, devPath string, serial string, initiatorQN string, addresses []string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("set -e\n")
+	sb.WriteString("B=" + shellQuote(sanConfigfsLIO+"/core/iblock_0/"+name) + "\n")
+	sb.WriteString("T=" + shellQuote(sanConfigfsLIO+"/iscsi/"+targetQN+"/tpgt_1") + "\n")
+	sb.WriteString("A=\"$T/acls/\"" + shellQuote(initiatorQN) + "\n")
+
+	// The backstore is bound to the backing device and identified by its unit serial,
+	// from which LIO derives the device's NAA WWN.
+	sb.WriteString("if [ ! -d \"$B\" ]; then\n")
+	sb.WriteString("  mkdir -p \"$B\"\n")
+	sb.WriteString("  echo " + shellQuote("udev_path="+devPath) + " > \"$B/control\"\n")
+	sb.WriteString("  echo " + shellQuote(serial) + " > \"$B/wwn/vpd_unit_serial\"\n")
+	sb.WriteString("  echo 1 > \"$B/enable\"\n")
+	sb.WriteString("fi\n")
+
+	// The target exposes the backstore as its only LUN.
+	// CHAP isn't supported, access is only restricted to the initiators that have an ACL on the target.
+	sb.WriteString("if [ ! -d \"$T/lun/lun_0\" ]; then\n")
+	sb.WriteString("  mkdir -p \"$T/lun/lun_0\"\n")
+	sb.WriteString("  ln -s \"$B\" \"$T/lun/lun_0/lxd\"\n")
+	sb.WriteString("  echo 0 > \"$T/attrib/authentication\"\n")
+	sb.WriteString("  echo 0 > \"$T/attrib/generate_node_acls\"\n")
+	sb.WriteString("fi\n")
+
+	for _, addr := range addresses {
+		host, port, err := c.splitAddress(addr)
+		if err != nil {
+			return "", err
+		}
+
+		portal := net.JoinHostPort(host, port)
+		sb.WriteString("[ -d \"$T/np/\"" + shellQuote(portal) + " ] || mkdir \"$T/np/\"" + shellQuote(portal) + "\n")
+	}
+
+	sb.WriteString("echo 1 > \"$T/enable\"\n")
+
+	// Grant the initiator access to the LUN.
+	sb.WriteString("if [ ! -d \"$A/lun_0\" ]; then\n")
+	sb.WriteString("  mkdir -p \"$A/lun_0\"\n")
+	sb.WriteString("  ln -s \"$T/lun/lun_0\" \"$A/lun_0/lun_0\"\n")
+	sb.WriteString("  echo granted\n")
+	sb.WriteString("fi\n")
+
+	return sb.String(), nil
+}
+
+// nvmeExportScript returns the script creating the nvmet subsystem, namespace and port links of a volume.
+func (c *SANClient) nvmeExportScript(targetQN string, devPath string, serial string, initiatorQN string, addresses []string) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("set -e\n")
+	sb.WriteString("N=" + shellQuote(sanConfigfsNVMe) + "\n")
+	sb.WriteString("S=\"$N/subsystems/\"" + shellQuote(targetQN) + "\n")
+	sb.WriteString("H=\"$N/hosts/\"" + shellQuote(initiatorQN) + "\n")
+
+	// The subsystem exposes the backing device as its only namespace, identified by its NGUID.
+	sb.WriteString("if [ ! -d \"$S\" ]; then\n")
+	sb.WriteString("  mkdir \"$S\"\n")
+	sb.WriteString("  echo 0 > \"$S/attr_allow_any_host\"\n")
+	sb.WriteString("  mkdir \"$S/namespaces/1\"\n")
+	sb.WriteString("  echo -n " + shellQuote(devPath) + " > \"$S/namespaces/1/device_path\"\n")
+	sb.WriteString("  echo " + shellQuote(serial) + " > \"$S/namespaces/1/device_nguid\"\n")
+	sb.WriteString("  echo 1 > \"$S/namespaces/1/enable\"\n")
+	sb.WriteString("fi\n")
+
+	// Ports are shared between all subsystems, so reuse an existing port listening on the address.
+	for _, addr := range addresses {
+		host, port, err := c.splitAddress(addr)
+		if err != nil {
+			return "", err
+		}
+
+		adrfam := "ipv4"
+		if net.ParseIP(host).To4() == nil {
+			adrfam = "ipv6"
+		}
+
+		sb.WriteString("P=\n")
+		sb.WriteString("for p in \"$N\"/ports/*; do\n")
+		sb.WriteString("  [ -d \"$p\" ] || continue\n")
+		sb.WriteString("  if [ \"$(cat \"$p/addr_trtype\")\" = tcp ] && [ \"$(cat \"$p/addr_traddr\")\" = " + shellQuote(host) + " ] && [ \"$(cat \"$p/addr_trsvcid\")\" = " + shellQuote(port) + " ]; then P=\"$p\"; break; fi\n")
+		sb.WriteString("done\n")
+		sb.WriteString("if [ -z \"$P\" ]; then\n")
+		sb.WriteString("  i=1; while [ -e \"$N/ports/$i\" ]; do i=$((i+1)); done\n")
+		sb.WriteString("  P=\"$N/ports/$i\"\n")
+		sb.WriteString("  mkdir \"$P\"\n")
+		sb.WriteString("  echo tcp > \"$P/addr_trtype\"\n")
+		sb.WriteString("  echo " + adrfam + " > \"$P/addr_adrfam\"\n")
+		sb.WriteString("  echo " + shellQuote(host) + " > \"$P/addr_traddr\"\n")
+		sb.WriteString("  echo " + shellQuote(port) + " > \"$P/addr_trsvcid\"\n")
+		sb.WriteString("fi\n")
+		sb.WriteString("[ -e \"$P/subsystems/\"" + shellQuote(targetQN) + " ] || ln -s \"$S\" \"$P/subsystems/\"" + shellQuote(targetQN) + "\n")
+	}
+
+	// Grant the initiator access to the subsystem.
+	sb.WriteString("[ -d \"$H\" ] || mkdir \"$H\"\n")
+	sb.WriteString("if [ ! -e \"$S/allowed_hosts/\"" + shellQuote(initiatorQN) + " ]; then\n")
+	sb.WriteString("  ln -s \"$H\" \"$S/allowed_hosts/\"" + shellQuote(initiatorQN) + "\n")
+	sb.WriteString("  echo granted\n")
+	sb.WriteString("fi\n")
+
+	return sb.String(), nil
+}
+
+// UnexportVolume revokes the initiator's access to the given volume's target.
+// Once no initiator has access anymore, the target is removed entirely, which is indicated by the returned bool.
+func (c *SANClient) UnexportVolume(ctx context.Context, targetQN string, initiatorQN string) (bool, error) {
+	out, err := c.run(ctx, c.unexportScript(targetQN, initiatorQN))
+	if err != nil {
+		return false, fmt.Errorf("Failed revoking access to target %q: %w", targetQN, err)
+	}
+
+	if strings.TrimSpace(out) == "used" {
+		return false, nil
+	}
+
+	err = c.DeleteExport(ctx, targetQN)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// unexportScript returns the script revoking the initiator's access to a target, which prints whether any
+// initiator still has access to it.
+func (c *SANClient) unexportScript(targetQN string, initiatorQN string) string {
+	var script string
+	if c.mode == connectors.TypeNVMeTCP {
+		script = "S=" + shellQuote(sanConfigfsNVMe+"/subsystems/"+targetQN) + "\n"
+		script += "rm -f \"$S/allowed_hosts/\"" + shellQuote(initiatorQN) + "\n"
+		script += "ls \"$S/allowed_hosts\" 2>/dev/null | grep -q . && echo used || echo unused\n"
+	} else {
+		script = "A=" + shellQuote(sanConfigfsLIO+"/iscsi/"+targetQN+"/tpgt_1/acls/"+initiatorQN) + "\n"
+		script += "if [ -d \"$A\" ]; then rm -f \"$A/lun_0/lun_0\"; rmdir \"$A/lun_0\" \"$A\"; fi\n"
+		script += "ls " + shellQuote(sanConfigfsLIO+"/iscsi/"+targetQN+"/tpgt_1/acls") + " 2>/dev/null | grep -q . && echo used || echo unused\n"
+	}
+
+	return script
+}
+
+// DeleteExport removes the target of a volume together with all its access rules.
+// Removing a target that doesn't exist is not an error.
+func (c *SANClient) DeleteExport(ctx context.Context, targetQN string) error {
+	_, err := c.run(ctx, c.deleteExportScript(targetQN))
+	if err != nil {
+		return fmt.Errorf("Failed removing target %q: %w", targetQN, err)
+	}
+
+	return nil
+}
+
+// deleteExportScript returns the script removing a target together with its backstore and access rules.
+func (c *SANClient) deleteExportScript(targetQN string) string {
+	var script string
+	if c.mode == connectors.TypeNVMeTCP {
+		script = "N=" + shellQuote(sanConfigfsNVMe) + "\n"
+		script += "S=\"$N/subsystems/\"" + shellQuote(targetQN) + "\n"
+		script += "[ -d \"$S\" ] || exit 0\n"
+		script += "set -e\n"
+		script += "for l in \"$N\"/ports/*/subsystems/" + shellQuote(targetQN) + "; do if [ -L \"$l\" ]; then rm \"$l\"; fi; done\n"
+		script += "for l in \"$S\"/allowed_hosts/*; do if [ -L \"$l\" ]; then rm \"$l\"; fi; done\n"
+		script += "if [ -d \"$S/namespaces/1\" ]; then echo 0 > \"$S/namespaces/1/enable\"; rmdir \"$S/namespaces/1\"; fi\n"
+		script += "rmdir \"$S\"\n"
+	} else {
+		script = "T=" + shellQuote(sanConfigfsLIO+"/iscsi/"+targetQN) + "\n"
+		script += "[ -d \"$T\" ] || exit 0\n"
+		script += "set -e\n"
+		script += "G=\"$T/tpgt_1\"\n"
+		script += "B=$(readlink -f \"$G\"/lun/lun_0/lxd || true)\n"
+		script += "echo 0 > \"$G/enable\"\n"
+		script += "for a in \"$G\"/acls/*; do [ -d \"$a\" ] || continue; rm -f \"$a/lun_0/lun_0\"; rmdir \"$a/lun_0\" \"$a\"; done\n"
+		script += "if [ -d \"$G/lun/lun_0\" ]; then rm -f \"$G/lun/lun_0/lxd\"; rmdir \"$G/lun/lun_0\"; fi\n"
+		script += "for np in \"$G\"/np/*; do if [ -d \"$np\" ]; then rmdir \"$np\"; fi; done\n"
+		script += "rmdir \"$G\" \"$T\"\n"
+		script += "if [ -n \"$B\" ] && [ -d \"$B\" ]; then rmdir \"$B\"; fi\n"
+	}
+
+	return script
+}
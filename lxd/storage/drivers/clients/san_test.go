@@ -0,0 +1,185 @@
+package clients
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/lxd/storage/connectors"
+)
+
+// assertShellSyntax checks that the given script is syntactically valid shell.
+func assertShellSyntax(t *testing.T, script string) {
+	t.Helper()
+
+	out, err := exec.Command("sh", "-n", "-c", script).CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func Test_shellQuote(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "Plain word is enclosed in single quotes",
+			value: "tank/lxd",
+			want:  "'tank/lxd'",
+		},
+		{
+			name:  "Shell metacharacters are not interpreted",
+			value: "$(reboot); `id`",
+			want:  "'$(reboot); `id`'",
+		},
+		{
+			name:  "Single quotes are escaped",
+			value: "it's",
+			want:  `'it'\''s'`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, shellQuote(tt.value))
+		})
+	}
+}
+
+func TestSANClient_DevicePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		backend  string
+		source   string
+		volume   string
+		snapshot string
+		want     string
+	}{
+		{
+			name:    "ZFS volume",
+			backend: SANBackendZFS,
+			source:  "tank/lxd",
+			volume:  "c-5a2504b06a6c48498ee7ddb0b674fd14",
+			want:    "/dev/zvol/tank/lxd/c-5a2504b06a6c48498ee7ddb0b674fd14",
+		},
+		{
+			name:     "ZFS snapshot",
+			backend:  SANBackendZFS,
+			source:   "tank/lxd",
+			volume:   "c-5a2504b06a6c48498ee7ddb0b674fd14",
+			snapshot: "sc-6b3615c17b7d59509ff8eec1c785ae25",
+			want:     "/dev/zvol/tank/lxd/c-5a2504b06a6c48498ee7ddb0b674fd14@sc-6b3615c17b7d59509ff8eec1c785ae25",
+		},
+		{
+			name:    "LVM volume",
+			backend: SANBackendLVM,
+			source:  "vg0/thinpool",
+			volume:  "c-5a2504b06a6c48498ee7ddb0b674fd14",
+			want:    "/dev/vg0/c-5a2504b06a6c48498ee7ddb0b674fd14",
+		},
+		{
+			name:     "LVM snapshot",
+			backend:  SANBackendLVM,
+			source:   "vg0/thinpool",
+			volume:   "c-5a2504b06a6c48498ee7ddb0b674fd14",
+			snapshot: "sc-6b3615c17b7d59509ff8eec1c785ae25",
+			want:     "/dev/vg0/sc-6b3615c17b7d59509ff8eec1c785ae25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewSANClient(nil, "", "", "", "", tt.backend, tt.source, "")
+			assert.Equal(t, tt.want, c.DevicePath(tt.volume, tt.snapshot))
+		})
+	}
+}
+
+func TestSANClient_iscsiExportScript(t *testing.T) {
+	c := NewSANClient(nil, "", "", "", "", SANBackendZFS, "tank/lxd", connectors.TypeISCSI)
+
+	script, err := c.iscsiExportScript("iqn.2024-01.com.canonical.lxd:vol1", "vol1", "/dev/zvol/tank/lxd/vol1", "0123456789abcdef", "iqn.2024-01.com.example:host1", []string{"192.0.2.1", "[2001:db8::1]:3261"})
+	require.NoError(t, err)
+	assertShellSyntax(t, script)
+
+	// Backstore bound to the device with its serial.
+	assert.Contains(t, script, "B='/sys/kernel/config/target/core/iblock_0/vol1'\n")
+	assert.Contains(t, script, "echo 'udev_path=/dev/zvol/tank/lxd/vol1' > \"$B/control\"\n")
+	assert.Contains(t, script, "echo '0123456789abcdef' > \"$B/wwn/vpd_unit_serial\"\n")
+
+	// Target with a single LUN, no CHAP and no generated ACLs.
+	assert.Contains(t, script, "T='/sys/kernel/config/target/iscsi/iqn.2024-01.com.canonical.lxd:vol1/tpgt_1'\n")
+	assert.Contains(t, script, "ln -s \"$B\" \"$T/lun/lun_0/lxd\"\n")
+	assert.Contains(t, script, "echo 0 > \"$T/attrib/authentication\"\n")
+	assert.Contains(t, script, "echo 0 > \"$T/attrib/generate_node_acls\"\n")
+
+	// Portals use the default port unless one is given.
+	assert.Contains(t, script, "mkdir \"$T/np/\"'192.0.2.1:3260'\n")
+	assert.Contains(t, script, "mkdir \"$T/np/\"'[2001:db8::1]:3261'\n")
+
+	// ACL for the initiator only.
+	assert.Contains(t, script, "A=\"$T/acls/\"'iqn.2024-01.com.example:host1'\n")
+	assert.Contains(t, script, "ln -s \"$T/lun/lun_0\" \"$A/lun_0/lun_0\"\n  echo granted\n")
+
+	_, err = c.iscsiExportScript("iqn.2024-01.com.canonical.lxd:vol1", "vol1", "/dev/zvol/tank/lxd/vol1", "0123456789abcdef", "iqn.2024-01.com.example:host1", []string{"[2001:db8::1"})
+	assert.Error(t, err)
+}
+
+func TestSANClient_nvmeExportScript(t *testing.T) {
+	c := NewSANClient(nil, "", "", "", "", SANBackendLVM, "vg0/thinpool", connectors.TypeNVMeTCP)
+
+	script, err := c.nvmeExportScript("nqn.2024-01.com.canonical.lxd:vol1", "/dev/vg0/vol1", "0123456789abcdef0123456789abcdef", "nqn.2014-08.org.nvmexpress:uuid:host1", []string{"192.0.2.1", "[2001:db8::1]:4421"})
+	require.NoError(t, err)
+	assertShellSyntax(t, script)
+
+	// Subsystem with the device as its only namespace, restricted to allowed hosts.
+	assert.Contains(t, script, "S=\"$N/subsystems/\"'nqn.2024-01.com.canonical.lxd:vol1'\n")
+	assert.Contains(t, script, "echo 0 > \"$S/attr_allow_any_host\"\n")
+	assert.Contains(t, script, "echo -n '/dev/vg0/vol1' > \"$S/namespaces/1/device_path\"\n")
+	assert.Contains(t, script, "echo '0123456789abcdef0123456789abcdef' > \"$S/namespaces/1/device_nguid\"\n")
+
+	// Ports are looked up by address before being created, using the default port unless one is given.
+	assert.Contains(t, script, "[ \"$(cat \"$p/addr_traddr\")\" = '192.0.2.1' ] && [ \"$(cat \"$p/addr_trsvcid\")\" = '4420' ]")
+	assert.Contains(t, script, "echo ipv4 > \"$P/addr_adrfam\"\n")
+	assert.Contains(t, script, "echo '2001:db8::1' > \"$P/addr_traddr\"\n")
+	assert.Contains(t, script, "echo '4421' > \"$P/addr_trsvcid\"\n")
+	assert.Contains(t, script, "echo ipv6 > \"$P/addr_adrfam\"\n")
+	assert.Contains(t, script, "ln -s \"$S\" \"$P/subsystems/\"'nqn.2024-01.com.canonical.lxd:vol1'\n")
+
+	// Access for the initiator only.
+	assert.Contains(t, script, "H=\"$N/hosts/\"'nqn.2014-08.org.nvmexpress:uuid:host1'\n")
+	assert.Contains(t, script, "ln -s \"$H\" \"$S/allowed_hosts/\"'nqn.2014-08.org.nvmexpress:uuid:host1'\n  echo granted\n")
+
+	_, err = c.nvmeExportScript("nqn.2024-01.com.canonical.lxd:vol1", "/dev/vg0/vol1", "0123456789abcdef0123456789abcdef", "nqn.2014-08.org.nvmexpress:uuid:host1", []string{"[2001:db8::1"})
+	assert.Error(t, err)
+}
+
+func TestSANClient_unexportScript(t *testing.T) {
+	iscsi := NewSANClient(nil, "", "", "", "", SANBackendZFS, "tank/lxd", connectors.TypeISCSI).unexportScript("iqn.2024-01.com.canonical.lxd:vol1", "iqn.2024-01.com.example:host1")
+	assertShellSyntax(t, iscsi)
+	assert.Contains(t, iscsi, "A='/sys/kernel/config/target/iscsi/iqn.2024-01.com.canonical.lxd:vol1/tpgt_1/acls/iqn.2024-01.com.example:host1'\n")
+	assert.Contains(t, iscsi, "ls '/sys/kernel/config/target/iscsi/iqn.2024-01.com.canonical.lxd:vol1/tpgt_1/acls' 2>/dev/null | grep -q . && echo used || echo unused\n")
+
+	nvme := NewSANClient(nil, "", "", "", "", SANBackendZFS, "tank/lxd", connectors.TypeNVMeTCP).unexportScript("nqn.2024-01.com.canonical.lxd:vol1", "nqn.2014-08.org.nvmexpress:uuid:host1")
+	assertShellSyntax(t, nvme)
+	assert.Contains(t, nvme, "S='/sys/kernel/config/nvmet/subsystems/nqn.2024-01.com.canonical.lxd:vol1'\n")
+	assert.Contains(t, nvme, "rm -f \"$S/allowed_hosts/\"'nqn.2014-08.org.nvmexpress:uuid:host1'\n")
+}
+
+func TestSANClient_deleteExportScript(t *testing.T) {
+	iscsi := NewSANClient(nil, "", "", "", "", SANBackendZFS, "tank/lxd", connectors.TypeISCSI).deleteExportScript("iqn.2024-01.com.canonical.lxd:vol1")
+	assertShellSyntax(t, iscsi)
+	assert.Contains(t, iscsi, "T='/sys/kernel/config/target/iscsi/iqn.2024-01.com.canonical.lxd:vol1'\n[ -d \"$T\" ] || exit 0\n")
+	assert.Contains(t, iscsi, "echo 0 > \"$G/enable\"\n")
+	assert.Contains(t, iscsi, "rmdir \"$G\" \"$T\"\n")
+	assert.Contains(t, iscsi, "rmdir \"$B\"")
+
+	nvme := NewSANClient(nil, "", "", "", "", SANBackendZFS, "tank/lxd", connectors.TypeNVMeTCP).deleteExportScript("nqn.2024-01.com.canonical.lxd:vol1")
+	assertShellSyntax(t, nvme)
+	assert.Contains(t, nvme, "S=\"$N/subsystems/\"'nqn.2024-01.com.canonical.lxd:vol1'\n[ -d \"$S\" ] || exit 0\n")
+	assert.Contains(t, nvme, "for l in \"$N\"/ports/*/subsystems/'nqn.2024-01.com.canonical.lxd:vol1'; do")
+	assert.Contains(t, nvme, "echo 0 > \"$S/namespaces/1/enable\"")
+	assert.Contains(t, nvme, "rmdir \"$S\"\n")
+}
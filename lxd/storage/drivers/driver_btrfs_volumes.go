@@ -616,6 +616,23 @@ func (d *btrfs) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteClose
 			localSubvolumes[snap] = receivedUUID
 		}
 
+		// When only refreshing from the last common snapshot, skip all the source snapshots up to it.
+		var olderSnapshots []string
+		if volTargetArgs.RefreshExcludeOlder {
+			for i := len(migrationHeader.Subvolumes) - 1; i >= 0; i-- {
+				migrationSnap := migrationHeader.Subvolumes[i]
+				if migrationSnap.Path != "/" || migrationSnap.Snapshot == "" {
+					continue
+				}
+
+				if olderSnapshots != nil {
+					olderSnapshots = append(olderSnapshots, migrationSnap.Snapshot)
+				} else if receivedUUID, ok := localSubvolumes[migrationSnap.Snapshot]; ok && receivedUUID == migrationSnap.UUID {
+					olderSnapshots = []string{migrationSnap.Snapshot}
+				}
+			}
+		}
+
 		// Figure out which snapshots need to be copied by comparing the UUIDs and received UUIDs from the migration header.
 		for _, migrationSnap := range migrationHeader.Subvolumes {
 			receivedUUID, ok := localSubvolumes[migrationSnap.Snapshot]
@@ -624,6 +641,11 @@ func (d *btrfs) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteClose
 				continue
 			}
 
+			// Skip this snapshot as it predates the last snapshot in common with the source.
+			if migrationSnap.Snapshot != "" && slices.Contains(olderSnapshots, migrationSnap.Snapshot) {
+				continue
+			}
+
 			if migrationSnap.Path == "/" && migrationSnap.Snapshot != "" {
 				volTargetArgs.Snapshots = append(volTargetArgs.Snapshots, migrationSnap.Snapshot)
 			}
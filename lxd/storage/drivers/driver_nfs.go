@@ -0,0 +1,265 @@
+package drivers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+var nfsVersion string
+var nfsLoaded bool
+
+// nfs stores its volumes like the dir driver within a mounted NFS export.
+type nfs struct {
+	dir
+}
+
+// load is used to run one-time action per-driver rather than per-pool.
+func (d *nfs) load() error {
+	// Register the patches.
+	d.patches = map[string]func() error{
+		"storage_lvm_skipactivation":                         nil,
+		"storage_missing_snapshot_records":                   nil,
+		"storage_delete_old_snapshot_records":                nil,
+		"storage_zfs_drop_block_volume_filesystem_extension": nil,
+		"storage_prefix_bucket_names_with_project":           nil,
+		"storage_zfs_remove_local_bucket_datasets":           nil,
+	}
+
+	// Done if previously loaded.
+	if nfsLoaded {
+		return nil
+	}
+
+	// Validate the required binaries.
+	_, err := exec.LookPath("mount.nfs")
+	if err != nil {
+		return errors.New(`Required tool "mount.nfs" is missing`)
+	}
+
+	// Detect and record the version.
+	if nfsVersion == "" {
+		out, err := shared.RunCommand(context.TODO(), "mount.nfs", "-V")
+		if err != nil {
+			return err
+		}
+
+		// Output looks like "mount.nfs: (linux nfs-utils 2.6.4)".
+		fields := strings.Fields(strings.TrimSpace(out))
+		if len(fields) == 0 {
+			return errors.New("Failed parsing mount.nfs version")
+		}
+
+		nfsVersion = strings.TrimSuffix(fields[len(fields)-1], ")")
+	}
+
+	nfsLoaded = true
+	return nil
+}
+
+// isRemote returns true indicating this driver uses remote storage.
+func (d *nfs) isRemote() bool {
+	return true
+}
+
+// Info returns info about the driver and its environment.
+func (d *nfs) Info() Info {
+	return Info{
+		Name:                         "nfs",
+		Version:                      nfsVersion,
+		DefaultBlockSize:             d.defaultBlockVolumeSize(),
+		DefaultVMBlockFilesystemSize: d.defaultVMBlockFilesystemSize(),
+		OptimizedImages:              false,
+		PreservesInodes:              false,
+		Remote:                       d.isRemote(),
+		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeMultiNode:              true,
+		BlockBacking:                 false,
+		RunningCopyFreeze:            true,
+		DirectIO:                     true,
+		IOUring:                      false,
+		MountedRoot:                  true,
+		PopulateParentVolumeUUID:     false,
+	}
+}
+
+// FillConfig populates the storage pool's configuration file with the default values.
+func (d *nfs) FillConfig() error {
+	return nil
+}
+
+// SourceIdentifier returns a combined string consisting of the NFS server and export path.
+func (d *nfs) SourceIdentifier() (string, error) {
+	host := d.config["nfs.host"]
+	if host == "" {
+		return "", errors.New("Cannot derive identifier from empty host")
+	}
+
+	path := d.config["nfs.path"]
+	if path == "" {
+		return "", errors.New("Cannot derive identifier from empty path")
+	}
+
+	return host + ":" + path, nil
+}
+
+// ValidateSource checks whether the required config keys are set to access the remote source.
+func (d *nfs) ValidateSource() error {
+	if d.config["nfs.host"] == "" {
+		return errors.New("Missing required NFS server host")
+	}
+
+	if d.config["nfs.path"] == "" {
+		return errors.New("Missing required NFS export path")
+	}
+
+	return nil
+}
+
+// Create is called during pool creation and is effectively using an empty driver struct.
+// WARNING: The Create() function cannot rely on any of the struct attributes being set.
+func (d *nfs) Create() error {
+	// Create a temporary mountpoint.
+	mountPath, err := os.MkdirTemp("", "lxd_nfs_")
+	if err != nil {
+		return fmt.Errorf("Failed creating temporary directory under: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(mountPath) }()
+
+	err = os.Chmod(mountPath, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed chmoding %q: %w", mountPath, err)
+	}
+
+	mountPoint := filepath.Join(mountPath, "mount")
+
+	err = os.Mkdir(mountPoint, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed creating directory %q: %w", mountPoint, err)
+	}
+
+	// Mount the export.
+	err = d.mountExport(context.TODO(), mountPoint)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _, _ = forceUnmount(mountPoint) }()
+
+	// Check that the export is empty, only allowing the "lost+found" directory of a filesystem root.
+	entries, err := os.ReadDir(mountPoint)
+	if err != nil {
+		return fmt.Errorf("Failed reading directory content of NFS export %q: %w", d.config["nfs.path"], err)
+	}
+
+	for _, e := range entries {
+		if e.Name() != "lost+found" {
+			return errors.New("Only empty NFS exports can be used as a LXD storage pool")
+		}
+	}
+
+	return nil
+}
+
+// Delete clears any local and remote data related to this driver instance.
+func (d *nfs) Delete(progressReporter ioprogress.ProgressReporter) error {
+	// Make sure the export is mounted so its content can be removed.
+	_, err := d.Mount()
+	if err != nil {
+		return err
+	}
+
+	// On delete, wipe everything in the directory.
+	err = wipeDirectory(GetPoolMountPath(d.name))
+	if err != nil {
+		return err
+	}
+
+	// Unmount the path.
+	_, err = d.Unmount()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
+func (d *nfs) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=nfs.host)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Host name or IP address of the NFS server
+		//  scope: global
+		"nfs.host": validate.IsAny,
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=nfs.path)
+		// The export must be empty when the storage pool is created.
+		// ---
+		//  type: string
+		//  shortdesc: Path of the NFS export on the server
+		//  scope: global
+		"nfs.path": validate.Required(validate.IsAbsFilePath),
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=nfs.version)
+		// If not set, the highest version supported by both the client and the server is negotiated.
+		// ---
+		//  type: string
+		//  shortdesc: NFS protocol version to use (`3`, `4`, `4.0`, `4.1` or `4.2`)
+		//  scope: global
+		"nfs.version": validate.Optional(validate.IsOneOf("3", "4", "4.0", "4.1", "4.2")),
+		// lxdmeta:generate(entities=storage-nfs; group=pool-conf; key=nfs.mount_options)
+		// Comma-separated list of additional options passed to `mount.nfs` when mounting the export.
+		// ---
+		//  type: string
+		//  shortdesc: Additional mount options for the NFS export
+		//  scope: global
+		"nfs.mount_options": validate.IsAny,
+	}
+
+	return d.validatePool(config, rules, nil)
+}
+
+// Update applies any driver changes required from a configuration change.
+func (d *nfs) Update(changedConfig map[string]string) error {
+	for _, key := range []string{"nfs.host", "nfs.path"} {
+		_, changed := changedConfig[key]
+		if changed {
+			return fmt.Errorf("Option %q cannot be changed", key)
+		}
+	}
+
+	return nil
+}
+
+// Mount mounts the storage pool.
+func (d *nfs) Mount() (bool, error) {
+	path := GetPoolMountPath(d.name)
+
+	// Check if already mounted.
+	if filesystem.IsMountPoint(path) {
+		return false, nil
+	}
+
+	err := d.mountExport(context.TODO(), path)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Unmount unmounts the storage pool.
+func (d *nfs) Unmount() (bool, error) {
+	return forceUnmount(GetPoolMountPath(d.name))
+}
@@ -0,0 +1,159 @@
+package drivers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// newTestNFS returns an nfs driver for the given pool config which only validates the "size" volume option.
+func newTestNFS(config map[string]string) *nfs {
+	d := &nfs{}
+	d.init(nil, "testpool", config, logger.Log, nil, &Validators{
+		PoolRules:      func() map[string]func(string) error { return map[string]func(string) error{} },
+		LocalPoolRules: func() map[string]func(string) error { return map[string]func(string) error{} },
+		VolumeRules: func(vol Volume) map[string]func(string) error {
+			return map[string]func(string) error{"size": validate.Optional(validate.IsSize)}
+		},
+	})
+
+	return d
+}
+
+func Test_nfs_Info(t *testing.T) {
+	info := newTestNFS(nil).Info()
+
+	assert.Equal(t, "nfs", info.Name)
+	assert.True(t, info.Remote)
+	assert.True(t, info.VolumeMultiNode)
+	assert.False(t, info.Buckets)
+	assert.NotContains(t, info.VolumeTypes, VolumeTypeBucket)
+}
+
+func Test_nfs_SourceIdentifier(t *testing.T) {
+	identifier, err := newTestNFS(map[string]string{"nfs.host": "192.0.2.1", "nfs.path": "/export/lxd"}).SourceIdentifier()
+	assert.NoError(t, err)
+	assert.Equal(t, "192.0.2.1:/export/lxd", identifier)
+
+	_, err = newTestNFS(map[string]string{"nfs.path": "/export/lxd"}).SourceIdentifier()
+	assert.Error(t, err)
+
+	_, err = newTestNFS(map[string]string{"nfs.host": "192.0.2.1"}).SourceIdentifier()
+	assert.Error(t, err)
+}
+
+func Test_nfs_ValidateSource(t *testing.T) {
+	assert.NoError(t, newTestNFS(map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd"}).ValidateSource())
+	assert.Error(t, newTestNFS(map[string]string{"nfs.path": "/export/lxd"}).ValidateSource())
+	assert.Error(t, newTestNFS(map[string]string{"nfs.host": "nfs.example.com"}).ValidateSource())
+}
+
+func Test_nfs_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "Valid config",
+			config: map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd", "nfs.version": "4.2", "nfs.mount_options": "nconnect=4"},
+		},
+		{
+			name:    "Missing export path",
+			config:  map[string]string{"nfs.host": "nfs.example.com"},
+			wantErr: true,
+		},
+		{
+			name:    "Relative export path",
+			config:  map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "export/lxd"},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported version",
+			config:  map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd", "nfs.version": "2"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown option",
+			config:  map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd", "source": "/srv/lxd"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newTestNFS(tt.config).Validate(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_nfs_Update(t *testing.T) {
+	d := newTestNFS(map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd"})
+
+	assert.Error(t, d.Update(map[string]string{"nfs.host": "nfs2.example.com"}))
+	assert.Error(t, d.Update(map[string]string{"nfs.path": "/export/other"}))
+	assert.NoError(t, d.Update(map[string]string{"nfs.mount_options": "nconnect=4"}))
+}
+
+func Test_nfs_ValidateVolume(t *testing.T) {
+	d := newTestNFS(map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd"})
+
+	tests := []struct {
+		name        string
+		volType     VolumeType
+		contentType ContentType
+		config      map[string]string
+		wantErr     bool
+	}{
+		{
+			name:        "Custom filesystem volume without size",
+			volType:     VolumeTypeCustom,
+			contentType: ContentTypeFS,
+			config:      map[string]string{},
+		},
+		{
+			name:        "Custom filesystem volume with size on an export without project quotas",
+			volType:     VolumeTypeCustom,
+			contentType: ContentTypeFS,
+			config:      map[string]string{"size": "10GiB"},
+			wantErr:     true,
+		},
+		{
+			name:        "Custom block volume with size",
+			volType:     VolumeTypeCustom,
+			contentType: ContentTypeBlock,
+			config:      map[string]string{"size": "10GiB"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vol := NewVolume(d, d.name, tt.volType, tt.contentType, "vol1", tt.config, d.config)
+			err := d.ValidateVolume(vol, false)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func Test_nfs_SetVolumeQuota(t *testing.T) {
+	d := newTestNFS(map[string]string{"nfs.host": "nfs.example.com", "nfs.path": "/export/lxd"})
+
+	// Size limits on filesystem volumes are rejected before touching the volume when the export (here not even
+	// mounted) doesn't support project quotas.
+	for _, volType := range []VolumeType{VolumeTypeContainer, VolumeTypeCustom} {
+		vol := NewVolume(d, d.name, volType, ContentTypeFS, "vol1", map[string]string{}, d.config)
+		assert.ErrorIs(t, d.SetVolumeQuota(vol, "10GiB", false, nil), errNFSSizeNotSupported)
+	}
+}
@@ -0,0 +1,51 @@
+package drivers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// nfsMountTimeout is the maximum time given to mount.nfs to mount an export.
+const nfsMountTimeout = 30 * time.Second
+
+// mountExport mounts the NFS export of the pool on the target path.
+func (d *nfs) mountExport(ctx context.Context, target string) error {
+	host := d.config["nfs.host"]
+
+	// IPv6 addresses need to be enclosed in brackets.
+	ip := net.ParseIP(host)
+	if ip != nil && ip.To4() == nil {
+		host = "[" + host + "]"
+	}
+
+	source := host + ":" + d.config["nfs.path"]
+
+	var options []string
+	if d.config["nfs.version"] != "" {
+		options = append(options, "vers="+d.config["nfs.version"])
+	}
+
+	if d.config["nfs.mount_options"] != "" {
+		options = append(options, d.config["nfs.mount_options"])
+	}
+
+	args := []string{source, target}
+	if len(options) > 0 {
+		args = append(args, "-o", strings.Join(options, ","))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, nfsMountTimeout)
+	defer cancel()
+
+	_, err := shared.RunCommand(ctx, "mount.nfs", args...)
+	if err != nil {
+		return fmt.Errorf("Failed mounting NFS export %q on %q: %w", source, target, err)
+	}
+
+	return nil
+}
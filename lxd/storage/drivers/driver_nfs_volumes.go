@@ -0,0 +1,53 @@
+package drivers
+
+import (
+	"errors"
+
+	"github.com/canonical/lxd/lxd/storage/quota"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/units"
+)
+
+// errNFSSizeNotSupported is returned when setting a size limit on a filesystem volume of an NFS storage pool whose
+// export doesn't support project quotas, as the size of filesystem volumes can't be limited then.
+var errNFSSizeNotSupported = errors.New("Size limits aren't supported for filesystem volumes on NFS storage pools whose export doesn't support project quotas")
+
+// projectQuotaSupported returns whether the mounted NFS export supports the project quotas used by the dir driver
+// to limit the size of filesystem volumes.
+func (d *nfs) projectQuotaSupported() bool {
+	ok, err := quota.Supported(GetPoolMountPath(d.name))
+
+	return err == nil && ok
+}
+
+// ValidateVolume validates the supplied volume config. Optionally removes invalid keys from the volume's config.
+func (d *nfs) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
+	if vol.volType == VolumeTypeCustom && vol.contentType == ContentTypeFS && vol.config["size"] != "" && !d.projectQuotaSupported() {
+		return errNFSSizeNotSupported
+	}
+
+	return d.dir.ValidateVolume(vol, removeUnknownKeys)
+}
+
+// SetVolumeQuota applies a size limit on volume.
+// The size of filesystem volumes is limited with project quotas like on the dir driver, if the export supports them.
+// The size of virtual machine and custom block volumes is enforced by the size of their backing file.
+func (d *nfs) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, progressReporter ioprogress.ProgressReporter) error {
+	if vol.contentType == ContentTypeFS && vol.volType != VolumeTypeVM {
+		sizeBytes, err := units.ParseByteSizeString(size)
+		if err != nil {
+			return err
+		}
+
+		if sizeBytes > 0 && !d.projectQuotaSupported() {
+			return errNFSSizeNotSupported
+		}
+	}
+
+	return d.dir.SetVolumeQuota(vol, size, allowUnsafeResize, progressReporter)
+}
+
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *nfs) ListVolumes() ([]Volume, error) {
+	return genericVFSListVolumes(d)
+}
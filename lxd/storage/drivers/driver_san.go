@@ -0,0 +1,388 @@
+package drivers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/storage/connectors"
+	"github.com/canonical/lxd/lxd/storage/drivers/clients"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// sanLoaded indicates whether load() function was already called for this storage driver.
+var sanLoaded = false
+
+// sanVersion indicates storage driver version.
+var sanVersion = ""
+
+// sanSupportedConnectors represents a list of storage connectors that can be used.
+var sanSupportedConnectors = []string{
+	connectors.TypeNVMeTCP,
+	connectors.TypeISCSI,
+}
+
+// sanDefaultMode represents the default SAN mode.
+const sanDefaultMode = connectors.TypeNVMeTCP
+
+// sanDefaultSSHUser is the default user used to manage a remote storage host.
+const sanDefaultSSHUser = "root"
+
+type san struct {
+	common
+
+	// Holds the low level connector (iSCSI, NVMe/TCP).
+	// Use .connector() method to retrieve the initialized connector.
+	storageConnector connectors.Connector
+
+	// Holds the low level client managing the storage host.
+	// Use .client() method to retrieve the client struct.
+	sanClient *clients.SANClient
+}
+
+// load is used to run one-time action per-driver rather than per-pool.
+func (d *san) load() error {
+	// Done if previously loaded.
+	if sanLoaded {
+		return nil
+	}
+
+	versions := connectors.GetSupportedVersions(sanSupportedConnectors)
+	sanVersion = strings.Join(versions, " / ")
+	sanLoaded = true
+
+	// Load the kernel modules of the respective connector, ignoring those that cannot be loaded.
+	// Support for a specific connector is checked during pool creation. However, this
+	// ensures that the kernel modules are loaded, even if the host has been rebooted.
+	connector, err := d.connector()
+	if err == nil {
+		_ = connector.LoadModules()
+	}
+
+	return nil
+}
+
+// connector retrieves an initialized storage connector based on the configured
+// storage driver mode (iSCSI, NVMe/TCP). The connector is cached in the driver struct.
+func (d *san) connector() (connectors.Connector, error) {
+	if d.storageConnector == nil {
+		connector, err := connectors.NewConnector(d.config["san.mode"], d.state.OS.ServerUUID)
+		if err != nil {
+			return nil, err
+		}
+
+		d.storageConnector = connector
+	}
+
+	return d.storageConnector, nil
+}
+
+// client returns the drivers SAN client. A new client is created only if it does not already exist.
+func (d *san) client() *clients.SANClient {
+	if d.sanClient == nil {
+		user := d.config["san.ssh.user"]
+		if user == "" {
+			user = sanDefaultSSHUser
+		}
+
+		d.sanClient = clients.NewSANClient(
+			d.logger,
+			d.config["san.host"],
+			user,
+			d.config["san.ssh.key"],
+			d.config["san.ssh.host_key"],
+			d.config["san.backend"],
+			d.config["san.source"],
+			d.config["san.mode"])
+	}
+
+	return d.sanClient
+}
+
+// isRemote returns true indicating this driver uses remote storage.
+func (d *san) isRemote() bool {
+	return true
+}
+
+// Info returns info about the driver and its environment.
+func (d *san) Info() Info {
+	return Info{
+		Name:                         "san",
+		Version:                      sanVersion,
+		DefaultBlockSize:             d.defaultBlockVolumeSize(),
+		DefaultVMBlockFilesystemSize: d.defaultVMBlockFilesystemSize(),
+		OptimizedImages:              true,
+		PreservesInodes:              true,
+		Remote:                       d.isRemote(),
+		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		BlockBacking:                 true,
+		RunningCopyFreeze:            true,
+		DirectIO:                     true,
+		MountedRoot:                  false,
+		PopulateParentVolumeUUID:     true,
+	}
+}
+
+// FillConfig populates the driver's config with default values.
+func (d *san) FillConfig() error {
+	// Use NVMe by default.
+	if d.config["san.mode"] == "" {
+		d.config["san.mode"] = sanDefaultMode
+	}
+
+	return nil
+}
+
+// Validate checks that all provide keys are supported and that no conflicting or missing configuration is present.
+func (d *san) Validate(config map[string]string) error {
+	rules := map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.host)
+		// If not set, the LXD server itself acts as the storage host.
+		// Otherwise, the storage host is managed over SSH.
+		// ---
+		//  type: string
+		//  shortdesc: Address of the storage host
+		"san.host": validate.IsAny,
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.ssh.user)
+		//
+		// ---
+		//  type: string
+		//  defaultdesc: `root`
+		//  shortdesc: User used to manage the storage host over SSH
+		"san.ssh.user": validate.IsAny,
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.ssh.key)
+		//
+		// ---
+		//  type: string
+		//  shortdesc: Private SSH key used to manage the storage host
+		"san.ssh.key": validate.IsAny,
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.ssh.host_key)
+		// The public key of the storage host in `authorized_keys` format, used to verify its identity.
+		// ---
+		//  type: string
+		//  shortdesc: SSH host key of the storage host
+		"san.ssh.host_key": validate.IsAny,
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.backend)
+		// Supported values are `lvm` and `zfs`.
+		// ---
+		//  type: string
+		//  shortdesc: Storage backend providing the volumes on the storage host
+		"san.backend": validate.Optional(validate.IsOneOf(clients.SANBackendLVM, clients.SANBackendZFS)),
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.source)
+		// For `lvm`, this is the LVM thin pool in the form `<volume_group>/<thin_pool>`.
+		// For `zfs`, this is the dataset under which the ZFS volumes are created.
+		// ---
+		//  type: string
+		//  shortdesc: Backing source on the storage host
+		"san.source": validate.Optional(validateSANSource),
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.target)
+		// A comma-separated list of IP addresses (with an optional port) on which the storage host exports the volumes.
+		// ---
+		//  type: string
+		//  defaultdesc: the IP address of `san.host`
+		//  shortdesc: List of target addresses
+		"san.target": validate.Optional(validate.IsListOf(validateSANTargetAddress)),
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=san.mode)
+		// The mode to use to map storage volumes to the local server.
+		// Supported values are `iscsi` and `nvme/tcp`.
+		// ---
+		//  type: string
+		//  defaultdesc: `nvme/tcp`
+		//  shortdesc: How volumes are mapped to the local server
+		"san.mode": validate.Optional(validate.IsOneOf(sanSupportedConnectors...)),
+		// lxdmeta:generate(entities=storage-san; group=pool-conf; key=volume.size)
+		// Default storage volume size rounded to 1MiB.
+		// ---
+		//  type: string
+		//  defaultdesc: `10GiB`
+		//  shortdesc: Size/quota of the storage volume
+		"volume.size": validate.Optional(validate.IsSize),
+	}
+
+	err := d.validatePool(config, rules, d.commonVolumeRules())
+	if err != nil {
+		return err
+	}
+
+	newMode := config["san.mode"]
+	oldMode := d.config["san.mode"]
+
+	// If mode is not provided, use default mode. This is needed for cluster pool creation to
+	// ensure required modules are available and loaded on all cluster members.
+	if newMode == "" {
+		newMode = sanDefaultMode
+	}
+
+	// Ensure san.mode cannot be changed to avoid leaving volume mappings
+	// and prevent disturbing running instances.
+	if oldMode != "" && oldMode != newMode {
+		return errors.New("SAN mode cannot be changed")
+	}
+
+	// Check if the selected SAN mode is supported on this node.
+	// Validate gets executed on every cluster member when receiving the cluster
+	// notification to finally create the pool.
+	connector, err := connectors.NewConnector(newMode, "")
+	if err != nil {
+		return fmt.Errorf("SAN mode %q is not supported: %w", newMode, err)
+	}
+
+	err = connector.LoadModules()
+	if err != nil {
+		return fmt.Errorf("SAN mode %q is not supported due to missing kernel modules: %w", newMode, err)
+	}
+
+	return nil
+}
+
+// validateSANSource validates an LVM thin pool or ZFS dataset name.
+func validateSANSource(value string) error {
+	if value == "" || strings.HasPrefix(value, "/") || strings.HasSuffix(value, "/") {
+		return fmt.Errorf("Invalid source %q", value)
+	}
+
+	for _, r := range value {
+		if !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') && !strings.ContainsRune("-_.:/+", r) {
+			return fmt.Errorf("Invalid character %q in source %q", r, value)
+		}
+	}
+
+	return nil
+}
+
+// validateSANTargetAddress validates a target IP address with an optional port.
+func validateSANTargetAddress(value string) error {
+	host, _, err := net.SplitHostPort(value)
+	if err != nil {
+		host = value
+	}
+
+	return validate.IsNetworkAddress(host)
+}
+
+// SourceIdentifier returns a combined string consisting of the storage host and backing source.
+func (d *san) SourceIdentifier() (string, error) {
+	source := d.config["san.source"]
+	if source == "" {
+		return "", errors.New("Cannot derive identifier from empty source")
+	}
+
+	host := d.config["san.host"]
+	if host == "" {
+		host = d.state.ServerName
+	}
+
+	return host + ":" + source, nil
+}
+
+// ValidateSource checks whether the required config keys are set to access the remote source.
+func (d *san) ValidateSource() error {
+	// Since those aren't any cluster member specific keys the general validation
+	// rules allow empty strings in order to create the pending storage pools.
+	if d.config["san.backend"] == "" {
+		return errors.New("The san.backend cannot be empty")
+	}
+
+	if d.config["san.source"] == "" {
+		return errors.New("The san.source cannot be empty")
+	}
+
+	if d.config["san.backend"] == clients.SANBackendLVM && !strings.Contains(d.config["san.source"], "/") {
+		return errors.New(`The san.source must be in the form "<volume_group>/<thin_pool>" when using the lvm backend`)
+	}
+
+	if d.config["san.host"] != "" {
+		if d.config["san.ssh.key"] == "" {
+			return errors.New("The san.ssh.key cannot be empty when san.host is set")
+		}
+
+		if d.config["san.ssh.host_key"] == "" {
+			return errors.New("The san.ssh.host_key cannot be empty when san.host is set")
+		}
+	}
+
+	_, err := d.getTargetAddrs()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Create is called during pool creation and is effectively using an empty driver struct.
+// WARNING: The Create() function cannot rely on any of the struct attributes being set.
+func (d *san) Create() error {
+	return d.client().CheckHost(d.state.ShutdownCtx)
+}
+
+// Delete removes a storage pool.
+func (d *san) Delete(progressReporter ioprogress.ProgressReporter) error {
+	// The backing source is provided by the user and is left in place.
+	// On delete, wipe everything in the directory.
+	return wipeDirectory(GetPoolMountPath(d.name))
+}
+
+// Update applies any driver changes required from a configuration change.
+func (d *san) Update(changedConfig map[string]string) error {
+	for _, key := range []string{"san.host", "san.backend", "san.source"} {
+		_, changed := changedConfig[key]
+		if changed {
+			return fmt.Errorf("Option %q cannot be changed", key)
+		}
+	}
+
+	// Recreate the client with the new connection settings.
+	d.sanClient = nil
+
+	return nil
+}
+
+// Mount mounts the storage pool.
+func (d *san) Mount() (bool, error) {
+	return true, nil
+}
+
+// Unmount unmounts the storage pool.
+func (d *san) Unmount() (bool, error) {
+	return true, nil
+}
+
+// GetResources returns the pool resource usage information.
+func (d *san) GetResources() (*api.ResourcesStoragePool, error) {
+	// The backing source can be shared with other consumers on the storage host.
+	// So this used/free information can be inaccurate for this reason.
+	used, total, err := d.client().GetSpace(d.state.ShutdownCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := api.ResourcesStoragePool{}
+	res.Space.Used = used
+	res.Space.Total = total
+	return &res, nil
+}
+
+// getTargetAddrs returns the addresses on which the storage host exports the volumes.
+func (d *san) getTargetAddrs() ([]string, error) {
+	targetAddrs := shared.SplitNTrimSpace(d.config["san.target"], ",", -1, true)
+	if len(targetAddrs) > 0 {
+		return targetAddrs, nil
+	}
+
+	// Fallback to the storage host's address if it is an IP address.
+	host := d.config["san.host"]
+	hostName, _, err := net.SplitHostPort(host)
+	if err == nil {
+		host = hostName
+	}
+
+	if net.ParseIP(host) == nil {
+		return nil, errors.New("The san.target must be set unless san.host is an IP address")
+	}
+
+	return []string{host}, nil
+}
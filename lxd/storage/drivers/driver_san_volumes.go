@@ -0,0 +1,1255 @@
+package drivers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/lxd/lxd/backup"
+	"github.com/canonical/lxd/lxd/instancewriter"
+	"github.com/canonical/lxd/lxd/migration"
+	"github.com/canonical/lxd/lxd/storage/block"
+	"github.com/canonical/lxd/lxd/storage/connectors"
+	"github.com/canonical/lxd/lxd/storage/filesystem"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/revert"
+	"github.com/canonical/lxd/shared/units"
+	"github.com/canonical/lxd/shared/validate"
+)
+
+// sanVolTypePrefixes maps volume type to storage volume name prefix.
+var sanVolTypePrefixes = map[VolumeType]string{
+	VolumeTypeContainer: "c",
+	VolumeTypeVM:        "v",
+	VolumeTypeImage:     "i",
+	VolumeTypeCustom:    "u",
+}
+
+// sanContentTypeSuffixes maps volume's content type to storage volume name suffix.
+var sanContentTypeSuffixes = map[ContentType]string{
+	// Suffix used for block content type volumes.
+	ContentTypeBlock: "b",
+
+	// Suffix used for ISO content type volumes.
+	ContentTypeISO: "i",
+}
+
+// sanSnapshotPrefix is a prefix used for SAN snapshots to avoid name conflicts.
+var sanSnapshotPrefix = "s"
+
+// sanCloneSuffix is a suffix used for the temporary writable clones through which snapshots are exported.
+var sanCloneSuffix = "-clone"
+
+// sanTargetQNPrefixes maps the SAN mode to the prefix of the per-volume target qualified names.
+var sanTargetQNPrefixes = map[string]string{
+	connectors.TypeISCSI:   "iqn.2024-10.com.canonical.lxd:",
+	connectors.TypeNVMeTCP: "nqn.2024-10.com.canonical.lxd:",
+}
+
+// sanVMBlockFilesystemSize is the size of a VM root device block volume's associated filesystem volume.
+const sanVMBlockFilesystemSize = "256MiB"
+
+// defaultVMBlockFilesystemSize returns the size of a VM root device block volume's associated filesystem volume.
+func (d *san) defaultVMBlockFilesystemSize() string {
+	return sanVMBlockFilesystemSize
+}
+
+// getVolumeName returns the fully qualified name derived from the volume's UUID.
+func (d *san) getVolumeName(vol Volume) (string, error) {
+	volUUID, err := uuid.Parse(vol.config["volatile.uuid"])
+	if err != nil {
+		return "", fmt.Errorf(`Failed parsing "volatile.uuid" from volume %q: %w`, vol.name, err)
+	}
+
+	// Remove hypens from the UUID to create a volume name.
+	volName := strings.ReplaceAll(volUUID.String(), "-", "")
+
+	// Search for the volume type prefix, and if found, prepend it to the volume name.
+	volumeTypePrefix, ok := sanVolTypePrefixes[vol.volType]
+	if ok {
+		volName = volumeTypePrefix + "-" + volName
+	}
+
+	// Search for the content type suffix, and if found, append it to the volume name.
+	contentTypeSuffix, ok := sanContentTypeSuffixes[vol.contentType]
+	if ok {
+		volName = volName + "-" + contentTypeSuffix
+	}
+
+	// If volume is snapshot, prepend snapshot prefix to its name.
+	if vol.IsSnapshot() {
+		volName = sanSnapshotPrefix + volName
+	}
+
+	return volName, nil
+}
+
+// getSourceName returns the names of the backing device and snapshot for the given volume.
+// For regular volumes the snapshot name is empty.
+func (d *san) getSourceName(vol Volume) (volName string, snapName string, err error) {
+	if !vol.IsSnapshot() {
+		volName, err = d.getVolumeName(vol)
+		return volName, "", err
+	}
+
+	volName, err = d.getVolumeName(vol.GetParent())
+	if err != nil {
+		return "", "", err
+	}
+
+	snapName, err = d.getVolumeName(vol)
+	if err != nil {
+		return "", "", err
+	}
+
+	return volName, snapName, nil
+}
+
+// getExportName returns the name of the backing device exported for the given volume.
+// Snapshots are exported through a temporary writable clone.
+func (d *san) getExportName(vol Volume) (string, error) {
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return "", err
+	}
+
+	if vol.IsSnapshot() {
+		return volName + sanCloneSuffix, nil
+	}
+
+	return volName, nil
+}
+
+// getTargetQN returns the qualified name of the target through which the given backing device is exported.
+// Each volume is exported under its own target to allow per volume access control.
+func (d *san) getTargetQN(exportName string) (string, error) {
+	connector, err := d.connector()
+	if err != nil {
+		return "", err
+	}
+
+	prefix, ok := sanTargetQNPrefixes[connector.Type()]
+	if !ok {
+		return "", fmt.Errorf("Unsupported SAN mode %q", connector.Type())
+	}
+
+	return prefix + exportName, nil
+}
+
+// getSerial returns the hexadecimal serial identifying the device of the given backing device.
+// The serial is used as the LIO unit serial (from which the device WWN is derived) and as the NVMe namespace NGUID.
+func (d *san) getSerial(exportName string) string {
+	hash := sha256.Sum256([]byte(exportName))
+	return hex.EncodeToString(hash[:16])
+}
+
+// mapVolume maps the given volume onto this host.
+func (d *san) mapVolume(vol Volume) (cleanup revert.Hook, err error) {
+	reverter := revert.New()
+	defer reverter.Fail()
+
+	connector, err := d.connector()
+	if err != nil {
+		return nil, err
+	}
+
+	exportName, err := d.getExportName(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := remoteVolumeMapLock(connector.Type(), d.Info().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	client := d.client()
+
+	// Snapshots are read-only, so export them through a writable clone.
+	if vol.IsSnapshot() {
+		exists, err := client.VolumeExists(d.state.ShutdownCtx, exportName, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			volName, snapName, err := d.getSourceName(vol)
+			if err != nil {
+				return nil, err
+			}
+
+			err = client.CloneVolumeSnapshot(d.state.ShutdownCtx, volName, snapName, exportName)
+			if err != nil {
+				return nil, err
+			}
+
+			reverter.Add(func() {
+				err := client.DeleteVolume(d.state.ShutdownCtx, exportName)
+				if err != nil {
+					d.logger.Warn("Failed deleting snapshot clone on error path", logger.Ctx{"err": err, "volName": exportName})
+				}
+			})
+		}
+	}
+
+	// Get the qualified name of the host.
+	qn, err := connector.QualifiedName()
+	if err != nil {
+		return nil, err
+	}
+
+	targetQN, err := d.getTargetQN(exportName)
+	if err != nil {
+		return nil, err
+	}
+
+	targetAddrs, err := d.getTargetAddrs()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure the volume is exported and accessible by the host.
+	accessGranted, err := client.ExportVolume(d.state.ShutdownCtx, targetQN, exportName, d.getSerial(exportName), qn, targetAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	if accessGranted {
+		reverter.Add(func() {
+			_, err := client.UnexportVolume(d.state.ShutdownCtx, targetQN, qn)
+			if err != nil {
+				d.logger.Warn("Failed unexporting volume on error path", logger.Ctx{"err": err, "volName": exportName})
+			}
+		})
+	}
+
+	// Connect to the volume's target.
+	connReverter, err := connector.Connect(d.state.ShutdownCtx, targetQN, targetAddrs...)
+	if err != nil {
+		return nil, err
+	}
+
+	reverter.Add(connReverter)
+
+	// If connect succeeded it means we have at least one established connection.
+	// However, its reverter does not clean up the established connections or a newly
+	// created session. Therefore, if we granted the access, add unmapVolume to the
+	// returned (outer) reverter.
+	outerReverter := revert.New()
+	if accessGranted {
+		outerReverter.Add(func() {
+			err := d.unmapVolume(vol)
+			if err != nil {
+				d.logger.Warn("unmapVolume failed on error path", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	// Add connReverter to the outer reverter, as it will immediately stop
+	// any ongoing connection attempts. Note that it must be added after
+	// unmapVolume to ensure it is called first.
+	outerReverter.Add(connReverter)
+
+	reverter.Success()
+	return outerReverter.Fail, nil
+}
+
+// unmapVolume unmaps the given volume from this host.
+func (d *san) unmapVolume(vol Volume) error {
+	connector, err := d.connector()
+	if err != nil {
+		return err
+	}
+
+	exportName, err := d.getExportName(vol)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := remoteVolumeMapLock(connector.Type(), d.Info().Name)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	qn, err := connector.QualifiedName()
+	if err != nil {
+		return err
+	}
+
+	targetQN, err := d.getTargetQN(exportName)
+	if err != nil {
+		return err
+	}
+
+	// Get a path of a block device we want to unmap.
+	volumePath, _, _ := d.getMappedDevPath(vol, false)
+
+	err = connector.RemoveDiskDevice(d.state.ShutdownCtx, volumePath)
+	if err != nil {
+		return fmt.Errorf("Failed unmapping SAN volume %q: %w", vol.name, err)
+	}
+
+	// Disconnect from the volume's target as it is not shared with any other volume.
+	err = connector.Disconnect(targetQN)
+	if err != nil {
+		return err
+	}
+
+	// For NVMe the host-side device is removed asynchronously after the disconnect.
+	// NVMe's [connectors.RemoveDiskDevice] is a no-op, so this is the only sync point.
+	if volumePath != "" && connector.Type() == connectors.TypeNVMeTCP && !block.WaitDiskDeviceGone(d.state.ShutdownCtx, volumePath) {
+		return fmt.Errorf("Timeout exceeded waiting for SAN volume %q to disappear on path %q", vol.name, volumePath)
+	}
+
+	// Revoke the access of this host, which removes the target once no other host uses it.
+	removed, err := d.client().UnexportVolume(d.state.ShutdownCtx, targetQN, qn)
+	if err != nil {
+		return err
+	}
+
+	// Remove the snapshot's clone once it isn't exported anymore.
+	if vol.IsSnapshot() && removed {
+		err = d.client().DeleteVolume(d.state.ShutdownCtx, exportName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getMappedDevPath returns the local device path for the given volume.
+// Indicate with mapVolume if the volume should get mapped to the system if it isn't present.
+func (d *san) getMappedDevPath(vol Volume, mapVolume bool) (string, revert.Hook, error) {
+	revert := revert.New()
+	defer revert.Fail()
+
+	connector, err := d.connector()
+	if err != nil {
+		return "", nil, err
+	}
+
+	if mapVolume {
+		cleanup, err := d.mapVolume(vol)
+		if err != nil {
+			return "", nil, err
+		}
+
+		revert.Add(cleanup)
+	}
+
+	exportName, err := d.getExportName(vol)
+	if err != nil {
+		return "", nil, err
+	}
+
+	serial := d.getSerial(exportName)
+
+	var diskSuffix string
+
+	switch connector.Type() {
+	case connectors.TypeISCSI:
+		// LIO derives the NAA WWN of the device from the first 25 hexadecimal digits of the unit serial.
+		diskSuffix = serial[:25]
+	case connectors.TypeNVMeTCP:
+		diskSuffix = serial
+	default:
+		return "", nil, fmt.Errorf("Unsupported SAN mode %q", connector.Type())
+	}
+
+	// Filters devices by matching the device path with the disk suffix.
+	diskPathFilter := func(devPath string) bool {
+		return strings.HasSuffix(devPath, diskSuffix)
+	}
+
+	var devicePath string
+	if mapVolume {
+		// Wait until the disk device is mapped to the host.
+		devicePath, err = connector.WaitDiskDevicePath(d.state.ShutdownCtx, diskPathFilter)
+	} else {
+		// Expect device to be already mapped.
+		devicePath, err = connector.GetDiskDevicePath(diskPathFilter)
+	}
+
+	if err != nil {
+		return "", nil, fmt.Errorf("Failed locating device for volume %q: %w", vol.name, err)
+	}
+
+	cleanup := revert.Clone().Fail
+	revert.Success()
+	return devicePath, cleanup, nil
+}
+
+// GetVolumeDiskPath returns the location of a root disk block device.
+func (d *san) GetVolumeDiskPath(vol Volume) (string, error) {
+	if vol.IsVMBlock() || (vol.volType == VolumeTypeCustom && IsContentBlock(vol.contentType)) {
+		devPath, _, err := d.getMappedDevPath(vol, false)
+		return devPath, err
+	}
+
+	return "", ErrNotSupported
+}
+
+// MountVolume mounts a volume and increments ref counter. Please call UnmountVolume() when done with the volume.
+func (d *san) MountVolume(vol Volume, progressReporter ioprogress.ProgressReporter) error {
+	return mountVolume(d, vol, d.getMappedDevPath, progressReporter)
+}
+
+// UnmountVolume simulates unmounting a volume.
+// keepBlockDev indicates if backing block device should not be unmapped if volume is unmounted.
+func (d *san) UnmountVolume(vol Volume, keepBlockDev bool, progressReporter ioprogress.ProgressReporter) (bool, error) {
+	return unmountVolume(d, vol, keepBlockDev, d.getMappedDevPath, d.unmapVolume, progressReporter)
+}
+
+// CreateVolume creates an empty volume and can optionally fill it by executing the supplied filler function.
+func (d *san) CreateVolume(vol Volume, filler *VolumeFiller, progressReporter ioprogress.ProgressReporter) error {
+	client := d.client()
+
+	revert := revert.New()
+	defer revert.Fail()
+
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return err
+	}
+
+	sizeBytes, err := units.ParseByteSizeString(vol.ConfigSize())
+	if err != nil {
+		return err
+	}
+
+	// Create the volume.
+	err = client.CreateVolume(d.state.ShutdownCtx, volName, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		err := client.DeleteVolume(d.state.ShutdownCtx, volName)
+		if err != nil {
+			d.logger.Warn("Failed deleting volume on error path", logger.Ctx{"err": err})
+		}
+	})
+
+	volumeFilesystem := vol.ConfigBlockFilesystem()
+	if vol.contentType == ContentTypeFS {
+		devPath, cleanup, err := d.getMappedDevPath(vol, true)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(cleanup)
+
+		_, err = makeFSType(devPath, volumeFilesystem, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// For VMs, also create the filesystem volume.
+	if vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+
+		err := d.CreateVolume(fsVol, nil, progressReporter)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			err := d.DeleteVolume(fsVol, progressReporter)
+			if err != nil {
+				d.logger.Warn("DeleteVolume failed on error path", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	err = vol.MountTask(func(mountPath string, progressReporter ioprogress.ProgressReporter) error {
+		// Run the volume filler function if supplied.
+		if filler != nil && filler.Fill != nil {
+			var err error
+			var devPath string
+
+			if IsContentBlock(vol.contentType) {
+				// Get the device path.
+				devPath, err = d.GetVolumeDiskPath(vol)
+				if err != nil {
+					return err
+				}
+			}
+
+			// Allow filler to resize initial image volume as needed.
+			// This is safe because if for some reason an error occurs the volume will be
+			// discarded rather than leaving a corrupt filesystem.
+			allowUnsafeResize := vol.volType == VolumeTypeImage
+
+			// Run the filler.
+			err = d.runFiller(vol, devPath, filler, allowUnsafeResize)
+			if err != nil {
+				return err
+			}
+
+			// Move the GPT alt header to end of disk if needed.
+			if vol.IsVMBlock() {
+				err = d.moveGPTAltHeader(devPath)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if vol.contentType == ContentTypeFS {
+			// Run EnsureMountPath again after mounting and filling to ensure the mount directory has
+			// the correct permissions set.
+			err = vol.EnsureMountPath()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, progressReporter)
+	if err != nil {
+		return err
+	}
+
+	revert.Success()
+	return nil
+}
+
+// CreateVolumeFromBackup re-creates a volume from its exported state.
+func (d *san) CreateVolumeFromBackup(vol VolumeCopy, srcBackup backup.Info, srcData io.ReadSeeker, progressReporter ioprogress.ProgressReporter) (VolumePostHook, revert.Hook, error) {
+	return genericVFSBackupUnpack(d, d.state, vol, srcBackup.Snapshots, srcData, progressReporter)
+}
+
+// BackupVolume creates an exported version of a volume.
+func (d *san) BackupVolume(vol VolumeCopy, projectName string, tarWriter *instancewriter.InstanceTarWriter, optimized bool, snapshots []string, progressReporter ioprogress.ProgressReporter) error {
+	return genericVFSBackupVolume(d, vol, tarWriter, snapshots, progressReporter)
+}
+
+// EnsureImage materialises the cached image volume on disk if it is not already present.
+func (d *san) EnsureImage(imgVol Volume, filler *VolumeFiller, progressReporter ioprogress.ProgressReporter) error {
+	return ensureImageVolume(imgVol, filler, progressReporter)
+}
+
+// CreateVolumeFromCopy provides same-pool volume copying functionality.
+// The content is copied on the storage host without going through the LXD server.
+func (d *san) CreateVolumeFromCopy(vol VolumeCopy, srcVol VolumeCopy, allowInconsistent bool, progressReporter ioprogress.ProgressReporter) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	// For VMs, also copy the filesystem volume.
+	if vol.IsVMBlock() {
+		// Ensure that the volume's snapshots are also replaced with their filesystem counterpart.
+		fsVolSnapshots := make([]Volume, 0, len(vol.Snapshots))
+		for _, snapshot := range vol.Snapshots {
+			fsVolSnapshots = append(fsVolSnapshots, snapshot.NewVMBlockFilesystemVolume())
+		}
+
+		srcFsVolSnapshots := make([]Volume, 0, len(srcVol.Snapshots))
+		for _, snapshot := range srcVol.Snapshots {
+			srcFsVolSnapshots = append(srcFsVolSnapshots, snapshot.NewVMBlockFilesystemVolume())
+		}
+
+		fsVol := NewVolumeCopy(vol.NewVMBlockFilesystemVolume(), fsVolSnapshots...)
+		srcFSVol := NewVolumeCopy(srcVol.NewVMBlockFilesystemVolume(), srcFsVolSnapshots...)
+
+		err := d.CreateVolumeFromCopy(fsVol, srcFSVol, false, progressReporter)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			err := d.DeleteVolume(fsVol.Volume, progressReporter)
+			if err != nil {
+				d.logger.Warn("DeleteVolume failed on error path", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	client := d.client()
+
+	volName, err := d.getVolumeName(vol.Volume)
+	if err != nil {
+		return err
+	}
+
+	srcVolName, srcSnapName, err := d.getSourceName(srcVol.Volume)
+	if err != nil {
+		return err
+	}
+
+	// Determine a destination volume size.
+	sizeBytes, err := units.ParseByteSizeString(vol.config["size"])
+	if err != nil {
+		return err
+	}
+
+	// Determine a source volume size on the storage host.
+	srcSizeBytes, err := client.GetVolumeSize(d.state.ShutdownCtx, srcVolName)
+	if err != nil {
+		return err
+	}
+
+	if srcSizeBytes > sizeBytes {
+		return ErrCannotBeShrunk
+	}
+
+	// Pre-create the target volume (empty).
+	err = client.CreateVolume(d.state.ShutdownCtx, volName, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		err := client.DeleteVolume(d.state.ShutdownCtx, volName)
+		if err != nil {
+			d.logger.Warn("Failed deleting volume on error path", logger.Ctx{"err": err})
+		}
+	})
+
+	// Copy volume snapshots.
+	// Each snapshot is first copied into destination volume from which a new snapshot is created.
+	// The process is repeated until all snapshots are copied.
+	if !srcVol.IsSnapshot() {
+		for _, snapshot := range vol.Snapshots {
+			_, snapshotShortName, _ := api.GetParentAndSnapshotName(snapshot.name)
+
+			// Find the corresponding source snapshot.
+			var srcSnapshot *Volume
+			for _, srcSnap := range srcVol.Snapshots {
+				_, srcSnapshotShortName, _ := api.GetParentAndSnapshotName(srcSnap.name)
+				if snapshotShortName == srcSnapshotShortName {
+					srcSnapshot = &srcSnap
+					break
+				}
+			}
+
+			if srcSnapshot == nil {
+				return fmt.Errorf("Failed copying snapshot %q: Source snapshot does not exist", snapshotShortName)
+			}
+
+			srcSnapshotName, err := d.getVolumeName(*srcSnapshot)
+			if err != nil {
+				return err
+			}
+
+			// Copy the snapshot on the destination volume.
+			err = client.CopyVolume(d.state.ShutdownCtx, srcVolName, srcSnapshotName, volName)
+			if err != nil {
+				return fmt.Errorf("Failed copying snapshot %q: %w", snapshot.name, err)
+			}
+
+			// Set snapshot's parent UUID and retain source snapshot UUID.
+			snapshot.SetParentUUID(vol.config["volatile.uuid"])
+
+			// Create snapshot from a new volume (that was created from the source snapshot).
+			// However, do not create VM's filesystem volume snapshot, as filesystem volume is
+			// copied before block volume.
+			err = d.createVolumeSnapshot(snapshot, false, progressReporter)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = client.CopyVolume(d.state.ShutdownCtx, srcVolName, srcSnapName, volName)
+	if err != nil {
+		return err
+	}
+
+	if vol.contentType == ContentTypeFS {
+		// Mount the volume and ensure the permissions are set correctly inside the mounted volume.
+		err := vol.MountTask(func(_ string, _ ioprogress.ProgressReporter) error {
+			return vol.EnsureMountPath()
+		}, progressReporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	revert.Success()
+	return nil
+}
+
+// RefreshVolume updates an existing volume to match the state of another.
+func (d *san) RefreshVolume(vol VolumeCopy, srcVol VolumeCopy, refreshSnapshots []string, allowInconsistent bool, progressReporter ioprogress.ProgressReporter) error {
+	_, err := genericVFSCopyVolume(d, nil, vol, srcVol, refreshSnapshots, true, allowInconsistent, progressReporter)
+	return err
+}
+
+// MigrateVolume sends a volume for migration.
+func (d *san) MigrateVolume(vol VolumeCopy, conn io.ReadWriteCloser, volSrcArgs *migration.VolumeSourceArgs, progressReporter ioprogress.ProgressReporter) error {
+	// When performing a cluster member move don't do anything on the source member.
+	if volSrcArgs.ClusterMove {
+		return nil
+	}
+
+	return genericVFSMigrateVolume(d, d.state, vol, conn, volSrcArgs, progressReporter)
+}
+
+// CreateVolumeFromMigration creates a volume being sent via a migration.
+func (d *san) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser, volTargetArgs migration.VolumeTargetArgs, preFiller *VolumeFiller, progressReporter ioprogress.ProgressReporter) error {
+	// When performing a cluster member move prepare the volumes on the target side.
+	if volTargetArgs.ClusterMoveSourceName != "" {
+		err := vol.EnsureMountPath()
+		if err != nil {
+			return err
+		}
+
+		if vol.IsVMBlock() {
+			fsVol := NewVolumeCopy(vol.NewVMBlockFilesystemVolume())
+			err := d.CreateVolumeFromMigration(fsVol, conn, volTargetArgs, preFiller, progressReporter)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	_, err := genericVFSCreateVolumeFromMigration(d, nil, vol, conn, volTargetArgs, preFiller, progressReporter)
+	return err
+}
+
+// DeleteVolume deletes the volume and all associated snapshots.
+func (d *san) DeleteVolume(vol Volume, progressReporter ioprogress.ProgressReporter) error {
+	volExists, err := d.HasVolume(vol)
+	if err != nil {
+		return err
+	}
+
+	if !volExists {
+		return nil
+	}
+
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return err
+	}
+
+	targetQN, err := d.getTargetQN(volName)
+	if err != nil {
+		return err
+	}
+
+	// Remove the volume's target including the access of any other host.
+	err = d.client().DeleteExport(d.state.ShutdownCtx, targetQN)
+	if err != nil {
+		return err
+	}
+
+	err = d.client().DeleteVolume(d.state.ShutdownCtx, volName)
+	if err != nil {
+		return err
+	}
+
+	// For VMs, also delete the filesystem volume.
+	if vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+
+		err := d.DeleteVolume(fsVol, progressReporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	mountPath := vol.MountPath()
+
+	if vol.contentType == ContentTypeFS {
+		err := wipeDirectory(mountPath)
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(mountPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed removing %q: %w", mountPath, err)
+		}
+	}
+
+	return nil
+}
+
+// HasVolume indicates whether a specific volume exists on the storage pool.
+func (d *san) HasVolume(vol Volume) (bool, error) {
+	volName, snapName, err := d.getSourceName(vol)
+	if err != nil {
+		return false, err
+	}
+
+	return d.client().VolumeExists(d.state.ShutdownCtx, volName, snapName)
+}
+
+// RenameVolume renames a volume and its snapshots.
+func (d *san) RenameVolume(vol Volume, newVolName string, progressReporter ioprogress.ProgressReporter) error {
+	// Renaming a volume won't change an actual name of the volume on the storage host.
+	return nil
+}
+
+// commonVolumeRules returns validation rules which are common for pool and volume.
+func (d *san) commonVolumeRules() map[string]func(value string) error {
+	return map[string]func(value string) error{
+		// lxdmeta:generate(entities=storage-san; group=volume-conf; key=block.filesystem)
+		// Valid options: `btrfs`, `ext4`, `xfs`
+		// If not set, `ext4` is assumed.
+		// ---
+		//  type: string
+		//  condition: block-based volume with content type `filesystem`
+		//  defaultdesc: same as `volume.block.filesystem`
+		//  shortdesc: File system of the storage volume
+		"block.filesystem": validate.Optional(validate.IsOneOf(blockBackedAllowedFilesystems...)),
+		// lxdmeta:generate(entities=storage-san; group=volume-conf; key=block.mount_options)
+		//
+		// ---
+		//  type: string
+		//  condition: block-based volume with content type `filesystem`
+		//  defaultdesc: same as `volume.block.mount_options`
+		//  shortdesc: Mount options for block-backed file system volumes
+		"block.mount_options": validate.IsAny,
+		// lxdmeta:generate(entities=storage-san; group=volume-conf; key=size)
+		// Default storage volume size rounded to 1MiB.
+		// ---
+		//  type: string
+		//  defaultdesc: `10GiB`
+		//  shortdesc: Size/quota of the storage volume
+		"volume.size": validate.Optional(validate.IsSize),
+	}
+}
+
+// FillVolumeConfig populate volume with default config.
+func (d *san) FillVolumeConfig(vol Volume) error {
+	// Copy volume.* configuration options from pool.
+	// Exclude 'block.filesystem' and 'block.mount_options'
+	// as these ones are handled below in this function and depend on the volume's type.
+	err := d.fillVolumeConfig(&vol, "block.filesystem", "block.mount_options")
+	if err != nil {
+		return err
+	}
+
+	// Only validate filesystem config keys for filesystem volumes or VM block volumes (which have an
+	// associated filesystem volume).
+	if vol.ContentType() == ContentTypeFS || vol.IsVMBlock() {
+		// VM volumes will always use the default filesystem.
+		if vol.IsVMBlock() {
+			vol.config["block.filesystem"] = DefaultFilesystem
+		} else {
+			// Inherit filesystem from pool if not set.
+			if vol.config["block.filesystem"] == "" {
+				vol.config["block.filesystem"] = d.config["volume.block.filesystem"]
+			}
+
+			// Default filesystem if neither volume nor pool specify an override.
+			if vol.config["block.filesystem"] == "" {
+				// Unchangeable volume property: Set unconditionally.
+				vol.config["block.filesystem"] = DefaultFilesystem
+			}
+		}
+
+		// Inherit filesystem mount options from pool if not set.
+		if vol.config["block.mount_options"] == "" {
+			vol.config["block.mount_options"] = d.config["volume.block.mount_options"]
+		}
+
+		// Default filesystem mount options if neither volume nor pool specify an override.
+		if vol.config["block.mount_options"] == "" {
+			// Unchangeable volume property: Set unconditionally.
+			vol.config["block.mount_options"] = "discard"
+		}
+	}
+
+	return nil
+}
+
+// ValidateVolume validates the supplied volume config.
+func (d *san) ValidateVolume(vol Volume, removeUnknownKeys bool) error {
+	// When creating volumes from ISO images, round its size to the next multiple of 1MiB.
+	if vol.ContentType() == ContentTypeISO {
+		sizeBytes, err := units.ParseByteSizeString(vol.ConfigSize())
+		if err != nil {
+			return err
+		}
+
+		sizeBytes = (sizeBytes + factorMiB - 1) / factorMiB * factorMiB
+		vol.SetConfigSize(strconv.FormatInt(sizeBytes, 10))
+	}
+
+	commonRules := d.commonVolumeRules()
+
+	// Disallow block.* settings for regular custom block volumes. These settings only make sense
+	// when using custom filesystem volumes. LXD will create the filesystem for these volumes,
+	// and use the mount options. When attaching a regular block volume to a VM, these are not
+	// mounted by LXD and therefore don't need these config keys.
+	if vol.volType == VolumeTypeCustom && vol.contentType == ContentTypeBlock {
+		delete(commonRules, "block.filesystem")
+		delete(commonRules, "block.mount_options")
+	}
+
+	return d.validateVolume(vol, commonRules, removeUnknownKeys)
+}
+
+// UpdateVolume applies config changes to the volume.
+func (d *san) UpdateVolume(vol Volume, changedConfig map[string]string) error {
+	newSize, sizeChanged := changedConfig["size"]
+	if sizeChanged {
+		err := d.SetVolumeQuota(vol, newSize, false, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetVolumeUsage returns the disk space used by the volume.
+func (d *san) GetVolumeUsage(vol Volume) (int64, error) {
+	// If mounted, use the filesystem stats for pretty accurate usage information.
+	if vol.contentType == ContentTypeFS && filesystem.IsMountPoint(vol.MountPath()) {
+		var stat unix.Statfs_t
+
+		err := unix.Statfs(vol.MountPath(), &stat)
+		if err != nil {
+			return -1, err
+		}
+
+		return int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize), nil
+	}
+
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return -1, err
+	}
+
+	return d.client().GetVolumeUsage(d.state.ShutdownCtx, volName)
+}
+
+// SetVolumeQuota applies a size limit on volume.
+// Does nothing if supplied with an empty/zero size.
+func (d *san) SetVolumeQuota(vol Volume, size string, allowUnsafeResize bool, progressReporter ioprogress.ProgressReporter) error {
+	// Convert to bytes.
+	sizeBytes, err := units.ParseByteSizeString(size)
+	if err != nil {
+		return err
+	}
+
+	// Do nothing if size isn't specified.
+	if sizeBytes <= 0 {
+		return nil
+	}
+
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return err
+	}
+
+	client := d.client()
+
+	// Fetch the current size of the volume from the storage host.
+	// If the volume is not yet mapped to the system this speeds up the
+	// process as the volume doesn't have to be mapped to get its size
+	// from the actual block device.
+	oldSizeBytes, err := client.GetVolumeSize(d.state.ShutdownCtx, volName)
+	if err != nil {
+		return err
+	}
+
+	// Do nothing if volume is already specified size (+/- 512 bytes).
+	if oldSizeBytes+512 > sizeBytes && oldSizeBytes-512 < sizeBytes {
+		return nil
+	}
+
+	// Shrinking the volumes is not supported, as exported devices can't be shrunk safely.
+	if sizeBytes < oldSizeBytes {
+		return ErrCannotBeShrunk
+	}
+
+	connector, err := d.connector()
+	if err != nil {
+		return err
+	}
+
+	inUse := vol.MountInUse()
+
+	// Only perform pre-resize checks if we are not in "unsafe" mode.
+	// In unsafe mode we expect the caller to know what they are doing and understand the risks.
+	if vol.contentType != ContentTypeFS && !allowUnsafeResize && inUse {
+		// We don't allow online resizing of block volumes.
+		return ErrInUse
+	}
+
+	// Grow block device first.
+	err = client.ResizeVolume(d.state.ShutdownCtx, volName, sizeBytes)
+	if err != nil {
+		return err
+	}
+
+	devPath, cleanup, err := d.getMappedDevPath(vol, true)
+	if err != nil {
+		return err
+	}
+
+	defer cleanup()
+
+	// Always wait for the disk to reflect the new size.
+	// The size of an already mapped device is only updated once the initiator rescans it.
+	err = connector.WaitDiskDeviceResize(d.state.ShutdownCtx, devPath, sizeBytes)
+	if err != nil {
+		return fmt.Errorf("Failed waiting for volume %q to change its size: %w", vol.name, err)
+	}
+
+	if vol.contentType == ContentTypeFS {
+		// Grow the filesystem to fill block device.
+		return growFileSystem(vol.ConfigBlockFilesystem(), devPath, vol)
+	}
+
+	// Move the VM GPT alt header to end of disk if needed (not needed in unsafe resize mode as it is
+	// expected the caller will do all necessary post resize actions themselves).
+	if vol.IsVMBlock() && !allowUnsafeResize {
+		err = d.moveGPTAltHeader(devPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateVolumeSnapshot creates a snapshot of a volume.
+func (d *san) CreateVolumeSnapshot(snapVol Volume, progressReporter ioprogress.ProgressReporter) error {
+	return d.createVolumeSnapshot(snapVol, true, progressReporter)
+}
+
+// createVolumeSnapshot creates a snapshot of a volume. If snapshotVMfilesystem is false, a VM's filesystem volume
+// is not copied.
+func (d *san) createVolumeSnapshot(snapVol Volume, snapshotVMfilesystem bool, progressReporter ioprogress.ProgressReporter) error {
+	revert := revert.New()
+	defer revert.Fail()
+
+	parentName, _, _ := api.GetParentAndSnapshotName(snapVol.name)
+	sourcePath := GetVolumeMountPath(d.name, snapVol.volType, parentName)
+
+	if filesystem.IsMountPoint(sourcePath) {
+		// Attempt to sync and freeze filesystem, but do not error if not able to freeze (as filesystem
+		// could still be busy), as we do not guarantee the consistency of a snapshot. This is costly but
+		// try to ensure that all cached data has been committed to disk. If we don't then the snapshot
+		// of the underlying filesystem can be inconsistent or, in the worst case, empty.
+		unfreezeFS, err := d.filesystemFreeze(sourcePath)
+		if err == nil {
+			defer func() {
+				err := unfreezeFS()
+				if err != nil {
+					d.logger.Warn("unfreezeFS failed on error path", logger.Ctx{"err": err})
+				}
+			}()
+		}
+	}
+
+	// Create the parent directory.
+	err := createParentSnapshotDirIfMissing(d.name, snapVol.volType, parentName)
+	if err != nil {
+		return err
+	}
+
+	err = snapVol.EnsureMountPath()
+	if err != nil {
+		return err
+	}
+
+	volName, snapName, err := d.getSourceName(snapVol)
+	if err != nil {
+		return err
+	}
+
+	err = d.client().CreateVolumeSnapshot(d.state.ShutdownCtx, volName, snapName)
+	if err != nil {
+		return err
+	}
+
+	revert.Add(func() {
+		err := d.DeleteVolumeSnapshot(snapVol, progressReporter)
+		if err != nil {
+			d.logger.Warn("DeleteVolumeSnapshot failed on error path", logger.Ctx{"err": err})
+		}
+	})
+
+	// For VMs, create a snapshot of the filesystem volume too.
+	// Skip if snapshotVMfilesystem is false to prevent overwriting separately copied volumes.
+	if snapVol.IsVMBlock() && snapshotVMfilesystem {
+		fsVol := snapVol.NewVMBlockFilesystemVolume()
+
+		err := d.CreateVolumeSnapshot(fsVol, progressReporter)
+		if err != nil {
+			return err
+		}
+
+		revert.Add(func() {
+			err := d.DeleteVolumeSnapshot(fsVol, progressReporter)
+			if err != nil {
+				d.logger.Warn("DeleteVolumeSnapshot failed on error path", logger.Ctx{"err": err})
+			}
+		})
+	}
+
+	revert.Success()
+	return nil
+}
+
+// DeleteVolumeSnapshot removes a snapshot from the storage device. The volName and snapshotName
+// must be bare names and should not be in the format "volume/snapshot".
+func (d *san) DeleteVolumeSnapshot(snapVol Volume, progressReporter ioprogress.ProgressReporter) error {
+	volName, snapName, err := d.getSourceName(snapVol)
+	if err != nil {
+		return err
+	}
+
+	// Delete snapshot.
+	err = d.client().DeleteVolumeSnapshot(d.state.ShutdownCtx, volName, snapName)
+	if err != nil {
+		return err
+	}
+
+	mountPath := snapVol.MountPath()
+
+	if snapVol.contentType == ContentTypeFS {
+		err = wipeDirectory(mountPath)
+		if err != nil {
+			return err
+		}
+
+		err = os.Remove(mountPath)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed removing %q: %w", mountPath, err)
+		}
+	}
+
+	// Remove the parent snapshot directory if this is the last snapshot being removed.
+	parentName, _, _ := api.GetParentAndSnapshotName(snapVol.name)
+	err = deleteParentSnapshotDirIfEmpty(d.name, snapVol.volType, parentName)
+	if err != nil {
+		return err
+	}
+
+	// For VM images, delete the filesystem volume too.
+	if snapVol.IsVMBlock() {
+		fsVol := snapVol.NewVMBlockFilesystemVolume()
+
+		err := d.DeleteVolumeSnapshot(fsVol, progressReporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RenameVolumeSnapshot renames a volume snapshot.
+func (d *san) RenameVolumeSnapshot(snapVol Volume, newSnapshotName string, progressReporter ioprogress.ProgressReporter) error {
+	// Renaming a volume snapshot won't change an actual name of the snapshot on the storage host.
+	return nil
+}
+
+// MountVolumeSnapshot mounts a writable clone of the snapshot.
+func (d *san) MountVolumeSnapshot(snapVol Volume, progressReporter ioprogress.ProgressReporter) error {
+	return mountVolume(d, snapVol, d.getMappedDevPath, progressReporter)
+}
+
+// UnmountVolumeSnapshot unmounts the clone of a snapshot and removes it.
+func (d *san) UnmountVolumeSnapshot(snapVol Volume, progressReporter ioprogress.ProgressReporter) (bool, error) {
+	return unmountVolume(d, snapVol, false, d.getMappedDevPath, d.unmapVolume, progressReporter)
+}
+
+// ListVolumes returns a list of LXD volumes in storage pool.
+func (d *san) ListVolumes() ([]Volume, error) {
+	// The original volume names are not stored on the storage host as
+	// UUID-based generated names are used (see getVolumeName() method).
+	return []Volume{}, nil
+}
+
+// VolumeSnapshots returns a list of snapshot names for the given volume (in no particular order).
+func (d *san) VolumeSnapshots(vol Volume) ([]string, error) {
+	volName, err := d.getVolumeName(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.client().GetVolumeSnapshots(d.state.ShutdownCtx, volName)
+}
+
+// CheckVolumeSnapshots checks that the volume's snapshots, according to the storage driver,
+// match those provided.
+func (d *san) CheckVolumeSnapshots(vol Volume, snapVols []Volume) error {
+	storageSnapshotNames, err := vol.driver.VolumeSnapshots(vol)
+	if err != nil {
+		return err
+	}
+
+	// Check if the provided list of volume snapshots matches the ones from the storage.
+	for _, snap := range snapVols {
+		snapName, err := d.getVolumeName(snap)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Contains(storageSnapshotNames, snapName) {
+			return fmt.Errorf("Snapshot %q expected but not in storage", snapName)
+		}
+	}
+
+	return nil
+}
+
+// RestoreVolume restores a volume from a snapshot.
+func (d *san) RestoreVolume(vol Volume, snapVol Volume, progressReporter ioprogress.ProgressReporter) error {
+	ourUnmount, err := d.UnmountVolume(vol, false, progressReporter)
+	if err != nil {
+		return err
+	}
+
+	if ourUnmount {
+		defer func() {
+			err := d.MountVolume(vol, progressReporter)
+			if err != nil {
+				d.logger.Warn("MountVolume failed on error path", logger.Ctx{"err": err})
+			}
+		}()
+	}
+
+	volName, snapName, err := d.getSourceName(snapVol)
+	if err != nil {
+		return err
+	}
+
+	// Overwrite existing volume by copying the given snapshot content into it.
+	// This keeps any newer snapshots of the volume in place.
+	err = d.client().CopyVolume(d.state.ShutdownCtx, volName, snapName, volName)
+	if err != nil {
+		return err
+	}
+
+	// For VMs, also restore the filesystem volume.
+	if vol.IsVMBlock() {
+		fsVol := vol.NewVMBlockFilesystemVolume()
+
+		snapFSVol := snapVol.NewVMBlockFilesystemVolume()
+
+		err := d.RestoreVolume(fsVol, snapFSVol, progressReporter)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -54,7 +54,7 @@ func (d *zfs) load() error {
 		"storage_delete_old_snapshot_records":                nil,
 		"storage_zfs_drop_block_volume_filesystem_extension": d.patchDropBlockVolumeFilesystemExtension,
 		"storage_prefix_bucket_names_with_project":           nil,
-		"storage_zfs_remove_local_bucket_datasets":           d.patchRemoveLocalBucketDatasets,
+		"storage_zfs_remove_local_bucket_datasets":           nil, // Local storage buckets use "<pool>/buckets" again.
 	}
 
 	// Done if previously loaded.
@@ -145,12 +145,12 @@ func (d *zfs) Info() Info {
 		OptimizedBackups:             true,
 		PreservesInodes:              true,
 		Remote:                       d.isRemote(),
-		VolumeTypes:                  []VolumeType{VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
+		VolumeTypes:                  []VolumeType{VolumeTypeBucket, VolumeTypeCustom, VolumeTypeImage, VolumeTypeContainer, VolumeTypeVM},
 		BlockBacking:                 shared.IsTrue(d.config["volume.zfs.block_mode"]),
 		RunningCopyFreeze:            false,
 		DirectIO:                     zfsDirectIO,
 		MountedRoot:                  false,
-		Buckets:                      false,
+		Buckets:                      true,
 		PopulateParentVolumeUUID:     false,
 	}
 
@@ -811,31 +811,6 @@ func (d *zfs) patchDropBlockVolumeFilesystemExtension() error {
 	return nil
 }
 
-// patchRemoveLocalBucketDatasets removes orphaned ZFS datasets left over from before local storage bucket support was
-// dropped. Affected datasets are "<pool>/buckets" and "<pool>/deleted/buckets".
-func (d *zfs) patchRemoveLocalBucketDatasets() error {
-	poolName, ok := d.config["zfs.pool_name"]
-	if !ok {
-		poolName = d.name
-	}
-
-	// Remove deepest-first to avoid dependency conflicts.
-	for _, dataset := range []string{poolName + "/deleted/buckets", poolName + "/buckets"} {
-		_, err := shared.RunCommand(d.state.ShutdownCtx, "zfs", "list", "-H", "-o", "name", dataset)
-		if err != nil {
-			// Dataset does not exist; nothing to do.
-			continue
-		}
-
-		_, err = shared.RunCommand(d.state.ShutdownCtx, "zfs", "destroy", "-r", dataset)
-		if err != nil {
-			return fmt.Errorf("Failed removing ZFS bucket dataset %q: %w", dataset, err)
-		}
-	}
-
-	return nil
-}
-
 // roundVolumeBlockSizeBytes returns sizeBytes rounded up to the next multiple
 // of `vol`'s "zfs.blocksize".
 func (d *zfs) roundVolumeBlockSizeBytes(vol Volume, sizeBytes int64) int64 {
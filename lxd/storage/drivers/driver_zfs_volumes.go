@@ -1094,7 +1094,55 @@ func (d *zfs) CreateVolumeFromMigration(vol VolumeCopy, conn io.ReadWriteCloser,
 		//
 		// We therefore need to check the snapshots, and delete all target snapshots if the above
 		// scenario is true.
-		if !volumeOnly && len(respSnapshots) > 0 && len(migrationHeader.SnapshotDatasets) > 0 && respSnapshots[0].GUID != migrationHeader.SnapshotDatasets[0].GUID {
+		//
+		// When only refreshing from the last common snapshot, the older snapshots are left alone on both
+		// sides and the first snapshot is sent incrementally from the last common one instead.
+		lastCommonSource, lastCommonTarget := -1, -1
+		if volTargetArgs.RefreshExcludeOlder {
+			for i := len(migrationHeader.SnapshotDatasets) - 1; i >= 0 && lastCommonSource < 0; i-- {
+				for j, dstSnapshot := range respSnapshots {
+					if migrationHeader.SnapshotDatasets[i].GUID == dstSnapshot.GUID {
+						lastCommonSource = i
+						lastCommonTarget = j
+						break
+					}
+				}
+			}
+		}
+
+		if lastCommonSource >= 0 {
+			syncSnapshotNames = []string{}
+
+			for i, srcSnapshot := range migrationHeader.SnapshotDatasets {
+				found := slices.ContainsFunc(respSnapshots, func(dstSnapshot ZFSDataset) bool {
+					return srcSnapshot.GUID == dstSnapshot.GUID
+				})
+
+				if found {
+					continue
+				}
+
+				if i < lastCommonSource {
+					// Let the source know that the older snapshots don't need to be sent.
+					respSnapshots = append(respSnapshots, srcSnapshot)
+					continue
+				}
+
+				syncSnapshotNames = append(syncSnapshotNames, srcSnapshot.Name)
+			}
+
+			// Delete local snapshots taken after the last common one which don't exist on the source.
+			for _, snapVol := range snapshots[lastCommonTarget+1:] {
+				_, snapName, _ := api.GetParentAndSnapshotName(snapVol.name)
+
+				if !slices.ContainsFunc(migrationHeader.SnapshotDatasets, func(srcSnapshot ZFSDataset) bool { return srcSnapshot.Name == snapName }) {
+					err = d.DeleteVolume(snapVol, progressReporter)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		} else if !volumeOnly && len(respSnapshots) > 0 && len(migrationHeader.SnapshotDatasets) > 0 && respSnapshots[0].GUID != migrationHeader.SnapshotDatasets[0].GUID {
 			for _, snapVol := range snapshots {
 				// Delete
 				err = d.DeleteVolume(snapVol, progressReporter)
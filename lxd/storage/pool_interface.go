@@ -115,6 +115,8 @@ type Pool interface {
 	UpdateBucketKey(projectName string, bucketName string, keyName string, key api.StorageBucketKeyPut) error
 	DeleteBucketKey(projectName string, bucketName string, keyName string) error
 	GetBucketURL(bucketName string) *url.URL
+	MountBucket(projectName string, bucketName string) (string, error)
+	UnmountBucket(projectName string, bucketName string) error
 
 	// Custom volumes.
 	CreateCustomVolume(ctx context.Context, projectName string, volName string, desc string, config map[string]string, contentType drivers.ContentType, progressReporter ioprogress.ProgressReporter) error
@@ -0,0 +1,376 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signV4Algorithm      = "AWS4-HMAC-SHA256"
+	signV4ChunkAlgorithm = "AWS4-HMAC-SHA256-PAYLOAD"
+	signV4Request        = "aws4_request"
+	signV4Service        = "s3"
+
+	emptySHA256                     = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	unsignedPayload                 = "UNSIGNED-PAYLOAD"
+	streamingPayload                = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+	streamingPayloadTrailer         = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD-TRAILER"
+	streamingUnsignedPayloadTrailer = "STREAMING-UNSIGNED-PAYLOAD-TRAILER"
+
+	iso8601Format  = "20060102T150405Z"
+	yyyymmddFormat = "20060102"
+
+	// maxRequestSkew is the maximum difference between the time of a signed request and the server time.
+	maxRequestSkew = 15 * time.Minute
+
+	// maxPresignedExpiry is the maximum validity of a presigned URL.
+	maxPresignedExpiry = 7 * 24 * time.Hour
+)
+
+// signature represents the AWS Signature Version 4 of a request.
+type signature struct {
+	accessKey     string
+	date          time.Time
+	scope         string
+	signedHeaders []string
+	signature     string
+	payloadHash   string
+	presigned     bool
+	expires       time.Duration
+
+	// Set once the signature is verified, to verify the signatures of payload chunks.
+	signingKey []byte
+}
+
+// parseSignature extracts the AWS Signature Version 4 of a request, either from the Authorization header or from
+// the query string of a presigned URL.
+func parseSignature(r *http.Request) (*signature, error) {
+	authorization := r.Header.Get("Authorization")
+	query := r.URL.Query()
+
+	switch {
+	case strings.HasPrefix(authorization, signV4Algorithm+" "):
+		return parseSignatureHeader(r, strings.TrimPrefix(authorization, signV4Algorithm+" "))
+	case query.Get("X-Amz-Algorithm") == signV4Algorithm:
+		return parseSignatureQuery(query)
+	case authorization != "" || query.Has("AWSAccessKeyId"):
+		return nil, newError(http.StatusBadRequest, "InvalidRequest", "The authorization mechanism you have provided is not supported. Please use AWS4-HMAC-SHA256.")
+	default:
+		// Anonymous requests aren't allowed.
+		return nil, errAccessDenied
+	}
+}
+
+// parseSignatureHeader parses the content of an AWS Signature Version 4 Authorization header.
+func parseSignatureHeader(r *http.Request, authorization string) (*signature, error) {
+	fields := map[string]string{}
+	for field := range strings.SplitSeq(authorization, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, errAuthorizationHeaderMalformed
+		}
+
+		fields[key] = value
+	}
+
+	sig := &signature{
+		signature:   fields["Signature"],
+		payloadHash: r.Header.Get("X-Amz-Content-Sha256"),
+	}
+
+	if sig.payloadHash == "" {
+		return nil, errMissingSecurityHeader
+	}
+
+	dateValue := r.Header.Get("X-Amz-Date")
+	if dateValue == "" {
+		// Fallback to the standard date header.
+		date, err := http.ParseTime(r.Header.Get("Date"))
+		if err != nil {
+			return nil, errMissingSecurityHeader
+		}
+
+		dateValue = date.UTC().Format(iso8601Format)
+	}
+
+	err := sig.parse(fields["Credential"], fields["SignedHeaders"], dateValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// parseSignatureQuery parses the AWS Signature Version 4 query parameters of a presigned URL.
+func parseSignatureQuery(query url.Values) (*signature, error) {
+	sig := &signature{
+		signature:   query.Get("X-Amz-Signature"),
+		payloadHash: query.Get("X-Amz-Content-Sha256"),
+		presigned:   true,
+	}
+
+	if sig.payloadHash == "" {
+		sig.payloadHash = unsignedPayload
+	}
+
+	expires, err := strconv.ParseInt(query.Get("X-Amz-Expires"), 10, 64)
+	if err != nil || expires < 0 || time.Duration(expires)*time.Second > maxPresignedExpiry {
+		return nil, newError(http.StatusBadRequest, "AuthorizationQueryParametersError", "X-Amz-Expires must be a number of seconds between 0 and 604800.")
+	}
+
+	sig.expires = time.Duration(expires) * time.Second
+
+	err = sig.parse(query.Get("X-Amz-Credential"), query.Get("X-Amz-SignedHeaders"), query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// parse parses the credential, signed headers and date elements of the signature.
+func (s *signature) parse(credential string, signedHeaders string, date string) error {
+	var err error
+
+	s.date, err = time.Parse(iso8601Format, date)
+	if err != nil {
+		return errMissingSecurityHeader
+	}
+
+	// The credential has the form "<access key>/<date>/<region>/<service>/aws4_request".
+	parts := strings.Split(credential, "/")
+	if len(parts) < 5 || s.signature == "" || signedHeaders == "" {
+		return errAuthorizationHeaderMalformed
+	}
+
+	scope := parts[len(parts)-4:]
+	if scope[0] != s.date.Format(yyyymmddFormat) || scope[2] != signV4Service || scope[3] != signV4Request {
+		return errAuthorizationHeaderMalformed
+	}
+
+	s.accessKey = strings.Join(parts[:len(parts)-4], "/")
+	s.scope = strings.Join(scope, "/")
+	s.signedHeaders = strings.Split(signedHeaders, ";")
+
+	if !slices.Contains(s.signedHeaders, "host") {
+		return errAuthorizationHeaderMalformed
+	}
+
+	return nil
+}
+
+// verify checks the signature of the request against the secret key.
+func (s *signature) verify(r *http.Request, secretKey string, now time.Time) error {
+	if s.presigned {
+		if now.Before(s.date.Add(-maxRequestSkew)) {
+			return errRequestTimeTooSkewed
+		}
+
+		if now.After(s.date.Add(s.expires)) {
+			return errExpiredToken
+		}
+	} else if now.Sub(s.date).Abs() > maxRequestSkew {
+		return errRequestTimeTooSkewed
+	}
+
+	scope := strings.Split(s.scope, "/")
+	signingKey := hmacSHA256([]byte("AWS4"+secretKey), scope[0])
+	for _, element := range scope[1:] {
+		signingKey = hmacSHA256(signingKey, element)
+	}
+
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		s.date.Format(iso8601Format),
+		s.scope,
+		sha256Hex([]byte(s.canonicalRequest(r))),
+	}, "\n")
+
+	expected := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	if !hmac.Equal([]byte(expected), []byte(s.signature)) {
+		return errSignatureDoesNotMatch
+	}
+
+	s.signingKey = signingKey
+
+	return nil
+}
+
+// canonicalRequest returns the canonical form of the request used to compute its signature.
+func (s *signature) canonicalRequest(r *http.Request) string {
+	// Canonical query string.
+	query := r.URL.Query()
+	if s.presigned {
+		query.Del("X-Amz-Signature")
+	}
+
+	queryKeys := make([]string, 0, len(query))
+	for key := range query {
+		queryKeys = append(queryKeys, key)
+	}
+
+	slices.SortFunc(queryKeys, func(a string, b string) int {
+		return strings.Compare(uriEncode(a, true), uriEncode(b, true))
+	})
+
+	queryParts := make([]string, 0, len(query))
+	for _, key := range queryKeys {
+		values := make([]string, 0, len(query[key]))
+		for _, value := range query[key] {
+			values = append(values, uriEncode(value, true))
+		}
+
+		slices.Sort(values)
+
+		for _, value := range values {
+			queryParts = append(queryParts, uriEncode(key, true)+"="+value)
+		}
+	}
+
+	// Canonical headers.
+	var headers strings.Builder
+	for _, name := range s.signedHeaders {
+		var values []string
+
+		switch name {
+		case "host":
+			values = []string{r.Host}
+		case "content-length":
+			values = slices.Clone(r.Header.Values(name))
+			if len(values) == 0 && r.ContentLength >= 0 {
+				values = []string{strconv.FormatInt(r.ContentLength, 10)}
+			}
+
+		default:
+			values = slices.Clone(r.Header.Values(name))
+		}
+
+		for i, value := range values {
+			values[i] = strings.Join(strings.Fields(value), " ")
+		}
+
+		headers.WriteString(name + ":" + strings.Join(values, ",") + "\n")
+	}
+
+	path := r.URL.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return strings.Join([]string{
+		r.Method,
+		uriEncode(path, false),
+		strings.Join(queryParts, "&"),
+		headers.String(),
+		strings.Join(s.signedHeaders, ";"),
+		s.payloadHash,
+	}, "\n")
+}
+
+// payloadReader returns a reader for the request payload that checks it against the signature.
+// It also returns the size of the payload, or -1 if unknown.
+func (s *signature) payloadReader(r *http.Request) (io.Reader, int64, error) {
+	switch s.payloadHash {
+	case unsignedPayload:
+		return r.Body, r.ContentLength, nil
+	case streamingPayload, streamingPayloadTrailer, streamingUnsignedPayloadTrailer:
+		size := int64(-1)
+
+		decodedLength := r.Header.Get("X-Amz-Decoded-Content-Length")
+		if decodedLength != "" {
+			var err error
+
+			size, err = strconv.ParseInt(decodedLength, 10, 64)
+			if err != nil || size < 0 {
+				return nil, -1, errInvalidArgument
+			}
+		}
+
+		reader := &chunkedReader{
+			reader:        bufio.NewReader(r.Body),
+			trailer:       s.payloadHash != streamingPayload,
+			signed:        s.payloadHash != streamingUnsignedPayloadTrailer,
+			signingKey:    s.signingKey,
+			date:          s.date.Format(iso8601Format),
+			scope:         s.scope,
+			prevSignature: s.signature,
+		}
+
+		return reader, size, nil
+	}
+
+	expected, err := hex.DecodeString(s.payloadHash)
+	if err != nil || len(expected) != sha256.Size {
+		return nil, -1, errContentSHA256Mismatch
+	}
+
+	return &hashReader{reader: r.Body, hash: sha256.New(), expected: expected, err: errContentSHA256Mismatch}, r.ContentLength, nil
+}
+
+// hashReader checks the hash of the data read from the reader once it's fully consumed.
+type hashReader struct {
+	reader   io.Reader
+	hash     hash.Hash
+	expected []byte
+	err      error
+}
+
+// Read implements io.Reader.
+func (h *hashReader) Read(p []byte) (int, error) {
+	n, err := h.reader.Read(p)
+	h.hash.Write(p[:n])
+
+	if errors.Is(err, io.EOF) && !hmac.Equal(h.hash.Sum(nil), h.expected) {
+		return n, h.err
+	}
+
+	return n, err
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data with the key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sha256Hex returns the hex encoded SHA256 of the data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// uriEncode encodes the value as specified for AWS Signature Version 4, where every byte except the unreserved
+// characters is percent-encoded. The "/" character is only encoded if encodeSlash is true.
+func uriEncode(value string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+
+	var b strings.Builder
+	for i := range len(value) {
+		c := value[i]
+
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexChars[c>>4])
+			b.WriteByte(hexChars[c&0x0f])
+		}
+	}
+
+	return b.String()
+}
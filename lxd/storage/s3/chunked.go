@@ -0,0 +1,149 @@
+package s3
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxChunkSize is the maximum size of a chunk of an aws-chunked payload.
+	maxChunkSize = 16 * 1024 * 1024
+
+	// maxChunkLineSize is the maximum size of the header and trailer lines of an aws-chunked payload.
+	maxChunkLineSize = 4096
+)
+
+// chunkedReader decodes an aws-chunked payload, as sent by clients streaming uploads with AWS Signature Version 4.
+// Each chunk is prefixed by its size and, for signed payloads, by a signature chaining it to the previous one.
+type chunkedReader struct {
+	reader  *bufio.Reader
+	trailer bool
+	signed  bool
+
+	signingKey    []byte
+	date          string
+	scope         string
+	prevSignature string
+
+	chunk []byte
+	data  []byte
+	err   error
+}
+
+// Read implements io.Reader.
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	for len(c.data) == 0 {
+		if c.err != nil {
+			return 0, c.err
+		}
+
+		c.err = c.readChunk()
+	}
+
+	n := copy(p, c.data)
+	c.data = c.data[n:]
+
+	return n, nil
+}
+
+// readChunk reads and checks the next chunk of the payload. It returns io.EOF after the final chunk.
+func (c *chunkedReader) readChunk() error {
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+
+	sizeValue, extension, _ := strings.Cut(line, ";")
+	size, err := strconv.ParseInt(sizeValue, 16, 64)
+	if err != nil || size < 0 || size > maxChunkSize {
+		return errIncompleteBody
+	}
+
+	if int64(cap(c.chunk)) < size {
+		c.chunk = make([]byte, size)
+	}
+
+	c.chunk = c.chunk[:size]
+
+	_, err = io.ReadFull(c.reader, c.chunk)
+	if err != nil {
+		return errIncompleteBody
+	}
+
+	if c.signed {
+		chunkSignature, found := strings.CutPrefix(extension, "chunk-signature=")
+		if !found {
+			return errSignatureDoesNotMatch
+		}
+
+		stringToSign := strings.Join([]string{
+			signV4ChunkAlgorithm,
+			c.date,
+			c.scope,
+			c.prevSignature,
+			emptySHA256,
+			sha256Hex(c.chunk),
+		}, "\n")
+
+		expected := hex.EncodeToString(hmacSHA256(c.signingKey, stringToSign))
+		if !hmac.Equal([]byte(expected), []byte(chunkSignature)) {
+			return errSignatureDoesNotMatch
+		}
+
+		c.prevSignature = chunkSignature
+	}
+
+	if size > 0 {
+		// Each chunk is terminated by an empty line.
+		line, err = c.readLine()
+		if err != nil || line != "" {
+			return errIncompleteBody
+		}
+
+		c.data = c.chunk
+
+		return nil
+	}
+
+	// The final chunk is followed by the optional trailing headers and an empty line.
+	// The trailing checksums aren't checked as the payload is already covered by TLS and, for signed payloads,
+	// by the chunk signatures.
+	for {
+		line, err = c.readLine()
+		if err != nil {
+			// Some clients don't terminate payloads without trailers with an empty line.
+			if errors.Is(err, io.EOF) && !c.trailer {
+				return io.EOF
+			}
+
+			return errIncompleteBody
+		}
+
+		if line == "" {
+			return io.EOF
+		}
+	}
+}
+
+// readLine reads a CRLF terminated line.
+func (c *chunkedReader) readLine() (string, error) {
+	line, err := c.reader.ReadSlice('\n')
+	if err != nil {
+		if errors.Is(err, bufio.ErrBufferFull) || len(line) > 0 {
+			return "", errIncompleteBody
+		}
+
+		return "", err
+	}
+
+	if len(line) > maxChunkLineSize || len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errIncompleteBody
+	}
+
+	return string(line[:len(line)-2]), nil
+}
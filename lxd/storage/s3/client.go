@@ -0,0 +1,356 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultRegion is the region used to sign the requests of clients without a region.
+	defaultRegion = "us-east-1"
+
+	// clientPartSize is the size of the first parts of the multipart uploads of a client.
+	clientPartSize = 16 * 1024 * 1024
+
+	// clientPartSizeStep is the number of parts after which the part size doubles, so that the largest objects
+	// still fit in the maximum number of parts.
+	clientPartSizeStep = 1000
+)
+
+// ObjectInfo holds the details of an object listed by a client.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Client is a client for S3-compatible object storage, sending path-style requests signed with AWS Signature
+// Version 4.
+type Client struct {
+	endpoint  *url.URL
+	region    string
+	accessKey string
+	secretKey string
+	http      *http.Client
+	now       func() time.Time
+	partSize  int
+}
+
+// NewClient returns a client for the S3 endpoint using the given credentials.
+// If httpClient is nil, http.DefaultClient is used.
+func NewClient(endpoint string, region string, accessKey string, secretKey string, httpClient *http.Client) (*Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid S3 endpoint %q: %w", endpoint, err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("Invalid S3 endpoint %q", endpoint)
+	}
+
+	if region == "" {
+		region = defaultRegion
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		endpoint:  u,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		http:      httpClient,
+		now:       time.Now,
+		partSize:  clientPartSize,
+	}, nil
+}
+
+// PutObject uploads the content of the reader to the object and returns its size.
+// The content is streamed using a multipart upload if it doesn't fit in a single part.
+func (c *Client) PutObject(ctx context.Context, bucket string, key string, reader io.Reader) (int64, error) {
+	buf := make([]byte, c.partSize)
+
+	n, err := io.ReadFull(reader, buf)
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		resp, err := c.request(ctx, http.MethodPut, bucket, key, nil, buf[:n])
+		if err != nil {
+			return -1, err
+		}
+
+		_ = resp.Body.Close()
+
+		return int64(n), nil
+	} else if err != nil {
+		return -1, err
+	}
+
+	// Start a multipart upload.
+	resp, err := c.request(ctx, http.MethodPost, bucket, key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	upload := initiateMultipartUploadResult{}
+
+	err = decodeXML(resp, &upload)
+	if err != nil {
+		return -1, err
+	}
+
+	size, err := c.putParts(ctx, bucket, key, upload.UploadID, buf[:n], reader)
+	if err != nil {
+		resp, abortErr := c.request(context.Background(), http.MethodDelete, bucket, key, url.Values{"uploadId": {upload.UploadID}}, nil)
+		if abortErr == nil {
+			_ = resp.Body.Close()
+		}
+
+		return -1, err
+	}
+
+	return size, nil
+}
+
+// putParts uploads the first part and the rest of the content of the reader as parts of the multipart upload,
+// and then completes the upload.
+func (c *Client) putParts(ctx context.Context, bucket string, key string, uploadID string, first []byte, reader io.Reader) (int64, error) {
+	var size int64
+	complete := completeMultipartUpload{}
+	part := first
+
+	for partNumber := 1; len(part) > 0; partNumber++ {
+		if partNumber > maxPartNumber {
+			return -1, errors.New("Object exceeds the maximum number of parts")
+		}
+
+		query := url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+
+		resp, err := c.request(ctx, http.MethodPut, bucket, key, query, part)
+		if err != nil {
+			return -1, err
+		}
+
+		_ = resp.Body.Close()
+
+		complete.Parts = append(complete.Parts, completedPart{PartNumber: partNumber, ETag: resp.Header.Get("ETag")})
+		size += int64(len(part))
+
+		// Read the next part.
+		buf := make([]byte, c.partSize<<(partNumber/clientPartSizeStep))
+
+		n, err := io.ReadFull(reader, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return -1, err
+		}
+
+		part = buf[:n]
+	}
+
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return -1, err
+	}
+
+	resp, err := c.request(ctx, http.MethodPost, bucket, key, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return -1, err
+	}
+
+	// Errors completing the upload may be reported with a successful status.
+	result := completeMultipartUploadResult{}
+
+	err = decodeXML(resp, &result)
+	if err != nil {
+		return -1, err
+	}
+
+	return size, nil
+}
+
+// GetObject returns a reader for the content of the object along with its size.
+func (c *Client) GetObject(ctx context.Context, bucket string, key string) (io.ReadCloser, int64, error) {
+	resp, err := c.request(ctx, http.MethodGet, bucket, key, nil, nil)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// StatObject returns the details of the object.
+func (c *Client) StatObject(ctx context.Context, bucket string, key string) (*ObjectInfo, error) {
+	resp, err := c.request(ctx, http.MethodHead, bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = resp.Body.Close()
+
+	info := &ObjectInfo{
+		Key:  key,
+		Size: resp.ContentLength,
+	}
+
+	info.LastModified, _ = http.ParseTime(resp.Header.Get("Last-Modified"))
+
+	return info, nil
+}
+
+// ListObjects returns the objects of the bucket whose key starts with the prefix.
+func (c *Client) ListObjects(ctx context.Context, bucket string, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+
+	for {
+		resp, err := c.request(ctx, http.MethodGet, bucket, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		result := listBucketResult{}
+
+		err = decodeXML(resp, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range result.Contents {
+			lastModified, _ := time.Parse(time.RFC3339Nano, entry.LastModified)
+			objects = append(objects, ObjectInfo{Key: entry.Key, Size: entry.Size, LastModified: lastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// DeleteObject deletes the object.
+func (c *Client) DeleteObject(ctx context.Context, bucket string, key string) error {
+	resp, err := c.request(ctx, http.MethodDelete, bucket, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	_ = resp.Body.Close()
+
+	return nil
+}
+
+// request sends a signed request for the object, or for the bucket if the key is empty.
+// Error responses are returned as an *Error.
+func (c *Client) request(ctx context.Context, method string, bucket string, key string, query url.Values, body []byte) (*http.Response, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+
+	// Encode the path and query as they are signed.
+	u.RawPath = uriEncode(u.Path, false)
+
+	queryParts := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			queryParts = append(queryParts, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+
+	slices.Sort(queryParts)
+	u.RawQuery = strings.Join(queryParts, "&")
+
+	r, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c.sign(r, sha256Hex(body))
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		defer func() { _ = resp.Body.Close() }()
+
+		s3Err := &Error{StatusCode: resp.StatusCode}
+
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if xml.Unmarshal(data, s3Err) != nil || s3Err.Code == "" {
+			s3Err.Code = strconv.Itoa(resp.StatusCode)
+			s3Err.Message = http.StatusText(resp.StatusCode)
+		}
+
+		return nil, s3Err
+	}
+
+	return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 of the request to its headers.
+func (c *Client) sign(r *http.Request, payloadHash string) {
+	now := c.now().UTC()
+
+	r.Header.Set("X-Amz-Date", now.Format(iso8601Format))
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	scope := []string{now.Format(yyyymmddFormat), c.region, signV4Service, signV4Request}
+	sig := &signature{
+		date:          now,
+		scope:         strings.Join(scope, "/"),
+		signedHeaders: []string{"host", "x-amz-content-sha256", "x-amz-date"},
+		payloadHash:   payloadHash,
+	}
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.secretKey), scope[0])
+	for _, element := range scope[1:] {
+		signingKey = hmacSHA256(signingKey, element)
+	}
+
+	stringToSign := strings.Join([]string{
+		signV4Algorithm,
+		now.Format(iso8601Format),
+		sig.scope,
+		sha256Hex([]byte(sig.canonicalRequest(r))),
+	}, "\n")
+
+	r.Header.Set("Authorization", signV4Algorithm+" Credential="+c.accessKey+"/"+sig.scope+",SignedHeaders="+strings.Join(sig.signedHeaders, ";")+",Signature="+hex.EncodeToString(hmacSHA256(signingKey, stringToSign)))
+}
+
+// decodeXML decodes the XML body of the response into the value and closes it.
+func decodeXML(resp *http.Response, value any) error {
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Some errors are reported with a successful status.
+	s3Err := &Error{StatusCode: resp.StatusCode}
+	if xml.Unmarshal(data, s3Err) == nil && s3Err.Code != "" {
+		return s3Err
+	}
+
+	err = xml.Unmarshal(data, value)
+	if err != nil {
+		return fmt.Errorf("Failed parsing S3 response: %w", err)
+	}
+
+	return nil
+}
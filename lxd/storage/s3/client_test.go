@@ -0,0 +1,216 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient returns a client for a test handler served over HTTP.
+func newTestClient(t *testing.T, accessKey string) *Client {
+	th := newTestHandler(t)
+
+	server := httptest.NewServer(th)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(server.URL, "", accessKey, accessKey+"-secret", server.Client())
+	require.NoError(t, err)
+
+	client.now = func() time.Time { return th.now }
+
+	return client
+}
+
+func TestClientObjects(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, "rw")
+
+	size, err := client.PutObject(ctx, "bucket1", "dir/small file+1", bytes.NewReader([]byte("hello")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+
+	// Content larger than a part is sent as a multipart upload.
+	client.partSize = minPartSize
+	large := bytes.Repeat([]byte("0123456789"), minPartSize/4)
+
+	size, err = client.PutObject(ctx, "bucket1", "dir/large", bytes.NewReader(large))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(large)), size)
+
+	reader, size, err := client.GetObject(ctx, "bucket1", "dir/large")
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(large)), size)
+
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	assert.Equal(t, large, data)
+
+	info, err := client.StatObject(ctx, "bucket1", "dir/small file+1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), info.Size)
+	assert.False(t, info.LastModified.IsZero())
+
+	objects, err := client.ListObjects(ctx, "bucket1", "dir/")
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	assert.Equal(t, "dir/large", objects[0].Key)
+	assert.Equal(t, "dir/small file+1", objects[1].Key)
+
+	require.NoError(t, client.DeleteObject(ctx, "bucket1", "dir/large"))
+
+	_, _, err = client.GetObject(ctx, "bucket1", "dir/large")
+	s3Err := &Error{}
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, http.StatusNotFound, s3Err.StatusCode)
+	assert.Equal(t, "NoSuchKey", s3Err.Code)
+}
+
+func TestClientErrors(t *testing.T) {
+	ctx := context.Background()
+
+	// Read-only keys can't upload, including as multipart uploads.
+	client := newTestClient(t, "ro")
+	client.partSize = minPartSize
+
+	_, err := client.PutObject(ctx, "bucket1", "large", bytes.NewReader(make([]byte, minPartSize+1)))
+	s3Err := &Error{}
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, "AccessDenied", s3Err.Code)
+
+	// Unknown access key.
+	client = newTestClient(t, "unknown")
+
+	_, err = client.ListObjects(ctx, "bucket1", "")
+	require.True(t, errors.As(err, &s3Err))
+	assert.Equal(t, "InvalidAccessKeyId", s3Err.Code)
+
+	_, err = NewClient("ftp://example.com", "", "rw", "rw-secret", nil)
+	assert.Error(t, err)
+}
+
+// s3Stub is an S3 endpoint stub that records the requests it receives and fails the upload of a given part.
+type s3Stub struct {
+	failPart int
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (s *s3Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RawQuery)
+	s.mu.Unlock()
+
+	query := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		_ = xml.NewEncoder(w).Encode(initiateMultipartUploadResult{Bucket: "bucket1", Key: "large", UploadID: "upload1"})
+	case r.Method == http.MethodPut && query.Get("partNumber") == strconv.Itoa(s.failPart):
+		http.Error(w, "Part upload failed", http.StatusInternalServerError)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		w.Header().Set("ETag", `"part`+query.Get("partNumber")+`"`)
+	case r.Method == http.MethodDelete && query.Get("uploadId") == "upload1":
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Unexpected request", http.StatusBadRequest)
+	}
+}
+
+// readerFunc is an [io.Reader] implemented by a function.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestClientPutObjectAbort(t *testing.T) {
+	tests := []struct {
+		name         string
+		size         int
+		failPart     int
+		cancel       bool
+		wantRequests []string
+	}{
+		{
+			name: "Read error",
+			size: minPartSize + 10,
+			wantRequests: []string{
+				"POST uploads=",
+				"PUT partNumber=1&uploadId=upload1",
+				"DELETE uploadId=upload1",
+			},
+		},
+		{
+			name:     "Part upload error",
+			size:     2*minPartSize + 10,
+			failPart: 2,
+			wantRequests: []string{
+				"POST uploads=",
+				"PUT partNumber=1&uploadId=upload1",
+				"PUT partNumber=2&uploadId=upload1",
+				"DELETE uploadId=upload1",
+			},
+		},
+		{
+			name:   "Cancelled upload",
+			size:   minPartSize + 10,
+			cancel: true,
+			wantRequests: []string{
+				"POST uploads=",
+				"PUT partNumber=1&uploadId=upload1",
+				"DELETE uploadId=upload1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &s3Stub{failPart: tt.failPart}
+
+			server := httptest.NewServer(stub)
+			defer server.Close()
+
+			client, err := NewClient(server.URL, "", "rw", "rw-secret", server.Client())
+			require.NoError(t, err)
+
+			client.partSize = minPartSize
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// The content is followed by a read error, cancelling the upload first if requested.
+			reader := io.MultiReader(bytes.NewReader(make([]byte, tt.size)), readerFunc(func(p []byte) (int, error) {
+				if tt.cancel {
+					cancel()
+					return 0, ctx.Err()
+				}
+
+				return 0, errors.New("Read failed")
+			}))
+
+			_, err = client.PutObject(ctx, "bucket1", "large", reader)
+			require.Error(t, err)
+
+			// The multipart upload is aborted rather than completed.
+			stub.mu.Lock()
+			defer stub.mu.Unlock()
+
+			assert.Equal(t, tt.wantRequests, stub.requests)
+		})
+	}
+}
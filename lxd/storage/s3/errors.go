@@ -0,0 +1,74 @@
+package s3
+
+import (
+	"encoding/xml"
+	"net/http"
+)
+
+// Error represents an S3 API error.
+type Error struct {
+	XMLName    xml.Name `xml:"Error"`
+	Code       string   `xml:"Code"`
+	Message    string   `xml:"Message"`
+	Resource   string   `xml:"Resource,omitempty"`
+	RequestID  string   `xml:"RequestId,omitempty"`
+	StatusCode int      `xml:"-"`
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// Response writes the error as an S3 XML error response.
+// The shared error values are left untouched as they may be used concurrently.
+func (e *Error) Response(w http.ResponseWriter, r *http.Request) {
+	resp := *e
+	resp.Resource = r.URL.Path
+	resp.RequestID = w.Header().Get("X-Amz-Request-Id")
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(resp.StatusCode)
+
+	// HEAD responses have no body.
+	if r.Method == http.MethodHead {
+		return
+	}
+
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(resp)
+}
+
+// newError returns a new S3 error.
+func newError(statusCode int, code string, message string) *Error {
+	return &Error{StatusCode: statusCode, Code: code, Message: message}
+}
+
+// Errors returned by the S3 API.
+var (
+	errAccessDenied                 = newError(http.StatusForbidden, "AccessDenied", "Access Denied.")
+	errAuthorizationHeaderMalformed = newError(http.StatusBadRequest, "AuthorizationHeaderMalformed", "The authorization header is malformed.")
+	errBadDigest                    = newError(http.StatusBadRequest, "BadDigest", "The Content-MD5 you specified did not match what was received.")
+	errBucketAlreadyOwnedByYou      = newError(http.StatusConflict, "BucketAlreadyOwnedByYou", "The bucket you tried to create already exists, and you own it.")
+	errContentSHA256Mismatch        = newError(http.StatusBadRequest, "XAmzContentSHA256Mismatch", "The provided 'x-amz-content-sha256' header does not match what was computed.")
+	errEntityTooLarge               = newError(http.StatusBadRequest, "EntityTooLarge", "Your proposed upload exceeds the space available in the bucket.")
+	errEntityTooSmall               = newError(http.StatusBadRequest, "EntityTooSmall", "Your proposed upload is smaller than the minimum allowed object size.")
+	errIncompleteBody               = newError(http.StatusBadRequest, "IncompleteBody", "You did not provide the number of bytes specified by the Content-Length HTTP header.")
+	errInternalError                = newError(http.StatusInternalServerError, "InternalError", "We encountered an internal error. Please try again.")
+	errInvalidAccessKeyID           = newError(http.StatusForbidden, "InvalidAccessKeyId", "The access key ID you provided does not exist in our records.")
+	errInvalidArgument              = newError(http.StatusBadRequest, "InvalidArgument", "Invalid argument.")
+	errInvalidDigest                = newError(http.StatusBadRequest, "InvalidDigest", "The Content-MD5 you specified is not valid.")
+	errInvalidPart                  = newError(http.StatusBadRequest, "InvalidPart", "One or more of the specified parts could not be found.")
+	errInvalidPartOrder             = newError(http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order.")
+	errInvalidRequest               = newError(http.StatusBadRequest, "InvalidRequest", "Invalid request.")
+	errKeyTooLong                   = newError(http.StatusBadRequest, "KeyTooLongError", "Your key is too long.")
+	errMalformedXML                 = newError(http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed or did not validate against our published schema.")
+	errMethodNotAllowed             = newError(http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+	errMissingSecurityHeader        = newError(http.StatusBadRequest, "MissingSecurityHeader", "Your request is missing a required header.")
+	errNoSuchKey                    = newError(http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+	errNoSuchUpload                 = newError(http.StatusNotFound, "NoSuchUpload", "The specified multipart upload does not exist.")
+	errNotImplemented               = newError(http.StatusNotImplemented, "NotImplemented", "A header or query you provided implies functionality that is not implemented.")
+	errRequestTimeTooSkewed         = newError(http.StatusForbidden, "RequestTimeTooSkewed", "The difference between the request time and the server's time is too large.")
+	errSignatureDoesNotMatch        = newError(http.StatusForbidden, "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided.")
+	errExpiredToken                 = newError(http.StatusForbidden, "AccessDenied", "Request has expired.")
+)
@@ -0,0 +1,844 @@
+// Package s3 implements a minimal S3 compatible API serving storage buckets from a local directory.
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+const (
+	// s3TimeFormat is the format of the timestamps in the S3 XML responses.
+	s3TimeFormat = "2006-01-02T15:04:05.000Z"
+
+	// maxXMLSize is the maximum size of the XML request bodies.
+	maxXMLSize = 2 * 1024 * 1024
+
+	// maxListKeys is the maximum number of keys returned by a list request.
+	maxListKeys = 1000
+)
+
+// unsupportedSubresources lists the S3 subresources that aren't implemented.
+var unsupportedSubresources = []string{
+	"accelerate", "acl", "analytics", "attributes", "cors", "encryption", "intelligent-tiering", "inventory",
+	"legal-hold", "lifecycle", "logging", "metrics", "notification", "object-lock", "ownershipControls", "policy",
+	"policyStatus", "publicAccessBlock", "replication", "requestPayment", "restore", "retention", "select", "tagging",
+	"torrent", "versions", "website",
+}
+
+// storedHeaders lists the headers stored along with an object and returned when it's retrieved.
+var storedHeaders = []string{"Cache-Control", "Content-Disposition", "Content-Encoding", "Content-Language", "Expires"}
+
+// Grant represents the access to a bucket granted by an access key.
+type Grant struct {
+	// SecretKey is the secret key used to sign the requests.
+	SecretKey string
+
+	// Bucket is the name of the bucket the access key gives access to.
+	Bucket string
+
+	// ReadOnly restricts the access to the retrieval of objects.
+	ReadOnly bool
+
+	// Mount makes the bucket content available and returns its path along with a function to release it.
+	Mount func() (string, func(), error)
+}
+
+// Handler serves the S3 API for buckets stored in local directories.
+type Handler struct {
+	lookup func(ctx context.Context, accessKey string) (*Grant, error)
+	mu     sync.Mutex
+	now    func() time.Time
+}
+
+// NewHandler returns a new S3 API handler using lookup to find the grant of an access key.
+// The lookup function should return an api.StatusError with http.StatusNotFound if the access key doesn't exist.
+func NewHandler(lookup func(ctx context.Context, accessKey string) (*Grant, error)) *Handler {
+	return &Handler{
+		lookup: lookup,
+		now:    time.Now,
+	}
+}
+
+// request holds the details of an authenticated S3 request.
+type request struct {
+	*http.Request
+
+	signature *signature
+	grant     *Grant
+	store     *store
+	bucket    string
+	key       string
+	query     url.Values
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := make([]byte, 8)
+	_, _ = rand.Read(requestID)
+	w.Header().Set("X-Amz-Request-Id", strings.ToUpper(hex.EncodeToString(requestID)))
+
+	err := h.serve(w, r)
+	if err != nil {
+		s3Err := &Error{}
+		if !errors.As(err, &s3Err) {
+			logger.Warn("Failed serving S3 request", logger.Ctx{"method": r.Method, "path": r.URL.Path, "err": err})
+			s3Err = errInternalError
+		}
+
+		s3Err.Response(w, r)
+	}
+}
+
+// serve authenticates the request and dispatches it to the matching operation.
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) error {
+	sig, err := parseSignature(r)
+	if err != nil {
+		return err
+	}
+
+	grant, err := h.lookup(r.Context(), sig.accessKey)
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return errInvalidAccessKeyID
+		}
+
+		return err
+	}
+
+	err = sig.verify(r, grant.SecretKey, h.now())
+	if err != nil {
+		return err
+	}
+
+	// Only path-style requests are supported.
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != "" && bucket != grant.Bucket {
+		return errAccessDenied
+	}
+
+	if grant.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return errAccessDenied
+	}
+
+	query := r.URL.Query()
+	for name := range query {
+		if slices.Contains(unsupportedSubresources, name) {
+			return errNotImplemented
+		}
+	}
+
+	path, release, err := grant.Mount()
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	req := &request{
+		Request:   r,
+		signature: sig,
+		grant:     grant,
+		store:     newStore(path, &h.mu),
+		bucket:    bucket,
+		key:       key,
+		query:     query,
+	}
+
+	if bucket == "" {
+		if r.Method != http.MethodGet {
+			return errMethodNotAllowed
+		}
+
+		return h.listBuckets(w, req)
+	}
+
+	if key == "" {
+		return h.serveBucket(w, req)
+	}
+
+	err = validateKey(key)
+	if err != nil {
+		return err
+	}
+
+	return h.serveObject(w, req)
+}
+
+// serveBucket dispatches a bucket level request.
+func (h *Handler) serveBucket(w http.ResponseWriter, r *request) error {
+	switch r.Method {
+	case http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+		return nil
+	case http.MethodGet:
+		switch {
+		case r.query.Has("location"):
+			return writeXML(w, http.StatusOK, locationConstraint{})
+		case r.query.Has("versioning"):
+			return writeXML(w, http.StatusOK, versioningConfiguration{})
+		case r.query.Has("uploads"):
+			return errNotImplemented
+		case r.query.Get("list-type") == "2":
+			return h.listObjects(w, r, true)
+		default:
+			return h.listObjects(w, r, false)
+		}
+
+	case http.MethodPost:
+		if r.query.Has("delete") {
+			return h.deleteObjects(w, r)
+		}
+
+		return errNotImplemented
+	case http.MethodPut:
+		if len(r.query) > 0 {
+			return errNotImplemented
+		}
+
+		// Buckets are managed through the LXD API.
+		return errBucketAlreadyOwnedByYou
+	}
+
+	return errMethodNotAllowed
+}
+
+// serveObject dispatches an object level request.
+func (h *Handler) serveObject(w http.ResponseWriter, r *request) error {
+	uploadID := r.query.Get("uploadId")
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if uploadID != "" || r.query.Has("partNumber") {
+			return errNotImplemented
+		}
+
+		return h.getObject(w, r)
+	case http.MethodPut:
+		if r.Header.Get("X-Amz-Copy-Source") != "" {
+			if uploadID != "" {
+				return errNotImplemented
+			}
+
+			return h.copyObject(w, r)
+		}
+
+		if uploadID != "" {
+			return h.putPart(w, r, uploadID)
+		}
+
+		return h.putObject(w, r)
+	case http.MethodDelete:
+		if uploadID != "" {
+			err := r.store.abortUpload(uploadID, r.key)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := r.store.deleteObject(r.key)
+			if err != nil {
+				return err
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	case http.MethodPost:
+		if r.query.Has("uploads") {
+			return h.createUpload(w, r)
+		}
+
+		if uploadID != "" {
+			return h.completeUpload(w, r, uploadID)
+		}
+
+		return errNotImplemented
+	}
+
+	return errMethodNotAllowed
+}
+
+// listBuckets lists the buckets the access key gives access to.
+func (h *Handler) listBuckets(w http.ResponseWriter, r *request) error {
+	fi, err := os.Stat(r.store.path)
+	if err != nil {
+		return err
+	}
+
+	result := listAllMyBucketsResult{
+		Buckets: []bucketInfo{{Name: r.grant.Bucket, CreationDate: fi.ModTime().UTC().Format(s3TimeFormat)}},
+	}
+
+	return writeXML(w, http.StatusOK, result)
+}
+
+// listObjects lists the objects of the bucket, using the version 2 of the API if v2 is true.
+func (h *Handler) listObjects(w http.ResponseWriter, r *request, v2 bool) error {
+	prefix := r.query.Get("prefix")
+	delimiter := r.query.Get("delimiter")
+
+	encodingType := r.query.Get("encoding-type")
+	if encodingType != "" && encodingType != "url" {
+		return errInvalidArgument
+	}
+
+	encode := func(value string) string {
+		if encodingType == "" {
+			return value
+		}
+
+		return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+	}
+
+	maxKeys := maxListKeys
+	if r.query.Has("max-keys") {
+		var err error
+
+		maxKeys, err = strconv.Atoi(r.query.Get("max-keys"))
+		if err != nil || maxKeys < 0 {
+			return errInvalidArgument
+		}
+
+		maxKeys = min(maxKeys, maxListKeys)
+	}
+
+	// Objects up to and including the start key are skipped.
+	var start string
+	if v2 {
+		start = r.query.Get("start-after")
+
+		token := r.query.Get("continuation-token")
+		if token != "" {
+			decoded, err := base64.RawURLEncoding.DecodeString(token)
+			if err != nil {
+				return newError(http.StatusBadRequest, "InvalidArgument", "The continuation token provided is incorrect.")
+			}
+
+			start = max(start, string(decoded))
+		}
+	} else {
+		start = r.query.Get("marker")
+	}
+
+	objects, err := r.store.listObjects(prefix)
+	if err != nil {
+		return err
+	}
+
+	var contents []objectEntry
+	var commonPrefixes []commonPrefix
+	var lastKey string
+	truncated := false
+
+	for _, object := range objects {
+		if object.Key <= start {
+			continue
+		}
+
+		entry := object.Key
+		isPrefix := false
+
+		if delimiter != "" {
+			index := strings.Index(object.Key[len(prefix):], delimiter)
+			if index >= 0 {
+				entry = object.Key[:len(prefix)+index+len(delimiter)]
+				isPrefix = true
+
+				// Keys under an already listed common prefix are skipped.
+				if entry <= start || entry == lastKey {
+					continue
+				}
+			}
+		}
+
+		if len(contents)+len(commonPrefixes) >= maxKeys {
+			truncated = true
+			break
+		}
+
+		lastKey = entry
+
+		if isPrefix {
+			commonPrefixes = append(commonPrefixes, commonPrefix{Prefix: encode(entry)})
+			continue
+		}
+
+		contents = append(contents, objectEntry{
+			Key:          encode(object.Key),
+			LastModified: object.LastModified.Format(s3TimeFormat),
+			ETag:         `"` + object.ETag + `"`,
+			Size:         object.Size,
+			StorageClass: "STANDARD",
+		})
+	}
+
+	result := listBucketResult{
+		Name:           r.bucket,
+		Prefix:         encode(prefix),
+		Delimiter:      encode(delimiter),
+		MaxKeys:        maxKeys,
+		EncodingType:   encodingType,
+		IsTruncated:    truncated,
+		Contents:       contents,
+		CommonPrefixes: commonPrefixes,
+	}
+
+	if v2 {
+		keyCount := len(contents) + len(commonPrefixes)
+		result.KeyCount = &keyCount
+		result.StartAfter = encode(r.query.Get("start-after"))
+		result.ContinuationToken = r.query.Get("continuation-token")
+
+		if truncated {
+			result.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(lastKey))
+		}
+	} else {
+		result.Marker = encode(r.query.Get("marker"))
+
+		if truncated {
+			result.NextMarker = encode(lastKey)
+		}
+	}
+
+	return writeXML(w, http.StatusOK, result)
+}
+
+// getObject retrieves an object or its metadata.
+func (h *Handler) getObject(w http.ResponseWriter, r *request) error {
+	f, info, err := r.store.getObject(r.key)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	setObjectHeaders(w, info)
+
+	// Allow the response headers to be overridden, typically by presigned URLs.
+	for _, name := range append([]string{"Content-Type"}, storedHeaders...) {
+		value := r.query.Get("response-" + strings.ToLower(name))
+		if value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+
+	http.ServeContent(w, r.Request, "", info.LastModified, f)
+
+	return nil
+}
+
+// putObject stores an object.
+func (h *Handler) putObject(w http.ResponseWriter, r *request) error {
+	payload, size, digest, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	info, err := r.store.putObject(r.objectInfo(), payload, size, digest)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", `"`+info.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// copyObject copies an object within the bucket.
+func (h *Handler) copyObject(w http.ResponseWriter, r *request) error {
+	source := r.Header.Get("X-Amz-Copy-Source")
+	source, versionID, _ := strings.Cut(source, "?versionId=")
+	if versionID != "" && versionID != "null" {
+		return errNotImplemented
+	}
+
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return errInvalidArgument
+	}
+
+	srcBucket, srcKey, _ := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if srcBucket != r.grant.Bucket {
+		return errAccessDenied
+	}
+
+	err = validateKey(srcKey)
+	if err != nil {
+		return err
+	}
+
+	var replaceMetadata bool
+	switch r.Header.Get("X-Amz-Metadata-Directive") {
+	case "", "COPY":
+		replaceMetadata = false
+	case "REPLACE":
+		replaceMetadata = true
+	default:
+		return errInvalidArgument
+	}
+
+	info, err := r.store.copyObject(srcKey, r.objectInfo(), replaceMetadata)
+	if err != nil {
+		return err
+	}
+
+	return writeXML(w, http.StatusOK, copyObjectResult{
+		ETag:         `"` + info.ETag + `"`,
+		LastModified: info.LastModified.Format(s3TimeFormat),
+	})
+}
+
+// deleteObjects deletes multiple objects.
+func (h *Handler) deleteObjects(w http.ResponseWriter, r *request) error {
+	req := deleteRequest{}
+	err := r.readXML(&req)
+	if err != nil {
+		return err
+	}
+
+	if len(req.Objects) == 0 || len(req.Objects) > maxListKeys {
+		return errMalformedXML
+	}
+
+	result := deleteResult{}
+	for _, object := range req.Objects {
+		err := validateKey(object.Key)
+		if err == nil {
+			err = r.store.deleteObject(object.Key)
+		}
+
+		if err != nil {
+			s3Err := &Error{}
+			if !errors.As(err, &s3Err) {
+				logger.Warn("Failed deleting S3 object", logger.Ctx{"bucket": r.bucket, "key": object.Key, "err": err})
+				s3Err = errInternalError
+			}
+
+			result.Errors = append(result.Errors, deleteError{Key: object.Key, Code: s3Err.Code, Message: s3Err.Message})
+			continue
+		}
+
+		if !req.Quiet {
+			result.Deleted = append(result.Deleted, deletedObject{Key: object.Key})
+		}
+	}
+
+	return writeXML(w, http.StatusOK, result)
+}
+
+// createUpload starts a multipart upload.
+func (h *Handler) createUpload(w http.ResponseWriter, r *request) error {
+	uploadID, err := r.store.createUpload(r.objectInfo())
+	if err != nil {
+		return err
+	}
+
+	return writeXML(w, http.StatusOK, initiateMultipartUploadResult{
+		Bucket:   r.bucket,
+		Key:      r.key,
+		UploadID: uploadID,
+	})
+}
+
+// putPart stores a part of a multipart upload.
+func (h *Handler) putPart(w http.ResponseWriter, r *request, uploadID string) error {
+	partNumber, err := strconv.Atoi(r.query.Get("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		return newError(http.StatusBadRequest, "InvalidArgument", "Part number must be an integer between 1 and 10000, inclusive.")
+	}
+
+	payload, size, digest, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	part, err := r.store.putPart(uploadID, r.key, partNumber, payload, size, digest)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("ETag", `"`+part.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// completeUpload assembles the parts of a multipart upload into an object.
+func (h *Handler) completeUpload(w http.ResponseWriter, r *request, uploadID string) error {
+	req := completeMultipartUpload{}
+	err := r.readXML(&req)
+	if err != nil {
+		return err
+	}
+
+	parts := make([]partInfo, 0, len(req.Parts))
+	for _, part := range req.Parts {
+		parts = append(parts, partInfo{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+
+	info, err := r.store.completeUpload(uploadID, r.key, parts)
+	if err != nil {
+		return err
+	}
+
+	return writeXML(w, http.StatusOK, completeMultipartUploadResult{
+		Location: "/" + r.bucket + "/" + r.key,
+		Bucket:   r.bucket,
+		Key:      r.key,
+		ETag:     `"` + info.ETag + `"`,
+	})
+}
+
+// payload returns a reader for the verified request payload, along with its size (or -1 if unknown) and the
+// expected MD5 from the Content-MD5 header (or nil if not set).
+func (r *request) payload() (io.Reader, int64, []byte, error) {
+	var digest []byte
+
+	contentMD5 := r.Header.Get("Content-MD5")
+	if contentMD5 != "" {
+		var err error
+
+		digest, err = base64.StdEncoding.DecodeString(contentMD5)
+		if err != nil || len(digest) != md5.Size {
+			return nil, -1, nil, errInvalidDigest
+		}
+	}
+
+	reader, size, err := r.signature.payloadReader(r.Request)
+	if err != nil {
+		return nil, -1, nil, err
+	}
+
+	return reader, size, digest, nil
+}
+
+// readXML reads and decodes the XML request payload into value.
+func (r *request) readXML(value any) error {
+	payload, _, digest, err := r.payload()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(payload, maxXMLSize+1))
+	if err != nil {
+		return err
+	}
+
+	if len(data) > maxXMLSize {
+		return errMalformedXML
+	}
+
+	if digest != nil {
+		sum := md5.Sum(data)
+		if !slices.Equal(sum[:], digest) {
+			return errBadDigest
+		}
+	}
+
+	err = xml.Unmarshal(data, value)
+	if err != nil {
+		return errMalformedXML
+	}
+
+	return nil
+}
+
+// objectInfo returns the metadata of the object from the request headers.
+func (r *request) objectInfo() objectInfo {
+	info := objectInfo{
+		Key:         r.key,
+		ContentType: r.Header.Get("Content-Type"),
+	}
+
+	for _, name := range storedHeaders {
+		value := r.Header.Get(name)
+
+		// The aws-chunked encoding only applies to the request payload.
+		if name == "Content-Encoding" {
+			var encodings []string
+			for encoding := range strings.SplitSeq(value, ",") {
+				encoding = strings.TrimSpace(encoding)
+				if encoding != "" && encoding != "aws-chunked" {
+					encodings = append(encodings, encoding)
+				}
+			}
+
+			value = strings.Join(encodings, ",")
+		}
+
+		if value != "" {
+			if info.Headers == nil {
+				info.Headers = map[string]string{}
+			}
+
+			info.Headers[name] = value
+		}
+	}
+
+	for name, values := range r.Header {
+		metadataName, found := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-")
+		if found && len(values) > 0 {
+			if info.Metadata == nil {
+				info.Metadata = map[string]string{}
+			}
+
+			info.Metadata[metadataName] = values[0]
+		}
+	}
+
+	return info
+}
+
+// setObjectHeaders sets the response headers describing an object.
+func setObjectHeaders(w http.ResponseWriter, info *objectInfo) {
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "binary/octet-stream"
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+info.ETag+`"`)
+
+	for name, value := range info.Headers {
+		w.Header().Set(name, value)
+	}
+
+	for name, value := range info.Metadata {
+		w.Header().Set("X-Amz-Meta-"+name, value)
+	}
+}
+
+// writeXML writes the value as an XML response.
+func writeXML(w http.ResponseWriter, statusCode int, value any) error {
+	data, err := xml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(data)
+
+	return nil
+}
+
+type locationConstraint struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ LocationConstraint"`
+}
+
+type versioningConfiguration struct {
+	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ VersioningConfiguration"`
+}
+
+type bucketInfo struct {
+	Name         string `xml:"Name"`
+	CreationDate string `xml:"CreationDate"`
+}
+
+type listAllMyBucketsResult struct {
+	XMLName xml.Name     `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListAllMyBucketsResult"`
+	Buckets []bucketInfo `xml:"Buckets>Bucket"`
+}
+
+type objectEntry struct {
+	Key          string `xml:"Key"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+type commonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+type listBucketResult struct {
+	XMLName               xml.Name       `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListBucketResult"`
+	Name                  string         `xml:"Name"`
+	Prefix                string         `xml:"Prefix"`
+	Delimiter             string         `xml:"Delimiter,omitempty"`
+	Marker                string         `xml:"Marker,omitempty"`
+	NextMarker            string         `xml:"NextMarker,omitempty"`
+	StartAfter            string         `xml:"StartAfter,omitempty"`
+	ContinuationToken     string         `xml:"ContinuationToken,omitempty"`
+	NextContinuationToken string         `xml:"NextContinuationToken,omitempty"`
+	KeyCount              *int           `xml:"KeyCount"`
+	MaxKeys               int            `xml:"MaxKeys"`
+	EncodingType          string         `xml:"EncodingType,omitempty"`
+	IsTruncated           bool           `xml:"IsTruncated"`
+	Contents              []objectEntry  `xml:"Contents"`
+	CommonPrefixes        []commonPrefix `xml:"CommonPrefixes"`
+}
+
+type copyObjectResult struct {
+	XMLName      xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CopyObjectResult"`
+	ETag         string   `xml:"ETag"`
+	LastModified string   `xml:"LastModified"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name `xml:"Delete"`
+	Quiet   bool     `xml:"Quiet"`
+	Objects []struct {
+		Key string `xml:"Key"`
+	} `xml:"Object"`
+}
+
+type deletedObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+type deleteResult struct {
+	XMLName xml.Name        `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteResult"`
+	Deleted []deletedObject `xml:"Deleted"`
+	Errors  []deleteError   `xml:"Error"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completeMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
@@ -0,0 +1,288 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// testHandler is an S3 handler serving a single bucket from a temporary directory.
+type testHandler struct {
+	*Handler
+
+	t    *testing.T
+	path string
+	now  time.Time
+}
+
+func newTestHandler(t *testing.T) *testHandler {
+	th := &testHandler{
+		t:    t,
+		path: t.TempDir(),
+		now:  time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	th.Handler = NewHandler(func(ctx context.Context, accessKey string) (*Grant, error) {
+		if accessKey != "rw" && accessKey != "ro" {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Storage bucket key not found")
+		}
+
+		return &Grant{
+			SecretKey: accessKey + "-secret",
+			Bucket:    "bucket1",
+			ReadOnly:  accessKey == "ro",
+			Mount: func() (string, func(), error) {
+				return th.path, func() {}, nil
+			},
+		}, nil
+	})
+
+	th.Handler.now = func() time.Time { return th.now }
+
+	return th
+}
+
+// do sends a request signed with the access key to the handler.
+func (th *testHandler) do(accessKey string, method string, target string, body []byte, headers map[string]string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, bytes.NewReader(body))
+	r.Host = "127.0.0.1:9000"
+
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+
+	th.sign(r, accessKey, sha256Hex(body))
+
+	w := httptest.NewRecorder()
+	th.ServeHTTP(w, r)
+
+	return w
+}
+
+// sign signs the request with the access key for the given payload hash.
+func (th *testHandler) sign(r *http.Request, accessKey string, payloadHash string) {
+	r.Header.Set("X-Amz-Date", th.now.Format(iso8601Format))
+	r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	sig := &signature{
+		date:          th.now,
+		scope:         th.now.Format(yyyymmddFormat) + "/us-east-1/s3/aws4_request",
+		signedHeaders: []string{"host", "x-amz-content-sha256", "x-amz-date"},
+		payloadHash:   payloadHash,
+	}
+
+	signingKey := hmacSHA256([]byte("AWS4"+accessKey+"-secret"), th.now.Format(yyyymmddFormat))
+	for _, element := range []string{"us-east-1", "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, element)
+	}
+
+	stringToSign := strings.Join([]string{signV4Algorithm, th.now.Format(iso8601Format), sig.scope, sha256Hex([]byte(sig.canonicalRequest(r)))}, "\n")
+	r.Header.Set("Authorization", signV4Algorithm+" Credential="+accessKey+"/"+sig.scope+",SignedHeaders=host;x-amz-content-sha256;x-amz-date,Signature="+hex.EncodeToString(hmacSHA256(signingKey, stringToSign)))
+}
+
+// errorCode returns the S3 error code of the response.
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	resp := Error{}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Code
+}
+
+func TestHandlerObjects(t *testing.T) {
+	th := newTestHandler(t)
+
+	// Store and retrieve an object.
+	w := th.do("rw", http.MethodPut, "/bucket1/dir/hello.txt", []byte("hello world"), map[string]string{"Content-Type": "text/plain", "X-Amz-Meta-Foo": "bar"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `"5eb63bbbe01eeed093cb22bb8f5acdc3"`, w.Header().Get("ETag"))
+
+	w = th.do("ro", http.MethodGet, "/bucket1/dir/hello.txt", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "bar", w.Header().Get("X-Amz-Meta-Foo"))
+
+	w = th.do("ro", http.MethodGet, "/bucket1/dir/hello.txt", nil, map[string]string{"Range": "bytes=6-"})
+	require.Equal(t, http.StatusPartialContent, w.Code)
+	assert.Equal(t, "world", w.Body.String())
+
+	w = th.do("ro", http.MethodHead, "/bucket1/dir/hello.txt", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "11", w.Header().Get("Content-Length"))
+
+	// Copy the object.
+	w = th.do("rw", http.MethodPut, "/bucket1/copy.txt", nil, map[string]string{"X-Amz-Copy-Source": "/bucket1/dir/hello.txt"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = th.do("rw", http.MethodGet, "/bucket1/copy.txt", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "hello world", w.Body.String())
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+
+	// Read-only keys can't modify the bucket.
+	w = th.do("ro", http.MethodPut, "/bucket1/other.txt", []byte("data"), nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "AccessDenied", errorCode(t, w))
+
+	// Other buckets can't be accessed.
+	w = th.do("rw", http.MethodGet, "/bucket2/dir/hello.txt", nil, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Invalid Content-MD5.
+	w = th.do("rw", http.MethodPut, "/bucket1/other.txt", []byte("data"), map[string]string{"Content-MD5": "XrY7u+Ae7tCTyyK7j1rNww=="})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "BadDigest", errorCode(t, w))
+
+	// Delete the objects.
+	w = th.do("rw", http.MethodDelete, "/bucket1/copy.txt", nil, nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = th.do("rw", http.MethodPost, "/bucket1?delete", []byte(`<Delete><Object><Key>dir/hello.txt</Key></Object></Delete>`), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), "<Deleted><Key>dir/hello.txt</Key></Deleted>")
+
+	w = th.do("rw", http.MethodGet, "/bucket1/dir/hello.txt", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "NoSuchKey", errorCode(t, w))
+}
+
+func TestHandlerAuthentication(t *testing.T) {
+	th := newTestHandler(t)
+
+	w := th.do("unknown", http.MethodGet, "/bucket1", nil, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "InvalidAccessKeyId", errorCode(t, w))
+
+	r := httptest.NewRequest(http.MethodGet, "/bucket1", nil)
+	w = httptest.NewRecorder()
+	th.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "AccessDenied", errorCode(t, w))
+	assert.NotEmpty(t, w.Header().Get("X-Amz-Request-Id"))
+
+	// Tampered payload.
+	r = httptest.NewRequest(http.MethodPut, "/bucket1/key", strings.NewReader("tampered"))
+	r.Host = "127.0.0.1:9000"
+	th.sign(r, "rw", sha256Hex([]byte("original")))
+
+	w = httptest.NewRecorder()
+	th.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "XAmzContentSHA256Mismatch", errorCode(t, w))
+
+	w = th.do("rw", http.MethodGet, "/bucket1/key", nil, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlerListObjects(t *testing.T) {
+	th := newTestHandler(t)
+
+	for _, key := range []string{"a.txt", "b/1.txt", "b/2.txt", "c/1.txt", "d.txt"} {
+		w := th.do("rw", http.MethodPut, "/bucket1/"+key, []byte(key), nil)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	list := func(query string) listBucketResult {
+		w := th.do("ro", http.MethodGet, "/bucket1?"+query, nil, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		result := listBucketResult{}
+		require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &result))
+		return result
+	}
+
+	keys := func(result listBucketResult) []string {
+		var keys []string
+		for _, entry := range result.Contents {
+			keys = append(keys, entry.Key)
+		}
+
+		for _, entry := range result.CommonPrefixes {
+			keys = append(keys, entry.Prefix)
+		}
+
+		return keys
+	}
+
+	result := list("list-type=2")
+	assert.Equal(t, []string{"a.txt", "b/1.txt", "b/2.txt", "c/1.txt", "d.txt"}, keys(result))
+	assert.False(t, result.IsTruncated)
+
+	result = list("list-type=2&delimiter=/")
+	assert.Equal(t, []string{"a.txt", "d.txt", "b/", "c/"}, keys(result))
+
+	result = list("list-type=2&prefix=b/")
+	assert.Equal(t, []string{"b/1.txt", "b/2.txt"}, keys(result))
+
+	// Paginate through the common prefixes.
+	var pages [][]string
+	query := "list-type=2&delimiter=/&max-keys=2"
+	for {
+		result = list(query)
+		pages = append(pages, keys(result))
+		if !result.IsTruncated {
+			break
+		}
+
+		query = "list-type=2&delimiter=/&max-keys=2&continuation-token=" + result.NextContinuationToken
+	}
+
+	assert.Equal(t, [][]string{{"a.txt", "b/"}, {"d.txt", "c/"}}, pages)
+
+	result = list("marker=b/2.txt")
+	assert.Equal(t, []string{"c/1.txt", "d.txt"}, keys(result))
+}
+
+func TestHandlerMultipartUpload(t *testing.T) {
+	th := newTestHandler(t)
+
+	w := th.do("rw", http.MethodPost, "/bucket1/large?uploads", nil, map[string]string{"Content-Type": "application/x-tar"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	initiated := initiateMultipartUploadResult{}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &initiated))
+
+	parts := [][]byte{bytes.Repeat([]byte("a"), minPartSize), []byte("end")}
+	var completeParts strings.Builder
+	for i, part := range parts {
+		w = th.do("rw", http.MethodPut, "/bucket1/large?partNumber="+strconv.Itoa(i+1)+"&uploadId="+initiated.UploadID, part, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		completeParts.WriteString("<Part><PartNumber>" + strconv.Itoa(i+1) + "</PartNumber><ETag>" + w.Header().Get("ETag") + "</ETag></Part>")
+	}
+
+	// Parts must be listed in order.
+	w = th.do("rw", http.MethodPost, "/bucket1/large?uploadId="+initiated.UploadID, []byte("<CompleteMultipartUpload><Part><PartNumber>2</PartNumber><ETag>x</ETag></Part><Part><PartNumber>1</PartNumber><ETag>x</ETag></Part></CompleteMultipartUpload>"), nil)
+	assert.Equal(t, "InvalidPartOrder", errorCode(t, w))
+
+	w = th.do("rw", http.MethodPost, "/bucket1/large?uploadId="+initiated.UploadID, []byte("<CompleteMultipartUpload>"+completeParts.String()+"</CompleteMultipartUpload>"), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	completed := completeMultipartUploadResult{}
+	require.NoError(t, xml.Unmarshal(w.Body.Bytes(), &completed))
+	assert.True(t, strings.HasSuffix(completed.ETag, `-2"`))
+
+	w = th.do("rw", http.MethodGet, "/bucket1/large", nil, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-tar", w.Header().Get("Content-Type"))
+	assert.Equal(t, completed.ETag, w.Header().Get("ETag"))
+
+	data, err := io.ReadAll(w.Body)
+	require.NoError(t, err)
+	assert.Equal(t, append(parts[0], parts[1]...), data)
+
+	// The upload is gone once completed.
+	w = th.do("rw", http.MethodDelete, "/bucket1/large?uploadId="+initiated.UploadID, nil, nil)
+	assert.Equal(t, "NoSuchUpload", errorCode(t, w))
+}
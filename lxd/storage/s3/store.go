@@ -0,0 +1,556 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	// maxKeyLength is the maximum length of an object key.
+	maxKeyLength = 1024
+
+	// maxPartNumber is the maximum number of parts of a multipart upload.
+	maxPartNumber = 10000
+
+	// minPartSize is the minimum size of all but the last part of a multipart upload.
+	minPartSize = 5 * 1024 * 1024
+)
+
+// objectInfo holds the metadata of an object or of a pending multipart upload.
+type objectInfo struct {
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	ContentType  string            `json:"content_type,omitempty"`
+	LastModified time.Time         `json:"last_modified"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+}
+
+// partInfo holds the metadata of a part of a multipart upload.
+type partInfo struct {
+	PartNumber   int       `json:"part_number"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// store stores the objects of a bucket in a directory.
+//
+// Each object is stored as a data file and a metadata file named after the SHA256 of its key, so that any key can
+// be stored regardless of the filesystem limits on names. New content is written to a temporary file and then
+// renamed in place, so readers always see complete objects.
+type store struct {
+	path string
+
+	// Serializes the updates of the data and metadata files, shared by all the stores of a handler.
+	mu *sync.Mutex
+}
+
+// newStore returns a store for the bucket directory at path.
+func newStore(path string, mu *sync.Mutex) *store {
+	return &store{path: path, mu: mu}
+}
+
+// validateKey checks that the object key is valid.
+func validateKey(key string) error {
+	if key == "" || !utf8.ValidString(key) {
+		return errInvalidArgument
+	}
+
+	if len(key) > maxKeyLength {
+		return errKeyTooLong
+	}
+
+	return nil
+}
+
+// objectPath returns the path of the data file of the object. The metadata file has the same path with ".json" appended.
+func (s *store) objectPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	id := hex.EncodeToString(sum[:])
+
+	return filepath.Join(s.path, "objects", id[:2], id)
+}
+
+// uploadPath returns the path of the directory of a multipart upload.
+func (s *store) uploadPath(uploadID string) (string, error) {
+	_, err := hex.DecodeString(uploadID)
+	if err != nil || len(uploadID) != 32 {
+		return "", errNoSuchUpload
+	}
+
+	return filepath.Join(s.path, "uploads", uploadID), nil
+}
+
+// createTemp creates a temporary file in the bucket.
+func (s *store) createTemp() (*os.File, error) {
+	tmpPath := filepath.Join(s.path, "tmp")
+
+	err := os.MkdirAll(tmpPath, 0o700)
+	if err != nil {
+		return nil, mapStorageError(err)
+	}
+
+	f, err := os.CreateTemp(tmpPath, "")
+	if err != nil {
+		return nil, mapStorageError(err)
+	}
+
+	return f, nil
+}
+
+// writeTemp writes the content of the reader to a new temporary file and returns its path along with the MD5 and
+// size of the content. If digest is set, it's checked against the MD5 of the content. If size isn't negative,
+// it's checked against the size of the content.
+func (s *store) writeTemp(reader io.Reader, size int64, digest []byte) (string, []byte, int64, error) {
+	f, err := s.createTemp()
+	if err != nil {
+		return "", nil, -1, err
+	}
+
+	success := false
+	defer func() {
+		_ = f.Close()
+
+		if !success {
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	md5Hash := md5.New()
+	written, err := io.Copy(io.MultiWriter(f, md5Hash), reader)
+	if err != nil {
+		return "", nil, -1, mapStorageError(err)
+	}
+
+	if size >= 0 && written != size {
+		return "", nil, -1, errIncompleteBody
+	}
+
+	sum := md5Hash.Sum(nil)
+	if digest != nil && !bytes.Equal(digest, sum) {
+		return "", nil, -1, errBadDigest
+	}
+
+	err = f.Close()
+	if err != nil {
+		return "", nil, -1, mapStorageError(err)
+	}
+
+	success = true
+
+	return f.Name(), sum, written, nil
+}
+
+// writeJSON writes the value as JSON to a new temporary file and returns its path.
+func (s *store) writeJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	path, _, _, err := s.writeTemp(bytes.NewReader(data), -1, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// readJSON reads the JSON file at path into value.
+func readJSON(path string, value any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, value)
+}
+
+// commit moves the data and metadata temporary files in place.
+func (s *store) commit(tmpDataPath string, dataPath string, info any, metadataPath string) error {
+	tmpMetadataPath, err := s.writeJSON(info)
+	if err != nil {
+		_ = os.Remove(tmpDataPath)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = os.MkdirAll(filepath.Dir(dataPath), 0o700)
+	if err == nil {
+		err = os.Rename(tmpDataPath, dataPath)
+	}
+
+	if err == nil {
+		err = os.Rename(tmpMetadataPath, metadataPath)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmpDataPath)
+		_ = os.Remove(tmpMetadataPath)
+		return mapStorageError(err)
+	}
+
+	return nil
+}
+
+// putObject stores the content of the reader as an object.
+func (s *store) putObject(info objectInfo, reader io.Reader, size int64, digest []byte) (*objectInfo, error) {
+	tmpPath, sum, written, err := s.writeTemp(reader, size, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Size = written
+	info.ETag = hex.EncodeToString(sum)
+	info.LastModified = time.Now().UTC()
+
+	dataPath := s.objectPath(info.Key)
+
+	err = s.commit(tmpPath, dataPath, info, dataPath+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// getObject opens the data file of an object and returns its metadata.
+func (s *store) getObject(key string) (*os.File, *objectInfo, error) {
+	dataPath := s.objectPath(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := &objectInfo{}
+	err := readJSON(dataPath+".json", info)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, errNoSuchKey
+		}
+
+		return nil, nil, err
+	}
+
+	f, err := os.Open(dataPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, errNoSuchKey
+		}
+
+		return nil, nil, err
+	}
+
+	return f, info, nil
+}
+
+// statObject returns the metadata of an object.
+func (s *store) statObject(key string) (*objectInfo, error) {
+	info := &objectInfo{}
+	err := readJSON(s.objectPath(key)+".json", info)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, errNoSuchKey
+		}
+
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// copyObject copies the object with key srcKey to the object described by info.
+// If replaceMetadata is false, the metadata of the source object is kept.
+func (s *store) copyObject(srcKey string, info objectInfo, replaceMetadata bool) (*objectInfo, error) {
+	f, srcInfo, err := s.getObject(srcKey)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	if !replaceMetadata {
+		info.ContentType = srcInfo.ContentType
+		info.Headers = srcInfo.Headers
+		info.Metadata = srcInfo.Metadata
+	}
+
+	return s.putObject(info, f, srcInfo.Size, nil)
+}
+
+// deleteObject deletes an object. Deleting an object that doesn't exist isn't an error.
+func (s *store) deleteObject(key string) error {
+	dataPath := s.objectPath(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, path := range []string{dataPath + ".json", dataPath} {
+		err := os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listObjects returns the metadata of all the objects with the given prefix, sorted by key.
+func (s *store) listObjects(prefix string) ([]objectInfo, error) {
+	var objects []objectInfo
+
+	err := filepath.WalkDir(filepath.Join(s.path, "objects"), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if entry.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+
+		info := objectInfo{}
+		err = readJSON(path, &info)
+		if err != nil {
+			// The object may have been deleted since the directory was read.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if strings.HasPrefix(info.Key, prefix) {
+			objects = append(objects, info)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed listing objects: %w", err)
+	}
+
+	slices.SortFunc(objects, func(a objectInfo, b objectInfo) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return objects, nil
+}
+
+// createUpload starts a new multipart upload and returns its ID.
+func (s *store) createUpload(info objectInfo) (string, error) {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	uploadID := hex.EncodeToString(id)
+
+	uploadPath, err := s.uploadPath(uploadID)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(uploadPath, 0o700)
+	if err != nil {
+		return "", mapStorageError(err)
+	}
+
+	info.LastModified = time.Now().UTC()
+
+	tmpPath, err := s.writeJSON(info)
+	if err != nil {
+		_ = os.RemoveAll(uploadPath)
+		return "", err
+	}
+
+	err = os.Rename(tmpPath, filepath.Join(uploadPath, "info.json"))
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		_ = os.RemoveAll(uploadPath)
+		return "", mapStorageError(err)
+	}
+
+	return uploadID, nil
+}
+
+// getUpload returns the metadata of a multipart upload for the object key.
+func (s *store) getUpload(uploadID string, key string) (string, *objectInfo, error) {
+	uploadPath, err := s.uploadPath(uploadID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	info := &objectInfo{}
+	err = readJSON(filepath.Join(uploadPath, "info.json"), info)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, errNoSuchUpload
+		}
+
+		return "", nil, err
+	}
+
+	if info.Key != key {
+		return "", nil, errNoSuchUpload
+	}
+
+	return uploadPath, info, nil
+}
+
+// putPart stores the content of the reader as a part of a multipart upload.
+func (s *store) putPart(uploadID string, key string, partNumber int, reader io.Reader, size int64, digest []byte) (*partInfo, error) {
+	uploadPath, _, err := s.getUpload(uploadID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpPath, sum, written, err := s.writeTemp(reader, size, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &partInfo{
+		PartNumber:   partNumber,
+		Size:         written,
+		ETag:         hex.EncodeToString(sum),
+		LastModified: time.Now().UTC(),
+	}
+
+	partPath := filepath.Join(uploadPath, strconv.Itoa(partNumber))
+
+	err = s.commit(tmpPath, partPath, part, partPath+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// completeUpload assembles the given parts of a multipart upload into an object.
+// The parts must be listed in ascending order and their ETags must match the uploaded parts.
+func (s *store) completeUpload(uploadID string, key string, parts []partInfo) (*objectInfo, error) {
+	uploadPath, info, err := s.getUpload(uploadID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 0 {
+		return nil, errMalformedXML
+	}
+
+	readers := make([]io.Reader, 0, len(parts))
+	md5Hash := md5.New()
+
+	defer func() {
+		for _, reader := range readers {
+			_ = reader.(*os.File).Close()
+		}
+	}()
+
+	for i := 1; i < len(parts); i++ {
+		if parts[i].PartNumber <= parts[i-1].PartNumber {
+			return nil, errInvalidPartOrder
+		}
+	}
+
+	for i, part := range parts {
+		partPath := filepath.Join(uploadPath, strconv.Itoa(part.PartNumber))
+
+		uploaded := partInfo{}
+		err := readJSON(partPath+".json", &uploaded)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, errInvalidPart
+			}
+
+			return nil, err
+		}
+
+		if strings.Trim(part.ETag, `"`) != uploaded.ETag {
+			return nil, errInvalidPart
+		}
+
+		if i < len(parts)-1 && uploaded.Size < minPartSize {
+			return nil, errEntityTooSmall
+		}
+
+		f, err := os.Open(partPath)
+		if err != nil {
+			return nil, err
+		}
+
+		readers = append(readers, f)
+
+		sum, err := hex.DecodeString(uploaded.ETag)
+		if err != nil {
+			return nil, err
+		}
+
+		md5Hash.Write(sum)
+	}
+
+	tmpPath, _, written, err := s.writeTemp(io.MultiReader(readers...), -1, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ETag of a multipart object is the MD5 of the MD5 of its parts followed by the number of parts.
+	info.Size = written
+	info.ETag = hex.EncodeToString(md5Hash.Sum(nil)) + "-" + strconv.Itoa(len(parts))
+	info.LastModified = time.Now().UTC()
+
+	dataPath := s.objectPath(key)
+
+	err = s.commit(tmpPath, dataPath, info, dataPath+".json")
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.RemoveAll(uploadPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// abortUpload deletes a multipart upload and its parts.
+func (s *store) abortUpload(uploadID string, key string) error {
+	uploadPath, _, err := s.getUpload(uploadID, key)
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(uploadPath)
+}
+
+// mapStorageError maps the errors caused by a full bucket volume to the matching S3 error.
+func mapStorageError(err error) error {
+	if errors.Is(err, unix.ENOSPC) || errors.Is(err, unix.EDQUOT) {
+		return errEntityTooLarge
+	}
+
+	return err
+}
@@ -114,7 +114,7 @@ func VolumeDBTypeToType(volDBType cluster.StoragePoolVolumeType) drivers.VolumeT
 // InstanceTypeToVolumeType converts instance type to storage driver volume type.
 func InstanceTypeToVolumeType(instType instancetype.Type) (drivers.VolumeType, error) {
 	switch instType {
-	case instancetype.Container:
+	case instancetype.Container, instancetype.Krun:
 		return drivers.VolumeTypeContainer, nil
 	case instancetype.VM:
 		return drivers.VolumeTypeVM, nil
@@ -337,7 +337,7 @@ func VolumeDBSnapshotsGet(pool Pool, projectName string, volume string, volumeTy
 // BucketDBCreate creates a bucket in the database.
 // The supplied bucket's config may be modified with defaults for the storage pool being used.
 // Returns bucket DB record ID.
-func BucketDBCreate(ctx context.Context, pool Pool, projectName string, bucket *api.StorageBucketsPost) (int64, error) {
+func BucketDBCreate(ctx context.Context, pool Pool, projectName string, memberSpecific bool, bucket *api.StorageBucketsPost) (int64, error) {
 	p, ok := pool.(*lxdBackend)
 	if !ok {
 		return -1, errors.New("Pool is not a lxdBackend")
@@ -373,7 +373,7 @@ func BucketDBCreate(ctx context.Context, pool Pool, projectName string, bucket *
 
 	err = p.state.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
 		// Create the database entry for the storage bucket.
-		bucketID, err = tx.CreateStoragePoolBucket(ctx, p.ID(), projectName, *bucket)
+		bucketID, err = tx.CreateStoragePoolBucket(ctx, p.ID(), projectName, memberSpecific, *bucket)
 
 		return err
 	})
@@ -421,7 +421,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  shortdesc: Size of the storage pool (for loop-based pools)
 		//  scope: local
 
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs; group=volume-conf; key=size)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs; group=volume-conf; key=size)
 		//
 		// ---
 		//  type: string
@@ -437,7 +437,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  shortdesc: Quota of the storage bucket
 		//  scope: local
 		"size": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=snapshots.expiry)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=snapshots.expiry)
 		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
 		// ---
 		//  type: string
@@ -450,7 +450,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 			_, err := shared.GetExpiry(time.Time{}, value)
 			return err
 		},
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=snapshots.schedule)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=snapshots.schedule)
 		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic snapshots (the default).
 		// ---
 		//  type: string
@@ -459,7 +459,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  shortdesc: Schedule for automatic volume snapshots
 		//  scope: global
 		"snapshots.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=snapshots.pattern)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=snapshots.pattern)
 		// You can specify a naming template for scheduled snapshots and unnamed snapshots.
 		//
 		// {{snapshot_pattern_detail}}
@@ -470,11 +470,71 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  shortdesc: Template for the snapshot name
 		//  scope: global
 		"snapshots.pattern": validate.IsAny,
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.expiry)
+		// Specify an expression like `1M 2H 3d 4w 5m 6y`.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.expiry`
+		//  shortdesc: Time until scheduled backups are deleted
+		//  scope: global
+		"backups.expiry": func(value string) error {
+			// Validate expression
+			_, err := shared.GetExpiry(time.Time{}, value)
+			return err
+		},
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.schedule)
+		// Specify either a cron expression (`<minute> <hour> <dom> <month> <dow>`), a comma-separated list of schedule aliases (`@hourly`, `@daily`, `@midnight`, `@weekly`, `@monthly`, `@annually`, `@yearly`), or leave empty to disable automatic backups (the default).
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.schedule`
+		//  shortdesc: Schedule for automatic volume backups
+		//  scope: global
+		"backups.schedule": validate.Optional(validate.IsCron([]string{"@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@annually", "@yearly"})),
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.pattern)
+		// You can specify a naming template for scheduled backups.
+		// The template is rendered with the `creation_date` variable, and `%d` is replaced by the next free index.
+		// ---
+		//  type: string
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.pattern` or `backup%d`
+		//  shortdesc: Template for the scheduled backup name
+		//  scope: global
+		"backups.pattern": validate.IsAny,
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.retention.last)
+		// Scheduled backups that aren't kept by any of the `backups.retention.*` rules are deleted after each scheduled backup.
+		// Backups created manually are never deleted by the retention policy.
+		// ---
+		//  type: integer
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.retention.last` or `0` (no limit)
+		//  shortdesc: Number of most recent scheduled backups to keep
+		//  scope: global
+		"backups.retention.last": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.retention.daily)
+		// The most recent scheduled backup of each of the last days that have a backup is kept.
+		// ---
+		//  type: integer
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.retention.daily` or `0` (no limit)
+		//  shortdesc: Number of days for which to keep a scheduled backup
+		//  scope: global
+		"backups.retention.daily": validate.Optional(validate.IsUint32),
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=backups.retention.weekly)
+		// The most recent scheduled backup of each of the last weeks that have a backup is kept.
+		// ---
+		//  type: integer
+		//  condition: custom volume
+		//  defaultdesc: same as `volume.backups.retention.weekly` or `0` (no limit)
+		//  shortdesc: Number of weeks for which to keep a scheduled backup
+		//  scope: global
+		"backups.retention.weekly": validate.Optional(validate.IsUint32),
 	}
 
 	// security.shifted and security.unmapped are only relevant for custom filesystem volumes.
 	if vol == nil || (vol.Type() == drivers.VolumeTypeCustom && vol.ContentType() == drivers.ContentTypeFS) {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=security.shifted)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=security.shifted)
 		// Enable this option to allow the volume to be attached to multiple isolated instances.
 		// ---
 		//  type: bool
@@ -483,7 +543,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  shortdesc: Enable ID shifting overlay
 		//  scope: global
 		rules["security.shifted"] = validate.Optional(validate.IsBool)
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=security.unmapped)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=security.unmapped)
 		//
 		// ---
 		//  type: bool
@@ -496,7 +556,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 
 	// security.shared guards virtual-machine and custom block volumes.
 	if vol == nil || ((vol.Type() == drivers.VolumeTypeCustom || vol.Type() == drivers.VolumeTypeVM) && vol.ContentType() == drivers.ContentTypeBlock) {
-		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=security.shared)
+		// lxdmeta:generate(entities=storage-btrfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=security.shared)
 		// Enable this option to allow the volume to be shared across multiple instances despite the possibility of data loss.
 		//
 		// ---
@@ -510,7 +570,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 
 	// Those keys are only valid for volumes.
 	if vol != nil {
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=volatile.uuid)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=volatile.uuid)
 		//
 		// ---
 		//  type: string
@@ -519,7 +579,7 @@ func poolAndVolumeCommonRules(vol *drivers.Volume) map[string]func(string) error
 		//  scope: global
 		rules["volatile.uuid"] = validate.Optional(validate.IsUUID)
 
-		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=volatile.devlxd.owner)
+		// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=volatile.devlxd.owner)
 		//
 		// ---
 		//  type: string
@@ -544,7 +604,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  shortdesc: Whether to wipe the block device before creating the pool
 		//  scope: local
 		"source.wipe": validate.Optional(validate.IsBool),
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-btrfs,storage-zfs,storage-ceph,storage-cephfs; group=pool-conf; key=source.recover)
+		// lxdmeta:generate(entities=storage-dir,storage-nfs,storage-lvm,storage-btrfs,storage-zfs,storage-ceph,storage-cephfs; group=pool-conf; key=source.recover)
 		// Set this option to true to recover an existing source which was previously created by LXD.
 		// ---
 		//  type: bool
@@ -553,7 +613,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  scope: local
 		"source.recover":          validate.Optional(validate.IsBool),
 		"volatile.initial_source": validate.IsAny,
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=pool-conf; key=rsync.bwlimit)
+		// lxdmeta:generate(entities=storage-dir,storage-nfs,storage-lvm,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=pool-conf; key=rsync.bwlimit)
 		// When `rsync` must be used to transfer storage entities, this option specifies the upper limit
 		// to be placed on the socket I/O.
 		// ---
@@ -562,7 +622,7 @@ func validatePoolCommonRules() map[string]func(string) error {
 		//  shortdesc: Upper limit on the socket I/O for `rsync`
 		//  scope: global
 		"rsync.bwlimit": validate.Optional(validate.IsSize),
-		// lxdmeta:generate(entities=storage-dir,storage-lvm,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=pool-conf; key=rsync.compression)
+		// lxdmeta:generate(entities=storage-dir,storage-nfs,storage-lvm,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=pool-conf; key=rsync.compression)
 		//
 		// ---
 		//  type: bool
@@ -620,14 +680,14 @@ func validateLocalPoolCommonRules() map[string]func(string) error {
 func validateVolumeCommonRules(vol drivers.Volume) map[string]func(string) error {
 	rules := poolAndVolumeCommonRules(&vol)
 
-	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=volatile.idmap.last)
+	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=volatile.idmap.last)
 	//
 	// ---
 	//   type: string
 	//   shortdesc: JSON-serialized UID/GID map that has been applied to the volume
 	//   condition: filesystem
 
-	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra; group=volume-conf; key=volatile.idmap.next)
+	// lxdmeta:generate(entities=storage-btrfs,storage-cephfs,storage-ceph,storage-dir,storage-nfs,storage-lvm,storage-zfs,storage-powerflex,storage-powerstore,storage-pure,storage-alletra,storage-san; group=volume-conf; key=volatile.idmap.next)
 	//
 	// ---
 	//   type: string
@@ -1332,6 +1392,45 @@ func CompareSnapshots(sourceSnapshots []ComparableSnapshot, targetSnapshots []Co
 	return syncFromSource, deleteFromTarget
 }
 
+// CompareSnapshotsFromLastCommon works like CompareSnapshots but only considers the snapshots taken after the
+// most recent snapshot that the source and target have in common. The snapshots up to and including that one
+// are left alone on both sides. Both input slices are expected to be ordered from oldest to newest.
+// If the source and target don't have any snapshot in common, it falls back to CompareSnapshots.
+func CompareSnapshotsFromLastCommon(sourceSnapshots []ComparableSnapshot, targetSnapshots []ComparableSnapshot) (syncSourceSnapshots []int, deleteTargetSnapshots []int) {
+	lastCommonSource := -1
+	lastCommonTarget := -1
+
+	// Find the most recent source snapshot which also exists unchanged on the target.
+	for sourceSnapIndex := len(sourceSnapshots) - 1; sourceSnapIndex >= 0 && lastCommonSource < 0; sourceSnapIndex-- {
+		sourceSnap := sourceSnapshots[sourceSnapIndex]
+
+		for targetSnapIndex, targetSnap := range targetSnapshots {
+			if targetSnap.Name == sourceSnap.Name && targetSnap.CreationDate.Equal(sourceSnap.CreationDate) && targetSnap.ID == sourceSnap.ID {
+				lastCommonSource = sourceSnapIndex
+				lastCommonTarget = targetSnapIndex
+				break
+			}
+		}
+	}
+
+	if lastCommonSource < 0 {
+		return CompareSnapshots(sourceSnapshots, targetSnapshots)
+	}
+
+	syncFromSource, deleteFromTarget := CompareSnapshots(sourceSnapshots[lastCommonSource+1:], targetSnapshots[lastCommonTarget+1:])
+
+	// Convert the indexes back to the ones of the input slices.
+	for i := range syncFromSource {
+		syncFromSource[i] += lastCommonSource + 1
+	}
+
+	for i := range deleteFromTarget {
+		deleteFromTarget[i] += lastCommonTarget + 1
+	}
+
+	return syncFromSource, deleteFromTarget
+}
+
 // VolumeDetermineNextSnapshotName determines a name for next snapshot of a volume
 // following the volume's snapshots.pattern or the provided default pattern.
 func VolumeDetermineNextSnapshotName(ctx context.Context, s *state.State, pool string, volumeName string, volumeConfig map[string]string) (string, error) {
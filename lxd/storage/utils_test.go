@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareSnapshotsFromLastCommon(t *testing.T) {
+	date := func(day int) time.Time { return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name       string
+		source     []ComparableSnapshot
+		target     []ComparableSnapshot
+		wantSync   []int
+		wantDelete []int
+	}{
+		{
+			name:     "no target snapshots",
+			source:   []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}, {Name: "snap1", CreationDate: date(2)}},
+			wantSync: []int{0, 1},
+		},
+		{
+			name:     "only newer snapshots are synced",
+			source:   []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}, {Name: "snap1", CreationDate: date(2)}, {Name: "snap2", CreationDate: date(3)}},
+			target:   []ComparableSnapshot{{Name: "snap1", CreationDate: date(2)}},
+			wantSync: []int{2},
+		},
+		{
+			name:     "older target snapshots are kept",
+			source:   []ComparableSnapshot{{Name: "snap2", CreationDate: date(3)}, {Name: "snap3", CreationDate: date(4)}},
+			target:   []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}, {Name: "snap1", CreationDate: date(2)}, {Name: "snap2", CreationDate: date(3)}},
+			wantSync: []int{1},
+		},
+		{
+			name:       "newer target snapshots which differ are replaced",
+			source:     []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}, {Name: "snap1", CreationDate: date(3)}},
+			target:     []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}, {Name: "snap1", CreationDate: date(2)}, {Name: "snap2", CreationDate: date(2)}},
+			wantSync:   []int{1},
+			wantDelete: []int{1, 2},
+		},
+		{
+			name:       "no common snapshot falls back to a full comparison",
+			source:     []ComparableSnapshot{{Name: "snap0", CreationDate: date(2)}},
+			target:     []ComparableSnapshot{{Name: "snap0", CreationDate: date(1)}},
+			wantSync:   []int{0},
+			wantDelete: []int{0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotSync, gotDelete := CompareSnapshotsFromLastCommon(tt.source, tt.target)
+			assert.Equal(t, tt.wantSync, gotSync)
+			assert.Equal(t, tt.wantDelete, gotDelete)
+		})
+	}
+}
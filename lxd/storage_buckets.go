@@ -16,7 +16,9 @@ import (
 	"github.com/canonical/lxd/lxd/project"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/state"
 	storagePools "github.com/canonical/lxd/lxd/storage"
+	"github.com/canonical/lxd/lxd/storage/s3"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/entity"
@@ -1360,3 +1362,57 @@ func addStorageBucketDetailsToContext(d *Daemon, r *http.Request) error {
 	details.bucketName = bucketName
 	return nil
 }
+
+// storageBucketsS3Lookup returns the grant of a key of a local storage bucket served by the storage buckets listener.
+func storageBucketsS3Lookup(s *state.State) func(ctx context.Context, accessKey string) (*s3.Grant, error) {
+	return func(ctx context.Context, accessKey string) (*s3.Grant, error) {
+		var bucket *db.StorageBucket
+		var bucketKey *db.StorageBucketKey
+
+		err := s.DB.Cluster.Transaction(ctx, func(ctx context.Context, tx *db.ClusterTx) error {
+			var err error
+
+			bucket, err = tx.GetStoragePoolLocalBucketByAccessKey(ctx, accessKey)
+			if err != nil {
+				return err
+			}
+
+			bucketKeys, err := tx.GetStoragePoolBucketKeys(ctx, bucket.ID, db.StorageBucketKeyFilter{AccessKey: &accessKey})
+			if err != nil {
+				return err
+			}
+
+			if len(bucketKeys) != 1 {
+				return api.StatusErrorf(http.StatusNotFound, "Storage bucket key not found")
+			}
+
+			bucketKey = bucketKeys[0]
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		pool, err := storagePools.LoadByName(s, bucket.PoolName)
+		if err != nil {
+			return nil, fmt.Errorf("Failed loading storage pool: %w", err)
+		}
+
+		grant := &s3.Grant{
+			SecretKey: bucketKey.SecretKey,
+			Bucket:    bucket.Name,
+			ReadOnly:  bucketKey.Role == "read-only",
+			Mount: func() (string, func(), error) {
+				path, err := pool.MountBucket(bucket.Project, bucket.Name)
+				if err != nil {
+					return "", nil, fmt.Errorf("Failed mounting storage bucket %q: %w", bucket.Name, err)
+				}
+
+				return path, func() { _ = pool.UnmountBucket(bucket.Project, bucket.Name) }, nil
+			},
+		}
+
+		return grant, nil
+	}
+}
@@ -167,6 +167,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -233,6 +247,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -287,6 +315,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Cluster member name
 //      type: string
 //      example: lxd01
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -346,6 +388,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: sort
+//	    description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//	    type: string
+//	    example: -created_at
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: continue
+//	    description: Token returned by a previous request to fetch the next page
+//	    type: string
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -405,6 +461,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -471,6 +541,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Collection filter
 //      type: string
 //      example: default
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -525,6 +609,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //      description: Cluster member name
 //      type: string
 //      example: lxd01
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -created_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: API endpoints
@@ -584,6 +682,20 @@ func checkStoragePoolVolumeTypeAccess(s *state.State, r *http.Request, entitleme
 //	    description: Cluster member name
 //	    type: string
 //	    example: lxd01
+//	  - in: query
+//	    name: sort
+//	    description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//	    type: string
+//	    example: -created_at
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: continue
+//	    description: Token returned by a previous request to fetch the next page
+//	    type: string
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -652,6 +764,11 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Invalid filter: %w", err))
 	}
 
+	pagination, err := request.PaginationParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	var poolID int64
 
 	if !allPools {
@@ -813,21 +930,37 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 		return dbProject
 	}
 
+	// Remove the volumes the user doesn't have access to.
+	volumes := make([]*api.StorageVolume, 0, len(dbVolumes))
+	volumeToDBVolume := make(map[*api.StorageVolume]*db.StorageVolume, len(dbVolumes))
+	for _, dbVol := range dbVolumes {
+		vol := &dbVol.StorageVolume
+
+		volumeName, _, _ := api.GetParentAndSnapshotName(vol.Name)
+		if !userHasPermission(entity.StorageVolumeURL(authCheckProject(vol.Project), vol.Location, dbVol.Pool, dbVol.Type, volumeName)) {
+			continue
+		}
+
+		volumes = append(volumes, vol)
+		volumeToDBVolume[vol] = dbVol
+	}
+
+	// Sort and paginate the results if needed.
+	var next string
+	if pagination.IsSet() {
+		volumes, next, err = request.Paginate(pagination, volumes, "project", "type", "name", "location")
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	recursion, _ := util.IsRecursionRequest(r)
 	if recursion > 0 {
-		volumes := make([]*api.StorageVolume, 0, len(dbVolumes))
 		urlToVolume := make(map[*api.URL]auth.EntitlementReporter)
-		for _, dbVol := range dbVolumes {
-			vol := &dbVol.StorageVolume
-
-			volumeName, _, _ := api.GetParentAndSnapshotName(vol.Name)
-			if !userHasPermission(entity.StorageVolumeURL(authCheckProject(vol.Project), vol.Location, dbVol.Pool, dbVol.Type, volumeName)) {
-				continue
-			}
-
+		for _, vol := range volumes {
 			// Fill in UsedBy if we haven't previously done so.
 			if clauses == nil || len(clauses.Clauses) == 0 {
-				volumeUsedBy, err := storagePoolVolumeUsedByGet(s, requestProjectName, dbVol)
+				volumeUsedBy, err := storagePoolVolumeUsedByGet(s, requestProjectName, volumeToDBVolume[vol])
 				if err != nil {
 					return response.InternalError(err)
 				}
@@ -835,7 +968,6 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 				vol.UsedBy = project.FilterUsedBy(r.Context(), s.Authorizer, volumeUsedBy)
 			}
 
-			volumes = append(volumes, vol)
 			urlToVolume[entity.StorageVolumeURL(vol.Project, vol.Location, vol.Pool, vol.Type, vol.Name)] = vol
 		}
 
@@ -846,21 +978,15 @@ func storagePoolVolumesGet(d *Daemon, r *http.Request) response.Response {
 			}
 		}
 
-		return response.SyncResponse(true, volumes)
+		return response.SyncResponseContinue(true, volumes, next)
 	}
 
-	urls := make([]string, 0, len(dbVolumes))
-	for _, dbVol := range dbVolumes {
-		volumeName, _, _ := api.GetParentAndSnapshotName(dbVol.Name)
-
-		if !userHasPermission(entity.StorageVolumeURL(authCheckProject(dbVol.Project), dbVol.Location, dbVol.Pool, dbVol.Type, volumeName)) {
-			continue
-		}
-
-		urls = append(urls, dbVol.StorageVolume.URL(version.APIVersion).String())
+	urls := make([]string, 0, len(volumes))
+	for _, vol := range volumes {
+		urls = append(urls, vol.URL(version.APIVersion).String())
 	}
 
-	return response.SyncResponse(true, urls)
+	return response.SyncResponseContinue(true, urls, next)
 }
 
 // filterVolumes returns a filtered list of volumes that match the given clauses.
@@ -253,6 +253,7 @@ func storagePoolVolumeTypeCustomBackupsGet(d *Daemon, r *http.Request) response.
 //	Create a storage volume backup
 //
 //	Creates a new storage volume backup.
+//	The backup is always stored on the server, as backup targets only support instance backups.
 //
 //	---
 //	consumes:
@@ -339,6 +340,13 @@ func storagePoolVolumeTypeCustomBackupsPost(d *Daemon, r *http.Request) response
 		return response.InternalError(err)
 	}
 
+	// Backup targets only support instance backups, so reject requests meant for the instance backups endpoint
+	// rather than silently storing the backup on the server.
+	backupTarget, _ := rj.GetString("backup_target")
+	if backupTarget != "" {
+		return response.BadRequest(errors.New("Backup targets are only supported for instance backups"))
+	}
+
 	expiry, _ := rj.GetString("expires_at")
 	if expiry == "" {
 		// Disable expiration by setting it to zero time.
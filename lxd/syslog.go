@@ -11,13 +11,15 @@ import (
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/lxd/lxd/events"
+	"github.com/canonical/lxd/lxd/network/flowlog"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/revert"
 )
 
 // StartSyslogListener starts the log monitor.
-func StartSyslogListener(ctx context.Context, eventServer *events.Server) error {
+// OVN ACL log entries are also passed to the flow logger (if not nil).
+func StartSyslogListener(ctx context.Context, eventServer *events.Server, flowLog *flowlog.Logger) error {
 	var listenConfig net.ListenConfig
 
 	sockFile := shared.VarPath("syslog.socket")
@@ -127,6 +129,10 @@ func StartSyslogListener(ctx context.Context, eventServer *events.Server) error
 				event.Context["application"] = applicationName
 			}
 
+			if flowLog != nil {
+				flowLog.HandleOVNLog(moduleName, message)
+			}
+
 			err = eventServer.Send("", api.EventTypeOVN, event)
 			if err != nil {
 				continue
@@ -79,6 +79,20 @@ func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Wa
 //      description: Project name
 //      type: string
 //      example: default
+//    - in: query
+//      name: sort
+//      description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//      type: string
+//      example: -last_seen_at
+//    - in: query
+//      name: limit
+//      description: Maximum number of entries to return
+//      type: integer
+//      example: 100
+//    - in: query
+//      name: continue
+//      description: Token returned by a previous request to fetch the next page
+//      type: string
 //  responses:
 //    "200":
 //      description: Sync response
@@ -126,6 +140,20 @@ func filterWarnings(warnings []api.Warning, clauses *filter.ClauseSet) ([]api.Wa
 //	    description: Project name
 //	    type: string
 //	    example: default
+//	  - in: query
+//	    name: sort
+//	    description: Comma separated list of fields to sort by, prefixed with "-" for descending order
+//	    type: string
+//	    example: -last_seen_at
+//	  - in: query
+//	    name: limit
+//	    description: Maximum number of entries to return
+//	    type: integer
+//	    example: 100
+//	  - in: query
+//	    name: continue
+//	    description: Token returned by a previous request to fetch the next page
+//	    type: string
 //	responses:
 //	  "200":
 //	    description: API endpoints
@@ -163,6 +191,12 @@ func warningsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(fmt.Errorf("Failed filtering warnings: %w", err))
 	}
 
+	// Parse sorting and pagination values
+	pagination, err := request.PaginationParams(r)
+	if err != nil {
+		return response.SmartError(err)
+	}
+
 	// Parse the project field
 	projectName := request.QueryParam(r, "project")
 
@@ -207,6 +241,15 @@ func warningsGet(d *Daemon, r *http.Request) response.Response {
 		return response.SmartError(err)
 	}
 
+	// Sort and paginate if needed
+	var next string
+	if pagination.IsSet() {
+		filteredWarnings, next, err = request.Paginate(pagination, filteredWarnings, "uuid")
+		if err != nil {
+			return response.SmartError(err)
+		}
+	}
+
 	if recursive == 0 {
 		var resultList []string
 
@@ -215,11 +258,11 @@ func warningsGet(d *Daemon, r *http.Request) response.Response {
 			resultList = append(resultList, url)
 		}
 
-		return response.SyncResponse(true, resultList)
+		return response.SyncResponseContinue(true, resultList, next)
 	}
 
 	// Return detailed list of warnings
-	return response.SyncResponse(true, filteredWarnings)
+	return response.SyncResponseContinue(true, filteredWarnings, next)
 }
 
 // swagger:operation GET /1.0/warnings/{uuid} warnings warning_get
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"time"
+)
+
+// BackupTargetsPost represents the fields available for a new backup target.
+//
+// swagger:model
+//
+// API extension: backup_targets.
+type BackupTargetsPost struct {
+	BackupTargetPut `yaml:",inline"`
+
+	// The name of the backup target
+	// Example: s3
+	Name string `json:"name" yaml:"name"`
+}
+
+// BackupTargetPut represents the modifiable fields of a backup target.
+//
+// swagger:model
+//
+// API extension: backup_targets.
+type BackupTargetPut struct {
+	// Description of the backup target
+	// Example: Offsite backups
+	Description string `json:"description" yaml:"description"`
+
+	// Backup target configuration map (refer to doc/reference/backup_targets.md)
+	// Example: {"endpoint": "https://s3.example.com", "bucket": "backups", "access_key": "ACCESSKEY", "secret_key": "SECRETKEY"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// BackupTarget represents a backup target on S3-compatible object storage.
+//
+// swagger:model
+//
+// API extension: backup_targets.
+type BackupTarget struct {
+	BackupTargetPut `yaml:",inline"`
+
+	// The name of the backup target
+	// Example: s3
+	Name string `json:"name" yaml:"name"`
+}
+
+// Writable converts a full BackupTarget struct into a BackupTargetPut struct (filters read-only fields).
+func (target *BackupTarget) Writable() BackupTargetPut {
+	return target.BackupTargetPut
+}
+
+// SetWritable sets applicable values from BackupTargetPut struct to BackupTarget struct.
+func (target *BackupTarget) SetWritable(put BackupTargetPut) {
+	target.BackupTargetPut = put
+}
+
+// BackupTargetBackup represents a backup stored on a backup target.
+//
+// swagger:model
+//
+// API extension: backup_targets.
+type BackupTargetBackup struct {
+	// Name of the backup on the target, made of the project, instance and backup names
+	// Example: default/c1/backup0
+	Name string `json:"name" yaml:"name"`
+
+	// Size of the backup in bytes
+	// Example: 104857600
+	Size int64 `json:"size" yaml:"size"`
+
+	// When the backup was created
+	// Example: 2021-03-23T16:38:37.753398689-04:00
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
@@ -0,0 +1,28 @@
+package api
+
+// ClusterRebalanceMove represents an instance move planned to even out the load of the cluster members.
+//
+// swagger:model
+//
+// API extension: cluster_rebalance.
+type ClusterRebalanceMove struct {
+	// Name of the instance
+	// Example: c1
+	Instance string `json:"instance" yaml:"instance"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Cluster member currently hosting the instance
+	// Example: lxd01
+	Source string `json:"source" yaml:"source"`
+
+	// Cluster member the instance is moved to
+	// Example: lxd02
+	Target string `json:"target" yaml:"target"`
+
+	// Whether the instance is live migrated
+	// Example: false
+	Live bool `json:"live" yaml:"live"`
+}
@@ -16,6 +16,18 @@ type ClusterMemberSysInfo struct {
 	FreeSwap     uint64    `json:"free_swap" yaml:"free_swap"`
 	Processes    uint16    `json:"processes" yaml:"processes"`
 	LogicalCPUs  uint64    `json:"logical_cpus" yaml:"logical_cpus"`
+
+	// Combined link speed of the physical network interfaces that are up (in bits per second)
+	// Example: 10000000000
+	//
+	// API extension: cluster_scheduler_weights.
+	NetworkBandwidth uint64 `json:"network_bandwidth" yaml:"network_bandwidth"`
+
+	// Throughput of the physical network interfaces in their busiest direction (in bits per second)
+	// Example: 1500000000
+	//
+	// API extension: cluster_scheduler_weights.
+	NetworkThroughput uint64 `json:"network_throughput" yaml:"network_throughput"`
 }
 
 // ClusterMemberState represents the state of a cluster member.
@@ -10,6 +10,7 @@ import (
 const (
 	EventTypeLifecycle = "lifecycle"
 	EventTypeLogging   = "logging"
+	EventTypeNetwork   = "network"
 	EventTypeOperation = "operation"
 	EventTypeOVN       = "ovn"
 	EventTypeSecurity  = "security"
@@ -19,7 +20,7 @@ const (
 //
 // swagger:model
 type Event struct {
-	// Event type (one of operation, logging, lifecycle, ovn or security)
+	// Event type (one of operation, logging, lifecycle, network, ovn or security)
 	// Example: lifecycle
 	Type string `yaml:"type" json:"type"`
 
@@ -27,7 +28,7 @@ type Event struct {
 	// Example: 2021-02-24T19:00:45.452649098-05:00
 	Timestamp time.Time `yaml:"timestamp" json:"timestamp"`
 
-	// JSON encoded metadata (see EventLogging, EventLifecycle, Operation or EventSecurity)
+	// JSON encoded metadata (see EventLogging, EventLifecycle, EventNetworkFlow, Operation or EventSecurity)
 	// Example: {"action": "instance-started", "source": "/1.0/instances/c1", "context": {}}
 	Metadata json.RawMessage `yaml:"metadata" json:"metadata"`
 
@@ -139,6 +140,43 @@ func (event *Event) ToLogging() (EventLogRecord, error) {
 			Msg:  e.Description,
 			Ctx:  ctx,
 		}, nil
+	case EventTypeNetwork:
+		e := &EventNetworkFlow{}
+		err := json.Unmarshal(event.Metadata, &e)
+		if err != nil {
+			return EventLogRecord{}, err
+		}
+
+		ctx := []any{
+			"source", e.Source,
+			"verdict", e.Verdict,
+			"protocol", e.Protocol,
+			"source_address", e.SourceAddress,
+			"destination_address", e.DestinationAddress,
+		}
+
+		if e.SourcePort > 0 {
+			ctx = append(ctx, "source_port", e.SourcePort)
+		}
+
+		if e.DestinationPort > 0 {
+			ctx = append(ctx, "destination_port", e.DestinationPort)
+		}
+
+		for _, field := range [][2]string{{"network", e.Network}, {"project", e.Project}, {"instance", e.Instance}, {"acl", e.ACL}, {"direction", e.Direction}} {
+			if field[1] != "" {
+				ctx = append(ctx, field[0], field[1])
+			}
+		}
+
+		ctx = append(ctx, "bytes_sent", e.BytesSent, "bytes_received", e.BytesReceived)
+
+		return EventLogRecord{
+			Time: event.Timestamp,
+			Lvl:  "info",
+			Msg:  "Network flow",
+			Ctx:  ctx,
+		}, nil
 	case EventTypeOperation:
 		e := &Operation{}
 		err := json.Unmarshal(event.Metadata, &e)
@@ -2,6 +2,11 @@ package api
 
 // Define consts for all the lifecycle events.
 const (
+	EventLifecycleBackupTargetBackupCreated         = "backup-target-backup-created"
+	EventLifecycleBackupTargetBackupDeleted         = "backup-target-backup-deleted"
+	EventLifecycleBackupTargetCreated               = "backup-target-created"
+	EventLifecycleBackupTargetDeleted               = "backup-target-deleted"
+	EventLifecycleBackupTargetUpdated               = "backup-target-updated"
 	EventLifecycleCertificateCreated                = "certificate-created"
 	EventLifecycleCertificateDeleted                = "certificate-deleted"
 	EventLifecycleCertificateUpdated                = "certificate-updated"
@@ -79,6 +84,10 @@ const (
 	EventLifecycleNetworkACLDeleted                 = "network-acl-deleted"
 	EventLifecycleNetworkACLRenamed                 = "network-acl-renamed"
 	EventLifecycleNetworkACLUpdated                 = "network-acl-updated"
+	EventLifecycleNetworkAddressSetCreated          = "network-address-set-created"
+	EventLifecycleNetworkAddressSetDeleted          = "network-address-set-deleted"
+	EventLifecycleNetworkAddressSetRenamed          = "network-address-set-renamed"
+	EventLifecycleNetworkAddressSetUpdated          = "network-address-set-updated"
 	EventLifecycleNetworkCreated                    = "network-created"
 	EventLifecycleNetworkDeleted                    = "network-deleted"
 	EventLifecycleNetworkForwardCreated             = "network-forward-created"
@@ -90,6 +99,10 @@ const (
 	EventLifecycleNetworkPeerCreated                = "network-peer-created"
 	EventLifecycleNetworkPeerDeleted                = "network-peer-deleted"
 	EventLifecycleNetworkPeerUpdated                = "network-peer-updated"
+	EventLifecycleNetworkPortSetCreated             = "network-port-set-created"
+	EventLifecycleNetworkPortSetDeleted             = "network-port-set-deleted"
+	EventLifecycleNetworkPortSetRenamed             = "network-port-set-renamed"
+	EventLifecycleNetworkPortSetUpdated             = "network-port-set-updated"
 	EventLifecycleNetworkRenamed                    = "network-renamed"
 	EventLifecycleNetworkUpdated                    = "network-updated"
 	EventLifecycleNetworkZoneCreated                = "network-zone-created"
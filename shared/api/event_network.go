@@ -0,0 +1,78 @@
+package api
+
+// EventNetworkFlow represents a network flow event entry in the events API.
+//
+// API extension: network_flow_logging.
+type EventNetworkFlow struct {
+	// Origin of the flow record (one of conntrack, nftables or ovn)
+	// Example: conntrack
+	Source string `json:"source" yaml:"source"`
+
+	// Name of the network the flow was seen on (empty if unknown)
+	// Example: lxdbr0
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Project of the instance or network ACL the flow belongs to (empty if unknown)
+	// Example: default
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+
+	// Name of the instance the flow belongs to (empty if unknown)
+	// Example: c1
+	Instance string `json:"instance,omitempty" yaml:"instance,omitempty"`
+
+	// Name of the network ACL that matched the flow (empty if none)
+	// Example: web
+	ACL string `json:"acl,omitempty" yaml:"acl,omitempty"`
+
+	// Direction of the flow relative to the instance (ingress or egress, empty if unknown)
+	// Example: egress
+	Direction string `json:"direction,omitempty" yaml:"direction,omitempty"`
+
+	// Verdict applied to the flow (one of allow, drop or reject)
+	// Example: allow
+	Verdict string `json:"verdict" yaml:"verdict"`
+
+	// Protocol of the flow
+	// Example: tcp
+	Protocol string `json:"protocol" yaml:"protocol"`
+
+	// Source address of the flow
+	// Example: 10.0.0.2
+	SourceAddress string `json:"source_address" yaml:"source_address"`
+
+	// Source port of the flow (TCP and UDP only)
+	// Example: 45678
+	SourcePort uint16 `json:"source_port,omitempty" yaml:"source_port,omitempty"`
+
+	// Destination address of the flow
+	// Example: 192.0.2.1
+	DestinationAddress string `json:"destination_address" yaml:"destination_address"`
+
+	// Destination port of the flow (TCP and UDP only)
+	// Example: 443
+	DestinationPort uint16 `json:"destination_port,omitempty" yaml:"destination_port,omitempty"`
+
+	// ICMP message type (ICMP only)
+	// Example: 8
+	ICMPType string `json:"icmp_type,omitempty" yaml:"icmp_type,omitempty"`
+
+	// ICMP message code (ICMP only)
+	// Example: 0
+	ICMPCode string `json:"icmp_code,omitempty" yaml:"icmp_code,omitempty"`
+
+	// Number of bytes sent from the source to the destination
+	// Example: 1420
+	BytesSent uint64 `json:"bytes_sent" yaml:"bytes_sent"`
+
+	// Number of bytes sent from the destination back to the source
+	// Example: 52800
+	BytesReceived uint64 `json:"bytes_received" yaml:"bytes_received"`
+
+	// Number of packets sent from the source to the destination
+	// Example: 12
+	PacketsSent uint64 `json:"packets_sent" yaml:"packets_sent"`
+
+	// Number of packets sent from the destination back to the source
+	// Example: 40
+	PacketsReceived uint64 `json:"packets_received" yaml:"packets_received"`
+}
@@ -388,3 +388,32 @@ type ImageMetadataTemplate struct {
 	// Example: {"foo": "bar"}
 	Properties map[string]string `json:"properties" yaml:"properties"`
 }
+
+// ImagesStreamsPost represents the fields required to export images to a simplestreams tree
+//
+// swagger:model
+//
+// API extension: images_export_streams.
+type ImagesStreamsPost struct {
+	// Directory on the server holding the simplestreams tree
+	// Example: /srv/images
+	Path string `json:"path" yaml:"path"`
+
+	// Images to add to the tree
+	Images []ImagesStreamsPostImage `json:"images" yaml:"images"`
+}
+
+// ImagesStreamsPostImage represents an image to add to a simplestreams tree
+//
+// swagger:model
+//
+// API extension: images_export_streams.
+type ImagesStreamsPostImage struct {
+	// Fingerprint of the image
+	// Example: 06b86454720d36b20f94e31c6812e05ec51c1b568cf3a8abd273769d213394bb
+	Fingerprint string `json:"fingerprint" yaml:"fingerprint"`
+
+	// Aliases to publish the image under, defaults to the aliases of the image
+	// Example: ["my-distro/2.0"]
+	Aliases []string `json:"aliases" yaml:"aliases"`
+}
@@ -24,6 +24,11 @@ const InstanceTypeContainer = InstanceType("container")
 // InstanceTypeVM defines the instance type value for a virtual-machine.
 const InstanceTypeVM = InstanceType("virtual-machine")
 
+// InstanceTypeKrun defines the instance type value for a libkrun microVM.
+//
+// API extension: instance_type_krun.
+const InstanceTypeKrun = InstanceType("krun")
+
 // SourceType represents source of the instance creation.
 type SourceType string
 
@@ -39,6 +44,11 @@ const SourceTypeImage = SourceType("image")
 // SourceTypeCopy represents instance creation from a copy operation.
 const SourceTypeCopy = SourceType("copy")
 
+// SourceTypeBackup represents instance creation from a backup stored on a backup target.
+//
+// API extension: backup_targets.
+const SourceTypeBackup = SourceType("backup")
+
 // SourceTypeNone represents an unknown source type for instance creation.
 const SourceTypeNone = SourceType("none")
 
@@ -601,6 +611,13 @@ type InstanceSource struct {
 	// Example: false
 	Refresh bool `json:"refresh,omitempty" yaml:"refresh,omitempty"`
 
+	// Whether a refresh should only transfer the snapshots taken after the last snapshot that the source
+	// and target have in common (for migration)
+	// Example: false
+	//
+	// API extension: replicator_incremental
+	RefreshExcludeOlder bool `json:"refresh_exclude_older,omitempty" yaml:"refresh_exclude_older,omitempty"`
+
 	// Source project name (for copy and local image)
 	// Example: blah
 	Project string `json:"project,omitempty" yaml:"project,omitempty"`
@@ -629,6 +646,18 @@ type InstanceSource struct {
 	//
 	// API extension: override_snapshot_profiles_on_copy
 	OverrideSnapshotProfiles bool `json:"override_snapshot_profiles" yaml:"override_snapshot_profiles"`
+
+	// Name of the backup target (for backup)
+	// Example: s3
+	//
+	// API extension: backup_targets
+	BackupTarget string `json:"backup_target,omitempty" yaml:"backup_target,omitempty"`
+
+	// Name of the backup on the backup target (for backup)
+	// Example: default/c1/backup0
+	//
+	// API extension: backup_targets
+	Backup string `json:"backup,omitempty" yaml:"backup,omitempty"`
 }
 
 // InstanceUEFIVars represents the UEFI variables of a LXD virtual machine.
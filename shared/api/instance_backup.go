@@ -43,6 +43,12 @@ type InstanceBackupsPost struct {
 	//
 	// API extension: backup_metadata_version
 	Version uint32 `json:"version" yaml:"version"`
+
+	// Name of the backup target to write the backup to, instead of the local server
+	// Example: s3
+	//
+	// API extension: backup_targets
+	BackupTarget string `json:"backup_target,omitempty" yaml:"backup_target,omitempty"`
 }
 
 // InstanceBackup represents a LXD instance backup.
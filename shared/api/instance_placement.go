@@ -0,0 +1,76 @@
+package api
+
+// InstancePlacementReasonNew is used when placing a new instance.
+//
+// API extension: instances_placement_webhook.
+const InstancePlacementReasonNew = "new"
+
+// InstancePlacementReasonRelocation is used when moving an existing instance to another cluster member.
+//
+// API extension: instances_placement_webhook.
+const InstancePlacementReasonRelocation = "relocation"
+
+// InstancePlacementWebhookRequest represents the request sent to the instance placement webhook.
+//
+// swagger:model
+//
+// API extension: instances_placement_webhook.
+type InstancePlacementWebhookRequest struct {
+	// Reason for the placement request
+	// Example: new
+	Reason string `json:"reason" yaml:"reason"`
+
+	// Project of the instance
+	// Example: default
+	Project string `json:"project" yaml:"project"`
+
+	// Instance being placed
+	Instance InstancesPost `json:"instance" yaml:"instance"`
+
+	// Cluster members the instance can be placed on
+	Candidates []InstancePlacementWebhookCandidate `json:"candidates" yaml:"candidates"`
+}
+
+// InstancePlacementWebhookCandidate represents a cluster member the instance can be placed on.
+//
+// swagger:model
+//
+// API extension: instances_placement_webhook.
+type InstancePlacementWebhookCandidate struct {
+	// Name of the cluster member
+	// Example: lxd01
+	Name string `json:"name" yaml:"name"`
+
+	// Cluster member description
+	// Example: AMD Epyc 32c/64t
+	Description string `json:"description" yaml:"description"`
+
+	// Architecture of the cluster member
+	// Example: x86_64
+	Architecture string `json:"architecture" yaml:"architecture"`
+
+	// Cluster groups the member belongs to
+	// Example: ["default"]
+	Groups []string `json:"groups" yaml:"groups"`
+
+	// Cluster member configuration
+	// Example: {"scheduler.instance": "all"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// InstancePlacementWebhookResponse represents the response of the instance placement webhook.
+//
+// If neither the target nor the error is set, LXD applies its built-in placement policy.
+//
+// swagger:model
+//
+// API extension: instances_placement_webhook.
+type InstancePlacementWebhookResponse struct {
+	// Name of the cluster member to place the instance on
+	// Example: lxd01
+	Target string `json:"target" yaml:"target"`
+
+	// Reason for rejecting the placement request
+	// Example: Not enough GPUs available
+	Error string `json:"error" yaml:"error"`
+}
@@ -146,6 +146,35 @@ type InstanceStateNetwork struct {
 	// Type of interface (broadcast, loopback, point-to-point, ...)
 	// Example: broadcast
 	Type string `json:"type" yaml:"type"`
+
+	// QoS class of the interface
+	//
+	// API extension: network_qos
+	QoS *InstanceStateNetworkQoS `json:"qos,omitempty" yaml:"qos,omitempty"`
+}
+
+// InstanceStateNetworkQoS represents the QoS class of a network interface as part of the network section of
+// a LXD instance's state.
+//
+// swagger:model
+//
+// API extension: network_qos.
+type InstanceStateNetworkQoS struct {
+	// Name of the QoS class
+	// Example: gold
+	Class string `json:"class" yaml:"class"`
+
+	// Guaranteed rate in bit/s
+	// Example: 100000000
+	Rate int64 `json:"rate" yaml:"rate"`
+
+	// Maximum rate in bit/s
+	// Example: 1000000000
+	Ceil int64 `json:"ceil" yaml:"ceil"`
+
+	// Burst size in bytes (0 for the default)
+	// Example: 0
+	Burst int64 `json:"burst" yaml:"burst"`
 }
 
 // InstanceStateNetworkAddress represents a network address as part of the network section of a LXD
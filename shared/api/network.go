@@ -213,6 +213,11 @@ type NetworkState struct {
 	//
 	// API extension: network_state_ovn
 	OVN *NetworkStateOVN `json:"ovn" yaml:"ovn"`
+
+	// WireGuard tunnel information, keyed by tunnel name
+	//
+	// API extension: network_bridge_tunnel_wireguard
+	WireGuard map[string]NetworkStateWireGuard `json:"wireguard,omitempty" yaml:"wireguard,omitempty"`
 }
 
 // NetworkStateAddress represents a network address
@@ -347,3 +352,18 @@ type NetworkStateOVN struct {
 	// OVN network chassis name
 	Chassis string `json:"chassis" yaml:"chassis"`
 }
+
+// NetworkStateWireGuard represents WireGuard tunnel specific state
+//
+// swagger:model
+//
+// API extension: network_bridge_tunnel_wireguard.
+type NetworkStateWireGuard struct {
+	// Public key of the local end of the tunnel
+	// Example: hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=
+	PublicKey string `json:"public_key" yaml:"public_key"`
+
+	// UDP port the tunnel listens on
+	// Example: 51820
+	ListenPort int64 `json:"listen_port" yaml:"listen_port"`
+}
@@ -0,0 +1,130 @@
+package api
+
+// NetworkAddressSetPost used for renaming an address set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkAddressSetPost struct {
+	// lxdmeta:generate(entities=network-address-set; group=set-properties; key=name)
+	//
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Unique name of the network address set in the project
+
+	// The new name for the address set
+	// Example: web-servers
+	Name string `json:"name" yaml:"name"` // Name of address set.
+}
+
+// NetworkAddressSetPut used for updating an address set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkAddressSetPut struct {
+	// lxdmeta:generate(entities=network-address-set; group=set-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the network address set
+
+	// Description of the address set
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=network-address-set; group=set-properties; key=addresses)
+	// Each entry can be an IP address, a CIDR subnet, `instance:<name>` for the addresses of an instance,
+	// `profile:<name>` for the addresses of all instances using a profile or
+	// `forward:<network>/<listen_address>` for the listen and target addresses of a network forward.
+	// ---
+	//  type: string list
+	//  required: no
+	//  shortdesc: Addresses and address references in the set
+
+	// List of addresses and address references
+	// Example: ["10.0.0.0/24", "instance:c1", "profile:web"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// lxdmeta:generate(entities=network-address-set; group=set-properties; key=config)
+	// The only supported keys are `user.*` custom keys.
+	// ---
+	//  type: string set
+	//  required: no
+	//  shortdesc: User-provided free-form key/value pairs
+
+	// Address set configuration map
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkAddressSet used for displaying an address set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkAddressSet struct {
+	// The name of the address set
+	// Example: web-servers
+	Name string `json:"name" yaml:"name"` // Name of address set.
+
+	// Description of the address set
+	// Example: Web servers
+	Description string `json:"description" yaml:"description"`
+
+	// List of addresses and address references
+	// Example: ["10.0.0.0/24", "instance:c1", "profile:web"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+
+	// Address set configuration map
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of objects using this address set
+	// Read only: true
+	// Example: ["/1.0/network-acls/web"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // Resources that use the address set.
+
+	// Project name
+	// Example: project1
+	Project string `json:"project" yaml:"project"` // Project the address set belongs to.
+}
+
+// Writable converts a full NetworkAddressSet struct into a NetworkAddressSetPut struct (filters read-only fields).
+func (set *NetworkAddressSet) Writable() NetworkAddressSetPut {
+	return NetworkAddressSetPut{
+		Description: set.Description,
+		Addresses:   set.Addresses,
+		Config:      set.Config,
+	}
+}
+
+// SetWritable sets applicable values from NetworkAddressSetPut struct to NetworkAddressSet struct.
+func (set *NetworkAddressSet) SetWritable(put NetworkAddressSetPut) {
+	set.Description = put.Description
+	set.Addresses = put.Addresses
+	set.Config = put.Config
+}
+
+// NetworkAddressSetsPost used for creating an address set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkAddressSetsPost struct {
+	NetworkAddressSetPost `yaml:",inline"`
+	NetworkAddressSetPut  `yaml:",inline"`
+}
+
+// NetworkAddressSetState represents the resolved addresses of an address set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkAddressSetState struct {
+	// Addresses the set currently resolves to
+	// Example: ["10.0.0.0/24", "10.0.0.12", "fd42::12"]
+	Addresses []string `json:"addresses" yaml:"addresses"`
+}
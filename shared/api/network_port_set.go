@@ -0,0 +1,117 @@
+package api
+
+// NetworkPortSetPost used for renaming a port set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkPortSetPost struct {
+	// lxdmeta:generate(entities=network-port-set; group=set-properties; key=name)
+	//
+	// ---
+	//  type: string
+	//  required: yes
+	//  shortdesc: Unique name of the network port set in the project
+
+	// The new name for the port set
+	// Example: web
+	Name string `json:"name" yaml:"name"` // Name of port set.
+}
+
+// NetworkPortSetPut used for updating a port set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkPortSetPut struct {
+	// lxdmeta:generate(entities=network-port-set; group=set-properties; key=description)
+	//
+	// ---
+	//  type: string
+	//  required: no
+	//  shortdesc: Description of the network port set
+
+	// Description of the port set
+	// Example: Web ports
+	Description string `json:"description" yaml:"description"`
+
+	// lxdmeta:generate(entities=network-port-set; group=set-properties; key=ports)
+	// Each entry can be a port or a port range (start-end inclusive).
+	// ---
+	//  type: string list
+	//  required: no
+	//  shortdesc: Ports and port ranges in the set
+
+	// List of ports and port ranges
+	// Example: ["80", "443", "8000-8080"]
+	Ports []string `json:"ports" yaml:"ports"`
+
+	// lxdmeta:generate(entities=network-port-set; group=set-properties; key=config)
+	// The only supported keys are `user.*` custom keys.
+	// ---
+	//  type: string set
+	//  required: no
+	//  shortdesc: User-provided free-form key/value pairs
+
+	// Port set configuration map
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// NetworkPortSet used for displaying a port set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkPortSet struct {
+	// The name of the port set
+	// Example: web
+	Name string `json:"name" yaml:"name"` // Name of port set.
+
+	// Description of the port set
+	// Example: Web ports
+	Description string `json:"description" yaml:"description"`
+
+	// List of ports and port ranges
+	// Example: ["80", "443", "8000-8080"]
+	Ports []string `json:"ports" yaml:"ports"`
+
+	// Port set configuration map
+	// Example: {"user.mykey": "foo"}
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// List of URLs of objects using this port set
+	// Read only: true
+	// Example: ["/1.0/network-acls/web"]
+	UsedBy []string `json:"used_by" yaml:"used_by"` // Resources that use the port set.
+
+	// Project name
+	// Example: project1
+	Project string `json:"project" yaml:"project"` // Project the port set belongs to.
+}
+
+// Writable converts a full NetworkPortSet struct into a NetworkPortSetPut struct (filters read-only fields).
+func (set *NetworkPortSet) Writable() NetworkPortSetPut {
+	return NetworkPortSetPut{
+		Description: set.Description,
+		Ports:       set.Ports,
+		Config:      set.Config,
+	}
+}
+
+// SetWritable sets applicable values from NetworkPortSetPut struct to NetworkPortSet struct.
+func (set *NetworkPortSet) SetWritable(put NetworkPortSetPut) {
+	set.Description = put.Description
+	set.Ports = put.Ports
+	set.Config = put.Config
+}
+
+// NetworkPortSetsPost used for creating a port set.
+//
+// swagger:model
+//
+// API extension: network_acl_sets.
+type NetworkPortSetsPost struct {
+	NetworkPortSetPost `yaml:",inline"`
+	NetworkPortSetPut  `yaml:",inline"`
+}
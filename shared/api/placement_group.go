@@ -18,6 +18,18 @@ const (
 	PlacementRigorPermissive string = "permissive"
 )
 
+const (
+	// PlacementScopeMember applies the placement policy and rules per cluster member.
+	//
+	// API extension: placement_group_affinity.
+	PlacementScopeMember string = "member"
+
+	// PlacementScopeFailureDomain applies the placement policy and rules per failure domain.
+	//
+	// API extension: placement_group_affinity.
+	PlacementScopeFailureDomain string = "failure-domain"
+)
+
 // PlacementGroup represents a group of instances that should be scheduled.
 //
 // API extension: instance_placement_groups.
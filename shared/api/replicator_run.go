@@ -0,0 +1,69 @@
+package api
+
+import (
+	"time"
+)
+
+const (
+	// ReplicatorRunInstanceStatusSucceeded represents an instance that was successfully replicated.
+	ReplicatorRunInstanceStatusSucceeded = "Succeeded"
+
+	// ReplicatorRunInstanceStatusFailed represents an instance that failed to replicate.
+	ReplicatorRunInstanceStatusFailed = "Failed"
+
+	// ReplicatorRunInstanceStatusSkipped represents an instance that was intentionally not replicated.
+	ReplicatorRunInstanceStatusSkipped = "Skipped"
+)
+
+// ReplicatorRun represents a single past run of a replicator.
+//
+// swagger:model
+//
+// API extension: replicator_run_history.
+type ReplicatorRun struct {
+	// Action performed by the run (start or restore).
+	// Example: start
+	Action string `json:"action" yaml:"action"`
+
+	// Timestamp when the run started.
+	// Example: 2021-03-23T17:38:37.753398689-04:00
+	StartedAt time.Time `json:"started_at" yaml:"started_at"`
+
+	// Timestamp when the run finished.
+	// Example: 2021-03-23T17:42:12.753398689-04:00
+	FinishedAt time.Time `json:"finished_at" yaml:"finished_at"`
+
+	// Status of the run (Completed or Failed).
+	// Example: Completed
+	Status string `json:"status" yaml:"status"`
+
+	// Total number of bytes transferred by the run.
+	// Example: 1073741824
+	BytesTransferred int64 `json:"bytes_transferred" yaml:"bytes_transferred"`
+
+	// Outcome of the run for each instance.
+	Instances []ReplicatorRunInstance `json:"instances" yaml:"instances"`
+}
+
+// ReplicatorRunInstance represents the outcome of a replicator run for a single instance.
+//
+// swagger:model
+//
+// API extension: replicator_run_history.
+type ReplicatorRunInstance struct {
+	// Name of the instance.
+	// Example: c1
+	Name string `json:"name" yaml:"name"`
+
+	// Outcome for the instance (Succeeded, Failed or Skipped).
+	// Example: Skipped
+	Status string `json:"status" yaml:"status"`
+
+	// Reason the instance failed or was skipped.
+	// Example: Instance was deleted on the current leader cluster
+	Reason string `json:"reason" yaml:"reason"`
+
+	// Number of bytes transferred for the instance.
+	// Example: 536870912
+	BytesTransferred int64 `json:"bytes_transferred" yaml:"bytes_transferred"`
+}
@@ -12,6 +12,10 @@ type ResponseRaw struct {
 	Status     string `json:"status" yaml:"status"`
 	StatusCode int    `json:"status_code" yaml:"status_code"`
 
+	// Valid only for paginated Sync responses
+	// API extension: collection_pagination
+	Continue string `json:"continue,omitempty" yaml:"continue,omitempty"`
+
 	// Valid only for Async responses
 	Operation string `json:"operation" yaml:"operation"`
 
@@ -30,6 +34,10 @@ type Response struct {
 	Status     string `json:"status" yaml:"status"`
 	StatusCode int    `json:"status_code" yaml:"status_code"`
 
+	// Valid only for paginated Sync responses
+	// API extension: collection_pagination
+	Continue string `json:"continue,omitempty" yaml:"continue,omitempty"`
+
 	// Valid only for Async responses
 	Operation string `json:"operation" yaml:"operation"`
 
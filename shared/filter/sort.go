@@ -0,0 +1,154 @@
+package filter
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SortKey is a single field to sort a collection by.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// String returns the query string representation of the sort key.
+func (k SortKey) String() string {
+	if k.Descending {
+		return "-" + k.Field
+	}
+
+	return k.Field
+}
+
+// ParseSort parses a user-provided, comma separated, list of fields to sort by.
+// A field prefixed with "-" is sorted in descending order.
+func ParseSort(s string) ([]SortKey, error) {
+	keys := []SortKey{}
+
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return keys, nil
+	}
+
+	for field := range strings.SplitSeq(s, ",") {
+		key := SortKey{Field: strings.TrimSpace(field)}
+
+		key.Field, key.Descending = strings.CutPrefix(key.Field, "-")
+		if key.Field == "" {
+			return nil, errors.New("Empty sort field")
+		}
+
+		if strings.ContainsAny(key.Field, " \t") {
+			return nil, fmt.Errorf("Invalid sort field %q", key.Field)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// FormatSort returns the query string representation of the sort keys.
+func FormatSort(keys []SortKey) string {
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, key.String())
+	}
+
+	return strings.Join(fields, ",")
+}
+
+// Sort performs a stable sort of the objects using the given sort keys.
+// Objects with a missing value for a field are sorted before the others.
+func Sort[T any](objs []T, keys []SortKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var err error
+
+	slices.SortStableFunc(objs, func(a T, b T) int {
+		if err != nil {
+			return 0
+		}
+
+		for _, key := range keys {
+			var res int
+
+			res, err = CompareValues(ValueOf(a, key.Field), ValueOf(b, key.Field), key.Field)
+			if err != nil {
+				return 0
+			}
+
+			if res == 0 {
+				continue
+			}
+
+			if key.Descending {
+				return -res
+			}
+
+			return res
+		}
+
+		return 0
+	})
+
+	return err
+}
+
+// CompareValues compares two values of a sort field, returning a negative number if a sorts before b, a positive
+// number if a sorts after b and zero if they are equal. Missing (nil) values sort before the others.
+func CompareValues(a any, b any, field string) (int, error) {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0, nil
+		case a == nil:
+			return -1, nil
+		default:
+			return 1, nil
+		}
+	}
+
+	aTime, ok := a.(time.Time)
+	if ok {
+		bTime, _ := b.(time.Time)
+		return aTime.Compare(bTime), nil
+	}
+
+	aValue := reflect.ValueOf(a)
+	bValue := reflect.ValueOf(b)
+
+	if aValue.Kind() != bValue.Kind() {
+		return 0, fmt.Errorf("Mismatched types %q and %q for sort field %q", aValue.Kind().String(), bValue.Kind().String(), field)
+	}
+
+	switch aValue.Kind() {
+	case reflect.String:
+		return cmp.Compare(aValue.String(), bValue.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(aValue.Int(), bValue.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return cmp.Compare(aValue.Uint(), bValue.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(aValue.Float(), bValue.Float()), nil
+	case reflect.Bool:
+		if aValue.Bool() == bValue.Bool() {
+			return 0, nil
+		}
+
+		if aValue.Bool() {
+			return 1, nil
+		}
+
+		return -1, nil
+	}
+
+	return 0, fmt.Errorf("Invalid type %q for sort field %q", aValue.Kind().String(), field)
+}
@@ -0,0 +1,105 @@
+package filter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/filter"
+)
+
+func TestParseSort(t *testing.T) {
+	cases := map[string][]filter.SortKey{
+		"":                     {},
+		"name":                 {{Field: "name"}},
+		"-created_at":          {{Field: "created_at", Descending: true}},
+		"project, -name":       {{Field: "project"}, {Field: "name", Descending: true}},
+		"config.user.some-key": {{Field: "config.user.some-key"}},
+	}
+
+	for s, want := range cases {
+		t.Run(s, func(t *testing.T) {
+			keys, err := filter.ParseSort(s)
+			require.NoError(t, err)
+			assert.Equal(t, want, keys)
+		})
+	}
+}
+
+func TestParseSort_Error(t *testing.T) {
+	cases := map[string]string{
+		"name,":     "Empty sort field",
+		"-":         "Empty sort field",
+		"name,,foo": "Empty sort field",
+		"foo bar":   `Invalid sort field "foo bar"`,
+	}
+
+	for s, message := range cases {
+		t.Run(s, func(t *testing.T) {
+			_, err := filter.ParseSort(s)
+			assert.EqualError(t, err, message)
+		})
+	}
+}
+
+func TestFormatSort(t *testing.T) {
+	keys, err := filter.ParseSort("project,-name")
+	require.NoError(t, err)
+	assert.Equal(t, "project,-name", filter.FormatSort(keys))
+}
+
+func TestSort_Instance(t *testing.T) {
+	now := time.Now()
+
+	instances := []*api.Instance{
+		{Name: "c1", Project: "p2", Stateful: true, CreatedAt: now, Config: map[string]string{"user.rank": "b"}},
+		{Name: "c2", Project: "p1", Stateful: false, CreatedAt: now.Add(-time.Hour), Config: map[string]string{"user.rank": "a"}},
+		{Name: "c3", Project: "p1", Stateful: true, CreatedAt: now.Add(time.Hour)},
+	}
+
+	names := func() []string {
+		result := make([]string, 0, len(instances))
+		for _, inst := range instances {
+			result = append(result, inst.Name)
+		}
+
+		return result
+	}
+
+	cases := []struct {
+		sort string
+		want []string
+	}{
+		{"name", []string{"c1", "c2", "c3"}},
+		{"-name", []string{"c3", "c2", "c1"}},
+		{"project,-name", []string{"c3", "c2", "c1"}},
+		{"created_at", []string{"c2", "c1", "c3"}},
+		{"-stateful,name", []string{"c1", "c3", "c2"}},
+		{"config.user.rank", []string{"c3", "c2", "c1"}},
+		{"unknown,name", []string{"c1", "c2", "c3"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.sort, func(t *testing.T) {
+			keys, err := filter.ParseSort(c.sort)
+			require.NoError(t, err)
+
+			err = filter.Sort(instances, keys)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, names())
+		})
+	}
+}
+
+func TestSort_Error(t *testing.T) {
+	instances := []api.Instance{
+		{Name: "c1", Profiles: []string{"default"}},
+		{Name: "c2", Profiles: []string{"default"}},
+	}
+
+	err := filter.Sort(instances, []filter.SortKey{{Field: "profiles"}})
+	assert.EqualError(t, err, `Invalid type "slice" for sort field "profiles"`)
+}
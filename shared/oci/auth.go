@@ -0,0 +1,224 @@
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// authTransport authenticates the requests made to a registry. The authentication scheme (basic or bearer token)
+// is selected from the challenge returned by the registry when a request is rejected.
+type authTransport struct {
+	base      http.RoundTripper
+	host      string
+	username  string
+	password  string
+	useragent string
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Never send the registry credentials to other hosts, such as the storage backends blobs are redirected to.
+	if req.URL.Host != t.host {
+		return t.base.RoundTrip(req)
+	}
+
+	scope := repositoryScope(req.URL.Path)
+
+	t.mu.Lock()
+	authorization := t.tokens[scope]
+	t.mu.Unlock()
+
+	resp, err := t.roundTrip(req, authorization)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || req.Body != nil {
+		return resp, err
+	}
+
+	authorization, err = t.authenticate(req.Context(), resp.Header.Get("WWW-Authenticate"), scope)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+
+	if authorization == "" {
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+
+	t.mu.Lock()
+	t.tokens[scope] = authorization
+	t.mu.Unlock()
+
+	return t.roundTrip(req, authorization)
+}
+
+// roundTrip performs the request with the given Authorization header.
+func (t *authTransport) roundTrip(req *http.Request, authorization string) (*http.Response, error) {
+	req = req.Clone(req.Context())
+
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	return t.base.RoundTrip(req)
+}
+
+// authenticate answers the challenge of the registry and returns the resulting Authorization header.
+// An empty header is returned if the challenge can't be answered.
+func (t *authTransport) authenticate(ctx context.Context, challenge string, scope string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if t.username == "" {
+			return "", nil
+		}
+
+		req := http.Request{Header: http.Header{}}
+		req.SetBasicAuth(t.username, t.password)
+
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := t.fetchToken(ctx, params, scope)
+		if err != nil {
+			return "", err
+		}
+
+		return "Bearer " + token, nil
+	}
+
+	return "", nil
+}
+
+// fetchToken retrieves a bearer token from the authorization service referenced by the challenge parameters.
+func (t *authTransport) fetchToken(ctx context.Context, params map[string]string, scope string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Scheme != "https" {
+		return "", fmt.Errorf("Invalid registry authentication realm %q", params["realm"])
+	}
+
+	if params["scope"] != "" {
+		scope = params["scope"]
+	}
+
+	values := realm.Query()
+	if params["service"] != "" {
+		values.Set("service", params["service"])
+	}
+
+	if scope != "" {
+		values.Set("scope", scope)
+	}
+
+	realm.RawQuery = values.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if t.useragent != "" {
+		req.Header.Set("User-Agent", t.useragent)
+	}
+
+	if t.username != "" {
+		req.SetBasicAuth(t.username, t.password)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return "", fmt.Errorf("Failed getting registry token: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Failed getting registry token: %s", resp.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxManifestSize)).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("Failed parsing registry token: %w", err)
+	}
+
+	if token.Token != "" {
+		return token.Token, nil
+	}
+
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+
+	return "", fmt.Errorf("Registry token service %q returned an empty token", realm.Host)
+}
+
+// repositoryScope returns the token scope needed to pull from the repository addressed by a registry API path.
+func repositoryScope(urlPath string) string {
+	repository, ok := strings.CutPrefix(urlPath, "/v2/")
+	if !ok {
+		return ""
+	}
+
+	for _, kind := range []string{"/manifests/", "/blobs/"} {
+		idx := strings.LastIndex(repository, kind)
+		if idx > 0 {
+			return "repository:" + repository[:idx] + ":pull"
+		}
+	}
+
+	return ""
+}
+
+// parseChallenge parses the value of a WWW-Authenticate header into its scheme and parameters.
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+
+	for rest != "" {
+		var key, value string
+
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		key = strings.ToLower(strings.TrimSpace(key))
+
+		if strings.HasPrefix(rest, `"`) {
+			// Quoted values may contain commas and escaped characters.
+			var sb strings.Builder
+
+			i := 1
+			for ; i < len(rest) && rest[i] != '"'; i++ {
+				if rest[i] == '\\' && i+1 < len(rest) {
+					i++
+				}
+
+				sb.WriteByte(rest[i])
+			}
+
+			value = sb.String()
+			rest = rest[min(i+1, len(rest)):]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+
+		if key != "" {
+			params[key] = value
+		}
+	}
+
+	return scheme, params
+}
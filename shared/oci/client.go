@@ -0,0 +1,489 @@
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/ioprogress"
+	"github.com/canonical/lxd/shared/osarch"
+)
+
+// maxManifestSize is the maximum size of the manifests and configuration blobs fetched from a registry.
+const maxManifestSize = 4 * 1024 * 1024
+
+// maxConfigSize is the maximum size of the image configuration blobs, which include the image history.
+const maxConfigSize = 16 * 1024 * 1024
+
+// manifestMediaTypes are the manifest media types accepted from registries.
+var manifestMediaTypes = []string{
+	MediaTypeImageIndex,
+	MediaTypeImageManifest,
+	MediaTypeDockerManifestList,
+	MediaTypeDockerManifest,
+}
+
+// dockerHubHosts are the host names under which the Docker Hub registry is known.
+var dockerHubHosts = []string{"docker.io", "index.docker.io", "registry-1.docker.io"}
+
+// Client is a client for OCI distribution registries.
+type Client struct {
+	http      *http.Client
+	url       string
+	useragent string
+	dockerHub bool
+
+	cachePath   string
+	cacheExpiry time.Duration
+}
+
+// NewClient returns a client for the registry at the provided URL. Credentials included in the URL are used to
+// authenticate with the registry.
+func NewClient(registryURL string, httpClient http.Client, useragent string) (*Client, error) {
+	u, err := url.Parse(registryURL)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid registry URL %q: %w", registryURL, err)
+	}
+
+	transport := &authTransport{
+		base:      httpClient.Transport,
+		useragent: useragent,
+		tokens:    map[string]string{},
+	}
+
+	if transport.base == nil {
+		transport.base = http.DefaultTransport
+	}
+
+	if u.User != nil {
+		transport.username = u.User.Username()
+		transport.password, _ = u.User.Password()
+		u.User = nil
+	}
+
+	// Docker Hub serves its registry API from a dedicated host name.
+	dockerHub := slices.Contains(dockerHubHosts, u.Host)
+	if dockerHub {
+		u.Host = "registry-1.docker.io"
+	}
+
+	transport.host = u.Host
+	httpClient.Transport = transport
+
+	return &Client{
+		http:      &httpClient,
+		url:       strings.TrimSuffix(u.String(), "/"),
+		useragent: useragent,
+		dockerHub: dockerHub,
+	}, nil
+}
+
+// SetCache configures the on-disk cache of image layers.
+// Layers which haven't been used for longer than the expiry are removed from the cache.
+func (c *Client) SetCache(path string, expiry time.Duration) {
+	c.cachePath = path
+	c.cacheExpiry = expiry
+}
+
+// Resolve fetches the manifest and configuration of the image matching the reference for the given architecture.
+// The digest of the reference is set to the one of the architecture specific manifest.
+func (c *Client) Resolve(ctx context.Context, ref Reference, architecture string) (*Image, error) {
+	archID, err := osarch.ArchitectureId(architecture)
+	if err != nil {
+		return nil, err
+	}
+
+	body, mediaType, digest, err := c.getManifest(ctx, ref.Repository, ref.manifestReference())
+	if err != nil {
+		return nil, err
+	}
+
+	if isIndex(mediaType) {
+		var index Index
+
+		err = json.Unmarshal(body, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", ref.String(), err)
+		}
+
+		digest = ""
+		for _, desc := range index.Manifests {
+			if desc.Platform == nil || desc.Platform.OS != "linux" {
+				continue
+			}
+
+			id, err := platformArchitectureID(desc.Platform.Architecture, desc.Platform.Variant)
+			if err == nil && id == archID {
+				digest = desc.Digest
+				break
+			}
+		}
+
+		if digest == "" {
+			return nil, api.StatusErrorf(http.StatusNotFound, "Image %q isn't available for architecture %q", ref.String(), architecture)
+		}
+
+		body, mediaType, digest, err = c.getManifest(ctx, ref.Repository, digest)
+		if err != nil {
+			return nil, err
+		}
+
+		if isIndex(mediaType) {
+			return nil, fmt.Errorf("Image %q has nested image indexes", ref.String())
+		}
+	}
+
+	img := &Image{
+		Reference:    ref,
+		Architecture: architecture,
+	}
+
+	img.Reference.Digest = digest
+
+	err = json.Unmarshal(body, &img.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", ref.String(), err)
+	}
+
+	if !slices.Contains([]string{MediaTypeImageConfig, MediaTypeDockerConfig}, img.Manifest.Config.MediaType) {
+		return nil, fmt.Errorf("Unsupported image configuration type %q", img.Manifest.Config.MediaType)
+	}
+
+	config, err := c.getBlob(ctx, ref.Repository, img.Manifest.Config, maxConfigSize)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(config, &img.Config)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image configuration of %q: %w", ref.String(), err)
+	}
+
+	if img.Config.OS != "linux" {
+		return nil, fmt.Errorf("Image %q is built for unsupported operating system %q", ref.String(), img.Config.OS)
+	}
+
+	id, err := platformArchitectureID(img.Config.Architecture, img.Config.Variant)
+	if err != nil || id != archID {
+		return nil, api.StatusErrorf(http.StatusNotFound, "Image %q isn't available for architecture %q", ref.String(), architecture)
+	}
+
+	return img, nil
+}
+
+// Architectures returns the digests of the manifests of the image matching the reference, indexed by architecture.
+func (c *Client) Architectures(ctx context.Context, ref Reference) (map[string]string, error) {
+	body, mediaType, digest, err := c.getManifest(ctx, ref.Repository, ref.manifestReference())
+	if err != nil {
+		return nil, err
+	}
+
+	digests := map[string]string{}
+
+	if isIndex(mediaType) {
+		var index Index
+
+		err = json.Unmarshal(body, &index)
+		if err != nil {
+			return nil, fmt.Errorf("Failed parsing image index of %q: %w", ref.String(), err)
+		}
+
+		for _, desc := range index.Manifests {
+			if desc.Platform == nil || desc.Platform.OS != "linux" {
+				continue
+			}
+
+			id, err := platformArchitectureID(desc.Platform.Architecture, desc.Platform.Variant)
+			if err != nil {
+				continue
+			}
+
+			name, err := osarch.ArchitectureName(id)
+			if err != nil {
+				continue
+			}
+
+			_, found := digests[name]
+			if !found {
+				digests[name] = desc.Digest
+			}
+		}
+
+		return digests, nil
+	}
+
+	// Single platform images only record their architecture in their configuration.
+	var manifest Manifest
+
+	err = json.Unmarshal(body, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image manifest of %q: %w", ref.String(), err)
+	}
+
+	config, err := c.getBlob(ctx, ref.Repository, manifest.Config, maxConfigSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageConfig ImageConfig
+
+	err = json.Unmarshal(config, &imageConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Failed parsing image configuration of %q: %w", ref.String(), err)
+	}
+
+	id, err := platformArchitectureID(imageConfig.Architecture, imageConfig.Variant)
+	if err == nil && imageConfig.OS == "linux" {
+		name, err := osarch.ArchitectureName(id)
+		if err == nil {
+			digests[name] = digest
+		}
+	}
+
+	return digests, nil
+}
+
+// repository returns the name of the repository as known by the registry.
+func (c *Client) repository(name string) string {
+	// Official Docker Hub images live in the "library" namespace.
+	if c.dockerHub && !strings.Contains(name, "/") {
+		return "library/" + name
+	}
+
+	return name
+}
+
+// get performs a GET request against the registry API and returns the response.
+func (c *Client) get(ctx context.Context, urlPath string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+urlPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.useragent != "" {
+		req.Header.Set("User-Agent", c.useragent)
+	}
+
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, api.StatusErrorf(resp.StatusCode, "Failed fetching %q from registry: %s", urlPath, resp.Status)
+	}
+
+	return resp, nil
+}
+
+// getManifest fetches a manifest by tag or digest and returns its content, media type and digest.
+func (c *Client) getManifest(ctx context.Context, repository string, reference string) ([]byte, string, string, error) {
+	resp, err := c.get(ctx, "/v2/"+c.repository(repository)+"/manifests/"+reference, manifestMediaTypes)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := readLimited(resp.Body, maxManifestSize)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	hash := sha256.Sum256(body)
+	digest := "sha256:" + hex.EncodeToString(hash[:])
+
+	if digestRegexp.MatchString(reference) && reference != digest {
+		return nil, "", "", fmt.Errorf("Manifest digest mismatch: %s != %s", digest, reference)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	// Fall back to the media type embedded in the manifest for registries not returning it.
+	if !slices.Contains(manifestMediaTypes, mediaType) {
+		var manifest struct {
+			MediaType string `json:"mediaType"`
+			Manifests []any  `json:"manifests"`
+		}
+
+		err = json.Unmarshal(body, &manifest)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("Failed parsing manifest: %w", err)
+		}
+
+		mediaType = manifest.MediaType
+		if mediaType == "" && manifest.Manifests != nil {
+			mediaType = MediaTypeImageIndex
+		}
+	}
+
+	if !slices.Contains(manifestMediaTypes, mediaType) {
+		return nil, "", "", fmt.Errorf("Unsupported manifest type %q", mediaType)
+	}
+
+	return body, mediaType, digest, nil
+}
+
+// getBlob fetches a small blob, such as an image configuration, into memory and verifies its digest.
+func (c *Client) getBlob(ctx context.Context, repository string, desc Descriptor, maxSize int64) ([]byte, error) {
+	if !digestRegexp.MatchString(desc.Digest) {
+		return nil, fmt.Errorf("Unsupported blob digest %q", desc.Digest)
+	}
+
+	if desc.Size > maxSize {
+		return nil, fmt.Errorf("Blob %q exceeds the maximum size of %d bytes", desc.Digest, maxSize)
+	}
+
+	resp, err := c.get(ctx, "/v2/"+c.repository(repository)+"/blobs/"+desc.Digest, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := readLimited(resp.Body, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(body)
+	if "sha256:"+hex.EncodeToString(hash[:]) != desc.Digest {
+		return nil, fmt.Errorf("Blob digest mismatch for %q", desc.Digest)
+	}
+
+	return body, nil
+}
+
+// openLayer returns the content of an image layer, downloading it into the cache if needed.
+// The returned file is positioned at its start and must be closed by the caller.
+func (c *Client) openLayer(ctx context.Context, repository string, desc Descriptor, name string, progress func(ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller) (*os.File, error) {
+	hash, ok := strings.CutPrefix(desc.Digest, "sha256:")
+	if !ok || !digestRegexp.MatchString(desc.Digest) {
+		return nil, fmt.Errorf("Unsupported layer digest %q", desc.Digest)
+	}
+
+	cacheName := ""
+	if c.cachePath != "" {
+		cacheName = filepath.Join(c.cachePath, hash)
+
+		f, err := os.Open(cacheName)
+		if err == nil {
+			// Refresh the modification time so layers in use don't expire.
+			now := time.Now()
+			_ = os.Chtimes(cacheName, now, now)
+
+			return f, nil
+		}
+	}
+
+	f, err := os.CreateTemp(c.cachePath, "oci_")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = shared.DownloadFileHash(ctx, c.http, c.useragent, progress, canceler, name, c.url+"/v2/"+c.repository(repository)+"/blobs/"+desc.Digest, hash, sha256.New(), f, desc.Size)
+	if err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+
+		return nil, fmt.Errorf("Failed downloading layer %q: %w", desc.Digest, err)
+	}
+
+	// Files without a cache are unlinked right away and only live as long as they are open.
+	if cacheName != "" {
+		err = os.Rename(f.Name(), cacheName)
+	} else {
+		err = os.Remove(f.Name())
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// pruneCache removes the expired layers from the cache.
+func (c *Client) pruneCache() {
+	if c.cachePath == "" || c.cacheExpiry == 0 {
+		return
+	}
+
+	entries, err := os.ReadDir(c.cachePath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+
+		if time.Since(info.ModTime()) > c.cacheExpiry {
+			_ = os.Remove(filepath.Join(c.cachePath, entry.Name()))
+		}
+	}
+}
+
+// isIndex returns whether the manifest media type is the one of a multi-platform image index.
+func isIndex(mediaType string) bool {
+	return mediaType == MediaTypeImageIndex || mediaType == MediaTypeDockerManifestList
+}
+
+// platformArchitectureID returns the LXD architecture ID matching an OCI platform architecture and variant.
+func platformArchitectureID(architecture string, variant string) (int, error) {
+	if architecture == "arm" {
+		switch variant {
+		case "v6":
+			return osarch.ARCH_32BIT_ARMV6_LITTLE_ENDIAN, nil
+		case "v8":
+			return osarch.ARCH_32BIT_ARMV8_LITTLE_ENDIAN, nil
+		default:
+			return osarch.ARCH_32BIT_ARMV7_LITTLE_ENDIAN, nil
+		}
+	}
+
+	return osarch.ArchitectureId(architecture)
+}
+
+// readLimited reads the whole reader, failing if it holds more than the given number of bytes.
+func readLimited(r io.Reader, maxSize int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > maxSize {
+		return nil, errors.New("Registry response exceeds the maximum size")
+	}
+
+	return body, nil
+}
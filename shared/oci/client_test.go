@@ -0,0 +1,278 @@
+package oci_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/oci"
+)
+
+// testRegistry is a minimal registry serving a single multi-platform image and requiring bearer tokens.
+type testRegistry struct {
+	server  *httptest.Server
+	blobs   map[string][]byte
+	tags    map[string]string
+	digests map[string]string
+}
+
+// add stores a blob in the registry and returns its descriptor.
+func (r *testRegistry) add(mediaType string, content []byte) oci.Descriptor {
+	hash := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(hash[:])
+	r.blobs[digest] = content
+
+	return oci.Descriptor{MediaType: mediaType, Digest: digest, Size: int64(len(content))}
+}
+
+// addJSON stores a JSON document in the registry and returns its descriptor.
+func (r *testRegistry) addJSON(t *testing.T, mediaType string, v any) oci.Descriptor {
+	t.Helper()
+
+	content, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	desc := r.add(mediaType, content)
+	r.digests[desc.Digest] = mediaType
+
+	return desc
+}
+
+func (r *testRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if req.URL.Query().Get("scope") != "repository:library/app:pull" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "secret"})
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer secret" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="`+r.server.URL+`/token",service="registry.test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	reference, isManifest := strings.CutPrefix(req.URL.Path, "/v2/library/app/manifests/")
+	if isManifest {
+		digest, ok := r.tags[reference]
+		if !ok {
+			digest = reference
+		}
+
+		mediaType, ok := r.digests[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", mediaType)
+		_, _ = w.Write(r.blobs[digest])
+		return
+	}
+
+	digest, isBlob := strings.CutPrefix(req.URL.Path, "/v2/library/app/blobs/")
+	content, ok := r.blobs[digest]
+	if !isBlob || !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	_, _ = w.Write(content)
+}
+
+// gzipLayer returns the gzip compressed form of a tar stream.
+func gzipLayer(t *testing.T, layer io.Reader) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+
+	_, err := io.Copy(gz, layer)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	return buf.Bytes()
+}
+
+func newTestRegistry(t *testing.T) *testRegistry {
+	t.Helper()
+
+	r := &testRegistry{
+		blobs:   map[string][]byte{},
+		tags:    map[string]string{},
+		digests: map[string]string{},
+	}
+
+	r.server = httptest.NewTLSServer(r)
+	t.Cleanup(r.server.Close)
+
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	manifests := []oci.Descriptor{}
+	for _, arch := range []string{"amd64", "arm64"} {
+		config := r.addJSON(t, oci.MediaTypeImageConfig, oci.ImageConfig{
+			Created:      &created,
+			Architecture: arch,
+			OS:           "linux",
+			Config: oci.ContainerConfig{
+				User:         "app",
+				ExposedPorts: map[string]struct{}{"80/tcp": {}, "443/tcp": {}},
+				Env:          []string{"PATH=/usr/bin:/bin", "APP_MODE=" + arch},
+				Entrypoint:   []string{"/bin/app"},
+				Cmd:          []string{"--listen", ":80 :443"},
+				WorkingDir:   "/srv",
+			},
+		})
+
+		layer := r.add(oci.MediaTypeLayerGzip, gzipLayer(t, buildLayer(t,
+			layerEntry{name: "bin/app", typeflag: tar.TypeReg, content: arch},
+		)))
+
+		manifest := r.addJSON(t, oci.MediaTypeImageManifest, oci.Manifest{
+			SchemaVersion: 2,
+			MediaType:     oci.MediaTypeImageManifest,
+			Config:        config,
+			Layers:        []oci.Descriptor{layer},
+		})
+
+		manifest.Platform = &oci.Platform{OS: "linux", Architecture: arch}
+		manifests = append(manifests, manifest)
+	}
+
+	index := r.addJSON(t, oci.MediaTypeImageIndex, oci.Index{
+		SchemaVersion: 2,
+		MediaType:     oci.MediaTypeImageIndex,
+		Manifests:     manifests,
+	})
+
+	r.tags["1.0"] = index.Digest
+
+	return r
+}
+
+func newTestClient(t *testing.T, r *testRegistry) *oci.Client {
+	t.Helper()
+
+	client, err := oci.NewClient(r.server.URL, *r.server.Client(), "")
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestClient_Resolve(t *testing.T) {
+	r := newTestRegistry(t)
+	client := newTestClient(t, r)
+
+	ref, err := oci.ParseReference("library/app:1.0")
+	require.NoError(t, err)
+
+	img, err := client.Resolve(context.Background(), *ref, "aarch64")
+	require.NoError(t, err)
+
+	assert.Equal(t, "aarch64", img.Architecture)
+	assert.Equal(t, "arm64", img.Config.Architecture)
+	assert.Equal(t, "sha256:"+img.Fingerprint(), img.Reference.Digest)
+	assert.Equal(t, oci.MediaTypeImageManifest, r.digests[img.Reference.Digest])
+
+	assert.Equal(t, map[string]string{
+		"architecture":     "aarch64",
+		"description":      "library/app:1.0",
+		"oci.digest":       img.Reference.Digest,
+		"oci.entrypoint":   `/bin/app --listen ':80 :443'`,
+		"oci.cwd":          "/srv",
+		"oci.user":         "app",
+		"oci.env.PATH":     "/usr/bin:/bin",
+		"oci.env.APP_MODE": "arm64",
+		"oci.ports":        "443/tcp,80/tcp",
+	}, img.Properties())
+
+	assert.Equal(t, map[string]string{
+		"oci.entrypoint":       `/bin/app --listen ':80 :443'`,
+		"oci.cwd":              "/srv",
+		"oci.user":             "app",
+		"environment.PATH":     "/usr/bin:/bin",
+		"environment.APP_MODE": "arm64",
+	}, oci.InstanceConfig(img.Properties()))
+
+	_, err = client.Resolve(context.Background(), *ref, "s390x")
+	assert.ErrorContains(t, err, `Image "library/app:1.0" isn't available for architecture "s390x"`)
+
+	missing, err := oci.ParseReference("library/app:2.0")
+	require.NoError(t, err)
+
+	_, err = client.Resolve(context.Background(), *missing, "aarch64")
+	assert.Error(t, err)
+}
+
+func TestClient_Architectures(t *testing.T) {
+	r := newTestRegistry(t)
+	client := newTestClient(t, r)
+
+	ref, err := oci.ParseReference("library/app:1.0")
+	require.NoError(t, err)
+
+	digests, err := client.Architectures(context.Background(), *ref)
+	require.NoError(t, err)
+	assert.Len(t, digests, 2)
+
+	// Single platform manifests are resolved through their configuration.
+	ref.Digest = digests["x86_64"]
+
+	digests, err = client.Architectures(context.Background(), *ref)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"x86_64": ref.Digest}, digests)
+}
+
+func TestClient_Export(t *testing.T) {
+	r := newTestRegistry(t)
+	client := newTestClient(t, r)
+
+	cachePath := t.TempDir()
+	client.SetCache(cachePath, time.Hour)
+
+	ref, err := oci.ParseReference("library/app:1.0")
+	require.NoError(t, err)
+
+	img, err := client.Resolve(context.Background(), *ref, "x86_64")
+	require.NoError(t, err)
+
+	export := func() []byte {
+		buf := &bytes.Buffer{}
+
+		err := client.Export(context.Background(), img, buf, nil, nil)
+		require.NoError(t, err)
+
+		return buf.Bytes()
+	}
+
+	first := export()
+
+	// The layer is served from the cache once downloaded.
+	delete(r.blobs, img.Manifest.Layers[0].Digest)
+	second := export()
+	assert.Equal(t, first, second)
+
+	gz, err := gzip.NewReader(bytes.NewReader(first))
+	require.NoError(t, err)
+
+	files := readTar(t, gz)
+	assert.Equal(t, "amd64", files["rootfs/bin/app"])
+	assert.Contains(t, files["metadata.yaml"], "architecture: x86_64")
+	assert.Contains(t, files["metadata.yaml"], "oci.entrypoint: /bin/app --listen ':80 :443'")
+}
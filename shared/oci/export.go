@@ -0,0 +1,254 @@
+package oci
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/cancel"
+	"github.com/canonical/lxd/shared/ioprogress"
+)
+
+// Whiteout file names used by layers to delete the content of lower layers.
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// Export downloads the layers of the image and writes them, flattened into a single root filesystem, as a
+// unified LXD image tarball. The output only depends on the image, so exporting an image twice results in
+// identical tarballs.
+func (c *Client) Export(ctx context.Context, img *Image, w io.Writer, progress func(ioprogress.ProgressData), canceler *cancel.HTTPRequestCanceller) error {
+	c.pruneCache()
+
+	layers := make([]io.Reader, 0, len(img.Manifest.Layers))
+
+	for i, desc := range img.Manifest.Layers {
+		f, err := c.openLayer(ctx, img.Reference.Repository, desc, fmt.Sprintf("layer %d/%d", i+1, len(img.Manifest.Layers)), progress, canceler)
+		if err != nil {
+			return err
+		}
+
+		defer func() { _ = f.Close() }()
+
+		layer, err := decompressLayer(f, desc.MediaType)
+		if err != nil {
+			return err
+		}
+
+		layers = append(layers, layer)
+	}
+
+	var created time.Time
+	if img.Config.Created != nil {
+		created = img.Config.Created.UTC()
+	}
+
+	metadata, err := yaml.Marshal(api.ImageMetadata{
+		Architecture: img.Architecture,
+		CreationDate: created.Unix(),
+		Properties:   img.Properties(),
+	})
+	if err != nil {
+		return err
+	}
+
+	gzWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzWriter)
+
+	err = tarWriter.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     "metadata.yaml",
+		Mode:     0644,
+		Size:     int64(len(metadata)),
+		ModTime:  created,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(metadata)
+	if err != nil {
+		return err
+	}
+
+	err = Flatten(tarWriter, layers, "rootfs", created)
+	if err != nil {
+		return err
+	}
+
+	err = tarWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzWriter.Close()
+}
+
+// decompressLayer returns the uncompressed tar stream of a layer blob.
+func decompressLayer(r io.Reader, mediaType string) (io.Reader, error) {
+	switch mediaType {
+	case MediaTypeLayer:
+		return r, nil
+	case MediaTypeLayerGzip, MediaTypeDockerLayerGzip:
+		return gzip.NewReader(r)
+	}
+
+	return nil, fmt.Errorf("Unsupported layer type %q", mediaType)
+}
+
+// flattenEntry records the state of a path of the flattened filesystem.
+type flattenEntry struct {
+	dir     bool
+	deleted bool
+}
+
+// Flatten writes the content of the layer tar streams, ordered from the lowest to the topmost layer, into a single
+// tar stream under the given directory. Files from upper layers replace those of lower layers and whiteout files
+// are applied. Layers are read from the topmost one down so that only the files visible in the resulting
+// filesystem are written, which allows the output to be written without unpacking the layers to disk.
+func Flatten(tw *tar.Writer, layers []io.Reader, dir string, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+		ModTime:  modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	entries := map[string]flattenEntry{}
+	opaque := map[string]bool{}
+	hardlinks := []*tar.Header{}
+
+	// hidden returns whether a parent directory of the path was deleted, replaced or made opaque by upper layers.
+	hidden := func(name string) bool {
+		if opaque["."] {
+			return true
+		}
+
+		for parent := path.Dir(name); parent != "."; parent = path.Dir(parent) {
+			entry, found := entries[parent]
+			if found && (entry.deleted || !entry.dir) {
+				return true
+			}
+
+			if opaque[parent] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for i := len(layers) - 1; i >= 0; i-- {
+		layerOpaque := []string{}
+		tr := tar.NewReader(layers[i])
+
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return fmt.Errorf("Failed reading layer %d: %w", i+1, err)
+			}
+
+			if hdr.Typeflag == tar.TypeXGlobalHeader {
+				continue
+			}
+
+			name := cleanLayerPath(hdr.Name)
+			if name == "." {
+				continue
+			}
+
+			parent, base := path.Split(name)
+			parent = path.Clean(parent)
+
+			// Opaque directories hide the content of the lower layers once this layer is done.
+			if base == whiteoutOpaque {
+				layerOpaque = append(layerOpaque, parent)
+				continue
+			}
+
+			deleted, isWhiteout := strings.CutPrefix(base, whiteoutPrefix)
+			if isWhiteout {
+				name = path.Join(parent, deleted)
+			}
+
+			_, found := entries[name]
+			if found || hidden(name) {
+				continue
+			}
+
+			if isWhiteout {
+				entries[name] = flattenEntry{deleted: true}
+				continue
+			}
+
+			entries[name] = flattenEntry{dir: hdr.Typeflag == tar.TypeDir}
+
+			hdr.Name = dir + "/" + name
+			hdr.Format = tar.FormatUnknown
+
+			// Hard links are written last so their target is always written before them.
+			if hdr.Typeflag == tar.TypeLink {
+				hdr.Linkname = cleanLayerPath(hdr.Linkname)
+				hardlinks = append(hardlinks, hdr)
+				continue
+			}
+
+			err = tw.WriteHeader(hdr)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.Copy(tw, tr)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, name := range layerOpaque {
+			opaque[name] = true
+		}
+	}
+
+	for _, hdr := range hardlinks {
+		entry, found := entries[hdr.Linkname]
+		if !found || entry.deleted || entry.dir {
+			continue
+		}
+
+		hdr.Linkname = dir + "/" + hdr.Linkname
+
+		err := tw.WriteHeader(hdr)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanLayerPath returns the normalized form of a path found in a layer, relative to the root of the filesystem.
+// Paths attempting to escape the root are confined to it.
+func cleanLayerPath(name string) string {
+	name = path.Clean("/" + name)
+	if name == "/" {
+		return "."
+	}
+
+	return strings.TrimPrefix(name, "/")
+}
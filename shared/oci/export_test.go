@@ -0,0 +1,122 @@
+package oci_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/oci"
+)
+
+// layerEntry is a file of a test layer.
+type layerEntry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// buildLayer returns a tar stream holding the given entries.
+func buildLayer(t *testing.T, entries ...layerEntry) io.Reader {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Typeflag: entry.typeflag,
+			Mode:     0644,
+			Size:     int64(len(entry.content)),
+			Linkname: entry.linkname,
+		}
+
+		require.NoError(t, tw.WriteHeader(hdr))
+
+		_, err := tw.Write([]byte(entry.content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return buf
+}
+
+// readTar returns the content of the regular files of a tar stream and the targets of its links, indexed by name.
+func readTar(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	files := map[string]string{}
+	tr := tar.NewReader(r)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			content, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			files[hdr.Name] = string(content)
+		case tar.TypeDir:
+			files[hdr.Name] = "<dir>"
+		default:
+			files[hdr.Name] = "-> " + hdr.Linkname
+		}
+	}
+
+	return files
+}
+
+func TestFlatten(t *testing.T) {
+	layers := []io.Reader{
+		buildLayer(t,
+			layerEntry{name: "etc/", typeflag: tar.TypeDir},
+			layerEntry{name: "etc/hostname", typeflag: tar.TypeReg, content: "base"},
+			layerEntry{name: "etc/removed", typeflag: tar.TypeReg, content: "removed"},
+			layerEntry{name: "var/cache/", typeflag: tar.TypeDir},
+			layerEntry{name: "var/cache/old", typeflag: tar.TypeReg, content: "old"},
+			layerEntry{name: "bin/sh", typeflag: tar.TypeReg, content: "shell"},
+			layerEntry{name: "opt/", typeflag: tar.TypeDir},
+			layerEntry{name: "opt/file", typeflag: tar.TypeReg, content: "replaced by a file"},
+		),
+		buildLayer(t,
+			layerEntry{name: "./etc/hostname", typeflag: tar.TypeReg, content: "upper"},
+			layerEntry{name: "etc/.wh.removed", typeflag: tar.TypeReg},
+			layerEntry{name: "var/cache/.wh..wh..opq", typeflag: tar.TypeReg},
+			layerEntry{name: "var/cache/new", typeflag: tar.TypeReg, content: "new"},
+			layerEntry{name: "bin/bash", typeflag: tar.TypeLink, linkname: "bin/sh"},
+			layerEntry{name: "opt", typeflag: tar.TypeReg, content: "file"},
+			layerEntry{name: "/../escape", typeflag: tar.TypeSymlink, linkname: "/etc"},
+		),
+	}
+
+	buf := &bytes.Buffer{}
+	tw := tar.NewWriter(buf)
+
+	err := oci.Flatten(tw, layers, "rootfs", time.Time{})
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	assert.Equal(t, map[string]string{
+		"rootfs/":              "<dir>",
+		"rootfs/etc":           "<dir>",
+		"rootfs/etc/hostname":  "upper",
+		"rootfs/var/cache":     "<dir>",
+		"rootfs/var/cache/new": "new",
+		"rootfs/bin/sh":        "shell",
+		"rootfs/bin/bash":      "-> rootfs/bin/sh",
+		"rootfs/opt":           "file",
+		"rootfs/escape":        "-> /etc",
+	}, readTar(t, buf))
+}
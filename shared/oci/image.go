@@ -0,0 +1,102 @@
+package oci
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+)
+
+// Image is an image resolved for a single architecture.
+type Image struct {
+	// Reference is the reference of the image, with the digest of its manifest.
+	Reference Reference
+
+	// Architecture is the LXD name of the architecture of the image.
+	Architecture string
+
+	Manifest Manifest
+	Config   ImageConfig
+}
+
+// Fingerprint returns the hexadecimal digest of the image manifest.
+func (i *Image) Fingerprint() string {
+	return strings.TrimPrefix(i.Reference.Digest, "sha256:")
+}
+
+// Size returns the compressed size of the image layers.
+func (i *Image) Size() int64 {
+	var size int64
+	for _, layer := range i.Manifest.Layers {
+		size += layer.Size
+	}
+
+	return size
+}
+
+// Properties returns the LXD image properties describing the image and its execution parameters.
+//
+// The execution parameters are recorded under the "oci." prefix: "oci.entrypoint" holds the shell quoted command
+// made of the image entrypoint and arguments, "oci.cwd" its working directory, "oci.user" the user running it,
+// "oci.env.<NAME>" its environment variables and "oci.ports" the comma separated list of exposed ports.
+func (i *Image) Properties() map[string]string {
+	description := i.Reference.Repository
+	if i.Reference.Tag != "" {
+		description += ":" + i.Reference.Tag
+	}
+
+	properties := map[string]string{
+		"architecture": i.Architecture,
+		"description":  description,
+		"oci.digest":   i.Reference.Digest,
+	}
+
+	config := i.Config.Config
+
+	command := append(slices.Clone(config.Entrypoint), config.Cmd...)
+	if len(command) > 0 {
+		properties["oci.entrypoint"] = shellquote.Join(command...)
+	}
+
+	if config.WorkingDir != "" {
+		properties["oci.cwd"] = config.WorkingDir
+	}
+
+	if config.User != "" {
+		properties["oci.user"] = config.User
+	}
+
+	for _, env := range config.Env {
+		key, value, found := strings.Cut(env, "=")
+		if found && key != "" {
+			properties["oci.env."+key] = value
+		}
+	}
+
+	if len(config.ExposedPorts) > 0 {
+		properties["oci.ports"] = strings.Join(slices.Sorted(maps.Keys(config.ExposedPorts)), ",")
+	}
+
+	return properties
+}
+
+// InstanceConfig translates the properties of an image built from an OCI image into the configuration of the
+// containers created from it. Images without such properties result in an empty configuration.
+func InstanceConfig(properties map[string]string) map[string]string {
+	config := map[string]string{}
+
+	for key, value := range properties {
+		name, found := strings.CutPrefix(key, "oci.env.")
+		if found {
+			config["environment."+name] = value
+			continue
+		}
+
+		if slices.Contains([]string{"oci.entrypoint", "oci.cwd", "oci.user"}, key) {
+			config[key] = value
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,88 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultTag is the tag used for references which don't specify one.
+const DefaultTag = "latest"
+
+var (
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+	digestRegexp     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Reference identifies an image within a registry.
+type Reference struct {
+	// Repository is the name of the repository, for example "library/nginx".
+	Repository string
+
+	// Tag is the tag of the image within the repository.
+	Tag string
+
+	// Digest is the digest of the image manifest, it takes precedence over the tag when set.
+	Digest string
+}
+
+// ParseReference parses an image reference of the form "repository[:tag][@digest]".
+func ParseReference(s string) (*Reference, error) {
+	ref := &Reference{}
+
+	name, digest, found := strings.Cut(s, "@")
+	if found {
+		if !digestRegexp.MatchString(digest) {
+			return nil, fmt.Errorf("Invalid image digest %q", digest)
+		}
+
+		ref.Digest = digest
+	}
+
+	// Only a colon after the last slash separates a tag, others are part of a registry host name.
+	idx := strings.LastIndex(name, ":")
+	if idx > strings.LastIndex(name, "/") {
+		ref.Tag = name[idx+1:]
+		name = name[:idx]
+
+		if !tagRegexp.MatchString(ref.Tag) {
+			return nil, fmt.Errorf("Invalid image tag %q", ref.Tag)
+		}
+	}
+
+	if !repositoryRegexp.MatchString(name) {
+		return nil, fmt.Errorf("Invalid image repository %q", name)
+	}
+
+	ref.Repository = name
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = DefaultTag
+	}
+
+	return ref, nil
+}
+
+// String returns the string representation of the reference.
+func (r Reference) String() string {
+	s := r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+
+	return s
+}
+
+// manifestReference returns the tag or digest to fetch the image manifest with.
+func (r Reference) manifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+
+	return r.Tag
+}
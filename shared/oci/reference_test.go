@@ -0,0 +1,53 @@
+package oci_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/oci"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	cases := map[string]oci.Reference{
+		"nginx":                       {Repository: "nginx", Tag: "latest"},
+		"nginx:1.27":                  {Repository: "nginx", Tag: "1.27"},
+		"library/nginx:stable-alpine": {Repository: "library/nginx", Tag: "stable-alpine"},
+		"org/app@" + digest:           {Repository: "org/app", Digest: digest},
+		"org/app:v1@" + digest:        {Repository: "org/app", Tag: "v1", Digest: digest},
+	}
+
+	for s, want := range cases {
+		t.Run(s, func(t *testing.T) {
+			ref, err := oci.ParseReference(s)
+			require.NoError(t, err)
+			assert.Equal(t, want, *ref)
+		})
+	}
+}
+
+func TestParseReference_Error(t *testing.T) {
+	cases := map[string]string{
+		"Nginx":          `Invalid image repository "Nginx"`,
+		"nginx:":         `Invalid image tag ""`,
+		"nginx:-foo":     `Invalid image tag "-foo"`,
+		"nginx@sha256:1": `Invalid image digest "sha256:1"`,
+		"../nginx":       `Invalid image repository "../nginx"`,
+	}
+
+	for s, message := range cases {
+		t.Run(s, func(t *testing.T) {
+			_, err := oci.ParseReference(s)
+			assert.EqualError(t, err, message)
+		})
+	}
+}
+
+func TestReference_String(t *testing.T) {
+	ref, err := oci.ParseReference("library/nginx")
+	require.NoError(t, err)
+	assert.Equal(t, "library/nginx:latest", ref.String())
+}
@@ -0,0 +1,95 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ResolveUser resolves the user specification of an image, of the form "user[:group]" where both parts can either
+// be a name or a numeric ID, using the user and group databases of the given root filesystem.
+func ResolveUser(root *os.Root, user string) (uid int64, gid int64, err error) {
+	userName, groupName, hasGroup := strings.Cut(user, ":")
+
+	uid, userGID, err := lookupID(root, "etc/passwd", userName, true)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	if !hasGroup {
+		return uid, userGID, nil
+	}
+
+	gid, _, err = lookupID(root, "etc/group", groupName, false)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return uid, gid, nil
+}
+
+// lookupID looks up a user or group by name or ID in a passwd or group file. For users, the ID of the primary group
+// of the user is also returned.
+func lookupID(root *os.Root, file string, name string, isUser bool) (int64, int64, error) {
+	id, err := parseID(name)
+	if err == nil && !isUser {
+		return id, -1, nil
+	}
+
+	content, err := root.ReadFile(file)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return -1, -1, err
+	}
+
+	for line := range strings.SplitSeq(string(content), "\n") {
+		// Both passwd and group lines are of the form "name:password:id:..." and passwd lines follow with the primary group ID.
+		fields := strings.Split(line, ":")
+		if len(fields) < 3 || (isUser && len(fields) < 4) {
+			continue
+		}
+
+		if fields[0] != name && fields[2] != name {
+			continue
+		}
+
+		lineID, err := parseID(fields[2])
+		if err != nil {
+			continue
+		}
+
+		if !isUser {
+			return lineID, -1, nil
+		}
+
+		groupID, err := parseID(fields[3])
+		if err != nil {
+			continue
+		}
+
+		return lineID, groupID, nil
+	}
+
+	// Numeric users missing from the database run with the root group.
+	id, err = parseID(name)
+	if err == nil {
+		return id, 0, nil
+	}
+
+	if isUser {
+		return -1, -1, fmt.Errorf("User %q not found in the container", name)
+	}
+
+	return -1, -1, fmt.Errorf("Group %q not found in the container", name)
+}
+
+// parseID parses a numeric user or group ID.
+func parseID(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return -1, fmt.Errorf("Invalid ID %q", value)
+	}
+
+	return id, nil
+}
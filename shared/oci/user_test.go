@@ -0,0 +1,53 @@
+package oci_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/canonical/lxd/shared/oci"
+)
+
+func TestResolveUser(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "etc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "etc", "passwd"), []byte("root:x:0:0:root:/root:/bin/sh\nnginx:x:101:102:nginx:/var/cache/nginx:/sbin/nologin\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "etc", "group"), []byte("root:x:0:\nnginx:x:102:\nwww-data:x:33:\n"), 0644))
+
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+
+	defer func() { _ = root.Close() }()
+
+	cases := []struct {
+		user string
+		uid  int64
+		gid  int64
+	}{
+		{"root", 0, 0},
+		{"nginx", 101, 102},
+		{"101", 101, 102},
+		{"nginx:www-data", 101, 33},
+		{"nginx:1000", 101, 1000},
+		{"1000", 1000, 0},
+		{"1000:1000", 1000, 1000},
+	}
+
+	for _, c := range cases {
+		t.Run(c.user, func(t *testing.T) {
+			uid, gid, err := oci.ResolveUser(root, c.user)
+			require.NoError(t, err)
+			assert.Equal(t, c.uid, uid)
+			assert.Equal(t, c.gid, gid)
+		})
+	}
+
+	_, _, err = oci.ResolveUser(root, "missing")
+	assert.EqualError(t, err, `User "missing" not found in the container`)
+
+	_, _, err = oci.ResolveUser(root, "nginx:missing")
+	assert.EqualError(t, err, `Group "missing" not found in the container`)
+}
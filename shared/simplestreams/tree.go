@@ -0,0 +1,471 @@
+package simplestreams
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Paths and identifiers used by the trees written by LXD.
+const (
+	treeIndexPath    = "streams/v1/index.json"
+	treeProductsPath = "streams/v1/images.json"
+	treeContentID    = "images"
+	treeImagesDir    = "images"
+)
+
+// treeVersionLayout is the layout of the version names, as parsed by [Products.ToLXD].
+const treeVersionLayout = "20060102_1504"
+
+// Tree is a simplestreams tree stored in a local directory, suitable to be served by a plain web server.
+// LXD images can be added to it, in which case the index and products are updated when saving the tree.
+type Tree struct {
+	path         string
+	stream       Stream
+	products     Products
+	productsPath string
+}
+
+// OpenTree loads the simplestreams tree stored in the given directory.
+// The directory is initialized as an empty tree if it doesn't hold one yet.
+func OpenTree(dir string) (*Tree, error) {
+	if !shared.IsDir(dir) {
+		return nil, fmt.Errorf("Directory %q does not exist", dir)
+	}
+
+	t := &Tree{
+		path: dir,
+		stream: Stream{
+			Index:  map[string]StreamIndex{},
+			Format: "index:1.0",
+		},
+		products: Products{
+			ContentID: treeContentID,
+			DataType:  "image-downloads",
+			Format:    "products:1.0",
+			Products:  map[string]Product{},
+		},
+		productsPath: treeProductsPath,
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, treeIndexPath))
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &t.stream)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding stream index %q: %w", treeIndexPath, err)
+	}
+
+	if t.stream.Index == nil {
+		t.stream.Index = map[string]StreamIndex{}
+	}
+
+	entry, ok := t.stream.Index[treeContentID]
+	if !ok {
+		return t, nil
+	}
+
+	if entry.DataType != "image-downloads" || !filepath.IsLocal(entry.Path) {
+		return nil, fmt.Errorf("Unsupported stream index entry %q", treeContentID)
+	}
+
+	t.productsPath = entry.Path
+
+	content, err = os.ReadFile(filepath.Join(dir, t.productsPath))
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, &t.products)
+	if err != nil {
+		return nil, fmt.Errorf("Failed decoding products %q: %w", t.productsPath, err)
+	}
+
+	if t.products.Products == nil {
+		t.products.Products = map[string]Product{}
+	}
+
+	return t, nil
+}
+
+// HasImage returns whether the image with the given fingerprint is in the tree along with all its files.
+func (t *Tree) HasImage(fingerprint string) bool {
+	images, downloads := t.products.ToLXD()
+
+	for _, image := range images {
+		if image.Fingerprint != fingerprint {
+			continue
+		}
+
+		for _, file := range downloads[fingerprint] {
+			if !filepath.IsLocal(file[0]) {
+				return false
+			}
+
+			info, err := os.Stat(filepath.Join(t.path, file[0]))
+			if err != nil || strconv.FormatInt(info.Size(), 10) != file[3] {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// AddImage adds an image to the tree, moving its metadata and root filesystem files into it.
+// The root filesystem path is empty for unified images. The files must be on the same filesystem as the tree.
+//
+// The image is published under the given aliases, which are removed from the other products of the same
+// architecture.
+func (t *Tree) AddImage(image api.Image, aliases []string, metaPath string, rootfsPath string) error {
+	architecture := image.Properties["architecture"]
+	if architecture == "" {
+		architecture = image.Architecture
+	}
+
+	// Work out the files and the item types.
+	metaName := "lxd_combined"
+	metaType := "lxd_combined.tar.gz"
+	rootfsType := ""
+	combinedFields := []*string{}
+	items := map[string]ProductVersionItem{}
+
+	hash := sha256.New()
+
+	metaItem, err := treeFileItem(metaPath, hash)
+	if err != nil {
+		return err
+	}
+
+	if rootfsPath != "" {
+		metaName = "lxd"
+		metaType = "lxd.tar.xz"
+
+		_, ext, _, err := shared.DetectCompression(rootfsPath)
+		if err != nil {
+			return fmt.Errorf("Failed detecting root filesystem format: %w", err)
+		}
+
+		rootfsItem, err := treeFileItem(rootfsPath, hash)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case image.Type == string(api.InstanceTypeVM) && ext == ".qcow2":
+			rootfsType = "disk-kvm.img"
+			combinedFields = append(combinedFields, &metaItem.LXDHashSha256DiskKvmImg)
+		case image.Type != string(api.InstanceTypeVM) && ext == ".squashfs":
+			rootfsType = "squashfs"
+			combinedFields = append(combinedFields, &metaItem.LXDHashSha256SquashFs)
+		case image.Type != string(api.InstanceTypeVM) && ext == ".tar.xz":
+			rootfsType = "root.tar.xz"
+			combinedFields = append(combinedFields, &metaItem.LXDHashSha256RootXz, &metaItem.LXDHashSha256)
+		default:
+			return fmt.Errorf("Unsupported %s root filesystem format %q", image.Type, ext)
+		}
+
+		rootfsItem.FileType = rootfsType
+		rootfsItem.Path = path.Join(treeImagesDir, image.Fingerprint, "rootfs"+ext)
+		items[treeItemName(image.Type, rootfsType)] = rootfsItem
+	} else if image.Type == string(api.InstanceTypeVM) {
+		return errors.New("Unified virtual-machine images are not supported")
+	}
+
+	fingerprint := hex.EncodeToString(hash.Sum(nil))
+	if fingerprint != image.Fingerprint {
+		return fmt.Errorf("Image files don't match fingerprint %q", image.Fingerprint)
+	}
+
+	for _, field := range combinedFields {
+		*field = fingerprint
+	}
+
+	_, ext, _, err := shared.DetectCompression(metaPath)
+	if err != nil {
+		return fmt.Errorf("Failed detecting metadata format: %w", err)
+	}
+
+	metaItem.FileType = metaType
+	metaItem.Path = path.Join(treeImagesDir, image.Fingerprint, metaName+ext)
+
+	items[treeItemName(image.Type, metaType)] = metaItem
+
+	// Record the image in its product.
+	productName, product := t.product(image, architecture)
+
+	created := image.CreatedAt
+	if created.IsZero() || created.Unix() <= 0 {
+		created = image.UploadedAt
+	}
+
+	versionName := created.UTC().Format(treeVersionLayout)
+	version, ok := product.Versions[versionName]
+	if !ok || version.Items == nil {
+		version = ProductVersion{
+			Items: map[string]ProductVersionItem{},
+			Label: image.Properties["label"],
+		}
+	}
+
+	for name, item := range items {
+		existing, ok := version.Items[name]
+		if ok && existing.Path != item.Path {
+			return fmt.Errorf("Version %q of product %q already holds a different image", versionName, productName)
+		}
+	}
+
+	// Move the files into the tree.
+	imageDir := filepath.Join(t.path, treeImagesDir, image.Fingerprint)
+
+	err = os.MkdirAll(imageDir, 0755)
+	if err != nil {
+		return err
+	}
+
+	err = t.moveFile(metaPath, metaItem.Path)
+	if err != nil {
+		return err
+	}
+
+	if rootfsPath != "" {
+		err = t.moveFile(rootfsPath, items[treeItemName(image.Type, rootfsType)].Path)
+		if err != nil {
+			return err
+		}
+	}
+
+	maps.Copy(version.Items, items)
+	product.Versions[versionName] = version
+	t.products.Products[productName] = product
+
+	t.setAliases(productName, aliases)
+
+	return nil
+}
+
+// AddAliases publishes the product holding the image with the given fingerprint under additional aliases.
+// As with any alias of a product, they resolve to its newest image.
+func (t *Tree) AddAliases(fingerprint string, aliases []string) error {
+	for name, product := range t.products.Products {
+		for _, version := range product.Versions {
+			for _, item := range version.Items {
+				if strings.HasPrefix(item.Path, treeImagesDir+"/"+fingerprint+"/") {
+					t.setAliases(name, aliases)
+					return nil
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("Image %q isn't in the tree", fingerprint)
+}
+
+// Save writes the products and the index of the tree.
+func (t *Tree) Save() error {
+	updated := time.Now().UTC().Format(time.RFC1123Z)
+	t.products.Updated = updated
+
+	err := t.writeJSON(t.productsPath, t.products)
+	if err != nil {
+		return err
+	}
+
+	t.stream.Updated = updated
+	t.stream.Index[treeContentID] = StreamIndex{
+		DataType: t.products.DataType,
+		Path:     t.productsPath,
+		Updated:  updated,
+		Products: slices.Sorted(maps.Keys(t.products.Products)),
+		Format:   t.products.Format,
+	}
+
+	return t.writeJSON(treeIndexPath, t.stream)
+}
+
+// moveFile moves a file into the tree, making it readable by the web server serving the tree.
+func (t *Tree) moveFile(source string, name string) error {
+	err := os.Chmod(source, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(source, filepath.Join(t.path, name))
+}
+
+// writeJSON atomically writes a JSON file into the tree.
+func (t *Tree) writeJSON(name string, data any) error {
+	content, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	target := filepath.Join(t.path, name)
+
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(name))
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	_, err = f.Write(append(content, '\n'))
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Chmod(0644)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), target)
+}
+
+// product returns the name of the product the image belongs to, and the product itself.
+func (t *Tree) product(image api.Image, architecture string) (string, Product) {
+	release := image.Properties["release"]
+	if release == "" && image.Properties["os"] == "" {
+		// Fallback to something identifying the image for images built outside of a distribution.
+		release = image.Fingerprint[:12]
+		if len(image.Aliases) > 0 {
+			release = image.Aliases[0].Name
+		}
+	}
+
+	fields := []string{}
+	for _, field := range []string{image.Properties["os"], release, architecture, image.Properties["variant"]} {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	name := strings.Join(fields, ":")
+
+	product, ok := t.products.Products[name]
+	if ok {
+		if product.Versions == nil {
+			product.Versions = map[string]ProductVersion{}
+		}
+
+		return name, product
+	}
+
+	product = Product{
+		Architecture:    architecture,
+		OperatingSystem: image.Properties["os"],
+		Release:         release,
+		ReleaseTitle:    release,
+		Version:         image.Properties["version"],
+		Variant:         image.Properties["variant"],
+		Versions:        map[string]ProductVersion{},
+	}
+
+	for key, value := range image.Properties {
+		requirement, ok := strings.CutPrefix(key, "requirements.")
+		if !ok {
+			continue
+		}
+
+		if product.Requirements == nil {
+			product.Requirements = map[string]string{}
+		}
+
+		product.Requirements[requirement] = value
+	}
+
+	return name, product
+}
+
+// setAliases adds the aliases to the product and removes them from the other products of the same architecture.
+func (t *Tree) setAliases(productName string, aliases []string) {
+	if len(aliases) == 0 {
+		return
+	}
+
+	architecture := t.products.Products[productName].Architecture
+
+	for name, product := range t.products.Products {
+		current := []string{}
+		if product.Aliases != "" {
+			current = strings.Split(product.Aliases, ",")
+		}
+
+		if name == productName {
+			for _, alias := range aliases {
+				if !slices.Contains(current, alias) {
+					current = append(current, alias)
+				}
+			}
+		} else if product.Architecture == architecture {
+			current = slices.DeleteFunc(current, func(alias string) bool { return slices.Contains(aliases, alias) })
+		}
+
+		slices.Sort(current)
+		product.Aliases = strings.Join(current, ",")
+		t.products.Products[name] = product
+	}
+}
+
+// treeItemName returns the name of the item of a product version for the given image type and item type.
+func treeItemName(imageType string, fileType string) string {
+	if imageType == string(api.InstanceTypeVM) && fileType == "lxd.tar.xz" {
+		return "lxd-vm.tar.xz"
+	}
+
+	return fileType
+}
+
+// treeFileItem returns the item describing the given file, feeding its content to the combined hash.
+func treeFileItem(name string, combined io.Writer) (ProductVersionItem, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return ProductVersionItem{}, err
+	}
+
+	defer func() { _ = f.Close() }()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(io.MultiWriter(hash, combined), f)
+	if err != nil {
+		return ProductVersionItem{}, err
+	}
+
+	return ProductVersionItem{
+		HashSha256: hex.EncodeToString(hash.Sum(nil)),
+		Size:       size,
+	}, nil
+}
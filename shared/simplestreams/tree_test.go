@@ -0,0 +1,195 @@
+package simplestreams
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// writeTestFile writes a file in the directory and returns its path.
+func writeTestFile(t *testing.T, dir string, name string, content []byte) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+
+	err := os.WriteFile(path, content, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// gzipContent returns the gzip compressed content.
+func gzipContent(t *testing.T, content string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	w := gzip.NewWriter(&buf)
+
+	_, err := w.Write([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+// hashContent returns the hexadecimal SHA-256 hash of the concatenated content.
+func hashContent(content ...[]byte) string {
+	hash := sha256.New()
+	for _, c := range content {
+		hash.Write(c)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func TestTree(t *testing.T) {
+	dir := t.TempDir()
+	created := time.Date(2026, 3, 4, 5, 6, 0, 0, time.UTC)
+
+	tree, err := OpenTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Unified container image.
+	unified := gzipContent(t, "unified")
+	unifiedImage := api.Image{
+		Architecture: "x86_64",
+		Fingerprint:  hashContent(unified),
+		Type:         string(api.InstanceTypeContainer),
+		CreatedAt:    created,
+		Properties:   map[string]string{"os": "Alpine", "release": "3.21", "architecture": "amd64"},
+	}
+
+	err = tree.AddImage(unifiedImage, []string{"alpine/3.21"}, writeTestFile(t, dir, "unified", unified), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Split container image.
+	meta := gzipContent(t, "meta")
+	rootfs := append([]byte("hsqs"), make([]byte, 512)...)
+	splitImage := api.Image{
+		Architecture: "x86_64",
+		Fingerprint:  hashContent(meta, rootfs),
+		Type:         string(api.InstanceTypeContainer),
+		CreatedAt:    created.Add(24 * time.Hour),
+		Properties:   map[string]string{"os": "Alpine", "release": "3.21", "architecture": "amd64"},
+	}
+
+	// Files not matching the fingerprint are rejected.
+	badImage := splitImage
+	badImage.Fingerprint = hashContent(meta)
+
+	err = tree.AddImage(badImage, nil, writeTestFile(t, dir, "meta", meta), writeTestFile(t, dir, "rootfs", rootfs))
+	if err == nil {
+		t.Fatal("Expected an error for files not matching the fingerprint")
+	}
+
+	// Downloaded files are usually only readable by their owner.
+	err = os.Chmod(filepath.Join(dir, "rootfs"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.AddImage(splitImage, []string{"alpine/3.21"}, filepath.Join(dir, "meta"), filepath.Join(dir, "rootfs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the tree and check the images are known.
+	tree, err = OpenTree(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !tree.HasImage(unifiedImage.Fingerprint) || !tree.HasImage(splitImage.Fingerprint) {
+		t.Fatal("Expected the images to be in the tree")
+	}
+
+	err = tree.AddAliases(unifiedImage.Fingerprint, []string{"alpine/edge"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tree.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the tree back through the simplestreams client.
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+
+	client := NewClient(server.URL, *server.Client(), "")
+
+	images, err := client.ListImages()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(images) != 2 {
+		t.Fatalf("Expected 2 images, got %d", len(images))
+	}
+
+	// The newest image gets the alias.
+	alias, err := client.GetAlias(string(api.InstanceTypeContainer), "alpine/3.21/amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if alias.Target != splitImage.Fingerprint {
+		t.Errorf("Expected alias to target %q, got %q", splitImage.Fingerprint, alias.Target)
+	}
+
+	files, err := client.GetFiles(splitImage.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if files["meta"].Sha256 != hashContent(meta) || files["root"].Sha256 != hashContent(rootfs) {
+		t.Errorf("Unexpected files %v", files)
+	}
+
+	// Files moved into the tree are readable by the web server.
+	info, err := os.Stat(filepath.Join(dir, files["root"].Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("Expected mode 0644, got %o", info.Mode().Perm())
+	}
+
+	image, err := client.GetImage(unifiedImage.Fingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !image.CreatedAt.Equal(created) {
+		t.Errorf("Expected creation date %v, got %v", created, image.CreatedAt)
+	}
+}
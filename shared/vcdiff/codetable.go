@@ -0,0 +1,141 @@
+package vcdiff
+
+import (
+	"errors"
+	"io"
+)
+
+// Instruction types.
+const (
+	instNoop = iota
+	instAdd
+	instRun
+	instCopy
+)
+
+// Sizes of the address caches of the default code table.
+const (
+	nearCacheSize = 4
+	sameCacheSize = 3
+)
+
+// instruction is one of the two instructions of a code table entry. A size of 0 means the size is read from
+// the instructions section.
+type instruction struct {
+	kind byte
+	size byte
+	mode byte
+}
+
+// defaultCodeTable is the code table defined by section 5.6 of RFC 3284.
+var defaultCodeTable = buildDefaultCodeTable()
+
+func buildDefaultCodeTable() [256][2]instruction {
+	var table [256][2]instruction
+
+	i := 0
+	add := func(first instruction, second instruction) {
+		table[i] = [2]instruction{first, second}
+		i++
+	}
+
+	// RUN with its size in the instructions section.
+	add(instruction{kind: instRun}, instruction{})
+
+	// ADD of size 0 (read from the instructions section) to 17.
+	for size := range 18 {
+		add(instruction{kind: instAdd, size: byte(size)}, instruction{})
+	}
+
+	// COPY of size 0 (read from the instructions section) and 4 to 18, for each mode.
+	for mode := range 2 + nearCacheSize + sameCacheSize {
+		add(instruction{kind: instCopy, mode: byte(mode)}, instruction{})
+
+		for size := 4; size <= 18; size++ {
+			add(instruction{kind: instCopy, size: byte(size), mode: byte(mode)}, instruction{})
+		}
+	}
+
+	// ADD of size 1 to 4 followed by a COPY of size 4 to 6 for the self, here and near modes.
+	for mode := range 2 + nearCacheSize {
+		for addSize := 1; addSize <= 4; addSize++ {
+			for copySize := 4; copySize <= 6; copySize++ {
+				add(instruction{kind: instAdd, size: byte(addSize)}, instruction{kind: instCopy, size: byte(copySize), mode: byte(mode)})
+			}
+		}
+	}
+
+	// ADD of size 1 to 4 followed by a COPY of size 4 for the same modes.
+	for mode := 2 + nearCacheSize; mode < 2+nearCacheSize+sameCacheSize; mode++ {
+		for addSize := 1; addSize <= 4; addSize++ {
+			add(instruction{kind: instAdd, size: byte(addSize)}, instruction{kind: instCopy, size: 4, mode: byte(mode)})
+		}
+	}
+
+	// COPY of size 4 followed by an ADD of size 1, for each mode.
+	for mode := range 2 + nearCacheSize + sameCacheSize {
+		add(instruction{kind: instCopy, size: 4, mode: byte(mode)}, instruction{kind: instAdd, size: 1})
+	}
+
+	return table
+}
+
+// Address modes.
+const (
+	modeSelf = 0
+	modeHere = 1
+)
+
+// addressCache holds the near and same caches used to encode the addresses of COPY instructions.
+type addressCache struct {
+	near     [nearCacheSize]int64
+	nextNear int
+	same     [sameCacheSize * 256]int64
+}
+
+// decode reads an address encoded with the given mode, and updates the caches.
+func (c *addressCache) decode(r io.ByteReader, here int64, mode byte) (int64, error) {
+	var addr int64
+
+	switch {
+	case mode == modeSelf:
+		value, err := readInt(r)
+		if err != nil {
+			return 0, err
+		}
+
+		addr = value
+	case mode == modeHere:
+		value, err := readInt(r)
+		if err != nil {
+			return 0, err
+		}
+
+		addr = here - value
+	case mode < 2+nearCacheSize:
+		value, err := readInt(r)
+		if err != nil {
+			return 0, err
+		}
+
+		addr = c.near[mode-2] + value
+	case mode < 2+nearCacheSize+sameCacheSize:
+		value, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		addr = c.same[int(mode-2-nearCacheSize)*256+int(value)]
+	default:
+		return 0, errors.New("Invalid VCDIFF address mode")
+	}
+
+	c.near[c.nextNear] = addr
+	c.nextNear = (c.nextNear + 1) % nearCacheSize
+
+	if addr >= 0 {
+		c.same[addr%(sameCacheSize*256)] = addr
+	}
+
+	return addr, nil
+}
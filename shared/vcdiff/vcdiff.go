@@ -0,0 +1,373 @@
+// Package vcdiff implements a decoder for the VCDIFF delta format (RFC 3284), as produced by xdelta3.
+package vcdiff
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/adler32"
+	"io"
+)
+
+// ErrUnsupported is returned for deltas using features of the format that aren't supported by the decoder,
+// such as secondary compression or application defined code tables.
+var ErrUnsupported = errors.New("Unsupported VCDIFF feature")
+
+// magic is the header of VCDIFF files.
+var magic = []byte{0xd6, 0xc3, 0xc4, 0x00}
+
+// Header indicator bits.
+const (
+	hdrDecompress = 1 << iota
+	hdrCodeTable
+	hdrAppHeader
+)
+
+// Window indicator bits, including the checksum extension of xdelta3.
+const (
+	winSource = 1 << iota
+	winTarget
+	winAdler32
+)
+
+// maxWindowSize is the largest target window accepted, xdelta3 windows being at most 16MiB.
+const maxWindowSize = 64 * 1024 * 1024
+
+// Target is the output of the decoder. Data previously written to it is read back by windows copying from it.
+type Target interface {
+	io.Writer
+	io.ReaderAt
+}
+
+// Decode applies the delta to the source and writes the result to the target.
+func Decode(source io.ReaderAt, delta io.Reader, target Target) error {
+	r := bufio.NewReader(delta)
+
+	header := make([]byte, len(magic))
+
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return fmt.Errorf("Failed reading VCDIFF header: %w", err)
+	}
+
+	if !bytes.Equal(header, magic) {
+		return errors.New("Invalid VCDIFF header")
+	}
+
+	indicator, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if indicator&hdrCodeTable != 0 {
+		return fmt.Errorf("%w: application defined code table", ErrUnsupported)
+	}
+
+	if indicator&hdrDecompress != 0 {
+		// The compressor is only used if a window indicates so.
+		_, err = r.ReadByte()
+		if err != nil {
+			return err
+		}
+	}
+
+	if indicator&hdrAppHeader != 0 {
+		length, err := readInt(r)
+		if err != nil {
+			return err
+		}
+
+		_, err = r.Discard(int(length))
+		if err != nil {
+			return err
+		}
+	}
+
+	d := decoder{
+		source: source,
+		target: target,
+	}
+
+	for {
+		_, err := r.Peek(1)
+		if err == io.EOF {
+			return nil
+		}
+
+		err = d.window(r)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// decoder holds the state of the decoding of a delta.
+type decoder struct {
+	source io.ReaderAt
+	target Target
+
+	// written is the amount of data written to the target.
+	written int64
+}
+
+// window decodes a single window of the delta.
+func (d *decoder) window(r *bufio.Reader) error {
+	indicator, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	var segment io.ReaderAt
+	var segmentSize, segmentPosition int64
+
+	if indicator&(winSource|winTarget) != 0 {
+		segmentSize, err = readInt(r)
+		if err != nil {
+			return err
+		}
+
+		segmentPosition, err = readInt(r)
+		if err != nil {
+			return err
+		}
+
+		segment = d.source
+		if indicator&winTarget != 0 {
+			if segmentPosition+segmentSize > d.written {
+				return errors.New("Invalid VCDIFF window: target segment out of range")
+			}
+
+			segment = d.target
+		}
+	}
+
+	// Length of the delta encoding, the following fields being self delimiting.
+	_, err = readInt(r)
+	if err != nil {
+		return err
+	}
+
+	targetSize, err := readInt(r)
+	if err != nil {
+		return err
+	}
+
+	if targetSize > maxWindowSize {
+		return fmt.Errorf("VCDIFF window of %d bytes exceeds the maximum size", targetSize)
+	}
+
+	deltaIndicator, err := r.ReadByte()
+	if err != nil {
+		return err
+	}
+
+	if deltaIndicator != 0 {
+		return fmt.Errorf("%w: secondary compression", ErrUnsupported)
+	}
+
+	sizes := make([]int64, 3)
+	for i := range sizes {
+		sizes[i], err = readInt(r)
+		if err != nil {
+			return err
+		}
+
+		if sizes[i] > maxWindowSize {
+			return fmt.Errorf("VCDIFF window section of %d bytes exceeds the maximum size", sizes[i])
+		}
+	}
+
+	var checksum []byte
+	if indicator&winAdler32 != 0 {
+		checksum = make([]byte, 4)
+
+		_, err = io.ReadFull(r, checksum)
+		if err != nil {
+			return err
+		}
+	}
+
+	sections := make([][]byte, 3)
+	for i := range sections {
+		sections[i] = make([]byte, sizes[i])
+
+		_, err = io.ReadFull(r, sections[i])
+		if err != nil {
+			return fmt.Errorf("Failed reading VCDIFF window: %w", err)
+		}
+	}
+
+	w := window{
+		segment:         segment,
+		segmentSize:     segmentSize,
+		segmentPosition: segmentPosition,
+		data:            bytes.NewReader(sections[0]),
+		instructions:    bytes.NewReader(sections[1]),
+		addresses:       bytes.NewReader(sections[2]),
+		output:          make([]byte, 0, targetSize),
+	}
+
+	err = w.decode()
+	if err != nil {
+		return err
+	}
+
+	if int64(len(w.output)) != targetSize {
+		return fmt.Errorf("Invalid VCDIFF window: decoded %d bytes instead of %d", len(w.output), targetSize)
+	}
+
+	if checksum != nil && adler32.Checksum(w.output) != uint32(checksum[0])<<24|uint32(checksum[1])<<16|uint32(checksum[2])<<8|uint32(checksum[3]) {
+		return errors.New("VCDIFF window checksum mismatch")
+	}
+
+	_, err = d.target.Write(w.output)
+	if err != nil {
+		return err
+	}
+
+	d.written += targetSize
+
+	return nil
+}
+
+// window holds the state of the decoding of a window.
+type window struct {
+	segment         io.ReaderAt
+	segmentSize     int64
+	segmentPosition int64
+
+	data         *bytes.Reader
+	instructions *bytes.Reader
+	addresses    *bytes.Reader
+
+	cache  addressCache
+	output []byte
+}
+
+// decode runs the instructions of the window.
+func (w *window) decode() error {
+	for w.instructions.Len() > 0 {
+		code, err := w.instructions.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		for _, inst := range defaultCodeTable[code] {
+			if inst.kind == instNoop {
+				continue
+			}
+
+			size := int64(inst.size)
+			if size == 0 {
+				size, err = readInt(w.instructions)
+				if err != nil {
+					return err
+				}
+			}
+
+			if int64(len(w.output))+size > int64(cap(w.output)) {
+				return errors.New("Invalid VCDIFF window: instructions exceed the window size")
+			}
+
+			switch inst.kind {
+			case instAdd:
+				err = w.add(size)
+			case instRun:
+				err = w.run(size)
+			case instCopy:
+				err = w.copy(size, inst.mode)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// add appends data from the data section to the output.
+func (w *window) add(size int64) error {
+	if size > int64(w.data.Len()) {
+		return errors.New("Invalid VCDIFF window: data section too short")
+	}
+
+	start := len(w.output)
+	w.output = w.output[:start+int(size)]
+
+	_, err := io.ReadFull(w.data, w.output[start:])
+
+	return err
+}
+
+// run appends a repeated byte from the data section to the output.
+func (w *window) run(size int64) error {
+	b, err := w.data.ReadByte()
+	if err != nil {
+		return errors.New("Invalid VCDIFF window: data section too short")
+	}
+
+	for range size {
+		w.output = append(w.output, b)
+	}
+
+	return nil
+}
+
+// copy appends data from the source segment or from the output to the output.
+func (w *window) copy(size int64, mode byte) error {
+	here := w.segmentSize + int64(len(w.output))
+
+	addr, err := w.cache.decode(w.addresses, here, mode)
+	if err != nil {
+		return err
+	}
+
+	if addr < 0 || addr >= here {
+		return errors.New("Invalid VCDIFF window: copy address out of range")
+	}
+
+	// Copy from the source segment first.
+	if addr < w.segmentSize {
+		n := min(size, w.segmentSize-addr)
+		start := len(w.output)
+		w.output = w.output[:start+int(n)]
+
+		_, err = w.segment.ReadAt(w.output[start:], w.segmentPosition+addr)
+		if err != nil {
+			return fmt.Errorf("Failed reading VCDIFF source: %w", err)
+		}
+
+		size -= n
+		addr += n
+	}
+
+	// Then from the output, byte by byte as the copy may overlap with the data it produces.
+	for i := addr - w.segmentSize; size > 0; i++ {
+		w.output = append(w.output, w.output[i])
+		size--
+	}
+
+	return nil
+}
+
+// readInt reads a variable length integer.
+func readInt(r io.ByteReader) (int64, error) {
+	var value int64
+
+	for range 9 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		value = value<<7 | int64(b&0x7f)
+		if b&0x80 == 0 {
+			return value, nil
+		}
+	}
+
+	return 0, errors.New("Invalid VCDIFF integer")
+}
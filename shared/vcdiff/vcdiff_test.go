@@ -0,0 +1,241 @@
+package vcdiff
+
+import (
+	"bytes"
+	"errors"
+	"hash/adler32"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// appendInt appends a variable length integer.
+func appendInt(b []byte, value int64) []byte {
+	encoded := []byte{byte(value & 0x7f)}
+	for value >>= 7; value > 0; value >>= 7 {
+		encoded = append([]byte{byte(value&0x7f) | 0x80}, encoded...)
+	}
+
+	return append(b, encoded...)
+}
+
+// testWindow describes a window to encode.
+type testWindow struct {
+	indicator       byte
+	segmentSize     int64
+	segmentPosition int64
+	deltaIndicator  byte
+	targetSize      int64
+	checksum        []byte
+
+	data         []byte
+	instructions []byte
+	addresses    []byte
+}
+
+// add encodes an ADD instruction.
+func (w *testWindow) add(data string) {
+	w.instructions = appendInt(append(w.instructions, 1), int64(len(data)))
+	w.data = append(w.data, data...)
+	w.targetSize += int64(len(data))
+}
+
+// run encodes a RUN instruction.
+func (w *testWindow) run(b byte, size int64) {
+	w.instructions = appendInt(append(w.instructions, 0), size)
+	w.data = append(w.data, b)
+	w.targetSize += size
+}
+
+// copy encodes a COPY instruction with the given mode and encoded address.
+func (w *testWindow) copy(mode byte, size int64, addr int64) {
+	w.instructions = appendInt(append(w.instructions, 19+mode*16), size)
+	if mode >= 2+nearCacheSize {
+		w.addresses = append(w.addresses, byte(addr))
+	} else {
+		w.addresses = appendInt(w.addresses, addr)
+	}
+
+	w.targetSize += size
+}
+
+// encode returns the encoded window.
+func (w *testWindow) encode() []byte {
+	b := []byte{w.indicator}
+	if w.indicator&(winSource|winTarget) != 0 {
+		b = appendInt(b, w.segmentSize)
+		b = appendInt(b, w.segmentPosition)
+	}
+
+	encoding := appendInt(nil, w.targetSize)
+	encoding = append(encoding, w.deltaIndicator)
+	encoding = appendInt(encoding, int64(len(w.data)))
+	encoding = appendInt(encoding, int64(len(w.instructions)))
+	encoding = appendInt(encoding, int64(len(w.addresses)))
+	encoding = append(encoding, w.checksum...)
+	encoding = append(encoding, w.data...)
+	encoding = append(encoding, w.instructions...)
+	encoding = append(encoding, w.addresses...)
+
+	b = appendInt(b, int64(len(encoding)))
+
+	return append(b, encoding...)
+}
+
+// encodeDelta returns a delta made of the given header and windows.
+func encodeDelta(header []byte, windows ...*testWindow) []byte {
+	b := append(append([]byte{}, magic...), header...)
+	for _, w := range windows {
+		b = append(b, w.encode()...)
+	}
+
+	return b
+}
+
+// decode applies the delta to the source, using a file as the target.
+func decode(t *testing.T, source string, delta []byte) (string, error) {
+	t.Helper()
+
+	target, err := os.Create(filepath.Join(t.TempDir(), "target"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() { _ = target.Close() }()
+
+	err = Decode(bytes.NewReader([]byte(source)), bytes.NewReader(delta), target)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(target.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(content), nil
+}
+
+func TestDecode(t *testing.T) {
+	source := "The quick brown fox jumps over the lazy dog"
+
+	t.Run("Copy modes", func(t *testing.T) {
+		w := &testWindow{indicator: winSource, segmentSize: int64(len(source))}
+		w.copy(modeSelf, 16, 0)        // "The quick brown ", near[0]=0
+		w.add("cat")                   // "cat"
+		w.copy(modeHere, 9, 62-19)     // " jumps ov" from source offset 19, here = 43+19 = 62, near[1]=19
+		w.run('-', 3)                  // "---"
+		w.copy(2, 3, 4)                // "qui" from near[0]+4, near[2]=4
+		w.copy(3, 4, 1)                // "jump" from near[1]+1, near[3]=20
+		w.copy(2+nearCacheSize, 5, 20) // "jumps" from same[20]
+
+		output, err := decode(t, source, encodeDelta([]byte{0}, w))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output != "The quick brown cat jumps ov---quijumpjumps" {
+			t.Errorf("Unexpected output %q", output)
+		}
+	})
+
+	t.Run("Overlapping copy", func(t *testing.T) {
+		w := &testWindow{}
+		w.add("ab")
+		w.copy(modeSelf, 6, 0)
+
+		output, err := decode(t, source, encodeDelta([]byte{0}, w))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output != "abababab" {
+			t.Errorf("Unexpected output %q", output)
+		}
+	})
+
+	t.Run("Paired instructions", func(t *testing.T) {
+		// ADD of size 1 followed by a COPY of size 4 in self mode, then the opposite.
+		w := &testWindow{indicator: winSource, segmentSize: int64(len(source)), targetSize: 10}
+		w.instructions = []byte{163, 247}
+		w.data = []byte("[]")
+		w.addresses = []byte{0, 4}
+
+		output, err := decode(t, source, encodeDelta([]byte{0}, w))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output != "[The quic]" {
+			t.Errorf("Unexpected output %q", output)
+		}
+	})
+
+	t.Run("Target segment and checksum", func(t *testing.T) {
+		w1 := &testWindow{indicator: winSource, segmentSize: 9, segmentPosition: 4}
+		w1.copy(modeSelf, 9, 0) // "quick bro"
+
+		w2 := &testWindow{indicator: winTarget | winAdler32, segmentSize: 5, segmentPosition: 0}
+		w2.add("[")
+		w2.copy(modeSelf, 5, 0) // "quick" from the output of the first window
+		w2.add("]")
+		sum := adler32.Checksum([]byte("[quick]"))
+		w2.checksum = []byte{byte(sum >> 24), byte(sum >> 16), byte(sum >> 8), byte(sum)}
+
+		// Application header.
+		header := appendInt([]byte{hdrAppHeader}, 4)
+		header = append(header, "test"...)
+
+		output, err := decode(t, source, encodeDelta(header, w1, w2))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if output != "quick bro[quick]" {
+			t.Errorf("Unexpected output %q", output)
+		}
+
+		w2.checksum[0]++
+
+		_, err = decode(t, source, encodeDelta(header, w1, w2))
+		if err == nil {
+			t.Error("Expected a checksum error")
+		}
+	})
+
+	t.Run("Invalid deltas", func(t *testing.T) {
+		// Secondary compression.
+		w := &testWindow{deltaIndicator: 1}
+		w.add("data")
+
+		_, err := decode(t, source, encodeDelta([]byte{hdrDecompress, 1}, w))
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Expected ErrUnsupported, got %v", err)
+		}
+
+		// Copy beyond the output.
+		w = &testWindow{}
+		w.add("ab")
+		w.copy(modeSelf, 2, 2)
+
+		_, err = decode(t, source, encodeDelta([]byte{0}, w))
+		if err == nil {
+			t.Error("Expected an error for an address out of range")
+		}
+
+		// Instructions exceeding the window.
+		w = &testWindow{}
+		w.add("ab")
+		w.targetSize = 1
+
+		_, err = decode(t, source, encodeDelta([]byte{0}, w))
+		if err == nil {
+			t.Error("Expected an error for instructions exceeding the window")
+		}
+
+		_, err = decode(t, source, []byte("not a delta"))
+		if err == nil {
+			t.Error("Expected an error for an invalid header")
+		}
+	})
+}
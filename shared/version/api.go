@@ -500,6 +500,29 @@ var APIExtensions = []string{
 	"operation_child_count",
 	"storage_driver_powerstore_nvme",
 	"access_management_expiry",
+	"instance_type_krun",
+	"replicator_incremental",
+	"replicator_run_history",
+	"replica_failover",
+	"placement_group_affinity",
+	"cluster_scheduler_weights",
+	"cluster_rebalance",
+	"instances_placement_webhook",
+	"network_acl_state",
+	"network_zones_dns_queries",
+	"storage_buckets_local",
+	"storage_driver_nfs",
+	"storage_driver_san",
+	"network_bridge_tunnel_wireguard",
+	"network_evpn",
+	"network_qos",
+	"network_flow_logging",
+	"network_acl_sets",
+	"collection_pagination",
+	"image_oci_remote",
+	"backup_targets",
+	"backup_scheduling",
+	"images_export_streams",
 }
 
 // APIExtensionsCount returns the number of available API extensions.
@@ -105,6 +105,7 @@ readonly test_group_image=(
     "image_architectures"
     "image_auto_update"
     "image_expiry"
+    "image_export_streams"
     "image_import_dir"
     "image_import_url"
     "image_import_existing_alias"
@@ -141,6 +142,7 @@ readonly test_group_network=(
     "dns"
     "network"
     "network_acl"
+    "network_acl_sets"
     "network_forward"
     "network_zone"
     "network_ovn"
@@ -167,6 +169,7 @@ readonly test_group_standalone=(
     "duplicate_detection"
     "fdleak"
     "filtering"
+    "pagination"
     "bulk_operation_children"
     "get_operations"
     "operations_conflict_reference"
@@ -237,6 +240,8 @@ readonly test_group_standalone_storage=(
     "backup_volume_expiry"
     "backup_export_import_recover"
     "backup_inconsistent_config"
+    "backup_target"
+    "backup_schedule"
     "container_copy_incremental"
     "container_copy_start"
     "container_devices_disk"
@@ -288,6 +293,7 @@ readonly test_group_standalone_storage=(
     "storage_driver_ceph"
     "storage_driver_cephfs"
     "storage_driver_dir"
+    "storage_driver_nfs"
     "storage_driver_zfs"
     "storage_driver_pure"
     "storage_pools"
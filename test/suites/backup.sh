@@ -1157,6 +1157,124 @@ test_backup_export_import_instance_only() {
   lxc delete c1
 }
 
+test_backup_target() {
+  local lxd_backend
+  lxd_backend=$(storage_backend "$LXD_DIR")
+
+  if ! [[ "${lxd_backend}" =~ ^(btrfs|dir|lvm|zfs)$ ]]; then
+    export TEST_UNMET_REQUIREMENT="Storage driver does not support local storage buckets"
+    return
+  fi
+
+  local poolName
+  poolName="lxdtest-$(basename "${LXD_DIR}")"
+
+  # Use a local storage bucket as the object storage of the backup target.
+  lxc config set core.storage_buckets_address "127.0.0.1:$(local_tcp_port)"
+  creds="$(lxc storage bucket create "${poolName}" backups)"
+  accessKey="$(echo "${creds}" | awk '{ if ($2 == "access" && $3 == "key:") {print $4}}')"
+  secretKey="$(echo "${creds}" | awk '{ if ($2 == "secret" && $3 == "key:") {print $4}}')"
+  endpoint="https://$(lxc config get core.storage_buckets_address)"
+  lxc query /1.0 | jq -r '.environment.certificate' > "${TEST_DIR}/s3.crt"
+
+  # Check backup target validation.
+  ! lxc backup-target create s3 endpoint="${endpoint}" || false
+  ! lxc backup-target create s3 endpoint="${endpoint}" bucket=backups access_key="${accessKey}" secret_key="${secretKey}" prefix=/lxd || false
+  ! lxc backup-target create s3 endpoint="${endpoint}" bucket=backups access_key="${accessKey}" secret_key="${secretKey}" foo=bar || false
+
+  lxc backup-target create s3 endpoint="${endpoint}" bucket=backups access_key="${accessKey}" secret_key="${secretKey}" prefix=lxd
+  lxc backup-target set s3 certificate="$(cat "${TEST_DIR}/s3.crt")"
+  [ "$(lxc backup-target get s3 prefix)" = "lxd" ]
+  lxc backup-target list --format csv | grep -xF "s3,,${endpoint},backups,lxd"
+  ! lxc backup-target create s3 endpoint="${endpoint}" bucket=backups access_key="${accessKey}" secret_key="${secretKey}" || false
+
+  # Back up an instance with a snapshot to the backup target.
+  lxc init --empty c1 -d "${SMALL_ROOT_DISK}"
+  lxc config set c1 user.foo=bar
+  lxc snapshot c1
+  lxc export c1 --backup-target s3
+  lxc export c1 --backup-target s3 --instance-only
+  ! lxc export c1 "${LXD_DIR}/c1.tar.gz" --backup-target s3 || false
+
+  # Backups are not stored on the server and are listed from the backup target.
+  [ "$(lxc query /1.0/instances/c1/backups | jq 'length')" = "0" ]
+  [ "$(lxc backup-target list-backups s3 --format csv --columns n)" = "default/c1/backup0
+default/c1/backup1" ]
+  [ "$(lxc query /1.0/backup-targets/s3/backups/default/c1/backup0 | jq -r '.name')" = "default/c1/backup0" ]
+
+  # Backups are never overwritten and cannot expire.
+  ! lxc query --request POST /1.0/instances/c1/backups --data '{"name": "backup0", "backup_target": "s3"}' || false
+  ! lxc query --request POST /1.0/instances/c1/backups --data '{"name": "backup2", "backup_target": "s3", "expires_at": "2100-01-01T00:00:00Z"}' || false
+
+  # Custom storage volume backups cannot be stored on a backup target.
+  lxc storage volume create "${poolName}" vol1 size=1MiB
+  ! lxc query --request POST "/1.0/storage-pools/${poolName}/volumes/custom/vol1/backups" --data '{"name": "backup0", "backup_target": "s3"}' || false
+  [ "$(lxc query "/1.0/storage-pools/${poolName}/volumes/custom/vol1/backups" | jq 'length')" = "0" ]
+  lxc storage volume delete "${poolName}" vol1
+
+  # Restore the instance from the backup target.
+  lxc import default/c1/backup0 c2 --backup-target s3
+  [ "$(lxc config get c2 user.foo)" = "bar" ]
+  lxc query "/1.0/storage-pools/${poolName}/volumes/container/c2/snapshots" | jq --exit-status 'length == 1'
+  lxc import default/c1/backup1 c3 --backup-target s3 --storage "${poolName}"
+  lxc query "/1.0/storage-pools/${poolName}/volumes/container/c3/snapshots" | jq --exit-status '. == []'
+  ! lxc import default/c1/missing c4 --backup-target s3 || false
+  lxc delete c1 c2 c3
+
+  # Delete the backups and the backup target.
+  lxc backup-target delete-backup s3 default/c1/backup1
+  ! lxc backup-target delete-backup s3 default/c1/backup1 || false
+  [ "$(lxc backup-target list-backups s3 default/c1/ --format csv --columns n)" = "default/c1/backup0" ]
+  lxc backup-target delete s3
+  ! lxc backup-target show s3 || false
+  lxc storage bucket delete "${poolName}" backups
+  lxc config unset core.storage_buckets_address
+  rm "${TEST_DIR}/s3.crt"
+}
+
+test_backup_schedule() {
+  local poolName
+  poolName="lxdtest-$(basename "${LXD_DIR}")"
+
+  lxc init --empty c1 -d "${SMALL_ROOT_DISK}"
+
+  # Check the configuration validation.
+  ! lxc config set c1 backups.schedule=invalid || false
+  ! lxc config set c1 backups.expiry=invalid || false
+  ! lxc config set c1 backups.retention.last=-1 || false
+
+  # Backups created manually are never deleted by the retention policy.
+  lxc query -X POST --wait -d '{"name":"manual"}' /1.0/instances/c1/backups
+
+  # Set schedule to be every minute. The daemon will create a backup every time the task is run.
+  lxc config set c1 backups.schedule='* * * * *' backups.pattern='sched%d' backups.retention.last=2
+  lxc query -X POST /internal/testing/backup-scheduled-task
+  lxc query /1.0/instances/c1/backups/sched0
+  lxc query -X POST /internal/testing/backup-scheduled-task
+  lxc query -X POST /internal/testing/backup-scheduled-task
+
+  # Only the two most recent scheduled backups are kept.
+  [ "$(lxc query /1.0/instances/c1/backups | jq '[.[] | select(contains("/backups/sched"))] | length')" = "2" ]
+  ! lxc query /1.0/instances/c1/backups/sched0 || false
+  lxc query /1.0/instances/c1/backups/manual
+
+  # Scheduled backups get the configured expiry.
+  lxc config unset c1 backups.retention.last
+  lxc config set c1 backups.expiry=1d
+  lxc query -X POST /internal/testing/backup-scheduled-task
+  [ "$(lxc query '/1.0/instances/c1/backups?recursion=1' | jq '[.[] | select(.name | startswith("sched")) | select(.expires_at | startswith("0001") | not)] | length')" = "1" ]
+  lxc delete c1
+
+  # Custom volumes.
+  lxc storage volume create "${poolName}" vol1
+  ! lxc storage volume set "${poolName}" vol1 backups.retention.daily=foo || false
+  lxc storage volume set "${poolName}" vol1 backups.schedule='* * * * *' backups.retention.last=1
+  lxc query -X POST /internal/testing/backup-scheduled-task
+  lxc query -X POST /internal/testing/backup-scheduled-task
+  [ "$(lxc query "/1.0/storage-pools/${poolName}/volumes/custom/vol1/backups" | jq 'length')" = "1" ]
+  lxc storage volume delete "${poolName}" vol1
+}
+
 test_backup_metadata() {
   ensure_import_testimage
 
@@ -5323,6 +5323,17 @@ test_clustering_placement_groups() {
   LXD_DIR="${LXD_ONE_DIR}" lxc placement-group list | grep pg-new
   ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group list | grep pg-old || false
 
+  echo "==> Test placement group rule references"
+  # Referenced placement groups must exist
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create pg-rules policy=spread rigor=strict anti_affinity=pg-missing || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group create pg-rules policy=spread rigor=strict anti_affinity=pg-new
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group set pg-rules affinity pg-missing || false
+  # Referenced placement groups can't be deleted or renamed
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete pg-new || false
+  ! LXD_DIR="${LXD_ONE_DIR}" lxc placement-group rename pg-new pg-renamed || false
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group unset pg-rules anti_affinity
+  LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete pg-rules
+
   # Clean up
   LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete pg-new
   LXD_DIR="${LXD_ONE_DIR}" lxc placement-group delete pg-spread-strict
@@ -15,3 +15,37 @@ test_filtering() {
     # Cleanup
     lxc delete c1 c2
 }
+
+# Test API sorting and pagination.
+test_pagination() {
+    echo "===> Instance sorting"
+    lxc init --empty c1
+    lxc init --empty c2
+    lxc init --empty c3
+    curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "recursion=0" --data-urlencode "sort=-name" | jq --exit-status '.metadata == ["/1.0/instances/c3", "/1.0/instances/c2", "/1.0/instances/c1"]'
+    curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "recursion=1" --data-urlencode "sort=-created_at" | jq --exit-status '[.metadata[].name] == ["c3", "c2", "c1"]'
+    [ "$(lxc list --sort=-name --format csv --columns n | tr '\n' ' ')" = "c3 c2 c1 " ]
+
+    echo "===> Instance pagination"
+    page="$(curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "recursion=1" --data-urlencode "limit=2")"
+    echo "${page}" | jq --exit-status '[.metadata[].name] == ["c1", "c2"]'
+    token="$(echo "${page}" | jq --exit-status --raw-output '.continue')"
+    page="$(curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "recursion=1" --data-urlencode "limit=2" --data-urlencode "continue=${token}")"
+    echo "${page}" | jq --exit-status '[.metadata[].name] == ["c3"]'
+    echo "${page}" | jq --exit-status '.continue == null'
+
+    # The continue token is only valid for the same sort order.
+    [ "$(curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "limit=2" --data-urlencode "sort=name" --data-urlencode "continue=${token}" --output /dev/null --write-out "%{http_code}")" = "400" ]
+    [ "$(curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/instances" --data-urlencode "limit=-1" --output /dev/null --write-out "%{http_code}")" = "400" ]
+
+    # The client fetches all pages.
+    [ "$(lxc list --page-size=1 --format csv --columns n | wc -l)" = "3" ]
+    [ "$(lxc list --page-size=2 --sort=-name --format csv --columns n | tr '\n' ' ')" = "c3 c2 c1 " ]
+
+    echo "===> Image pagination"
+    ensure_import_testimage
+    curl --silent --get --unix-socket "$LXD_DIR/unix.socket" "lxd/1.0/images" --data-urlencode "recursion=1" --data-urlencode "limit=1" --data-urlencode "sort=-created_at" | jq --exit-status '.metadata | length == 1'
+
+    # Cleanup
+    lxc delete c1 c2 c3
+}
@@ -228,6 +228,41 @@ test_image_import_dir() {
     rm "${fingerprint}.tar"*
 }
 
+test_image_export_streams() {
+    ensure_import_testimage
+    local fingerprint tree
+    fingerprint="$(lxc image info testimage | awk '/^Fingerprint/ {print $2}')"
+    tree="$(mktemp -d -p "${TEST_DIR}" XXX)"
+
+    sub_test "Verify images are exported to a simplestreams tree"
+    lxc image export-streams "${tree}" testimage --alias test/image
+    jq --exit-status '.index.images.path == "streams/v1/images.json"' "${tree}/streams/v1/index.json"
+    jq --exit-status --arg fp "${fingerprint}" '[.products[].versions[].items[] | select(.sha256 == $fp)] | length == 1' "${tree}/streams/v1/images.json"
+    jq --exit-status '[.products[].aliases] == ["test/image"]' "${tree}/streams/v1/images.json"
+
+    sub_test "Verify images already in the tree are not exported again"
+    local image_file
+    image_file="$(find "${tree}/images/${fingerprint}" -type f)"
+    touch -d "2000-01-01" "${image_file}"
+    lxc image export-streams "${tree}" testimage --alias test/other
+    [ "$(stat -c %Y "${image_file}")" = "$(date -d "2000-01-01" +%s)" ]
+    jq --exit-status '[.products[].aliases] == ["test/image,test/other"]' "${tree}/streams/v1/images.json"
+
+    sub_test "Verify aliases are rejected for multiple images"
+    ! lxc image export-streams "${tree}" testimage testimage --alias test/image || false
+
+    sub_test "Verify images are exported to a simplestreams tree by the server"
+    local server_tree
+    server_tree="$(mktemp -d -p "${TEST_DIR}" XXX)"
+    lxc image export-streams "${server_tree}" testimage --alias test/server --server
+    jq --exit-status --arg fp "${fingerprint}" '[.products[].versions[].items[] | select(.sha256 == $fp)] | length == 1' "${server_tree}/streams/v1/images.json"
+    jq --exit-status '[.products[].aliases] == ["test/server"]' "${server_tree}/streams/v1/images.json"
+    [ "$(stat -c %a "$(find "${server_tree}/images/${fingerprint}" -type f)")" = "644" ]
+    ! lxc query --request POST /1.0/images/streams --data '{"path": "relative", "images": [{"fingerprint": "'"${fingerprint}"'"}]}' || false
+
+    rm -rf "${tree}" "${server_tree}"
+}
+
 test_image_import_url() {
     sub_test "Verify image import from URL is rejected by the client"
 
@@ -172,6 +172,17 @@ test_metrics() {
   wait $!
   [ "$(curl -k -s -X GET "https://${metrics_addr}/1.0/metrics" | awk '/^lxd_api_requests_ongoing{entity_type="instance"}/ {print $2}')" -eq "$previous" ]
 
+  echo "==> Test live resource usage view"
+  ! lxc top --sort=foo --count=1 || false
+  ! lxc top --interval=100ms --count=1 || false
+  lxc top --format=json --count=1 --interval=9s > "${TEST_DIR}/top.json"
+  [ "$(wc -l < "${TEST_DIR}/top.json")" = 1 ]
+  jq --exit-status '.instances | map(.name) | index("c1") != null' "${TEST_DIR}/top.json"
+  jq --exit-status '.instances | map(.name) | index("c3") == null' "${TEST_DIR}/top.json"
+  lxc top --format=json --count=1 --interval=9s --all-projects | jq --exit-status '.instances | map(.name) | index("c3") != null'
+  lxc top --count=1 --interval=9s --sort=memory | grep -wF c1
+  rm "${TEST_DIR}/top.json"
+
   if [ "${LXD_VM_TESTS}" = "1" ]; then
     lxc delete -f v1
     lxc delete v2
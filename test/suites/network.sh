@@ -89,6 +89,60 @@ test_network() {
   [ "$(lxc network get lxdt$$ -p description)" = "foo" ]
   lxc network delete lxdt$$
 
+  # WireGuard tunnels
+  if command -v wg >/dev/null; then
+    lxc network create lxdwg0 ipv4.address=none ipv6.address=none
+
+    # Invalid remote keys and MTUs exceeding the WireGuard overhead are rejected.
+    ! lxc network set lxdwg0 tunnel.t1.protocol=wireguard tunnel.t1.remote=192.0.2.2 tunnel.t1.remote_key=invalid || false
+    ! lxc network set lxdwg0 tunnel.t1.protocol=wireguard bridge.mtu=1400 || false
+
+    lxc network set lxdwg0 tunnel.t1.protocol=wireguard tunnel.t1.remote=192.0.2.2 tunnel.t1.remote_key=hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=
+    [ "$(< /sys/class/net/lxdwg0/mtu)" = "1350" ]
+    [ "$(wg show lxdwg0-t1-wg peers)" = "hSDwCYkwp1R0i33ctD73Wg2/Og0mOBr066SpjqqbTmo=" ]
+    [ "$(wg show lxdwg0-t1-wg listen-port)" = "51820" ]
+
+    # The public key of the local end of the tunnel is reported in the network state.
+    lxc network info lxdwg0 | grep -xF "    Public key: $(wg show lxdwg0-t1-wg public-key)"
+
+    lxc network delete lxdwg0
+    ! ip link show lxdwg0-t1-wg || false
+  fi
+
+  # EVPN networks
+  ! lxc network create lxdevpn0 --type=evpn evpn.local=127.0.0.1 || false # evpn.vni is required
+  lxc network create lxdevpn0 --type=evpn evpn.vni=100 evpn.local=127.0.0.1
+  [ "$(< /sys/class/net/lxdevpn0/mtu)" = "1450" ]
+  ip -d link show lxdevpn0-vx | grep -F "master lxdevpn0"
+  ip -d link show lxdevpn0-vx | grep -F "vxlan id 100 local 127.0.0.1"
+
+  # Changing the VNI recreates the VXLAN interface.
+  lxc network set lxdevpn0 evpn.vni=200
+  ip -d link show lxdevpn0-vx | grep -F "vxlan id 200 local 127.0.0.1"
+
+  lxc network delete lxdevpn0
+  ! ip link show lxdevpn0-vx || false
+
+  # QoS classes
+  lxc network create lxdqos0 ipv4.address=none ipv6.address=none
+  ! lxc network set lxdqos0 qos.classes.gold.rate=100Mbit || false # qos.rate is required
+  ! lxc network set lxdqos0 qos.rate=100Mbit qos.classes.gold.rate=200Mbit || false # class rate exceeds qos.rate
+  ! lxc network set lxdqos0 qos.rate=1Gbit qos.classes.gold.rate=200Mbit qos.classes.gold.ceil=100Mbit || false # ceil lower than rate
+  lxc network set lxdqos0 qos.rate=1Gbit qos.classes.gold.rate=600Mbit qos.classes.bronze.rate=100Mbit qos.classes.bronze.ceil=300Mbit
+  tc class show dev lxdqos0 | grep -F "class htb 1:1 root"
+  tc class show dev lxdqos0 | grep -F "class htb 1:10 parent 1:1"
+  tc class show dev lxdqos0 | grep -F "class htb 1:11 parent 1:1"
+  tc qdisc show dev lxdqos0 | grep -F "qdisc ingress ffff:"
+  ip -d link show type ifb | grep -F "lxdqos"
+
+  # Removing the classes clears the traffic shaping.
+  lxc network unset lxdqos0 qos.classes.gold.rate
+  lxc network unset lxdqos0 qos.classes.bronze.ceil
+  lxc network unset lxdqos0 qos.classes.bronze.rate
+  ! tc class show dev lxdqos0 | grep -F "class htb" || false
+  ! ip -d link show type ifb | grep -F "lxdqos" || false
+  lxc network delete lxdqos0
+
   # rename network
   lxc network create lxdt$$ ipv4.address=192.0.2.1/24 ipv6.address=none
   old_log="${LXD_DIR}/logs/dnsmasq.lxdt$$.log"
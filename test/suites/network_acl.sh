@@ -125,6 +125,21 @@ EOF
     nft -nn list chain inet lxd "acl.${netName}" | grep -F "oifname \"${netName}\" ip saddr 192.168.1.2 ip daddr ${daddr} tcp dport { 22, 2222-2223 } accept"
   fi
 
+  echo "Verify flow logging sends the matched packets to the netlink log group"
+  lxc network set "${netName}" security.flow_logging=true
+  if [ "$firewallDriver" = "xtables" ]; then
+    iptables -w -S | grep -F -- "-A lxd_acl_${netName} -s 192.168.1.2/32 -d ${daddr} -o ${netName} -p tcp -m multiport --dports 22,2222:2223 -j NFLOG --nflog-prefix"
+  else
+    nft -nn list chain inet lxd "acl.${netName}" | grep -F "tcp dport { 22, 2222-2223 } log prefix" | grep -F "group 8000"
+  fi
+
+  lxc network unset "${netName}" security.flow_logging
+  if [ "$firewallDriver" = "xtables" ]; then
+    ! iptables -w -S | grep -F -- "-j NFLOG" || false
+  else
+    ! nft -nn list chain inet lxd "acl.${netName}" | grep -F "group 8000" || false
+  fi
+
   echo "Stop applying ACL to test network"
   lxc network unset "${netName}" security.acls
 
@@ -154,3 +169,85 @@ EOF
 
   lxc network acl delete testacl2
 }
+
+test_network_acl_sets() {
+  firewallDriver=$(lxc info | awk -F ":" '/firewall:/{gsub(/ /, "", $0); print $2}')
+  netName=lxdt$$
+
+  lxc network create "${netName}" ipv4.address=192.0.2.1/24 ipv6.address=fd42:4242:4242:1010::1/64
+
+  # Check basic set creation, listing and validation.
+  ! lxc network address-set create 192.168.1.1 || false # Don't allow non-hostname compatible names.
+  ! lxc network address-set create testset not-an-address || false
+  ! lxc network port-set create testports 99999 || false
+  lxc network address-set create testset 192.0.2.10 198.51.100.0/24
+  lxc network port-set create testports 80 8000-8080
+  ! lxc network address-set create testset 192.0.2.11 || false
+  [ "$(lxc network address-set ls -f csv | grep -cwF 'testset')" = 1 ]
+  [ "$(lxc network port-set ls -f csv | grep -cwF 'testports')" = 1 ]
+  lxc network address-set add testset 2001:db8::10
+  ! lxc network address-set add testset 2001:db8::10 || false
+  lxc network port-set add testports 443
+  lxc network address-set remove testset 198.51.100.0/24
+  [ "$(lxc network address-set get testset -p addresses | grep -cF '198.51.100.0/24')" = 0 ]
+  lxc network address-set set testset user.mykey=foo
+  [ "$(lxc network address-set get testset user.mykey)" = "foo" ]
+  ! lxc network address-set set testset non.userkey=foo || false
+
+  # ACL rules can only reference existing sets of the right type.
+  lxc network acl create testacl
+  ! lxc network acl rule add testacl ingress action=allow source='$missing' || false
+  ! lxc network acl rule add testacl ingress action=allow source='$testports' || false
+  ! lxc network acl rule add testacl ingress action=allow protocol=tcp destination_port='$testset' || false
+  lxc network acl rule add testacl ingress action=allow source='$testset' protocol=tcp destination_port='$testports'
+  lxc network acl rule add testacl egress action=allow destination='$testset,192.0.2.20'
+
+  # Sets in use can't be renamed or deleted and report their users.
+  [ "$(lxc network address-set show testset | grep -cF '/1.0/network-acls/testacl')" = 1 ]
+  [ "$(lxc network port-set show testports | grep -cF '/1.0/network-acls/testacl')" = 1 ]
+  ! lxc network address-set rename testset testset2 || false
+  ! lxc network address-set delete testset || false
+  ! lxc network port-set delete testports || false
+
+  # Apply the ACL to the network and check the sets are rendered.
+  lxc network set "${netName}" security.acls=testacl
+
+  if [ "$firewallDriver" = "nftables" ]; then
+    nft list set inet lxd "aclset.${netName}.testset_ip4" | grep -F 192.0.2.10
+    nft list set inet lxd "aclset.${netName}.testset_ip6" | grep -F 2001:db8::10
+    nft list set inet lxd "aclset.${netName}.testports_port" | grep -F 8000-8080
+    nft list chain inet lxd "acl.${netName}" | grep -F "@aclset.${netName}.testset_ip4"
+
+    # Updating a set updates the rendered set without changing the ACL.
+    lxc network address-set add testset 192.0.2.30
+    nft list set inet lxd "aclset.${netName}.testset_ip4" | grep -F 192.0.2.30
+  else
+    iptables -S | grep -F 192.0.2.10
+  fi
+
+  # Check address sets referencing instances resolve to the instance's addresses.
+  ensure_import_testimage
+  lxc init testimage c1 -n "${netName}"
+  lxc config device set c1 eth0 ipv4.address=192.0.2.50
+  lxc network address-set create instset instance:c1
+  [ "$(lxc network address-set show-state instset | grep -cF '192.0.2.50')" = 1 ]
+  ! lxc network address-set create badinstset instance: || false
+  lxc network address-set delete instset
+  lxc delete c1
+
+  lxc network unset "${netName}" security.acls
+  lxc network acl delete testacl
+
+  if [ "$firewallDriver" = "nftables" ]; then
+    ! nft list set inet lxd "aclset.${netName}.testset_ip4" || false
+  fi
+
+  # Unused sets can be renamed and deleted.
+  lxc network address-set rename testset testset2
+  lxc network address-set delete testset2
+  lxc network port-set delete testports
+  [ "$(lxc network address-set ls -f csv || echo fail)" = "" ]
+  [ "$(lxc network port-set ls -f csv || echo fail)" = "" ]
+
+  lxc network delete "${netName}"
+}
@@ -0,0 +1,60 @@
+test_storage_driver_nfs() {
+  if [ -z "${LXD_NFS_HOST:-}" ] || [ -z "${LXD_NFS_PATH:-}" ]; then
+    export TEST_UNMET_REQUIREMENT="required 'LXD_NFS_HOST' and 'LXD_NFS_PATH' not set"
+    return
+  fi
+
+  if ! command -v mount.nfs >/dev/null; then
+    export TEST_UNMET_REQUIREMENT="mount.nfs is missing"
+    return
+  fi
+
+  ensure_import_testimage
+
+  # Simple create/delete attempt.
+  lxc storage create nfs nfs nfs.host="${LXD_NFS_HOST}" nfs.path="${LXD_NFS_PATH}"
+  lxc storage delete nfs
+
+  # Test invalid configuration.
+  ! lxc storage create nfs nfs nfs.host="${LXD_NFS_HOST}" || false
+  ! lxc storage create nfs nfs nfs.host="${LXD_NFS_HOST}" nfs.path=relative/path || false
+  ! lxc storage create nfs nfs nfs.host="${LXD_NFS_HOST}" nfs.path="${LXD_NFS_PATH}" nfs.version=5 || false
+
+  lxc storage create nfs nfs nfs.host="${LXD_NFS_HOST}" nfs.path="${LXD_NFS_PATH}"
+  lxc storage info nfs
+  ! lxc storage set nfs nfs.path=/other || false
+
+  # Creation, rename and deletion of custom volumes.
+  lxc storage volume create nfs vol1
+  lxc storage volume set nfs vol1 size 100MiB
+  lxc storage volume rename nfs vol1 vol2
+  lxc storage volume copy nfs/vol2 nfs/vol1
+  lxc storage volume delete nfs vol1
+  lxc storage volume delete nfs vol2
+
+  # Custom block volumes are stored as sparse files.
+  lxc storage volume create nfs vol1 --type=block size=10MiB
+  lxc storage volume set nfs vol1 size=20MiB
+  lxc storage volume delete nfs vol1
+
+  # Snapshots.
+  lxc storage volume create nfs vol1
+  lxc storage volume snapshot nfs vol1
+  lxc storage volume snapshot nfs vol1 blah1
+  lxc storage volume rename nfs vol1/blah1 vol1/blah2
+  lxc storage volume restore nfs vol1 blah2
+  lxc storage volume delete nfs vol1/snap0
+  lxc storage volume delete nfs vol1/blah2
+  lxc storage volume delete nfs vol1
+
+  # Instances.
+  lxc init testimage c1 -s nfs
+  lxc start c1
+  lxc snapshot c1
+  lxc stop -f c1
+  lxc restore c1 snap0
+  lxc delete c1
+
+  # Cleanup.
+  lxc storage delete nfs
+}
@@ -61,9 +61,9 @@ test_storage_pools() {
     ! lxc storage show "${poolName}" || false
   done
 
-  # The Pure Storage, HPE Alletra and PowerFlex drivers all contact their remote
+  # The Pure Storage, HPE Alletra, PowerFlex and SAN drivers all contact their remote
   # backend while creating a pool (Pure and Alletra provision the pool, PowerFlex
-  # discovers the system version). With placeholder configuration pointing at a
+  # discovers the system version, SAN prepares the storage host). With placeholder configuration pointing at a
   # closed local port, pool creation must fail cleanly and leave no pool behind.
   sub_test "Remote pool creation fails cleanly with placeholder config"
 
@@ -78,4 +78,8 @@ test_storage_pools() {
   poolName="${poolPrefix}-powerflex"
   ! lxc storage create "${poolName}" powerflex powerflex.gateway=https://127.0.0.1:1234 powerflex.user.password=secret powerflex.pool=fakepool powerflex.mode=nvme/tcp || false
   ! lxc storage show "${poolName}" || false
+
+  poolName="${poolPrefix}-san"
+  ! lxc storage create "${poolName}" san san.host=127.0.0.1:1234 san.ssh.key=secret san.ssh.host_key=secret san.backend=zfs san.source=fakepool/lxd san.mode=iscsi || false
+  ! lxc storage show "${poolName}" || false
 }